@@ -24,6 +24,7 @@ func NewTraefikConfiguration() *TraefikCmdConfiguration {
 			EntryPoints: make(static.EntryPoints),
 			Providers: &static.Providers{
 				ProvidersThrottleDuration: ptypes.Duration(2 * time.Second),
+				FirstSyncTimeout:          ptypes.Duration(60 * time.Second),
 			},
 			ServersTransport: &static.ServersTransport{
 				MaxIdleConnsPerHost: 200,
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"context"
+
+	"github.com/traefik/paerser/cli"
+)
+
+// runAsService runs the given command as a Windows service, which is never the case on this platform.
+func runAsService(_ *cli.Command) (bool, error) {
+	return false, nil
+}
+
+// withServiceStop returns the given context, as there is no Windows service to stop on this platform.
+func withServiceStop(ctx context.Context) context.Context {
+	return ctx
+}
+
+// newServiceCmd returns nil, as the Windows service command is only available on Windows.
+func newServiceCmd() *cli.Command {
+	return nil
+}
@@ -0,0 +1,263 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/traefik/paerser/cli"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	serviceName        = "traefik"
+	serviceDisplayName = "Traefik"
+	serviceStopTimeout = 30 * time.Second
+)
+
+// serviceStop is closed when the Windows service control manager asks Traefik to stop.
+var serviceStop = make(chan struct{})
+
+// runAsService runs the given command as a Windows service, when Traefik is started by the service control manager.
+// It returns false when Traefik is run from an interactive session.
+func runAsService(cmdTraefik *cli.Command) (bool, error) {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		return false, fmt.Errorf("unable to determine whether Traefik runs as a service: %w", err)
+	}
+
+	if interactive {
+		return false, nil
+	}
+
+	return true, svc.Run(serviceName, &windowsService{
+		execute: func() error {
+			return cli.Execute(cmdTraefik)
+		},
+	})
+}
+
+// withServiceStop returns a context canceled when the Windows service is asked to stop.
+func withServiceStop(ctx context.Context) context.Context {
+	newCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-serviceStop:
+			cancel()
+		case <-newCtx.Done():
+		}
+	}()
+	return newCtx
+}
+
+type windowsService struct {
+	execute func() error
+}
+
+// Execute implements svc.Handler.
+func (s *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.execute()
+	}()
+
+	accepts := svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+
+	for {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				return true, 1
+			}
+			return false, 0
+
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				select {
+				case <-serviceStop:
+				default:
+					close(serviceStop)
+				}
+			}
+		}
+	}
+}
+
+func newServiceCmd() *cli.Command {
+	serviceCmd := &cli.Command{
+		Name:        "service",
+		Description: `Manages the Traefik Windows service.`,
+	}
+
+	subCommands := []*cli.Command{
+		{
+			Name:        "install",
+			Description: `Installs Traefik as a Windows service. The given arguments are passed to Traefik when the service starts, e.g. traefik service install --configFile=C:\traefik\traefik.toml`,
+			AllowArg:    true,
+			Run:         installService,
+		},
+		{
+			Name:        "uninstall",
+			Description: `Uninstalls the Traefik Windows service.`,
+			Run: func(_ []string) error {
+				return uninstallService()
+			},
+		},
+		{
+			Name:        "start",
+			Description: `Starts the Traefik Windows service.`,
+			Run: func(_ []string) error {
+				return startService()
+			},
+		},
+		{
+			Name:        "stop",
+			Description: `Stops the Traefik Windows service, and waits for its graceful shutdown.`,
+			Run: func(_ []string) error {
+				return stopService()
+			},
+		},
+	}
+
+	for _, subCommand := range subCommands {
+		if err := serviceCmd.AddCommand(subCommand); err != nil {
+			// Should never happen as the sub-commands have distinct names.
+			panic(err)
+		}
+	}
+
+	return serviceCmd
+}
+
+func installService(args []string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	exePath, err = filepath.Abs(exePath)
+	if err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		_ = s.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+
+	s, err := m.CreateService(serviceName, exePath, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: "Traefik reverse proxy and load balancer",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	err = eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("unable to register the %s event source: %w", serviceName, err)
+	}
+
+	fmt.Printf("Service %s installed\n", serviceName)
+	return nil
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer func() { _ = s.Close() }()
+
+	if err = s.Delete(); err != nil {
+		return err
+	}
+
+	if err = eventlog.Remove(serviceName); err != nil {
+		return fmt.Errorf("unable to remove the %s event source: %w", serviceName, err)
+	}
+
+	fmt.Printf("Service %s uninstalled\n", serviceName)
+	return nil
+}
+
+func startService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer func() { _ = s.Close() }()
+
+	return s.Start()
+}
+
+func stopService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer func() { _ = s.Close() }()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return errors.New("timeout while waiting for the service to stop")
+		}
+
+		time.Sleep(300 * time.Millisecond)
+
+		status, err = s.Query()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
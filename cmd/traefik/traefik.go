@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	stdlog "log"
 	"net/http"
 	"os"
@@ -16,15 +17,21 @@ import (
 	"github.com/containous/traefik/v2/cmd/healthcheck"
 	cmdVersion "github.com/containous/traefik/v2/cmd/version"
 	tcli "github.com/containous/traefik/v2/pkg/cli"
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/collector"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/deprecation"
+	traefikhealthcheck "github.com/containous/traefik/v2/pkg/healthcheck"
+	"github.com/containous/traefik/v2/pkg/ipset"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/v2/pkg/pilot"
+	"github.com/containous/traefik/v2/pkg/ping"
 	"github.com/containous/traefik/v2/pkg/plugins"
+	"github.com/containous/traefik/v2/pkg/policy"
 	"github.com/containous/traefik/v2/pkg/provider/acme"
 	"github.com/containous/traefik/v2/pkg/provider/aggregator"
 	"github.com/containous/traefik/v2/pkg/provider/traefik"
@@ -71,12 +78,28 @@ Complete documentation is available at https://traefik.io`,
 		os.Exit(1)
 	}
 
-	err = cli.Execute(cmdTraefik)
+	if serviceCmd := newServiceCmd(); serviceCmd != nil {
+		err = cmdTraefik.AddCommand(serviceCmd)
+		if err != nil {
+			stdlog.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	isService, err := runAsService(cmdTraefik)
 	if err != nil {
 		stdlog.Println(err)
 		logrus.Exit(1)
 	}
 
+	if !isService {
+		err = cli.Execute(cmdTraefik)
+		if err != nil {
+			stdlog.Println(err)
+			logrus.Exit(1)
+		}
+	}
+
 	logrus.Exit(0)
 }
 
@@ -94,6 +117,14 @@ func runCmd(staticConfiguration *static.Configuration) error {
 		return err
 	}
 
+	if isSupervisor(staticConfiguration) {
+		return runSupervisor(staticConfiguration.Experimental.Workers)
+	}
+
+	if id, ok := workerID(); ok {
+		setupWorker(staticConfiguration, id)
+	}
+
 	log.WithoutContext().Infof("Traefik version %s built on %s", version.Version, version.BuildDate)
 
 	jsonConf, err := json.Marshal(staticConfiguration)
@@ -119,7 +150,7 @@ func runCmd(staticConfiguration *static.Configuration) error {
 		return err
 	}
 
-	ctx := cmd.ContextWithSignal(context.Background())
+	ctx := withServiceStop(cmd.ContextWithSignal(context.Background()))
 
 	if staticConfiguration.Experimental != nil && staticConfiguration.Experimental.DevPlugin != nil {
 		var cancel context.CancelFunc
@@ -134,6 +165,10 @@ func runCmd(staticConfiguration *static.Configuration) error {
 	svr.Start(ctx)
 	defer svr.Close()
 
+	if staticConfiguration.Ping != nil && !staticConfiguration.Providers.WaitForFirstSync {
+		staticConfiguration.Ping.SetReady(ping.HealthCheckEntryPoints)
+	}
+
 	sent, err := daemon.SdNotify(false, "READY=1")
 	if !sent && err != nil {
 		log.WithoutContext().Errorf("Failed to notify: %v", err)
@@ -183,18 +218,23 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 
 	acmeProviders := initACMEProvider(staticConfiguration, &providerAggregator, tlsManager)
 
-	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints)
-	if err != nil {
-		return nil, err
+	if id, ok := workerID(); ok {
+		setupWorkerACME(acmeProviders, id)
 	}
 
-	serverEntryPointsUDP, err := server.NewUDPEntryPoints(staticConfiguration.EntryPoints)
+	ctx := context.Background()
+	routinesPool := safe.NewPool(ctx)
+
+	clusterNode, err := setupCluster(staticConfiguration.Cluster, routinesPool)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	routinesPool := safe.NewPool(ctx)
+	if clusterNode != nil {
+		for _, p := range acmeProviders {
+			p.SetCluster(clusterNode)
+		}
+	}
 
 	metricRegistries := registerMetricClients(staticConfiguration.Metrics)
 
@@ -211,6 +251,34 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	}
 
 	metricsRegistry := metrics.NewMultiRegistry(metricRegistries)
+
+	if metricsRegistry.IsSvcEnabled() {
+		traefikhealthcheck.GetHealthCheck().SetMetricsRegistry(metricsRegistry)
+	}
+
+	for _, p := range acmeProviders {
+		p.SetMetricsRegistry(metricsRegistry)
+	}
+
+	if staticConfiguration.Providers.ConsulCatalog != nil {
+		staticConfiguration.Providers.ConsulCatalog.SetMetricsRegistry(metricsRegistry)
+	}
+	if staticConfiguration.Providers.Ecs != nil {
+		staticConfiguration.Providers.Ecs.SetMetricsRegistry(metricsRegistry)
+	}
+
+	ipSets := ipset.NewManager()
+
+	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints, metricsRegistry, ipSets)
+	if err != nil {
+		return nil, err
+	}
+
+	serverEntryPointsUDP, err := server.NewUDPEntryPoints(staticConfiguration.EntryPoints, metricsRegistry)
+	if err != nil {
+		return nil, err
+	}
+
 	accessLog := setupAccessLog(staticConfiguration.AccessLog)
 	chainBuilder := middleware.NewChainBuilder(*staticConfiguration, metricsRegistry, accessLog)
 	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry)
@@ -225,7 +293,11 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		return nil, err
 	}
 
-	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder)
+	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder, metricsRegistry)
+	if clusterNode != nil {
+		routerFactory.SetCluster(clusterNode)
+	}
+	routerFactory.SetIPSets(ipSets)
 
 	var defaultEntryPoints []string
 	for name, cfg := range staticConfiguration.EntryPoints {
@@ -252,6 +324,15 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	watcher.AddListener(func(conf dynamic.Configuration) {
 		ctx := context.Background()
 		tlsManager.UpdateConfigs(ctx, conf.TLS.Stores, conf.TLS.Options, conf.TLS.Certificates)
+
+		metrics.OnCertificatesUpdate(metricsRegistry.TLSCertsNotAfterTimestampGauge(), conf, tlsManager.GetCertificates())
+	})
+
+	// The IP sets are updated before the routers, for the middlewares to find them.
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		if conf.HTTP != nil {
+			ipSets.UpdateConfigs(context.Background(), conf.HTTP.IPSets)
+		}
 	})
 
 	watcher.AddListener(func(_ dynamic.Configuration) {
@@ -259,10 +340,23 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		metricsRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
 	})
 
-	watcher.AddListener(switchRouter(routerFactory, acmeProviders, serverEntryPointsTCP, serverEntryPointsUDP, aviator))
+	var policyEngine *policy.Engine
+	if staticConfiguration.Policies != nil && staticConfiguration.Policies.OPA != nil {
+		policyEngine, err = policy.New(staticConfiguration.Policies.OPA)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deprecations := deprecation.NewRegistry(metricsRegistry.ConfigDeprecationsGauge())
+	providerReloads := metrics.NewProviderReloads(metricsRegistry)
+
+	watcher.AddListener(switchRouter(routerFactory, acmeProviders, serverEntryPointsTCP, serverEntryPointsUDP, aviator, policyEngine, deprecations, providerReloads))
+
+	watcher.AddProviderListener(providerReloads.OnProviderReload)
 
 	watcher.AddListener(func(conf dynamic.Configuration) {
-		if metricsRegistry.IsEpEnabled() || metricsRegistry.IsSvcEnabled() {
+		if metricsRegistry.IsEpEnabled() || metricsRegistry.IsRouterEnabled() || metricsRegistry.IsSvcEnabled() {
 			var eps []string
 			for key := range serverEntryPointsTCP {
 				eps = append(eps, key)
@@ -272,6 +366,14 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	})
 
+	if staticConfiguration.Metrics != nil && staticConfiguration.Metrics.Prometheus != nil && staticConfiguration.Metrics.Prometheus.AddInfoMetrics {
+		watcher.AddListener(metrics.OnConfigurationInfoUpdate)
+	}
+
+	if staticConfiguration.Ping != nil {
+		setupHealthChecks(staticConfiguration, acmeProviders, watcher)
+	}
+
 	resolverNames := map[string]struct{}{}
 	for _, p := range acmeProviders {
 		resolverNames[p.ResolverName] = struct{}{}
@@ -290,15 +392,121 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	})
 
-	return server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, watcher, chainBuilder, accessLog), nil
+	svr := server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, watcher, chainBuilder, accessLog)
+
+	if staticConfiguration.Providers.WaitForFirstSync {
+		var started func()
+		if staticConfiguration.Ping != nil {
+			started = func() {
+				staticConfiguration.Ping.SetReady(ping.HealthCheckEntryPoints)
+			}
+		}
+
+		svr.WaitForFirstSync(syncProviderNames(staticConfiguration.Providers, acmeProviders), time.Duration(staticConfiguration.Providers.FirstSyncTimeout), started)
+	}
+
+	return svr, nil
 }
 
-func switchRouter(routerFactory *server.RouterFactory, acmeProviders []*acme.Provider, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints, aviator *pilot.Pilot) func(conf dynamic.Configuration) {
+// setupHealthChecks marks the providers and certificates subsystems as ready,
+// once the watcher has applied the first configuration of the corresponding providers.
+func setupHealthChecks(staticConfiguration *static.Configuration, acmeProviders []*acme.Provider, watcher *server.ConfigurationWatcher) {
+	watcher.AddSyncListener(syncProviderNames(staticConfiguration.Providers, acmeProviders), func() {
+		log.WithoutContext().Debug("All the providers have delivered their first configuration")
+		staticConfiguration.Ping.SetReady(ping.HealthCheckProviders)
+	})
+
+	watcher.AddSyncListener(certificateProviderNames(acmeProviders), func() {
+		staticConfiguration.Ping.SetReady(ping.HealthCheckCertificates)
+	})
+}
+
+// syncProviderNames returns the names of the providers delivering a first configuration,
+// including the ones of the certificates.
+func syncProviderNames(providers *static.Providers, acmeProviders []*acme.Provider) []string {
+	return append(providerNames(providers), certificateProviderNames(acmeProviders)...)
+}
+
+// certificateProviderNames returns the names of the providers delivering the certificates.
+func certificateProviderNames(acmeProviders []*acme.Provider) []string {
+	names := []string{"internal"}
+	for _, p := range acmeProviders {
+		names = append(names, p.ResolverName+".acme")
+	}
+
+	return names
+}
+
+// providerNames returns the names of the enabled providers which deliver a configuration on start.
+// The REST provider is not part of them, as it only sends a configuration when it is called.
+func providerNames(providers *static.Providers) []string {
+	if providers == nil {
+		return nil
+	}
+
+	var names []string
+	if providers.Docker != nil {
+		names = append(names, "docker")
+	}
+	if providers.File != nil {
+		names = append(names, "file")
+	}
+	if providers.Marathon != nil {
+		names = append(names, "marathon")
+	}
+	if providers.KubernetesIngress != nil {
+		names = append(names, "kubernetes")
+	}
+	if providers.KubernetesCRD != nil {
+		names = append(names, "kubernetescrd")
+	}
+	if providers.Rancher != nil {
+		names = append(names, "rancher")
+	}
+	if providers.ConsulCatalog != nil {
+		names = append(names, "consulcatalog")
+	}
+	if providers.Ecs != nil {
+		names = append(names, "ecs")
+	}
+	if providers.Consul != nil {
+		names = append(names, "consul")
+	}
+	if providers.Etcd != nil {
+		names = append(names, "etcd")
+	}
+	if providers.ZooKeeper != nil {
+		names = append(names, "zookeeper")
+	}
+	if providers.Redis != nil {
+		names = append(names, "redis")
+	}
+	if providers.HTTP != nil {
+		names = append(names, "http")
+	}
+	if providers.OCI != nil {
+		names = append(names, "oci")
+	}
+	if providers.Git != nil {
+		names = append(names, "git")
+	}
+
+	return names
+}
+
+func switchRouter(routerFactory *server.RouterFactory, acmeProviders []*acme.Provider, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints, aviator *pilot.Pilot, policyEngine *policy.Engine, deprecations *deprecation.Registry, providerReloads *metrics.ProviderReloads) func(conf dynamic.Configuration) {
 	return func(conf dynamic.Configuration) {
 		rtConf := runtime.NewConfig(conf)
+		rtConf.Deprecations = deprecations.Check(context.Background(), conf)
+
+		if policyEngine != nil {
+			policyEngine.Apply(context.Background(), conf, rtConf)
+		}
 
 		routers, udpRouters := routerFactory.CreateRouters(rtConf)
 
+		providerReloads.OnRuntimeConfiguration(rtConf)
+
 		for entryPointName, rt := range routers {
 			for _, p := range acmeProviders {
 				if p != nil && p.HTTPChallenge != nil && p.HTTPChallenge.EntryPoint == entryPointName {
@@ -317,6 +525,24 @@ func switchRouter(routerFactory *server.RouterFactory, acmeProviders []*acme.Pro
 	}
 }
 
+// setupCluster joins the cluster the runtime state is shared with, if any.
+func setupCluster(config *cluster.Configuration, routinesPool *safe.Pool) (*cluster.Node, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	node, err := cluster.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to join the cluster: %w", err)
+	}
+
+	traefikhealthcheck.GetHealthCheck().SetCluster(node)
+
+	node.Start(routinesPool)
+
+	return node, nil
+}
+
 // initACMEProvider creates an acme provider from the ACME part of globalConfiguration.
 func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator, tlsManager *traefiktls.Manager) []*acme.Provider {
 	challengeStore := acme.NewLocalChallengeStore()
@@ -392,6 +618,13 @@ func registerMetricClients(metricsConfig *types.Metrics) []metrics.Registry {
 			metricsConfig.InfluxDB.Address, metricsConfig.InfluxDB.PushInterval)
 	}
 
+	if metricsConfig.Graphite != nil {
+		ctx := log.With(context.Background(), log.Str(log.MetricsProviderName, "graphite"))
+		registries = append(registries, metrics.RegisterGraphite(ctx, metricsConfig.Graphite))
+		log.FromContext(ctx).Debugf("Configured Graphite metrics: pushing to %s once every %s",
+			metricsConfig.Graphite.Address, metricsConfig.Graphite.PushInterval)
+	}
+
 	return registries
 }
 
@@ -443,6 +676,15 @@ func configureLogging(staticConfiguration *static.Configuration) {
 	}
 	log.SetFormatter(formatter)
 
+	if staticConfiguration.Log != nil && staticConfiguration.Log.EventLog != nil {
+		hook, err := log.NewEventLogHook(staticConfiguration.Log.EventLog.Source)
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to write the logs to the Event Log: %v", err)
+		} else {
+			logrus.AddHook(hook)
+		}
+	}
+
 	if len(logFile) > 0 {
 		dir := filepath.Dir(logFile)
 
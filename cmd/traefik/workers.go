@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containous/traefik/v2/cmd"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+)
+
+const (
+	// workerIDEnv holds the index of a worker process, it is not set in the supervisor process.
+	workerIDEnv = "TRAEFIK_WORKER_ID"
+	// workerStateDirEnv holds the directory of the state shared by the worker processes.
+	workerStateDirEnv = "TRAEFIK_WORKER_STATE_DIR"
+
+	workerRestartDelay = time.Second
+	workerStopTimeout  = 30 * time.Second
+)
+
+// isSupervisor returns whether this process has to start the worker processes, instead of serving the traffic itself.
+func isSupervisor(staticConfiguration *static.Configuration) bool {
+	return staticConfiguration.Experimental != nil &&
+		staticConfiguration.Experimental.Workers != nil &&
+		staticConfiguration.Experimental.Workers.Count > 1 &&
+		os.Getenv(workerIDEnv) == ""
+}
+
+// workerID returns the index of the current worker process, and false if this process is not a worker.
+func workerID() (int, bool) {
+	value := os.Getenv(workerIDEnv)
+	if value == "" {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// setupWorker adapts the static configuration of a worker process:
+// the TCP entry points are shared with the other workers,
+// while the UDP entry points and the ACME resolvers are only run by the first worker.
+func setupWorker(staticConfiguration *static.Configuration, id int) {
+	for name, ep := range staticConfiguration.EntryPoints {
+		protocol, err := ep.GetProtocol()
+		if err != nil {
+			continue
+		}
+
+		if protocol == "udp" {
+			if id != 0 {
+				delete(staticConfiguration.EntryPoints, name)
+			}
+			continue
+		}
+
+		ep.ReusePort = true
+	}
+}
+
+// setupWorkerACME makes the ACME resolvers of a worker share their challenges with the other workers,
+// and only resolve certificates in the first worker.
+func setupWorkerACME(resolvers []*acme.Provider, id int) {
+	stateDir := os.Getenv(workerStateDirEnv)
+
+	var challengeStore acme.ChallengeStore
+	if stateDir != "" {
+		challengeStore = acme.NewFileChallengeStore(filepath.Join(stateDir, "acme-challenges.json"))
+	}
+
+	for _, resolver := range resolvers {
+		if challengeStore != nil {
+			resolver.ChallengeStore = challengeStore
+		}
+
+		if id != 0 {
+			resolver.SetFollower()
+		}
+	}
+}
+
+// runSupervisor starts the worker processes, restarts them when they exit unexpectedly,
+// and stops them gracefully when the supervisor is asked to stop.
+func runSupervisor(workers *static.Workers) error {
+	if runtime.GOOS == "windows" {
+		return errors.New("the multi-process mode is not supported on Windows")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	stateDir, err := ioutil.TempDir("", "traefik-workers")
+	if err != nil {
+		return fmt.Errorf("unable to create the workers state directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(stateDir) }()
+
+	cpuSets, err := workerCPUSets(workers)
+	if err != nil {
+		return fmt.Errorf("unable to compute the CPU affinity of the workers: %w", err)
+	}
+
+	ctx := cmd.ContextWithSignal(context.Background())
+	logger := log.FromContext(ctx)
+
+	logger.Infof("Starting %d worker processes", workers.Count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers.Count; i++ {
+		worker := &workerProcess{
+			id:         i,
+			executable: executable,
+			args:       os.Args[1:],
+			env:        append(os.Environ(), fmt.Sprintf("%s=%d", workerIDEnv, i), fmt.Sprintf("%s=%s", workerStateDirEnv, stateDir)),
+		}
+		if cpuSets != nil {
+			worker.cpus = cpuSets[i]
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.run(ctx)
+		}()
+	}
+
+	wg.Wait()
+	logger.Info("All the worker processes are stopped")
+
+	return nil
+}
+
+type workerProcess struct {
+	id         int
+	executable string
+	args       []string
+	env        []string
+	cpus       []int
+}
+
+func (w *workerProcess) run(ctx context.Context) {
+	logger := log.FromContext(ctx).WithField("worker", w.id)
+
+	for {
+		command := exec.Command(w.executable, w.args...)
+		command.Env = w.env
+		command.Stdin = os.Stdin
+		command.Stdout = os.Stdout
+		command.Stderr = os.Stderr
+
+		if err := startWorker(command, w.cpus); err != nil {
+			logger.Errorf("Unable to start the worker process: %v", err)
+		} else {
+			logger.Debugf("Worker process started with PID %d", command.Process.Pid)
+
+			exited := make(chan error, 1)
+			go func() {
+				exited <- command.Wait()
+			}()
+
+			select {
+			case err := <-exited:
+				logger.Errorf("Worker process exited unexpectedly: %v", err)
+
+			case <-ctx.Done():
+				_ = command.Process.Signal(syscall.SIGTERM)
+
+				select {
+				case <-exited:
+				case <-time.After(workerStopTimeout):
+					logger.Error("Timeout while stopping the worker process, killing it")
+					_ = command.Process.Kill()
+					<-exited
+				}
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(workerRestartDelay):
+		}
+	}
+}
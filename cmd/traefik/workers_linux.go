@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"golang.org/x/sys/unix"
+)
+
+// workerCPUSets splits the CPUs available to the supervisor between the workers.
+func workerCPUSets(workers *static.Workers) ([][]int, error) {
+	if !workers.CPUAffinity {
+		return nil, nil
+	}
+
+	var available unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &available); err != nil {
+		return nil, err
+	}
+
+	var cpus []int
+	for cpu := 0; len(cpus) < available.Count(); cpu++ {
+		if available.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+
+	if len(cpus) < workers.Count {
+		return nil, errors.New("there are fewer CPUs than workers")
+	}
+
+	sets := make([][]int, workers.Count)
+	for i, cpu := range cpus {
+		worker := i * workers.Count / len(cpus)
+		sets[worker] = append(sets[worker], cpu)
+	}
+
+	return sets, nil
+}
+
+// startWorker starts the worker process, pinned to the given CPUs.
+// The affinity is set on the thread which forks the process, so that the process inherits it from its very start.
+func startWorker(command *exec.Cmd, cpus []int) error {
+	if len(cpus) == 0 {
+		return command.Start()
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var previous unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &previous); err != nil {
+		return err
+	}
+
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+
+	if err := unix.SchedSetaffinity(0, &set); err != nil {
+		return err
+	}
+	defer func() { _ = unix.SchedSetaffinity(0, &previous) }()
+
+	return command.Start()
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+)
+
+// workerCPUSets fails when the CPU affinity is enabled, as it is only supported on Linux.
+func workerCPUSets(workers *static.Workers) ([][]int, error) {
+	if workers.CPUAffinity {
+		return nil, errors.New("the CPU affinity is only supported on Linux")
+	}
+	return nil, nil
+}
+
+// startWorker starts the worker process.
+func startWorker(command *exec.Cmd, _ []int) error {
+	return command.Start()
+}
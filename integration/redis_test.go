@@ -9,11 +9,10 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/abronan/valkeyrie"
 	"github.com/abronan/valkeyrie/store"
-	"github.com/abronan/valkeyrie/store/redis"
 	"github.com/containous/traefik/v2/integration/try"
 	"github.com/containous/traefik/v2/pkg/api"
+	"github.com/containous/traefik/v2/pkg/redis"
 	"github.com/go-check/check"
 	"github.com/pmezard/go-difflib/difflib"
 	checker "github.com/vdemeester/shakers"
@@ -29,14 +28,10 @@ func (s *RedisSuite) setupStore(c *check.C) {
 	s.createComposeProject(c, "redis")
 	s.composeProject.Start(c)
 
-	redis.Register()
-	kv, err := valkeyrie.NewStore(
-		store.REDIS,
-		[]string{s.composeProject.Container(c, "redis").NetworkSettings.IPAddress + ":6379"},
-		&store.Config{
-			ConnectionTimeout: 10 * time.Second,
-		},
-	)
+	// The keys are written with the store of the provider.
+	kv, err := redis.NewStore(redis.Options{
+		Endpoints: []string{s.composeProject.Container(c, "redis").NetworkSettings.IPAddress + ":6379"},
+	})
 	if err != nil {
 		c.Fatal("Cannot create store redis")
 	}
@@ -0,0 +1,67 @@
+package affinity
+
+import (
+	"sync"
+	"time"
+)
+
+const purgeInterval = time.Minute
+
+type memoryEntry struct {
+	server  string
+	expires time.Time
+}
+
+// MemoryTable is an affinity table local to the instance.
+type MemoryTable struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastPurge time.Time
+}
+
+// NewMemoryTable creates a MemoryTable.
+func NewMemoryTable() *MemoryTable {
+	return &MemoryTable{
+		entries:   make(map[string]memoryEntry),
+		lastPurge: time.Now(),
+	}
+}
+
+// Get returns the server of the key, or an empty string when the key is unknown or expired.
+func (t *MemoryTable) Get(key string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.entries[key]
+	if !ok {
+		return "", nil
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(t.entries, key)
+		return "", nil
+	}
+
+	return entry.server, nil
+}
+
+// Set maps the key to the server, for the given duration.
+func (t *MemoryTable) Set(key, server string, ttl time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.entries[key] = memoryEntry{server: server, expires: now.Add(ttl)}
+
+	// The expired entries of the clients which never came back are purged from time to time.
+	if now.Sub(t.lastPurge) > purgeInterval {
+		for k, entry := range t.entries {
+			if now.After(entry.expires) {
+				delete(t.entries, k)
+			}
+		}
+		t.lastPurge = now
+	}
+
+	return nil
+}
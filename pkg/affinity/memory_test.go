@@ -0,0 +1,48 @@
+package affinity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTable(t *testing.T) {
+	table := NewMemoryTable()
+
+	server, err := table.Get("foo")
+	require.NoError(t, err)
+	assert.Empty(t, server)
+
+	require.NoError(t, table.Set("foo", "http://10.0.0.1", time.Hour))
+
+	server, err = table.Get("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.1", server)
+
+	require.NoError(t, table.Set("bar", "http://10.0.0.2", -time.Second))
+
+	server, err = table.Get("bar")
+	require.NoError(t, err)
+	assert.Empty(t, server)
+}
+
+func TestMemoryTablePurge(t *testing.T) {
+	table := NewMemoryTable()
+
+	require.NoError(t, table.Set("foo", "http://10.0.0.1", -time.Second))
+
+	table.lastPurge = time.Now().Add(-2 * purgeInterval)
+	require.NoError(t, table.Set("bar", "http://10.0.0.2", time.Hour))
+
+	assert.Len(t, table.entries, 1)
+	assert.Contains(t, table.entries, "bar")
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key("foo@file", "bar"), Key("foo@file", "bar"))
+	assert.NotEqual(t, Key("foo@file", "bar"), Key("foo@file", "baz"))
+	assert.NotEqual(t, Key("foo@file", "bar"), Key("foo@docker", "bar"))
+	assert.NotContains(t, Key("foo@file", "secret"), "secret")
+}
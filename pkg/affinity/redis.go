@@ -0,0 +1,64 @@
+package affinity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/redis"
+)
+
+// redisTable is an affinity table stored in Redis, and thus shared by the instances using the same Redis server.
+type redisTable struct {
+	store  store.Store
+	prefix string
+}
+
+func newRedisTable(config *static.RedisAffinityTable) (*redisTable, error) {
+	options := redis.Options{
+		Endpoints: []string{config.Endpoint},
+		Username:  config.Username,
+		Password:  config.Password,
+		DB:        config.DB,
+		Sentinel:  config.Sentinel,
+		Cluster:   config.Cluster,
+	}
+
+	if config.TLS != nil {
+		var err error
+		options.TLS, err = config.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the TLS configuration of the affinity table: %w", err)
+		}
+	}
+
+	kvStore, err := redis.NewStore(options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the Redis client of the affinity table: %w", err)
+	}
+
+	return &redisTable{
+		store:  kvStore,
+		prefix: strings.TrimSuffix(config.Prefix, "/"),
+	}, nil
+}
+
+func (t *redisTable) Get(key string) (string, error) {
+	pair, err := t.store.Get(t.prefix+"/"+key, nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(pair.Value), nil
+}
+
+func (t *redisTable) Set(key, server string, ttl time.Duration) error {
+	return t.store.Put(t.prefix+"/"+key, []byte(server), &store.WriteOptions{TTL: ttl})
+}
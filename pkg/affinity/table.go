@@ -0,0 +1,34 @@
+package affinity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+)
+
+// Table maps the keys of the clients to the servers of their sticky sessions.
+type Table interface {
+	// Get returns the server of the key, or an empty string when the key is unknown.
+	Get(key string) (string, error)
+	// Set maps the key to the server, for the given duration.
+	Set(key, server string, ttl time.Duration) error
+}
+
+// NewTable creates the affinity table described by the configuration.
+// The table is local to the instance when no store is configured.
+func NewTable(config *static.AffinityTable) (Table, error) {
+	if config == nil || config.Redis == nil {
+		return NewMemoryTable(), nil
+	}
+
+	return newRedisTable(config.Redis)
+}
+
+// Key returns the key of a client in the table of a service.
+// The client key is hashed, as it can be a credential given in a header, and to bound the length of the keys.
+func Key(serviceName, clientKey string) string {
+	hash := sha256.Sum256([]byte(clientKey))
+	return serviceName + "/" + hex.EncodeToString(hash[:])
+}
@@ -6,8 +6,10 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/containous/traefik/v2/pkg/config/label"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/deprecation"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/version"
 	assetfs "github.com/elazarl/go-bindata-assetfs"
@@ -42,6 +44,8 @@ type RunTimeRepresentation struct {
 	TCPServices map[string]*runtime.TCPServiceInfo    `json:"tcpServices,omitempty"`
 	UDPRouters  map[string]*runtime.UDPRouterInfo     `json:"udpRouters,omitempty"`
 	UDPServices map[string]*runtime.UDPServiceInfo    `json:"udpServices,omitempty"`
+
+	Deprecations []deprecation.Warning `json:"deprecations,omitempty"`
 }
 
 // Handler serves the configuration and status of Traefik on API endpoints.
@@ -50,15 +54,21 @@ type Handler struct {
 	debug           bool
 	staticConfig    static.Configuration
 	dashboardAssets *assetfs.AssetFS
+	weights         WeightSetter
+	federation      *federation
+	issues          *label.IssueRegistry
 
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *runtime.Configuration
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration.
-func NewBuilder(staticConfig static.Configuration) func(*runtime.Configuration) http.Handler {
+// The weights are only used when the runtime weights API is enabled.
+func NewBuilder(staticConfig static.Configuration, weights WeightSetter) func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).createRouter()
+		handler := New(staticConfig, configuration)
+		handler.weights = weights
+		return handler.createRouter()
 	}
 }
 
@@ -76,6 +86,8 @@ func New(staticConfig static.Configuration, runtimeConfig *runtime.Configuration
 		runtimeConfiguration: rConfig,
 		staticConfig:         staticConfig,
 		debug:                staticConfig.API.Debug,
+		federation:           newFederation(staticConfig.API.Federation),
+		issues:               label.GetIssueRegistry(),
 	}
 }
 
@@ -85,6 +97,7 @@ func (h Handler) createRouter() *mux.Router {
 
 	if h.debug {
 		DebugHandler{}.Append(router)
+		router.Methods(http.MethodGet).Path("/api/debug/route").HandlerFunc(h.getDebugRoute)
 	}
 
 	router.Methods(http.MethodGet).Path("/api/rawdata").HandlerFunc(h.getRuntimeConfiguration)
@@ -92,13 +105,23 @@ func (h Handler) createRouter() *mux.Router {
 	// Experimental endpoint
 	router.Methods(http.MethodGet).Path("/api/overview").HandlerFunc(h.getOverview)
 
+	router.Methods(http.MethodGet).Path("/api/issues").HandlerFunc(h.getIssues)
+
 	router.Methods(http.MethodGet).Path("/api/entrypoints").HandlerFunc(h.getEntryPoints)
 	router.Methods(http.MethodGet).Path("/api/entrypoints/{entryPointID}").HandlerFunc(h.getEntryPoint)
 
 	router.Methods(http.MethodGet).Path("/api/http/routers").HandlerFunc(h.getRouters)
 	router.Methods(http.MethodGet).Path("/api/http/routers/{routerID}").HandlerFunc(h.getRouter)
+	router.Methods(http.MethodGet).Path("/api/http/conflicts").HandlerFunc(h.getRouterConflicts)
 	router.Methods(http.MethodGet).Path("/api/http/services").HandlerFunc(h.getServices)
 	router.Methods(http.MethodGet).Path("/api/http/services/{serviceID}").HandlerFunc(h.getService)
+	if h.staticConfig.API.Weights != nil && h.weights != nil {
+		router.Methods(http.MethodPut).Path("/api/http/services/{serviceID}/weights").HandlerFunc(h.updateServiceWeights)
+		router.Methods(http.MethodDelete).Path("/api/http/services/{serviceID}/weights").HandlerFunc(h.clearServiceWeights)
+		router.Methods(http.MethodGet).Path("/api/http/services/{serviceID}/trafficsplit").HandlerFunc(h.getTrafficSplit)
+		router.Methods(http.MethodPut).Path("/api/http/services/{serviceID}/trafficsplit").HandlerFunc(h.updateTrafficSplit)
+		router.Methods(http.MethodPost).PathPrefix("/api.trafficsplit.TrafficSplit/").Handler(newTrafficSplitHandler(h))
+	}
 	router.Methods(http.MethodGet).Path("/api/http/middlewares").HandlerFunc(h.getMiddlewares)
 	router.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}").HandlerFunc(h.getMiddleware)
 
@@ -112,6 +135,11 @@ func (h Handler) createRouter() *mux.Router {
 	router.Methods(http.MethodGet).Path("/api/udp/services").HandlerFunc(h.getUDPServices)
 	router.Methods(http.MethodGet).Path("/api/udp/services/{serviceID}").HandlerFunc(h.getUDPService)
 
+	if h.federation != nil {
+		router.Methods(http.MethodGet).Path("/api/federation/instances").HandlerFunc(h.getFederatedInstances)
+		router.Methods(http.MethodGet).Path("/api/federation/{protocol}/{kind}").HandlerFunc(h.getFederatedResources)
+	}
+
 	version.Handler{}.Append(router)
 
 	if h.dashboard {
@@ -138,6 +166,8 @@ func (h Handler) getRuntimeConfiguration(rw http.ResponseWriter, request *http.R
 		TCPServices: h.runtimeConfiguration.TCPServices,
 		UDPRouters:  h.runtimeConfiguration.UDPRouters,
 		UDPServices: h.runtimeConfiguration.UDPServices,
+
+		Deprecations: h.runtimeConfiguration.Deprecations,
 	}
 
 	rw.Header().Set("Content-Type", "application/json")
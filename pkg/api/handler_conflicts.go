@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/rules"
+)
+
+type conflictingRouterRepresentation struct {
+	Name              string `json:"name"`
+	Rule              string `json:"rule"`
+	SuggestedPriority int    `json:"suggestedPriority"`
+}
+
+type routerConflictRepresentation struct {
+	EntryPoints []string                          `json:"entryPoints"`
+	Priority    int                               `json:"priority"`
+	Routers     []conflictingRouterRepresentation `json:"routers"`
+}
+
+// getRouterConflicts returns the pairs of HTTP routers of an entry point having the same priority,
+// and whose rules could match the same requests, which are then routed to either of them.
+// The more specific router of each pair is suggested a higher priority.
+func (h Handler) getRouterConflicts(rw http.ResponseWriter, request *http.Request) {
+	results := h.findRouterConflicts()
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) findRouterConflicts() []routerConflictRepresentation {
+	type group struct {
+		entryPoint string
+		tls        bool
+		priority   int
+	}
+
+	groups := make(map[group][]string)
+	for name, rt := range h.runtimeConfiguration.Routers {
+		if rt.Status == runtime.StatusDisabled {
+			continue
+		}
+
+		for _, entryPoint := range rt.Using {
+			key := group{entryPoint: entryPoint, tls: rt.TLS != nil, priority: h.routerPriority(rt)}
+			groups[key] = append(groups[key], name)
+		}
+	}
+
+	conflicts := make(map[[2]string]*routerConflictRepresentation)
+	for key, names := range groups {
+		sort.Strings(names)
+
+		for i, nameA := range names {
+			for _, nameB := range names[i+1:] {
+				pair := [2]string{nameA, nameB}
+				if conflict, ok := conflicts[pair]; ok {
+					conflict.EntryPoints = append(conflict.EntryPoints, key.entryPoint)
+					continue
+				}
+
+				overlap, err := rules.Overlap(h.runtimeConfiguration.Routers[nameA].Rule, h.runtimeConfiguration.Routers[nameB].Rule)
+				if err != nil || !overlap {
+					continue
+				}
+
+				conflicts[pair] = h.newRouterConflict(key.entryPoint, key.priority, nameA, nameB)
+			}
+		}
+	}
+
+	results := make([]routerConflictRepresentation, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		sort.Strings(conflict.EntryPoints)
+		results = append(results, *conflict)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Priority != results[j].Priority {
+			return results[i].Priority > results[j].Priority
+		}
+		if results[i].Routers[0].Name != results[j].Routers[0].Name {
+			return results[i].Routers[0].Name < results[j].Routers[0].Name
+		}
+		return results[i].Routers[1].Name < results[j].Routers[1].Name
+	})
+
+	return results
+}
+
+// newRouterConflict returns the conflict between two routers, the more specific one being first,
+// and being suggested a higher priority.
+func (h Handler) newRouterConflict(entryPoint string, priority int, nameA, nameB string) *routerConflictRepresentation {
+	ruleA := h.runtimeConfiguration.Routers[nameA].Rule
+	ruleB := h.runtimeConfiguration.Routers[nameB].Rule
+
+	specificityA, _ := rules.Specificity(ruleA)
+	specificityB, _ := rules.Specificity(ruleB)
+
+	if specificityB > specificityA {
+		nameA, nameB = nameB, nameA
+		ruleA, ruleB = ruleB, ruleA
+	}
+
+	return &routerConflictRepresentation{
+		EntryPoints: []string{entryPoint},
+		Priority:    priority,
+		Routers: []conflictingRouterRepresentation{
+			{Name: nameA, Rule: ruleA, SuggestedPriority: priority + 1},
+			{Name: nameB, Rule: ruleB, SuggestedPriority: priority},
+		},
+	}
+}
+
+// routerPriority returns the priority of the route of the router, as computed by the router manager.
+func (h Handler) routerPriority(rt *runtime.RouterInfo) int {
+	if rt.Priority != 0 {
+		return rt.Priority
+	}
+
+	if h.staticConfig.Experimental != nil && h.staticConfig.Experimental.AutoPriority {
+		if specificity, err := rules.Specificity(rt.Rule); err == nil {
+			return specificity
+		}
+	}
+
+	return len(rt.Rule)
+}
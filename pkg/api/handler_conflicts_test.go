@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_RouterConflicts(t *testing.T) {
+	newRouter := func(rule string, priority int, entryPoints ...string) *runtime.RouterInfo {
+		return &runtime.RouterInfo{
+			Router: &dynamic.Router{
+				EntryPoints: entryPoints,
+				Service:     "foo-service@myprovider",
+				Rule:        rule,
+				Priority:    priority,
+			},
+			Status: runtime.StatusEnabled,
+			Using:  entryPoints,
+		}
+	}
+
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			// Host(`foo.bar`) && Path(`/a`) and PathPrefix(`/`) || Path(`/b`) have the same length.
+			"foo@myprovider":   newRouter("Host(`foo.bar`) && Path(`/a`)", 0, "web", "websecure"),
+			"bar@myprovider":   newRouter("PathPrefix(`/`) || Path(`/b`)", 0, "web", "websecure"),
+			"baz@myprovider":   newRouter("Host(`baz.foo`) && Path(`/a`)", 0, "web"),
+			"qux@myprovider":   newRouter("PathPrefix(`/qux`)", 10, "web"),
+			"quux@myprovider":  newRouter("PathPrefix(`/quux`)", 10, "web"),
+			"other@myprovider": newRouter("PathPrefix(`/`) || Path(`/b`)", 0, "other"),
+		},
+	}
+
+	server := httptest.NewServer(New(static.Configuration{API: &static.API{}}, rtConf).createRouter())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/http/conflicts")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(nextPageHeader))
+
+	var conflicts []routerConflictRepresentation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&conflicts))
+
+	expected := []routerConflictRepresentation{
+		{
+			EntryPoints: []string{"web"},
+			Priority:    29,
+			Routers: []conflictingRouterRepresentation{
+				{Name: "baz@myprovider", Rule: "Host(`baz.foo`) && Path(`/a`)", SuggestedPriority: 30},
+				{Name: "bar@myprovider", Rule: "PathPrefix(`/`) || Path(`/b`)", SuggestedPriority: 29},
+			},
+		},
+		{
+			EntryPoints: []string{"web", "websecure"},
+			Priority:    29,
+			Routers: []conflictingRouterRepresentation{
+				{Name: "foo@myprovider", Rule: "Host(`foo.bar`) && Path(`/a`)", SuggestedPriority: 30},
+				{Name: "bar@myprovider", Rule: "PathPrefix(`/`) || Path(`/b`)", SuggestedPriority: 29},
+			},
+		},
+	}
+	assert.Equal(t, expected, conflicts)
+}
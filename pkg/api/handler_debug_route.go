@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/v2/pkg/rules"
+)
+
+// Outcomes of the routers considered for a request.
+const (
+	routeMatched = "matched"
+	routeSkipped = "skipped"
+)
+
+type routeCandidateRepresentation struct {
+	Name     string `json:"name"`
+	Rule     string `json:"rule"`
+	Priority int    `json:"priority"`
+	Result   string `json:"result"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type routeRepresentation struct {
+	Method     string                         `json:"method"`
+	URL        string                         `json:"url"`
+	EntryPoint string                         `json:"entryPoint,omitempty"`
+	Router     string                         `json:"router,omitempty"`
+	Routers    []routeCandidateRepresentation `json:"routers"`
+}
+
+// getDebugRoute explains which router would handle a request, without sending it.
+// The HTTP routers are considered by decreasing priority, as they are by the entry points,
+// and the reason why each of them was skipped is reported.
+func (h Handler) getDebugRoute(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	query := request.URL.Query()
+
+	method := query.Get("method")
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	target, err := url.Parse(query.Get("url"))
+	if err != nil || !target.IsAbs() || target.Host == "" {
+		writeError(rw, fmt.Sprintf("invalid url %q: an absolute URL is expected", query.Get("url")), http.StatusBadRequest)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), method, target.String(), nil)
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := routeRepresentation{
+		Method:     method,
+		URL:        target.String(),
+		EntryPoint: query.Get("entryPoint"),
+		Routers:    make([]routeCandidateRepresentation, 0),
+	}
+
+	requestdecorator.New(nil).ServeHTTP(rw, req, func(_ http.ResponseWriter, req *http.Request) {
+		h.explainRoute(req, &result)
+	})
+
+	err = json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// explainRoute fills result with the routers considered for the request.
+func (h Handler) explainRoute(req *http.Request, result *routeRepresentation) {
+	for name, rt := range h.runtimeConfiguration.Routers {
+		candidate := routeCandidateRepresentation{
+			Name:     name,
+			Rule:     rt.Rule,
+			Priority: h.routerPriority(rt),
+			Result:   routeSkipped,
+		}
+
+		switch {
+		case rt.Status == runtime.StatusDisabled:
+			candidate.Reason = "the router is disabled"
+		case result.EntryPoint != "" && !usesEntryPoint(rt, result.EntryPoint):
+			candidate.Reason = fmt.Sprintf("the router is not used on the entry point %s", result.EntryPoint)
+		case (req.URL.Scheme == "https") != (rt.TLS != nil):
+			candidate.Reason = "the router does not handle the " + req.URL.Scheme + " requests"
+		default:
+			match, err := rules.Match(rt.Rule, req)
+			if err != nil {
+				candidate.Reason = err.Error()
+			} else if !match {
+				candidate.Reason = "the rule does not match the request"
+			} else {
+				candidate.Result = routeMatched
+			}
+		}
+
+		result.Routers = append(result.Routers, candidate)
+	}
+
+	sort.SliceStable(result.Routers, func(i, j int) bool {
+		if result.Routers[i].Priority != result.Routers[j].Priority {
+			return result.Routers[i].Priority > result.Routers[j].Priority
+		}
+		return result.Routers[i].Name < result.Routers[j].Name
+	})
+
+	for i, candidate := range result.Routers {
+		if candidate.Result != routeMatched {
+			continue
+		}
+
+		if result.Router == "" {
+			result.Router = candidate.Name
+			continue
+		}
+
+		result.Routers[i].Result = routeSkipped
+		result.Routers[i].Reason = fmt.Sprintf("the router %s has a higher priority", result.Router)
+	}
+}
+
+func usesEntryPoint(rt *runtime.RouterInfo, entryPoint string) bool {
+	for _, name := range rt.Using {
+		if name == entryPoint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_DebugRoute(t *testing.T) {
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"api@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Service:     "api-service@myprovider",
+					Rule:        "Host(`foo.bar`) && PathPrefix(`/api`)",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"catchall@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Service:     "web-service@myprovider",
+					Rule:        "Host(`foo.bar`)",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"admin@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Service:     "admin-service@myprovider",
+					Rule:        "Host(`foo.bar`) && Method(`POST`)",
+					Priority:    1000,
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"web"},
+			},
+			"internal@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"internal"},
+					Service:     "api-service@myprovider",
+					Rule:        "PathPrefix(`/`)",
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"internal"},
+			},
+			"secure@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"websecure"},
+					Service:     "api-service@myprovider",
+					Rule:        "Host(`foo.bar`)",
+					TLS:         &dynamic.RouterTLSConfig{},
+				},
+				Status: runtime.StatusEnabled,
+				Using:  []string{"websecure"},
+			},
+			"broken@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Service:     "api-service@myprovider",
+					Rule:        "Host(`foo.bar`",
+				},
+				Status: runtime.StatusDisabled,
+			},
+		},
+	}
+
+	server := httptest.NewServer(New(static.Configuration{API: &static.API{Debug: true}}, rtConf).createRouter())
+	t.Cleanup(server.Close)
+
+	query := url.Values{}
+	query.Set("url", "http://foo.bar/api/users")
+	query.Set("entryPoint", "web")
+
+	resp, err := http.Get(server.URL + "/api/debug/route?" + query.Encode())
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result routeRepresentation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	expected := routeRepresentation{
+		Method:     http.MethodGet,
+		URL:        "http://foo.bar/api/users",
+		EntryPoint: "web",
+		Router:     "api@myprovider",
+		Routers: []routeCandidateRepresentation{
+			{
+				Name:     "admin@myprovider",
+				Rule:     "Host(`foo.bar`) && Method(`POST`)",
+				Priority: 1000,
+				Result:   routeSkipped,
+				Reason:   "the rule does not match the request",
+			},
+			{
+				Name:     "api@myprovider",
+				Rule:     "Host(`foo.bar`) && PathPrefix(`/api`)",
+				Priority: 37,
+				Result:   routeMatched,
+			},
+			{
+				Name:     "catchall@myprovider",
+				Rule:     "Host(`foo.bar`)",
+				Priority: 15,
+				Result:   routeSkipped,
+				Reason:   "the router api@myprovider has a higher priority",
+			},
+			{
+				Name:     "internal@myprovider",
+				Rule:     "PathPrefix(`/`)",
+				Priority: 15,
+				Result:   routeSkipped,
+				Reason:   "the router is not used on the entry point web",
+			},
+			{
+				Name:     "secure@myprovider",
+				Rule:     "Host(`foo.bar`)",
+				Priority: 15,
+				Result:   routeSkipped,
+				Reason:   "the router is not used on the entry point web",
+			},
+			{
+				Name:     "broken@myprovider",
+				Rule:     "Host(`foo.bar`",
+				Priority: 14,
+				Result:   routeSkipped,
+				Reason:   "the router is disabled",
+			},
+		},
+	}
+	assert.Equal(t, expected, result)
+}
+
+func TestHandler_DebugRoute_invalidURL(t *testing.T) {
+	server := httptest.NewServer(New(static.Configuration{API: &static.API{Debug: true}}, &runtime.Configuration{}).createRouter())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/debug/route?url=/api/users")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_DebugRoute_disabled(t *testing.T) {
+	server := httptest.NewServer(New(static.Configuration{API: &static.API{}}, &runtime.Configuration{}).createRouter())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/debug/route?url=http://foo.bar/")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
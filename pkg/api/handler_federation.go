@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/gorilla/mux"
+)
+
+// maxFederatedResponseSize is the size of the largest response read from the API of a federated instance.
+const maxFederatedResponseSize = 10 * 1024 * 1024
+
+// Statuses of the federated instances.
+const (
+	federatedInstanceUp   = "up"
+	federatedInstanceDown = "down"
+)
+
+// federatedResourcePaths are the API paths of the resource lists, keyed by protocol and kind, that can be federated.
+var federatedResourcePaths = map[string]map[string]string{
+	"http": {"routers": "/api/http/routers", "services": "/api/http/services", "middlewares": "/api/http/middlewares"},
+	"tcp":  {"routers": "/api/tcp/routers", "services": "/api/tcp/services"},
+	"udp":  {"routers": "/api/udp/routers", "services": "/api/udp/services"},
+}
+
+type federatedInstanceRepresentation struct {
+	Name     string          `json:"name"`
+	URL      string          `json:"url"`
+	Status   string          `json:"status"`
+	Error    string          `json:"error,omitempty"`
+	Overview json.RawMessage `json:"overview,omitempty"`
+}
+
+// federation fetches the API of the federated instances.
+type federation struct {
+	instances map[string]*static.FederatedInstance
+	client    *http.Client
+}
+
+func newFederation(config *static.FederationAPI) *federation {
+	if config == nil {
+		return nil
+	}
+
+	return &federation{
+		instances: config.Instances,
+		client:    &http.Client{Timeout: time.Duration(config.Timeout)},
+	}
+}
+
+// getFederatedInstances returns the status and the overview of each federated instance.
+func (h Handler) getFederatedInstances(rw http.ResponseWriter, request *http.Request) {
+	results := make([]federatedInstanceRepresentation, 0, len(h.federation.instances))
+
+	var mu sync.Mutex
+	h.federation.forEach(func(name string, instance *static.FederatedInstance) {
+		result := federatedInstanceRepresentation{Name: name, URL: instance.URL, Status: federatedInstanceUp}
+
+		var overview json.RawMessage
+		if _, err := h.federation.get(request.Context(), instance, "/api/overview", nil, &overview); err != nil {
+			result.Status = federatedInstanceDown
+			result.Error = err.Error()
+		} else {
+			result.Overview = overview
+		}
+
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(results)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getFederatedResources returns the resources of all the federated instances,
+// each resource being labeled with the name of its instance.
+// The instances whose API cannot be fetched are skipped, their status being reported by getFederatedInstances.
+func (h Handler) getFederatedResources(rw http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	path, ok := federatedResourcePaths[vars["protocol"]][vars["kind"]]
+	if !ok {
+		writeError(rw, fmt.Sprintf("unknown resources: %s %s", vars["protocol"], vars["kind"]), http.StatusNotFound)
+		return
+	}
+
+	query := url.Values{}
+	for _, key := range []string{"search", "status"} {
+		if value := request.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	results := make([]map[string]interface{}, 0)
+
+	var mu sync.Mutex
+	h.federation.forEach(func(name string, instance *static.FederatedInstance) {
+		resources, err := h.federation.getAll(request.Context(), instance, path, query)
+		if err != nil {
+			log.FromContext(request.Context()).Warnf("Unable to fetch the %s of the federated instance %s: %v", path, name, err)
+			return
+		}
+
+		for _, resource := range resources {
+			resource["instance"] = name
+		}
+
+		mu.Lock()
+		results = append(results, resources...)
+		mu.Unlock()
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		nameI, _ := results[i]["name"].(string)
+		nameJ, _ := results[j]["name"].(string)
+		if nameI != nameJ {
+			return nameI < nameJ
+		}
+		return results[i]["instance"].(string) < results[j]["instance"].(string)
+	})
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// forEach calls fn concurrently for each federated instance, and waits for all the calls to return.
+func (f *federation) forEach(fn func(name string, instance *static.FederatedInstance)) {
+	var wg sync.WaitGroup
+
+	for name, instance := range f.instances {
+		wg.Add(1)
+
+		go func(name string, instance *static.FederatedInstance) {
+			defer wg.Done()
+			fn(name, instance)
+		}(name, instance)
+	}
+
+	wg.Wait()
+}
+
+// getAll returns the resources of all the pages of the resource list at path.
+func (f *federation) getAll(ctx context.Context, instance *static.FederatedInstance, path string, query url.Values) ([]map[string]interface{}, error) {
+	var resources []map[string]interface{}
+
+	for page := 1; ; page++ {
+		pageQuery := url.Values{}
+		for key, values := range query {
+			pageQuery[key] = values
+		}
+		pageQuery.Set("page", strconv.Itoa(page))
+		pageQuery.Set("per_page", strconv.Itoa(defaultPerPage))
+
+		var pageResources []map[string]interface{}
+		header, err := f.get(ctx, instance, path, pageQuery, &pageResources)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, pageResources...)
+
+		// The next page is 1 on the last page.
+		nextPage, err := strconv.Atoi(header.Get(nextPageHeader))
+		if err != nil || nextPage <= page {
+			return resources, nil
+		}
+	}
+}
+
+// get decodes into result the JSON response of the API of the instance at path, and returns the response headers.
+func (f *federation) get(ctx context.Context, instance *static.FederatedInstance, path string, query url.Values, result interface{}) (http.Header, error) {
+	u, err := url.Parse(strings.TrimSuffix(instance.URL, "/") + path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if instance.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+instance.Token)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body := io.LimitReader(resp.Body, maxFederatedResponseSize)
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(ioutil.Discard, body)
+		return nil, fmt.Errorf("unexpected status code %d on %s", resp.StatusCode, path)
+	}
+
+	if err = json.NewDecoder(body).Decode(result); err != nil {
+		return nil, fmt.Errorf("unable to decode the response on %s: %w", path, err)
+	}
+
+	return resp.Header, nil
+}
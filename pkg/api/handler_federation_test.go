@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestHandler_Federation(t *testing.T) {
+	edge1 := newFederatedServer(t, "", "foo@myprovider", "bar@myprovider")
+	edge2 := newFederatedServer(t, "secret", "foo@myprovider")
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	config := static.Configuration{API: &static.API{
+		Federation: &static.FederationAPI{
+			Instances: map[string]*static.FederatedInstance{
+				"edge1": {URL: edge1.URL},
+				"edge2": {URL: edge2.URL + "/", Token: "secret"},
+				"down":  {URL: down.URL},
+			},
+			Timeout: ptypes.Duration(time.Second),
+		},
+	}}
+
+	server := httptest.NewServer(New(config, &runtime.Configuration{}).createRouter())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/federation/http/routers")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(nextPageHeader))
+
+	var routers []struct {
+		Name     string `json:"name"`
+		Instance string `json:"instance"`
+		Rule     string `json:"rule"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&routers))
+
+	expectedRouters := []struct {
+		Name     string `json:"name"`
+		Instance string `json:"instance"`
+		Rule     string `json:"rule"`
+	}{
+		{Name: "bar@myprovider", Instance: "edge1", Rule: "Host(`bar.localhost`)"},
+		{Name: "foo@myprovider", Instance: "edge1", Rule: "Host(`foo.localhost`)"},
+		{Name: "foo@myprovider", Instance: "edge2", Rule: "Host(`foo.localhost`)"},
+	}
+	assert.Equal(t, expectedRouters, routers)
+
+	resp, err = http.Get(server.URL + "/api/federation/instances")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var instances []federatedInstanceRepresentation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&instances))
+	require.Len(t, instances, 3)
+
+	assert.Equal(t, "down", instances[0].Name)
+	assert.Equal(t, federatedInstanceDown, instances[0].Status)
+	assert.NotEmpty(t, instances[0].Error)
+	assert.Empty(t, instances[0].Overview)
+
+	assert.Equal(t, "edge1", instances[1].Name)
+	assert.Equal(t, federatedInstanceUp, instances[1].Status)
+	assert.NotEmpty(t, instances[1].Overview)
+
+	assert.Equal(t, "edge2", instances[2].Name)
+	assert.Equal(t, federatedInstanceUp, instances[2].Status)
+
+	resp, err = http.Get(server.URL + "/api/federation/http/foo")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandler_Federation_disabled(t *testing.T) {
+	server := httptest.NewServer(New(static.Configuration{API: &static.API{}}, &runtime.Configuration{}).createRouter())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/federation/http/routers")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// newFederatedServer returns a server serving the API of an instance having the given routers,
+// and requiring the token, if any.
+func newFederatedServer(t *testing.T, token string, routers ...string) *httptest.Server {
+	t.Helper()
+
+	rtConf := &runtime.Configuration{Routers: map[string]*runtime.RouterInfo{}}
+	for _, name := range routers {
+		rtConf.Routers[name] = &runtime.RouterInfo{
+			Router: &dynamic.Router{
+				EntryPoints: []string{"web"},
+				Service:     "foo-service@myprovider",
+				Rule:        "Host(`" + name[:3] + ".localhost`)",
+			},
+			Status: runtime.StatusEnabled,
+		}
+	}
+
+	handler := New(static.Configuration{API: &static.API{}}, rtConf).createRouter()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if token != "" && req.Header.Get("Authorization") != "Bearer "+token {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
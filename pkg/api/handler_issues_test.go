@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/label"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Issues(t *testing.T) {
+	issue := label.ProviderIssue{
+		Provider: "docker",
+		Element:  "foo",
+		Issue: label.Issue{
+			Code:       label.CodeMisspelledKey,
+			Key:        "traefik.http.routers.foo.rul",
+			Message:    `unknown option "rul", did you mean "rule"?`,
+			Suggestion: "traefik.http.routers.foo.rule",
+		},
+	}
+
+	handler := New(static.Configuration{API: &static.API{}}, nil)
+	handler.issues = label.NewIssueRegistry()
+	handler.issues.Set("docker", []label.ProviderIssue{issue})
+
+	server := httptest.NewServer(handler.createRouter())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/api/issues")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var issues []label.ProviderIssue
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&issues))
+
+	assert.Equal(t, []label.ProviderIssue{issue}, issues)
+}
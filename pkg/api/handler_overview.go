@@ -35,6 +35,14 @@ type overview struct {
 	UDP       schemeOverview `json:"udp"`
 	Features  features       `json:"features,omitempty"`
 	Providers []string       `json:"providers,omitempty"`
+	Instance  *instance      `json:"instance,omitempty"`
+}
+
+type instance struct {
+	Cluster     string   `json:"cluster,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	Color       string   `json:"color,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
 }
 
 func (h Handler) getOverview(rw http.ResponseWriter, request *http.Request) {
@@ -54,6 +62,7 @@ func (h Handler) getOverview(rw http.ResponseWriter, request *http.Request) {
 		},
 		Features:  getFeatures(h.staticConfig),
 		Providers: getProviders(h.staticConfig),
+		Instance:  getInstance(h.staticConfig),
 	}
 
 	rw.Header().Set("Content-Type", "application/json")
@@ -261,3 +270,16 @@ func getTracing(conf static.Configuration) string {
 
 	return ""
 }
+
+func getInstance(conf static.Configuration) *instance {
+	if conf.Instance == nil {
+		return nil
+	}
+
+	return &instance{
+		Cluster:     conf.Instance.Cluster,
+		Environment: conf.Instance.Environment,
+		Color:       conf.Instance.Color,
+		Tags:        conf.Instance.Tags,
+	}
+}
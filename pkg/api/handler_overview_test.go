@@ -243,6 +243,25 @@ func TestHandler_Overview(t *testing.T) {
 				jsonFile:   "testdata/overview-features.json",
 			},
 		},
+		{
+			desc: "with instance",
+			path: "/api/overview",
+			confStatic: static.Configuration{
+				Global: &static.Global{},
+				API:    &static.API{},
+				Instance: &static.Instance{
+					Cluster:     "eu-west",
+					Environment: "production",
+					Color:       "#cc0000",
+					Tags:        []string{"critical"},
+				},
+			},
+			confDyn: runtime.Configuration{},
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/overview-instance.json",
+			},
+		},
 	}
 
 	for _, test := range testCases {
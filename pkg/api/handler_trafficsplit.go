@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/api/trafficsplit"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// trafficSplitServer serves the traffic split of the weighted services over gRPC,
+// for the progressive delivery tools driving the canaries with gRPC rather than with the HTTP endpoints.
+type trafficSplitServer struct {
+	handler Handler
+}
+
+// newTrafficSplitHandler returns the handler of the gRPC traffic split API,
+// which requires HTTP/2, either over TLS or in clear text (h2c).
+func newTrafficSplitHandler(h Handler) http.Handler {
+	server := grpc.NewServer()
+	trafficsplit.RegisterTrafficSplitServer(server, trafficSplitServer{handler: h})
+	return server
+}
+
+// Get returns the weights and the traffic statistics of the children of a weighted service.
+func (s trafficSplitServer) Get(ctx context.Context, req *trafficsplit.GetRequest) (*trafficsplit.Split, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.handler.trafficSplit(req.GetService())
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	return toSplit(statuses), nil
+}
+
+// SetCanaryWeight gives a percentage of the traffic of a weighted service to the canary.
+func (s trafficSplitServer) SetCanaryWeight(ctx context.Context, req *trafficsplit.SetCanaryWeightRequest) (*trafficsplit.Split, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	err := s.handler.setCanaryWeight(ctx, req.GetService(), req.GetCanary(), int(req.GetWeight()))
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+
+	return s.Get(ctx, &trafficsplit.GetRequest{Service: req.GetService()})
+}
+
+// authorize checks the token carried by the authorization metadata, like the Authorization header of the HTTP endpoints.
+func (s trafficSplitServer) authorize(ctx context.Context) error {
+	var auth string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			auth = values[0]
+		}
+	}
+
+	if !s.handler.isWeightsAuthorization(auth) {
+		return status.Error(codes.Unauthenticated, http.StatusText(http.StatusUnauthorized))
+	}
+
+	return nil
+}
+
+func toSplit(statuses []wrr.ServiceStatus) *trafficsplit.Split {
+	split := &trafficsplit.Split{}
+	for _, s := range statuses {
+		split.Services = append(split.Services, &trafficsplit.ServiceStatus{
+			Name:             s.Name,
+			Weight:           int32(s.Weight),
+			ConfiguredWeight: int32(s.ConfiguredWeight),
+			Overridden:       s.Overridden,
+			Requests:         s.Requests,
+			Errors:           s.Errors,
+			AverageDuration:  s.AverageDuration,
+		})
+	}
+	return split
+}
+
+// toGRPCError converts an error of the weights API to the gRPC status matching its HTTP status code.
+func toGRPCError(err error) error {
+	var wErr weightsError
+	if !errors.As(err, &wErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch wErr.statusCode {
+	case http.StatusBadRequest:
+		return status.Error(codes.InvalidArgument, wErr.msg)
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, wErr.msg)
+	case http.StatusConflict:
+		return status.Error(codes.FailedPrecondition, wErr.msg)
+	default:
+		return status.Error(codes.Internal, wErr.msg)
+	}
+}
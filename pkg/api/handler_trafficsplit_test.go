@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/api/trafficsplit"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTrafficSplitServer(t *testing.T) {
+	rtConf := &runtime.Configuration{
+		Services: map[string]*runtime.ServiceInfo{
+			"canary@myprovider": {
+				Service: &dynamic.Service{
+					Weighted: &dynamic.WeightedRoundRobin{
+						Services: []dynamic.WRRService{{Name: "v1"}, {Name: "v2"}},
+					},
+				},
+			},
+		},
+	}
+
+	weights := weightSetterMock{}
+	staticConfig := static.Configuration{
+		API:    &static.API{Weights: &static.WeightsAPI{Token: "secret"}},
+		Global: &static.Global{},
+	}
+
+	server := httptest.NewUnstartedServer(NewBuilder(staticConfig, weights)(rtConf))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	creds := credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	conn, err := grpc.Dial(strings.TrimPrefix(server.URL, "https://"), grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	client := trafficsplit.NewTrafficSplitClient(conn)
+
+	_, err = client.Get(context.Background(), &trafficsplit.GetRequest{Service: "canary@myprovider"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+
+	split, err := client.SetCanaryWeight(ctx, &trafficsplit.SetCanaryWeightRequest{Service: "canary@myprovider", Canary: "v2", Weight: 20})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"v1": 80, "v2": 20}, map[string]int(weights))
+
+	require.Len(t, split.GetServices(), 2)
+	assert.Equal(t, "v1", split.GetServices()[0].GetName())
+	assert.Equal(t, int32(80), split.GetServices()[0].GetWeight())
+	assert.Equal(t, uint64(10), split.GetServices()[0].GetRequests())
+	assert.Equal(t, "v2", split.GetServices()[1].GetName())
+	assert.Equal(t, int32(20), split.GetServices()[1].GetWeight())
+
+	_, err = client.SetCanaryWeight(ctx, &trafficsplit.SetCanaryWeightRequest{Service: "canary@myprovider", Canary: "v3", Weight: 20})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.SetCanaryWeight(ctx, &trafficsplit.SetCanaryWeightRequest{Service: "canary@myprovider", Canary: "v2", Weight: 120})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Get(ctx, &trafficsplit.GetRequest{Service: "nope@myprovider"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
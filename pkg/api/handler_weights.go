@@ -0,0 +1,421 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
+	"github.com/gorilla/mux"
+)
+
+// WeightSetter updates at runtime the weights of the children of a weighted service.
+type WeightSetter interface {
+	SetWeights(serviceName string, weights map[string]int) error
+	ClearWeights(serviceName string) error
+	Status(serviceName string) ([]wrr.ServiceStatus, error)
+}
+
+type weightsRepresentation struct {
+	Services []dynamic.WRRService `json:"services"`
+}
+
+type trafficSplitRepresentation struct {
+	Services []wrr.ServiceStatus `json:"services"`
+}
+
+// trafficSplitUpdate is the traffic split requested by the progressive delivery tools:
+// the canary gets weight percents of the traffic, and the other services share the rest.
+type trafficSplitUpdate struct {
+	Canary string `json:"canary"`
+	Weight int    `json:"weight"`
+}
+
+// weightsError is an error of the weights API, with the HTTP status code of its response.
+type weightsError struct {
+	statusCode int
+	msg        string
+}
+
+func (e weightsError) Error() string {
+	return e.msg
+}
+
+// writeWeightsError writes the response of an error of the weights API.
+func writeWeightsError(rw http.ResponseWriter, err error) {
+	var wErr weightsError
+	if errors.As(err, &wErr) {
+		writeError(rw, wErr.msg, wErr.statusCode)
+		return
+	}
+
+	writeError(rw, err.Error(), http.StatusInternalServerError)
+}
+
+func (h Handler) getTrafficSplit(rw http.ResponseWriter, request *http.Request) {
+	serviceID := mux.Vars(request)["serviceID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if !h.isWeightsRequestAuthorized(request) {
+		writeError(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	statuses, err := h.trafficSplit(serviceID)
+	if err != nil {
+		writeWeightsError(rw, err)
+		return
+	}
+
+	err = json.NewEncoder(rw).Encode(trafficSplitRepresentation{Services: statuses})
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) updateTrafficSplit(rw http.ResponseWriter, request *http.Request) {
+	serviceID := mux.Vars(request)["serviceID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if !h.isWeightsRequestAuthorized(request) {
+		writeError(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var update trafficSplitUpdate
+	if err := json.NewDecoder(request.Body).Decode(&update); err != nil {
+		writeError(rw, fmt.Sprintf("invalid traffic split: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.setCanaryWeight(request.Context(), serviceID, update.Canary, update.Weight); err != nil {
+		writeWeightsError(rw, err)
+		return
+	}
+
+	h.getTrafficSplit(rw, request)
+}
+
+// trafficSplit returns the current weights and the traffic statistics of the children of the weighted service serviceID.
+func (h Handler) trafficSplit(serviceID string) ([]wrr.ServiceStatus, error) {
+	if _, err := h.lookupWeightedService(serviceID); err != nil {
+		return nil, err
+	}
+
+	statuses, err := h.weights.Status(serviceID)
+	if err != nil {
+		return nil, weightsError{statusCode: http.StatusConflict, msg: err.Error()}
+	}
+
+	return statuses, nil
+}
+
+// setCanaryWeight gives weight percents of the traffic of the weighted service serviceID to its child canary,
+// the weights of all the children being changed at once.
+func (h Handler) setCanaryWeight(ctx context.Context, serviceID, canary string, weight int) error {
+	weighted, err := h.lookupWeightedService(serviceID)
+	if err != nil {
+		return err
+	}
+
+	if weight < 0 || weight > 100 {
+		return weightsError{statusCode: http.StatusBadRequest, msg: fmt.Sprintf("invalid canary weight %d: must be between 0 and 100", weight)}
+	}
+
+	canaryIndex, _ := findWRRService(weighted, serviceID, canary)
+	if canaryIndex < 0 {
+		return weightsError{statusCode: http.StatusNotFound, msg: fmt.Sprintf("service %s is not part of the weighted service %s", canary, serviceID)}
+	}
+
+	split := splitTraffic(weighted.Services, canaryIndex, weight)
+
+	weights := make(map[string]int, len(split))
+	for i, wrrService := range weighted.Services {
+		weights[wrrService.Name] = split[i]
+	}
+
+	if err := h.weights.SetWeights(serviceID, weights); err != nil {
+		return weightsError{statusCode: http.StatusConflict, msg: err.Error()}
+	}
+
+	for i, wrrService := range weighted.Services {
+		if err := h.persistWeight(serviceID, i, split[i]); err != nil {
+			log.FromContext(ctx).Errorf("Unable to persist the weight of %s in %s: %v", wrrService.Name, serviceID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitTraffic returns the weights giving canaryWeight percents of the traffic to the canary,
+// the other services sharing the rest in proportion to their configured weights.
+func splitTraffic(services []dynamic.WRRService, canaryIndex, canaryWeight int) []int {
+	weights := make([]int, len(services))
+	weights[canaryIndex] = canaryWeight
+
+	total := 0
+	shares := make([]int, len(services))
+	for i, wrrService := range services {
+		if i == canaryIndex {
+			continue
+		}
+
+		shares[i] = 1
+		if wrrService.Weight != nil {
+			shares[i] = *wrrService.Weight
+		}
+		if shares[i] < 0 {
+			// A negative weight is meaningless, the service is not scheduled like with a zero weight.
+			shares[i] = 0
+		}
+		total += shares[i]
+	}
+
+	if total == 0 {
+		// The stable services have no weight, they share the rest equally.
+		for i := range shares {
+			if i != canaryIndex {
+				shares[i] = 1
+				total++
+			}
+		}
+	}
+
+	if total == 0 {
+		return weights
+	}
+
+	// The remainder of the integer divisions goes to the first stable services, for the weights to sum to 100.
+	rest := 100 - canaryWeight
+	remainder := rest
+	for i := range services {
+		if i == canaryIndex {
+			continue
+		}
+
+		weights[i] = rest * shares[i] / total
+		remainder -= weights[i]
+	}
+
+	for i := range services {
+		if remainder == 0 {
+			break
+		}
+
+		if i != canaryIndex && shares[i] > 0 {
+			weights[i]++
+			remainder--
+		}
+	}
+
+	return weights
+}
+
+// getWeightedService returns the configuration of the weighted service serviceID,
+// or writes the error response if there is no such weighted service.
+func (h Handler) getWeightedService(rw http.ResponseWriter, serviceID string) (*dynamic.WeightedRoundRobin, bool) {
+	weighted, err := h.lookupWeightedService(serviceID)
+	if err != nil {
+		writeWeightsError(rw, err)
+		return nil, false
+	}
+
+	return weighted, true
+}
+
+// lookupWeightedService returns the configuration of the weighted service serviceID.
+func (h Handler) lookupWeightedService(serviceID string) (*dynamic.WeightedRoundRobin, error) {
+	service, ok := h.runtimeConfiguration.Services[serviceID]
+	if !ok {
+		return nil, weightsError{statusCode: http.StatusNotFound, msg: fmt.Sprintf("service not found: %s", serviceID)}
+	}
+
+	if service.Weighted == nil {
+		return nil, weightsError{statusCode: http.StatusBadRequest, msg: fmt.Sprintf("service %s is not a weighted service", serviceID)}
+	}
+
+	return service.Weighted, nil
+}
+
+func (h Handler) updateServiceWeights(rw http.ResponseWriter, request *http.Request) {
+	serviceID := mux.Vars(request)["serviceID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if !h.isWeightsRequestAuthorized(request) {
+		writeError(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	weighted, ok := h.getWeightedService(rw, serviceID)
+	if !ok {
+		return
+	}
+
+	var update weightsRepresentation
+	if err := json.NewDecoder(request.Body).Decode(&update); err != nil {
+		writeError(rw, fmt.Sprintf("invalid weights: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	type weightUpdate struct {
+		index  int
+		name   string
+		weight int
+	}
+
+	// All the updates are checked before applying any of them.
+	var updates []weightUpdate
+	for _, wrrService := range update.Services {
+		if wrrService.Weight == nil || *wrrService.Weight < 0 {
+			writeError(rw, fmt.Sprintf("invalid weight for service %s", wrrService.Name), http.StatusBadRequest)
+			return
+		}
+
+		index, name := findWRRService(weighted, serviceID, wrrService.Name)
+		if index < 0 {
+			writeError(rw, fmt.Sprintf("service %s is not part of the weighted service %s", wrrService.Name, serviceID), http.StatusNotFound)
+			return
+		}
+
+		updates = append(updates, weightUpdate{index: index, name: name, weight: *wrrService.Weight})
+	}
+
+	// The weights are all applied at once, for the requests to never be balanced with only some of them.
+	weights := make(map[string]int, len(updates))
+	for _, u := range updates {
+		weights[u.name] = u.weight
+	}
+
+	if err := h.weights.SetWeights(serviceID, weights); err != nil {
+		writeError(rw, err.Error(), http.StatusConflict)
+		return
+	}
+
+	for _, u := range updates {
+		if err := h.persistWeight(serviceID, u.index, u.weight); err != nil {
+			log.FromContext(request.Context()).Errorf("Unable to persist the weight of %s in %s: %v", u.name, serviceID, err)
+			writeError(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err := json.NewEncoder(rw).Encode(update)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) clearServiceWeights(rw http.ResponseWriter, request *http.Request) {
+	serviceID := mux.Vars(request)["serviceID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if !h.isWeightsRequestAuthorized(request) {
+		writeError(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if _, ok := h.getWeightedService(rw, serviceID); !ok {
+		return
+	}
+
+	if err := h.weights.ClearWeights(serviceID); err != nil {
+		writeError(rw, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.getTrafficSplit(rw, request)
+}
+
+func (h Handler) isWeightsRequestAuthorized(request *http.Request) bool {
+	return h.isWeightsAuthorization(request.Header.Get("Authorization"))
+}
+
+// isWeightsAuthorization checks the value of the Authorization header, or metadata, of a weights request.
+func (h Handler) isWeightsAuthorization(auth string) bool {
+	token := h.staticConfig.API.Weights.Token
+	if token == "" {
+		return true
+	}
+
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) == 1
+}
+
+// findWRRService returns the index and the configured name of the child service matching name,
+// which can be qualified with the provider name or not.
+func findWRRService(weighted *dynamic.WeightedRoundRobin, serviceID, name string) (int, string) {
+	providerName := getProviderName(serviceID)
+
+	for i, wrrService := range weighted.Services {
+		if wrrService.Name == name || qualifyName(wrrService.Name, providerName) == qualifyName(name, providerName) {
+			return i, wrrService.Name
+		}
+	}
+
+	return -1, ""
+}
+
+func qualifyName(name, providerName string) string {
+	if strings.Contains(name, "@") {
+		return name
+	}
+	return name + "@" + providerName
+}
+
+// persistWeight writes the weight into the KV store the weighted service comes from,
+// if this KV provider is configured as the weights storage.
+func (h Handler) persistWeight(serviceID string, index, weight int) error {
+	storage := h.staticConfig.API.Weights.Storage
+	if storage == "" || getProviderName(serviceID) != storage {
+		return nil
+	}
+
+	provider, err := h.weightsStorage(storage)
+	if err != nil {
+		return err
+	}
+
+	serviceName := strings.SplitN(serviceID, "@", 2)[0]
+	key := path.Join("http", "services", serviceName, "weighted", "services", strconv.Itoa(index), "weight")
+
+	return provider.Put(key, strconv.Itoa(weight))
+}
+
+func (h Handler) weightsStorage(storage string) (*kv.Provider, error) {
+	providers := h.staticConfig.Providers
+	if providers == nil {
+		return nil, errors.New("no provider configured")
+	}
+
+	switch {
+	case storage == "consul" && providers.Consul != nil:
+		return &providers.Consul.Provider, nil
+	case storage == "etcd" && providers.Etcd != nil:
+		return &providers.Etcd.Provider, nil
+	case storage == "redis" && providers.Redis != nil:
+		return &providers.Redis.Provider, nil
+	case storage == "zookeeper" && providers.ZooKeeper != nil:
+		return &providers.ZooKeeper.Provider, nil
+	default:
+		return nil, fmt.Errorf("the weights storage %q is not an enabled KV provider", storage)
+	}
+}
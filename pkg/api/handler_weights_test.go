@@ -0,0 +1,375 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type weightSetterMock map[string]int
+
+func Int(v int) *int { return &v }
+
+func (w weightSetterMock) SetWeight(serviceName, name string, weight int) error {
+	if serviceName != "canary@myprovider" && serviceName != "split@myprovider" {
+		return errors.New("not in use")
+	}
+	w[name] = weight
+	return nil
+}
+
+func (w weightSetterMock) SetWeights(serviceName string, weights map[string]int) error {
+	if serviceName != "canary@myprovider" && serviceName != "split@myprovider" {
+		return errors.New("not in use")
+	}
+	for name, weight := range weights {
+		w[name] = weight
+	}
+	return nil
+}
+
+func (w weightSetterMock) ClearWeights(serviceName string) error {
+	if serviceName != "canary@myprovider" && serviceName != "split@myprovider" {
+		return errors.New("not in use")
+	}
+	for name := range w {
+		delete(w, name)
+	}
+	return nil
+}
+
+func (w weightSetterMock) Status(serviceName string) ([]wrr.ServiceStatus, error) {
+	if serviceName != "canary@myprovider" && serviceName != "split@myprovider" {
+		return nil, errors.New("not in use")
+	}
+
+	var statuses []wrr.ServiceStatus
+	for _, name := range []string{"v1", "v2"} {
+		statuses = append(statuses, wrr.ServiceStatus{Name: name, Weight: w[name], Requests: 10})
+	}
+	return statuses, nil
+}
+
+func TestHandler_Weights(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		method     string
+		initial    map[string]int
+		path       string
+		body       string
+		token      string
+		header     string
+		statusCode int
+		expected   map[string]int
+	}{
+		{
+			desc:       "update weights",
+			path:       "/api/http/services/canary@myprovider/weights",
+			body:       `{"services":[{"name":"v1","weight":90},{"name":"v2@myprovider","weight":10}]}`,
+			statusCode: http.StatusOK,
+			expected:   map[string]int{"v1": 90, "v2": 10},
+		},
+		{
+			desc:       "with a valid token",
+			path:       "/api/http/services/canary@myprovider/weights",
+			body:       `{"services":[{"name":"v2","weight":0}]}`,
+			token:      "secret",
+			header:     "Bearer secret",
+			statusCode: http.StatusOK,
+			expected:   map[string]int{"v2": 0},
+		},
+		{
+			desc:       "with an invalid token",
+			path:       "/api/http/services/canary@myprovider/weights",
+			body:       `{"services":[{"name":"v2","weight":0}]}`,
+			token:      "secret",
+			header:     "Bearer foo",
+			statusCode: http.StatusUnauthorized,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "unknown child service",
+			path:       "/api/http/services/canary@myprovider/weights",
+			body:       `{"services":[{"name":"v3","weight":1}]}`,
+			statusCode: http.StatusNotFound,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "negative weight",
+			path:       "/api/http/services/canary@myprovider/weights",
+			body:       `{"services":[{"name":"v1","weight":-1}]}`,
+			statusCode: http.StatusBadRequest,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "clear the weights",
+			method:     http.MethodDelete,
+			initial:    map[string]int{"v1": 90, "v2": 10},
+			path:       "/api/http/services/canary@myprovider/weights",
+			statusCode: http.StatusOK,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "not a weighted service",
+			path:       "/api/http/services/v1@myprovider/weights",
+			body:       `{"services":[{"name":"v1","weight":1}]}`,
+			statusCode: http.StatusBadRequest,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "unknown service",
+			path:       "/api/http/services/nope@myprovider/weights",
+			body:       `{"services":[{"name":"v1","weight":1}]}`,
+			statusCode: http.StatusNotFound,
+			expected:   map[string]int{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rtConf := &runtime.Configuration{
+				Services: map[string]*runtime.ServiceInfo{
+					"canary@myprovider": {
+						Service: &dynamic.Service{
+							Weighted: &dynamic.WeightedRoundRobin{
+								Services: []dynamic.WRRService{{Name: "v1"}, {Name: "v2"}},
+							},
+						},
+					},
+					"v1@myprovider": {
+						Service: &dynamic.Service{
+							LoadBalancer: &dynamic.ServersLoadBalancer{},
+						},
+					},
+				},
+			}
+
+			weights := weightSetterMock{}
+			for name, weight := range test.initial {
+				weights[name] = weight
+			}
+
+			staticConfig := static.Configuration{
+				API:    &static.API{Weights: &static.WeightsAPI{Token: test.token}},
+				Global: &static.Global{},
+			}
+
+			server := httptest.NewServer(NewBuilder(staticConfig, weights)(rtConf))
+			defer server.Close()
+
+			method := test.method
+			if method == "" {
+				method = http.MethodPut
+			}
+
+			req, err := http.NewRequest(method, server.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, test.statusCode, resp.StatusCode)
+			assert.Equal(t, test.expected, map[string]int(weights))
+		})
+	}
+}
+
+func TestHandler_TrafficSplit(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		expected   map[string]int
+		jsonResp   string
+	}{
+		{
+			desc:       "get traffic split",
+			method:     http.MethodGet,
+			path:       "/api/http/services/canary@myprovider/trafficsplit",
+			statusCode: http.StatusOK,
+			expected:   map[string]int{},
+			jsonResp:   `{"services":[{"name":"v1","weight":0,"configuredWeight":0,"overridden":false,"requests":10,"errors":0,"averageDuration":0},{"name":"v2","weight":0,"configuredWeight":0,"overridden":false,"requests":10,"errors":0,"averageDuration":0}]}`,
+		},
+		{
+			desc:       "set canary weight",
+			method:     http.MethodPut,
+			path:       "/api/http/services/canary@myprovider/trafficsplit",
+			body:       `{"canary":"v2@myprovider","weight":20}`,
+			statusCode: http.StatusOK,
+			expected:   map[string]int{"v1": 80, "v2": 20},
+			jsonResp:   `{"services":[{"name":"v1","weight":80,"configuredWeight":0,"overridden":false,"requests":10,"errors":0,"averageDuration":0},{"name":"v2","weight":20,"configuredWeight":0,"overridden":false,"requests":10,"errors":0,"averageDuration":0}]}`,
+		},
+		{
+			desc:       "set canary weight with several stable services",
+			method:     http.MethodPut,
+			path:       "/api/http/services/split@myprovider/trafficsplit",
+			body:       `{"canary":"v2","weight":20}`,
+			statusCode: http.StatusOK,
+			expected:   map[string]int{"v1": 60, "v2": 20, "v3": 20},
+		},
+		{
+			desc:       "set canary weight with several stable services and a remainder",
+			method:     http.MethodPut,
+			path:       "/api/http/services/split@myprovider/trafficsplit",
+			body:       `{"canary":"v2","weight":25}`,
+			statusCode: http.StatusOK,
+			expected:   map[string]int{"v1": 57, "v2": 25, "v3": 18},
+		},
+		{
+			desc:       "canary weight out of range",
+			method:     http.MethodPut,
+			path:       "/api/http/services/canary@myprovider/trafficsplit",
+			body:       `{"canary":"v2","weight":101}`,
+			statusCode: http.StatusBadRequest,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "unknown canary",
+			method:     http.MethodPut,
+			path:       "/api/http/services/canary@myprovider/trafficsplit",
+			body:       `{"canary":"v3","weight":10}`,
+			statusCode: http.StatusNotFound,
+			expected:   map[string]int{},
+		},
+		{
+			desc:       "not a weighted service",
+			method:     http.MethodGet,
+			path:       "/api/http/services/v1@myprovider/trafficsplit",
+			statusCode: http.StatusBadRequest,
+			expected:   map[string]int{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rtConf := &runtime.Configuration{
+				Services: map[string]*runtime.ServiceInfo{
+					"canary@myprovider": {
+						Service: &dynamic.Service{
+							Weighted: &dynamic.WeightedRoundRobin{
+								Services: []dynamic.WRRService{{Name: "v1"}, {Name: "v2"}},
+							},
+						},
+					},
+					"split@myprovider": {
+						Service: &dynamic.Service{
+							Weighted: &dynamic.WeightedRoundRobin{
+								Services: []dynamic.WRRService{{Name: "v1", Weight: Int(3)}, {Name: "v2"}, {Name: "v3"}},
+							},
+						},
+					},
+					"v1@myprovider": {
+						Service: &dynamic.Service{
+							LoadBalancer: &dynamic.ServersLoadBalancer{},
+						},
+					},
+				},
+			}
+
+			weights := weightSetterMock{}
+			staticConfig := static.Configuration{
+				API:    &static.API{Weights: &static.WeightsAPI{}},
+				Global: &static.Global{},
+			}
+
+			server := httptest.NewServer(NewBuilder(staticConfig, weights)(rtConf))
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, test.statusCode, resp.StatusCode, string(contents))
+			assert.Equal(t, test.expected, map[string]int(weights))
+
+			if test.jsonResp != "" {
+				assert.JSONEq(t, test.jsonResp, string(contents))
+			}
+		})
+	}
+}
+
+func Test_splitTraffic(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		services []dynamic.WRRService
+		weight   int
+		expected []int
+	}{
+		{
+			desc:     "one stable service",
+			services: []dynamic.WRRService{{Name: "canary"}, {Name: "v1"}},
+			weight:   10,
+			expected: []int{10, 90},
+		},
+		{
+			desc:     "stable services sharing the rest equally",
+			services: []dynamic.WRRService{{Name: "canary"}, {Name: "v1"}, {Name: "v2"}, {Name: "v3"}},
+			weight:   10,
+			expected: []int{10, 30, 30, 30},
+		},
+		{
+			desc:     "stable services with weights",
+			services: []dynamic.WRRService{{Name: "canary"}, {Name: "v1", Weight: Int(1)}, {Name: "v2", Weight: Int(0)}, {Name: "v3", Weight: Int(1)}},
+			weight:   1,
+			expected: []int{1, 50, 0, 49},
+		},
+		{
+			desc:     "stable services without weight",
+			services: []dynamic.WRRService{{Name: "canary"}, {Name: "v1", Weight: Int(0)}, {Name: "v2", Weight: Int(0)}},
+			weight:   51,
+			expected: []int{51, 25, 24},
+		},
+		{
+			desc:     "stable service with a negative weight",
+			services: []dynamic.WRRService{{Name: "canary"}, {Name: "v1", Weight: Int(-3)}, {Name: "v2", Weight: Int(1)}},
+			weight:   10,
+			expected: []int{10, 0, 90},
+		},
+		{
+			desc:     "whole traffic to the canary",
+			services: []dynamic.WRRService{{Name: "v1"}, {Name: "canary"}, {Name: "v2"}},
+			weight:   100,
+			expected: []int{0, 100, 0},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			canaryIndex, _ := findWRRService(&dynamic.WeightedRoundRobin{Services: test.services}, "split@file", "canary")
+			assert.Equal(t, test.expected, splitTraffic(test.services, canaryIndex, test.weight))
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// Package trafficsplit holds the gRPC API driving the canaries of the weighted services.
+package trafficsplit
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. trafficsplit.proto
@@ -0,0 +1,571 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0-devel
+// 	protoc        (unknown)
+// source: trafficsplit.proto
+
+// The package name makes the paths of the methods start with /api,
+// for them to be routed to the API like its HTTP endpoints.
+
+package trafficsplit
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type GetRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Service is the name of the weighted service, e.g. canary@file.
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficsplit_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficsplit_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_trafficsplit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+type SetCanaryWeightRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Service is the name of the weighted service, e.g. canary@file.
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// Canary is the name of the child service receiving the percentage of the traffic.
+	Canary string `protobuf:"bytes,2,opt,name=canary,proto3" json:"canary,omitempty"`
+	// Weight is the percentage of the traffic, between 0 and 100.
+	Weight int32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+}
+
+func (x *SetCanaryWeightRequest) Reset() {
+	*x = SetCanaryWeightRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficsplit_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetCanaryWeightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCanaryWeightRequest) ProtoMessage() {}
+
+func (x *SetCanaryWeightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficsplit_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCanaryWeightRequest.ProtoReflect.Descriptor instead.
+func (*SetCanaryWeightRequest) Descriptor() ([]byte, []int) {
+	return file_trafficsplit_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetCanaryWeightRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *SetCanaryWeightRequest) GetCanary() string {
+	if x != nil {
+		return x.Canary
+	}
+	return ""
+}
+
+func (x *SetCanaryWeightRequest) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type Split struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Services []*ServiceStatus `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+}
+
+func (x *Split) Reset() {
+	*x = Split{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficsplit_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Split) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Split) ProtoMessage() {}
+
+func (x *Split) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficsplit_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Split.ProtoReflect.Descriptor instead.
+func (*Split) Descriptor() ([]byte, []int) {
+	return file_trafficsplit_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Split) GetServices() []*ServiceStatus {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+// ServiceStatus describes the current weight of a child of a weighted service,
+// and the traffic it received since Traefik started.
+type ServiceStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name             string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Weight           int32  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	ConfiguredWeight int32  `protobuf:"varint,3,opt,name=configured_weight,json=configuredWeight,proto3" json:"configured_weight,omitempty"`
+	Overridden       bool   `protobuf:"varint,4,opt,name=overridden,proto3" json:"overridden,omitempty"`
+	Requests         uint64 `protobuf:"varint,5,opt,name=requests,proto3" json:"requests,omitempty"`
+	Errors           uint64 `protobuf:"varint,6,opt,name=errors,proto3" json:"errors,omitempty"`
+	// AverageDuration is in seconds.
+	AverageDuration float64 `protobuf:"fixed64,7,opt,name=average_duration,json=averageDuration,proto3" json:"average_duration,omitempty"`
+}
+
+func (x *ServiceStatus) Reset() {
+	*x = ServiceStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trafficsplit_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServiceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceStatus) ProtoMessage() {}
+
+func (x *ServiceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_trafficsplit_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceStatus.ProtoReflect.Descriptor instead.
+func (*ServiceStatus) Descriptor() ([]byte, []int) {
+	return file_trafficsplit_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ServiceStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ServiceStatus) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetConfiguredWeight() int32 {
+	if x != nil {
+		return x.ConfiguredWeight
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetOverridden() bool {
+	if x != nil {
+		return x.Overridden
+	}
+	return false
+}
+
+func (x *ServiceStatus) GetRequests() uint64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetErrors() uint64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *ServiceStatus) GetAverageDuration() float64 {
+	if x != nil {
+		return x.AverageDuration
+	}
+	return 0
+}
+
+var File_trafficsplit_proto protoreflect.FileDescriptor
+
+var file_trafficsplit_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x61, 0x70, 0x69, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69,
+	0x63, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x22, 0x26, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x22, 0x62,
+	0x0a, 0x16, 0x53, 0x65, 0x74, 0x43, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x57, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x22, 0x44, 0x0a, 0x05, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x12, 0x3b, 0x0a, 0x08, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x73, 0x70, 0x6c, 0x69, 0x74,
+	0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0xe7, 0x01, 0x0a, 0x0d, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x65, 0x64, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x64, 0x57, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64,
+	0x64, 0x65, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x76, 0x65, 0x72, 0x61,
+	0x67, 0x65, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x32, 0xa6, 0x01, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x53, 0x70,
+	0x6c, 0x69, 0x74, 0x12, 0x3e, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x74,
+	0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x2e, 0x53, 0x70, 0x6c, 0x69,
+	0x74, 0x22, 0x00, 0x12, 0x56, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x43, 0x61, 0x6e, 0x61, 0x72, 0x79,
+	0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x28, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x74, 0x72, 0x61,
+	0x66, 0x66, 0x69, 0x63, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x2e, 0x53, 0x65, 0x74, 0x43, 0x61, 0x6e,
+	0x61, 0x72, 0x79, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x73, 0x70,
+	0x6c, 0x69, 0x74, 0x2e, 0x53, 0x70, 0x6c, 0x69, 0x74, 0x22, 0x00, 0x42, 0x37, 0x5a, 0x35, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x6f, 0x75, 0x73, 0x2f, 0x74, 0x72, 0x61, 0x65, 0x66, 0x69, 0x6b, 0x2f, 0x76, 0x32, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x73,
+	0x70, 0x6c, 0x69, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_trafficsplit_proto_rawDescOnce sync.Once
+	file_trafficsplit_proto_rawDescData = file_trafficsplit_proto_rawDesc
+)
+
+func file_trafficsplit_proto_rawDescGZIP() []byte {
+	file_trafficsplit_proto_rawDescOnce.Do(func() {
+		file_trafficsplit_proto_rawDescData = protoimpl.X.CompressGZIP(file_trafficsplit_proto_rawDescData)
+	})
+	return file_trafficsplit_proto_rawDescData
+}
+
+var file_trafficsplit_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_trafficsplit_proto_goTypes = []interface{}{
+	(*GetRequest)(nil),             // 0: api.trafficsplit.GetRequest
+	(*SetCanaryWeightRequest)(nil), // 1: api.trafficsplit.SetCanaryWeightRequest
+	(*Split)(nil),                  // 2: api.trafficsplit.Split
+	(*ServiceStatus)(nil),          // 3: api.trafficsplit.ServiceStatus
+}
+var file_trafficsplit_proto_depIdxs = []int32{
+	3, // 0: api.trafficsplit.Split.services:type_name -> api.trafficsplit.ServiceStatus
+	0, // 1: api.trafficsplit.TrafficSplit.Get:input_type -> api.trafficsplit.GetRequest
+	1, // 2: api.trafficsplit.TrafficSplit.SetCanaryWeight:input_type -> api.trafficsplit.SetCanaryWeightRequest
+	2, // 3: api.trafficsplit.TrafficSplit.Get:output_type -> api.trafficsplit.Split
+	2, // 4: api.trafficsplit.TrafficSplit.SetCanaryWeight:output_type -> api.trafficsplit.Split
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_trafficsplit_proto_init() }
+func file_trafficsplit_proto_init() {
+	if File_trafficsplit_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_trafficsplit_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficsplit_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetCanaryWeightRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficsplit_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Split); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trafficsplit_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServiceStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_trafficsplit_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_trafficsplit_proto_goTypes,
+		DependencyIndexes: file_trafficsplit_proto_depIdxs,
+		MessageInfos:      file_trafficsplit_proto_msgTypes,
+	}.Build()
+	File_trafficsplit_proto = out.File
+	file_trafficsplit_proto_rawDesc = nil
+	file_trafficsplit_proto_goTypes = nil
+	file_trafficsplit_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// TrafficSplitClient is the client API for TrafficSplit service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TrafficSplitClient interface {
+	// Get returns the weights and the traffic statistics of the children of a weighted service.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Split, error)
+	// SetCanaryWeight gives a percentage of the traffic to the canary,
+	// the other children sharing the rest in proportion to their configured weights.
+	SetCanaryWeight(ctx context.Context, in *SetCanaryWeightRequest, opts ...grpc.CallOption) (*Split, error)
+}
+
+type trafficSplitClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrafficSplitClient(cc grpc.ClientConnInterface) TrafficSplitClient {
+	return &trafficSplitClient{cc}
+}
+
+func (c *trafficSplitClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Split, error) {
+	out := new(Split)
+	err := c.cc.Invoke(ctx, "/api.trafficsplit.TrafficSplit/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficSplitClient) SetCanaryWeight(ctx context.Context, in *SetCanaryWeightRequest, opts ...grpc.CallOption) (*Split, error) {
+	out := new(Split)
+	err := c.cc.Invoke(ctx, "/api.trafficsplit.TrafficSplit/SetCanaryWeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TrafficSplitServer is the server API for TrafficSplit service.
+type TrafficSplitServer interface {
+	// Get returns the weights and the traffic statistics of the children of a weighted service.
+	Get(context.Context, *GetRequest) (*Split, error)
+	// SetCanaryWeight gives a percentage of the traffic to the canary,
+	// the other children sharing the rest in proportion to their configured weights.
+	SetCanaryWeight(context.Context, *SetCanaryWeightRequest) (*Split, error)
+}
+
+// UnimplementedTrafficSplitServer can be embedded to have forward compatible implementations.
+type UnimplementedTrafficSplitServer struct {
+}
+
+func (*UnimplementedTrafficSplitServer) Get(context.Context, *GetRequest) (*Split, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedTrafficSplitServer) SetCanaryWeight(context.Context, *SetCanaryWeightRequest) (*Split, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCanaryWeight not implemented")
+}
+
+func RegisterTrafficSplitServer(s *grpc.Server, srv TrafficSplitServer) {
+	s.RegisterService(&_TrafficSplit_serviceDesc, srv)
+}
+
+func _TrafficSplit_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficSplitServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.trafficsplit.TrafficSplit/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficSplitServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficSplit_SetCanaryWeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCanaryWeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficSplitServer).SetCanaryWeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.trafficsplit.TrafficSplit/SetCanaryWeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficSplitServer).SetCanaryWeight(ctx, req.(*SetCanaryWeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TrafficSplit_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.trafficsplit.TrafficSplit",
+	HandlerType: (*TrafficSplitServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _TrafficSplit_Get_Handler,
+		},
+		{
+			MethodName: "SetCanaryWeight",
+			Handler:    _TrafficSplit_SetCanaryWeight_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "trafficsplit.proto",
+}
@@ -0,0 +1,23 @@
+package cluster
+
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
+// Configuration holds the configuration of the cluster mode.
+type Configuration struct {
+	Name             string          `description:"Name of the instance in the cluster (default: the hostname, followed by a random suffix)." json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
+	Address          string          `description:"Address on which the gossip messages are received, over both TCP and UDP." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" export:"true"`
+	AdvertiseAddress string          `description:"Address to which the other instances send their gossip messages (default: the IP of the address, or a private IP of the host)." json:"advertiseAddress,omitempty" toml:"advertiseAddress,omitempty" yaml:"advertiseAddress,omitempty" export:"true"`
+	Peers            []string        `description:"Addresses of the instances to join." json:"peers,omitempty" toml:"peers,omitempty" yaml:"peers,omitempty" export:"true"`
+	Secret           string          `description:"Secret shared by the instances, authenticating and encrypting the gossip messages." json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty"`
+	GossipInterval   ptypes.Duration `description:"Interval between two gossip rounds." json:"gossipInterval,omitempty" toml:"gossipInterval,omitempty" yaml:"gossipInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.Address = ":7946"
+	c.GossipInterval = ptypes.Duration(time.Second)
+}
@@ -0,0 +1,114 @@
+package cluster
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/hashicorp/memberlist"
+)
+
+// wireEntry is an entry, as sent to the other instances.
+type wireEntry struct {
+	Key     string `json:"key"`
+	Value   []byte `json:"value,omitempty"`
+	Origin  string `json:"origin"`
+	Version uint64 `json:"version"`
+	// Age is the time elapsed since the last change of the entry, in nanoseconds.
+	// It is sent instead of a time, so the clocks of the instances do not have to be synchronized.
+	Age int64 `json:"age"`
+}
+
+// entryBroadcast is an entry gossiped to the other instances, replacing the previous versions of the entry still queued.
+type entryBroadcast struct {
+	key string
+	msg []byte
+}
+
+func (b *entryBroadcast) Invalidates(other memberlist.Broadcast) bool {
+	named, ok := other.(memberlist.NamedBroadcast)
+	return ok && named.Name() == b.key
+}
+
+func (b *entryBroadcast) Name() string {
+	return b.key
+}
+
+func (b *entryBroadcast) Message() []byte {
+	return b.msg
+}
+
+func (b *entryBroadcast) Finished() {}
+
+// delegate gossips the changes of the state of the local instance,
+// and exchanges its whole state during the push-pull rounds of memberlist.
+// The messages are authenticated and encrypted by memberlist, with the key derived from the secret.
+type delegate struct {
+	node *Node
+}
+
+func (d *delegate) NodeMeta(int) []byte {
+	return nil
+}
+
+func (d *delegate) NotifyMsg(msg []byte) {
+	var entries []wireEntry
+	if err := json.Unmarshal(msg, &entries); err != nil {
+		log.WithoutContext().Debugf("Invalid gossip message: %v", err)
+		return
+	}
+
+	d.node.merge(entries, true)
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.node.broadcasts.GetBroadcasts(overhead, limit)
+}
+
+func (d *delegate) LocalState(bool) []byte {
+	state, err := json.Marshal(d.node.state())
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to encode the gossip message: %v", err)
+		return nil
+	}
+
+	return state
+}
+
+func (d *delegate) MergeRemoteState(buf []byte, _ bool) {
+	if len(buf) == 0 {
+		return
+	}
+
+	var entries []wireEntry
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		log.WithoutContext().Debugf("Invalid gossip message: %v", err)
+		return
+	}
+
+	d.node.merge(entries, false)
+}
+
+// eventDelegate counts the instances alive in the cluster,
+// and records when the instances leave it, so their entries are eventually forgotten.
+type eventDelegate struct {
+	node *Node
+}
+
+func (d *eventDelegate) NotifyJoin(member *memberlist.Node) {
+	d.node.mu.Lock()
+	defer d.node.mu.Unlock()
+
+	d.node.members++
+	delete(d.node.left, member.Name)
+}
+
+func (d *eventDelegate) NotifyLeave(member *memberlist.Node) {
+	d.node.mu.Lock()
+	defer d.node.mu.Unlock()
+
+	d.node.members--
+	d.node.left[member.Name] = time.Now()
+}
+
+func (d *eventDelegate) NotifyUpdate(*memberlist.Node) {}
@@ -0,0 +1,427 @@
+// Package cluster implements the cluster mode, where the Traefik instances share runtime state by gossip.
+//
+// The membership of the instances, and the failure detection, are handled by hashicorp/memberlist (SWIM).
+// The changes of the state are gossiped at each gossip interval, and relayed by the instances receiving them,
+// while each instance exchanges its whole state with another random instance (push-pull) much less often,
+// so every instance eventually knows the state of all the others, without external store.
+// The state is a set of entries, each one being written by a single instance (its origin),
+// and versioned with a Lamport clock, so the most recent version of an entry always wins.
+// The clock is not persisted: a restarted instance catches up with the versions of the cluster when it joins.
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	mathrand "math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/hashicorp/memberlist"
+	"github.com/sirupsen/logrus"
+)
+
+// pruneAfter is the duration after which the entries of an instance which left the cluster are forgotten.
+const pruneAfter = time.Hour
+
+// pushPullIntervals is the number of gossip intervals between two exchanges of the whole state,
+// the changes being gossiped in between.
+const pushPullIntervals = 30
+
+// maxBroadcastSize is the size of the largest entry gossiped when it changes,
+// so it fits in a gossip message: the larger ones, such as the certificates, are only sent with the whole state.
+const maxBroadcastSize = 1024
+
+// Entry is an entry of the shared state.
+type Entry struct {
+	Key     string
+	Value   []byte
+	Origin  string
+	Version uint64
+	// Updated is the time of the last change of the entry, as seen by the local instance.
+	Updated time.Time
+}
+
+// Node is the local instance of a cluster.
+type Node struct {
+	name          string
+	peers         []string
+	interval      time.Duration
+	encryptionKey []byte
+
+	list       *memberlist.Memberlist
+	broadcasts *memberlist.TransmitLimitedQueue
+
+	mu      sync.RWMutex
+	clock   uint64
+	entries map[string]*Entry
+	// members is the number of instances alive in the cluster, including the local one.
+	members int
+	// left are the times the instances left the cluster, keyed by name.
+	left map[string]time.Time
+}
+
+// New creates the local instance of a cluster, and starts listening to the gossip messages.
+func New(config *Configuration) (*Node, error) {
+	if config.Secret == "" {
+		return nil, errors.New("a secret is required to authenticate the gossip messages")
+	}
+
+	interval := time.Duration(config.GossipInterval)
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	name := config.Name
+	if name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the hostname to name the instance: %w", err)
+		}
+
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+		name = hostname + "-" + hex.EncodeToString(suffix)
+	}
+
+	gossipKey, encryptionKey := deriveKeys(config.Secret)
+
+	n := &Node{
+		name:          name,
+		peers:         config.Peers,
+		interval:      interval,
+		encryptionKey: encryptionKey,
+		entries:       make(map[string]*Entry),
+		left:          make(map[string]time.Time),
+	}
+
+	listConfig := memberlist.DefaultLANConfig()
+	listConfig.Name = name
+	listConfig.SecretKey = gossipKey
+	listConfig.GossipInterval = interval
+	listConfig.PushPullInterval = pushPullIntervals * interval
+	listConfig.Delegate = &delegate{node: n}
+	listConfig.Events = &eventDelegate{node: n}
+	listConfig.LogOutput = log.WithoutContext().WriterLevel(logrus.DebugLevel)
+
+	n.broadcasts = &memberlist.TransmitLimitedQueue{
+		NumNodes:       n.numMembers,
+		RetransmitMult: listConfig.RetransmitMult,
+	}
+
+	var err error
+	listConfig.BindAddr, listConfig.BindPort, err = splitAddress(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", config.Address, err)
+	}
+
+	if config.AdvertiseAddress != "" {
+		listConfig.AdvertiseAddr, listConfig.AdvertisePort, err = splitAddress(config.AdvertiseAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid advertise address %q: %w", config.AdvertiseAddress, err)
+		}
+	}
+
+	n.list, err = memberlist.Create(listConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen to the gossip messages: %w", err)
+	}
+
+	return n, nil
+}
+
+// splitAddress returns the IP and port of an address, resolving its host name if needed.
+func splitAddress(address string) (string, int, error) {
+	host, rawPort, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", rawPort)
+	}
+
+	if host == "" {
+		return "0.0.0.0", port, nil
+	}
+
+	ip, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return ip.String(), port, nil
+}
+
+// Name returns the name of the local instance.
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Address returns the address the other instances send their gossip messages to.
+func (n *Node) Address() string {
+	return n.list.LocalNode().Address()
+}
+
+// Interval returns the interval between two gossip rounds.
+func (n *Node) Interval() time.Duration {
+	return n.interval
+}
+
+// Start joins the cluster through the peers, and leaves it when the context is done.
+// The peers are contacted again, in a random order, as long as the local instance is alone,
+// so the instances which lost each other can join again.
+func (n *Node) Start(pool *safe.Pool) {
+	pool.GoCtx(func(ctx context.Context) {
+		log.WithoutContext().Infof("Joining the cluster as %q, advertising %s", n.name, n.Address())
+
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+
+		for {
+			if n.list.NumMembers() <= 1 && len(n.peers) > 0 {
+				n.join()
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				if err := n.list.Leave(n.interval); err != nil {
+					log.WithoutContext().Debugf("Unable to leave the cluster: %v", err)
+				}
+				if err := n.list.Shutdown(); err != nil {
+					log.WithoutContext().Debugf("Unable to stop the gossip: %v", err)
+				}
+				return
+			}
+		}
+	})
+}
+
+// join contacts the peers in a random order, so the instances do not all join through the same one.
+func (n *Node) join() {
+	peers := make([]string, len(n.peers))
+	for i, j := range mathrand.Perm(len(n.peers)) {
+		peers[i] = n.peers[j]
+	}
+
+	if _, err := n.list.Join(peers); err != nil {
+		log.WithoutContext().Debugf("Unable to join the cluster through %v: %v", peers, err)
+	}
+}
+
+// Set writes the value of a key owned by the local instance.
+func (n *Node) Set(key string, value []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.clock++
+	entry := &Entry{
+		Key:     key,
+		Value:   value,
+		Origin:  n.name,
+		Version: n.clock,
+		Updated: time.Now(),
+	}
+	n.entries[key] = entry
+
+	n.broadcast(entry)
+}
+
+// broadcast queues an entry to be gossiped to the other instances.
+// The entries too large to be gossiped are only sent with the whole state.
+// It must be called with the lock held.
+func (n *Node) broadcast(entry *Entry) {
+	msg, err := json.Marshal([]wireEntry{{
+		Key:     entry.Key,
+		Value:   entry.Value,
+		Origin:  entry.Origin,
+		Version: entry.Version,
+		Age:     int64(time.Since(entry.Updated)),
+	}})
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to encode the gossip message: %v", err)
+		return
+	}
+
+	if len(msg) > maxBroadcastSize {
+		return
+	}
+
+	n.broadcasts.QueueBroadcast(&entryBroadcast{key: entry.Key, msg: msg})
+}
+
+// Get returns the value of a key, and the time of its last change.
+func (n *Node) Get(key string) ([]byte, time.Time, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	entry, ok := n.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	return entry.Value, entry.Updated, true
+}
+
+// Entries returns the entries whose key has the given prefix.
+func (n *Node) Entries(prefix string) []Entry {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	var entries []Entry
+	for key, entry := range n.entries {
+		if strings.HasPrefix(key, prefix) {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries
+}
+
+// Members returns the names of the instances alive in the cluster, including the local one.
+func (n *Node) Members() []string {
+	var members []string
+	for _, member := range n.list.Members() {
+		members = append(members, member.Name)
+	}
+
+	return members
+}
+
+// Size returns the number of instances alive in the cluster, including the local one.
+func (n *Node) Size() int {
+	return n.list.NumMembers()
+}
+
+// numMembers returns the number of instances alive in the cluster, as known by the event delegate,
+// as the gossip may need it before the memberlist is created.
+func (n *Node) numMembers() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.members
+}
+
+// Owns tells whether the local instance is the one in charge of the key, among the instances alive in the cluster.
+// The owner is chosen by rendezvous hashing, so the keys of an instance are spread among the others when it leaves.
+func (n *Node) Owns(key string) bool {
+	owner := ""
+	var max uint64
+	for _, member := range n.Members() {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(member))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key))
+
+		if sum := h.Sum64(); owner == "" || sum > max || (sum == max && member < owner) {
+			owner, max = member, sum
+		}
+	}
+
+	return owner == n.name
+}
+
+// prune forgets the entries of the instances which left the cluster a while ago.
+// It must be called with the lock held.
+func (n *Node) prune(now time.Time) {
+	for name, left := range n.left {
+		if now.Sub(left) < pruneAfter {
+			continue
+		}
+
+		for key, entry := range n.entries {
+			if entry.Origin == name {
+				delete(n.entries, key)
+			}
+		}
+		delete(n.left, name)
+	}
+}
+
+// merge merges the entries received from another instance into the local state.
+// The age of an entry is the time elapsed since its last change, as seen by the sender.
+// When relay is true, the entries newer than the local ones are gossiped again to the other instances.
+func (n *Node) merge(entries []wireEntry, relay bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	for _, received := range entries {
+		if received.Version > n.clock {
+			n.clock = received.Version
+		}
+
+		if received.Origin == n.name {
+			// Only the local instance writes its own entries,
+			// but the cluster may still have the ones it wrote before a restart, with a more recent version:
+			// the current value is then written again, so it replaces them.
+			if current, ok := n.entries[received.Key]; ok && current.Version < received.Version {
+				n.clock++
+				current.Version = n.clock
+				n.broadcast(current)
+			}
+			continue
+		}
+
+		age := time.Duration(received.Age)
+		if age < 0 {
+			age = 0
+		}
+		updated := now.Add(-age)
+
+		current, ok := n.entries[received.Key]
+		switch {
+		case !ok || received.Version > current.Version || (received.Version == current.Version && received.Origin > current.Origin):
+			entry := &Entry{
+				Key:     received.Key,
+				Value:   received.Value,
+				Origin:  received.Origin,
+				Version: received.Version,
+				Updated: updated,
+			}
+			n.entries[received.Key] = entry
+
+			if relay {
+				n.broadcast(entry)
+			}
+		case received.Version == current.Version && received.Origin == current.Origin && updated.After(current.Updated):
+			current.Updated = updated
+		}
+	}
+}
+
+// state returns the local state, as sent to the other instances.
+func (n *Node) state() []wireEntry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	n.prune(now)
+
+	entries := make([]wireEntry, 0, len(n.entries))
+	for _, entry := range n.entries {
+		entries = append(entries, wireEntry{
+			Key:     entry.Key,
+			Value:   entry.Value,
+			Origin:  entry.Origin,
+			Version: entry.Version,
+			Age:     int64(now.Sub(entry.Updated)),
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,206 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/hashicorp/memberlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func newTestNode(t *testing.T, name, secret string, peers ...string) *Node {
+	t.Helper()
+
+	node, err := New(&Configuration{
+		Name:           name,
+		Address:        "127.0.0.1:0",
+		Peers:          peers,
+		Secret:         secret,
+		GossipInterval: ptypes.Duration(20 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := safe.NewPool(ctx)
+	t.Cleanup(func() {
+		cancel()
+		pool.Stop()
+	})
+
+	node.Start(pool)
+
+	return node
+}
+
+func TestNodeConvergence(t *testing.T) {
+	nodeA := newTestNode(t, "a", "secret")
+	nodeB := newTestNode(t, "b", "secret", nodeA.Address())
+	nodeC := newTestNode(t, "c", "secret", nodeB.Address())
+
+	nodeA.Set("foo", []byte("bar"))
+	nodeC.Set("baz", []byte("qux"))
+
+	for _, node := range []*Node{nodeA, nodeB, nodeC} {
+		node := node
+		assert.Eventually(t, func() bool {
+			foo, _, okFoo := node.Get("foo")
+			baz, _, okBaz := node.Get("baz")
+			return okFoo && okBaz && string(foo) == "bar" && string(baz) == "qux" && node.Size() == 3
+		}, 5*time.Second, 10*time.Millisecond, node.Name())
+	}
+
+	// The most recent value wins.
+	nodeA.Set("foo", []byte("updated"))
+	assert.Eventually(t, func() bool {
+		foo, _, _ := nodeC.Get("foo")
+		return string(foo) == "updated"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Exactly one instance owns a key.
+	var owners int
+	for _, node := range []*Node{nodeA, nodeB, nodeC} {
+		if node.Owns("health/backend/http://127.0.0.1") {
+			owners++
+		}
+	}
+	assert.Equal(t, 1, owners)
+}
+
+func TestNodeRestart(t *testing.T) {
+	nodeA := newTestNode(t, "a", "secret")
+
+	nodeB, err := New(&Configuration{Name: "b", Address: "127.0.0.1:0", Secret: "secret", Peers: []string{nodeA.Address()}, GossipInterval: ptypes.Duration(20 * time.Millisecond)})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := safe.NewPool(ctx)
+	nodeB.Start(pool)
+
+	for i := 0; i < 10; i++ {
+		nodeB.Set("foo", []byte("before restart"))
+	}
+
+	assert.Eventually(t, func() bool {
+		foo, _, _ := nodeA.Get("foo")
+		return string(foo) == "before restart"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	address := nodeB.Address()
+	cancel()
+	pool.Stop()
+
+	// The restarted instance starts its clock from the versions of the cluster, so its writes replace the previous ones.
+	restarted, err := New(&Configuration{Name: "b", Address: address, Secret: "secret", Peers: []string{nodeA.Address()}, GossipInterval: ptypes.Duration(20 * time.Millisecond)})
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	pool = safe.NewPool(ctx)
+	t.Cleanup(func() {
+		cancel()
+		pool.Stop()
+	})
+
+	restarted.Start(pool)
+
+	assert.Eventually(t, func() bool {
+		return restarted.Size() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	restarted.Set("foo", []byte("after restart"))
+
+	assert.Eventually(t, func() bool {
+		foo, _, _ := nodeA.Get("foo")
+		return string(foo) == "after restart"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNodeInvalidSecret(t *testing.T) {
+	nodeA := newTestNode(t, "a", "secret")
+	nodeB := newTestNode(t, "b", "other", nodeA.Address())
+
+	nodeB.Set("foo", []byte("bar"))
+
+	time.Sleep(200 * time.Millisecond)
+
+	_, _, ok := nodeA.Get("foo")
+	assert.False(t, ok)
+	assert.Equal(t, 1, nodeA.Size())
+	assert.Equal(t, 1, nodeB.Size())
+}
+
+func TestNodeMerge(t *testing.T) {
+	node := &Node{
+		name:       "a",
+		interval:   time.Second,
+		broadcasts: &memberlist.TransmitLimitedQueue{NumNodes: func() int { return 1 }, RetransmitMult: 1},
+		entries:    make(map[string]*Entry),
+		left:       make(map[string]time.Time),
+	}
+
+	node.merge([]wireEntry{
+		{Key: "foo", Value: []byte("b"), Origin: "b", Version: 5},
+		{Key: "bar", Value: []byte("c"), Origin: "c", Version: 4, Age: int64(time.Minute)},
+		{Key: "self", Value: []byte("b"), Origin: "a", Version: 6},
+	}, false)
+
+	_, _, ok := node.Get("self")
+	assert.False(t, ok)
+
+	// A relayed entry keeps its age.
+	_, updated, _ := node.Get("bar")
+	assert.WithinDuration(t, time.Now().Add(-time.Minute), updated, time.Second)
+
+	// An older version does not replace the current one.
+	node.merge([]wireEntry{{Key: "foo", Value: []byte("c"), Origin: "c", Version: 4}}, false)
+	value, _, _ := node.Get("foo")
+	assert.Equal(t, "b", string(value))
+
+	// The local writes are more recent than everything seen so far.
+	node.Set("foo", []byte("a"))
+	value, _, _ = node.Get("foo")
+	assert.Equal(t, "a", string(value))
+	assert.Greater(t, node.entries["foo"].Version, uint64(5))
+
+	// A local entry written again before a restart keeps its value, with a version more recent than the previous one.
+	node.merge([]wireEntry{{Key: "foo", Value: []byte("before restart"), Origin: "a", Version: 42}}, false)
+	value, _, _ = node.Get("foo")
+	assert.Equal(t, "a", string(value))
+	assert.Greater(t, node.entries["foo"].Version, uint64(42))
+
+	// The entries of an instance which left the cluster a while ago are forgotten.
+	node.left["c"] = time.Now().Add(-2 * pruneAfter)
+	node.state()
+
+	_, _, ok = node.Get("bar")
+	assert.False(t, ok)
+}
+
+func TestNodeSeal(t *testing.T) {
+	node, err := New(&Configuration{Address: "127.0.0.1:0", Secret: "secret"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = node.list.Shutdown() })
+
+	sealed, err := node.Seal([]byte("private key"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "private key")
+
+	opened, err := node.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "private key", string(opened))
+
+	other, err := New(&Configuration{Address: "127.0.0.1:0", Secret: "other"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = other.list.Shutdown() })
+
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+}
+
+func TestNewWithoutSecret(t *testing.T) {
+	_, err := New(&Configuration{Address: "127.0.0.1:0"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+// deriveKeys derives the key encrypting the gossip messages, and the key encrypting the sealed values, from the secret.
+// Both are AES-256 keys.
+func deriveKeys(secret string) ([]byte, []byte) {
+	return deriveKey(secret, "gossip"), deriveKey(secret, "encryption")
+}
+
+func deriveKey(secret, usage string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("traefik-cluster-" + usage))
+	return mac.Sum(nil)
+}
+
+// Seal encrypts a value with the secret of the cluster,
+// for the values which must not be readable by whoever sees the gossip messages, such as the private keys.
+func (n *Node) Seal(value []byte) ([]byte, error) {
+	gcm, err := n.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, value, nil), nil
+}
+
+// Open decrypts a value sealed by an instance of the cluster.
+func (n *Node) Open(sealed []byte) ([]byte, error) {
+	gcm, err := n.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("the sealed value is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func (n *Node) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(n.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -2,8 +2,10 @@ package dynamic
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/types"
+	ptypes "github.com/traefik/paerser/types"
 )
 
 // +k8s:deepcopy-gen=true
@@ -14,6 +16,26 @@ type HTTPConfiguration struct {
 	Services    map[string]*Service    `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
 	Middlewares map[string]*Middleware `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
 	Models      map[string]*Model      `json:"models,omitempty" toml:"models,omitempty" yaml:"models,omitempty"`
+	IPSets      map[string]*IPSet      `json:"ipSets,omitempty" toml:"ipSets,omitempty" yaml:"ipSets,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// IPSet holds a named set of IPs and CIDRs, maintained once for the middlewares and the entry points referencing it.
+type IPSet struct {
+	// SourceRange are the IPs and CIDRs of the set.
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// URL is the address of a list of IPs and CIDRs added to the set, e.g. the ranges of a cloud provider.
+	URL string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	// File is the path of a list of IPs and CIDRs added to the set.
+	File string `json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty"`
+	// RefreshInterval is how often the list of the URL, or of the file, is loaded again.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for an IPSet.
+func (s *IPSet) SetDefaults() {
+	s.RefreshInterval = ptypes.Duration(time.Hour)
 }
 
 // +k8s:deepcopy-gen=true
@@ -31,18 +53,61 @@ type Service struct {
 	LoadBalancer *ServersLoadBalancer `json:"loadBalancer,omitempty" toml:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty"`
 	Weighted     *WeightedRoundRobin  `json:"weighted,omitempty" toml:"weighted,omitempty" yaml:"weighted,omitempty" label:"-"`
 	Mirroring    *Mirroring           `json:"mirroring,omitempty" toml:"mirroring,omitempty" yaml:"mirroring,omitempty" label:"-"`
+	Egress       *Egress              `json:"egress,omitempty" toml:"egress,omitempty" yaml:"egress,omitempty" label:"-"`
 }
 
 // +k8s:deepcopy-gen=true
 
 // Router holds the router configuration.
 type Router struct {
-	EntryPoints []string         `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty"`
-	Middlewares []string         `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
-	Service     string           `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
-	Rule        string           `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
-	Priority    int              `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty"`
-	TLS         *RouterTLSConfig `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	EntryPoints   []string            `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty"`
+	Middlewares   []string            `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Service       string              `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
+	Rule          string              `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
+	Priority      int                 `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty"`
+	TLS           *RouterTLSConfig    `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	HTTPVersions  *RouterHTTPVersions `json:"httpVersions,omitempty" toml:"httpVersions,omitempty" yaml:"httpVersions,omitempty"`
+	Deadline      *RouterDeadline     `json:"deadline,omitempty" toml:"deadline,omitempty" yaml:"deadline,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	TrailingSlash string              `json:"trailingSlash,omitempty" toml:"trailingSlash,omitempty" yaml:"trailingSlash,omitempty"`
+	Pacing        *RouterPacing       `json:"pacing,omitempty" toml:"pacing,omitempty" yaml:"pacing,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RouterPacing holds the pacing of the responses of a router,
+// limiting the bandwidth of each response so that large downloads do not crowd out the other traffic.
+type RouterPacing struct {
+	// Rate is the bandwidth of each response, in bytes/s.
+	Rate int64 `json:"rate,omitempty" toml:"rate,omitempty" yaml:"rate,omitempty" export:"true"`
+	// Burst is the number of bytes of each response sent before the pacing starts.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RouterDeadline holds the deadline of the requests handled by a router,
+// whose remaining time is propagated to the servers of the service.
+type RouterDeadline struct {
+	// Timeout is how long the requests can take, from their arrival on the router.
+	// The requests only get the deadline set by the clients when it is 0.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Header is the header giving the remaining time, in milliseconds, to the servers,
+	// the gRPC requests getting it in their grpc-timeout header.
+	Header string `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty"`
+}
+
+// SetDefaults Default values for a RouterDeadline.
+func (d *RouterDeadline) SetDefaults() {
+	d.Header = "X-Request-Deadline"
+}
+
+// +k8s:deepcopy-gen=true
+
+// RouterHTTPVersions holds the HTTP versions policy of a router.
+// The requests made with another HTTP version are rejected with a 505 status code.
+type RouterHTTPVersions struct {
+	Allowed   []string `json:"allowed,omitempty" toml:"allowed,omitempty" yaml:"allowed,omitempty"`
+	Forbidden []string `json:"forbidden,omitempty" toml:"forbidden,omitempty" yaml:"forbidden,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -79,6 +144,31 @@ type MirrorService struct {
 
 // +k8s:deepcopy-gen=true
 
+// Egress is an egress gateway, forwarding the requests to the external destinations named by their Host header.
+type Egress struct {
+	Destinations []EgressDestination `json:"destinations,omitempty" toml:"destinations,omitempty" yaml:"destinations,omitempty"`
+	AuditLog     bool                `json:"auditLog,omitempty" toml:"auditLog,omitempty" yaml:"auditLog,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// EgressDestination holds the policy of an external destination of an egress gateway.
+type EgressDestination struct {
+	Host             string          `json:"host,omitempty" toml:"host,omitempty" yaml:"host,omitempty"`
+	Scheme           string          `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
+	Port             int             `json:"port,omitempty" toml:"port,omitempty" yaml:"port,omitempty" export:"true"`
+	PinnedPublicKeys []string        `json:"pinnedPublicKeys,omitempty" toml:"pinnedPublicKeys,omitempty" yaml:"pinnedPublicKeys,omitempty"`
+	Retry            *Retry          `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty" export:"true"`
+	CircuitBreaker   *CircuitBreaker `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for an EgressDestination.
+func (e *EgressDestination) SetDefaults() {
+	e.Scheme = "https"
+}
+
+// +k8s:deepcopy-gen=true
+
 // WeightedRoundRobin is a weighted round robin load-balancer of services.
 type WeightedRoundRobin struct {
 	Services []WRRService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
@@ -103,7 +193,11 @@ func (w *WRRService) SetDefaults() {
 
 // Sticky holds the sticky configuration.
 type Sticky struct {
-	Cookie *Cookie `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	Cookie   *Cookie   `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	Affinity *Affinity `json:"affinity,omitempty" toml:"affinity,omitempty" yaml:"affinity,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	// DrainWindow is how long the servers removed from the configuration keep serving the clients of their sticky sessions,
+	// as long as they are healthy, before being removed from the load-balancer.
+	DrainWindow ptypes.Duration `json:"drainWindow,omitempty" toml:"drainWindow,omitempty" yaml:"drainWindow,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -118,6 +212,22 @@ type Cookie struct {
 
 // +k8s:deepcopy-gen=true
 
+// Affinity holds the sticky configuration based on the affinity table,
+// which maps the clients to the servers without cookies.
+type Affinity struct {
+	// Header is the header whose value identifies the clients.
+	// They are identified by their IP address when it is empty.
+	Header string          `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty"`
+	TTL    ptypes.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// SetDefaults Default values for an Affinity.
+func (a *Affinity) SetDefaults() {
+	a.TTL = ptypes.Duration(time.Hour)
+}
+
+// +k8s:deepcopy-gen=true
+
 // ServersLoadBalancer holds the ServersLoadBalancer configuration.
 type ServersLoadBalancer struct {
 	Sticky             *Sticky             `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty" file:"allowEmpty"`
@@ -125,6 +235,72 @@ type ServersLoadBalancer struct {
 	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty"`
+	// ServersTransports selects the transport forwarding each request to the servers:
+	// the first one whose rule matches the request is used, the default transport otherwise.
+	ServersTransports []ServersTransportSelector `json:"serversTransports,omitempty" toml:"serversTransports,omitempty" yaml:"serversTransports,omitempty" label:"-"`
+	Coalescing        *Coalescing                `json:"coalescing,omitempty" toml:"coalescing,omitempty" yaml:"coalescing,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	QoS               *QoS                       `json:"qos,omitempty" toml:"qos,omitempty" yaml:"qos,omitempty"`
+	PathNormalization *PathNormalization         `json:"pathNormalization,omitempty" toml:"pathNormalization,omitempty" yaml:"pathNormalization,omitempty"`
+	// HostHeader sets the Host header sent to the servers, taking precedence over PassHostHeader.
+	HostHeader *HostHeader `json:"hostHeader,omitempty" toml:"hostHeader,omitempty" yaml:"hostHeader,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// HostHeader holds the policy of the Host header, and of the X-Forwarded-Host header, sent to the servers.
+type HostHeader struct {
+	// Policy is preserve (the Host header of the client), backend (the host of the server URL),
+	// or custom (the value rendered from the template).
+	Policy string `json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty" export:"true"`
+	// Value is the Go template of the custom Host header, given the Host, Hostname and ServerHost of the request.
+	Value string `json:"value,omitempty" toml:"value,omitempty" yaml:"value,omitempty"`
+	// ForwardedHost is keep (the X-Forwarded-Host header set on the entry point),
+	// client (the Host header of the client), or remove.
+	ForwardedHost string `json:"forwardedHost,omitempty" toml:"forwardedHost,omitempty" yaml:"forwardedHost,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// PathNormalization holds the rules normalizing the request paths in the path label of the service metrics,
+// to keep the number of label values under control.
+type PathNormalization struct {
+	// Rules are applied in order, the path being replaced by the first one whose regex matches it.
+	Rules []PathNormalizationRule `json:"rules,omitempty" toml:"rules,omitempty" yaml:"rules,omitempty"`
+	// Hosts are the hosts whose request paths are kept, the paths of the requests to the other hosts being labeled "other".
+	Hosts []string `json:"hosts,omitempty" toml:"hosts,omitempty" yaml:"hosts,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// PathNormalizationRule replaces the request paths matching the regex.
+type PathNormalizationRule struct {
+	Regex       string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty"`
+	Replacement string `json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Coalescing holds the configuration of the coalescing of the identical GET requests:
+// the concurrent identical requests result in a single request to the servers, whose response is sent to all of them.
+type Coalescing struct {
+	// Headers are the request headers, in addition to Authorization, Cookie and Range, whose values distinguish the requests.
+	Headers []string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	// MaxBodySize is the size of the largest response body sent to the coalesced requests,
+	// which send their own request to the servers when it is exceeded.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a Coalescing.
+func (c *Coalescing) SetDefaults() {
+	c.MaxBodySize = 10 * 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
+// ServersTransportSelector selects a named servers transport for the requests matching its rule.
+type ServersTransportSelector struct {
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	Rule string `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
 }
 
 // Mergeable tells if the given service is mergeable.
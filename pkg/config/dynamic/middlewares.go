@@ -1,6 +1,7 @@
 package dynamic
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -16,30 +17,42 @@ import (
 
 // Middleware holds the Middleware configuration.
 type Middleware struct {
-	AddPrefix         *AddPrefix         `json:"addPrefix,omitempty" toml:"addPrefix,omitempty" yaml:"addPrefix,omitempty"`
-	StripPrefix       *StripPrefix       `json:"stripPrefix,omitempty" toml:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
-	StripPrefixRegex  *StripPrefixRegex  `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty"`
-	ReplacePath       *ReplacePath       `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty"`
-	ReplacePathRegex  *ReplacePathRegex  `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty"`
-	Chain             *Chain             `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty"`
-	IPWhiteList       *IPWhiteList       `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
-	Headers           *Headers           `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
-	Errors            *ErrorPage         `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
-	RateLimit         *RateLimit         `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
-	RedirectRegex     *RedirectRegex     `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
-	RedirectScheme    *RedirectScheme    `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
-	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
-	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty"`
-	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty"`
-	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
-	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty" file:"allowEmpty"`
-	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty"`
-	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty"`
-	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
+	AddPrefix           *AddPrefix           `json:"addPrefix,omitempty" toml:"addPrefix,omitempty" yaml:"addPrefix,omitempty"`
+	StripPrefix         *StripPrefix         `json:"stripPrefix,omitempty" toml:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
+	StripPrefixRegex    *StripPrefixRegex    `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty"`
+	ReplacePath         *ReplacePath         `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty"`
+	ReplacePathRegex    *ReplacePathRegex    `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty"`
+	Chain               *Chain               `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty"`
+	IPWhiteList         *IPWhiteList         `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
+	Headers             *Headers             `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	Errors              *ErrorPage           `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
+	RateLimit           *RateLimit           `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	RedirectRegex       *RedirectRegex       `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
+	RedirectScheme      *RedirectScheme      `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
+	BasicAuth           *BasicAuth           `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	DigestAuth          *DigestAuth          `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty"`
+	ForwardAuth         *ForwardAuth         `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
+	InFlightReq         *InFlightReq         `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty"`
+	Buffering           *Buffering           `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty"`
+	CircuitBreaker      *CircuitBreaker      `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+	Compress            *Compress            `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	PassTLSClientCert   *PassTLSClientCert   `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty"`
+	Retry               *Retry               `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty"`
+	ContentType         *ContentType         `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
+	OPA                 *OPA                 `json:"opa,omitempty" toml:"opa,omitempty" yaml:"opa,omitempty"`
+	SPNEGOAuth          *SPNEGOAuth          `json:"spnegoAuth,omitempty" toml:"spnegoAuth,omitempty" yaml:"spnegoAuth,omitempty"`
+	LDAPAuth            *LDAPAuth            `json:"ldapAuth,omitempty" toml:"ldapAuth,omitempty" yaml:"ldapAuth,omitempty"`
+	SAML                *SAML                `json:"saml,omitempty" toml:"saml,omitempty" yaml:"saml,omitempty"`
+	EarlyHints          *EarlyHints          `json:"earlyHints,omitempty" toml:"earlyHints,omitempty" yaml:"earlyHints,omitempty"`
+	S3                  *S3                  `json:"s3,omitempty" toml:"s3,omitempty" yaml:"s3,omitempty"`
+	AdaptiveConcurrency *AdaptiveConcurrency `json:"adaptiveConcurrency,omitempty" toml:"adaptiveConcurrency,omitempty" yaml:"adaptiveConcurrency,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	QoS                 *QoS                 `json:"qos,omitempty" toml:"qos,omitempty" yaml:"qos,omitempty"`
 
 	Plugin map[string]PluginConf `json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	// When is a rule, with the same matchers as the router rules, the requests must match for the middleware to be applied.
+	// The middleware is skipped for the other requests.
+	When string `json:"when,omitempty" toml:"when,omitempty" yaml:"when,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -59,6 +72,27 @@ type ContentType struct {
 
 // +k8s:deepcopy-gen=true
 
+// AdaptiveConcurrency limits the requests processed concurrently by the service,
+// to a limit inferred from the gradient of its latency.
+// The middlewares of the routers targeting the same service share their limit.
+type AdaptiveConcurrency struct {
+	InitialLimit int             `json:"initialLimit,omitempty" toml:"initialLimit,omitempty" yaml:"initialLimit,omitempty" export:"true"`
+	MinLimit     int             `json:"minLimit,omitempty" toml:"minLimit,omitempty" yaml:"minLimit,omitempty" export:"true"`
+	MaxLimit     int             `json:"maxLimit,omitempty" toml:"maxLimit,omitempty" yaml:"maxLimit,omitempty" export:"true"`
+	QueueSize    int             `json:"queueSize,omitempty" toml:"queueSize,omitempty" yaml:"queueSize,omitempty" export:"true"`
+	QueueTimeout ptypes.Duration `json:"queueTimeout,omitempty" toml:"queueTimeout,omitempty" yaml:"queueTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a AdaptiveConcurrency.
+func (a *AdaptiveConcurrency) SetDefaults() {
+	a.InitialLimit = 20
+	a.MinLimit = 1
+	a.MaxLimit = 1000
+	a.QueueTimeout = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // AddPrefix holds the AddPrefix configuration.
 type AddPrefix struct {
 	Prefix string `json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty"`
@@ -100,6 +134,8 @@ type Buffering struct {
 // Chain holds a chain of middlewares.
 type Chain struct {
 	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	// Variables are shared by the middlewares of the chain, which reference them with ${name} in their configuration.
+	Variables map[string]string `json:"variables,omitempty" toml:"variables,omitempty" yaml:"variables,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -114,6 +150,8 @@ type CircuitBreaker struct {
 // Compress holds the compress configuration.
 type Compress struct {
 	ExcludedContentTypes []string `json:"excludedContentTypes,omitempty" toml:"excludedContentTypes,omitempty" yaml:"excludedContentTypes,omitempty" export:"true"`
+	// ETagPolicy defines how the ETag of the compressed responses is modified: weak (default), suffix, remove or keep.
+	ETagPolicy string `json:"etagPolicy,omitempty" toml:"etagPolicy,omitempty" yaml:"etagPolicy,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -129,11 +167,32 @@ type DigestAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// EarlyHints holds the early hints configuration.
+// This middleware sends a 103 Early Hints response with the Link headers, before the final response.
+type EarlyHints struct {
+	Links []string `json:"links,omitempty" toml:"links,omitempty" yaml:"links,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // ErrorPage holds the custom error page configuration.
 type ErrorPage struct {
 	Status  []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty"`
 	Service string   `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
 	Query   string   `json:"query,omitempty" toml:"query,omitempty" yaml:"query,omitempty"`
+	// Templates are the error pages rendered by Traefik for the clients preferring their media type,
+	// instead of the page of the service.
+	Templates []ErrorPageTemplate `json:"templates,omitempty" toml:"templates,omitempty" yaml:"templates,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ErrorPageTemplate holds an error page rendered by Traefik.
+type ErrorPageTemplate struct {
+	// MediaType is the media type of the page, e.g. application/problem+json.
+	MediaType string `json:"mediaType,omitempty" toml:"mediaType,omitempty" yaml:"mediaType,omitempty"`
+	// Body is the Go template of the page, given the Status code and its Title.
+	Body string `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -144,6 +203,22 @@ type ForwardAuth struct {
 	TLS                 *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
 	TrustForwardHeader  bool       `json:"trustForwardHeader,omitempty" toml:"trustForwardHeader,omitempty" yaml:"trustForwardHeader,omitempty" export:"true"`
 	AuthResponseHeaders []string   `json:"authResponseHeaders,omitempty" toml:"authResponseHeaders,omitempty" yaml:"authResponseHeaders,omitempty"`
+	AuthRequestHeaders  []string   `json:"authRequestHeaders,omitempty" toml:"authRequestHeaders,omitempty" yaml:"authRequestHeaders,omitempty"`
+	// ForwardBody sends the request body to the authentication server, with a POST request.
+	ForwardBody bool `json:"forwardBody,omitempty" toml:"forwardBody,omitempty" yaml:"forwardBody,omitempty" export:"true"`
+	// MaxBodySize is the maximum size, in bytes, of the forwarded body. It defaults to 1MiB, and -1 means no limit.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// GRPC calls the authentication server with the Envoy ext_authz gRPC API (envoy.service.auth.v3.Authorization).
+	GRPC   bool    `json:"grpc,omitempty" toml:"grpc,omitempty" yaml:"grpc,omitempty" export:"true"`
+	SPIFFE *SPIFFE `json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SPIFFE holds the SPIFFE IDs accepted for the certificate of a server.
+type SPIFFE struct {
+	IDs         []string `json:"ids,omitempty" toml:"ids,omitempty" yaml:"ids,omitempty"`
+	TrustDomain string   `json:"trustDomain,omitempty" toml:"trustDomain,omitempty" yaml:"trustDomain,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -191,6 +266,12 @@ type Headers struct {
 	ReferrerPolicy          string            `json:"referrerPolicy,omitempty" toml:"referrerPolicy,omitempty" yaml:"referrerPolicy,omitempty"`
 	FeaturePolicy           string            `json:"featurePolicy,omitempty" toml:"featurePolicy,omitempty" yaml:"featurePolicy,omitempty"`
 	IsDevelopment           bool              `json:"isDevelopment,omitempty" toml:"isDevelopment,omitempty" yaml:"isDevelopment,omitempty"`
+
+	// ContentSecurityPolicyNonceHeader is the request header forwarding to the service the nonce generated for each request,
+	// which replaces the $NONCE placeholders of the ContentSecurityPolicy.
+	ContentSecurityPolicyNonceHeader string `json:"contentSecurityPolicyNonceHeader,omitempty" toml:"contentSecurityPolicyNonceHeader,omitempty" yaml:"contentSecurityPolicyNonceHeader,omitempty" export:"true"`
+	// SecurityHeaders is a preset of security headers (modern, strict, or api), whose values are overridden by the other options.
+	SecurityHeaders string `json:"securityHeaders,omitempty" toml:"securityHeaders,omitempty" yaml:"securityHeaders,omitempty" export:"true"`
 }
 
 // HasCustomHeadersDefined checks to see if any of the custom header elements have been set.
@@ -229,10 +310,12 @@ func (h *Headers) HasSecureHeadersDefined() bool {
 		h.BrowserXSSFilter ||
 		h.CustomBrowserXSSValue != "" ||
 		h.ContentSecurityPolicy != "" ||
+		h.ContentSecurityPolicyNonceHeader != "" ||
 		h.PublicKey != "" ||
 		h.ReferrerPolicy != "" ||
 		h.FeaturePolicy != "" ||
-		h.IsDevelopment)
+		h.IsDevelopment ||
+		h.SecurityHeaders != "")
 }
 
 // +k8s:deepcopy-gen=true
@@ -241,6 +324,8 @@ func (h *Headers) HasSecureHeadersDefined() bool {
 type IPStrategy struct {
 	Depth       int      `json:"depth,omitempty" toml:"depth,omitempty" yaml:"depth,omitempty" export:"true"`
 	ExcludedIPs []string `json:"excludedIPs,omitempty" toml:"excludedIPs,omitempty" yaml:"excludedIPs,omitempty"`
+	// ExcludedIPSets are the names of the IP sets whose IPs are excluded, as the ExcludedIPs.
+	ExcludedIPSets []string `json:"excludedIPSets,omitempty" toml:"excludedIPSets,omitempty" yaml:"excludedIPSets,omitempty"`
 	// TODO(mpl): I think we should make RemoteAddr an explicit field. For one thing, it would yield better documentation.
 }
 
@@ -248,7 +333,8 @@ type IPStrategy struct {
 // If nil return the RemoteAddr strategy
 // else return a strategy base on the configuration using the X-Forwarded-For Header.
 // Depth override the ExcludedIPs.
-func (s *IPStrategy) Get() (ip.Strategy, error) {
+// The ExcludedIPSets are looked up in the IP sets.
+func (s *IPStrategy) Get(ctx context.Context, sets ip.Sets) (ip.Strategy, error) {
 	if s == nil {
 		return &ip.RemoteAddrStrategy{}, nil
 	}
@@ -259,8 +345,13 @@ func (s *IPStrategy) Get() (ip.Strategy, error) {
 		}, nil
 	}
 
-	if len(s.ExcludedIPs) > 0 {
-		checker, err := ip.NewChecker(s.ExcludedIPs)
+	if len(s.ExcludedIPs) > 0 || len(s.ExcludedIPSets) > 0 {
+		excludedSets, err := ip.GetSets(ctx, sets, s.ExcludedIPSets)
+		if err != nil {
+			return nil, err
+		}
+
+		checker, err := ip.NewChecker(s.ExcludedIPs, excludedSets...)
 		if err != nil {
 			return nil, err
 		}
@@ -278,6 +369,8 @@ func (s *IPStrategy) Get() (ip.Strategy, error) {
 type IPWhiteList struct {
 	SourceRange []string    `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
 	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty"  label:"allowEmpty" file:"allowEmpty"`
+	// IPSets are the names of the IP sets whose IPs are allowed, in addition to the SourceRange.
+	IPSets []string `json:"ipSets,omitempty" toml:"ipSets,omitempty" yaml:"ipSets,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -290,6 +383,61 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// LDAPAuth holds the LDAP authentication configuration.
+// The clients authenticate with the HTTP basic authentication scheme, and their credentials are verified by binding to the LDAP server.
+type LDAPAuth struct {
+	// URL is the address of the LDAP server, e.g. ldap://ldap.example.com or ldaps://ldap.example.com.
+	URL string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	// StartTLS upgrades the ldap:// connections to TLS with the StartTLS operation.
+	StartTLS bool       `json:"startTLS,omitempty" toml:"startTLS,omitempty" yaml:"startTLS,omitempty" export:"true"`
+	TLS      *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+	// BaseDN is the DN of the entry under which the users are searched.
+	BaseDN string `json:"baseDN,omitempty" toml:"baseDN,omitempty" yaml:"baseDN,omitempty"`
+	// Attribute is the attribute matching the usernames. It defaults to uid.
+	Attribute string `json:"attribute,omitempty" toml:"attribute,omitempty" yaml:"attribute,omitempty" export:"true"`
+	// SearchFilter is an additional filter the users must match, e.g. (objectClass=person).
+	SearchFilter string `json:"searchFilter,omitempty" toml:"searchFilter,omitempty" yaml:"searchFilter,omitempty"`
+	// BindDN and BindPassword are the credentials used to search the users and their groups.
+	// Without BindDN, the users are bound with the <attribute>=<username>,<baseDN> DN.
+	BindDN       string `json:"bindDN,omitempty" toml:"bindDN,omitempty" yaml:"bindDN,omitempty"`
+	BindPassword string `json:"bindPassword,omitempty" toml:"bindPassword,omitempty" yaml:"bindPassword,omitempty"`
+	// AllowedGroups are the DNs of the groups the users must be a member of, at least one of them.
+	AllowedGroups []string `json:"allowedGroups,omitempty" toml:"allowedGroups,omitempty" yaml:"allowedGroups,omitempty"`
+	// GroupMemberAttribute is the attribute of the groups holding the DNs of their members. It defaults to member.
+	GroupMemberAttribute string `json:"groupMemberAttribute,omitempty" toml:"groupMemberAttribute,omitempty" yaml:"groupMemberAttribute,omitempty" export:"true"`
+	// PoolSize is the maximum number of idle connections to the LDAP server. It defaults to 10.
+	PoolSize int `json:"poolSize,omitempty" toml:"poolSize,omitempty" yaml:"poolSize,omitempty" export:"true"`
+	// CacheDuration is how long a successful authentication is cached. It defaults to 0, which means no caching.
+	CacheDuration ptypes.Duration `json:"cacheDuration,omitempty" toml:"cacheDuration,omitempty" yaml:"cacheDuration,omitempty" export:"true"`
+	Realm         string          `json:"realm,omitempty" toml:"realm,omitempty" yaml:"realm,omitempty"`
+	RemoveHeader  bool            `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty" export:"true"`
+	HeaderField   string          `json:"headerField,omitempty" toml:"headerField,omitempty" yaml:"headerField,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// OPA holds the Open Policy Agent middleware configuration.
+// This middleware authorizes the requests with the decisions of a policy evaluated by an OPA server,
+// or by the OPA engine embedded in Traefik.
+type OPA struct {
+	// URL is the address of the OPA server, e.g. http://localhost:8181.
+	URL string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	// Bundle is the path of the bundle (a directory or a .tar.gz file) evaluated by the embedded OPA engine, instead of querying an OPA server.
+	Bundle string `json:"bundle,omitempty" toml:"bundle,omitempty" yaml:"bundle,omitempty"`
+	// ReloadInterval is the interval at which the bundle is checked for changes, and reloaded when modified (10s when 0, never when negative).
+	ReloadInterval ptypes.Duration `json:"reloadInterval,omitempty" toml:"reloadInterval,omitempty" yaml:"reloadInterval,omitempty" export:"true"`
+	// Policy is the path of the decision in the OPA documents, e.g. httpapi/authz/allow.
+	Policy string `json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty" export:"true"`
+	// ForwardBody adds the request body to the input document.
+	ForwardBody bool `json:"forwardBody,omitempty" toml:"forwardBody,omitempty" yaml:"forwardBody,omitempty" export:"true"`
+	// MaxBodySize is the maximum size in bytes of the bodies added to the input document (1MiB when 0, no limit when -1).
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// DecisionLog logs the decisions, with their ID, at the INFO level.
+	DecisionLog bool `json:"decisionLog,omitempty" toml:"decisionLog,omitempty" yaml:"decisionLog,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the TLS client cert headers configuration.
 type PassTLSClientCert struct {
 	PEM  bool                      `json:"pem,omitempty" toml:"pem,omitempty" yaml:"pem,omitempty"`
@@ -298,6 +446,69 @@ type PassTLSClientCert struct {
 
 // +k8s:deepcopy-gen=true
 
+// QoS holds the QoS configuration.
+// This middleware sends the requests to the servers on connections marked with a DSCP value, for the network to prioritize them.
+type QoS struct {
+	// DSCP is the name of a standard class (e.g. EF or AF41), or a number between 0 and 63.
+	DSCP string `json:"dscp,omitempty" toml:"dscp,omitempty" yaml:"dscp,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SAML holds the SAML service provider configuration.
+// This middleware authenticates the users with the single sign-on of a SAML identity provider.
+type SAML struct {
+	// RootURL is the external URL of the service, e.g. https://app.example.com,
+	// under which the assertion consumer service (/saml/acs) and the metadata (/saml/metadata) are served.
+	RootURL string `json:"rootURL,omitempty" toml:"rootURL,omitempty" yaml:"rootURL,omitempty"`
+	// EntityID is the entity ID of the service provider. It defaults to the URL of its metadata.
+	EntityID string `json:"entityID,omitempty" toml:"entityID,omitempty" yaml:"entityID,omitempty"`
+	// IDPMetadata is the metadata of the identity provider, as a file path or its content.
+	IDPMetadata string `json:"idpMetadata,omitempty" toml:"idpMetadata,omitempty" yaml:"idpMetadata,omitempty"`
+	// Certificate and Key are the certificate and private key of the service provider, as file paths or contents.
+	// The identity provider encrypts the assertions with the certificate, and the key also signs the session cookies.
+	Certificate string `json:"certificate,omitempty" toml:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Key         string `json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty"`
+	// NameIDFormat is the format of the NameID requested to the identity provider.
+	NameIDFormat string `json:"nameIDFormat,omitempty" toml:"nameIDFormat,omitempty" yaml:"nameIDFormat,omitempty" export:"true"`
+	// AuthnContextClassRefs are the authentication context classes (e.g. a multi-factor authentication) required from the users.
+	// The sessions opened with another class are elevated by authenticating the users again with one of these classes.
+	AuthnContextClassRefs []string `json:"authnContextClassRefs,omitempty" toml:"authnContextClassRefs,omitempty" yaml:"authnContextClassRefs,omitempty" export:"true"`
+	// SessionDuration is the lifetime of the sessions. It defaults to 8h.
+	SessionDuration ptypes.Duration `json:"sessionDuration,omitempty" toml:"sessionDuration,omitempty" yaml:"sessionDuration,omitempty" export:"true"`
+	// CookieName is the name of the session cookie. It defaults to traefik_saml.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty" export:"true"`
+	// HeaderField is the header forwarding the NameID of the users.
+	HeaderField string `json:"headerField,omitempty" toml:"headerField,omitempty" yaml:"headerField,omitempty" export:"true"`
+	// AttributeHeaders are the SAML attributes (by name or friendly name) forwarded to the service, by header.
+	AttributeHeaders map[string]string `json:"attributeHeaders,omitempty" toml:"attributeHeaders,omitempty" yaml:"attributeHeaders,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SPNEGOAuth holds the Kerberos authentication configuration, with the SPNEGO (Negotiate) HTTP authentication scheme.
+type SPNEGOAuth struct {
+	Keytab           string `json:"keytab,omitempty" toml:"keytab,omitempty" yaml:"keytab,omitempty"`
+	ServicePrincipal string `json:"servicePrincipal,omitempty" toml:"servicePrincipal,omitempty" yaml:"servicePrincipal,omitempty" export:"true"`
+	RemoveHeader     bool   `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty" export:"true"`
+	HeaderField      string `json:"headerField,omitempty" toml:"headerField,omitempty" yaml:"headerField,omitempty" export:"true"`
+	StripRealm       bool   `json:"stripRealm,omitempty" toml:"stripRealm,omitempty" yaml:"stripRealm,omitempty" export:"true"`
+	// Delegation forwards the credentials of the clients to the service, with the Kerberos constrained delegation (S4U2Proxy).
+	Delegation *SPNEGODelegation `json:"delegation,omitempty" toml:"delegation,omitempty" yaml:"delegation,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SPNEGODelegation holds the Kerberos constrained delegation (S4U2Proxy) configuration.
+type SPNEGODelegation struct {
+	// Krb5Conf is the path of the krb5.conf file locating the KDC of the realm.
+	Krb5Conf string `json:"krb5Conf,omitempty" toml:"krb5Conf,omitempty" yaml:"krb5Conf,omitempty"`
+	// ServicePrincipal is the principal of the service (e.g. HTTP/backend.example.com) the tickets are requested for.
+	ServicePrincipal string `json:"servicePrincipal,omitempty" toml:"servicePrincipal,omitempty" yaml:"servicePrincipal,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // SourceCriterion defines what criterion is used to group requests as originating from a common source.
 // If none are set, the default is to use the request's remote address field.
 // All fields are mutually exclusive.
@@ -305,6 +516,10 @@ type SourceCriterion struct {
 	IPStrategy        *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty"`
 	RequestHeaderName string      `json:"requestHeaderName,omitempty" toml:"requestHeaderName,omitempty" yaml:"requestHeaderName,omitempty"`
 	RequestHost       bool        `json:"requestHost,omitempty" toml:"requestHost,omitempty" yaml:"requestHost,omitempty"`
+	// RequestQueryParameterName groups the requests by the value of the query parameter.
+	RequestQueryParameterName string `json:"requestQueryParameterName,omitempty" toml:"requestQueryParameterName,omitempty" yaml:"requestQueryParameterName,omitempty"`
+	// RequestCookieName groups the requests by the value of the cookie.
+	RequestCookieName string `json:"requestCookieName,omitempty" toml:"requestCookieName,omitempty" yaml:"requestCookieName,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -326,6 +541,9 @@ type RateLimit struct {
 	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty"`
 
 	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty"`
+
+	// Redis stores the token buckets in Redis, to share them with the other instances using the same Redis.
+	Redis *RateLimitRedis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -336,6 +554,40 @@ func (r *RateLimit) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// RateLimitRedis holds the configuration of the Redis storing the token buckets of a RateLimit middleware.
+// The local token buckets are used while Redis is unreachable.
+type RateLimitRedis struct {
+	// Endpoints are the addresses of the Redis server,
+	// of the sentinels when Sentinel is set, or of the seed nodes of the cluster when Cluster is set.
+	Endpoints []string `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// Username is the ACL user, the default user being used when empty.
+	Username string         `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password string         `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	DB       int            `json:"db,omitempty" toml:"db,omitempty" yaml:"db,omitempty"`
+	Sentinel *RedisSentinel `json:"sentinel,omitempty" toml:"sentinel,omitempty" yaml:"sentinel,omitempty"`
+	Cluster  bool           `json:"cluster,omitempty" toml:"cluster,omitempty" yaml:"cluster,omitempty"`
+	TLS      *ClientTLS     `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+	// Prefix is the prefix of the keys of the token buckets, followed by the name of the middleware and the source.
+	Prefix string `json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty"`
+}
+
+// SetDefaults sets the default values on a RateLimitRedis.
+func (r *RateLimitRedis) SetDefaults() {
+	r.Endpoints = []string{"127.0.0.1:6379"}
+	r.Prefix = "traefik/ratelimit"
+}
+
+// +k8s:deepcopy-gen=true
+
+// RedisSentinel holds the configuration of the Redis Sentinel monitoring the master.
+type RedisSentinel struct {
+	MasterName string `json:"masterName,omitempty" toml:"masterName,omitempty" yaml:"masterName,omitempty"`
+	Username   string `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password   string `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // RedirectRegex holds the redirection configuration.
 type RedirectRegex struct {
 	Regex       string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty"`
@@ -371,7 +623,51 @@ type ReplacePathRegex struct {
 
 // Retry holds the retry configuration.
 type Retry struct {
-	Attempts int `json:"attempts,omitempty" toml:"attempts,omitempty" yaml:"attempts,omitempty" export:"true"`
+	Attempts int          `json:"attempts,omitempty" toml:"attempts,omitempty" yaml:"attempts,omitempty" export:"true"`
+	Budget   *RetryBudget `json:"budget,omitempty" toml:"budget,omitempty" yaml:"budget,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RetryBudget limits the retries to a percentage of the requests over a sliding window,
+// shared by the retry middlewares of the routers targeting the same service.
+type RetryBudget struct {
+	Percent             int             `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+	MinRetriesPerSecond int             `json:"minRetriesPerSecond,omitempty" toml:"minRetriesPerSecond,omitempty" yaml:"minRetriesPerSecond,omitempty" export:"true"`
+	Window              ptypes.Duration `json:"window,omitempty" toml:"window,omitempty" yaml:"window,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a RetryBudget.
+func (r *RetryBudget) SetDefaults() {
+	r.Percent = 20
+	r.MinRetriesPerSecond = 10
+	r.Window = ptypes.Duration(10 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// S3 holds the S3 configuration.
+// This middleware fronts an S3-compatible object storage: it rewrites the bucket addressing style,
+// signs again the requests whose host is rewritten, and verifies the uploaded payloads.
+type S3 struct {
+	Domain         string       `json:"domain,omitempty" toml:"domain,omitempty" yaml:"domain,omitempty"`
+	UpstreamDomain string       `json:"upstreamDomain,omitempty" toml:"upstreamDomain,omitempty" yaml:"upstreamDomain,omitempty"`
+	BucketStyle    string       `json:"bucketStyle,omitempty" toml:"bucketStyle,omitempty" yaml:"bucketStyle,omitempty" export:"true"`
+	Signature      *S3Signature `json:"signature,omitempty" toml:"signature,omitempty" yaml:"signature,omitempty"`
+	VerifyPayload  bool         `json:"verifyPayload,omitempty" toml:"verifyPayload,omitempty" yaml:"verifyPayload,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// S3Signature holds the credentials used to sign the requests with the AWS Signature Version 4,
+// and the ones of the clients, used to verify their signatures beforehand.
+type S3Signature struct {
+	AccessKeyID       string   `json:"accessKeyID,omitempty" toml:"accessKeyID,omitempty" yaml:"accessKeyID,omitempty"`
+	SecretAccessKey   string   `json:"secretAccessKey,omitempty" toml:"secretAccessKey,omitempty" yaml:"secretAccessKey,omitempty"`
+	SessionToken      string   `json:"sessionToken,omitempty" toml:"sessionToken,omitempty" yaml:"sessionToken,omitempty"`
+	Region            string   `json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty" export:"true"`
+	Service           string   `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	ClientCredentials []string `json:"clientCredentials,omitempty" toml:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
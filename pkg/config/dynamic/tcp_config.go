@@ -2,8 +2,10 @@ package dynamic
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/types"
+	ptypes "github.com/traefik/paerser/types"
 )
 
 // +k8s:deepcopy-gen=true
@@ -74,6 +76,7 @@ type TCPServersLoadBalancer struct {
 	// means an infinite deadline (i.e. the reading capability is never closed).
 	TerminationDelay *int        `json:"terminationDelay,omitempty" toml:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty"`
 	Servers          []TCPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	Sticky           *TCPSticky  `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty" file:"allowEmpty"`
 }
 
 // SetDefaults Default values for a TCPServersLoadBalancer.
@@ -101,6 +104,26 @@ func (l *TCPServersLoadBalancer) Mergeable(loadBalancer *TCPServersLoadBalancer)
 
 // +k8s:deepcopy-gen=true
 
+// TCPSticky holds the sticky configuration of the TCP services.
+type TCPSticky struct {
+	Affinity *TCPAffinity `json:"affinity,omitempty" toml:"affinity,omitempty" yaml:"affinity,omitempty" label:"allowEmpty" file:"allowEmpty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPAffinity holds the sticky configuration based on the affinity table,
+// the clients being identified by their IP address.
+type TCPAffinity struct {
+	TTL ptypes.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// SetDefaults Default values for a TCPAffinity.
+func (a *TCPAffinity) SetDefaults() {
+	a.TTL = ptypes.Duration(time.Hour)
+}
+
+// +k8s:deepcopy-gen=true
+
 // TCPServer holds a TCP Server configuration.
 type TCPServer struct {
 	Address string `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" label:"-"`
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -33,6 +34,22 @@ import (
 	types "github.com/containous/traefik/v2/pkg/types"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveConcurrency) DeepCopyInto(out *AdaptiveConcurrency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveConcurrency.
+func (in *AdaptiveConcurrency) DeepCopy() *AdaptiveConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddPrefix) DeepCopyInto(out *AddPrefix) {
 	*out = *in
@@ -49,6 +66,22 @@ func (in *AddPrefix) DeepCopy() *AddPrefix {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Affinity) DeepCopyInto(out *Affinity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Affinity.
+func (in *Affinity) DeepCopy() *Affinity {
+	if in == nil {
+		return nil
+	}
+	out := new(Affinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Auth) DeepCopyInto(out *Auth) {
 	*out = *in
@@ -125,6 +158,13 @@ func (in *Chain) DeepCopyInto(out *Chain) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -170,6 +210,27 @@ func (in *ClientTLS) DeepCopy() *ClientTLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Coalescing) DeepCopyInto(out *Coalescing) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Coalescing.
+func (in *Coalescing) DeepCopy() *Coalescing {
+	if in == nil {
+		return nil
+	}
+	out := new(Coalescing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Compress) DeepCopyInto(out *Compress) {
 	*out = *in
@@ -310,6 +371,81 @@ func (in *DigestAuth) DeepCopy() *DigestAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EarlyHints) DeepCopyInto(out *EarlyHints) {
+	*out = *in
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EarlyHints.
+func (in *EarlyHints) DeepCopy() *EarlyHints {
+	if in == nil {
+		return nil
+	}
+	out := new(EarlyHints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Egress) DeepCopyInto(out *Egress) {
+	*out = *in
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]EgressDestination, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Egress.
+func (in *Egress) DeepCopy() *Egress {
+	if in == nil {
+		return nil
+	}
+	out := new(Egress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressDestination) DeepCopyInto(out *EgressDestination) {
+	*out = *in
+	if in.PinnedPublicKeys != nil {
+		in, out := &in.PinnedPublicKeys, &out.PinnedPublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(Retry)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreaker)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressDestination.
+func (in *EgressDestination) DeepCopy() *EgressDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ErrorPage) DeepCopyInto(out *ErrorPage) {
 	*out = *in
@@ -318,6 +454,11 @@ func (in *ErrorPage) DeepCopyInto(out *ErrorPage) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]ErrorPageTemplate, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -331,6 +472,22 @@ func (in *ErrorPage) DeepCopy() *ErrorPage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ErrorPageTemplate) DeepCopyInto(out *ErrorPageTemplate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErrorPageTemplate.
+func (in *ErrorPageTemplate) DeepCopy() *ErrorPageTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ErrorPageTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ForwardAuth) DeepCopyInto(out *ForwardAuth) {
 	*out = *in
@@ -344,6 +501,16 @@ func (in *ForwardAuth) DeepCopyInto(out *ForwardAuth) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AuthRequestHeaders != nil {
+		in, out := &in.AuthRequestHeaders, &out.AuthRequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SPIFFE != nil {
+		in, out := &in.SPIFFE, &out.SPIFFE
+		*out = new(SPIFFE)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -420,6 +587,21 @@ func (in *HTTPConfiguration) DeepCopyInto(out *HTTPConfiguration) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.IPSets != nil {
+		in, out := &in.IPSets, &out.IPSets
+		*out = make(map[string]*IPSet, len(*in))
+		for key, val := range *in {
+			var outVal *IPSet
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(IPSet)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -528,6 +710,43 @@ func (in *HealthCheck) DeepCopy() *HealthCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostHeader) DeepCopyInto(out *HostHeader) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostHeader.
+func (in *HostHeader) DeepCopy() *HostHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(HostHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPSet) DeepCopyInto(out *IPSet) {
+	*out = *in
+	if in.SourceRange != nil {
+		in, out := &in.SourceRange, &out.SourceRange
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPSet.
+func (in *IPSet) DeepCopy() *IPSet {
+	if in == nil {
+		return nil
+	}
+	out := new(IPSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPStrategy) DeepCopyInto(out *IPStrategy) {
 	*out = *in
@@ -536,6 +755,11 @@ func (in *IPStrategy) DeepCopyInto(out *IPStrategy) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExcludedIPSets != nil {
+		in, out := &in.ExcludedIPSets, &out.ExcludedIPSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -562,6 +786,11 @@ func (in *IPWhiteList) DeepCopyInto(out *IPWhiteList) {
 		*out = new(IPStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IPSets != nil {
+		in, out := &in.IPSets, &out.IPSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -596,6 +825,32 @@ func (in *InFlightReq) DeepCopy() *InFlightReq {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPAuth) DeepCopyInto(out *LDAPAuth) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ClientTLS)
+		**out = **in
+	}
+	if in.AllowedGroups != nil {
+		in, out := &in.AllowedGroups, &out.AllowedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPAuth.
+func (in *LDAPAuth) DeepCopy() *LDAPAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Message) DeepCopyInto(out *Message) {
 	*out = *in
@@ -723,13 +978,53 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 	if in.Retry != nil {
 		in, out := &in.Retry, &out.Retry
 		*out = new(Retry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.ContentType != nil {
 		in, out := &in.ContentType, &out.ContentType
 		*out = new(ContentType)
 		**out = **in
 	}
+	if in.OPA != nil {
+		in, out := &in.OPA, &out.OPA
+		*out = new(OPA)
+		**out = **in
+	}
+	if in.SPNEGOAuth != nil {
+		in, out := &in.SPNEGOAuth, &out.SPNEGOAuth
+		*out = new(SPNEGOAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAPAuth != nil {
+		in, out := &in.LDAPAuth, &out.LDAPAuth
+		*out = new(LDAPAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SAML != nil {
+		in, out := &in.SAML, &out.SAML
+		*out = new(SAML)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EarlyHints != nil {
+		in, out := &in.EarlyHints, &out.EarlyHints
+		*out = new(EarlyHints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdaptiveConcurrency != nil {
+		in, out := &in.AdaptiveConcurrency, &out.AdaptiveConcurrency
+		*out = new(AdaptiveConcurrency)
+		**out = **in
+	}
+	if in.QoS != nil {
+		in, out := &in.QoS, &out.QoS
+		*out = new(QoS)
+		**out = **in
+	}
 	if in.Plugin != nil {
 		in, out := &in.Plugin, &out.Plugin
 		*out = make(map[string]PluginConf, len(*in))
@@ -818,6 +1113,22 @@ func (in *Model) DeepCopy() *Model {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OPA) DeepCopyInto(out *OPA) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OPA.
+func (in *OPA) DeepCopy() *OPA {
+	if in == nil {
+		return nil
+	}
+	out := new(OPA)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PassTLSClientCert) DeepCopyInto(out *PassTLSClientCert) {
 	*out = *in
@@ -839,6 +1150,64 @@ func (in *PassTLSClientCert) DeepCopy() *PassTLSClientCert {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathNormalization) DeepCopyInto(out *PathNormalization) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]PathNormalizationRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathNormalization.
+func (in *PathNormalization) DeepCopy() *PathNormalization {
+	if in == nil {
+		return nil
+	}
+	out := new(PathNormalization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathNormalizationRule) DeepCopyInto(out *PathNormalizationRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathNormalizationRule.
+func (in *PathNormalizationRule) DeepCopy() *PathNormalizationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PathNormalizationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QoS) DeepCopyInto(out *QoS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoS.
+func (in *QoS) DeepCopy() *QoS {
+	if in == nil {
+		return nil
+	}
+	out := new(QoS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RateLimit) DeepCopyInto(out *RateLimit) {
 	*out = *in
@@ -847,6 +1216,11 @@ func (in *RateLimit) DeepCopyInto(out *RateLimit) {
 		*out = new(SourceCriterion)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(RateLimitRedis)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -860,6 +1234,37 @@ func (in *RateLimit) DeepCopy() *RateLimit {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitRedis) DeepCopyInto(out *RateLimitRedis) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sentinel != nil {
+		in, out := &in.Sentinel, &out.Sentinel
+		*out = new(RedisSentinel)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ClientTLS)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitRedis.
+func (in *RateLimitRedis) DeepCopy() *RateLimitRedis {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitRedis)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedirectRegex) DeepCopyInto(out *RedirectRegex) {
 	*out = *in
@@ -892,6 +1297,22 @@ func (in *RedirectScheme) DeepCopy() *RedirectScheme {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinel) DeepCopyInto(out *RedisSentinel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinel.
+func (in *RedisSentinel) DeepCopy() *RedisSentinel {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinel)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplacePath) DeepCopyInto(out *ReplacePath) {
 	*out = *in
@@ -943,6 +1364,11 @@ func (in *ResponseForwarding) DeepCopy() *ResponseForwarding {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Retry) DeepCopyInto(out *Retry) {
 	*out = *in
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(RetryBudget)
+		**out = **in
+	}
 	return
 }
 
@@ -956,6 +1382,22 @@ func (in *Retry) DeepCopy() *Retry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBudget) DeepCopyInto(out *RetryBudget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBudget.
+func (in *RetryBudget) DeepCopy() *RetryBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Router) DeepCopyInto(out *Router) {
 	*out = *in
@@ -974,6 +1416,21 @@ func (in *Router) DeepCopyInto(out *Router) {
 		*out = new(RouterTLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HTTPVersions != nil {
+		in, out := &in.HTTPVersions, &out.HTTPVersions
+		*out = new(RouterHTTPVersions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Deadline != nil {
+		in, out := &in.Deadline, &out.Deadline
+		*out = new(RouterDeadline)
+		**out = **in
+	}
+	if in.Pacing != nil {
+		in, out := &in.Pacing, &out.Pacing
+		*out = new(RouterPacing)
+		**out = **in
+	}
 	return
 }
 
@@ -987,6 +1444,64 @@ func (in *Router) DeepCopy() *Router {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterDeadline) DeepCopyInto(out *RouterDeadline) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterDeadline.
+func (in *RouterDeadline) DeepCopy() *RouterDeadline {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterDeadline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterHTTPVersions) DeepCopyInto(out *RouterHTTPVersions) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Forbidden != nil {
+		in, out := &in.Forbidden, &out.Forbidden
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterHTTPVersions.
+func (in *RouterHTTPVersions) DeepCopy() *RouterHTTPVersions {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterHTTPVersions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterPacing) DeepCopyInto(out *RouterPacing) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterPacing.
+func (in *RouterPacing) DeepCopy() *RouterPacing {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterPacing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RouterTCPTLSConfig) DeepCopyInto(out *RouterTCPTLSConfig) {
 	*out = *in
@@ -1033,6 +1548,134 @@ func (in *RouterTLSConfig) DeepCopy() *RouterTLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPIFFE) DeepCopyInto(out *SPIFFE) {
+	*out = *in
+	if in.IDs != nil {
+		in, out := &in.IDs, &out.IDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPIFFE.
+func (in *SPIFFE) DeepCopy() *SPIFFE {
+	if in == nil {
+		return nil
+	}
+	out := new(SPIFFE)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3) DeepCopyInto(out *S3) {
+	*out = *in
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(S3Signature)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3.
+func (in *S3) DeepCopy() *S3 {
+	if in == nil {
+		return nil
+	}
+	out := new(S3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Signature) DeepCopyInto(out *S3Signature) {
+	*out = *in
+	if in.ClientCredentials != nil {
+		in, out := &in.ClientCredentials, &out.ClientCredentials
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Signature.
+func (in *S3Signature) DeepCopy() *S3Signature {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Signature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAML) DeepCopyInto(out *SAML) {
+	*out = *in
+	if in.AuthnContextClassRefs != nil {
+		in, out := &in.AuthnContextClassRefs, &out.AuthnContextClassRefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AttributeHeaders != nil {
+		in, out := &in.AttributeHeaders, &out.AttributeHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAML.
+func (in *SAML) DeepCopy() *SAML {
+	if in == nil {
+		return nil
+	}
+	out := new(SAML)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPNEGOAuth) DeepCopyInto(out *SPNEGOAuth) {
+	*out = *in
+	if in.Delegation != nil {
+		in, out := &in.Delegation, &out.Delegation
+		*out = new(SPNEGODelegation)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPNEGOAuth.
+func (in *SPNEGOAuth) DeepCopy() *SPNEGOAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(SPNEGOAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPNEGODelegation) DeepCopyInto(out *SPNEGODelegation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPNEGODelegation.
+func (in *SPNEGODelegation) DeepCopy() *SPNEGODelegation {
+	if in == nil {
+		return nil
+	}
+	out := new(SPNEGODelegation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Server) DeepCopyInto(out *Server) {
 	*out = *in
@@ -1077,6 +1720,31 @@ func (in *ServersLoadBalancer) DeepCopyInto(out *ServersLoadBalancer) {
 		*out = new(ResponseForwarding)
 		**out = **in
 	}
+	if in.ServersTransports != nil {
+		in, out := &in.ServersTransports, &out.ServersTransports
+		*out = make([]ServersTransportSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.Coalescing != nil {
+		in, out := &in.Coalescing, &out.Coalescing
+		*out = new(Coalescing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QoS != nil {
+		in, out := &in.QoS, &out.QoS
+		*out = new(QoS)
+		**out = **in
+	}
+	if in.PathNormalization != nil {
+		in, out := &in.PathNormalization, &out.PathNormalization
+		*out = new(PathNormalization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostHeader != nil {
+		in, out := &in.HostHeader, &out.HostHeader
+		*out = new(HostHeader)
+		**out = **in
+	}
 	return
 }
 
@@ -1090,6 +1758,22 @@ func (in *ServersLoadBalancer) DeepCopy() *ServersLoadBalancer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServersTransportSelector) DeepCopyInto(out *ServersTransportSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServersTransportSelector.
+func (in *ServersTransportSelector) DeepCopy() *ServersTransportSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ServersTransportSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -1108,6 +1792,11 @@ func (in *Service) DeepCopyInto(out *Service) {
 		*out = new(Mirroring)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = new(Egress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1150,6 +1839,11 @@ func (in *Sticky) DeepCopyInto(out *Sticky) {
 		*out = new(Cookie)
 		**out = **in
 	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(Affinity)
+		**out = **in
+	}
 	return
 }
 
@@ -1205,6 +1899,22 @@ func (in *StripPrefixRegex) DeepCopy() *StripPrefixRegex {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPAffinity) DeepCopyInto(out *TCPAffinity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPAffinity.
+func (in *TCPAffinity) DeepCopy() *TCPAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPConfiguration) DeepCopyInto(out *TCPConfiguration) {
 	*out = *in
@@ -1306,6 +2016,11 @@ func (in *TCPServersLoadBalancer) DeepCopyInto(out *TCPServersLoadBalancer) {
 		*out = make([]TCPServer, len(*in))
 		copy(*out, *in)
 	}
+	if in.Sticky != nil {
+		in, out := &in.Sticky, &out.Sticky
+		*out = new(TCPSticky)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1345,6 +2060,27 @@ func (in *TCPService) DeepCopy() *TCPService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPSticky) DeepCopyInto(out *TCPSticky) {
+	*out = *in
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(TCPAffinity)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPSticky.
+func (in *TCPSticky) DeepCopy() *TCPSticky {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPSticky)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPWRRService) DeepCopyInto(out *TCPWRRService) {
 	*out = *in
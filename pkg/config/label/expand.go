@@ -0,0 +1,129 @@
+package label
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/paerser/parser"
+)
+
+// expandOption is the option of a router listing, as a JSON array, the routers it is expanded into.
+const expandOption = "expand"
+
+// isExpandKey returns whether the parts of the key are the ones of the expansion of a router,
+// i.e. traefik.<http|tcp|udp>.routers.<name>.expand.
+func isExpandKey(parts []string) bool {
+	if len(parts) != 5 || !strings.EqualFold(parts[0], parser.DefaultRootName) || !strings.EqualFold(parts[2], "routers") || !strings.EqualFold(parts[4], expandOption) {
+		return false
+	}
+
+	for _, protocol := range []string{"http", "tcp", "udp"} {
+		if strings.EqualFold(parts[1], protocol) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseExpansion parses the JSON array of the routers a router is expanded into,
+// each router being an object of the values of its options, keyed by the options relative to the router.
+func parseExpansion(value string) ([]map[string]string, error) {
+	var items []map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, fmt.Errorf("a JSON array of objects is expected: %w", err)
+	}
+
+	expansion := make([]map[string]string, len(items))
+	for i, item := range items {
+		expansion[i] = make(map[string]string, len(item))
+		for option, v := range item {
+			switch v := v.(type) {
+			case string:
+				expansion[i][option] = v
+			case bool:
+				expansion[i][option] = strconv.FormatBool(v)
+			case float64:
+				expansion[i][option] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				return nil, fmt.Errorf("the value of the option %q of the item %d must be a string, a number or a boolean", option, i)
+			}
+		}
+	}
+
+	return expansion, nil
+}
+
+// expandRouters replaces each router having the expand option with the routers listed by its value, named <name>-<index>.
+// The expanded routers inherit the other options of the router, which they override.
+func expandRouters(labels map[string]string) (map[string]string, error) {
+	var expandKeys []string
+	for key := range labels {
+		if isExpandKey(strings.Split(key, ".")) {
+			expandKeys = append(expandKeys, key)
+		}
+	}
+
+	if len(expandKeys) == 0 {
+		return labels, nil
+	}
+
+	sort.Strings(expandKeys)
+
+	expanded := make(map[string]string, len(labels))
+	for key, value := range labels {
+		expanded[key] = value
+	}
+
+	for _, expandKey := range expandKeys {
+		parts := strings.Split(expandKey, ".")
+		routersPrefix := strings.Join(parts[:3], ".") + "."
+		routerPrefix := routersPrefix + parts[3] + "."
+
+		expansion, err := parseExpansion(labels[expandKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid expansion of the router %s: %w", parts[3], err)
+		}
+
+		base := make(map[string]string)
+		for key, value := range labels {
+			if len(key) <= len(routerPrefix) || !strings.EqualFold(key[:len(routerPrefix)], routerPrefix) {
+				continue
+			}
+
+			delete(expanded, key)
+			if key != expandKey {
+				base[key[len(routerPrefix):]] = value
+			}
+		}
+
+		for i, options := range expansion {
+			prefix := routersPrefix + parts[3] + "-" + strconv.Itoa(i) + "."
+
+			for option, value := range base {
+				if !overridden(options, option) {
+					expanded[prefix+option] = value
+				}
+			}
+			for option, value := range options {
+				expanded[prefix+option] = value
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+// overridden returns whether the option, whose case is ignored, is set by the options of an expanded router.
+func overridden(options map[string]string, option string) bool {
+	for o := range options {
+		if strings.EqualFold(o, option) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package label
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandRouters(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		labels      map[string]string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			desc: "no expansion",
+			labels: map[string]string{
+				"traefik.http.routers.foo.rule": "Host(`foo.bar`)",
+			},
+			expected: map[string]string{
+				"traefik.http.routers.foo.rule": "Host(`foo.bar`)",
+			},
+		},
+		{
+			desc: "expanded routers inherit and override the options of the router",
+			labels: map[string]string{
+				"traefik.http.routers.foo.rule":                      "Host(`default.bar`)",
+				"traefik.http.routers.foo.service":                   "foo",
+				"traefik.http.routers.foo.tls.certresolver":          "le",
+				"traefik.http.routers.foo.expand":                    `[{"Rule": "Host(` + "`foo.bar`" + `)"}, {"rule": "Host(` + "`bar.foo`" + `)", "priority": 10, "tls": false}]`,
+				"traefik.http.routers.bar.rule":                      "Host(`bar.bar`)",
+				"traefik.http.services.foo.loadbalancer.server.port": "80",
+			},
+			expected: map[string]string{
+				"traefik.http.routers.foo-0.Rule":                    "Host(`foo.bar`)",
+				"traefik.http.routers.foo-0.service":                 "foo",
+				"traefik.http.routers.foo-0.tls.certresolver":        "le",
+				"traefik.http.routers.foo-1.rule":                    "Host(`bar.foo`)",
+				"traefik.http.routers.foo-1.priority":                "10",
+				"traefik.http.routers.foo-1.service":                 "foo",
+				"traefik.http.routers.foo-1.tls":                     "false",
+				"traefik.http.routers.foo-1.tls.certresolver":        "le",
+				"traefik.http.routers.bar.rule":                      "Host(`bar.bar`)",
+				"traefik.http.services.foo.loadbalancer.server.port": "80",
+			},
+		},
+		{
+			desc: "empty expansion",
+			labels: map[string]string{
+				"traefik.tcp.routers.foo.rule":   "HostSNI(`*`)",
+				"traefik.tcp.routers.foo.expand": "[]",
+			},
+			expected: map[string]string{},
+		},
+		{
+			desc: "invalid JSON",
+			labels: map[string]string{
+				"traefik.http.routers.foo.expand": "Host(`foo.bar`)",
+			},
+			expectedErr: "invalid expansion of the router foo: a JSON array of objects is expected: invalid character 'H' looking for beginning of value",
+		},
+		{
+			desc: "invalid option value",
+			labels: map[string]string{
+				"traefik.http.routers.foo.expand": `[{"tls": {"certresolver": "le"}}]`,
+			},
+			expectedErr: `invalid expansion of the router foo: the value of the option "tls" of the item 0 must be a string, a number or a boolean`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			labels, err := expandRouters(test.labels)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, labels)
+		})
+	}
+}
+
+func TestDecodeConfiguration_expandedRouters(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.foo.service": "foo",
+		"traefik.http.routers.foo.expand":  `[{"rule": "Host(` + "`foo.bar`" + `)"}, {"rule": "Host(` + "`bar.foo`" + `)"}]`,
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	require.Len(t, conf.HTTP.Routers, 2)
+	assert.Equal(t, "Host(`foo.bar`)", conf.HTTP.Routers["foo-0"].Rule)
+	assert.Equal(t, "foo", conf.HTTP.Routers["foo-0"].Service)
+	assert.Equal(t, "Host(`bar.foo`)", conf.HTTP.Routers["foo-1"].Rule)
+	assert.Equal(t, "foo", conf.HTTP.Routers["foo-1"].Service)
+}
@@ -0,0 +1,68 @@
+package label
+
+import (
+	"sort"
+	"sync"
+)
+
+// ProviderIssue is an issue found in the labels of an element of a provider.
+type ProviderIssue struct {
+	Provider string `json:"provider"`
+	// Element identifies the element carrying the labels, e.g. a container.
+	Element string `json:"element"`
+	Issue
+}
+
+var issues = NewIssueRegistry()
+
+// GetIssueRegistry returns the registry of the issues found in the labels by the providers.
+func GetIssueRegistry() *IssueRegistry {
+	return issues
+}
+
+// IssueRegistry keeps the issues found in the labels of the last configuration of each provider.
+type IssueRegistry struct {
+	mu         sync.RWMutex
+	byProvider map[string][]ProviderIssue
+}
+
+// NewIssueRegistry creates a new IssueRegistry.
+func NewIssueRegistry() *IssueRegistry {
+	return &IssueRegistry{byProvider: make(map[string][]ProviderIssue)}
+}
+
+// Set replaces the issues of the provider.
+func (r *IssueRegistry) Set(providerName string, providerIssues []ProviderIssue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(providerIssues) == 0 {
+		delete(r.byProvider, providerName)
+		return
+	}
+
+	r.byProvider[providerName] = providerIssues
+}
+
+// Issues returns the issues of all the providers, sorted by provider, element and key.
+func (r *IssueRegistry) Issues() []ProviderIssue {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := []ProviderIssue{}
+	for _, providerIssues := range r.byProvider {
+		all = append(all, providerIssues...)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Provider != all[j].Provider {
+			return all[i].Provider < all[j].Provider
+		}
+		if all[i].Element != all[j].Element {
+			return all[i].Element < all[j].Element
+		}
+		return all[i].Key < all[j].Key
+	})
+
+	return all
+}
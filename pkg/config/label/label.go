@@ -7,14 +7,20 @@ import (
 )
 
 // DecodeConfiguration converts the labels to a configuration.
+// The routers having the expand option are first replaced with the routers it lists.
 func DecodeConfiguration(labels map[string]string) (*dynamic.Configuration, error) {
+	labels, err := expandRouters(labels)
+	if err != nil {
+		return nil, err
+	}
+
 	conf := &dynamic.Configuration{
 		HTTP: &dynamic.HTTPConfiguration{},
 		TCP:  &dynamic.TCPConfiguration{},
 		UDP:  &dynamic.UDPConfiguration{},
 	}
 
-	err := parser.Decode(labels, conf, parser.DefaultRootName, "traefik.http", "traefik.tcp", "traefik.udp")
+	err = parser.Decode(labels, conf, parser.DefaultRootName, "traefik.http", "traefik.tcp", "traefik.udp")
 	if err != nil {
 		return nil, err
 	}
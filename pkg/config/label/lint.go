@@ -0,0 +1,363 @@
+package label
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/traefik/paerser/parser"
+	"github.com/traefik/paerser/types"
+)
+
+// Codes of the issues, which are stable across the versions.
+const (
+	// CodeUnknownKey is the code of the labels whose key matches no option.
+	CodeUnknownKey = "TL001"
+	// CodeMisspelledKey is the code of the labels whose key matches no option, but is close to the key of an option.
+	CodeMisspelledKey = "TL002"
+	// CodeInvalidValue is the code of the labels whose value does not match the type of their option.
+	CodeInvalidValue = "TL003"
+)
+
+// Issue is an issue found in a label.
+type Issue struct {
+	Code    string `json:"code"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	// Suggestion is the key of the closest option, for a misspelled key.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+var errSubOptions = errors.New("the option only has sub-options")
+
+var indexedName = regexp.MustCompile(`^(.+)\[\d+\]$`)
+
+// Lint checks the labels under the traefik root against the options of the elements they are decoded to,
+// and returns the issues of the keys matching no option, and of the values not matching the type of their option.
+// The labels under other roots are ignored.
+func Lint(labels map[string]string, elements ...interface{}) []Issue {
+	var issues []Issue
+
+	for key, value := range labels {
+		parts := strings.Split(key, ".")
+		if len(parts) < 2 || !strings.EqualFold(parts[0], parser.DefaultRootName) {
+			continue
+		}
+
+		if isExpandKey(parts) {
+			issues = append(issues, lintExpand(key, parts, value, elements)...)
+			continue
+		}
+
+		if issue := lintElements(key, parts, value, elements); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+
+	return issues
+}
+
+// lintElements checks the key against the elements, and returns the issue of the element it matches the best,
+// if it matches none.
+func lintElements(key string, parts []string, value string, elements []interface{}) *Issue {
+	var best *Issue
+	var bestDepth int
+	for _, element := range elements {
+		issue, depth := lintKey(reflect.TypeOf(element), key, parts, 1, value, "")
+		if issue == nil {
+			return nil
+		}
+
+		// The deepest match wins, a misspelled key being more actionable than an unknown one.
+		if best == nil || depth > bestDepth || depth == bestDepth && best.Code == CodeUnknownKey && issue.Code == CodeMisspelledKey {
+			best, bestDepth = issue, depth
+		}
+	}
+
+	return best
+}
+
+// lintExpand checks the options of the routers listed by the expand option of a router,
+// and returns their issues under the key of the expand option.
+func lintExpand(key string, parts []string, value string, elements []interface{}) []Issue {
+	expansion, err := parseExpansion(value)
+	if err != nil {
+		return []Issue{{
+			Code:    CodeInvalidValue,
+			Key:     key,
+			Message: fmt.Sprintf("invalid value: %v", err),
+		}}
+	}
+
+	var issues []Issue
+	for i, options := range expansion {
+		var names []string
+		for option := range options {
+			names = append(names, option)
+		}
+		sort.Strings(names)
+
+		for _, option := range names {
+			optionKey := strings.Join(parts[:4], ".") + "." + option
+
+			issue := lintElements(optionKey, strings.Split(optionKey, "."), options[option], elements)
+			if issue == nil {
+				continue
+			}
+
+			// The suggestion is the key of a label, which the options of an expanded router are not.
+			issues = append(issues, Issue{
+				Code:    issue.Code,
+				Key:     key,
+				Message: fmt.Sprintf("item %d, option %q: %s", i, option, issue.Message),
+			})
+		}
+	}
+
+	return issues
+}
+
+// lintKey checks the parts of the key, from index i, against the type,
+// and returns the issue found, if any, with the number of parts matching an option.
+func lintKey(typ reflect.Type, key string, parts []string, i int, value, tag string) (*Issue, int) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if i == len(parts) {
+		return lintValue(typ, key, value, tag), i
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		name := parts[i]
+		if match := indexedName.FindStringSubmatch(name); match != nil {
+			name = match[1]
+		}
+
+		field, ok := findField(typ, name)
+		if !ok {
+			return unknownKey(typ, key, parts, i, name), i
+		}
+
+		// The slices of structs are set by index, or as a struct for the first item.
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Slice && i+1 < len(parts) {
+			fieldType = fieldType.Elem()
+		}
+
+		return lintKey(fieldType, key, parts, i+1, value, field.Tag.Get(parser.TagLabel))
+
+	case reflect.Map:
+		return lintKey(typ.Elem(), key, parts, i+1, value, "")
+
+	case reflect.Interface:
+		return nil, len(parts)
+
+	default:
+		return &Issue{
+			Code:    CodeUnknownKey,
+			Key:     key,
+			Message: fmt.Sprintf("the option %q has no sub-option %q", strings.Join(parts[:i], "."), parts[i]),
+		}, i
+	}
+}
+
+// findField returns the field of the struct matching the name of an option.
+func findField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !parser.IsExported(field) || field.Tag.Get(parser.TagLabel) == "-" {
+			continue
+		}
+
+		if field.Anonymous {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if f, ok := findField(embedded, name); ok {
+					return f, true
+				}
+				continue
+			}
+		}
+
+		if strings.EqualFold(field.Name, name) || strings.EqualFold(field.Tag.Get(parser.TagLabelSliceAsStruct), name) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// optionNames returns the names of the options of the struct.
+func optionNames(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !parser.IsExported(field) || field.Tag.Get(parser.TagLabel) == "-" {
+			continue
+		}
+
+		embedded := field.Type
+		for embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && embedded.Kind() == reflect.Struct {
+			names = append(names, optionNames(embedded)...)
+			continue
+		}
+
+		names = append(names, strings.ToLower(field.Name))
+		if sliceName := field.Tag.Get(parser.TagLabelSliceAsStruct); sliceName != "" {
+			names = append(names, strings.ToLower(sliceName))
+		}
+	}
+
+	return names
+}
+
+func unknownKey(typ reflect.Type, key string, parts []string, i int, name string) *Issue {
+	name = strings.ToLower(name)
+
+	closest, distance := "", -1
+	for _, option := range optionNames(typ) {
+		d := levenshtein(name, option)
+		if distance < 0 || d < distance {
+			closest, distance = option, d
+		}
+	}
+
+	// The options at most a third different from the name are considered as misspelled.
+	if distance > 0 && distance*3 <= len(closest) {
+		suggestion := make([]string, len(parts))
+		copy(suggestion, parts)
+		suggestion[i] = closest
+
+		return &Issue{
+			Code:       CodeMisspelledKey,
+			Key:        key,
+			Message:    fmt.Sprintf("unknown option %q, did you mean %q?", name, closest),
+			Suggestion: strings.Join(suggestion, "."),
+		}
+	}
+
+	return &Issue{
+		Code:    CodeUnknownKey,
+		Key:     key,
+		Message: fmt.Sprintf("unknown option %q", name),
+	}
+}
+
+// lintValue checks that the value can be decoded to the type.
+func lintValue(typ reflect.Type, key, value, tag string) *Issue {
+	var err error
+	switch typ.Kind() {
+	case reflect.Struct:
+		// A struct enabled with its default values.
+		if tag == parser.TagLabelAllowEmpty {
+			_, err = strconv.ParseBool(value)
+		} else {
+			err = errSubOptions
+		}
+
+	case reflect.Map:
+		err = errSubOptions
+
+	case reflect.Slice:
+		for _, item := range strings.Split(value, ",") {
+			if issue := lintValue(typ.Elem(), key, strings.TrimSpace(item), ""); issue != nil {
+				return issue
+			}
+		}
+
+	default:
+		err = parseValue(typ, value)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	return &Issue{
+		Code:    CodeInvalidValue,
+		Key:     key,
+		Message: fmt.Sprintf("invalid value %q: %v", value, err),
+	}
+}
+
+func parseValue(typ reflect.Type, value string) error {
+	switch typ.Kind() {
+	case reflect.Bool:
+		_, err := strconv.ParseBool(value)
+		return err
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == reflect.TypeOf(types.Duration(0)) || typ == reflect.TypeOf(time.Duration(0)) {
+			if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return nil
+			}
+			_, err := time.ParseDuration(value)
+			return err
+		}
+
+		_, err := strconv.ParseInt(value, 10, typ.Bits())
+		return err
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err := strconv.ParseUint(value, 10, typ.Bits())
+		return err
+
+	case reflect.Float32, reflect.Float64:
+		_, err := strconv.ParseFloat(value, typ.Bits())
+		return err
+
+	default:
+		return nil
+	}
+}
+
+// levenshtein returns the edit distance between the strings.
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
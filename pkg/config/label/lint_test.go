@@ -0,0 +1,184 @@
+package label
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	type providerConfig struct {
+		Enable bool
+	}
+
+	testCases := []struct {
+		desc     string
+		labels   map[string]string
+		expected []Issue
+	}{
+		{
+			desc: "valid labels",
+			labels: map[string]string{
+				"traefik.http.routers.foo.rule":                                   "Host(`foo.bar`)",
+				"traefik.http.routers.foo.priority":                               "10",
+				"traefik.http.routers.foo.tls":                                    "true",
+				"traefik.http.routers.foo.tls.domains[0].sans":                    "foo.bar, bar.foo",
+				"traefik.http.services.foo.loadbalancer.server.port":              "80",
+				"traefik.http.middlewares.foo.headers.customrequestheaders.X-Foo": "bar",
+				"traefik.enable": "true",
+				"com.docker.foo": "bar",
+			},
+		},
+		{
+			desc: "misspelled key",
+			labels: map[string]string{
+				"traefik.http.routers.foo.rul": "Host(`foo.bar`)",
+			},
+			expected: []Issue{
+				{
+					Code:       CodeMisspelledKey,
+					Key:        "traefik.http.routers.foo.rul",
+					Message:    `unknown option "rul", did you mean "rule"?`,
+					Suggestion: "traefik.http.routers.foo.rule",
+				},
+			},
+		},
+		{
+			desc: "misspelled provider key",
+			labels: map[string]string{
+				"traefik.enabel": "true",
+			},
+			expected: []Issue{
+				{
+					Code:       CodeMisspelledKey,
+					Key:        "traefik.enabel",
+					Message:    `unknown option "enabel", did you mean "enable"?`,
+					Suggestion: "traefik.enable",
+				},
+			},
+		},
+		{
+			desc: "unknown key",
+			labels: map[string]string{
+				"traefik.http.routers.foo.something": "bar",
+			},
+			expected: []Issue{
+				{
+					Code:    CodeUnknownKey,
+					Key:     "traefik.http.routers.foo.something",
+					Message: `unknown option "something"`,
+				},
+			},
+		},
+		{
+			desc: "sub-option of a leaf option",
+			labels: map[string]string{
+				"traefik.http.routers.foo.rule.host": "foo.bar",
+			},
+			expected: []Issue{
+				{
+					Code:    CodeUnknownKey,
+					Key:     "traefik.http.routers.foo.rule.host",
+					Message: `the option "traefik.http.routers.foo.rule" has no sub-option "host"`,
+				},
+			},
+		},
+		{
+			desc: "invalid values",
+			labels: map[string]string{
+				"traefik.http.routers.foo.priority":                     "high",
+				"traefik.http.services.foo.loadbalancer.passhostheader": "yes",
+				"traefik.http.middlewares.foo.ratelimit.period":         "often",
+				"traefik.http.routers.foo":                              "bar",
+			},
+			expected: []Issue{
+				{
+					Code:    CodeInvalidValue,
+					Key:     "traefik.http.middlewares.foo.ratelimit.period",
+					Message: `invalid value "often": time: invalid duration "often"`,
+				},
+				{
+					Code:    CodeInvalidValue,
+					Key:     "traefik.http.routers.foo",
+					Message: `invalid value "bar": the option only has sub-options`,
+				},
+				{
+					Code:    CodeInvalidValue,
+					Key:     "traefik.http.routers.foo.priority",
+					Message: `invalid value "high": strconv.ParseInt: parsing "high": invalid syntax`,
+				},
+				{
+					Code:    CodeInvalidValue,
+					Key:     "traefik.http.services.foo.loadbalancer.passhostheader",
+					Message: `invalid value "yes": strconv.ParseBool: parsing "yes": invalid syntax`,
+				},
+			},
+		},
+		{
+			desc: "expanded routers",
+			labels: map[string]string{
+				"traefik.http.routers.foo.service": "foo",
+				"traefik.http.routers.foo.expand":  `[{"rule": "Host(` + "`foo.bar`" + `)", "priority": 10}, {"rul": "Host(` + "`bar.foo`" + `)", "tls": "yes"}]`,
+			},
+			expected: []Issue{
+				{
+					Code:    CodeMisspelledKey,
+					Key:     "traefik.http.routers.foo.expand",
+					Message: `item 1, option "rul": unknown option "rul", did you mean "rule"?`,
+				},
+				{
+					Code:    CodeInvalidValue,
+					Key:     "traefik.http.routers.foo.expand",
+					Message: `item 1, option "tls": invalid value "yes": strconv.ParseBool: parsing "yes": invalid syntax`,
+				},
+			},
+		},
+		{
+			desc: "invalid expansion",
+			labels: map[string]string{
+				"traefik.tcp.routers.foo.expand": `{"rule": "HostSNI(*)"}`,
+			},
+			expected: []Issue{
+				{
+					Code:    CodeInvalidValue,
+					Key:     "traefik.tcp.routers.foo.expand",
+					Message: "invalid value: a JSON array of objects is expected: json: cannot unmarshal object into Go value of type []map[string]interface {}",
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			issues := Lint(test.labels, &dynamic.Configuration{}, &providerConfig{})
+
+			assert.Equal(t, test.expected, issues)
+		})
+	}
+}
+
+func TestIssueRegistry(t *testing.T) {
+	registry := NewIssueRegistry()
+
+	registry.Set("foo", []ProviderIssue{
+		{Provider: "foo", Element: "b", Issue: Issue{Code: CodeUnknownKey, Key: "traefik.b"}},
+		{Provider: "foo", Element: "a", Issue: Issue{Code: CodeUnknownKey, Key: "traefik.a"}},
+	})
+	registry.Set("bar", []ProviderIssue{
+		{Provider: "bar", Element: "a", Issue: Issue{Code: CodeInvalidValue, Key: "traefik.a"}},
+	})
+
+	assert.Equal(t, []ProviderIssue{
+		{Provider: "bar", Element: "a", Issue: Issue{Code: CodeInvalidValue, Key: "traefik.a"}},
+		{Provider: "foo", Element: "a", Issue: Issue{Code: CodeUnknownKey, Key: "traefik.a"}},
+		{Provider: "foo", Element: "b", Issue: Issue{Code: CodeUnknownKey, Key: "traefik.b"}},
+	}, registry.Issues())
+
+	registry.Set("foo", nil)
+
+	assert.Len(t, registry.Issues(), 1)
+}
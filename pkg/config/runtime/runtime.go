@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/deprecation"
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
@@ -24,6 +25,9 @@ type Configuration struct {
 	TCPServices map[string]*TCPServiceInfo `json:"tcpServices,omitempty"`
 	UDPRouters  map[string]*UDPRouterInfo  `json:"udpRouters,omitempty"`
 	UDPServices map[string]*UDPServiceInfo `json:"updServices,omitempty"`
+
+	// Deprecations are the uses of the deprecated fields and behaviors.
+	Deprecations []deprecation.Warning `json:"deprecations,omitempty"`
 }
 
 // NewConfig returns a Configuration initialized with the given conf. It never returns nil.
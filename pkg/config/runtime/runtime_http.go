@@ -10,7 +10,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
-// GetRoutersByEntryPoints returns all the http routers by entry points name and routers name.
+// GetRoutersByEntryPoints returns all the http routers by entry points name and routers name, except the disabled ones.
 func (c *Configuration) GetRoutersByEntryPoints(ctx context.Context, entryPoints []string, tls bool) map[string]map[string]*RouterInfo {
 	entryPointsRouters := make(map[string]map[string]*RouterInfo)
 
@@ -30,13 +30,18 @@ func (c *Configuration) GetRoutersByEntryPoints(ctx context.Context, entryPoints
 				continue
 			}
 
+			entryPointsCount++
+			rt.Using = append(rt.Using, entryPointName)
+
+			if rt.Status == StatusDisabled {
+				// The router was rejected before being built, it is only reported with the entry points it would use.
+				continue
+			}
+
 			if _, ok := entryPointsRouters[entryPointName]; !ok {
 				entryPointsRouters[entryPointName] = make(map[string]*RouterInfo)
 			}
 
-			entryPointsCount++
-			rt.Using = append(rt.Using, entryPointName)
-
 			entryPointsRouters[entryPointName][rtName] = rt
 		}
 
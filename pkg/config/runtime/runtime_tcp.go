@@ -8,7 +8,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
-// GetTCPRoutersByEntryPoints returns all the tcp routers by entry points name and routers name.
+// GetTCPRoutersByEntryPoints returns all the tcp routers by entry points name and routers name, except the disabled ones.
 func (c *Configuration) GetTCPRoutersByEntryPoints(ctx context.Context, entryPoints []string) map[string]map[string]*TCPRouterInfo {
 	entryPointsRouters := make(map[string]map[string]*TCPRouterInfo)
 
@@ -30,13 +30,18 @@ func (c *Configuration) GetTCPRoutersByEntryPoints(ctx context.Context, entryPoi
 				continue
 			}
 
+			entryPointsCount++
+			rt.Using = append(rt.Using, entryPointName)
+
+			if rt.Status == StatusDisabled {
+				// The router was rejected before being built, it is only reported with the entry points it would use.
+				continue
+			}
+
 			if _, ok := entryPointsRouters[entryPointName]; !ok {
 				entryPointsRouters[entryPointName] = make(map[string]*TCPRouterInfo)
 			}
 
-			entryPointsCount++
-			rt.Using = append(rt.Using, entryPointName)
-
 			entryPointsRouters[entryPointName][rtName] = rt
 		}
 
@@ -8,7 +8,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
-// GetUDPRoutersByEntryPoints returns all the UDP routers by entry points name and routers name.
+// GetUDPRoutersByEntryPoints returns all the UDP routers by entry points name and routers name, except the disabled ones.
 func (c *Configuration) GetUDPRoutersByEntryPoints(ctx context.Context, entryPoints []string) map[string]map[string]*UDPRouterInfo {
 	entryPointsRouters := make(map[string]map[string]*UDPRouterInfo)
 
@@ -30,13 +30,18 @@ func (c *Configuration) GetUDPRoutersByEntryPoints(ctx context.Context, entryPoi
 				continue
 			}
 
+			entryPointsCount++
+			rt.Using = append(rt.Using, entryPointName)
+
+			if rt.Status == StatusDisabled {
+				// The router was rejected before being built, it is only reported with the entry points it would use.
+				continue
+			}
+
 			if _, ok := entryPointsRouters[entryPointName]; !ok {
 				entryPointsRouters[entryPointName] = make(map[string]*UDPRouterInfo)
 			}
 
-			entryPointsCount++
-			rt.Using = append(rt.Using, entryPointName)
-
 			entryPointsRouters[entryPointName][rtName] = rt
 		}
 
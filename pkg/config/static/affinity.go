@@ -0,0 +1,30 @@
+package static
+
+import (
+	"github.com/containous/traefik/v2/pkg/redis"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// AffinityTable holds the configuration of the affinity table,
+// which maps the clients to the servers of the sticky sessions without cookies.
+type AffinityTable struct {
+	Redis *RedisAffinityTable `description:"Stores the affinity table in Redis, to share it with the other instances." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// RedisAffinityTable holds the configuration of the Redis server storing the affinity table.
+type RedisAffinityTable struct {
+	Endpoint string           `description:"Endpoint of the Redis server, of a sentinel, or of a seed node of the cluster." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Username string           `description:"ACL user of the Redis server." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password string           `description:"Password of the Redis server." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	DB       int              `description:"Database selected on the Redis server." json:"db,omitempty" toml:"db,omitempty" yaml:"db,omitempty" export:"true"`
+	Sentinel *redis.Sentinel  `description:"Enable the discovery of the master by Redis Sentinel, the endpoint being a sentinel." json:"sentinel,omitempty" toml:"sentinel,omitempty" yaml:"sentinel,omitempty" export:"true"`
+	Cluster  bool             `description:"Enable Redis Cluster, the endpoint being a seed node of the cluster." json:"cluster,omitempty" toml:"cluster,omitempty" yaml:"cluster,omitempty" export:"true"`
+	TLS      *types.ClientTLS `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	Prefix   string           `description:"Prefix of the keys of the affinity table." json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *RedisAffinityTable) SetDefaults() {
+	r.Endpoint = "127.0.0.1:6379"
+	r.Prefix = "traefik/affinity"
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/types"
+	ptypes "github.com/traefik/paerser/types"
 )
 
 // EntryPoint holds the entry point configuration.
@@ -15,6 +17,14 @@ type EntryPoint struct {
 	ProxyProtocol    *ProxyProtocol        `description:"Proxy-Protocol configuration." json:"proxyProtocol,omitempty" toml:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty" label:"allowEmpty" file:"allowEmpty"`
 	ForwardedHeaders *ForwardedHeaders     `description:"Trust client forwarding headers." json:"forwardedHeaders,omitempty" toml:"forwardedHeaders,omitempty" yaml:"forwardedHeaders,omitempty"`
 	HTTP             HTTPConfig            `description:"HTTP configuration." json:"http,omitempty" toml:"http,omitempty" yaml:"http,omitempty"`
+	HTTP2            *HTTP2Config          `description:"HTTP/2 configuration." json:"http2,omitempty" toml:"http2,omitempty" yaml:"http2,omitempty" export:"true"`
+	TLS              *EntryPointTLS        `description:"TLS store, certificate selection and TLS options of the entry point." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	ReusePort        bool                  `description:"Enables EntryPoints from the same or different processes listening on the same TCP address." json:"reusePort,omitempty" toml:"reusePort,omitempty" yaml:"reusePort,omitempty" export:"true"`
+	DSCP             string                `description:"Marks the connections of the clients with a DSCP value, the name of a standard class or a number between 0 and 63." json:"dscp,omitempty" toml:"dscp,omitempty" yaml:"dscp,omitempty" export:"true"`
+	ForwardProxy     *ForwardProxy         `description:"Enables the forward proxy mode, which accepts the CONNECT requests and tunnels them to their destination." json:"forwardProxy,omitempty" toml:"forwardProxy,omitempty" yaml:"forwardProxy,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Privacy          *Privacy              `description:"Anonymizes the client IPs in the access logs, the metrics and the forwarded headers." json:"privacy,omitempty" toml:"privacy,omitempty" yaml:"privacy,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Normalization    *Normalization        `description:"Handles the ambiguous request targets and hosts, which the services may interpret differently than the routers." json:"normalization,omitempty" toml:"normalization,omitempty" yaml:"normalization,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Canary           *Canary               `description:"Forwards a percentage of the accepted connections to another local address, e.g. a Traefik instance of another version." json:"canary,omitempty" toml:"canary,omitempty" yaml:"canary,omitempty" export:"true"`
 }
 
 // GetAddress strips any potential protocol part of the address field of the
@@ -45,6 +55,8 @@ func (ep *EntryPoint) SetDefaults() {
 	ep.Transport = &EntryPointsTransport{}
 	ep.Transport.SetDefaults()
 	ep.ForwardedHeaders = &ForwardedHeaders{}
+	ep.HTTP2 = &HTTP2Config{}
+	ep.HTTP2.SetDefaults()
 }
 
 // HTTPConfig is the HTTP configuration of an entry point.
@@ -52,6 +64,32 @@ type HTTPConfig struct {
 	Redirections *Redirections `description:"Set of redirection" json:"redirections,omitempty" toml:"redirections,omitempty" yaml:"redirections,omitempty"`
 	Middlewares  []string      `description:"Default middlewares for the routers linked to the entry point." json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
 	TLS          *TLSConfig    `description:"Default TLS configuration for the routers linked to the entry point." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	RouterHeader bool          `description:"Sets the X-Traefik-Router header of the responses to the name of the router that handled the request." json:"routerHeader,omitempty" toml:"routerHeader,omitempty" yaml:"routerHeader,omitempty" export:"true"`
+}
+
+// HTTP2Config is the HTTP/2 configuration of an entry point.
+type HTTP2Config struct {
+	MaxConcurrentStreams        int32           `description:"Specifies the number of concurrent streams per connection that each client is allowed to initiate." json:"maxConcurrentStreams,omitempty" toml:"maxConcurrentStreams,omitempty" yaml:"maxConcurrentStreams,omitempty" export:"true"`
+	MaxReadFrameSize            int32           `description:"Specifies the largest frame, in bytes, the server is willing to read." json:"maxReadFrameSize,omitempty" toml:"maxReadFrameSize,omitempty" yaml:"maxReadFrameSize,omitempty" export:"true"`
+	InitialStreamWindowSize     int32           `description:"Specifies the flow control window, in bytes, of the request bodies of each stream." json:"initialStreamWindowSize,omitempty" toml:"initialStreamWindowSize,omitempty" yaml:"initialStreamWindowSize,omitempty" export:"true"`
+	InitialConnectionWindowSize int32           `description:"Specifies the flow control window, in bytes, of the request bodies of each connection." json:"initialConnectionWindowSize,omitempty" toml:"initialConnectionWindowSize,omitempty" yaml:"initialConnectionWindowSize,omitempty" export:"true"`
+	MaxResetStreamsPerSecond    int32           `description:"Specifies the number of streams per second a client is allowed to reset on a connection, before the connection is closed (0 means unlimited)." json:"maxResetStreamsPerSecond,omitempty" toml:"maxResetStreamsPerSecond,omitempty" yaml:"maxResetStreamsPerSecond,omitempty" export:"true"`
+	MaxNewStreamsPerSecond      int32           `description:"Specifies the number of streams per second a client is allowed to open on a connection, before the connection is closed (0 means unlimited)." json:"maxNewStreamsPerSecond,omitempty" toml:"maxNewStreamsPerSecond,omitempty" yaml:"maxNewStreamsPerSecond,omitempty" export:"true"`
+	MaxContinuationFrames       int32           `description:"Specifies the number of CONTINUATION frames a client is allowed to send for a header block, before the connection is closed (0 means unlimited)." json:"maxContinuationFrames,omitempty" toml:"maxContinuationFrames,omitempty" yaml:"maxContinuationFrames,omitempty" export:"true"`
+	ReadIdleTimeout             ptypes.Duration `description:"Specifies the duration without any frame received on a connection after which a ping is sent to check its health (0 means no health check)." json:"readIdleTimeout,omitempty" toml:"readIdleTimeout,omitempty" yaml:"readIdleTimeout,omitempty" export:"true"`
+	PingTimeout                 ptypes.Duration `description:"Specifies the duration after which a connection is closed when the response to a ping is not received." json:"pingTimeout,omitempty" toml:"pingTimeout,omitempty" yaml:"pingTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *HTTP2Config) SetDefaults() {
+	// The defaults of golang.org/x/net/http2.
+	c.MaxConcurrentStreams = 250
+	c.MaxReadFrameSize = 1 << 20
+	c.InitialStreamWindowSize = 1 << 20
+	c.InitialConnectionWindowSize = 1 << 20
+	c.MaxResetStreamsPerSecond = 100
+	c.MaxContinuationFrames = 32
+	c.PingTimeout = ptypes.Duration(15 * time.Second)
 }
 
 // Redirections is a set of redirection for an entry point.
@@ -81,10 +119,78 @@ type TLSConfig struct {
 	Domains      []types.Domain `description:"Default TLS domains for the routers linked to the entry point." json:"domains,omitempty" toml:"domains,omitempty" yaml:"domains,omitempty"`
 }
 
+// EntryPointTLS holds the TLS store, the certificate selection and the TLS options of an entry point.
+type EntryPointTLS struct {
+	DefaultStore         string `description:"TLS store of the certificates served on the entry point, instead of the default one." json:"defaultStore,omitempty" toml:"defaultStore,omitempty" yaml:"defaultStore,omitempty" export:"true"`
+	Options              string `description:"TLS options of all the connections of the entry point, whatever the server name, instead of the ones of the routers." json:"options,omitempty" toml:"options,omitempty" yaml:"options,omitempty" export:"true"`
+	CertificateSelection string `description:"Strategy selecting the certificate among the ones matching the server name: mostSpecific | preferECDSA | preferLargestKey | preferNearestExpiry" json:"certificateSelection,omitempty" toml:"certificateSelection,omitempty" yaml:"certificateSelection,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (t *EntryPointTLS) SetDefaults() {
+	t.DefaultStore = "default"
+	t.CertificateSelection = "mostSpecific"
+}
+
 // ForwardedHeaders Trust client forwarding headers.
 type ForwardedHeaders struct {
-	Insecure   bool     `description:"Trust all forwarded headers." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
-	TrustedIPs []string `description:"Trust only forwarded headers from selected IPs." json:"trustedIPs,omitempty" toml:"trustedIPs,omitempty" yaml:"trustedIPs,omitempty"`
+	Insecure      bool     `description:"Trust all forwarded headers." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	TrustedIPs    []string `description:"Trust only forwarded headers from selected IPs." json:"trustedIPs,omitempty" toml:"trustedIPs,omitempty" yaml:"trustedIPs,omitempty"`
+	TrustedIPSets []string `description:"Trust only forwarded headers from the IPs of the selected IP sets of the dynamic configuration, with their qualified names (e.g. name@file)." json:"trustedIPSets,omitempty" toml:"trustedIPSets,omitempty" yaml:"trustedIPSets,omitempty"`
+}
+
+// ForwardProxy holds the forward proxy configuration of an entry point.
+type ForwardProxy struct {
+	Users                    []string        `description:"Users allowed to open tunnels, in the htpasswd format." json:"users,omitempty" toml:"users,omitempty" yaml:"users,omitempty"`
+	UsersFile                string          `description:"Path to a file holding the users allowed to open tunnels, in the htpasswd format." json:"usersFile,omitempty" toml:"usersFile,omitempty" yaml:"usersFile,omitempty"`
+	AllowedDestinations      []string        `description:"Destinations the tunnels can be opened to (domain names, IPs or CIDRs), at least one is required." json:"allowedDestinations,omitempty" toml:"allowedDestinations,omitempty" yaml:"allowedDestinations,omitempty" export:"true"`
+	DeniedDestinations       []string        `description:"Destinations the tunnels cannot be opened to (domain names, IPs or CIDRs)." json:"deniedDestinations,omitempty" toml:"deniedDestinations,omitempty" yaml:"deniedDestinations,omitempty" export:"true"`
+	AllowPrivateDestinations bool            `description:"Allows the tunnels to the private, loopback and link-local IPs (e.g. 169.254.169.254), which are denied by default." json:"allowPrivateDestinations,omitempty" toml:"allowPrivateDestinations,omitempty" yaml:"allowPrivateDestinations,omitempty" export:"true"`
+	AllowedPorts             []int           `description:"Destination ports the tunnels can be opened to." json:"allowedPorts,omitempty" toml:"allowedPorts,omitempty" yaml:"allowedPorts,omitempty" export:"true"`
+	MaxConnections           int             `description:"Maximum number of open tunnels (0 means no limit)." json:"maxConnections,omitempty" toml:"maxConnections,omitempty" yaml:"maxConnections,omitempty" export:"true"`
+	BandwidthLimit           int64           `description:"Maximum bandwidth of a tunnel in each direction, in bytes per second (0 means no limit)." json:"bandwidthLimit,omitempty" toml:"bandwidthLimit,omitempty" yaml:"bandwidthLimit,omitempty" export:"true"`
+	DialTimeout              ptypes.Duration `description:"Timeout for the connection to the destination." json:"dialTimeout,omitempty" toml:"dialTimeout,omitempty" yaml:"dialTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (f *ForwardProxy) SetDefaults() {
+	f.AllowedPorts = []int{443}
+	f.DialTimeout = ptypes.Duration(30 * time.Second)
+}
+
+// Privacy holds the anonymization of the client IPs of an entry point.
+type Privacy struct {
+	Mode        string          `description:"Anonymization of the client IPs: truncate (IPv4 /24, IPv6 /64) or hash (HMAC with a rotating key)." json:"mode,omitempty" toml:"mode,omitempty" yaml:"mode,omitempty" export:"true"`
+	Key         string          `description:"Secret the hashing keys are derived from, shared by the instances hashing the client IPs the same way (default: a random secret)." json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty"`
+	KeyRotation ptypes.Duration `description:"Duration after which the hashing key is rotated." json:"keyRotation,omitempty" toml:"keyRotation,omitempty" yaml:"keyRotation,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (p *Privacy) SetDefaults() {
+	p.Mode = "truncate"
+	p.KeyRotation = ptypes.Duration(24 * time.Hour)
+}
+
+// Normalization holds the handling of the ambiguous request targets and hosts of an entry point.
+type Normalization struct {
+	AbsoluteForm    string `description:"Handling of the requests with an absolute-form target: reject, normalize or log." json:"absoluteForm,omitempty" toml:"absoluteForm,omitempty" yaml:"absoluteForm,omitempty" export:"true"`
+	HostMismatch    string `description:"Handling of the HTTP/2 requests whose Host header differs from their authority: reject, normalize or log." json:"hostMismatch,omitempty" toml:"hostMismatch,omitempty" yaml:"hostMismatch,omitempty" export:"true"`
+	UserInfo        string `description:"Handling of the requests with userinfo in their target or host: reject, normalize or log." json:"userInfo,omitempty" toml:"userInfo,omitempty" yaml:"userInfo,omitempty" export:"true"`
+	MixedScriptHost string `description:"Handling of the requests whose host mixes scripts in a label, as the lookalikes of other hosts do: reject or log." json:"mixedScriptHost,omitempty" toml:"mixedScriptHost,omitempty" yaml:"mixedScriptHost,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (n *Normalization) SetDefaults() {
+	n.AbsoluteForm = "normalize"
+	n.HostMismatch = "normalize"
+	n.UserInfo = "reject"
+}
+
+// Canary holds the forwarding of a percentage of the connections accepted by an entry point to another address.
+type Canary struct {
+	Address       string `description:"Address the connections are forwarded to." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Weight        int    `description:"Percentage of the accepted connections forwarded to the address." json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty" export:"true"`
+	ProxyProtocol bool   `description:"Sends the PROXY protocol header, preserving the client IPs, on the forwarded connections." json:"proxyProtocol,omitempty" toml:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty" export:"true"`
 }
 
 // ProxyProtocol contains Proxy-Protocol configuration.
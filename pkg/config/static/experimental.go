@@ -8,9 +8,20 @@ type Experimental struct {
 
 	Plugins   map[string]plugins.Descriptor `description:"Plugins configuration." json:"plugins,omitempty" toml:"plugins,omitempty" yaml:"plugins,omitempty"`
 	DevPlugin *plugins.DevPlugin            `description:"Dev plugin configuration." json:"devPlugin,omitempty" toml:"devPlugin,omitempty" yaml:"devPlugin,omitempty"`
+
+	Workers *Workers `description:"Multi-process mode configuration." json:"workers,omitempty" toml:"workers,omitempty" yaml:"workers,omitempty" export:"true"`
+
+	AutoPriority bool `description:"Use the specificity of their rule, instead of its length, as the priority of the HTTP routers without priority." json:"autoPriority,omitempty" toml:"autoPriority,omitempty" yaml:"autoPriority,omitempty" export:"true"`
 }
 
 // Pilot Configuration related to Traefik Pilot.
 type Pilot struct {
 	Token string `description:"Traefik Pilot token." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
 }
+
+// Workers configures the multi-process mode,
+// where a supervisor process starts several worker processes sharing the entry points listeners.
+type Workers struct {
+	Count       int  `description:"Number of worker processes." json:"count,omitempty" toml:"count,omitempty" yaml:"count,omitempty" export:"true"`
+	CPUAffinity bool `description:"Pins each worker process to its own set of CPUs (Linux only)." json:"cpuAffinity,omitempty" toml:"cpuAffinity,omitempty" yaml:"cpuAffinity,omitempty" export:"true"`
+}
@@ -0,0 +1,17 @@
+package static
+
+// Guardrails holds the limits applied by the platform administrators to the configuration of the namespaces.
+type Guardrails struct {
+	Namespaces map[string]*NamespaceGuardrails `description:"Limits applied to the configuration of the namespaces." json:"namespaces,omitempty" toml:"namespaces,omitempty" yaml:"namespaces,omitempty" export:"true"`
+}
+
+// NamespaceGuardrails holds the limits applied to the configuration of a namespace.
+// A namespace is the configuration of a provider, or the part of it whose resources names start with a prefix,
+// such as the Kubernetes namespace of the resources.
+type NamespaceGuardrails struct {
+	Provider             string   `description:"Name of the provider of the namespace." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty" export:"true"`
+	Namespace            string   `description:"Namespace of the resources, which names start with it followed by a dash (default: all the resources of the provider)." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	MaxRouters           int      `description:"Maximum number of routers, 0 meaning no limit." json:"maxRouters,omitempty" toml:"maxRouters,omitempty" yaml:"maxRouters,omitempty" export:"true"`
+	MaxMiddlewares       int      `description:"Maximum number of middlewares, 0 meaning no limit." json:"maxMiddlewares,omitempty" toml:"maxMiddlewares,omitempty" yaml:"maxMiddlewares,omitempty" export:"true"`
+	ForbiddenMiddlewares []string `description:"Types of the forbidden middlewares." json:"forbiddenMiddlewares,omitempty" toml:"forbiddenMiddlewares,omitempty" yaml:"forbiddenMiddlewares,omitempty" export:"true"`
+}
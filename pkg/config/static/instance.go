@@ -0,0 +1,19 @@
+package static
+
+import "regexp"
+
+// colorRegexp matches the hexadecimal CSS colors, e.g. #c00 or #cc0000.
+var colorRegexp = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Instance holds the metadata identifying the instance,
+// exposed by the API so that the dashboard and the external UIs can tell the instances apart, e.g. production from staging.
+type Instance struct {
+	Cluster     string   `description:"Name of the cluster of the instance." json:"cluster,omitempty" toml:"cluster,omitempty" yaml:"cluster,omitempty" export:"true"`
+	Environment string   `description:"Environment of the instance, e.g. production or staging." json:"environment,omitempty" toml:"environment,omitempty" yaml:"environment,omitempty" export:"true"`
+	Color       string   `description:"Hexadecimal color identifying the instance in the dashboard, e.g. #cc0000." json:"color,omitempty" toml:"color,omitempty" yaml:"color,omitempty" export:"true"`
+	Tags        []string `description:"Tags of the instance." json:"tags,omitempty" toml:"tags,omitempty" yaml:"tags,omitempty" export:"true"`
+}
+
+func isValidColor(color string) bool {
+	return colorRegexp.MatchString(color)
+}
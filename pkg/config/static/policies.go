@@ -0,0 +1,26 @@
+package static
+
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
+// Policies holds the configuration of the policies validating the dynamic configuration sent by the providers.
+type Policies struct {
+	OPA *OPAPolicies `description:"Evaluates Rego policies with an Open Policy Agent server." json:"opa,omitempty" toml:"opa,omitempty" yaml:"opa,omitempty" export:"true"`
+}
+
+// OPAPolicies holds the configuration of the Open Policy Agent server evaluating the policies.
+type OPAPolicies struct {
+	URL           string          `description:"URL of the Open Policy Agent server." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	Policy        string          `description:"Path of the document listing the violations, such as traefik/violations." json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty" export:"true"`
+	Timeout       ptypes.Duration `description:"Timeout of the evaluation of the policies." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	RejectOnError bool            `description:"Rejects the whole configuration when the policies cannot be evaluated." json:"rejectOnError,omitempty" toml:"rejectOnError,omitempty" yaml:"rejectOnError,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OPAPolicies) SetDefaults() {
+	o.Policy = "traefik/violations"
+	o.Timeout = ptypes.Duration(10 * time.Second)
+}
@@ -1,18 +1,22 @@
 package static
 
 import (
+	"errors"
 	"fmt"
 	stdlog "log"
 	"strings"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/ping"
 	acmeprovider "github.com/containous/traefik/v2/pkg/provider/acme"
 	"github.com/containous/traefik/v2/pkg/provider/consulcatalog"
 	"github.com/containous/traefik/v2/pkg/provider/docker"
 	"github.com/containous/traefik/v2/pkg/provider/ecs"
 	"github.com/containous/traefik/v2/pkg/provider/file"
+	"github.com/containous/traefik/v2/pkg/provider/git"
 	"github.com/containous/traefik/v2/pkg/provider/http"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/ingress"
@@ -21,6 +25,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/provider/kv/redis"
 	"github.com/containous/traefik/v2/pkg/provider/kv/zk"
 	"github.com/containous/traefik/v2/pkg/provider/marathon"
+	"github.com/containous/traefik/v2/pkg/provider/oci"
 	"github.com/containous/traefik/v2/pkg/provider/rancher"
 	"github.com/containous/traefik/v2/pkg/provider/rest"
 	"github.com/containous/traefik/v2/pkg/tls"
@@ -41,6 +46,9 @@ const (
 	// DefaultInternalEntryPointName the name of the default internal entry point.
 	DefaultInternalEntryPointName = "traefik"
 
+	// DefaultMetricsEntryPointName the name of the dedicated entry point serving the Prometheus metrics.
+	DefaultMetricsEntryPointName = "prometheus"
+
 	// DefaultGraceTimeout controls how long Traefik serves pending requests
 	// prior to shutting down.
 	DefaultGraceTimeout = 10 * time.Second
@@ -56,9 +64,10 @@ const (
 type Configuration struct {
 	Global *Global `description:"Global configuration options" json:"global,omitempty" toml:"global,omitempty" yaml:"global,omitempty" export:"true"`
 
-	ServersTransport *ServersTransport `description:"Servers default transport." json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
-	EntryPoints      EntryPoints       `description:"Entry points definition." json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
-	Providers        *Providers        `description:"Providers configuration." json:"providers,omitempty" toml:"providers,omitempty" yaml:"providers,omitempty" export:"true"`
+	ServersTransport  *ServersTransport            `description:"Servers default transport." json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	ServersTransports map[string]*ServersTransport `description:"Named servers transports, selected by the load-balancers of the services according to the requests." json:"serversTransports,omitempty" toml:"serversTransports,omitempty" yaml:"serversTransports,omitempty" export:"true"`
+	EntryPoints       EntryPoints                  `description:"Entry points definition." json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+	Providers         *Providers                   `description:"Providers configuration." json:"providers,omitempty" toml:"providers,omitempty" yaml:"providers,omitempty" export:"true"`
 
 	API     *API           `description:"Enable api/dashboard." json:"api,omitempty" toml:"api,omitempty" yaml:"api,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	Metrics *types.Metrics `description:"Enable a metrics exporter." json:"metrics,omitempty" toml:"metrics,omitempty" yaml:"metrics,omitempty" export:"true"`
@@ -70,6 +79,16 @@ type Configuration struct {
 
 	HostResolver *types.HostResolverConfig `description:"Enable CNAME Flattening." json:"hostResolver,omitempty" toml:"hostResolver,omitempty" yaml:"hostResolver,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 
+	AffinityTable *AffinityTable `description:"Table of the sticky sessions without cookies." json:"affinityTable,omitempty" toml:"affinityTable,omitempty" yaml:"affinityTable,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	Guardrails *Guardrails `description:"Limits applied to the configuration of the namespaces." json:"guardrails,omitempty" toml:"guardrails,omitempty" yaml:"guardrails,omitempty" export:"true"`
+
+	Policies *Policies `description:"Policies validating the dynamic configuration." json:"policies,omitempty" toml:"policies,omitempty" yaml:"policies,omitempty" export:"true"`
+
+	Cluster *cluster.Configuration `description:"Share the runtime state with the other Traefik instances of a cluster." json:"cluster,omitempty" toml:"cluster,omitempty" yaml:"cluster,omitempty" export:"true"`
+
+	Instance *Instance `description:"Metadata identifying the instance in the API and the dashboard." json:"instance,omitempty" toml:"instance,omitempty" yaml:"instance,omitempty" export:"true"`
+
 	CertificatesResolvers map[string]CertificateResolver `description:"Certificates resolvers configuration." json:"certificatesResolvers,omitempty" toml:"certificatesResolvers,omitempty" yaml:"certificatesResolvers,omitempty" export:"true"`
 
 	Experimental *Experimental `description:"experimental features." json:"experimental,omitempty" toml:"experimental,omitempty" yaml:"experimental,omitempty"`
@@ -90,15 +109,21 @@ type Global struct {
 type ServersTransport struct {
 	InsecureSkipVerify  bool                `description:"Disable SSL certificate verification." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
 	RootCAs             []tls.FileOrContent `description:"Add cert file for self-signed certificate." json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
+	Certificates        tls.Certificates    `description:"Client certificates presented to the servers requiring mutual TLS." json:"certificates,omitempty" toml:"certificates,omitempty" yaml:"certificates,omitempty"`
 	MaxIdleConnsPerHost int                 `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host. If zero, DefaultMaxIdleConnsPerHost is used" json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty" export:"true"`
 	ForwardingTimeouts  *ForwardingTimeouts `description:"Timeouts for requests forwarded to the backend servers." json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty" export:"true"`
+	LocalAddress        string              `description:"Local IP address the connections to the servers are made from." json:"localAddress,omitempty" toml:"localAddress,omitempty" yaml:"localAddress,omitempty" export:"true"`
+	Interface           string              `description:"Network interface, or VRF device, the connections to the servers are bound to (Linux only)." json:"interface,omitempty" toml:"interface,omitempty" yaml:"interface,omitempty" export:"true"`
+	Proxy               string              `description:"URL of the SOCKS5 (socks5://) or HTTP CONNECT (http://) proxy the connections to the servers are tunneled through." json:"proxy,omitempty" toml:"proxy,omitempty" yaml:"proxy,omitempty"`
 }
 
 // API holds the API configuration.
 type API struct {
-	Insecure  bool `description:"Activate API directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
-	Dashboard bool `description:"Activate dashboard." json:"dashboard,omitempty" toml:"dashboard,omitempty" yaml:"dashboard,omitempty" export:"true"`
-	Debug     bool `description:"Enable additional endpoints for debugging and profiling." json:"debug,omitempty" toml:"debug,omitempty" yaml:"debug,omitempty" export:"true"`
+	Insecure   bool           `description:"Activate API directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	Dashboard  bool           `description:"Activate dashboard." json:"dashboard,omitempty" toml:"dashboard,omitempty" yaml:"dashboard,omitempty" export:"true"`
+	Debug      bool           `description:"Enable additional endpoints for debugging and profiling." json:"debug,omitempty" toml:"debug,omitempty" yaml:"debug,omitempty" export:"true"`
+	Weights    *WeightsAPI    `description:"Enable the runtime adjustment of the weighted services weights." json:"weights,omitempty" toml:"weights,omitempty" yaml:"weights,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Federation *FederationAPI `description:"Federate the API of other instances." json:"federation,omitempty" toml:"federation,omitempty" yaml:"federation,omitempty" export:"true"`
 	// TODO: Re-enable statistics
 	// Statistics      *types.Statistics `description:"Enable more detailed statistics." json:"statistics,omitempty" toml:"statistics,omitempty" yaml:"statistics,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	DashboardAssets *assetfs.AssetFS `json:"-" toml:"-" yaml:"-" label:"-" file:"-"`
@@ -109,6 +134,29 @@ func (a *API) SetDefaults() {
 	a.Dashboard = true
 }
 
+// FederationAPI holds the configuration of the API federating the API of other instances.
+type FederationAPI struct {
+	Instances map[string]*FederatedInstance `description:"Instances whose API is federated, keyed by the name labeling their resources." json:"instances,omitempty" toml:"instances,omitempty" yaml:"instances,omitempty" export:"true"`
+	Timeout   ptypes.Duration               `description:"Timeout of the requests to the API of the instances." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (f *FederationAPI) SetDefaults() {
+	f.Timeout = ptypes.Duration(5 * time.Second)
+}
+
+// FederatedInstance holds the configuration of an instance whose API is federated.
+type FederatedInstance struct {
+	URL   string `description:"Base URL of the API of the instance." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" export:"true"`
+	Token string `description:"Bearer token sent to the API of the instance." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// WeightsAPI holds the configuration of the runtime weights API.
+type WeightsAPI struct {
+	Token   string `description:"Bearer token required to update the weights." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	Storage string `description:"KV provider (consul, etcd, redis or zookeeper) where the weights updates are persisted." json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty" export:"true"`
+}
+
 // RespondingTimeouts contains timeout configurations for incoming requests to the Traefik instance.
 type RespondingTimeouts struct {
 	ReadTimeout  ptypes.Duration `description:"ReadTimeout is the maximum duration for reading the entire request, including the body. If zero, no timeout is set." json:"readTimeout,omitempty" toml:"readTimeout,omitempty" yaml:"readTimeout,omitempty" export:"true"`
@@ -149,6 +197,7 @@ func (a *LifeCycle) SetDefaults() {
 type Tracing struct {
 	ServiceName   string           `description:"Set the name for this service." json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty" export:"true"`
 	SpanNameLimit int              `description:"Set the maximum character limit for Span names (default 0 = no limit)." json:"spanNameLimit,omitempty" toml:"spanNameLimit,omitempty" yaml:"spanNameLimit,omitempty" export:"true"`
+	ForceSampling *ForceSampling   `description:"Allows the requests from trusted IPs to force the sampling of their trace with a debug header." json:"forceSampling,omitempty" toml:"forceSampling,omitempty" yaml:"forceSampling,omitempty" export:"true"`
 	Jaeger        *jaeger.Config   `description:"Settings for Jaeger." json:"jaeger,omitempty" toml:"jaeger,omitempty" yaml:"jaeger,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	Zipkin        *zipkin.Config   `description:"Settings for Zipkin." json:"zipkin,omitempty" toml:"zipkin,omitempty" yaml:"zipkin,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	Datadog       *datadog.Config  `description:"Settings for Datadog." json:"datadog,omitempty" toml:"datadog,omitempty" yaml:"datadog,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
@@ -163,9 +212,22 @@ func (t *Tracing) SetDefaults() {
 	t.SpanNameLimit = 0
 }
 
+// ForceSampling holds the debug header forcing the sampling of the trace of a request, honored only from trusted IPs.
+type ForceSampling struct {
+	Header     string   `description:"Header forcing the sampling of the trace of a request, when valued with force." json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty" export:"true"`
+	TrustedIPs []string `description:"IPs or CIDR ranges of the clients allowed to force the sampling." json:"trustedIPs,omitempty" toml:"trustedIPs,omitempty" yaml:"trustedIPs,omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (f *ForceSampling) SetDefaults() {
+	f.Header = "X-Traefik-Trace"
+}
+
 // Providers contains providers configuration.
 type Providers struct {
 	ProvidersThrottleDuration ptypes.Duration `description:"Backends throttle duration: minimum duration between 2 events from providers before applying a new configuration. It avoids unnecessary reloads if multiples events are sent in a short amount of time." json:"providersThrottleDuration,omitempty" toml:"providersThrottleDuration,omitempty" yaml:"providersThrottleDuration,omitempty" export:"true"`
+	WaitForFirstSync          bool            `description:"Delays the start of the entry points until every enabled provider has delivered its first configuration." json:"waitForFirstSync,omitempty" toml:"waitForFirstSync,omitempty" yaml:"waitForFirstSync,omitempty" export:"true"`
+	FirstSyncTimeout          ptypes.Duration `description:"Maximum duration to wait for the first configuration of the providers before starting the entry points anyway, 0 waiting without limit." json:"firstSyncTimeout,omitempty" toml:"firstSyncTimeout,omitempty" yaml:"firstSyncTimeout,omitempty" export:"true"`
 
 	Docker            *docker.Provider        `description:"Enable Docker backend with default settings." json:"docker,omitempty" toml:"docker,omitempty" yaml:"docker,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	File              *file.Provider          `description:"Enable File backend with default settings." json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty" export:"true"`
@@ -182,6 +244,8 @@ type Providers struct {
 	ZooKeeper *zk.Provider     `description:"Enable ZooKeeper backend with default settings." json:"zooKeeper,omitempty" toml:"zooKeeper,omitempty" yaml:"zooKeeper,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	Redis     *redis.Provider  `description:"Enable Redis backend with default settings." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	HTTP      *http.Provider   `description:"Enable HTTP backend with default settings." json:"http,omitempty" toml:"http,omitempty" yaml:"http,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
+	OCI       *oci.Provider    `description:"Enable OCI artifact backend with default settings." json:"oci,omitempty" toml:"oci,omitempty" yaml:"oci,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
+	Git       *git.Provider    `description:"Enable Git backend with default settings." json:"git,omitempty" toml:"git,omitempty" yaml:"git,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 }
 
 // SetEffectiveConfiguration adds missing configuration parameters derived from existing ones.
@@ -194,6 +258,16 @@ func (c *Configuration) SetEffectiveConfiguration() {
 		c.EntryPoints = EntryPoints{"http": ep}
 	}
 
+	// Creates the dedicated metrics entry point if needed
+	if c.Metrics != nil && c.Metrics.Prometheus != nil && c.Metrics.Prometheus.Address != "" {
+		if _, ok := c.EntryPoints[DefaultMetricsEntryPointName]; !ok {
+			ep := &EntryPoint{Address: c.Metrics.Prometheus.Address}
+			ep.SetDefaults()
+			c.EntryPoints[DefaultMetricsEntryPointName] = ep
+		}
+		c.Metrics.Prometheus.EntryPoint = DefaultMetricsEntryPointName
+	}
+
 	// Creates the internal traefik entry point if needed
 	if (c.API != nil && c.API.Insecure) ||
 		(c.Ping != nil && !c.Ping.ManualRouting && c.Ping.EntryPoint == DefaultInternalEntryPointName) ||
@@ -225,6 +299,16 @@ func (c *Configuration) SetEffectiveConfiguration() {
 	c.initACMEProvider()
 }
 
+// sharesMetricsEntryPoint returns whether the entry point of the Prometheus metrics also serves the API, the ping or the REST provider,
+// which would otherwise use the TLS options of the metrics.
+func (c *Configuration) sharesMetricsEntryPoint() bool {
+	entryPoint := c.Metrics.Prometheus.EntryPoint
+
+	return (c.API != nil && c.API.Insecure && entryPoint == DefaultInternalEntryPointName) ||
+		(c.Ping != nil && !c.Ping.ManualRouting && c.Ping.EntryPoint == entryPoint) ||
+		(c.Providers != nil && c.Providers.Rest != nil && c.Providers.Rest.Insecure && entryPoint == DefaultInternalEntryPointName)
+}
+
 func (c *Configuration) initACMEProvider() {
 	for _, resolver := range c.CertificatesResolvers {
 		if resolver.ACME != nil {
@@ -253,9 +337,104 @@ func (c *Configuration) ValidateConfiguration() error {
 		acmeEmail = resolver.ACME.Email
 	}
 
+	if c.Guardrails != nil {
+		for name, guardrails := range c.Guardrails.Namespaces {
+			if guardrails == nil || guardrails.Provider == "" {
+				return fmt.Errorf("the provider of the guardrails %q is required", name)
+			}
+		}
+	}
+
+	if c.Policies != nil && c.Policies.OPA != nil && c.Policies.OPA.URL == "" {
+		return errors.New("the URL of the Open Policy Agent server evaluating the policies is required")
+	}
+
+	if c.Cluster != nil && c.Cluster.Secret == "" {
+		return errors.New("unable to join the cluster without a secret")
+	}
+
+	if c.Instance != nil && c.Instance.Color != "" && !isValidColor(c.Instance.Color) {
+		return fmt.Errorf("invalid instance color %q, a hexadecimal color like #cc0000 is expected", c.Instance.Color)
+	}
+
+	if c.API != nil && c.API.Federation != nil {
+		for name, instance := range c.API.Federation.Instances {
+			if instance == nil || instance.URL == "" {
+				return fmt.Errorf("the URL of the federated instance %q is required", name)
+			}
+		}
+	}
+
+	for name, entryPoint := range c.EntryPoints {
+		if entryPoint.TLS != nil && entryPoint.TLS.CertificateSelection != "" && !isValidCertificateSelection(entryPoint.TLS.CertificateSelection) {
+			return fmt.Errorf("unknown certificate selection %q on the entry point %q, the valid ones are: %s",
+				entryPoint.TLS.CertificateSelection, name, strings.Join(tls.CertificateSelections(), ", "))
+		}
+	}
+
+	if c.Metrics != nil && c.Metrics.Prometheus != nil && c.Metrics.Prometheus.NativeHistograms != nil {
+		nativeHistograms := c.Metrics.Prometheus.NativeHistograms
+		if nativeHistograms.BucketFactor <= 1 {
+			return fmt.Errorf("invalid native histograms bucket factor %v, it must be greater than 1", nativeHistograms.BucketFactor)
+		}
+		if nativeHistograms.MaxBucketNumber < 0 {
+			return fmt.Errorf("invalid native histograms maximum bucket number %d, it must be positive", nativeHistograms.MaxBucketNumber)
+		}
+	}
+
+	if c.Metrics != nil && c.Metrics.Prometheus != nil {
+		if err := metrics.ValidateRequestLabels(c.Metrics.Prometheus.RequestLabels); err != nil {
+			return err
+		}
+		if err := metrics.ValidateRuntimeNamespace(c.Metrics.Prometheus.RuntimeNamespace); err != nil {
+			return err
+		}
+		if push := c.Metrics.Prometheus.Push; push != nil {
+			if push.URL == "" {
+				return errors.New("the URL of the Pushgateway is required")
+			}
+			if push.PushInterval <= 0 {
+				return fmt.Errorf("invalid Pushgateway push interval %s, it must be positive", time.Duration(push.PushInterval))
+			}
+		}
+		if c.Metrics.Prometheus.TLS != nil && !c.Metrics.Prometheus.ManualRouting && c.sharesMetricsEntryPoint() {
+			return fmt.Errorf("the TLS of the Prometheus metrics requires a dedicated entry point, %q serves other internal services", c.Metrics.Prometheus.EntryPoint)
+		}
+	}
+
+	if c.Tracing != nil && c.Tracing.ForceSampling != nil && len(c.Tracing.ForceSampling.TrustedIPs) == 0 {
+		return errors.New("the trusted IPs allowed to force the sampling of the traces are required")
+	}
+
+	if c.Ping != nil {
+		for _, check := range c.Ping.HealthChecks {
+			if !isValidHealthCheck(check) {
+				return fmt.Errorf("unknown health check %q, the valid ones are: %s", check, strings.Join(ping.ValidHealthChecks(), ", "))
+			}
+		}
+	}
+
 	return nil
 }
 
+func isValidCertificateSelection(selection string) bool {
+	for _, valid := range tls.CertificateSelections() {
+		if selection == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidHealthCheck(check string) bool {
+	for _, valid := range ping.ValidHealthChecks() {
+		if check == valid {
+			return true
+		}
+	}
+	return false
+}
+
 func getSafeACMECAServer(caServerSrc string) string {
 	if len(caServerSrc) == 0 {
 		return DefaultAcmeCAServer
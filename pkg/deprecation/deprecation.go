@@ -0,0 +1,203 @@
+package deprecation
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// Warning is a use of a deprecated configuration field or behavior.
+type Warning struct {
+	// Feature identifies the deprecated field or behavior.
+	Feature string `json:"feature"`
+	// Element is the qualified name of the element using the feature, empty for the static configuration.
+	Element     string `json:"element,omitempty"`
+	Since       string `json:"since"`
+	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// deprecation is a deprecated configuration field or behavior, and how to find its uses.
+type deprecation struct {
+	feature     string
+	since       string
+	message     string
+	replacement string
+
+	// static reports whether the static configuration, or the environment, uses the feature.
+	static func() bool
+	// dynamic returns the names of the elements of the dynamic configuration using the feature.
+	dynamic func(conf dynamic.Configuration) []string
+}
+
+var deprecations = []deprecation{
+	{
+		feature:     "http.middlewares.headers.accessControlAllowOrigin",
+		since:       "v2.2",
+		message:     "accessControlAllowOrigin is deprecated and will be removed in future 2.x releases",
+		replacement: "accessControlAllowOriginList",
+		dynamic: func(conf dynamic.Configuration) []string {
+			if conf.HTTP == nil {
+				return nil
+			}
+
+			var names []string
+			for name, middleware := range conf.HTTP.Middlewares {
+				if middleware.Headers != nil && middleware.Headers.AccessControlAllowOrigin != "" {
+					names = append(names, name)
+				}
+			}
+			return names
+		},
+	},
+	{
+		feature:     "http.middlewares.stripPrefix.forceSlash",
+		since:       "v2.2",
+		message:     "forceSlash is deprecated, and the prefix stripping will no longer turn an empty path into a slash in future releases",
+		replacement: "forceSlash=false",
+		dynamic: func(conf dynamic.Configuration) []string {
+			if conf.HTTP == nil {
+				return nil
+			}
+
+			var names []string
+			for name, middleware := range conf.HTTP.Middlewares {
+				if middleware.StripPrefix != nil && middleware.StripPrefix.ForceSlash {
+					names = append(names, name)
+				}
+			}
+			return names
+		},
+	},
+	{
+		feature:     "tls.x509CommonName",
+		since:       "v2.3",
+		message:     "treating the CommonName of the certificates as a host name is deprecated, and re-enabled by x509ignoreCN=0 in GODEBUG",
+		replacement: "certificates with Subject Alternative Names",
+		static: func() bool {
+			for _, value := range strings.Split(os.Getenv("GODEBUG"), ",") {
+				if strings.TrimSpace(value) == "x509ignoreCN=0" {
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+// Registry keeps track of the deprecated fields and behaviors used by the configuration.
+type Registry struct {
+	gauge metrics.Gauge
+
+	mu       sync.Mutex
+	static   []Warning
+	warnings []Warning
+	// reported holds the number of uses of each feature, as reported by the gauge.
+	reported map[string]int
+}
+
+// NewRegistry creates a Registry, and checks the deprecated behaviors enabled by the environment.
+// The gauge, which may be nil, reports the number of uses of each feature.
+func NewRegistry(gauge metrics.Gauge) *Registry {
+	r := &Registry{
+		gauge:    gauge,
+		reported: make(map[string]int),
+	}
+
+	for _, d := range deprecations {
+		if d.static != nil && d.static() {
+			r.static = append(r.static, d.warning(""))
+		}
+	}
+
+	r.update(context.Background(), r.static)
+
+	return r
+}
+
+// Check finds the uses of the deprecated fields and behaviors in the dynamic configuration,
+// and returns them along with the ones of the static configuration.
+// The new uses are logged, and the gauge is updated.
+func (r *Registry) Check(ctx context.Context, conf dynamic.Configuration) []Warning {
+	warnings := append([]Warning{}, r.static...)
+
+	for _, d := range deprecations {
+		if d.dynamic == nil {
+			continue
+		}
+
+		for _, name := range d.dynamic(conf) {
+			warnings = append(warnings, d.warning(name))
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].Feature != warnings[j].Feature {
+			return warnings[i].Feature < warnings[j].Feature
+		}
+		return warnings[i].Element < warnings[j].Element
+	})
+
+	r.update(ctx, warnings)
+
+	return warnings
+}
+
+func (r *Registry) update(ctx context.Context, warnings []Warning) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	known := make(map[Warning]struct{}, len(r.warnings))
+	for _, w := range r.warnings {
+		known[w] = struct{}{}
+	}
+
+	counts := make(map[string]int)
+	for _, w := range warnings {
+		counts[w.Feature]++
+
+		if _, ok := known[w]; ok {
+			continue
+		}
+
+		logger := log.FromContext(ctx).WithField("feature", w.Feature)
+		if w.Element != "" {
+			logger = logger.WithField("element", w.Element)
+		}
+		logger.Warnf("%s (deprecated since %s), please use %s instead", w.Message, w.Since, w.Replacement)
+	}
+
+	if r.gauge != nil {
+		for feature := range r.reported {
+			if _, ok := counts[feature]; !ok {
+				r.gauge.With("feature", feature).Set(0)
+				delete(r.reported, feature)
+			}
+		}
+
+		for feature, count := range counts {
+			if r.reported[feature] != count {
+				r.gauge.With("feature", feature).Set(float64(count))
+				r.reported[feature] = count
+			}
+		}
+	}
+
+	r.warnings = warnings
+}
+
+func (d deprecation) warning(element string) Warning {
+	return Warning{
+		Feature:     d.feature,
+		Element:     element,
+		Since:       d.since,
+		Message:     d.message,
+		Replacement: d.replacement,
+	}
+}
@@ -0,0 +1,96 @@
+package deprecation
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collectingGauge struct {
+	values map[string]float64
+	label  string
+}
+
+func newCollectingGauge() *collectingGauge {
+	return &collectingGauge{values: make(map[string]float64)}
+}
+
+func (g *collectingGauge) With(labelValues ...string) metrics.Gauge {
+	return &collectingGauge{values: g.values, label: labelValues[1]}
+}
+
+func (g *collectingGauge) Set(value float64) {
+	g.values[g.label] = value
+}
+
+func (g *collectingGauge) Add(delta float64) {
+	g.values[g.label] += delta
+}
+
+func TestRegistry_Check(t *testing.T) {
+	gauge := newCollectingGauge()
+	registry := NewRegistry(gauge)
+
+	conf := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"cors-b@file": {Headers: &dynamic.Headers{AccessControlAllowOrigin: "*"}},
+				"cors-a@file": {Headers: &dynamic.Headers{AccessControlAllowOrigin: "https://example.com"}},
+				"list@file":   {Headers: &dynamic.Headers{AccessControlAllowOriginList: []string{"*"}}},
+				"strip@file":  {StripPrefix: &dynamic.StripPrefix{Prefixes: []string{"/api"}}},
+				"slash@file":  {StripPrefix: &dynamic.StripPrefix{Prefixes: []string{"/api"}, ForceSlash: true}},
+			},
+		},
+	}
+
+	warnings := registry.Check(context.Background(), conf)
+	require.Len(t, warnings, 3)
+
+	assert.Equal(t, "http.middlewares.headers.accessControlAllowOrigin", warnings[0].Feature)
+	assert.Equal(t, "cors-a@file", warnings[0].Element)
+	assert.Equal(t, "v2.2", warnings[0].Since)
+	assert.Equal(t, "accessControlAllowOriginList", warnings[0].Replacement)
+	assert.Equal(t, "cors-b@file", warnings[1].Element)
+	assert.Equal(t, "http.middlewares.stripPrefix.forceSlash", warnings[2].Feature)
+	assert.Equal(t, "slash@file", warnings[2].Element)
+
+	assert.Equal(t, float64(2), gauge.values["http.middlewares.headers.accessControlAllowOrigin"])
+	assert.Equal(t, float64(1), gauge.values["http.middlewares.stripPrefix.forceSlash"])
+
+	delete(conf.HTTP.Middlewares, "cors-a@file")
+	delete(conf.HTTP.Middlewares, "cors-b@file")
+	delete(conf.HTTP.Middlewares, "slash@file")
+
+	warnings = registry.Check(context.Background(), conf)
+	assert.Empty(t, warnings)
+	assert.Equal(t, float64(0), gauge.values["http.middlewares.headers.accessControlAllowOrigin"])
+	assert.Equal(t, float64(0), gauge.values["http.middlewares.stripPrefix.forceSlash"])
+}
+
+func TestRegistry_Static(t *testing.T) {
+	previous, ok := os.LookupEnv("GODEBUG")
+	defer func() {
+		if ok {
+			_ = os.Setenv("GODEBUG", previous)
+		} else {
+			_ = os.Unsetenv("GODEBUG")
+		}
+	}()
+
+	require.NoError(t, os.Setenv("GODEBUG", "http2debug=1, x509ignoreCN=0"))
+
+	gauge := newCollectingGauge()
+	registry := NewRegistry(gauge)
+
+	assert.Equal(t, float64(1), gauge.values["tls.x509CommonName"])
+
+	warnings := registry.Check(context.Background(), dynamic.Configuration{})
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "tls.x509CommonName", warnings[0].Feature)
+	assert.Empty(t, warnings[0].Element)
+}
@@ -0,0 +1,117 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Database is a MaxMind DB database file, reloaded when it changes.
+type Database struct {
+	path string
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+}
+
+// Open opens the database file.
+func Open(path string) (*Database, error) {
+	db := &Database{path: path}
+	if _, err := db.reload(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Lookup returns the value at the path of the record of the IP.
+// The maps are decoded as map[string]interface{} and the unsigned integers as uint64.
+func (db *Database) Lookup(ip net.IP, path ...string) (interface{}, bool, error) {
+	db.mu.RLock()
+	reader := db.reader
+	db.mu.RUnlock()
+
+	var record interface{}
+	if _, found, err := reader.LookupNetwork(ip, &record); err != nil || !found {
+		return nil, false, err
+	}
+
+	value := record
+	for _, key := range path {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false, nil
+		}
+
+		if value, ok = fields[key]; !ok {
+			return nil, false, nil
+		}
+	}
+
+	return value, true, nil
+}
+
+// Watch reloads the database file every interval when it changed, until the context is done.
+func (db *Database) Watch(ctx context.Context, interval time.Duration) {
+	logger := log.FromContext(ctx).WithField("database", db.path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := db.reload()
+			if err != nil {
+				logger.Errorf("Unable to reload the MaxMind database: %v", err)
+				continue
+			}
+			if reloaded {
+				logger.Debug("MaxMind database reloaded")
+			}
+		}
+	}
+}
+
+// reload reads the database file when its modification time changed.
+// The current database is kept when the file cannot be read.
+func (db *Database) reload() (bool, error) {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return false, fmt.Errorf("unable to open the MaxMind database %s: %w", db.path, err)
+	}
+
+	db.mu.RLock()
+	unchanged := db.reader != nil && info.ModTime().Equal(db.modTime)
+	db.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	buf, err := ioutil.ReadFile(db.path)
+	if err != nil {
+		return false, fmt.Errorf("unable to read the MaxMind database %s: %w", db.path, err)
+	}
+
+	reader, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return false, fmt.Errorf("invalid MaxMind database %s: %w", db.path, err)
+	}
+
+	db.mu.Lock()
+	db.reader = reader
+	db.modTime = info.ModTime()
+	db.mu.Unlock()
+
+	return true, nil
+}
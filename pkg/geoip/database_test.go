@@ -0,0 +1,157 @@
+package geoip
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_Lookup(t *testing.T) {
+	country, err := Open(filepath.Join("testdata", "GeoIP2-Country-Test.mmdb"))
+	require.NoError(t, err)
+
+	asn, err := Open(filepath.Join("testdata", "GeoLite2-ASN-Test.mmdb"))
+	require.NoError(t, err)
+
+	anonymous, err := Open(filepath.Join("testdata", "GeoIP2-Anonymous-IP-Test.mmdb"))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		db       *Database
+		ip       string
+		path     []string
+		expected interface{}
+		found    bool
+	}{
+		{
+			desc:     "IPv4 nested value",
+			db:       country,
+			ip:       "81.2.69.160",
+			path:     []string{"country", "iso_code"},
+			expected: "GB",
+			found:    true,
+		},
+		{
+			desc:     "IPv6 nested value",
+			db:       country,
+			ip:       "2001:218::1",
+			path:     []string{"country", "iso_code"},
+			expected: "JP",
+			found:    true,
+		},
+		{
+			desc:     "unsigned integer",
+			db:       asn,
+			ip:       "1.128.0.1",
+			path:     []string{"autonomous_system_number"},
+			expected: uint64(1221),
+			found:    true,
+		},
+		{
+			desc:     "string",
+			db:       asn,
+			ip:       "1.128.0.1",
+			path:     []string{"autonomous_system_organization"},
+			expected: "Telstra Pty Ltd",
+			found:    true,
+		},
+		{
+			desc:     "boolean",
+			db:       anonymous,
+			ip:       "1.2.0.1",
+			path:     []string{"is_anonymous_vpn"},
+			expected: true,
+			found:    true,
+		},
+		{
+			desc:     "whole record",
+			db:       anonymous,
+			ip:       "1.2.0.1",
+			expected: map[string]interface{}{"is_anonymous": true, "is_anonymous_vpn": true},
+			found:    true,
+		},
+		{
+			desc: "unknown key",
+			db:   anonymous,
+			ip:   "1.2.0.1",
+			path: []string{"is_tor_exit_node"},
+		},
+		{
+			desc: "path through a value",
+			db:   asn,
+			ip:   "1.128.0.1",
+			path: []string{"autonomous_system_number", "foo"},
+		},
+		{
+			desc: "unknown network",
+			db:   country,
+			ip:   "192.0.2.1",
+			path: []string{"country", "iso_code"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			value, found, err := test.db.Lookup(net.ParseIP(test.ip), test.path...)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.found, found)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Open(filepath.Join(dir, "missing.mmdb"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "invalid.mmdb")
+	require.NoError(t, ioutil.WriteFile(path, []byte("not a database"), 0o600))
+
+	_, err = Open(path)
+	assert.Error(t, err)
+}
+
+func TestDatabase_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	write := func(fixture string, modTime time.Time) {
+		buf, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(path, buf, 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	now := time.Now()
+	write("GeoLite2-ASN-Test.mmdb", now.Add(-time.Hour))
+
+	db, err := Open(path)
+	require.NoError(t, err)
+
+	_, found, err := db.Lookup(net.ParseIP("81.2.69.160"), "country", "iso_code")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	write("GeoIP2-Country-Test.mmdb", now)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go db.Watch(ctx, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		value, _, err := db.Lookup(net.ParseIP("81.2.69.160"), "country", "iso_code")
+		return err == nil && value == "GB"
+	}, 5*time.Second, 10*time.Millisecond)
+}
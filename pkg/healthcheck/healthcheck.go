@@ -2,6 +2,7 @@ package healthcheck
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,10 +11,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/safe"
-	"github.com/go-kit/kit/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/vulcand/oxy/roundrobin"
 )
 
@@ -44,7 +47,8 @@ type BalancerHandler interface {
 // exposing only the required metrics necessary for the health check package.
 // This makes it easier for the tests.
 type metricsRegistry interface {
-	BackendServerUpGauge() metrics.Gauge
+	ServiceServerUpGauge() gokitmetrics.Gauge
+	ServiceHealthCheckDurationHistogram() metrics.ScalableHistogram
 }
 
 // Options are the public health check options.
@@ -111,6 +115,18 @@ type HealthCheck struct {
 	Backends map[string]*BackendConfig
 	metrics  metricsRegistry
 	cancel   context.CancelFunc
+	cluster  *cluster.Node
+}
+
+// SetCluster shares the health check results with the other instances of the cluster,
+// so each server is only checked by one instance.
+func (hc *HealthCheck) SetCluster(node *cluster.Node) {
+	hc.cluster = node
+}
+
+// SetMetricsRegistry reports the state of the servers, and the duration of their health checks, in the metrics.
+func (hc *HealthCheck) SetMetricsRegistry(registry metricsRegistry) {
+	hc.metrics = registry
 }
 
 // SetBackendsConfiguration set backends configuration.
@@ -155,7 +171,9 @@ func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig)
 	enabledURLs := backend.LB.Servers()
 	var newDisabledURLs []backendURL
 	for _, disabledURL := range backend.disabledURLs {
-		if err := checkHealth(disabledURL.url, backend); err == nil {
+		err := hc.check(disabledURL.url, backend)
+		hc.setServerUp(backend, disabledURL.url, err == nil)
+		if err == nil {
 			logger.Warnf("Health check up: Returning to server list. Backend: %q URL: %q Weight: %d",
 				backend.name, disabledURL.url.String(), disabledURL.weight)
 			if err = backend.LB.UpsertServer(disabledURL.url, roundrobin.Weight(disabledURL.weight)); err != nil {
@@ -169,7 +187,9 @@ func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig)
 	backend.disabledURLs = newDisabledURLs
 
 	for _, enableURL := range enabledURLs {
-		if err := checkHealth(enableURL, backend); err != nil {
+		err := hc.check(enableURL, backend)
+		hc.setServerUp(backend, enableURL, err == nil)
+		if err != nil {
 			weight := 1
 			rr, ok := backend.LB.(*roundrobin.RoundRobin)
 			if ok {
@@ -188,6 +208,62 @@ func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig)
 	}
 }
 
+// check checks the health of the server.
+// In cluster mode, the server is checked by the instance owning it,
+// and the other instances use the result it shares as long as it is fresh.
+func (hc *HealthCheck) check(serverURL *url.URL, backend *BackendConfig) error {
+	if hc.cluster == nil {
+		return hc.checkHealth(serverURL, backend)
+	}
+
+	key := "health/" + backend.name + "/" + serverURL.String()
+
+	if !hc.cluster.Owns(key) {
+		freshness := 2*backend.Interval + 3*hc.cluster.Interval()
+		if value, updated, ok := hc.cluster.Get(key); ok && time.Since(updated) < freshness {
+			if string(value) == serverUp {
+				return nil
+			}
+			return errors.New(string(value))
+		}
+	}
+
+	err := hc.checkHealth(serverURL, backend)
+	if err != nil {
+		hc.cluster.Set(key, []byte(err.Error()))
+	} else {
+		hc.cluster.Set(key, []byte(serverUp))
+	}
+
+	return err
+}
+
+// checkHealth checks the health of the server, and records the duration of the check.
+func (hc *HealthCheck) checkHealth(serverURL *url.URL, backend *BackendConfig) error {
+	start := time.Now()
+	err := checkHealth(serverURL, backend)
+
+	if hc.metrics != nil {
+		hc.metrics.ServiceHealthCheckDurationHistogram().With("service", backend.name, "url", serverURL.String()).ObserveFromStart(start)
+	}
+
+	return err
+}
+
+// setServerUp sets the server up gauge to 1 when the server is healthy, and to 0 otherwise.
+func (hc *HealthCheck) setServerUp(backend *BackendConfig, serverURL *url.URL, up bool) {
+	if hc.metrics == nil {
+		return
+	}
+
+	var value float64
+	if up {
+		value = 1
+	}
+
+	hc.metrics.ServiceServerUpGauge().With("service", backend.name, "url", serverURL.String()).Set(value)
+}
+
 // GetHealthCheck returns the health check which is guaranteed to be a singleton.
 func GetHealthCheck() *HealthCheck {
 	once.Do(func() {
@@ -6,13 +6,18 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/vulcand/oxy/roundrobin"
 )
 
@@ -120,7 +125,7 @@ func TestSetBackendsConfiguration(t *testing.T) {
 				backend.disabledURLs = append(backend.disabledURLs, backendURL{url: serverURL, weight: 1})
 			}
 
-			collectingMetrics := testhelpers.NewCollectingHealthCheckMetrics()
+			collectingMetrics := newCollectingMetrics()
 			check := HealthCheck{
 				Backends: make(map[string]*BackendConfig),
 				metrics:  collectingMetrics,
@@ -149,8 +154,9 @@ func TestSetBackendsConfiguration(t *testing.T) {
 
 			assert.Equal(t, test.expectedNumRemovedServers, lb.numRemovedServers, "removed servers")
 			assert.Equal(t, test.expectedNumUpsertedServers, lb.numUpsertedServers, "upserted servers")
-			// FIXME re add metrics
-			// assert.Equal(t, test.expectedGaugeValue, collectingMetrics.Gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, test.expectedGaugeValue, collectingMetrics.Gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, []string{"service", "backendName", "url", serverURL.String()}, collectingMetrics.Gauge.LastLabelValues)
+			assert.Equal(t, len(test.healthSequence), collectingMetrics.Histogram.observations, "health check durations")
 		})
 	}
 }
@@ -363,6 +369,43 @@ func TestAddHeadersAndHost(t *testing.T) {
 	}
 }
 
+// collectingMetrics adds the health check duration histogram to the collecting health check metrics.
+type collectingMetrics struct {
+	*testhelpers.CollectingHealthCheckMetrics
+	Histogram *collectingHistogram
+}
+
+func newCollectingMetrics() *collectingMetrics {
+	return &collectingMetrics{
+		CollectingHealthCheckMetrics: testhelpers.NewCollectingHealthCheckMetrics(),
+		Histogram:                    &collectingHistogram{},
+	}
+}
+
+func (m *collectingMetrics) ServiceHealthCheckDurationHistogram() metrics.ScalableHistogram {
+	return m.Histogram
+}
+
+type collectingHistogram struct {
+	observations int
+}
+
+func (h *collectingHistogram) With(...string) metrics.ScalableHistogram {
+	return h
+}
+
+func (h *collectingHistogram) Observe(float64) {
+	h.observations++
+}
+
+func (h *collectingHistogram) ObserveFromStart(time.Time) {
+	h.observations++
+}
+
+func (h *collectingHistogram) ObserveFromStartWithExemplar(time.Time, map[string]string) {
+	h.observations++
+}
+
 type testLoadBalancer struct {
 	// RWMutex needed due to parallel test execution: Both the system-under-test
 	// and the test assertions reference the counters.
@@ -504,7 +547,7 @@ func TestNotFollowingRedirects(t *testing.T) {
 
 	check := HealthCheck{
 		Backends: make(map[string]*BackendConfig),
-		metrics:  testhelpers.NewCollectingHealthCheckMetrics(),
+		metrics:  newCollectingMetrics(),
 	}
 
 	wg := sync.WaitGroup{}
@@ -525,3 +568,61 @@ func TestNotFollowingRedirects(t *testing.T) {
 
 	assert.False(t, redirectServerCalled, "HTTP redirect must not be followed")
 }
+
+func TestCheckCluster(t *testing.T) {
+	var probes int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := safe.NewPool(ctx)
+	t.Cleanup(func() {
+		cancel()
+		pool.Stop()
+	})
+
+	nodeA, err := cluster.New(&cluster.Configuration{Name: "a", Address: "127.0.0.1:0", Secret: "secret", GossipInterval: ptypes.Duration(20 * time.Millisecond)})
+	require.NoError(t, err)
+	nodeA.Start(pool)
+
+	nodeB, err := cluster.New(&cluster.Configuration{Name: "b", Address: "127.0.0.1:0", Peers: []string{nodeA.Address()}, Secret: "secret", GossipInterval: ptypes.Duration(20 * time.Millisecond)})
+	require.NoError(t, err)
+	nodeB.Start(pool)
+
+	require.Eventually(t, func() bool {
+		return nodeA.Size() == 2 && nodeB.Size() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+	key := "health/backendName/" + serverURL.String()
+
+	owner, other := nodeA, nodeB
+	if !nodeA.Owns(key) {
+		owner, other = nodeB, nodeA
+	}
+
+	backend := NewBackendConfig(Options{
+		Path:     "/path",
+		Interval: time.Minute,
+		Timeout:  healthCheckTimeout,
+	}, "backendName")
+
+	ownerCheck := newHealthCheck()
+	ownerCheck.SetCluster(owner)
+	require.NoError(t, ownerCheck.check(serverURL, backend))
+
+	require.Eventually(t, func() bool {
+		_, _, ok := other.Get(key)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The other instance uses the result shared by the owner, instead of checking the server itself.
+	otherCheck := newHealthCheck()
+	otherCheck.SetCluster(other)
+	require.NoError(t, otherCheck.check(serverURL, backend))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&probes))
+}
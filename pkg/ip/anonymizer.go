@@ -0,0 +1,121 @@
+package ip
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Anonymization modes.
+const (
+	AnonymizationTruncate = "truncate"
+	AnonymizationHash     = "hash"
+)
+
+// Anonymizer anonymizes the IPs, by truncating them to their network (IPv4 /24, IPv6 /64),
+// or by hashing them with a key rotated periodically.
+// The hashed IPs are still IPs, in the reserved 240.0.0.0/4 range for the IPv4 ones,
+// and in the unique local fd00::/8 range for the IPv6 ones.
+type Anonymizer struct {
+	mode     string
+	secret   []byte
+	rotation time.Duration
+
+	mu     sync.Mutex
+	period int64
+	key    []byte
+}
+
+// NewAnonymizer creates an Anonymizer.
+// The hashing keys are derived from the secret, or from a random one when it is empty.
+func NewAnonymizer(mode, secret string, rotation time.Duration) (*Anonymizer, error) {
+	switch mode {
+	case AnonymizationTruncate:
+		return &Anonymizer{mode: mode}, nil
+
+	case AnonymizationHash:
+		if rotation <= 0 {
+			return nil, errors.New("the key rotation must be positive")
+		}
+
+		a := &Anonymizer{mode: mode, secret: []byte(secret), rotation: rotation, period: -1}
+		if secret == "" {
+			a.secret = make([]byte, 32)
+			if _, err := rand.Read(a.secret); err != nil {
+				return nil, fmt.Errorf("unable to generate the secret: %w", err)
+			}
+		}
+		return a, nil
+
+	default:
+		return nil, fmt.Errorf("unknown anonymization mode %q, it must be %q or %q", mode, AnonymizationTruncate, AnonymizationHash)
+	}
+}
+
+// Anonymize anonymizes the IP.
+// The values which are not IPs are returned as is.
+func (a *Anonymizer) Anonymize(value string) string {
+	ip := net.ParseIP(strings.Split(value, "%")[0])
+	if ip == nil {
+		return value
+	}
+
+	if a.mode == AnonymizationTruncate {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.Mask(net.CIDRMask(24, 32)).String()
+		}
+		return ip.Mask(net.CIDRMask(64, 128)).String()
+	}
+
+	mac := hmac.New(sha256.New, a.currentKey(time.Now()))
+	_, _ = mac.Write(ip.To16())
+	sum := mac.Sum(nil)
+
+	if ip.To4() != nil {
+		hashed := net.IP(sum[:4])
+		hashed[0] = 0xf0 | hashed[0]&0x0f
+		return hashed.String()
+	}
+
+	hashed := net.IP(sum[:16])
+	hashed[0] = 0xfd
+	return hashed.String()
+}
+
+// AnonymizeList anonymizes the comma-separated IPs of a header like X-Forwarded-For.
+func (a *Anonymizer) AnonymizeList(values string) string {
+	items := strings.Split(values, ",")
+	for i, item := range items {
+		items[i] = a.Anonymize(strings.TrimSpace(item))
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// currentKey returns the hashing key of the current rotation period, derived from the secret.
+// The instances sharing the secret hash the IPs the same way.
+func (a *Anonymizer) currentKey(now time.Time) []byte {
+	period := now.UnixNano() / int64(a.rotation)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if period != a.period {
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], uint64(period))
+
+		mac := hmac.New(sha256.New, a.secret)
+		_, _ = mac.Write(counter[:])
+		a.key = mac.Sum(nil)
+		a.period = period
+	}
+
+	return a.key
+}
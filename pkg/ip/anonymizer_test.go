@@ -0,0 +1,67 @@
+package ip
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymizer_Truncate(t *testing.T) {
+	anonymizer, err := NewAnonymizer(AnonymizationTruncate, "", 0)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "10.1.2.3", expected: "10.1.2.0"},
+		{value: "::ffff:10.1.2.3", expected: "10.1.2.0"},
+		{value: "2001:db8:1:2:3:4:5:6", expected: "2001:db8:1:2::"},
+		{value: "fe80::1:2%eth0", expected: "fe80::"},
+		{value: "unknown", expected: "unknown"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, anonymizer.Anonymize(test.value), test.value)
+	}
+}
+
+func TestAnonymizer_Hash(t *testing.T) {
+	anonymizer, err := NewAnonymizer(AnonymizationHash, "secret", time.Hour)
+	require.NoError(t, err)
+
+	v4 := anonymizer.Anonymize("10.1.2.3")
+	assert.NotEqual(t, "10.1.2.3", v4)
+	assert.Equal(t, v4, anonymizer.Anonymize("10.1.2.3"))
+	assert.NotEqual(t, v4, anonymizer.Anonymize("10.1.2.4"))
+
+	_, reserved, _ := net.ParseCIDR("240.0.0.0/4")
+	assert.True(t, reserved.Contains(net.ParseIP(v4)), v4)
+
+	v6 := anonymizer.Anonymize("2001:db8::1")
+	_, uniqueLocal, _ := net.ParseCIDR("fd00::/8")
+	assert.True(t, uniqueLocal.Contains(net.ParseIP(v6)), v6)
+
+	// The instances sharing the secret hash the IPs the same way.
+	other, err := NewAnonymizer(AnonymizationHash, "secret", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, v4, other.Anonymize("10.1.2.3"))
+
+	// The key is rotated.
+	now := time.Now()
+	assert.Equal(t, anonymizer.currentKey(now), anonymizer.currentKey(now))
+	assert.NotEqual(t, anonymizer.currentKey(now), anonymizer.currentKey(now.Add(time.Hour)))
+
+	assert.Equal(t, v4+", "+v6+", unknown", anonymizer.AnonymizeList("10.1.2.3,2001:db8::1, unknown"))
+}
+
+func TestNewAnonymizer_Errors(t *testing.T) {
+	_, err := NewAnonymizer("foo", "", time.Hour)
+	assert.Error(t, err)
+
+	_, err = NewAnonymizer(AnonymizationHash, "", 0)
+	assert.Error(t, err)
+}
@@ -11,15 +11,17 @@ import (
 type Checker struct {
 	authorizedIPs    []*net.IP
 	authorizedIPsNet []*net.IPNet
+	authorizedSets   []*Set
 }
 
-// NewChecker builds a new Checker given a list of CIDR-Strings to trusted IPs.
-func NewChecker(trustedIPs []string) (*Checker, error) {
-	if len(trustedIPs) == 0 {
+// NewChecker builds a new Checker given a list of CIDR-Strings to trusted IPs,
+// and the sets of trusted IPs, whose IPs are checked as they are at the time.
+func NewChecker(trustedIPs []string, trustedSets ...*Set) (*Checker, error) {
+	if len(trustedIPs) == 0 && len(trustedSets) == 0 {
 		return nil, errors.New("no trusted IPs provided")
 	}
 
-	checker := &Checker{}
+	checker := &Checker{authorizedSets: trustedSets}
 
 	for _, ipMask := range trustedIPs {
 		if ipAddr := net.ParseIP(ipMask); ipAddr != nil {
@@ -86,6 +88,12 @@ func (ip *Checker) ContainsIP(addr net.IP) bool {
 		}
 	}
 
+	for _, authorizedSet := range ip.authorizedSets {
+		if authorizedSet.ContainsIP(addr) {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -0,0 +1,66 @@
+package ip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+)
+
+// Set is a set of IPs and CIDRs, whose content can be replaced while it is checked.
+// The zero value is an empty set.
+type Set struct {
+	checker atomic.Value
+}
+
+// Update replaces the IPs and CIDRs of the set.
+func (s *Set) Update(ips []string) error {
+	var checker *Checker
+	if len(ips) > 0 {
+		var err error
+		checker, err = NewChecker(ips)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.checker.Store(checker)
+
+	return nil
+}
+
+// ContainsIP checks if provided address is in the set.
+func (s *Set) ContainsIP(addr net.IP) bool {
+	checker, _ := s.checker.Load().(*Checker)
+
+	return checker != nil && checker.ContainsIP(addr)
+}
+
+// Sets gives the named IP sets.
+type Sets interface {
+	// Get returns the IP set of the name, qualified by the provider of the context when it is not already.
+	Get(ctx context.Context, name string) (*Set, error)
+}
+
+// GetSets returns the IP sets of the names.
+func GetSets(ctx context.Context, sets Sets, names []string) ([]*Set, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	if sets == nil {
+		return nil, errors.New("the IP sets are not available")
+	}
+
+	result := make([]*Set, 0, len(names))
+	for _, name := range names {
+		set, err := sets.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, set)
+	}
+
+	return result, nil
+}
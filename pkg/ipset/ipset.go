@@ -0,0 +1,294 @@
+package ipset
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/server/provider"
+)
+
+// maxListSize is the size of the largest list of IPs and CIDRs loaded from a URL.
+const maxListSize = 10 * 1024 * 1024
+
+// fileProviderName is the name of the only provider allowed to define the file of a set.
+const fileProviderName = "file"
+
+// Manager manages the named IP sets of the dynamic configuration,
+// loading again the lists of their URLs and files at their refresh interval.
+// The sets are shared: the middlewares and the entry points referencing a set see its updates.
+type Manager struct {
+	client *http.Client
+
+	mu   sync.Mutex
+	sets map[string]*managedSet
+}
+
+// managedSet is a named IP set, which is empty while it is not defined by the configuration.
+type managedSet struct {
+	set    *ip.Set
+	config *dynamic.IPSet
+	cancel context.CancelFunc
+}
+
+// NewManager creates a new Manager.
+func NewManager() *Manager {
+	return &Manager{
+		client: &http.Client{Timeout: 10 * time.Second},
+		sets:   make(map[string]*managedSet),
+	}
+}
+
+// UpdateConfigs updates the IP sets with the ones of the configuration, the sets no longer defined becoming empty.
+// The lists of the URLs and files of the new or updated sets are loaded concurrently, without holding the lock,
+// before it returns, the sets keeping their previous IPs meanwhile, and when a list cannot be loaded.
+func (m *Manager) UpdateConfigs(ctx context.Context, configs map[string]*dynamic.IPSet) {
+	m.mu.Lock()
+	updated := m.updateConfigs(configs)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, managed := range updated {
+		wg.Add(1)
+
+		name, managed, config := name, managed, managed.config
+		safe.Go(func() {
+			defer wg.Done()
+
+			logger := log.FromContext(log.With(ctx, log.Str(log.IPSetName, name)))
+			m.update(logger, name, managed, config)
+		})
+	}
+	wg.Wait()
+}
+
+// updateConfigs records the configurations, and returns the sets to load, by name.
+func (m *Manager) updateConfigs(configs map[string]*dynamic.IPSet) map[string]*managedSet {
+	for name, managed := range m.sets {
+		if configs[name] != nil || managed.config == nil {
+			continue
+		}
+
+		managed.stop()
+		managed.config = nil
+		_ = managed.set.Update(nil)
+	}
+
+	updated := make(map[string]*managedSet)
+	for name, config := range configs {
+		if config == nil {
+			continue
+		}
+
+		managed := m.getOrCreate(name)
+		if reflect.DeepEqual(managed.config, config) {
+			continue
+		}
+
+		managed.stop()
+		managed.config = config
+		updated[name] = managed
+	}
+
+	return updated
+}
+
+// update loads the IPs of the configuration into the set, and starts refreshing them at its refresh interval.
+func (m *Manager) update(logger log.Logger, name string, managed *managedSet, config *dynamic.IPSet) {
+	// The other providers, such as the labels of the containers, must not read the files of the host.
+	if config.File != "" && !strings.HasSuffix(name, "@"+fileProviderName) {
+		logger.Errorf("Unable to load the IP set: the file of an IP set is only allowed with the %s provider", fileProviderName)
+		return
+	}
+
+	if err := m.load(managed, config); err != nil {
+		logger.Errorf("Unable to load the IP set: %v", err)
+	}
+
+	if (config.URL == "" && config.File == "") || config.RefreshInterval <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The configuration of the set was updated meanwhile.
+	if managed.config != config {
+		return
+	}
+
+	refreshCtx, cancel := context.WithCancel(context.Background())
+	managed.cancel = cancel
+
+	safe.Go(func() {
+		m.refresh(refreshCtx, logger, managed, config)
+	})
+}
+
+// Get returns the IP set of the name, qualified by the provider of the context when it is not already.
+func (m *Manager) Get(ctx context.Context, name string) (*ip.Set, error) {
+	qualifiedName := provider.GetQualifiedName(ctx, name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	managed, ok := m.sets[qualifiedName]
+	if !ok || managed.config == nil {
+		return nil, fmt.Errorf("the IP set %s does not exist", qualifiedName)
+	}
+
+	return managed.set, nil
+}
+
+// Reference returns the IP set of the qualified name, which is empty until the configuration defines it.
+// It is used by the entry points, which are created before the dynamic configuration is loaded.
+func (m *Manager) Reference(name string) *ip.Set {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.getOrCreate(name).set
+}
+
+func (m *Manager) getOrCreate(name string) *managedSet {
+	managed, ok := m.sets[name]
+	if !ok {
+		managed = &managedSet{set: &ip.Set{}}
+		m.sets[name] = managed
+	}
+
+	return managed
+}
+
+func (m *Manager) refresh(ctx context.Context, logger log.Logger, managed *managedSet, config *dynamic.IPSet) {
+	ticker := time.NewTicker(time.Duration(config.RefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.load(managed, config); err != nil {
+				logger.Errorf("Unable to refresh the IP set, keeping its previous IPs: %v", err)
+			}
+		}
+	}
+}
+
+// load replaces the IPs of the set with the ones of the configuration, and the ones of its URL and file,
+// unless the configuration of the set was updated meanwhile.
+// The set is left as is when a list cannot be loaded.
+func (m *Manager) load(managed *managedSet, config *dynamic.IPSet) error {
+	ips, err := m.list(config)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if managed.config != config {
+		return nil
+	}
+
+	return managed.set.Update(ips)
+}
+
+// list returns the IPs of the configuration, and the ones of its URL and file.
+func (m *Manager) list(config *dynamic.IPSet) ([]string, error) {
+	ips := append([]string(nil), config.SourceRange...)
+
+	if config.URL != "" {
+		list, err := m.fetch(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch %s: %w", config.URL, err)
+		}
+
+		listIPs, err := parseList(list)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list %s: %w", config.URL, err)
+		}
+
+		ips = append(ips, listIPs...)
+	}
+
+	if config.File != "" {
+		list, err := ioutil.ReadFile(config.File)
+		if err != nil {
+			return nil, err
+		}
+
+		listIPs, err := parseList(string(list))
+		if err != nil {
+			return nil, fmt.Errorf("invalid list %s: %w", config.File, err)
+		}
+
+		ips = append(ips, listIPs...)
+	}
+
+	return ips, nil
+}
+
+func (m *Manager) fetch(url string) (string, error) {
+	resp, err := m.client.Get(url)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	list, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxListSize))
+	if err != nil {
+		return "", err
+	}
+
+	return string(list), nil
+}
+
+func (s *managedSet) stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// parseList returns the IPs and CIDRs of a list, separated by spaces, commas or new lines,
+// the end of the lines from a # being comments.
+// The invalid entries are reported by line, without their value, the content of a file not meant to be a list staying private.
+func parseList(list string) ([]string, error) {
+	var ips []string
+	for i, line := range strings.Split(list, "\n") {
+		if j := strings.Index(line, "#"); j >= 0 {
+			line = line[:j]
+		}
+
+		for _, entry := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		}) {
+			if net.ParseIP(entry) == nil {
+				if _, _, err := net.ParseCIDR(entry); err != nil {
+					return nil, fmt.Errorf("line %d is not a list of IPs and CIDRs", i+1)
+				}
+			}
+
+			ips = append(ips, entry)
+		}
+	}
+
+	return ips, nil
+}
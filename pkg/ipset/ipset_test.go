@@ -0,0 +1,274 @@
+package ipset
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestManager_UpdateConfigs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, "# Cloud provider ranges\n10.1.0.0/16, 10.2.0.0/16\n10.3.0.1 # a single IP\n")
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ipset")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "ips.txt")
+	err = ioutil.WriteFile(file, []byte("10.4.0.0/16\n"), 0o600)
+	require.NoError(t, err)
+
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {
+			SourceRange: []string{"10.0.0.0/16"},
+			URL:         server.URL,
+			File:        file,
+		},
+	})
+
+	set, err := manager.Get(context.Background(), "foo@file")
+	require.NoError(t, err)
+
+	for _, addr := range []string{"10.0.0.1", "10.1.0.1", "10.2.0.1", "10.3.0.1", "10.4.0.1"} {
+		assert.True(t, set.ContainsIP(net.ParseIP(addr)), addr)
+	}
+	for _, addr := range []string{"10.3.0.2", "10.5.0.1"} {
+		assert.False(t, set.ContainsIP(net.ParseIP(addr)), addr)
+	}
+
+	// The set is emptied when it is no longer defined, for the entry points and middlewares still referencing it.
+	manager.UpdateConfigs(context.Background(), nil)
+
+	assert.False(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+
+	_, err = manager.Get(context.Background(), "foo@file")
+	assert.Error(t, err)
+}
+
+func TestManager_refresh(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipset")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "ips.txt")
+	err = ioutil.WriteFile(file, []byte("10.0.0.1\n"), 0o600)
+	require.NoError(t, err)
+
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {
+			File:            file,
+			RefreshInterval: ptypes.Duration(10 * time.Millisecond),
+		},
+	})
+	defer manager.UpdateConfigs(context.Background(), nil)
+
+	set := manager.Reference("foo@file")
+	assert.True(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+
+	err = ioutil.WriteFile(file, []byte("10.0.0.2\n"), 0o600)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return set.ContainsIP(net.ParseIP("10.0.0.2")) && !set.ContainsIP(net.ParseIP("10.0.0.1"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestManager_Reference(t *testing.T) {
+	manager := NewManager()
+
+	// The entry points reference the sets before the dynamic configuration is loaded.
+	set := manager.Reference("foo@file")
+	assert.False(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {SourceRange: []string{"10.0.0.1"}},
+	})
+
+	assert.True(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+}
+
+func TestManager_Get(t *testing.T) {
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {SourceRange: []string{"10.0.0.1"}},
+	})
+
+	testCases := []struct {
+		desc        string
+		ctx         context.Context
+		name        string
+		expectedErr bool
+	}{
+		{
+			desc: "qualified name",
+			ctx:  context.Background(),
+			name: "foo@file",
+		},
+		{
+			desc: "name qualified by the provider of the context",
+			ctx:  provider.AddInContext(context.Background(), "bar@file"),
+			name: "foo",
+		},
+		{
+			desc:        "name of another provider",
+			ctx:         provider.AddInContext(context.Background(), "bar@docker"),
+			name:        "foo",
+			expectedErr: true,
+		},
+		{
+			desc:        "undefined set",
+			ctx:         context.Background(),
+			name:        "bar@file",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			set, err := manager.Get(test.ctx, test.name)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+		})
+	}
+}
+
+func TestManager_UpdateConfigs_invalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {SourceRange: []string{"10.0.0.1"}},
+	})
+
+	set := manager.Reference("foo@file")
+
+	// The previous IPs are kept when a list cannot be loaded.
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {SourceRange: []string{"10.0.0.2"}, URL: server.URL},
+	})
+
+	assert.True(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+	assert.False(t, set.ContainsIP(net.ParseIP("10.0.0.2")))
+}
+
+func TestManager_UpdateConfigs_unlocked(t *testing.T) {
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(requested)
+		<-release
+		_, _ = fmt.Fprint(rw, "10.0.0.2\n")
+	}))
+	defer server.Close()
+
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file": {SourceRange: []string{"10.0.0.1"}},
+	})
+
+	updated := make(chan struct{})
+	go func() {
+		manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+			"foo@file": {URL: server.URL},
+		})
+		close(updated)
+	}()
+
+	<-requested
+
+	// The sets are available, with their previous IPs, while the lists are fetched.
+	set, err := manager.Get(context.Background(), "foo@file")
+	require.NoError(t, err)
+	assert.True(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+	assert.False(t, set.ContainsIP(net.ParseIP("10.0.0.2")))
+
+	close(release)
+	<-updated
+
+	assert.False(t, set.ContainsIP(net.ParseIP("10.0.0.1")))
+	assert.True(t, set.ContainsIP(net.ParseIP("10.0.0.2")))
+}
+
+func TestManager_UpdateConfigs_fileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipset")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "ips.txt")
+	err = ioutil.WriteFile(file, []byte("10.0.0.1\n"), 0o600)
+	require.NoError(t, err)
+
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), map[string]*dynamic.IPSet{
+		"foo@file":   {File: file},
+		"foo@docker": {File: file},
+	})
+
+	assert.True(t, manager.Reference("foo@file").ContainsIP(net.ParseIP("10.0.0.1")))
+	assert.False(t, manager.Reference("foo@docker").ContainsIP(net.ParseIP("10.0.0.1")))
+}
+
+func TestParseList(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		list        string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			desc:     "IPs and CIDRs",
+			list:     "# Cloud provider ranges\n10.1.0.0/16, 10.2.0.0/16\r\n10.3.0.1 # a single IP\n\n",
+			expected: []string{"10.1.0.0/16", "10.2.0.0/16", "10.3.0.1"},
+		},
+		{
+			desc:        "invalid line",
+			list:        "10.0.0.1\nroot:secret\n",
+			expectedErr: "line 2 is not a list of IPs and CIDRs",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ips, err := parseList(test.list)
+			if test.expectedErr != "" {
+				// The content of the list is not reported.
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ips)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewEventLogHook creates a hook writing the logs to the Windows Event Log, with the given event source.
+func NewEventLogHook(_ string) (logrus.Hook, error) {
+	return nil, errors.New("the Windows Event Log is only available on Windows")
+}
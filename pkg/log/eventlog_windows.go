@@ -0,0 +1,52 @@
+//go:build windows
+// +build windows
+
+package log
+
+import (
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventID is the identifier of the events written by Traefik.
+const eventID = 1
+
+type eventLogHook struct {
+	log       *eventlog.Log
+	formatter logrus.Formatter
+}
+
+// NewEventLogHook creates a hook writing the logs to the Windows Event Log, with the given event source.
+func NewEventLogHook(source string) (logrus.Hook, error) {
+	l, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventLogHook{
+		log:       l,
+		formatter: &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true, DisableSorting: true},
+	}, nil
+}
+
+// Levels implements logrus.Hook.
+func (h *eventLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *eventLogHook) Fire(entry *logrus.Entry) error {
+	msg, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return h.log.Error(eventID, string(msg))
+	case logrus.WarnLevel:
+		return h.log.Warning(eventID, string(msg))
+	default:
+		return h.log.Info(eventID, string(msg))
+	}
+}
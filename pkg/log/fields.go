@@ -13,4 +13,5 @@ const (
 	TracingProviderName = "tracingProviderName"
 	ServerName          = "serverName"
 	TLSStoreName        = "tlsStoreName"
+	IPSetName           = "ipSetName"
 )
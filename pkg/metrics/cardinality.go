@@ -0,0 +1,310 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// cardinalityOverflowValue is the value of the labels of the combinations exceeding the cardinality limit.
+const cardinalityOverflowValue = "other"
+
+// cardinalityLimiters are the limiters of the registries, pruned on each configuration update.
+var cardinalityLimiters = &limiterSet{}
+
+type limiterSet struct {
+	mu       sync.Mutex
+	limiters []*cardinalityLimiter
+}
+
+func (s *limiterSet) add(limiter *cardinalityLimiter) *cardinalityLimiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limiters = append(s.limiters, limiter)
+	return limiter
+}
+
+// prune forgets the label combinations of the entry points, routers, middlewares, services and servers no longer defined,
+// for the new ones to take their place.
+func (s *limiterSet) prune(config *dynamicConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, limiter := range s.limiters {
+		limiter.prune(config)
+	}
+}
+
+// cardinalityLimiter caps the number of label combinations of a metric,
+// the combinations seen beyond the limit being collapsed into a single one whose values are "other".
+type cardinalityLimiter struct {
+	name     string
+	max      int
+	overflow metrics.Counter
+
+	mu     sync.Mutex
+	seen   map[string][]string
+	warned bool
+}
+
+func newCardinalityLimiter(name string, max int, overflow metrics.Counter) *cardinalityLimiter {
+	return cardinalityLimiters.add(&cardinalityLimiter{
+		name:     name,
+		max:      max,
+		overflow: overflow,
+		seen:     make(map[string][]string),
+	})
+}
+
+// limit returns the label values to record the metric with.
+// As the seen combinations are only forgotten when their configuration is removed,
+// a combination is either always kept or always collapsed meanwhile.
+func (l *cardinalityLimiter) limit(labelValues []string) []string {
+	key := strings.Join(labelValues, "\x00")
+
+	l.mu.Lock()
+	_, seen := l.seen[key]
+	if !seen && len(l.seen) < l.max {
+		l.seen[key] = labelValues
+		seen = true
+	}
+
+	warn := !seen && !l.warned
+	if warn {
+		l.warned = true
+	}
+	l.mu.Unlock()
+
+	if seen {
+		return labelValues
+	}
+
+	if warn {
+		log.WithoutContext().Warnf("The metric %s reached its limit of %d label combinations, the new combinations are recorded as %q", l.name, l.max, cardinalityOverflowValue)
+	}
+
+	if l.overflow != nil {
+		l.overflow.With("metric", l.name).Add(1)
+	}
+
+	collapsed := make([]string, len(labelValues))
+	for i := range labelValues {
+		if i%2 == 0 {
+			// Label names.
+			collapsed[i] = labelValues[i]
+		} else {
+			collapsed[i] = cardinalityOverflowValue
+		}
+	}
+
+	return collapsed
+}
+
+// forget forgets the label combination, for a new one to take its place,
+// and returns whether it was seen, i.e. whether it has its own series.
+func (l *cardinalityLimiter) forget(labelValues []string) bool {
+	key := strings.Join(labelValues, "\x00")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, seen := l.seen[key]
+	delete(l.seen, key)
+
+	return seen
+}
+
+// prune forgets the label combinations belonging to an outdated configuration.
+func (l *cardinalityLimiter) prune(config *dynamicConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, labelValues := range l.seen {
+		labels := make(map[string]string, len(labelValues)/2)
+		for i := 0; i+1 < len(labelValues); i += 2 {
+			labels[labelValues[i]] = labelValues[i+1]
+		}
+
+		if config.isOutdated(labels) {
+			delete(l.seen, key)
+		}
+	}
+}
+
+// limitCardinality caps the number of label combinations of each metric of the registry carrying dynamic labels,
+// counting the observations of the collapsed combinations with the overflow counter, labeled by metric.
+func limitCardinality(reg *standardRegistry, max int, overflow metrics.Counter) {
+	if max <= 0 {
+		return
+	}
+
+	counter := func(c metrics.Counter, name string) metrics.Counter {
+		if c == nil {
+			return nil
+		}
+		return &limitedCounter{counter: c, limiter: newCardinalityLimiter(name, max, overflow)}
+	}
+	gauge := func(g metrics.Gauge, name string) metrics.Gauge {
+		if g == nil {
+			return nil
+		}
+		return &limitedGauge{gauge: g, limiter: newCardinalityLimiter(name, max, overflow)}
+	}
+	histogram := func(h metrics.Histogram, name string) metrics.Histogram {
+		if h == nil {
+			return nil
+		}
+		return &limitedHistogram{histogram: h, limiter: newCardinalityLimiter(name, max, overflow)}
+	}
+	scalableHistogram := func(h ScalableHistogram, name string) ScalableHistogram {
+		if h == nil {
+			return nil
+		}
+		return &limitedScalableHistogram{histogram: h, limiter: newCardinalityLimiter(name, max, overflow)}
+	}
+
+	reg.entryPointReqsCounter = counter(reg.entryPointReqsCounter, entryPointReqsTotalName)
+	reg.entryPointReqsTLSCounter = counter(reg.entryPointReqsTLSCounter, entryPointReqsTLSTotalName)
+	reg.entryPointReqDurationHistogram = scalableHistogram(reg.entryPointReqDurationHistogram, entryPointReqDurationName)
+	reg.entryPointOpenConnsGauge = gauge(reg.entryPointOpenConnsGauge, entryPointOpenConnsName)
+	reg.entryPointHTTP2AbusesCounter = counter(reg.entryPointHTTP2AbusesCounter, entryPointHTTP2AbusesName)
+	reg.entryPointTCPConnsCounter = counter(reg.entryPointTCPConnsCounter, entryPointTCPConnsTotalName)
+	reg.entryPointTCPOpenConnsGauge = gauge(reg.entryPointTCPOpenConnsGauge, entryPointTCPOpenConnsName)
+	reg.entryPointTCPBytesCounter = counter(reg.entryPointTCPBytesCounter, entryPointTCPBytesTotalName)
+	reg.entryPointUDPDatagramsCounter = counter(reg.entryPointUDPDatagramsCounter, entryPointUDPDatagramsTotalName)
+	reg.entryPointUDPBytesCounter = counter(reg.entryPointUDPBytesCounter, entryPointUDPBytesTotalName)
+
+	reg.routerReqsCounter = counter(reg.routerReqsCounter, routerReqsTotalName)
+	reg.routerReqsTLSCounter = counter(reg.routerReqsTLSCounter, routerReqsTLSTotalName)
+	reg.routerReqDurationHistogram = scalableHistogram(reg.routerReqDurationHistogram, routerReqDurationName)
+	reg.routerOpenConnsGauge = gauge(reg.routerOpenConnsGauge, routerOpenConnsName)
+
+	reg.serviceReqsCounter = counter(reg.serviceReqsCounter, serviceReqsTotalName)
+	reg.serviceReqsTLSCounter = counter(reg.serviceReqsTLSCounter, serviceReqsTLSTotalName)
+	reg.serviceReqDurationHistogram = scalableHistogram(reg.serviceReqDurationHistogram, serviceReqDurationName)
+	reg.serviceOpenConnsGauge = gauge(reg.serviceOpenConnsGauge, serviceOpenConnsName)
+	reg.serviceRetriesCounter = counter(reg.serviceRetriesCounter, serviceRetriesTotalName)
+	reg.serviceRetryDurationHistogram = scalableHistogram(reg.serviceRetryDurationHistogram, serviceRetryDurationName)
+	reg.serviceServerUpGauge = gauge(reg.serviceServerUpGauge, serviceServerUpName)
+	reg.serviceHealthCheckDurationHistogram = scalableHistogram(reg.serviceHealthCheckDurationHistogram, serviceHealthCheckDurationName)
+	reg.serviceReqSizeHistogram = histogram(reg.serviceReqSizeHistogram, serviceReqSizeName)
+	reg.serviceRespSizeHistogram = histogram(reg.serviceRespSizeHistogram, serviceRespSizeName)
+	reg.serviceTCPRTTHistogram = scalableHistogram(reg.serviceTCPRTTHistogram, serviceTCPRTTName)
+	reg.serviceTCPRetransmitsCounter = counter(reg.serviceTCPRetransmitsCounter, serviceTCPRetransmitsName)
+	reg.serviceTCPDeliveryRateHistogram = histogram(reg.serviceTCPDeliveryRateHistogram, serviceTCPDeliveryRateName)
+
+	reg.routerHTTPVersionRejectionsCounter = counter(reg.routerHTTPVersionRejectionsCounter, routerHTTPVersionRejectionsName)
+	reg.middlewareOPADecisionsCounter = counter(reg.middlewareOPADecisionsCounter, middlewareOPADecisionsName)
+	reg.middlewareCircuitBreakerStateGauge = gauge(reg.middlewareCircuitBreakerStateGauge, middlewareCircuitBreakerStateName)
+	reg.middlewareCircuitBreakerTripsCounter = counter(reg.middlewareCircuitBreakerTripsCounter, middlewareCircuitBreakerTripsName)
+	reg.middlewareRateLimitRequestsCounter = counter(reg.middlewareRateLimitRequestsCounter, middlewareRateLimitRequestsName)
+	reg.middlewareRateLimitBucketTokensGauge = gauge(reg.middlewareRateLimitBucketTokensGauge, middlewareRateLimitBucketTokensName)
+	reg.middlewareReqsCounter = counter(reg.middlewareReqsCounter, middlewareReqsTotalName)
+	reg.middlewareReqDurationHistogram = scalableHistogram(reg.middlewareReqDurationHistogram, middlewareReqDurationName)
+	reg.middlewareShortCircuitsCounter = counter(reg.middlewareShortCircuitsCounter, middlewareShortCircuitsName)
+	reg.middlewareErrorsCounter = counter(reg.middlewareErrorsCounter, middlewareErrorsTotalName)
+
+	reg.acmeChallengeFailuresCounter = counter(reg.acmeChallengeFailuresCounter, acmeChallengeFailuresTotalName)
+}
+
+// limitedCounter is a counter whose label values are accumulated until the counter is incremented,
+// to apply the cardinality limit to the whole combination.
+type limitedCounter struct {
+	counter     metrics.Counter
+	limiter     *cardinalityLimiter
+	labelValues []string
+}
+
+func (c *limitedCounter) With(labelValues ...string) metrics.Counter {
+	return &limitedCounter{counter: c.counter, limiter: c.limiter, labelValues: appendLabelValues(c.labelValues, labelValues)}
+}
+
+func (c *limitedCounter) Add(delta float64) {
+	c.counter.With(c.limiter.limit(c.labelValues)...).Add(delta)
+}
+
+// Delete deletes the series of the label values, unless they are collapsed, the series gathering other combinations.
+func (c *limitedCounter) Delete() {
+	if !c.limiter.forget(c.labelValues) {
+		return
+	}
+
+	if d, ok := c.counter.With(c.labelValues...).(Deleter); ok {
+		d.Delete()
+	}
+}
+
+type limitedGauge struct {
+	gauge       metrics.Gauge
+	limiter     *cardinalityLimiter
+	labelValues []string
+}
+
+func (g *limitedGauge) With(labelValues ...string) metrics.Gauge {
+	return &limitedGauge{gauge: g.gauge, limiter: g.limiter, labelValues: appendLabelValues(g.labelValues, labelValues)}
+}
+
+func (g *limitedGauge) Set(value float64) {
+	g.gauge.With(g.limiter.limit(g.labelValues)...).Set(value)
+}
+
+func (g *limitedGauge) Add(delta float64) {
+	g.gauge.With(g.limiter.limit(g.labelValues)...).Add(delta)
+}
+
+// Delete deletes the series of the label values, unless they are collapsed, the series gathering other combinations.
+func (g *limitedGauge) Delete() {
+	if !g.limiter.forget(g.labelValues) {
+		return
+	}
+
+	if d, ok := g.gauge.With(g.labelValues...).(Deleter); ok {
+		d.Delete()
+	}
+}
+
+type limitedHistogram struct {
+	histogram   metrics.Histogram
+	limiter     *cardinalityLimiter
+	labelValues []string
+}
+
+func (h *limitedHistogram) With(labelValues ...string) metrics.Histogram {
+	return &limitedHistogram{histogram: h.histogram, limiter: h.limiter, labelValues: appendLabelValues(h.labelValues, labelValues)}
+}
+
+func (h *limitedHistogram) Observe(value float64) {
+	h.histogram.With(h.limiter.limit(h.labelValues)...).Observe(value)
+}
+
+type limitedScalableHistogram struct {
+	histogram   ScalableHistogram
+	limiter     *cardinalityLimiter
+	labelValues []string
+}
+
+func (h *limitedScalableHistogram) With(labelValues ...string) ScalableHistogram {
+	return &limitedScalableHistogram{histogram: h.histogram, limiter: h.limiter, labelValues: appendLabelValues(h.labelValues, labelValues)}
+}
+
+func (h *limitedScalableHistogram) Observe(value float64) {
+	h.histogram.With(h.limiter.limit(h.labelValues)...).Observe(value)
+}
+
+func (h *limitedScalableHistogram) ObserveFromStart(start time.Time) {
+	h.histogram.With(h.limiter.limit(h.labelValues)...).ObserveFromStart(start)
+}
+
+func (h *limitedScalableHistogram) ObserveFromStartWithExemplar(start time.Time, exemplar map[string]string) {
+	h.histogram.With(h.limiter.limit(h.labelValues)...).ObserveFromStartWithExemplar(start, exemplar)
+}
+
+func appendLabelValues(labelValues, more []string) []string {
+	result := make([]string, 0, len(labelValues)+len(more))
+	result = append(result, labelValues...)
+	return append(result, more...)
+}
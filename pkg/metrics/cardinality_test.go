@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingCounter is a metrics.Counter recording the label values of each increment.
+type recordingCounter struct {
+	labelValues []string
+	records     *[]string
+}
+
+func newRecordingCounter() *recordingCounter {
+	return &recordingCounter{records: &[]string{}}
+}
+
+func (c *recordingCounter) With(labelValues ...string) metrics.Counter {
+	return &recordingCounter{labelValues: append(append([]string{}, c.labelValues...), labelValues...), records: c.records}
+}
+
+func (c *recordingCounter) Add(_ float64) {
+	*c.records = append(*c.records, strings.Join(c.labelValues, ","))
+}
+
+func (c *recordingCounter) Delete() {
+	*c.records = append(*c.records, "delete "+strings.Join(c.labelValues, ","))
+}
+
+func TestLimitCardinality(t *testing.T) {
+	serviceReqs := newRecordingCounter()
+	overflows := newRecordingCounter()
+
+	reg := &standardRegistry{serviceReqsCounter: serviceReqs}
+	limitCardinality(reg, 2, overflows)
+
+	for _, service := range []string{"foo", "bar", "baz", "foo", "qux"} {
+		reg.ServiceReqsCounter().With("service", service).With("code", "200").Add(1)
+	}
+
+	expected := []string{
+		"service,foo,code,200",
+		"service,bar,code,200",
+		"service,other,code,other",
+		"service,foo,code,200",
+		"service,other,code,other",
+	}
+	assert.Equal(t, expected, *serviceReqs.records)
+
+	assert.Equal(t, []string{"metric," + serviceReqsTotalName, "metric," + serviceReqsTotalName}, *overflows.records)
+}
+
+func TestLimitCardinality_delete(t *testing.T) {
+	rateLimitRequests := newRecordingCounter()
+
+	reg := &standardRegistry{middlewareRateLimitRequestsCounter: rateLimitRequests}
+	limitCardinality(reg, 1, nil)
+
+	reg.MiddlewareRateLimitRequestsCounter().With("source", "foo").Add(1)
+	reg.MiddlewareRateLimitRequestsCounter().With("source", "bar").Add(1)
+
+	// The collapsed combinations are not deleted, their series gathering the other ones.
+	reg.MiddlewareRateLimitRequestsCounter().With("source", "bar").(Deleter).Delete()
+
+	// The deleted combinations make room for the new ones.
+	reg.MiddlewareRateLimitRequestsCounter().With("source", "foo").(Deleter).Delete()
+	reg.MiddlewareRateLimitRequestsCounter().With("source", "bar").Add(1)
+
+	expected := []string{
+		"source,foo",
+		"source,other",
+		"delete source,foo",
+		"source,bar",
+	}
+	assert.Equal(t, expected, *rateLimitRequests.records)
+}
+
+func TestLimitCardinality_noLimit(t *testing.T) {
+	serviceReqs := newRecordingCounter()
+
+	reg := &standardRegistry{serviceReqsCounter: serviceReqs}
+	limitCardinality(reg, 0, nil)
+
+	assert.Same(t, serviceReqs, reg.ServiceReqsCounter())
+}
+
+func TestLimitCardinality_prune(t *testing.T) {
+	serviceReqs := newRecordingCounter()
+
+	reg := &standardRegistry{serviceReqsCounter: serviceReqs}
+	limitCardinality(reg, 2, nil)
+
+	config := newDynamicConfig()
+	config.services["foo"] = map[string]bool{}
+	config.services["bar"] = map[string]bool{}
+
+	for _, service := range []string{"foo", "bar", "baz"} {
+		reg.ServiceReqsCounter().With("service", service).Add(1)
+	}
+
+	// The removed service leaves its place to a new one.
+	delete(config.services, "bar")
+	config.services["baz"] = map[string]bool{}
+	cardinalityLimiters.prune(config)
+
+	for _, service := range []string{"baz", "foo"} {
+		reg.ServiceReqsCounter().With("service", service).Add(1)
+	}
+
+	expected := []string{
+		"service,foo",
+		"service,bar",
+		"service,other",
+		"service,baz",
+		"service,foo",
+	}
+	assert.Equal(t, expected, *serviceReqs.records)
+}
+
+func TestDynamicConfig_isOutdated(t *testing.T) {
+	config := newDynamicConfig()
+	config.entryPoints["web"] = true
+	config.services["foo"] = map[string]bool{"http://localhost": true}
+
+	testCases := []struct {
+		desc     string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			desc:   "defined service",
+			labels: map[string]string{"entrypoint": "web", "service": "foo", "url": "http://localhost"},
+		},
+		{
+			desc:     "removed service",
+			labels:   map[string]string{"service": "bar"},
+			expected: true,
+		},
+		{
+			desc:     "removed server",
+			labels:   map[string]string{"service": "foo", "url": "http://127.0.0.1"},
+			expected: true,
+		},
+		{
+			desc:   "labels collapsed by the cardinality limit",
+			labels: map[string]string{"entrypoint": "other", "router": "other", "service": "other", "url": "other", "code": "other"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, config.isOutdated(test.labels))
+		})
+	}
+}
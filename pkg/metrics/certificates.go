@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"crypto/x509"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/rules"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-kit/kit/metrics"
+)
+
+// tlsRouter holds what tells which certificates a TLS router serves, and how.
+type tlsRouter struct {
+	entryPoints []string
+	resolver    string
+	domains     []string
+}
+
+// OnCertificatesUpdate records the expiration timestamps of the certificates in the gauge,
+// labeled by the entry points and the certificate resolvers of the TLS routers serving them.
+// The certificates not served by a router are recorded with empty entry point and resolver labels.
+func OnCertificatesUpdate(gauge metrics.Gauge, conf dynamic.Configuration, certificates []*x509.Certificate) {
+	routers := tlsRouters(conf)
+
+	for _, certificate := range certificates {
+		sans := certificateSANs(certificate)
+		notAfter := float64(certificate.NotAfter.Unix())
+
+		var served bool
+		for _, router := range routers {
+			if !router.serves(sans) {
+				continue
+			}
+
+			for _, entryPoint := range router.entryPoints {
+				gauge.With("sans", strings.Join(sans, ","), "resolver", router.resolver, "entrypoint", entryPoint).Set(notAfter)
+				served = true
+			}
+		}
+
+		if !served {
+			gauge.With("sans", strings.Join(sans, ","), "resolver", "", "entrypoint", "").Set(notAfter)
+		}
+	}
+}
+
+func tlsRouters(conf dynamic.Configuration) []tlsRouter {
+	var routers []tlsRouter
+
+	if conf.HTTP != nil {
+		for _, router := range conf.HTTP.Routers {
+			if router.TLS == nil {
+				continue
+			}
+
+			domains, err := routerDomains(router.TLS.Domains, router.Rule, rules.ParseDomains)
+			if err != nil {
+				continue
+			}
+
+			routers = append(routers, tlsRouter{entryPoints: router.EntryPoints, resolver: router.TLS.CertResolver, domains: domains})
+		}
+	}
+
+	if conf.TCP != nil {
+		for _, router := range conf.TCP.Routers {
+			// The passthrough routers do not serve the certificates of Traefik.
+			if router.TLS == nil || router.TLS.Passthrough {
+				continue
+			}
+
+			domains, err := routerDomains(router.TLS.Domains, router.Rule, rules.ParseHostSNI)
+			if err != nil {
+				continue
+			}
+
+			routers = append(routers, tlsRouter{entryPoints: router.EntryPoints, resolver: router.TLS.CertResolver, domains: domains})
+		}
+	}
+
+	return routers
+}
+
+// routerDomains returns the domains of the TLS configuration of a router, or the ones of its rule.
+func routerDomains(tlsDomains []types.Domain, rule string, parse func(string) ([]string, error)) ([]string, error) {
+	if len(tlsDomains) == 0 {
+		return parse(rule)
+	}
+
+	var domains []string
+	for _, domain := range tlsDomains {
+		domains = append(domains, domain.ToStrArray()...)
+	}
+
+	return domains, nil
+}
+
+func (r tlsRouter) serves(sans []string) bool {
+	for _, domain := range r.domains {
+		for _, san := range sans {
+			if traefiktls.MatchDomain(strings.ToLower(domain), san) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// certificateSANs returns the sorted DNS names of the certificate, or its common name when it has none.
+func certificateSANs(certificate *x509.Certificate) []string {
+	var sans []string
+	for _, name := range certificate.DNSNames {
+		sans = append(sans, strings.ToLower(name))
+	}
+
+	if len(sans) == 0 && certificate.Subject.CommonName != "" {
+		sans = append(sans, strings.ToLower(certificate.Subject.CommonName))
+	}
+
+	sort.Strings(sans)
+
+	return sans
+}
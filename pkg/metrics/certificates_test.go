@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type collectingGauge struct {
+	values map[string]float64
+	labels string
+}
+
+func (g *collectingGauge) With(labelValues ...string) metrics.Gauge {
+	return &collectingGauge{values: g.values, labels: strings.Join(labelValues, " ")}
+}
+
+func (g *collectingGauge) Set(value float64) {
+	g.values[g.labels] = value
+}
+
+func (g *collectingGauge) Add(delta float64) {
+	g.values[g.labels] += delta
+}
+
+func TestOnCertificatesUpdate(t *testing.T) {
+	notAfter := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	expected := float64(notAfter.Unix())
+
+	certificates := []*x509.Certificate{
+		{DNSNames: []string{"www.example.com", "Example.com"}, NotAfter: notAfter},
+		{DNSNames: []string{"*.example.org"}, NotAfter: notAfter},
+		{Subject: pkix.Name{CommonName: "internal.example.net"}, NotAfter: notAfter},
+		{DNSNames: []string{"passthrough.example.com"}, NotAfter: notAfter},
+	}
+
+	conf := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"acme@file": {
+					EntryPoints: []string{"websecure"},
+					Rule:        "Host(`example.com`)",
+					TLS:         &dynamic.RouterTLSConfig{CertResolver: "le"},
+				},
+				"wildcard@file": {
+					EntryPoints: []string{"websecure", "admin"},
+					Rule:        "PathPrefix(`/`)",
+					TLS:         &dynamic.RouterTLSConfig{Domains: []types.Domain{{Main: "api.example.org"}}},
+				},
+				"plain@file": {
+					EntryPoints: []string{"web"},
+					Rule:        "Host(`example.com`)",
+				},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"passthrough@file": {
+					EntryPoints: []string{"tcp"},
+					Rule:        "HostSNI(`passthrough.example.com`)",
+					TLS:         &dynamic.RouterTCPTLSConfig{Passthrough: true},
+				},
+			},
+		},
+	}
+
+	gauge := &collectingGauge{values: make(map[string]float64)}
+	OnCertificatesUpdate(gauge, conf, certificates)
+
+	assert.Equal(t, map[string]float64{
+		"sans example.com,www.example.com resolver le entrypoint websecure": expected,
+		"sans *.example.org resolver  entrypoint websecure":                 expected,
+		"sans *.example.org resolver  entrypoint admin":                     expected,
+		"sans internal.example.net resolver  entrypoint ":                   expected,
+		"sans passthrough.example.com resolver  entrypoint ":                expected,
+	}, gauge.values)
+}
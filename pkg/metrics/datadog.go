@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/log"
@@ -9,12 +10,15 @@ import (
 	"github.com/containous/traefik/v2/pkg/types"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics/dogstatsd"
+	"github.com/go-kit/kit/util/conn"
 )
 
-var datadogClient = dogstatsd.New("traefik.", kitlog.LoggerFunc(func(keyvals ...interface{}) error {
+var datadogLogger = kitlog.LoggerFunc(func(keyvals ...interface{}) error {
 	log.WithoutContext().WithField(log.MetricsProviderName, "datadog").Info(keyvals)
 	return nil
-}))
+})
+
+var datadogClient = dogstatsd.New("traefik.", datadogLogger)
 
 var datadogTicker *time.Ticker
 
@@ -31,7 +35,25 @@ const (
 	ddEntryPointReqDurationName   = "entrypoint.request.duration"
 	ddEntryPointOpenConnsName     = "entrypoint.connections.open"
 	ddOpenConnsName               = "service.connections.open"
+	ddRouterReqsName              = "router.request.total"
+	ddRouterReqDurationName       = "router.request.duration"
+	ddRouterOpenConnsName         = "router.connections.open"
 	ddServerUpName                = "service.server.up"
+	ddHealthCheckDurationName     = "service.healthcheck.duration"
+
+	ddCircuitBreakerStateName   = "middleware.circuitbreaker.state"
+	ddCircuitBreakerTripsName   = "middleware.circuitbreaker.trips.total"
+	ddRateLimitRequestsName     = "middleware.ratelimit.request.total"
+	ddRateLimitBucketTokensName = "middleware.ratelimit.bucket.tokens"
+
+	ddMetricCardinalityOverflowsName = "metric.cardinality.overflows.total"
+)
+
+const (
+	ddUnixAddressPrefix = "unix://"
+	// ddMaxUDPPacketSize keeps the UDP packets under the usual MTU.
+	ddMaxUDPPacketSize = 1432
+	ddMaxUDSPacketSize = 8192
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -45,6 +67,9 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		configReloadsFailureCounter:  datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
 		lastConfigReloadSuccessGauge: datadogClient.NewGauge(ddLastConfigReloadSuccessName),
 		lastConfigReloadFailureGauge: datadogClient.NewGauge(ddLastConfigReloadFailureName),
+
+		middlewareCircuitBreakerStateGauge:   datadogClient.NewGauge(ddCircuitBreakerStateName),
+		middlewareCircuitBreakerTripsCounter: datadogClient.NewCounter(ddCircuitBreakerTripsName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -54,6 +79,13 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		registry.entryPointOpenConnsGauge = datadogClient.NewGauge(ddEntryPointOpenConnsName)
 	}
 
+	if config.AddRoutersLabels {
+		registry.routerEnabled = config.AddRoutersLabels
+		registry.routerReqsCounter = datadogClient.NewCounter(ddRouterReqsName, 1.0)
+		registry.routerReqDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddRouterReqDurationName, 1.0), time.Second)
+		registry.routerOpenConnsGauge = datadogClient.NewGauge(ddRouterOpenConnsName)
+	}
+
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = datadogClient.NewCounter(ddMetricsServiceReqsName, 1.0)
@@ -61,8 +93,25 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		registry.serviceRetriesCounter = datadogClient.NewCounter(ddRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = datadogClient.NewGauge(ddOpenConnsName)
 		registry.serviceServerUpGauge = datadogClient.NewGauge(ddServerUpName)
+		registry.serviceHealthCheckDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddHealthCheckDurationName, 1.0), time.Second)
+	}
+
+	if config.AddRateLimitMetrics {
+		registry.middlewareRateLimitRequestsCounter = datadogClient.NewCounter(ddRateLimitRequestsName, 1.0)
+
+		// The tokens are the ones of the bucket of each source.
+		if config.AddRateLimitSourcesLabels {
+			registry.middlewareRateLimitBucketTokensGauge = datadogClient.NewGauge(ddRateLimitBucketTokensName)
+		}
+	}
+
+	if config.MaxLabelCardinality > 0 {
+		limitCardinality(registry, config.MaxLabelCardinality, datadogClient.NewCounter(ddMetricCardinalityOverflowsName, 1.0))
 	}
 
+	reduceRequestLabels(registry, config.StatusCodeClasses, config.DropMethodLabel)
+	dropRateLimitSourceLabel(registry, !config.AddRateLimitSourcesLabels)
+
 	return registry
 }
 
@@ -72,10 +121,46 @@ func initDatadogClient(ctx context.Context, config *types.Datadog) *time.Ticker
 		address = "localhost:8125"
 	}
 
+	network := "udp"
+	maxPacketSize := ddMaxUDPPacketSize
+	if strings.HasPrefix(address, ddUnixAddressPrefix) {
+		network = "unixgram"
+		address = strings.TrimPrefix(address, ddUnixAddressPrefix)
+		maxPacketSize = ddMaxUDSPacketSize
+	}
+
+	if config.MaxPacketSize > 0 {
+		maxPacketSize = config.MaxPacketSize
+	}
+
+	var containerID string
+	if config.OriginDetection {
+		var err error
+		containerID, err = readContainerID(cgroupPath)
+		if err != nil {
+			log.FromContext(ctx).WithField(log.MetricsProviderName, "datadog").Errorf("Unable to read the container ID for the origin detection: %v", err)
+		}
+	}
+
 	report := time.NewTicker(time.Duration(config.PushInterval))
 
 	safe.Go(func() {
-		datadogClient.SendLoop(ctx, report.C, "udp", address)
+		manager := conn.NewDefaultManager(network, address, datadogLogger)
+		writer := newPacketWriter(manager, maxPacketSize, containerID)
+
+		for {
+			select {
+			case <-report.C:
+				if _, err := datadogClient.WriteTo(writer); err != nil {
+					log.FromContext(ctx).WithField(log.MetricsProviderName, "datadog").Debugf("Unable to send the metrics: %v", err)
+				}
+				if err := writer.Flush(); err != nil {
+					log.FromContext(ctx).WithField(log.MetricsProviderName, "datadog").Debugf("Unable to send the metrics: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
 	})
 
 	return report
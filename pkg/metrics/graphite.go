@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/types"
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/graphite"
+	"github.com/go-kit/kit/util/conn"
+)
+
+var (
+	graphiteClient *graphite.Graphite
+	graphiteTicker *time.Ticker
+)
+
+// Protocols of the Carbon receivers.
+const (
+	graphiteProtocolPlaintext = "plaintext"
+	graphiteProtocolPickle    = "pickle"
+)
+
+const (
+	graphiteServiceReqsName             = "service.request.total"
+	graphiteServiceReqDurationName      = "service.request.duration"
+	graphiteRetriesTotalName            = "service.retries.total"
+	graphiteConfigReloadsName           = "config.reload.total"
+	graphiteConfigReloadsFailureName    = graphiteConfigReloadsName + ".failure"
+	graphiteLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
+	graphiteLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
+	graphiteEntryPointReqsName          = "entrypoint.request.total"
+	graphiteEntryPointReqDurationName   = "entrypoint.request.duration"
+	graphiteEntryPointOpenConnsName     = "entrypoint.connections.open"
+	graphiteServiceOpenConnsName        = "service.connections.open"
+	graphiteRouterReqsName              = "router.request.total"
+	graphiteRouterReqDurationName       = "router.request.duration"
+	graphiteRouterOpenConnsName         = "router.connections.open"
+	graphiteServerUpName                = "service.server.up"
+	graphiteHealthCheckDurationName     = "service.healthcheck.duration"
+)
+
+// graphiteHistogramBuckets is the number of buckets of the histograms, whose quantiles are pushed.
+const graphiteHistogramBuckets = 50
+
+// RegisterGraphite registers the metrics pusher if this didn't happen yet and creates a Graphite Registry instance.
+// Graphite has no labels, the metrics are aggregated over all the entry points, routers and services.
+func RegisterGraphite(ctx context.Context, config *types.Graphite) Registry {
+	if config.Prefix == "" {
+		config.Prefix = "traefik"
+	}
+
+	graphiteClient = graphite.New(config.Prefix+".", graphiteLogger)
+
+	if graphiteTicker == nil {
+		graphiteTicker = initGraphiteTicker(ctx, config)
+	}
+
+	registry := &standardRegistry{
+		configReloadsCounter:         graphiteClient.NewCounter(graphiteConfigReloadsName),
+		configReloadsFailureCounter:  graphiteClient.NewCounter(graphiteConfigReloadsFailureName),
+		lastConfigReloadSuccessGauge: graphiteClient.NewGauge(graphiteLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge: graphiteClient.NewGauge(graphiteLastConfigReloadFailureName),
+	}
+
+	if config.AddEntryPointsLabels {
+		registry.epEnabled = config.AddEntryPointsLabels
+		registry.entryPointReqsCounter = graphiteClient.NewCounter(graphiteEntryPointReqsName)
+		registry.entryPointReqDurationHistogram, _ = NewHistogramWithScale(graphiteClient.NewHistogram(graphiteEntryPointReqDurationName, graphiteHistogramBuckets), time.Second)
+		registry.entryPointOpenConnsGauge = graphiteClient.NewGauge(graphiteEntryPointOpenConnsName)
+	}
+
+	if config.AddRoutersLabels {
+		registry.routerEnabled = config.AddRoutersLabels
+		registry.routerReqsCounter = graphiteClient.NewCounter(graphiteRouterReqsName)
+		registry.routerReqDurationHistogram, _ = NewHistogramWithScale(graphiteClient.NewHistogram(graphiteRouterReqDurationName, graphiteHistogramBuckets), time.Second)
+		registry.routerOpenConnsGauge = graphiteClient.NewGauge(graphiteRouterOpenConnsName)
+	}
+
+	if config.AddServicesLabels {
+		registry.svcEnabled = config.AddServicesLabels
+		registry.serviceReqsCounter = graphiteClient.NewCounter(graphiteServiceReqsName)
+		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(graphiteClient.NewHistogram(graphiteServiceReqDurationName, graphiteHistogramBuckets), time.Second)
+		registry.serviceRetriesCounter = graphiteClient.NewCounter(graphiteRetriesTotalName)
+		registry.serviceOpenConnsGauge = graphiteClient.NewGauge(graphiteServiceOpenConnsName)
+		registry.serviceServerUpGauge = graphiteClient.NewGauge(graphiteServerUpName)
+		registry.serviceHealthCheckDurationHistogram, _ = NewHistogramWithScale(graphiteClient.NewHistogram(graphiteHealthCheckDurationName, graphiteHistogramBuckets), time.Second)
+	}
+
+	return registry
+}
+
+var graphiteLogger = kitlog.LoggerFunc(func(keyvals ...interface{}) error {
+	log.WithoutContext().WithField(log.MetricsProviderName, "graphite").Info(keyvals)
+	return nil
+})
+
+// initGraphiteTicker initializes the metrics pusher, writing the metrics to the Carbon receiver on each tick.
+func initGraphiteTicker(ctx context.Context, config *types.Graphite) *time.Ticker {
+	address := config.Address
+	if len(address) == 0 {
+		address = "localhost:2003"
+	}
+
+	var pickle bool
+	switch config.Protocol {
+	case graphiteProtocolPickle:
+		pickle = true
+	case graphiteProtocolPlaintext, "":
+	default:
+		log.WithoutContext().WithField(log.MetricsProviderName, "graphite").
+			Warnf("Unsupported protocol %s: falling back on %s.", config.Protocol, graphiteProtocolPlaintext)
+	}
+
+	report := time.NewTicker(time.Duration(config.PushInterval))
+
+	w := conn.NewDefaultManager("tcp", address, graphiteLogger)
+	client := graphiteClient
+
+	safe.Go(func() {
+		for {
+			select {
+			case <-report.C:
+				if err := writeGraphite(client, w, pickle); err != nil {
+					graphiteLogger.Log("during", "write", "err", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return report
+}
+
+// writeGraphite flushes the metrics of the client to the writer at once,
+// in the plaintext protocol, or in the pickle protocol.
+func writeGraphite(client *graphite.Graphite, w io.Writer, pickle bool) error {
+	var buf bytes.Buffer
+	if _, err := client.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	if !pickle {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	payload, err := encodePickle(&buf)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(payload)
+	return err
+}
+
+// encodePickle encodes the plaintext lines (`path value timestamp`) as the message of the pickle protocol:
+// the length of the payload, as a 4-byte big-endian integer, followed by the payload,
+// a list of (path, (timestamp, value)) tuples pickled with the protocol 2.
+func encodePickle(lines io.Reader) ([]byte, error) {
+	var payload bytes.Buffer
+	// PROTO 2, EMPTY_LIST, MARK.
+	payload.WriteString("\x80\x02](")
+
+	scanner := bufio.NewScanner(lines)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid Graphite line: %q", scanner.Text())
+		}
+
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Graphite value: %q", scanner.Text())
+		}
+
+		timestamp, err := strconv.ParseInt(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Graphite timestamp: %q", scanner.Text())
+		}
+
+		// BINUNICODE path.
+		payload.WriteByte('X')
+		_ = binary.Write(&payload, binary.LittleEndian, uint32(len(fields[0])))
+		payload.WriteString(fields[0])
+
+		// BININT timestamp, BINFLOAT value, TUPLE2, TUPLE2.
+		payload.WriteByte('J')
+		_ = binary.Write(&payload, binary.LittleEndian, int32(timestamp))
+		payload.WriteByte('G')
+		_ = binary.Write(&payload, binary.BigEndian, math.Float64bits(value))
+		payload.WriteString("\x86\x86")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// APPENDS, STOP.
+	payload.WriteString("e.")
+
+	message := make([]byte, 4, 4+payload.Len())
+	binary.BigEndian.PutUint32(message, uint32(payload.Len()))
+
+	return append(message, payload.Bytes()...), nil
+}
+
+// StopGraphite stops the internal graphiteTicker which controls the pushing of metrics to Graphite, and resets it to `nil`.
+func StopGraphite() {
+	if graphiteTicker != nil {
+		graphiteTicker.Stop()
+	}
+	graphiteTicker = nil
+}
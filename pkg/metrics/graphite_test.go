@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestGraphite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	graphiteRegistry := RegisterGraphite(context.Background(), &types.Graphite{
+		Address:              listener.Addr().String(),
+		PushInterval:         ptypes.Duration(100 * time.Millisecond),
+		AddEntryPointsLabels: true,
+		AddRoutersLabels:     true,
+		AddServicesLabels:    true,
+	})
+	defer StopGraphite()
+
+	if !graphiteRegistry.IsEpEnabled() || !graphiteRegistry.IsRouterEnabled() || !graphiteRegistry.IsSvcEnabled() {
+		t.Errorf("Graphite registry should return true for IsEnabled()")
+	}
+
+	graphiteRegistry.ServiceReqsCounter().With("service", "test", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet).Add(1)
+	graphiteRegistry.ServiceReqsCounter().With("service", "test", "code", strconv.Itoa(http.StatusNotFound), "method", http.MethodGet).Add(1)
+	graphiteRegistry.ServiceRetriesCounter().With("service", "test").Add(1)
+	graphiteRegistry.ConfigReloadsCounter().Add(1)
+	graphiteRegistry.EntryPointReqsCounter().With("entrypoint", "test").Add(1)
+	graphiteRegistry.EntryPointOpenConnsGauge().With("entrypoint", "test").Set(1)
+	graphiteRegistry.RouterReqsCounter().With("router", "demo", "service", "test", "code", strconv.Itoa(http.StatusOK)).Add(1)
+	graphiteRegistry.ServiceServerUpGauge().With("service", "test", "url", "http://127.0.0.1").Set(1)
+
+	expected := map[string]string{
+		"traefik.service.request.total":       "2.000000",
+		"traefik.service.retries.total":       "1.000000",
+		"traefik.config.reload.total":         "1.000000",
+		"traefik.entrypoint.request.total":    "1.000000",
+		"traefik.entrypoint.connections.open": "1.000000",
+		"traefik.router.request.total":        "1.000000",
+		"traefik.service.server.up":           "1.000000",
+	}
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	received := make(map[string]string)
+	scanner := bufio.NewScanner(conn)
+	for len(received) < len(expected) && scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		require.Len(t, fields, 3)
+
+		if _, ok := expected[fields[0]]; ok {
+			received[fields[0]] = fields[1]
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, expected, received)
+}
+
+func TestEncodePickle(t *testing.T) {
+	message, err := encodePickle(strings.NewReader("traefik.foo 1.500000 1600000000\n"))
+	require.NoError(t, err)
+
+	payload := "\x80\x02](" +
+		"X\x0b\x00\x00\x00traefik.foo" +
+		"J\x00\x10\x5e\x5f" +
+		"G\x3f\xf8\x00\x00\x00\x00\x00\x00" +
+		"\x86\x86" +
+		"e."
+
+	require.Len(t, message, 4+len(payload))
+	assert.Equal(t, uint32(len(payload)), binary.BigEndian.Uint32(message[:4]))
+	assert.Equal(t, payload, string(message[4:]))
+
+	_, err = encodePickle(strings.NewReader("traefik.foo 1.5\n"))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -19,8 +20,9 @@ import (
 var influxDBClient *influx.Influx
 
 type influxDBWriter struct {
-	buf    bytes.Buffer
-	config *types.InfluxDB
+	buf       bytes.Buffer
+	config    *types.InfluxDB
+	tlsConfig *tls.Config
 }
 
 var influxDBTicker *time.Ticker
@@ -37,7 +39,13 @@ const (
 	influxDBEntryPointReqDurationName   = "traefik.entrypoint.request.duration"
 	influxDBEntryPointOpenConnsName     = "traefik.entrypoint.connections.open"
 	influxDBOpenConnsName               = "traefik.service.connections.open"
+	influxDBRouterReqsName              = "traefik.router.requests.total"
+	influxDBRouterReqDurationName       = "traefik.router.request.duration"
+	influxDBRouterOpenConnsName         = "traefik.router.connections.open"
 	influxDBServerUpName                = "traefik.service.server.up"
+	influxDBHealthCheckDurationName     = "traefik.service.healthcheck.duration"
+
+	influxDBMetricCardinalityOverflowsName = "traefik.metric.cardinality.overflows.total"
 )
 
 const (
@@ -68,6 +76,13 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 		registry.entryPointOpenConnsGauge = influxDBClient.NewGauge(influxDBEntryPointOpenConnsName)
 	}
 
+	if config.AddRoutersLabels {
+		registry.routerEnabled = config.AddRoutersLabels
+		registry.routerReqsCounter = influxDBClient.NewCounter(influxDBRouterReqsName)
+		registry.routerReqDurationHistogram, _ = NewHistogramWithScale(influxDBClient.NewHistogram(influxDBRouterReqDurationName), time.Second)
+		registry.routerOpenConnsGauge = influxDBClient.NewGauge(influxDBRouterOpenConnsName)
+	}
+
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = influxDBClient.NewCounter(influxDBMetricsServiceReqsName)
@@ -75,8 +90,15 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 		registry.serviceRetriesCounter = influxDBClient.NewCounter(influxDBRetriesTotalName)
 		registry.serviceOpenConnsGauge = influxDBClient.NewGauge(influxDBOpenConnsName)
 		registry.serviceServerUpGauge = influxDBClient.NewGauge(influxDBServerUpName)
+		registry.serviceHealthCheckDurationHistogram, _ = NewHistogramWithScale(influxDBClient.NewHistogram(influxDBHealthCheckDurationName), time.Second)
 	}
 
+	if config.MaxLabelCardinality > 0 {
+		limitCardinality(registry, config.MaxLabelCardinality, influxDBClient.NewCounter(influxDBMetricCardinalityOverflowsName))
+	}
+
+	reduceRequestLabels(registry, config.StatusCodeClasses, config.DropMethodLabel)
+
 	return registry
 }
 
@@ -92,6 +114,11 @@ func initInfluxDBClient(ctx context.Context, config *types.InfluxDB) *influx.Inf
 			config.Database = ""
 			config.RetentionPolicy = ""
 		}
+		if len(config.Bucket) > 0 || config.TLS != nil {
+			logger.Warn("Bucket and TLS options have no effect with UDP: the protocol must be http to write to InfluxDB 2.x.")
+			config.Bucket = ""
+			config.TLS = nil
+		}
 	case protocolHTTP:
 		if u, err := url.Parse(config.Address); err == nil {
 			if u.Scheme != "http" && u.Scheme != "https" {
@@ -127,9 +154,24 @@ func initInfluxDBClient(ctx context.Context, config *types.InfluxDB) *influx.Inf
 func initInfluxDBTicker(ctx context.Context, config *types.InfluxDB) *time.Ticker {
 	report := time.NewTicker(time.Duration(config.PushInterval))
 
+	var tlsConfig *tls.Config
+	if config.Protocol == protocolHTTP && config.TLS != nil {
+		var err error
+		tlsConfig, err = config.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			log.FromContext(ctx).Errorf("Unable to create the InfluxDB TLS configuration: %v", err)
+		}
+	}
+
+	var writer influx.BatchPointsWriter
+	if config.Protocol == protocolHTTP && config.Bucket != "" {
+		writer = newInfluxDBv2Writer(config, tlsConfig)
+	} else {
+		writer = &influxDBWriter{config: config, tlsConfig: tlsConfig}
+	}
+
 	safe.Go(func() {
-		var buf bytes.Buffer
-		influxDBClient.WriteLoop(ctx, report.C, &influxDBWriter{buf: buf, config: config})
+		influxDBClient.WriteLoop(ctx, report.C, writer)
 	})
 
 	return report
@@ -170,9 +212,10 @@ func (w *influxDBWriter) Write(bp influxdb.BatchPoints) error {
 func (w *influxDBWriter) initWriteClient() (influxdb.Client, error) {
 	if w.config.Protocol == "http" {
 		return influxdb.NewHTTPClient(influxdb.HTTPConfig{
-			Addr:     w.config.Address,
-			Username: w.config.Username,
-			Password: w.config.Password,
+			Addr:      w.config.Address,
+			Username:  w.config.Username,
+			Password:  w.config.Password,
+			TLSConfig: w.tlsConfig,
 		})
 	}
 
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -11,7 +12,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/containous/traefik/v2/pkg/types"
+	influxdb "github.com/influxdata/influxdb1-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stvp/go-udp-testing"
 	ptypes "github.com/traefik/paerser/types"
 )
@@ -21,10 +26,10 @@ func TestInfluxDB(t *testing.T) {
 	// This is needed to make sure that UDP Listener listens for data a bit longer, otherwise it will quit after a millisecond
 	udp.Timeout = 5 * time.Second
 
-	influxDBRegistry := RegisterInfluxDB(context.Background(), &types.InfluxDB{Address: ":8089", PushInterval: ptypes.Duration(time.Second), AddEntryPointsLabels: true, AddServicesLabels: true})
+	influxDBRegistry := RegisterInfluxDB(context.Background(), &types.InfluxDB{Address: ":8089", PushInterval: ptypes.Duration(time.Second), AddEntryPointsLabels: true, AddRoutersLabels: true, AddServicesLabels: true})
 	defer StopInfluxDB()
 
-	if !influxDBRegistry.IsEpEnabled() || !influxDBRegistry.IsSvcEnabled() {
+	if !influxDBRegistry.IsEpEnabled() || !influxDBRegistry.IsRouterEnabled() || !influxDBRegistry.IsSvcEnabled() {
 		t.Fatalf("InfluxDB registry must be epEnabled")
 	}
 
@@ -64,6 +69,20 @@ func TestInfluxDB(t *testing.T) {
 	})
 
 	assertMessage(t, msgEntrypoint, expectedEntrypoint)
+
+	expectedRouter := []string{
+		`(traefik\.router\.requests\.total,code=200,router=demo,service=test count=1) [\d]{19}`,
+		`(traefik\.router\.request\.duration,code=200,router=demo,service=test p50=10000,p90=10000,p95=10000,p99=10000) [\d]{19}`,
+		`(traefik\.router\.connections\.open,router=demo,service=test value=1) [\d]{19}`,
+	}
+
+	msgRouter := udp.ReceiveString(t, func() {
+		influxDBRegistry.RouterReqsCounter().With("router", "demo", "service", "test", "code", strconv.Itoa(http.StatusOK)).Add(1)
+		influxDBRegistry.RouterReqDurationHistogram().With("router", "demo", "service", "test", "code", strconv.Itoa(http.StatusOK)).Observe(10000)
+		influxDBRegistry.RouterOpenConnsGauge().With("router", "demo", "service", "test").Set(1)
+	})
+
+	assertMessage(t, msgRouter, expectedRouter)
 }
 
 func TestInfluxDBHTTP(t *testing.T) {
@@ -133,3 +152,122 @@ func assertMessage(t *testing.T, msg string, patterns []string) {
 		}
 	}
 }
+
+func TestInfluxDBv2Writer(t *testing.T) {
+	var attempts int
+	var batches []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.Equal(t, "/api/v2/write", r.URL.Path)
+		assert.Equal(t, "traefik", r.URL.Query().Get("bucket"))
+		assert.Equal(t, "acme", r.URL.Query().Get("org"))
+		assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+
+		body, err := ioutil.ReadAll(gz)
+		require.NoError(t, err)
+
+		batches = append(batches, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	writer := newInfluxDBv2Writer(&types.InfluxDB{
+		Address:      ts.URL,
+		Protocol:     protocolHTTP,
+		Token:        "secret",
+		Organization: "acme",
+		Bucket:       "traefik",
+		Gzip:         true,
+		BatchSize:    2,
+		MaxRetries:   1,
+	}, nil)
+	writer.backoff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+
+	bp, err := influxdb.NewBatchPoints(influxdb.BatchPointsConfig{})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		point, err := influxdb.NewPoint("traefik.test", map[string]string{"id": strconv.Itoa(i)}, map[string]interface{}{"count": 1}, time.Unix(0, 42))
+		require.NoError(t, err)
+		bp.AddPoint(point)
+	}
+
+	require.NoError(t, writer.Write(bp))
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []string{
+		"traefik.test,id=0 count=1i 42\ntraefik.test,id=1 count=1i 42\n",
+		"traefik.test,id=2 count=1i 42\n",
+	}, batches)
+}
+
+func TestInfluxDBv2WriterPermanentError(t *testing.T) {
+	var attempts int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "unauthorized access", http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	writer := newInfluxDBv2Writer(&types.InfluxDB{
+		Address:    ts.URL,
+		Protocol:   protocolHTTP,
+		Bucket:     "traefik",
+		MaxRetries: 3,
+	}, nil)
+	writer.backoff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+
+	bp, err := influxdb.NewBatchPoints(influxdb.BatchPointsConfig{})
+	require.NoError(t, err)
+
+	point, err := influxdb.NewPoint("traefik.test", nil, map[string]interface{}{"count": 1}, time.Unix(0, 42))
+	require.NoError(t, err)
+	bp.AddPoint(point)
+
+	assert.Error(t, writer.Write(bp))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestInfluxDBv2WriterTLS(t *testing.T) {
+	var attempts int
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	bp, err := influxdb.NewBatchPoints(influxdb.BatchPointsConfig{})
+	require.NoError(t, err)
+
+	point, err := influxdb.NewPoint("traefik.test", nil, map[string]interface{}{"count": 1}, time.Unix(0, 42))
+	require.NoError(t, err)
+	bp.AddPoint(point)
+
+	config := &types.InfluxDB{
+		Address:  ts.URL,
+		Protocol: protocolHTTP,
+		Bucket:   "traefik",
+		TLS:      &types.ClientTLS{InsecureSkipVerify: true},
+	}
+
+	// The self-signed certificate of the server is not trusted by default.
+	assert.Error(t, newInfluxDBv2Writer(config, nil).Write(bp))
+
+	tlsConfig, err := config.TLS.CreateTLSConfig(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, newInfluxDBv2Writer(config, tlsConfig).Write(bp))
+	assert.Equal(t, 1, attempts)
+}
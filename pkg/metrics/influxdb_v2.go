@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+	influxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// influxDBv2Writer writes the points with the InfluxDB v2 write API,
+// which is also supported by InfluxDB Cloud and InfluxDB 3.
+type influxDBv2Writer struct {
+	config  *types.InfluxDB
+	client  *http.Client
+	backoff func() backoff.BackOff
+}
+
+func newInfluxDBv2Writer(config *types.InfluxDB, tlsConfig *tls.Config) *influxDBv2Writer {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &influxDBv2Writer{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		backoff: func() backoff.BackOff {
+			return backoff.NewExponentialBackOff()
+		},
+	}
+}
+
+// Write writes the points in batches of at most BatchSize points.
+func (w *influxDBv2Writer) Write(bp influxdb.BatchPoints) error {
+	points := bp.Points()
+
+	batchSize := w.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(points)
+	}
+
+	for start := 0; start < len(points); start += batchSize {
+		end := start + batchSize
+		if end > len(points) {
+			end = len(points)
+		}
+
+		var lines bytes.Buffer
+		for _, point := range points[start:end] {
+			lines.WriteString(point.PrecisionString("ns"))
+			lines.WriteByte('\n')
+		}
+
+		if err := w.writeBatch(lines.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *influxDBv2Writer) writeBatch(lines []byte) error {
+	body := lines
+	if w.config.Gzip {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		if _, err := gz.Write(lines); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		body = compressed.Bytes()
+	}
+
+	ctx := log.With(context.Background(), log.Str(log.MetricsProviderName, "influxdb"))
+	logger := log.FromContext(ctx)
+
+	operation := func() error {
+		return w.send(body)
+	}
+
+	notify := func(err error, d time.Duration) {
+		logger.Debugf("Error while writing to InfluxDB, retrying in %s: %v", d, err)
+	}
+
+	err := backoff.RetryNotify(operation, backoff.WithMaxRetries(w.backoff(), uint64(w.config.MaxRetries)), notify)
+	if err != nil {
+		logger.Errorf("Error while writing to InfluxDB: %v", err)
+	}
+
+	return err
+}
+
+func (w *influxDBv2Writer) send(body []byte) error {
+	query := url.Values{}
+	query.Set("bucket", w.config.Bucket)
+	query.Set("precision", "ns")
+	if w.config.Organization != "" {
+		query.Set("org", w.config.Organization)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(w.config.Address, "/")+"/api/v2/write?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.config.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.config.Token)
+	}
+	if w.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return err
+	}
+
+	return backoff.Permanent(err)
+}
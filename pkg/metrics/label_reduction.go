@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// labelReducer rewrites the label values of the request metrics,
+// aggregating the status codes into classes, and dropping the method and source labels.
+type labelReducer struct {
+	statusCodeClasses bool
+	dropMethod        bool
+	dropSource        bool
+}
+
+// reduce returns the label values to record the metric with.
+func (r labelReducer) reduce(labelValues []string) []string {
+	reduced := make([]string, 0, len(labelValues))
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		name, value := labelValues[i], labelValues[i+1]
+
+		switch {
+		case name == "method" && r.dropMethod, name == "source" && r.dropSource:
+			continue
+		case name == "code" && r.statusCodeClasses:
+			value = statusCodeClass(value)
+		}
+
+		reduced = append(reduced, name, value)
+	}
+
+	return reduced
+}
+
+// statusCodeClass returns the class of the status code, e.g. 4xx for 404, or the code itself when it is not a 3-digit one.
+func statusCodeClass(code string) string {
+	if len(code) != 3 || code[0] < '1' || code[0] > '5' {
+		return code
+	}
+
+	return code[:1] + "xx"
+}
+
+// reduceRequestLabels aggregates the code label of the request metrics of the registry into classes (2xx, 3xx, 4xx, 5xx),
+// and drops their method label, which are the main sources of series for large deployments.
+func reduceRequestLabels(reg *standardRegistry, statusCodeClasses, dropMethod bool) {
+	if !statusCodeClasses && !dropMethod {
+		return
+	}
+
+	reducer := labelReducer{statusCodeClasses: statusCodeClasses, dropMethod: dropMethod}
+
+	counter := func(c metrics.Counter) metrics.Counter {
+		if c == nil {
+			return nil
+		}
+		return &reducedCounter{counter: c, reducer: reducer}
+	}
+	gauge := func(g metrics.Gauge) metrics.Gauge {
+		if g == nil {
+			return nil
+		}
+		return &reducedGauge{gauge: g, reducer: reducer}
+	}
+	histogram := func(h metrics.Histogram) metrics.Histogram {
+		if h == nil {
+			return nil
+		}
+		return &reducedHistogram{histogram: h, reducer: reducer}
+	}
+	scalableHistogram := func(h ScalableHistogram) ScalableHistogram {
+		if h == nil {
+			return nil
+		}
+		return &reducedScalableHistogram{histogram: h, reducer: reducer}
+	}
+
+	reg.entryPointReqsCounter = counter(reg.entryPointReqsCounter)
+	reg.entryPointReqDurationHistogram = scalableHistogram(reg.entryPointReqDurationHistogram)
+
+	reg.routerReqsCounter = counter(reg.routerReqsCounter)
+	reg.routerReqDurationHistogram = scalableHistogram(reg.routerReqDurationHistogram)
+	reg.routerOpenConnsGauge = gauge(reg.routerOpenConnsGauge)
+
+	reg.serviceReqsCounter = counter(reg.serviceReqsCounter)
+	reg.serviceReqDurationHistogram = scalableHistogram(reg.serviceReqDurationHistogram)
+	reg.serviceOpenConnsGauge = gauge(reg.serviceOpenConnsGauge)
+	reg.serviceReqSizeHistogram = histogram(reg.serviceReqSizeHistogram)
+	reg.serviceRespSizeHistogram = histogram(reg.serviceRespSizeHistogram)
+
+	reg.middlewareShortCircuitsCounter = counter(reg.middlewareShortCircuitsCounter)
+	reg.middlewareErrorsCounter = counter(reg.middlewareErrorsCounter)
+}
+
+// dropRateLimitSourceLabel drops the source label of the rate limit metrics of the registry,
+// which otherwise creates series for each client.
+func dropRateLimitSourceLabel(reg *standardRegistry, dropSource bool) {
+	if !dropSource || reg.middlewareRateLimitRequestsCounter == nil {
+		return
+	}
+
+	reg.middlewareRateLimitRequestsCounter = &reducedCounter{counter: reg.middlewareRateLimitRequestsCounter, reducer: labelReducer{dropSource: true}}
+}
+
+// withoutMethodLabel returns the label names, without the method one when it is dropped.
+func withoutMethodLabel(dropMethod bool, labelNames []string) []string {
+	return withoutLabel(dropMethod, "method", labelNames)
+}
+
+// withoutLabel returns the label names, without the given one when it is dropped.
+func withoutLabel(drop bool, label string, labelNames []string) []string {
+	if !drop {
+		return labelNames
+	}
+
+	var names []string
+	for _, name := range labelNames {
+		if name != label {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+type reducedCounter struct {
+	counter metrics.Counter
+	reducer labelReducer
+}
+
+func (c *reducedCounter) With(labelValues ...string) metrics.Counter {
+	return &reducedCounter{counter: c.counter.With(c.reducer.reduce(labelValues)...), reducer: c.reducer}
+}
+
+func (c *reducedCounter) Add(delta float64) {
+	c.counter.Add(delta)
+}
+
+type reducedGauge struct {
+	gauge   metrics.Gauge
+	reducer labelReducer
+}
+
+func (g *reducedGauge) With(labelValues ...string) metrics.Gauge {
+	return &reducedGauge{gauge: g.gauge.With(g.reducer.reduce(labelValues)...), reducer: g.reducer}
+}
+
+func (g *reducedGauge) Set(value float64) {
+	g.gauge.Set(value)
+}
+
+func (g *reducedGauge) Add(delta float64) {
+	g.gauge.Add(delta)
+}
+
+type reducedHistogram struct {
+	histogram metrics.Histogram
+	reducer   labelReducer
+}
+
+func (h *reducedHistogram) With(labelValues ...string) metrics.Histogram {
+	return &reducedHistogram{histogram: h.histogram.With(h.reducer.reduce(labelValues)...), reducer: h.reducer}
+}
+
+func (h *reducedHistogram) Observe(value float64) {
+	h.histogram.Observe(value)
+}
+
+type reducedScalableHistogram struct {
+	histogram ScalableHistogram
+	reducer   labelReducer
+}
+
+func (h *reducedScalableHistogram) With(labelValues ...string) ScalableHistogram {
+	return &reducedScalableHistogram{histogram: h.histogram.With(h.reducer.reduce(labelValues)...), reducer: h.reducer}
+}
+
+func (h *reducedScalableHistogram) Observe(value float64) {
+	h.histogram.Observe(value)
+}
+
+func (h *reducedScalableHistogram) ObserveFromStart(start time.Time) {
+	h.histogram.ObserveFromStart(start)
+}
+
+func (h *reducedScalableHistogram) ObserveFromStartWithExemplar(start time.Time, exemplar map[string]string) {
+	h.histogram.ObserveFromStartWithExemplar(start, exemplar)
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReduceRequestLabels(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		statusCodeClasses bool
+		dropMethod        bool
+		expected          []string
+	}{
+		{
+			desc:              "status code classes",
+			statusCodeClasses: true,
+			expected: []string{
+				"service,foo,code,2xx,method,GET",
+				"service,foo,code,4xx,method,POST",
+				"service,foo,code,other,method,GET",
+			},
+		},
+		{
+			desc:       "drop method",
+			dropMethod: true,
+			expected: []string{
+				"service,foo,code,200",
+				"service,foo,code,404",
+				"service,foo,code,other",
+			},
+		},
+		{
+			desc:              "status code classes and drop method",
+			statusCodeClasses: true,
+			dropMethod:        true,
+			expected: []string{
+				"service,foo,code,2xx",
+				"service,foo,code,4xx",
+				"service,foo,code,other",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			serviceReqs := newRecordingCounter()
+
+			reg := &standardRegistry{serviceReqsCounter: serviceReqs}
+			reduceRequestLabels(reg, test.statusCodeClasses, test.dropMethod)
+
+			reg.ServiceReqsCounter().With("service", "foo").With("code", "200", "method", "GET").Add(1)
+			reg.ServiceReqsCounter().With("service", "foo", "code", "404", "method", "POST").Add(1)
+			reg.ServiceReqsCounter().With("service", "foo", "code", "other", "method", "GET").Add(1)
+
+			assert.Equal(t, test.expected, *serviceReqs.records)
+		})
+	}
+}
+
+func TestReduceRequestLabels_withCardinalityLimit(t *testing.T) {
+	serviceReqs := newRecordingCounter()
+
+	reg := &standardRegistry{serviceReqsCounter: serviceReqs}
+	limitCardinality(reg, 1, nil)
+	reduceRequestLabels(reg, true, true)
+
+	// The limit applies to the reduced label combinations.
+	reg.ServiceReqsCounter().With("service", "foo", "code", "200", "method", "GET").Add(1)
+	reg.ServiceReqsCounter().With("service", "foo", "code", "201", "method", "POST").Add(1)
+
+	assert.Equal(t, []string{"service,foo,code,2xx", "service,foo,code,2xx"}, *serviceReqs.records)
+}
+
+func TestReduceRequestLabels_disabled(t *testing.T) {
+	serviceReqs := newRecordingCounter()
+
+	reg := &standardRegistry{serviceReqsCounter: serviceReqs}
+	reduceRequestLabels(reg, false, false)
+
+	assert.Same(t, serviceReqs, reg.ServiceReqsCounter())
+}
+
+func TestDropRateLimitSourceLabel(t *testing.T) {
+	rateLimitRequests := newRecordingCounter()
+
+	reg := &standardRegistry{middlewareRateLimitRequestsCounter: rateLimitRequests}
+	dropRateLimitSourceLabel(reg, true)
+
+	requests := reg.MiddlewareRateLimitRequestsCounter().With("middleware", "foo@file", "source", "10.0.0.1", "result", "allowed")
+	requests.Add(1)
+
+	// The series gathers all the sources, it is not deleted with one of them.
+	_, ok := requests.(Deleter)
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"middleware,foo@file,result,allowed"}, *rateLimitRequests.records)
+}
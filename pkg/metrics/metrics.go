@@ -12,20 +12,53 @@ import (
 type Registry interface {
 	// IsEpEnabled shows whether metrics instrumentation is enabled on entry points.
 	IsEpEnabled() bool
+	// IsRouterEnabled shows whether metrics instrumentation is enabled on routers.
+	IsRouterEnabled() bool
 	// IsSvcEnabled shows whether metrics instrumentation is enabled on services.
 	IsSvcEnabled() bool
+	// IsMiddlewareEnabled shows whether metrics instrumentation is enabled on middlewares.
+	IsMiddlewareEnabled() bool
+	// IsSvcTCPInfoEnabled shows whether the TCP statistics of the connections to the servers are collected on services.
+	IsSvcTCPInfoEnabled() bool
+	// RequestLabels returns the extra labels of the request metrics of the entry points, routers and services.
+	RequestLabels() []*RequestLabel
 
 	// server metrics
 	ConfigReloadsCounter() metrics.Counter
 	ConfigReloadsFailureCounter() metrics.Counter
 	LastConfigReloadSuccessGauge() metrics.Gauge
 	LastConfigReloadFailureGauge() metrics.Gauge
+	ConfigDeprecationsGauge() metrics.Gauge
+
+	// provider metrics
+	ProviderConfigReloadsCounter() metrics.Counter
+	ProviderConfigReloadsFailureCounter() metrics.Counter
+	ProviderLastConfigReloadSuccessGauge() metrics.Gauge
+	ProviderLastConfigReloadFailureGauge() metrics.Gauge
+	ProviderRoutersGauge() metrics.Gauge
+	ProviderServicesGauge() metrics.Gauge
+	ProviderConfigStalenessGauge() metrics.Gauge
+
+	// TLS metrics
+	TLSCertsNotAfterTimestampGauge() metrics.Gauge
 
 	// entry point metrics
 	EntryPointReqsCounter() metrics.Counter
 	EntryPointReqsTLSCounter() metrics.Counter
 	EntryPointReqDurationHistogram() ScalableHistogram
 	EntryPointOpenConnsGauge() metrics.Gauge
+	EntryPointHTTP2AbusesCounter() metrics.Counter
+	EntryPointTCPConnsCounter() metrics.Counter
+	EntryPointTCPOpenConnsGauge() metrics.Gauge
+	EntryPointTCPBytesCounter() metrics.Counter
+	EntryPointUDPDatagramsCounter() metrics.Counter
+	EntryPointUDPBytesCounter() metrics.Counter
+
+	// router metrics
+	RouterReqsCounter() metrics.Counter
+	RouterReqsTLSCounter() metrics.Counter
+	RouterReqDurationHistogram() ScalableHistogram
+	RouterOpenConnsGauge() metrics.Gauge
 
 	// service metrics
 	ServiceReqsCounter() metrics.Counter
@@ -33,7 +66,33 @@ type Registry interface {
 	ServiceReqDurationHistogram() ScalableHistogram
 	ServiceOpenConnsGauge() metrics.Gauge
 	ServiceRetriesCounter() metrics.Counter
+	ServiceRetryDurationHistogram() ScalableHistogram
 	ServiceServerUpGauge() metrics.Gauge
+	ServiceHealthCheckDurationHistogram() ScalableHistogram
+	ServiceReqSizeHistogram() metrics.Histogram
+	ServiceRespSizeHistogram() metrics.Histogram
+	ServiceTCPRTTHistogram() ScalableHistogram
+	ServiceTCPRetransmitsCounter() metrics.Counter
+	ServiceTCPDeliveryRateHistogram() metrics.Histogram
+
+	RouterHTTPVersionRejectionsCounter() metrics.Counter
+
+	// middleware metrics
+	MiddlewareOPADecisionsCounter() metrics.Counter
+	MiddlewareCircuitBreakerStateGauge() metrics.Gauge
+	MiddlewareCircuitBreakerTripsCounter() metrics.Counter
+	MiddlewareRateLimitRequestsCounter() metrics.Counter
+	MiddlewareRateLimitBucketTokensGauge() metrics.Gauge
+	MiddlewareReqsCounter() metrics.Counter
+	MiddlewareReqDurationHistogram() ScalableHistogram
+	MiddlewareShortCircuitsCounter() metrics.Counter
+	MiddlewareErrorsCounter() metrics.Counter
+
+	// ACME metrics
+	ACMECertsObtainedCounter() metrics.Counter
+	ACMECertsRenewedCounter() metrics.Counter
+	ACMEChallengeFailuresCounter() metrics.Counter
+	ACMECertIssueDurationHistogram() ScalableHistogram
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -50,18 +109,62 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var configReloadsFailureCounter []metrics.Counter
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var lastConfigReloadFailureGauge []metrics.Gauge
+	var configDeprecationsGauge []metrics.Gauge
+	var tlsCertsNotAfterTimestampGauge []metrics.Gauge
+	var providerConfigReloadsCounter []metrics.Counter
+	var providerConfigReloadsFailureCounter []metrics.Counter
+	var providerLastConfigReloadSuccessGauge []metrics.Gauge
+	var providerLastConfigReloadFailureGauge []metrics.Gauge
+	var providerRoutersGauge []metrics.Gauge
+	var providerServicesGauge []metrics.Gauge
+	var providerConfigStalenessGauge []metrics.Gauge
+	var requestLabels []*RequestLabel
 	var entryPointReqsCounter []metrics.Counter
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
 	var entryPointOpenConnsGauge []metrics.Gauge
+	var entryPointHTTP2AbusesCounter []metrics.Counter
+	var entryPointTCPConnsCounter []metrics.Counter
+	var entryPointTCPOpenConnsGauge []metrics.Gauge
+	var entryPointTCPBytesCounter []metrics.Counter
+	var entryPointUDPDatagramsCounter []metrics.Counter
+	var entryPointUDPBytesCounter []metrics.Counter
+	var routerReqsCounter []metrics.Counter
+	var routerReqsTLSCounter []metrics.Counter
+	var routerReqDurationHistogram []ScalableHistogram
+	var routerOpenConnsGauge []metrics.Gauge
 	var serviceReqsCounter []metrics.Counter
 	var serviceReqsTLSCounter []metrics.Counter
 	var serviceReqDurationHistogram []ScalableHistogram
 	var serviceOpenConnsGauge []metrics.Gauge
 	var serviceRetriesCounter []metrics.Counter
+	var serviceRetryDurationHistogram []ScalableHistogram
 	var serviceServerUpGauge []metrics.Gauge
+	var serviceHealthCheckDurationHistogram []ScalableHistogram
+	var serviceReqSizeHistogram []metrics.Histogram
+	var serviceRespSizeHistogram []metrics.Histogram
+	var serviceTCPRTTHistogram []ScalableHistogram
+	var serviceTCPRetransmitsCounter []metrics.Counter
+	var serviceTCPDeliveryRateHistogram []metrics.Histogram
+	var routerHTTPVersionRejectionsCounter []metrics.Counter
+	var middlewareOPADecisionsCounter []metrics.Counter
+	var middlewareCircuitBreakerStateGauge []metrics.Gauge
+	var middlewareCircuitBreakerTripsCounter []metrics.Counter
+	var middlewareRateLimitRequestsCounter []metrics.Counter
+	var middlewareRateLimitBucketTokensGauge []metrics.Gauge
+	var middlewareReqsCounter []metrics.Counter
+	var middlewareReqDurationHistogram []ScalableHistogram
+	var middlewareShortCircuitsCounter []metrics.Counter
+	var middlewareErrorsCounter []metrics.Counter
+	var acmeCertsObtainedCounter []metrics.Counter
+	var acmeCertsRenewedCounter []metrics.Counter
+	var acmeChallengeFailuresCounter []metrics.Counter
+	var acmeCertIssueDurationHistogram []ScalableHistogram
 
 	for _, r := range registries {
+		if len(requestLabels) == 0 {
+			requestLabels = r.RequestLabels()
+		}
 		if r.ConfigReloadsCounter() != nil {
 			configReloadsCounter = append(configReloadsCounter, r.ConfigReloadsCounter())
 		}
@@ -74,6 +177,33 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.LastConfigReloadFailureGauge() != nil {
 			lastConfigReloadFailureGauge = append(lastConfigReloadFailureGauge, r.LastConfigReloadFailureGauge())
 		}
+		if r.ConfigDeprecationsGauge() != nil {
+			configDeprecationsGauge = append(configDeprecationsGauge, r.ConfigDeprecationsGauge())
+		}
+		if r.ProviderConfigReloadsCounter() != nil {
+			providerConfigReloadsCounter = append(providerConfigReloadsCounter, r.ProviderConfigReloadsCounter())
+		}
+		if r.ProviderConfigReloadsFailureCounter() != nil {
+			providerConfigReloadsFailureCounter = append(providerConfigReloadsFailureCounter, r.ProviderConfigReloadsFailureCounter())
+		}
+		if r.ProviderLastConfigReloadSuccessGauge() != nil {
+			providerLastConfigReloadSuccessGauge = append(providerLastConfigReloadSuccessGauge, r.ProviderLastConfigReloadSuccessGauge())
+		}
+		if r.ProviderLastConfigReloadFailureGauge() != nil {
+			providerLastConfigReloadFailureGauge = append(providerLastConfigReloadFailureGauge, r.ProviderLastConfigReloadFailureGauge())
+		}
+		if r.ProviderRoutersGauge() != nil {
+			providerRoutersGauge = append(providerRoutersGauge, r.ProviderRoutersGauge())
+		}
+		if r.ProviderServicesGauge() != nil {
+			providerServicesGauge = append(providerServicesGauge, r.ProviderServicesGauge())
+		}
+		if r.ProviderConfigStalenessGauge() != nil {
+			providerConfigStalenessGauge = append(providerConfigStalenessGauge, r.ProviderConfigStalenessGauge())
+		}
+		if r.TLSCertsNotAfterTimestampGauge() != nil {
+			tlsCertsNotAfterTimestampGauge = append(tlsCertsNotAfterTimestampGauge, r.TLSCertsNotAfterTimestampGauge())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -86,6 +216,36 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.EntryPointOpenConnsGauge() != nil {
 			entryPointOpenConnsGauge = append(entryPointOpenConnsGauge, r.EntryPointOpenConnsGauge())
 		}
+		if r.EntryPointHTTP2AbusesCounter() != nil {
+			entryPointHTTP2AbusesCounter = append(entryPointHTTP2AbusesCounter, r.EntryPointHTTP2AbusesCounter())
+		}
+		if r.EntryPointTCPConnsCounter() != nil {
+			entryPointTCPConnsCounter = append(entryPointTCPConnsCounter, r.EntryPointTCPConnsCounter())
+		}
+		if r.EntryPointTCPOpenConnsGauge() != nil {
+			entryPointTCPOpenConnsGauge = append(entryPointTCPOpenConnsGauge, r.EntryPointTCPOpenConnsGauge())
+		}
+		if r.EntryPointTCPBytesCounter() != nil {
+			entryPointTCPBytesCounter = append(entryPointTCPBytesCounter, r.EntryPointTCPBytesCounter())
+		}
+		if r.EntryPointUDPDatagramsCounter() != nil {
+			entryPointUDPDatagramsCounter = append(entryPointUDPDatagramsCounter, r.EntryPointUDPDatagramsCounter())
+		}
+		if r.EntryPointUDPBytesCounter() != nil {
+			entryPointUDPBytesCounter = append(entryPointUDPBytesCounter, r.EntryPointUDPBytesCounter())
+		}
+		if r.RouterReqsCounter() != nil {
+			routerReqsCounter = append(routerReqsCounter, r.RouterReqsCounter())
+		}
+		if r.RouterReqsTLSCounter() != nil {
+			routerReqsTLSCounter = append(routerReqsTLSCounter, r.RouterReqsTLSCounter())
+		}
+		if r.RouterReqDurationHistogram() != nil {
+			routerReqDurationHistogram = append(routerReqDurationHistogram, r.RouterReqDurationHistogram())
+		}
+		if r.RouterOpenConnsGauge() != nil {
+			routerOpenConnsGauge = append(routerOpenConnsGauge, r.RouterOpenConnsGauge())
+		}
 		if r.ServiceReqsCounter() != nil {
 			serviceReqsCounter = append(serviceReqsCounter, r.ServiceReqsCounter())
 		}
@@ -101,58 +261,225 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceRetriesCounter() != nil {
 			serviceRetriesCounter = append(serviceRetriesCounter, r.ServiceRetriesCounter())
 		}
+		if r.ServiceRetryDurationHistogram() != nil {
+			serviceRetryDurationHistogram = append(serviceRetryDurationHistogram, r.ServiceRetryDurationHistogram())
+		}
 		if r.ServiceServerUpGauge() != nil {
 			serviceServerUpGauge = append(serviceServerUpGauge, r.ServiceServerUpGauge())
 		}
+		if r.ServiceHealthCheckDurationHistogram() != nil {
+			serviceHealthCheckDurationHistogram = append(serviceHealthCheckDurationHistogram, r.ServiceHealthCheckDurationHistogram())
+		}
+		if r.ServiceReqSizeHistogram() != nil {
+			serviceReqSizeHistogram = append(serviceReqSizeHistogram, r.ServiceReqSizeHistogram())
+		}
+		if r.ServiceRespSizeHistogram() != nil {
+			serviceRespSizeHistogram = append(serviceRespSizeHistogram, r.ServiceRespSizeHistogram())
+		}
+		if r.ServiceTCPRTTHistogram() != nil {
+			serviceTCPRTTHistogram = append(serviceTCPRTTHistogram, r.ServiceTCPRTTHistogram())
+		}
+		if r.ServiceTCPRetransmitsCounter() != nil {
+			serviceTCPRetransmitsCounter = append(serviceTCPRetransmitsCounter, r.ServiceTCPRetransmitsCounter())
+		}
+		if r.ServiceTCPDeliveryRateHistogram() != nil {
+			serviceTCPDeliveryRateHistogram = append(serviceTCPDeliveryRateHistogram, r.ServiceTCPDeliveryRateHistogram())
+		}
+		if r.RouterHTTPVersionRejectionsCounter() != nil {
+			routerHTTPVersionRejectionsCounter = append(routerHTTPVersionRejectionsCounter, r.RouterHTTPVersionRejectionsCounter())
+		}
+		if r.MiddlewareOPADecisionsCounter() != nil {
+			middlewareOPADecisionsCounter = append(middlewareOPADecisionsCounter, r.MiddlewareOPADecisionsCounter())
+		}
+		if r.MiddlewareCircuitBreakerStateGauge() != nil {
+			middlewareCircuitBreakerStateGauge = append(middlewareCircuitBreakerStateGauge, r.MiddlewareCircuitBreakerStateGauge())
+		}
+		if r.MiddlewareCircuitBreakerTripsCounter() != nil {
+			middlewareCircuitBreakerTripsCounter = append(middlewareCircuitBreakerTripsCounter, r.MiddlewareCircuitBreakerTripsCounter())
+		}
+		if r.MiddlewareRateLimitRequestsCounter() != nil {
+			middlewareRateLimitRequestsCounter = append(middlewareRateLimitRequestsCounter, r.MiddlewareRateLimitRequestsCounter())
+		}
+		if r.MiddlewareRateLimitBucketTokensGauge() != nil {
+			middlewareRateLimitBucketTokensGauge = append(middlewareRateLimitBucketTokensGauge, r.MiddlewareRateLimitBucketTokensGauge())
+		}
+		if r.MiddlewareReqsCounter() != nil {
+			middlewareReqsCounter = append(middlewareReqsCounter, r.MiddlewareReqsCounter())
+		}
+		if r.MiddlewareReqDurationHistogram() != nil {
+			middlewareReqDurationHistogram = append(middlewareReqDurationHistogram, r.MiddlewareReqDurationHistogram())
+		}
+		if r.MiddlewareShortCircuitsCounter() != nil {
+			middlewareShortCircuitsCounter = append(middlewareShortCircuitsCounter, r.MiddlewareShortCircuitsCounter())
+		}
+		if r.MiddlewareErrorsCounter() != nil {
+			middlewareErrorsCounter = append(middlewareErrorsCounter, r.MiddlewareErrorsCounter())
+		}
+		if r.ACMECertsObtainedCounter() != nil {
+			acmeCertsObtainedCounter = append(acmeCertsObtainedCounter, r.ACMECertsObtainedCounter())
+		}
+		if r.ACMECertsRenewedCounter() != nil {
+			acmeCertsRenewedCounter = append(acmeCertsRenewedCounter, r.ACMECertsRenewedCounter())
+		}
+		if r.ACMEChallengeFailuresCounter() != nil {
+			acmeChallengeFailuresCounter = append(acmeChallengeFailuresCounter, r.ACMEChallengeFailuresCounter())
+		}
+		if r.ACMECertIssueDurationHistogram() != nil {
+			acmeCertIssueDurationHistogram = append(acmeCertIssueDurationHistogram, r.ACMECertIssueDurationHistogram())
+		}
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		entryPointReqsCounter:          multi.NewCounter(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: NewMultiHistogram(entryPointReqDurationHistogram...),
-		entryPointOpenConnsGauge:       multi.NewGauge(entryPointOpenConnsGauge...),
-		serviceReqsCounter:             multi.NewCounter(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    NewMultiHistogram(serviceReqDurationHistogram...),
-		serviceOpenConnsGauge:          multi.NewGauge(serviceOpenConnsGauge...),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
+		epEnabled:                            len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
+		routerEnabled:                        len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0 || len(routerOpenConnsGauge) > 0,
+		svcEnabled:                           len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		middlewareEnabled:                    len(middlewareReqsCounter) > 0 || len(middlewareReqDurationHistogram) > 0,
+		svcTCPInfoEnabled:                    len(serviceTCPRTTHistogram) > 0 || len(serviceTCPRetransmitsCounter) > 0 || len(serviceTCPDeliveryRateHistogram) > 0,
+		requestLabels:                        requestLabels,
+		configReloadsCounter:                 multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:          multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:         multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:         multi.NewGauge(lastConfigReloadFailureGauge...),
+		configDeprecationsGauge:              multi.NewGauge(configDeprecationsGauge...),
+		tlsCertsNotAfterTimestampGauge:       multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		providerConfigReloadsCounter:         multi.NewCounter(providerConfigReloadsCounter...),
+		providerConfigReloadsFailureCounter:  multi.NewCounter(providerConfigReloadsFailureCounter...),
+		providerLastConfigReloadSuccessGauge: multi.NewGauge(providerLastConfigReloadSuccessGauge...),
+		providerLastConfigReloadFailureGauge: multi.NewGauge(providerLastConfigReloadFailureGauge...),
+		providerRoutersGauge:                 multi.NewGauge(providerRoutersGauge...),
+		providerServicesGauge:                multi.NewGauge(providerServicesGauge...),
+		providerConfigStalenessGauge:         multi.NewGauge(providerConfigStalenessGauge...),
+		entryPointReqsCounter:                multi.NewCounter(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:             multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:       NewMultiHistogram(entryPointReqDurationHistogram...),
+		entryPointOpenConnsGauge:             multi.NewGauge(entryPointOpenConnsGauge...),
+		entryPointHTTP2AbusesCounter:         multi.NewCounter(entryPointHTTP2AbusesCounter...),
+		entryPointTCPConnsCounter:            multi.NewCounter(entryPointTCPConnsCounter...),
+		entryPointTCPOpenConnsGauge:          multi.NewGauge(entryPointTCPOpenConnsGauge...),
+		entryPointTCPBytesCounter:            multi.NewCounter(entryPointTCPBytesCounter...),
+		entryPointUDPDatagramsCounter:        multi.NewCounter(entryPointUDPDatagramsCounter...),
+		entryPointUDPBytesCounter:            multi.NewCounter(entryPointUDPBytesCounter...),
+		routerReqsCounter:                    multi.NewCounter(routerReqsCounter...),
+		routerReqsTLSCounter:                 multi.NewCounter(routerReqsTLSCounter...),
+		routerReqDurationHistogram:           NewMultiHistogram(routerReqDurationHistogram...),
+		routerOpenConnsGauge:                 multi.NewGauge(routerOpenConnsGauge...),
+		serviceReqsCounter:                   multi.NewCounter(serviceReqsCounter...),
+		serviceReqsTLSCounter:                multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:          NewMultiHistogram(serviceReqDurationHistogram...),
+		serviceOpenConnsGauge:                multi.NewGauge(serviceOpenConnsGauge...),
+		serviceRetriesCounter:                multi.NewCounter(serviceRetriesCounter...),
+		serviceRetryDurationHistogram:        NewMultiHistogram(serviceRetryDurationHistogram...),
+		serviceServerUpGauge:                 multi.NewGauge(serviceServerUpGauge...),
+		serviceHealthCheckDurationHistogram:  NewMultiHistogram(serviceHealthCheckDurationHistogram...),
+		serviceReqSizeHistogram:              multi.NewHistogram(serviceReqSizeHistogram...),
+		serviceRespSizeHistogram:             multi.NewHistogram(serviceRespSizeHistogram...),
+		serviceTCPRTTHistogram:               NewMultiHistogram(serviceTCPRTTHistogram...),
+		serviceTCPRetransmitsCounter:         multi.NewCounter(serviceTCPRetransmitsCounter...),
+		serviceTCPDeliveryRateHistogram:      multi.NewHistogram(serviceTCPDeliveryRateHistogram...),
+		routerHTTPVersionRejectionsCounter:   multi.NewCounter(routerHTTPVersionRejectionsCounter...),
+		middlewareOPADecisionsCounter:        multi.NewCounter(middlewareOPADecisionsCounter...),
+		middlewareCircuitBreakerStateGauge:   multi.NewGauge(middlewareCircuitBreakerStateGauge...),
+		middlewareCircuitBreakerTripsCounter: multi.NewCounter(middlewareCircuitBreakerTripsCounter...),
+		middlewareRateLimitRequestsCounter:   newMultiCounter(middlewareRateLimitRequestsCounter...),
+		middlewareRateLimitBucketTokensGauge: newMultiGauge(middlewareRateLimitBucketTokensGauge...),
+		middlewareReqsCounter:                multi.NewCounter(middlewareReqsCounter...),
+		middlewareReqDurationHistogram:       NewMultiHistogram(middlewareReqDurationHistogram...),
+		middlewareShortCircuitsCounter:       multi.NewCounter(middlewareShortCircuitsCounter...),
+		middlewareErrorsCounter:              multi.NewCounter(middlewareErrorsCounter...),
+		acmeCertsObtainedCounter:             multi.NewCounter(acmeCertsObtainedCounter...),
+		acmeCertsRenewedCounter:              multi.NewCounter(acmeCertsRenewedCounter...),
+		acmeChallengeFailuresCounter:         multi.NewCounter(acmeChallengeFailuresCounter...),
+		acmeCertIssueDurationHistogram:       NewMultiHistogram(acmeCertIssueDurationHistogram...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	entryPointReqsCounter          metrics.Counter
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointOpenConnsGauge       metrics.Gauge
-	serviceReqsCounter             metrics.Counter
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceOpenConnsGauge          metrics.Gauge
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
+	epEnabled                            bool
+	routerEnabled                        bool
+	svcEnabled                           bool
+	middlewareEnabled                    bool
+	configReloadsCounter                 metrics.Counter
+	configReloadsFailureCounter          metrics.Counter
+	lastConfigReloadSuccessGauge         metrics.Gauge
+	lastConfigReloadFailureGauge         metrics.Gauge
+	configDeprecationsGauge              metrics.Gauge
+	tlsCertsNotAfterTimestampGauge       metrics.Gauge
+	providerConfigReloadsCounter         metrics.Counter
+	providerConfigReloadsFailureCounter  metrics.Counter
+	providerLastConfigReloadSuccessGauge metrics.Gauge
+	providerLastConfigReloadFailureGauge metrics.Gauge
+	providerRoutersGauge                 metrics.Gauge
+	providerServicesGauge                metrics.Gauge
+	providerConfigStalenessGauge         metrics.Gauge
+	entryPointReqsCounter                metrics.Counter
+	entryPointReqsTLSCounter             metrics.Counter
+	entryPointReqDurationHistogram       ScalableHistogram
+	entryPointOpenConnsGauge             metrics.Gauge
+	entryPointHTTP2AbusesCounter         metrics.Counter
+	entryPointTCPConnsCounter            metrics.Counter
+	entryPointTCPOpenConnsGauge          metrics.Gauge
+	entryPointTCPBytesCounter            metrics.Counter
+	entryPointUDPDatagramsCounter        metrics.Counter
+	entryPointUDPBytesCounter            metrics.Counter
+	routerReqsCounter                    metrics.Counter
+	routerReqsTLSCounter                 metrics.Counter
+	routerReqDurationHistogram           ScalableHistogram
+	routerOpenConnsGauge                 metrics.Gauge
+	serviceReqsCounter                   metrics.Counter
+	serviceReqsTLSCounter                metrics.Counter
+	serviceReqDurationHistogram          ScalableHistogram
+	serviceOpenConnsGauge                metrics.Gauge
+	serviceRetriesCounter                metrics.Counter
+	serviceRetryDurationHistogram        ScalableHistogram
+	serviceServerUpGauge                 metrics.Gauge
+	serviceHealthCheckDurationHistogram  ScalableHistogram
+	serviceReqSizeHistogram              metrics.Histogram
+	serviceRespSizeHistogram             metrics.Histogram
+	svcTCPInfoEnabled                    bool
+	requestLabels                        []*RequestLabel
+	serviceTCPRTTHistogram               ScalableHistogram
+	serviceTCPRetransmitsCounter         metrics.Counter
+	serviceTCPDeliveryRateHistogram      metrics.Histogram
+	routerHTTPVersionRejectionsCounter   metrics.Counter
+	middlewareOPADecisionsCounter        metrics.Counter
+	middlewareCircuitBreakerStateGauge   metrics.Gauge
+	middlewareCircuitBreakerTripsCounter metrics.Counter
+	middlewareRateLimitRequestsCounter   metrics.Counter
+	middlewareRateLimitBucketTokensGauge metrics.Gauge
+	middlewareReqsCounter                metrics.Counter
+	middlewareReqDurationHistogram       ScalableHistogram
+	middlewareShortCircuitsCounter       metrics.Counter
+	middlewareErrorsCounter              metrics.Counter
+	acmeCertsObtainedCounter             metrics.Counter
+	acmeCertsRenewedCounter              metrics.Counter
+	acmeChallengeFailuresCounter         metrics.Counter
+	acmeCertIssueDurationHistogram       ScalableHistogram
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
 	return r.epEnabled
 }
 
+func (r *standardRegistry) IsRouterEnabled() bool {
+	return r.routerEnabled
+}
+
+func (r *standardRegistry) IsMiddlewareEnabled() bool {
+	return r.middlewareEnabled
+}
+
 func (r *standardRegistry) IsSvcEnabled() bool {
 	return r.svcEnabled
 }
 
+func (r *standardRegistry) IsSvcTCPInfoEnabled() bool {
+	return r.svcTCPInfoEnabled
+}
+
+func (r *standardRegistry) RequestLabels() []*RequestLabel {
+	return r.requestLabels
+}
+
 func (r *standardRegistry) ConfigReloadsCounter() metrics.Counter {
 	return r.configReloadsCounter
 }
@@ -169,6 +496,42 @@ func (r *standardRegistry) LastConfigReloadFailureGauge() metrics.Gauge {
 	return r.lastConfigReloadFailureGauge
 }
 
+func (r *standardRegistry) ConfigDeprecationsGauge() metrics.Gauge {
+	return r.configDeprecationsGauge
+}
+
+func (r *standardRegistry) ProviderConfigReloadsCounter() metrics.Counter {
+	return r.providerConfigReloadsCounter
+}
+
+func (r *standardRegistry) ProviderConfigReloadsFailureCounter() metrics.Counter {
+	return r.providerConfigReloadsFailureCounter
+}
+
+func (r *standardRegistry) ProviderLastConfigReloadSuccessGauge() metrics.Gauge {
+	return r.providerLastConfigReloadSuccessGauge
+}
+
+func (r *standardRegistry) ProviderLastConfigReloadFailureGauge() metrics.Gauge {
+	return r.providerLastConfigReloadFailureGauge
+}
+
+func (r *standardRegistry) ProviderRoutersGauge() metrics.Gauge {
+	return r.providerRoutersGauge
+}
+
+func (r *standardRegistry) ProviderServicesGauge() metrics.Gauge {
+	return r.providerServicesGauge
+}
+
+func (r *standardRegistry) ProviderConfigStalenessGauge() metrics.Gauge {
+	return r.providerConfigStalenessGauge
+}
+
+func (r *standardRegistry) TLSCertsNotAfterTimestampGauge() metrics.Gauge {
+	return r.tlsCertsNotAfterTimestampGauge
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() metrics.Counter {
 	return r.entryPointReqsCounter
 }
@@ -185,6 +548,46 @@ func (r *standardRegistry) EntryPointOpenConnsGauge() metrics.Gauge {
 	return r.entryPointOpenConnsGauge
 }
 
+func (r *standardRegistry) EntryPointHTTP2AbusesCounter() metrics.Counter {
+	return r.entryPointHTTP2AbusesCounter
+}
+
+func (r *standardRegistry) EntryPointTCPConnsCounter() metrics.Counter {
+	return r.entryPointTCPConnsCounter
+}
+
+func (r *standardRegistry) EntryPointTCPOpenConnsGauge() metrics.Gauge {
+	return r.entryPointTCPOpenConnsGauge
+}
+
+func (r *standardRegistry) EntryPointTCPBytesCounter() metrics.Counter {
+	return r.entryPointTCPBytesCounter
+}
+
+func (r *standardRegistry) EntryPointUDPDatagramsCounter() metrics.Counter {
+	return r.entryPointUDPDatagramsCounter
+}
+
+func (r *standardRegistry) EntryPointUDPBytesCounter() metrics.Counter {
+	return r.entryPointUDPBytesCounter
+}
+
+func (r *standardRegistry) RouterReqsCounter() metrics.Counter {
+	return r.routerReqsCounter
+}
+
+func (r *standardRegistry) RouterReqsTLSCounter() metrics.Counter {
+	return r.routerReqsTLSCounter
+}
+
+func (r *standardRegistry) RouterReqDurationHistogram() ScalableHistogram {
+	return r.routerReqDurationHistogram
+}
+
+func (r *standardRegistry) RouterOpenConnsGauge() metrics.Gauge {
+	return r.routerOpenConnsGauge
+}
+
 func (r *standardRegistry) ServiceReqsCounter() metrics.Counter {
 	return r.serviceReqsCounter
 }
@@ -205,16 +608,112 @@ func (r *standardRegistry) ServiceRetriesCounter() metrics.Counter {
 	return r.serviceRetriesCounter
 }
 
+func (r *standardRegistry) ServiceRetryDurationHistogram() ScalableHistogram {
+	return r.serviceRetryDurationHistogram
+}
+
 func (r *standardRegistry) ServiceServerUpGauge() metrics.Gauge {
 	return r.serviceServerUpGauge
 }
 
+func (r *standardRegistry) ServiceHealthCheckDurationHistogram() ScalableHistogram {
+	return r.serviceHealthCheckDurationHistogram
+}
+
+func (r *standardRegistry) ServiceReqSizeHistogram() metrics.Histogram {
+	return r.serviceReqSizeHistogram
+}
+
+func (r *standardRegistry) ServiceRespSizeHistogram() metrics.Histogram {
+	return r.serviceRespSizeHistogram
+}
+
+func (r *standardRegistry) ServiceTCPRTTHistogram() ScalableHistogram {
+	return r.serviceTCPRTTHistogram
+}
+
+func (r *standardRegistry) ServiceTCPRetransmitsCounter() metrics.Counter {
+	return r.serviceTCPRetransmitsCounter
+}
+
+func (r *standardRegistry) ServiceTCPDeliveryRateHistogram() metrics.Histogram {
+	return r.serviceTCPDeliveryRateHistogram
+}
+
+func (r *standardRegistry) RouterHTTPVersionRejectionsCounter() metrics.Counter {
+	return r.routerHTTPVersionRejectionsCounter
+}
+
+func (r *standardRegistry) MiddlewareOPADecisionsCounter() metrics.Counter {
+	return r.middlewareOPADecisionsCounter
+}
+
+func (r *standardRegistry) MiddlewareCircuitBreakerStateGauge() metrics.Gauge {
+	return r.middlewareCircuitBreakerStateGauge
+}
+
+func (r *standardRegistry) MiddlewareCircuitBreakerTripsCounter() metrics.Counter {
+	return r.middlewareCircuitBreakerTripsCounter
+}
+
+func (r *standardRegistry) MiddlewareRateLimitRequestsCounter() metrics.Counter {
+	return r.middlewareRateLimitRequestsCounter
+}
+
+func (r *standardRegistry) MiddlewareRateLimitBucketTokensGauge() metrics.Gauge {
+	return r.middlewareRateLimitBucketTokensGauge
+}
+
+func (r *standardRegistry) MiddlewareReqsCounter() metrics.Counter {
+	return r.middlewareReqsCounter
+}
+
+func (r *standardRegistry) MiddlewareReqDurationHistogram() ScalableHistogram {
+	return r.middlewareReqDurationHistogram
+}
+
+func (r *standardRegistry) MiddlewareShortCircuitsCounter() metrics.Counter {
+	return r.middlewareShortCircuitsCounter
+}
+
+func (r *standardRegistry) MiddlewareErrorsCounter() metrics.Counter {
+	return r.middlewareErrorsCounter
+}
+
+func (r *standardRegistry) ACMECertsObtainedCounter() metrics.Counter {
+	return r.acmeCertsObtainedCounter
+}
+
+func (r *standardRegistry) ACMECertsRenewedCounter() metrics.Counter {
+	return r.acmeCertsRenewedCounter
+}
+
+func (r *standardRegistry) ACMEChallengeFailuresCounter() metrics.Counter {
+	return r.acmeChallengeFailuresCounter
+}
+
+func (r *standardRegistry) ACMECertIssueDurationHistogram() ScalableHistogram {
+	return r.acmeCertIssueDurationHistogram
+}
+
 // ScalableHistogram is a Histogram with a predefined time unit,
 // used when producing observations without explicitly setting the observed value.
 type ScalableHistogram interface {
 	With(labelValues ...string) ScalableHistogram
 	Observe(v float64)
 	ObserveFromStart(start time.Time)
+	ObserveFromStartWithExemplar(start time.Time, exemplar map[string]string)
+}
+
+// ExemplarHistogram is a Histogram able to attach an exemplar, such as the trace ID of a request, to an observation.
+type ExemplarHistogram interface {
+	ObserveWithExemplar(v float64, exemplar map[string]string)
+}
+
+// Deleter is implemented by the metrics whose series, of the label values given with With, can be deleted,
+// such as the series of a rate limiter source once its bucket expired.
+type Deleter interface {
+	Delete()
 }
 
 // HistogramWithScale is a histogram that will convert its observed value to the specified unit.
@@ -231,6 +730,12 @@ func (s *HistogramWithScale) With(labelValues ...string) ScalableHistogram {
 
 // ObserveFromStart implements ScalableHistogram.
 func (s *HistogramWithScale) ObserveFromStart(start time.Time) {
+	s.ObserveFromStartWithExemplar(start, nil)
+}
+
+// ObserveFromStartWithExemplar implements ScalableHistogram.
+// The exemplar is dropped by the histograms not supporting them.
+func (s *HistogramWithScale) ObserveFromStartWithExemplar(start time.Time, exemplar map[string]string) {
 	if s.unit <= 0 {
 		return
 	}
@@ -239,6 +744,11 @@ func (s *HistogramWithScale) ObserveFromStart(start time.Time) {
 	if d < 0 {
 		d = 0
 	}
+
+	if h, ok := s.histogram.(ExemplarHistogram); ok && len(exemplar) > 0 {
+		h.ObserveWithExemplar(d, exemplar)
+		return
+	}
 	s.histogram.Observe(d)
 }
 
@@ -273,6 +783,13 @@ func (h MultiHistogram) ObserveFromStart(start time.Time) {
 	}
 }
 
+// ObserveFromStartWithExemplar implements ScalableHistogram.
+func (h MultiHistogram) ObserveFromStartWithExemplar(start time.Time, exemplar map[string]string) {
+	for _, histogram := range h {
+		histogram.ObserveFromStartWithExemplar(start, exemplar)
+	}
+}
+
 // Observe implements ScalableHistogram.
 func (h MultiHistogram) Observe(v float64) {
 	for _, histogram := range h {
@@ -288,3 +805,86 @@ func (h MultiHistogram) With(labelValues ...string) ScalableHistogram {
 	}
 	return next
 }
+
+// multiCounter collects multiple individual counters and treats them as a unit,
+// deleting the series of the ones supporting it.
+type multiCounter []metrics.Counter
+
+// newMultiCounter returns a multi-counter wrapping the passed counters, or nil when there are none,
+// for the metrics enabled by an option to stay disabled.
+func newMultiCounter(c ...metrics.Counter) metrics.Counter {
+	if len(c) == 0 {
+		return nil
+	}
+	return multiCounter(c)
+}
+
+// With implements metrics.Counter.
+func (c multiCounter) With(labelValues ...string) metrics.Counter {
+	next := make(multiCounter, len(c))
+	for i := range c {
+		next[i] = c[i].With(labelValues...)
+	}
+	return next
+}
+
+// Add implements metrics.Counter.
+func (c multiCounter) Add(delta float64) {
+	for _, counter := range c {
+		counter.Add(delta)
+	}
+}
+
+// Delete implements Deleter.
+func (c multiCounter) Delete() {
+	for _, counter := range c {
+		if d, ok := counter.(Deleter); ok {
+			d.Delete()
+		}
+	}
+}
+
+// multiGauge collects multiple individual gauges and treats them as a unit,
+// deleting the series of the ones supporting it.
+type multiGauge []metrics.Gauge
+
+// newMultiGauge returns a multi-gauge wrapping the passed gauges, or nil when there are none,
+// for the metrics enabled by an option to stay disabled.
+func newMultiGauge(g ...metrics.Gauge) metrics.Gauge {
+	if len(g) == 0 {
+		return nil
+	}
+	return multiGauge(g)
+}
+
+// With implements metrics.Gauge.
+func (g multiGauge) With(labelValues ...string) metrics.Gauge {
+	next := make(multiGauge, len(g))
+	for i := range g {
+		next[i] = g[i].With(labelValues...)
+	}
+	return next
+}
+
+// Set implements metrics.Gauge.
+func (g multiGauge) Set(value float64) {
+	for _, gauge := range g {
+		gauge.Set(value)
+	}
+}
+
+// Add implements metrics.Gauge.
+func (g multiGauge) Add(delta float64) {
+	for _, gauge := range g {
+		gauge.Add(delta)
+	}
+}
+
+// Delete implements Deleter.
+func (g multiGauge) Delete() {
+	for _, gauge := range g {
+		if d, ok := gauge.(Deleter); ok {
+			d.Delete()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+)
+
+const cgroupPath = "/proc/self/cgroup"
+
+var containerIDRegexp = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// packetWriter packs the metric lines written by the dogstatsd client into packets of at most maxSize bytes,
+// instead of sending each line in its own packet.
+// When containerID is set, it is appended to each line for the agent origin detection.
+type packetWriter struct {
+	w           io.Writer
+	maxSize     int
+	containerID string
+	buf         bytes.Buffer
+}
+
+func newPacketWriter(w io.Writer, maxSize int, containerID string) *packetWriter {
+	return &packetWriter{w: w, maxSize: maxSize, containerID: containerID}
+}
+
+// Write buffers the given metric lines, sending the buffered packet first if they don't fit in it.
+func (p *packetWriter) Write(b []byte) (int, error) {
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		if p.containerID != "" {
+			line = append(bytes.TrimSuffix(line, []byte("\n")), []byte("|c:"+p.containerID+"\n")...)
+		}
+
+		if p.buf.Len() > 0 && p.buf.Len()+len(line) > p.maxSize {
+			if err := p.Flush(); err != nil {
+				return 0, err
+			}
+		}
+
+		p.buf.Write(line)
+	}
+
+	return len(b), nil
+}
+
+// Flush sends the buffered packet.
+func (p *packetWriter) Flush() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+
+	defer p.buf.Reset()
+
+	_, err := p.w.Write(p.buf.Bytes())
+	return err
+}
+
+// readContainerID reads the ID of the container Traefik runs in from the cgroup file.
+func readContainerID(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if id := containerIDRegexp.FindString(scanner.Text()); id != "" {
+			return id, nil
+		}
+	}
+
+	return "", scanner.Err()
+}
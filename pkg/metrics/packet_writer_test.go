@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type packetsRecorder [][]byte
+
+func (p *packetsRecorder) Write(b []byte) (int, error) {
+	*p = append(*p, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func TestPacketWriter(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		maxSize     int
+		containerID string
+		lines       []string
+		expected    []string
+	}{
+		{
+			desc:     "lines packed in one packet",
+			maxSize:  1432,
+			lines:    []string{"foo:1|c\n", "bar:2|g\n"},
+			expected: []string{"foo:1|c\nbar:2|g\n"},
+		},
+		{
+			desc:     "lines split when exceeding the packet size",
+			maxSize:  10,
+			lines:    []string{"foo:1|c\n", "bar:2|g\n", "baz:3|h\n"},
+			expected: []string{"foo:1|c\n", "bar:2|g\n", "baz:3|h\n"},
+		},
+		{
+			desc:     "line larger than the packet size",
+			maxSize:  4,
+			lines:    []string{"foo:1|c\n"},
+			expected: []string{"foo:1|c\n"},
+		},
+		{
+			desc:        "with the container ID",
+			maxSize:     1432,
+			containerID: "abc",
+			lines:       []string{"foo:1|c|#a:b\n", "bar:2|g\n"},
+			expected:    []string{"foo:1|c|#a:b|c:abc\nbar:2|g|c:abc\n"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := &packetsRecorder{}
+			writer := newPacketWriter(recorder, test.maxSize, test.containerID)
+
+			for _, line := range test.lines {
+				_, err := writer.Write([]byte(line))
+				require.NoError(t, err)
+			}
+			require.NoError(t, writer.Flush())
+
+			var packets []string
+			for _, packet := range *recorder {
+				packets = append(packets, string(packet))
+			}
+
+			assert.Equal(t, test.expected, packets)
+		})
+	}
+}
+
+func TestReadContainerID(t *testing.T) {
+	id := "3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860"
+
+	dir, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "cgroup")
+	err = ioutil.WriteFile(path, []byte("12:devices:/\n11:memory:/docker/"+id+"\n"), 0o644)
+	require.NoError(t, err)
+
+	containerID, err := readContainerID(path)
+	require.NoError(t, err)
+	assert.Equal(t, id, containerID)
+}
@@ -2,7 +2,9 @@ package metrics
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -27,24 +29,92 @@ const (
 	configReloadsFailuresTotalName = metricConfigPrefix + "reloads_failure_total"
 	configLastReloadSuccessName    = metricConfigPrefix + "last_reload_success"
 	configLastReloadFailureName    = metricConfigPrefix + "last_reload_failure"
+	configDeprecationsName         = metricConfigPrefix + "deprecations"
+
+	// provider.
+	metricProviderPrefix                   = MetricNamePrefix + "provider_"
+	providerConfigReloadsTotalName         = metricProviderPrefix + "config_reloads_total"
+	providerConfigReloadsFailuresTotalName = metricProviderPrefix + "config_reloads_failure_total"
+	providerConfigLastReloadSuccessName    = metricProviderPrefix + "config_last_reload_success"
+	providerConfigLastReloadFailureName    = metricProviderPrefix + "config_last_reload_failure"
+	providerRoutersName                    = metricProviderPrefix + "routers"
+	providerServicesName                   = metricProviderPrefix + "services"
+	providerConfigStalenessName            = metricProviderPrefix + "config_staleness_seconds"
+
+	// TLS.
+	metricsTLSPrefix          = MetricNamePrefix + "tls_"
+	tlsCertsNotAfterTimestamp = metricsTLSPrefix + "certs_not_after"
 
 	// entry point.
-	metricEntryPointPrefix     = MetricNamePrefix + "entrypoint_"
-	entryPointReqsTotalName    = metricEntryPointPrefix + "requests_total"
-	entryPointReqsTLSTotalName = metricEntryPointPrefix + "requests_tls_total"
-	entryPointReqDurationName  = metricEntryPointPrefix + "request_duration_seconds"
-	entryPointOpenConnsName    = metricEntryPointPrefix + "open_connections"
+	metricEntryPointPrefix          = MetricNamePrefix + "entrypoint_"
+	entryPointReqsTotalName         = metricEntryPointPrefix + "requests_total"
+	entryPointReqsTLSTotalName      = metricEntryPointPrefix + "requests_tls_total"
+	entryPointReqDurationName       = metricEntryPointPrefix + "request_duration_seconds"
+	entryPointOpenConnsName         = metricEntryPointPrefix + "open_connections"
+	entryPointHTTP2AbusesName       = metricEntryPointPrefix + "http2_abuses_total"
+	entryPointTCPConnsTotalName     = metricEntryPointPrefix + "tcp_connections_total"
+	entryPointTCPOpenConnsName      = metricEntryPointPrefix + "tcp_open_connections"
+	entryPointTCPBytesTotalName     = metricEntryPointPrefix + "tcp_bytes_total"
+	entryPointUDPDatagramsTotalName = metricEntryPointPrefix + "udp_datagrams_total"
+	entryPointUDPBytesTotalName     = metricEntryPointPrefix + "udp_bytes_total"
+
+	// router level.
+	metricRouterPrefix     = MetricNamePrefix + "router_"
+	routerReqsTotalName    = metricRouterPrefix + "requests_total"
+	routerReqsTLSTotalName = metricRouterPrefix + "requests_tls_total"
+	routerReqDurationName  = metricRouterPrefix + "request_duration_seconds"
+	routerOpenConnsName    = metricRouterPrefix + "open_connections"
 
 	// service level.
 
 	// MetricServicePrefix prefix of all service metric names.
-	MetricServicePrefix     = MetricNamePrefix + "service_"
-	serviceReqsTotalName    = MetricServicePrefix + "requests_total"
-	serviceReqsTLSTotalName = MetricServicePrefix + "requests_tls_total"
-	serviceReqDurationName  = MetricServicePrefix + "request_duration_seconds"
-	serviceOpenConnsName    = MetricServicePrefix + "open_connections"
-	serviceRetriesTotalName = MetricServicePrefix + "retries_total"
-	serviceServerUpName     = MetricServicePrefix + "server_up"
+	MetricServicePrefix      = MetricNamePrefix + "service_"
+	serviceReqsTotalName     = MetricServicePrefix + "requests_total"
+	serviceReqsTLSTotalName  = MetricServicePrefix + "requests_tls_total"
+	serviceReqDurationName   = MetricServicePrefix + "request_duration_seconds"
+	serviceOpenConnsName     = MetricServicePrefix + "open_connections"
+	serviceRetriesTotalName  = MetricServicePrefix + "retries_total"
+	serviceRetryDurationName = MetricServicePrefix + "retry_duration_seconds"
+	serviceServerUpName      = MetricServicePrefix + "server_up"
+
+	serviceHealthCheckDurationName = MetricServicePrefix + "health_check_duration_seconds"
+
+	serviceReqSizeName  = MetricServicePrefix + "request_size_bytes"
+	serviceRespSizeName = MetricServicePrefix + "response_size_bytes"
+
+	serviceTCPRTTName          = MetricServicePrefix + "tcp_rtt_seconds"
+	serviceTCPRetransmitsName  = MetricServicePrefix + "tcp_retransmits_total"
+	serviceTCPDeliveryRateName = MetricServicePrefix + "tcp_delivery_rate_bytes"
+
+	// router level.
+
+	// MetricRouterPrefix prefix of all router metric names.
+	MetricRouterPrefix              = MetricNamePrefix + "router_"
+	routerHTTPVersionRejectionsName = MetricRouterPrefix + "http_version_rejections_total"
+
+	// middleware level.
+
+	// MetricMiddlewarePrefix prefix of all middleware metric names.
+	MetricMiddlewarePrefix            = MetricNamePrefix + "middleware_"
+	middlewareOPADecisionsName        = MetricMiddlewarePrefix + "opa_decisions_total"
+	middlewareCircuitBreakerStateName = MetricMiddlewarePrefix + "circuit_breaker_state"
+	middlewareCircuitBreakerTripsName = MetricMiddlewarePrefix + "circuit_breaker_trips_total"
+	middlewareReqsTotalName           = MetricMiddlewarePrefix + "requests_total"
+	middlewareReqDurationName         = MetricMiddlewarePrefix + "request_duration_seconds"
+	middlewareShortCircuitsName       = MetricMiddlewarePrefix + "short_circuits_total"
+	middlewareErrorsTotalName         = MetricMiddlewarePrefix + "errors_total"
+
+	middlewareRateLimitRequestsName     = MetricMiddlewarePrefix + "ratelimit_requests_total"
+	middlewareRateLimitBucketTokensName = MetricMiddlewarePrefix + "ratelimit_bucket_tokens"
+
+	// ACME.
+	metricACMEPrefix               = MetricNamePrefix + "acme_"
+	acmeCertsObtainedTotalName     = metricACMEPrefix + "certificates_obtained_total"
+	acmeCertsRenewedTotalName      = metricACMEPrefix + "certificates_renewed_total"
+	acmeChallengeFailuresTotalName = metricACMEPrefix + "challenge_failures_total"
+	acmeCertIssueDurationName      = metricACMEPrefix + "certificate_issue_duration_seconds"
+
+	metricCardinalityOverflowsName = MetricNamePrefix + "metric_cardinality_overflows_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -63,9 +133,12 @@ var promState = newPrometheusState()
 
 var promRegistry = stdprometheus.NewRegistry()
 
+// runtimeMetricsNamespaceRegexp matches the valid namespaces of the runtime metrics, which prefix Prometheus metric names.
+var runtimeMetricsNamespaceRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
 // PrometheusHandler exposes Prometheus routes.
 func PrometheusHandler() http.Handler {
-	return promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{})
+	return promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true})
 }
 
 // RegisterPrometheus registers all Prometheus metrics.
@@ -73,30 +146,75 @@ func PrometheusHandler() http.Handler {
 func RegisterPrometheus(ctx context.Context, config *types.Prometheus) Registry {
 	standardRegistry := initStandardRegistry(config)
 
-	if err := promRegistry.Register(stdprometheus.NewProcessCollector(stdprometheus.ProcessCollectorOpts{})); err != nil {
-		if _, ok := err.(stdprometheus.AlreadyRegisteredError); !ok {
-			log.FromContext(ctx).Warn("ProcessCollector is already registered")
-		}
-	}
-	if err := promRegistry.Register(stdprometheus.NewGoCollector()); err != nil {
-		if _, ok := err.(stdprometheus.AlreadyRegisteredError); !ok {
-			log.FromContext(ctx).Warn("GoCollector is already registered")
-		}
+	if config.AddRuntimeMetrics {
+		registerRuntimeCollectors(ctx, config.RuntimeNamespace)
 	}
 
 	if !registerPromState(ctx) {
 		return nil
 	}
 
+	if config.AddInfoMetrics {
+		registerPromInfo(ctx)
+	}
+
+	if config.Push != nil && prometheusPushTicker == nil {
+		prometheusPusher = newPrometheusPusher(config.Push)
+		prometheusPushTicker = initPrometheusPush(ctx, prometheusPusher, time.Duration(config.Push.PushInterval))
+	}
+
 	return standardRegistry
 }
 
+// ValidateRuntimeNamespace checks that the namespace of the runtime metrics, when there is one, is a valid metric name prefix.
+func ValidateRuntimeNamespace(namespace string) error {
+	if namespace != "" && !runtimeMetricsNamespaceRegexp.MatchString(namespace) {
+		return fmt.Errorf("invalid namespace %q of the runtime metrics", namespace)
+	}
+	return nil
+}
+
+// registerRuntimeCollectors registers the Go runtime and process metrics,
+// whose names are prefixed with the namespace, when there is one.
+func registerRuntimeCollectors(ctx context.Context, namespace string) {
+	logger := log.FromContext(ctx)
+
+	var registerer stdprometheus.Registerer = promRegistry
+	if namespace != "" {
+		if err := ValidateRuntimeNamespace(namespace); err != nil {
+			logger.Errorf("The runtime metrics are not registered: %v", err)
+			return
+		}
+
+		registerer = stdprometheus.WrapRegistererWithPrefix(namespace+"_", promRegistry)
+	}
+
+	if err := registerer.Register(stdprometheus.NewProcessCollector(stdprometheus.ProcessCollectorOpts{})); err != nil {
+		if _, ok := err.(stdprometheus.AlreadyRegisteredError); !ok {
+			logger.Warn("ProcessCollector is already registered")
+		}
+	}
+	if err := registerer.Register(stdprometheus.NewGoCollector()); err != nil {
+		if _, ok := err.(stdprometheus.AlreadyRegisteredError); !ok {
+			logger.Warn("GoCollector is already registered")
+		}
+	}
+}
+
 func initStandardRegistry(config *types.Prometheus) Registry {
 	buckets := []float64{0.1, 0.3, 1.2, 5.0}
 	if config.Buckets != nil {
 		buckets = config.Buckets
 	}
 
+	sizeBuckets := []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+	if config.SizeBuckets != nil {
+		sizeBuckets = config.SizeBuckets
+	}
+
+	requestLabels := NewRequestLabels(config.RequestLabels)
+	extraLabelNames := requestLabelNames(requestLabels)
+
 	safe.Go(func() {
 		promState.ListenValueUpdates()
 	})
@@ -117,79 +235,222 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: configLastReloadFailureName,
 		Help: "Last config reload failure",
 	}, []string{})
+	configDeprecations := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: configDeprecationsName,
+		Help: "How many uses of deprecated configuration fields or behaviors exist, partitioned by feature.",
+	}, []string{"feature"})
+	providerConfigReloads := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: providerConfigReloadsTotalName,
+		Help: "Config reloads, partitioned by provider.",
+	}, []string{"provider"})
+	providerConfigReloadsFailures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: providerConfigReloadsFailuresTotalName,
+		Help: "Config failure reloads, partitioned by provider.",
+	}, []string{"provider"})
+	providerLastConfigReloadSuccess := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: providerConfigLastReloadSuccessName,
+		Help: "Last config reload success, partitioned by provider.",
+	}, []string{"provider"})
+	providerLastConfigReloadFailure := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: providerConfigLastReloadFailureName,
+		Help: "Last config reload failure, partitioned by provider.",
+	}, []string{"provider"})
+	providerRouters := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: providerRoutersName,
+		Help: "How many routers the configuration of a provider defines, partitioned by protocol.",
+	}, []string{"provider", "protocol"})
+	providerServices := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: providerServicesName,
+		Help: "How many services the configuration of a provider defines, partitioned by protocol.",
+	}, []string{"provider", "protocol"})
+	providerConfigStaleness := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: providerConfigStalenessName,
+		Help: "How long since the configuration of a discovery provider was last successfully discovered, partitioned by provider.",
+	}, []string{"provider"})
+	tlsCertsNotAfter := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: tlsCertsNotAfterTimestamp,
+		Help: "Certificate expiration timestamp, partitioned by SANs, certificate resolver, and entrypoint.",
+	}, []string{"sans", "resolver", "entrypoint"})
 
 	promState.describers = []func(chan<- *stdprometheus.Desc){
 		configReloads.cv.Describe,
 		configReloadsFailures.cv.Describe,
 		lastConfigReloadSuccess.gv.Describe,
 		lastConfigReloadFailure.gv.Describe,
+		configDeprecations.gv.Describe,
+		providerConfigReloads.cv.Describe,
+		providerConfigReloadsFailures.cv.Describe,
+		providerLastConfigReloadSuccess.gv.Describe,
+		providerLastConfigReloadFailure.gv.Describe,
+		providerRouters.gv.Describe,
+		providerServices.gv.Describe,
+		providerConfigStaleness.gv.Describe,
+		tlsCertsNotAfter.gv.Describe,
 	}
 
 	reg := &standardRegistry{
-		epEnabled:                    config.AddEntryPointsLabels,
-		svcEnabled:                   config.AddServicesLabels,
-		configReloadsCounter:         configReloads,
-		configReloadsFailureCounter:  configReloadsFailures,
-		lastConfigReloadSuccessGauge: lastConfigReloadSuccess,
-		lastConfigReloadFailureGauge: lastConfigReloadFailure,
+		epEnabled:                            config.AddEntryPointsLabels,
+		routerEnabled:                        config.AddRoutersLabels,
+		svcEnabled:                           config.AddServicesLabels,
+		middlewareEnabled:                    config.AddMiddlewaresLabels,
+		requestLabels:                        requestLabels,
+		configReloadsCounter:                 configReloads,
+		configReloadsFailureCounter:          configReloadsFailures,
+		lastConfigReloadSuccessGauge:         lastConfigReloadSuccess,
+		lastConfigReloadFailureGauge:         lastConfigReloadFailure,
+		configDeprecationsGauge:              configDeprecations,
+		providerConfigReloadsCounter:         providerConfigReloads,
+		providerConfigReloadsFailureCounter:  providerConfigReloadsFailures,
+		providerLastConfigReloadSuccessGauge: providerLastConfigReloadSuccess,
+		providerLastConfigReloadFailureGauge: providerLastConfigReloadFailure,
+		providerRoutersGauge:                 providerRouters,
+		providerServicesGauge:                providerServices,
+		providerConfigStalenessGauge:         providerConfigStaleness,
+		tlsCertsNotAfterTimestampGauge:       tlsCertsNotAfter,
 	}
 
 	if config.AddEntryPointsLabels {
 		entryPointReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 			Name: entryPointReqsTotalName,
 			Help: "How many HTTP requests processed on an entrypoint, partitioned by status code, protocol, method, and path.",
-		}, []string{"code", "method", "protocol", "entrypoint", "path"})
+		}, withoutMethodLabel(config.DropMethodLabel, append([]string{"code", "method", "protocol", "entrypoint", "path"}, extraLabelNames...)))
 		entryPointReqsTLS := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 			Name: entryPointReqsTLSTotalName,
 			Help: "How many HTTP requests with TLS processed on an entrypoint, partitioned by TLS Version and TLS cipher Used.",
 		}, []string{"tls_version", "tls_cipher", "entrypoint"})
-		entryPointReqDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		entryPointReqDurations := newHistogramFromConfig(promState.collectors, config.Histograms, withNativeHistogram(config.NativeHistograms, stdprometheus.HistogramOpts{
 			Name:    entryPointReqDurationName,
 			Help:    "How long it took to process the request on an entrypoint, partitioned by status code, protocol, method, and path.",
 			Buckets: buckets,
-		}, []string{"code", "method", "protocol", "entrypoint", "path"})
+		}), withoutMethodLabel(config.DropMethodLabel, append([]string{"code", "method", "protocol", "entrypoint", "path"}, extraLabelNames...)))
 		entryPointOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
 			Name: entryPointOpenConnsName,
-			Help: "How many open connections exist on an entrypoint, partitioned by method and protocol.",
-		}, []string{"method", "protocol", "entrypoint"})
+			Help: "How many open connections exist on an entrypoint, partitioned by protocol and TLS version.",
+		}, []string{"protocol", "tls_version", "entrypoint"})
+		entryPointHTTP2Abuses := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entryPointHTTP2AbusesName,
+			Help: "How many HTTP/2 connections were closed for abusing the protocol on an entrypoint, partitioned by reason.",
+		}, []string{"reason", "entrypoint"})
+		entryPointTCPConns := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entryPointTCPConnsTotalName,
+			Help: "How many connections were accepted by the TCP routers of an entrypoint.",
+		}, []string{"entrypoint"})
+		entryPointTCPOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: entryPointTCPOpenConnsName,
+			Help: "How many connections are open on the TCP routers of an entrypoint.",
+		}, []string{"entrypoint"})
+		entryPointTCPBytes := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entryPointTCPBytesTotalName,
+			Help: "How many bytes were transferred over the connections of the TCP routers of an entrypoint, partitioned by direction.",
+		}, []string{"direction", "entrypoint"})
+		entryPointUDPDatagrams := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entryPointUDPDatagramsTotalName,
+			Help: "How many UDP datagrams were transferred on an entrypoint, partitioned by direction.",
+		}, []string{"direction", "entrypoint"})
+		entryPointUDPBytes := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entryPointUDPBytesTotalName,
+			Help: "How many bytes were transferred in the UDP datagrams of an entrypoint, partitioned by direction.",
+		}, []string{"direction", "entrypoint"})
 
 		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
 			entryPointReqs.cv.Describe,
 			entryPointReqsTLS.cv.Describe,
 			entryPointReqDurations.hv.Describe,
 			entryPointOpenConns.gv.Describe,
+			entryPointHTTP2Abuses.cv.Describe,
+			entryPointTCPConns.cv.Describe,
+			entryPointTCPOpenConns.gv.Describe,
+			entryPointTCPBytes.cv.Describe,
+			entryPointUDPDatagrams.cv.Describe,
+			entryPointUDPBytes.cv.Describe,
 		}...)
 		reg.entryPointReqsCounter = entryPointReqs
 		reg.entryPointReqsTLSCounter = entryPointReqsTLS
 		reg.entryPointReqDurationHistogram, _ = NewHistogramWithScale(entryPointReqDurations, time.Second)
 		reg.entryPointOpenConnsGauge = entryPointOpenConns
+		reg.entryPointHTTP2AbusesCounter = entryPointHTTP2Abuses
+		reg.entryPointTCPConnsCounter = entryPointTCPConns
+		reg.entryPointTCPOpenConnsGauge = entryPointTCPOpenConns
+		reg.entryPointTCPBytesCounter = entryPointTCPBytes
+		reg.entryPointUDPDatagramsCounter = entryPointUDPDatagrams
+		reg.entryPointUDPBytesCounter = entryPointUDPBytes
+	}
+	if config.AddRoutersLabels {
+		routerReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: routerReqsTotalName,
+			Help: "How many HTTP requests are processed on a router, partitioned by service, status code, protocol, method, and path.",
+		}, withoutMethodLabel(config.DropMethodLabel, append([]string{"code", "method", "protocol", "router", "service", "path"}, extraLabelNames...)))
+		routerReqsTLS := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: routerReqsTLSTotalName,
+			Help: "How many HTTP requests with TLS are processed on a router, partitioned by service, TLS Version, and TLS cipher Used.",
+		}, []string{"tls_version", "tls_cipher", "router", "service"})
+		routerReqDurations := newHistogramFromConfig(promState.collectors, config.Histograms, withNativeHistogram(config.NativeHistograms, stdprometheus.HistogramOpts{
+			Name:    routerReqDurationName,
+			Help:    "How long it took to process the request on a router, partitioned by service, status code, protocol, method, and path.",
+			Buckets: buckets,
+		}), withoutMethodLabel(config.DropMethodLabel, append([]string{"code", "method", "protocol", "router", "service", "path"}, extraLabelNames...)))
+		routerOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: routerOpenConnsName,
+			Help: "How many open connections exist on a router, partitioned by service, method, and protocol.",
+		}, withoutMethodLabel(config.DropMethodLabel, []string{"method", "protocol", "router", "service"}))
+
+		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
+			routerReqs.cv.Describe,
+			routerReqsTLS.cv.Describe,
+			routerReqDurations.hv.Describe,
+			routerOpenConns.gv.Describe,
+		}...)
+		reg.routerReqsCounter = routerReqs
+		reg.routerReqsTLSCounter = routerReqsTLS
+		reg.routerReqDurationHistogram, _ = NewHistogramWithScale(routerReqDurations, time.Second)
+		reg.routerOpenConnsGauge = routerOpenConns
 	}
 	if config.AddServicesLabels {
 		serviceReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 			Name: serviceReqsTotalName,
 			Help: "How many HTTP requests processed on a service, partitioned by status code, protocol, method, and path.",
-		}, []string{"code", "method", "protocol", "service", "path"})
+		}, withoutMethodLabel(config.DropMethodLabel, append([]string{"code", "method", "protocol", "service", "path"}, extraLabelNames...)))
 		serviceReqsTLS := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 			Name: serviceReqsTLSTotalName,
 			Help: "How many HTTP requests with TLS processed on a service, partitioned by TLS version and TLS cipher.",
 		}, []string{"tls_version", "tls_cipher", "service"})
-		serviceReqDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		serviceReqDurations := newHistogramFromConfig(promState.collectors, config.Histograms, withNativeHistogram(config.NativeHistograms, stdprometheus.HistogramOpts{
 			Name:    serviceReqDurationName,
 			Help:    "How long it took to process the request on a service, partitioned by status code, protocol, method, and path.",
 			Buckets: buckets,
-		}, []string{"code", "method", "protocol", "service", "path"})
+		}), withoutMethodLabel(config.DropMethodLabel, append([]string{"code", "method", "protocol", "service", "path"}, extraLabelNames...)))
 		serviceOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
 			Name: serviceOpenConnsName,
 			Help: "How many open connections exist on a service, partitioned by method and protocol.",
-		}, []string{"method", "protocol", "service"})
+		}, withoutMethodLabel(config.DropMethodLabel, []string{"method", "protocol", "service"}))
 		serviceRetries := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 			Name: serviceRetriesTotalName,
-			Help: "How many request retries happened on a service.",
-		}, []string{"service"})
+			Help: "How many request retries happened on a service, partitioned by attempt number and outcome of the request.",
+		}, []string{"service", "attempt", "outcome"})
+		serviceRetryDurations := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    serviceRetryDurationName,
+			Help:    "How long all the attempts of the retried requests took on a service, partitioned by outcome.",
+			Buckets: buckets,
+		}, []string{"service", "outcome"})
 		serviceServerUp := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
 			Name: serviceServerUpName,
 			Help: "service server is up, described by gauge value of 0 or 1.",
 		}, []string{"service", "url"})
+		serviceHealthCheckDurations := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    serviceHealthCheckDurationName,
+			Help:    "How long it took to check the health of a service server.",
+			Buckets: buckets,
+		}, []string{"service", "url"})
+		serviceReqSizes := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    serviceReqSizeName,
+			Help:    "Size, in bytes, of the request bodies processed on a service, partitioned by router and method.",
+			Buckets: sizeBuckets,
+		}, withoutMethodLabel(config.DropMethodLabel, []string{"service", "router", "method"}))
+		serviceRespSizes := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    serviceRespSizeName,
+			Help:    "Size, in bytes, of the response bodies sent by a service, partitioned by router and method.",
+			Buckets: sizeBuckets,
+		}, withoutMethodLabel(config.DropMethodLabel, []string{"service", "router", "method"}))
 
 		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
 			serviceReqs.cv.Describe,
@@ -197,7 +458,11 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			serviceReqDurations.hv.Describe,
 			serviceOpenConns.gv.Describe,
 			serviceRetries.cv.Describe,
+			serviceRetryDurations.hv.Describe,
 			serviceServerUp.gv.Describe,
+			serviceHealthCheckDurations.hv.Describe,
+			serviceReqSizes.hv.Describe,
+			serviceRespSizes.hv.Describe,
 		}...)
 
 		reg.serviceReqsCounter = serviceReqs
@@ -205,9 +470,169 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		reg.serviceReqDurationHistogram, _ = NewHistogramWithScale(serviceReqDurations, time.Second)
 		reg.serviceOpenConnsGauge = serviceOpenConns
 		reg.serviceRetriesCounter = serviceRetries
+		reg.serviceRetryDurationHistogram, _ = NewHistogramWithScale(serviceRetryDurations, time.Second)
 		reg.serviceServerUpGauge = serviceServerUp
+		reg.serviceHealthCheckDurationHistogram, _ = NewHistogramWithScale(serviceHealthCheckDurations, time.Second)
+		reg.serviceReqSizeHistogram = serviceReqSizes
+		reg.serviceRespSizeHistogram = serviceRespSizes
+	}
+
+	if config.AddServicesTCPInfo {
+		serviceTCPRTT := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    serviceTCPRTTName,
+			Help:    "Smoothed round trip time of the TCP connections to the servers of a service, measured after each request.",
+			Buckets: []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5},
+		}, []string{"service"})
+		serviceTCPRetransmits := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceTCPRetransmitsName,
+			Help: "How many TCP segments were retransmitted on the connections to the servers of a service.",
+		}, []string{"service"})
+		serviceTCPDeliveryRate := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    serviceTCPDeliveryRateName,
+			Help:    "Delivery rate, in bytes per second, of the TCP connections to the servers of a service, measured after each request.",
+			Buckets: stdprometheus.ExponentialBuckets(1e4, 10, 6),
+		}, []string{"service"})
+
+		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
+			serviceTCPRTT.hv.Describe,
+			serviceTCPRetransmits.cv.Describe,
+			serviceTCPDeliveryRate.hv.Describe,
+		}...)
+
+		reg.svcTCPInfoEnabled = true
+		reg.serviceTCPRTTHistogram, _ = NewHistogramWithScale(serviceTCPRTT, time.Second)
+		reg.serviceTCPRetransmitsCounter = serviceTCPRetransmits
+		reg.serviceTCPDeliveryRateHistogram = serviceTCPDeliveryRate
+	}
+
+	routerHTTPVersionRejections := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: routerHTTPVersionRejectionsName,
+		Help: "How many requests were rejected by a router HTTP versions policy, partitioned by router and HTTP version.",
+	}, []string{"router", "version"})
+
+	promState.describers = append(promState.describers, routerHTTPVersionRejections.cv.Describe)
+	reg.routerHTTPVersionRejectionsCounter = routerHTTPVersionRejections
+
+	middlewareOPADecisions := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: middlewareOPADecisionsName,
+		Help: "How many authorization decisions were made by an OPA middleware, partitioned by decision.",
+	}, []string{"middleware", "decision"})
+
+	promState.describers = append(promState.describers, middlewareOPADecisions.cv.Describe)
+	reg.middlewareOPADecisionsCounter = middlewareOPADecisions
+
+	middlewareCircuitBreakerState := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: middlewareCircuitBreakerStateName,
+		Help: "Whether a circuit breaker middleware is in a state (closed, open or recovering), partitioned by service.",
+	}, []string{"middleware", "service", "state"})
+	middlewareCircuitBreakerTrips := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: middlewareCircuitBreakerTripsName,
+		Help: "How many times a circuit breaker middleware opened, partitioned by service.",
+	}, []string{"middleware", "service"})
+
+	promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
+		middlewareCircuitBreakerState.gv.Describe,
+		middlewareCircuitBreakerTrips.cv.Describe,
+	}...)
+	reg.middlewareCircuitBreakerStateGauge = middlewareCircuitBreakerState
+	reg.middlewareCircuitBreakerTripsCounter = middlewareCircuitBreakerTrips
+
+	if config.AddRateLimitMetrics {
+		middlewareRateLimitRequests := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: middlewareRateLimitRequestsName,
+			Help: "How many requests were allowed or rejected by a rate limit middleware, partitioned by result.",
+		}, withoutLabel(!config.AddRateLimitSourcesLabels, "source", []string{"middleware", "source", "result"}))
+
+		promState.describers = append(promState.describers, middlewareRateLimitRequests.cv.Describe)
+		reg.middlewareRateLimitRequestsCounter = middlewareRateLimitRequests
+
+		// The tokens are the ones of the bucket of each source.
+		if config.AddRateLimitSourcesLabels {
+			middlewareRateLimitBucketTokens := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+				Name: middlewareRateLimitBucketTokensName,
+				Help: "How many tokens are left in the bucket of a source of a rate limit middleware, negative when the requests are delayed.",
+			}, []string{"middleware", "source"})
+
+			promState.describers = append(promState.describers, middlewareRateLimitBucketTokens.gv.Describe)
+			reg.middlewareRateLimitBucketTokensGauge = middlewareRateLimitBucketTokens
+		}
+	}
+
+	if config.AddMiddlewaresLabels {
+		middlewareReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: middlewareReqsTotalName,
+			Help: "How many HTTP requests were processed by a middleware, partitioned by router, middleware and type.",
+		}, []string{"router", "middleware", "type"})
+		middlewareReqDurations := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+			Name:    middlewareReqDurationName,
+			Help:    "How long a middleware took to process the request, excluding the next handlers, partitioned by router, middleware and type.",
+			Buckets: buckets,
+		}, []string{"router", "middleware", "type"})
+		middlewareShortCircuits := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: middlewareShortCircuitsName,
+			Help: "How many HTTP requests were answered by a middleware without calling the next handler, partitioned by router, middleware, type and status code.",
+		}, []string{"router", "middleware", "type", "code"})
+		middlewareErrors := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: middlewareErrorsTotalName,
+			Help: "How many HTTP requests were answered by a middleware with a server error, without calling the next handler, partitioned by router, middleware, type and status code.",
+		}, []string{"router", "middleware", "type", "code"})
+
+		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
+			middlewareReqs.cv.Describe,
+			middlewareReqDurations.hv.Describe,
+			middlewareShortCircuits.cv.Describe,
+			middlewareErrors.cv.Describe,
+		}...)
+
+		reg.middlewareReqsCounter = middlewareReqs
+		reg.middlewareReqDurationHistogram, _ = NewHistogramWithScale(middlewareReqDurations, time.Second)
+		reg.middlewareShortCircuitsCounter = middlewareShortCircuits
+		reg.middlewareErrorsCounter = middlewareErrors
 	}
 
+	acmeCertsObtained := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: acmeCertsObtainedTotalName,
+		Help: "How many certificates were obtained by an ACME certificate resolver.",
+	}, []string{"resolver"})
+	acmeCertsRenewed := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: acmeCertsRenewedTotalName,
+		Help: "How many certificates were renewed by an ACME certificate resolver.",
+	}, []string{"resolver"})
+	acmeChallengeFailures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: acmeChallengeFailuresTotalName,
+		Help: "How many ACME challenges failed on a certificate resolver, partitioned by challenge type and domain.",
+	}, []string{"resolver", "challenge", "domain"})
+	acmeCertIssueDurations := newHistogramFromConfig(promState.collectors, config.Histograms, stdprometheus.HistogramOpts{
+		Name:    acmeCertIssueDurationName,
+		Help:    "How long it took an ACME certificate resolver to get a certificate issued, partitioned by operation.",
+		Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"resolver", "operation"})
+
+	promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
+		acmeCertsObtained.cv.Describe,
+		acmeCertsRenewed.cv.Describe,
+		acmeChallengeFailures.cv.Describe,
+		acmeCertIssueDurations.hv.Describe,
+	}...)
+
+	reg.acmeCertsObtainedCounter = acmeCertsObtained
+	reg.acmeCertsRenewedCounter = acmeCertsRenewed
+	reg.acmeChallengeFailuresCounter = acmeChallengeFailures
+	reg.acmeCertIssueDurationHistogram, _ = NewHistogramWithScale(acmeCertIssueDurations, time.Second)
+
+	if config.MaxLabelCardinality > 0 {
+		cardinalityOverflows := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: metricCardinalityOverflowsName,
+			Help: "How many observations were recorded with the other label values, the label combinations of their metric exceeding the limit, partitioned by metric.",
+		}, []string{"metric"})
+
+		promState.describers = append(promState.describers, cardinalityOverflows.cv.Describe)
+		limitCardinality(reg, config.MaxLabelCardinality, cardinalityOverflows)
+	}
+
+	reduceRequestLabels(reg, config.StatusCodeClasses, config.DropMethodLabel)
+	dropRateLimitSourceLabel(reg, !config.AddRateLimitSourcesLabels)
+
 	return reg
 }
 
@@ -237,6 +662,10 @@ func OnConfigurationUpdate(conf dynamic.Configuration, entryPoints []string) {
 		dynamicConfig.routers[name] = true
 	}
 
+	for name := range conf.HTTP.Middlewares {
+		dynamicConfig.middlewares[name] = true
+	}
+
 	for serviceName, service := range conf.HTTP.Services {
 		dynamicConfig.services[serviceName] = make(map[string]bool)
 		if service.LoadBalancer != nil {
@@ -247,6 +676,7 @@ func OnConfigurationUpdate(conf dynamic.Configuration, entryPoints []string) {
 	}
 
 	promState.SetDynamicConfig(dynamicConfig)
+	cardinalityLimiters.prune(dynamicConfig)
 }
 
 func newPrometheusState() *prometheusState {
@@ -275,7 +705,12 @@ func (ps *prometheusState) SetDynamicConfig(dynamicConfig *dynamicConfig) {
 func (ps *prometheusState) ListenValueUpdates() {
 	for collector := range ps.collectors {
 		ps.mtx.Lock()
-		ps.state[collector.id] = collector
+		if collector.deleted {
+			collector.delete()
+			delete(ps.state, collector.id)
+		} else {
+			ps.state[collector.id] = collector
+		}
 		ps.mtx.Unlock()
 	}
 }
@@ -315,28 +750,14 @@ func (ps *prometheusState) Collect(ch chan<- stdprometheus.Metric) {
 // isOutdated checks whether the passed collector has labels that mark
 // it as belonging to an outdated configuration of Traefik.
 func (ps *prometheusState) isOutdated(collector *collector) bool {
-	labels := collector.labels
-
-	if entrypointName, ok := labels["entrypoint"]; ok && !ps.dynamicConfig.hasEntryPoint(entrypointName) {
-		return true
-	}
-
-	if serviceName, ok := labels["service"]; ok {
-		if !ps.dynamicConfig.hasService(serviceName) {
-			return true
-		}
-		if url, ok := labels["url"]; ok && !ps.dynamicConfig.hasServerURL(serviceName, url) {
-			return true
-		}
-	}
-
-	return false
+	return ps.dynamicConfig.isOutdated(collector.labels)
 }
 
 func newDynamicConfig() *dynamicConfig {
 	return &dynamicConfig{
 		entryPoints: make(map[string]bool),
 		routers:     make(map[string]bool),
+		middlewares: make(map[string]bool),
 		services:    make(map[string]map[string]bool),
 	}
 }
@@ -348,14 +769,54 @@ func newDynamicConfig() *dynamicConfig {
 type dynamicConfig struct {
 	entryPoints map[string]bool
 	routers     map[string]bool
+	middlewares map[string]bool
 	services    map[string]map[string]bool
 }
 
+// isOutdated checks whether the labels belong to an outdated configuration of Traefik.
+// The labels collapsed by the cardinality limit are never outdated, their series gathering all the removed combinations.
+func (d *dynamicConfig) isOutdated(labels map[string]string) bool {
+	// The entry point label is empty on the certificates not served by a router.
+	if entrypointName, ok := labels["entrypoint"]; ok && entrypointName != "" && entrypointName != cardinalityOverflowValue && !d.hasEntryPoint(entrypointName) {
+		return true
+	}
+
+	// The router label is empty on the service size metrics of the requests not handled by a router.
+	if routerName, ok := labels["router"]; ok && routerName != "" && routerName != cardinalityOverflowValue && !d.hasRouter(routerName) {
+		return true
+	}
+
+	if middlewareName, ok := labels["middleware"]; ok && middlewareName != cardinalityOverflowValue && !d.hasMiddleware(middlewareName) {
+		return true
+	}
+
+	if serviceName, ok := labels["service"]; ok && serviceName != cardinalityOverflowValue {
+		if !d.hasService(serviceName) {
+			return true
+		}
+		if url, ok := labels["url"]; ok && !d.hasServerURL(serviceName, url) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (d *dynamicConfig) hasEntryPoint(entrypointName string) bool {
 	_, ok := d.entryPoints[entrypointName]
 	return ok
 }
 
+func (d *dynamicConfig) hasRouter(routerName string) bool {
+	_, ok := d.routers[routerName]
+	return ok
+}
+
+func (d *dynamicConfig) hasMiddleware(middlewareName string) bool {
+	_, ok := d.middlewares[middlewareName]
+	return ok
+}
+
 func (d *dynamicConfig) hasService(serviceName string) bool {
 	_, ok := d.services[serviceName]
 	return ok
@@ -378,6 +839,16 @@ func newCollector(metricName string, labels stdprometheus.Labels, c stdprometheu
 	}
 }
 
+// newDeletedCollector returns the collector deleting the series of the labels from the state.
+func newDeletedCollector(metricName string, labels stdprometheus.Labels, delete func()) *collector {
+	return &collector{
+		id:      buildMetricID(metricName, labels),
+		labels:  labels,
+		delete:  delete,
+		deleted: true,
+	}
+}
+
 // collector wraps a Collector object from the Prometheus client library.
 // It adds information on how many generations this metric should be present
 // in the /metrics output, relatived to the time it was last tracked.
@@ -386,6 +857,8 @@ type collector struct {
 	labels    stdprometheus.Labels
 	collector stdprometheus.Collector
 	delete    func()
+	// deleted is whether the series is deleted, instead of updated.
+	deleted bool
 }
 
 func buildMetricID(metricName string, labels stdprometheus.Labels) string {
@@ -435,6 +908,14 @@ func (c *counter) Add(delta float64) {
 	})
 }
 
+// Delete deletes the series of the label values, once the values recorded before are.
+func (c *counter) Delete() {
+	labels := c.labelNamesValues.ToLabels()
+	c.collectors <- newDeletedCollector(c.name, labels, func() {
+		c.cv.Delete(labels)
+	})
+}
+
 func (c *counter) Describe(ch chan<- *stdprometheus.Desc) {
 	c.cv.Describe(ch)
 }
@@ -486,6 +967,14 @@ func (g *gauge) Set(value float64) {
 	})
 }
 
+// Delete deletes the series of the label values, once the values recorded before are.
+func (g *gauge) Delete() {
+	labels := g.labelNamesValues.ToLabels()
+	g.collectors <- newDeletedCollector(g.name, labels, func() {
+		g.gv.Delete(labels)
+	})
+}
+
 func (g *gauge) Describe(ch chan<- *stdprometheus.Desc) {
 	g.gv.Describe(ch)
 }
@@ -499,9 +988,55 @@ func newHistogramFrom(collectors chan<- *collector, opts stdprometheus.Histogram
 	}
 }
 
+// withNativeHistogram returns the options of a histogram also recording its observations in a native histogram,
+// when they are enabled, besides the classic buckets, which the scrapers not supporting them still get.
+func withNativeHistogram(config *types.PrometheusNativeHistograms, opts stdprometheus.HistogramOpts) stdprometheus.HistogramOpts {
+	if config == nil {
+		return opts
+	}
+
+	opts.NativeHistogramBucketFactor = config.BucketFactor
+	opts.NativeHistogramMaxBucketNumber = uint32(config.MaxBucketNumber)
+
+	return opts
+}
+
+// newHistogramFromConfig creates a histogram with the buckets configured for the metric, if any,
+// the observations of the services with their own buckets being recorded in a distinct vector.
+func newHistogramFromConfig(collectors chan<- *collector, histograms map[string]*types.PrometheusHistogram, opts stdprometheus.HistogramOpts, labelNames []string) *histogram {
+	config := histograms[opts.Name]
+	if config == nil {
+		return newHistogramFrom(collectors, opts, labelNames)
+	}
+
+	if config.Buckets != nil {
+		opts.Buckets = config.Buckets
+	}
+
+	h := newHistogramFrom(collectors, opts, labelNames)
+
+	for serviceName, service := range config.Services {
+		if service == nil || service.Buckets == nil {
+			continue
+		}
+
+		if h.serviceHVs == nil {
+			h.serviceHVs = make(map[string]*stdprometheus.HistogramVec)
+		}
+
+		serviceOpts := opts
+		serviceOpts.Buckets = service.Buckets
+		h.serviceHVs[serviceName] = stdprometheus.NewHistogramVec(serviceOpts, labelNames)
+	}
+
+	return h
+}
+
 type histogram struct {
-	name             string
-	hv               *stdprometheus.HistogramVec
+	name string
+	hv   *stdprometheus.HistogramVec
+	// serviceHVs are the vectors of the services with their own buckets, by service name.
+	serviceHVs       map[string]*stdprometheus.HistogramVec
 	labelNamesValues labelNamesValues
 	collectors       chan<- *collector
 }
@@ -510,19 +1045,35 @@ func (h *histogram) With(labelValues ...string) metrics.Histogram {
 	return &histogram{
 		name:             h.name,
 		hv:               h.hv,
+		serviceHVs:       h.serviceHVs,
 		labelNamesValues: h.labelNamesValues.With(labelValues...),
 		collectors:       h.collectors,
 	}
 }
 
 func (h *histogram) Observe(value float64) {
+	h.ObserveWithExemplar(value, nil)
+}
+
+// ObserveWithExemplar implements ExemplarHistogram, the exemplar is exposed with the OpenMetrics format.
+func (h *histogram) ObserveWithExemplar(value float64, exemplar map[string]string) {
 	labels := h.labelNamesValues.ToLabels()
-	observer := h.hv.With(labels)
-	observer.Observe(value)
+
+	hv := h.hv
+	if serviceHV, ok := h.serviceHVs[labels["service"]]; ok {
+		hv = serviceHV
+	}
+
+	observer := hv.With(labels)
+	if exemplarObserver, ok := observer.(stdprometheus.ExemplarObserver); ok && len(exemplar) > 0 {
+		exemplarObserver.ObserveWithExemplar(value, exemplar)
+	} else {
+		observer.Observe(value)
+	}
 	// Do a type assertion to be sure that prometheus will be able to call the Collect method.
 	if collector, ok := observer.(stdprometheus.Histogram); ok {
 		h.collectors <- newCollector(h.name, labels, collector, func() {
-			h.hv.Delete(labels)
+			hv.Delete(labels)
 		})
 	}
 }
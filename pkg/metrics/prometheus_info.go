@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	routerInfoName     = MetricRouterPrefix + "info"
+	serviceInfoName    = MetricServicePrefix + "info"
+	middlewareInfoName = MetricMiddlewarePrefix + "info"
+	providerInfoName   = metricProviderPrefix + "info"
+)
+
+// promInfo holds the info metrics describing the current dynamic configuration.
+var promInfo = newConfigurationInfo()
+
+// registerPromInfo registers the info metrics of the dynamic configuration.
+func registerPromInfo(ctx context.Context) {
+	if err := promRegistry.Register(promInfo); err != nil {
+		if _, ok := err.(stdprometheus.AlreadyRegisteredError); !ok {
+			log.FromContext(ctx).Errorf("Unable to register the configuration info metrics to Prometheus: %v", err)
+		}
+	}
+}
+
+// OnConfigurationInfoUpdate replaces the info metrics with the ones describing the configuration.
+func OnConfigurationInfoUpdate(conf dynamic.Configuration) {
+	promInfo.SetConfiguration(conf)
+}
+
+// configurationInfo is a Collector of info metrics, valued with 1 and labelled with the description
+// of the routers, services, middlewares and providers of the dynamic configuration.
+// As its metrics are rebuilt on each configuration, the ones of the removed elements are dropped at once.
+type configurationInfo struct {
+	routerDesc     *stdprometheus.Desc
+	serviceDesc    *stdprometheus.Desc
+	middlewareDesc *stdprometheus.Desc
+	providerDesc   *stdprometheus.Desc
+
+	mu      sync.RWMutex
+	metrics []stdprometheus.Metric
+}
+
+func newConfigurationInfo() *configurationInfo {
+	return &configurationInfo{
+		routerDesc: stdprometheus.NewDesc(routerInfoName,
+			"Information about a router of the configuration, partitioned by protocol, service, and provider.",
+			[]string{"router", "protocol", "service", "provider"}, nil),
+		serviceDesc: stdprometheus.NewDesc(serviceInfoName,
+			"Information about a service of the configuration, partitioned by protocol, type, and provider.",
+			[]string{"service", "protocol", "type", "provider"}, nil),
+		middlewareDesc: stdprometheus.NewDesc(middlewareInfoName,
+			"Information about a middleware of the configuration, partitioned by type and provider.",
+			[]string{"middleware", "type", "provider"}, nil),
+		providerDesc: stdprometheus.NewDesc(providerInfoName,
+			"Information about a provider contributing to the configuration.",
+			[]string{"provider"}, nil),
+	}
+}
+
+// SetConfiguration rebuilds the info metrics from the configuration.
+func (c *configurationInfo) SetConfiguration(conf dynamic.Configuration) {
+	var metrics []stdprometheus.Metric
+	providers := make(map[string]struct{})
+
+	addRouter := func(name, protocol, service string) {
+		provider := providerName(name)
+		providers[provider] = struct{}{}
+		metrics = append(metrics, stdprometheus.MustNewConstMetric(c.routerDesc, stdprometheus.GaugeValue, 1,
+			name, protocol, qualifiedName(provider, service), provider))
+	}
+
+	addService := func(name, protocol string, service interface{}) {
+		provider := providerName(name)
+		providers[provider] = struct{}{}
+		metrics = append(metrics, stdprometheus.MustNewConstMetric(c.serviceDesc, stdprometheus.GaugeValue, 1,
+			name, protocol, configurationType(service), provider))
+	}
+
+	if conf.HTTP != nil {
+		for name, router := range conf.HTTP.Routers {
+			addRouter(name, "http", router.Service)
+		}
+		for name, service := range conf.HTTP.Services {
+			addService(name, "http", service)
+		}
+		for name, middleware := range conf.HTTP.Middlewares {
+			provider := providerName(name)
+			providers[provider] = struct{}{}
+
+			middlewareType := "Plugin"
+			if len(middleware.Plugin) == 0 {
+				middlewareType = configurationType(middleware)
+			}
+
+			metrics = append(metrics, stdprometheus.MustNewConstMetric(c.middlewareDesc, stdprometheus.GaugeValue, 1,
+				name, middlewareType, provider))
+		}
+	}
+
+	if conf.TCP != nil {
+		for name, router := range conf.TCP.Routers {
+			addRouter(name, "tcp", router.Service)
+		}
+		for name, service := range conf.TCP.Services {
+			addService(name, "tcp", service)
+		}
+	}
+
+	if conf.UDP != nil {
+		for name, router := range conf.UDP.Routers {
+			addRouter(name, "udp", router.Service)
+		}
+		for name, service := range conf.UDP.Services {
+			addService(name, "udp", service)
+		}
+	}
+
+	delete(providers, "")
+
+	var providerNames []string
+	for provider := range providers {
+		providerNames = append(providerNames, provider)
+	}
+	sort.Strings(providerNames)
+
+	for _, provider := range providerNames {
+		metrics = append(metrics, stdprometheus.MustNewConstMetric(c.providerDesc, stdprometheus.GaugeValue, 1, provider))
+	}
+
+	c.mu.Lock()
+	c.metrics = metrics
+	c.mu.Unlock()
+}
+
+// Describe implements prometheus.Collector.
+func (c *configurationInfo) Describe(ch chan<- *stdprometheus.Desc) {
+	ch <- c.routerDesc
+	ch <- c.serviceDesc
+	ch <- c.middlewareDesc
+	ch <- c.providerDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *configurationInfo) Collect(ch chan<- stdprometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// qualifiedName returns the name of the element qualified with the provider, when it is not already.
+func qualifiedName(provider, elementName string) string {
+	if elementName == "" || provider == "" || strings.Contains(elementName, "@") {
+		return elementName
+	}
+
+	return elementName + "@" + provider
+}
+
+// configurationType returns the type of a service or a middleware, which is the name of its configuration field.
+func configurationType(config interface{}) string {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ""
+	}
+
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			return v.Type().Field(i).Name
+		}
+	}
+
+	return ""
+}
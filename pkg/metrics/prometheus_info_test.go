@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationInfo(t *testing.T) {
+	info := newConfigurationInfo()
+
+	registry := stdprometheus.NewRegistry()
+	require.NoError(t, registry.Register(info))
+
+	info.SetConfiguration(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo@docker": {Service: "bar"},
+				"baz@file":   {Service: "bar@docker"},
+			},
+			Services: map[string]*dynamic.Service{
+				"bar@docker": {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"strip@docker":  {StripPrefix: &dynamic.StripPrefix{}},
+				"plugin@docker": {Plugin: map[string]dynamic.PluginConf{"demo": {}}},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"db@file": {Service: "db"},
+			},
+			Services: map[string]*dynamic.TCPService{
+				"db@file": {Weighted: &dynamic.TCPWeightedRoundRobin{}},
+			},
+		},
+	})
+
+	expected := map[string][]string{
+		routerInfoName: {
+			"protocol=http,provider=docker,router=foo@docker,service=bar@docker",
+			"protocol=http,provider=file,router=baz@file,service=bar@docker",
+			"protocol=tcp,provider=file,router=db@file,service=db@file",
+		},
+		serviceInfoName: {
+			"protocol=http,provider=docker,service=bar@docker,type=LoadBalancer",
+			"protocol=tcp,provider=file,service=db@file,type=Weighted",
+		},
+		middlewareInfoName: {
+			"middleware=plugin@docker,provider=docker,type=Plugin",
+			"middleware=strip@docker,provider=docker,type=StripPrefix",
+		},
+		providerInfoName: {
+			"provider=docker",
+			"provider=file",
+		},
+	}
+	assert.Equal(t, expected, gatherInfo(t, registry))
+
+	// The metrics of the removed elements are dropped.
+	info.SetConfiguration(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"baz@file": {Service: "bar@docker"},
+			},
+		},
+	})
+
+	expected = map[string][]string{
+		routerInfoName: {
+			"protocol=http,provider=file,router=baz@file,service=bar@docker",
+		},
+		providerInfoName: {
+			"provider=file",
+		},
+	}
+	assert.Equal(t, expected, gatherInfo(t, registry))
+}
+
+// gatherInfo returns the label pairs of the gathered info metrics, by metric name.
+func gatherInfo(t *testing.T, registry *stdprometheus.Registry) map[string][]string {
+	t.Helper()
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	infos := make(map[string][]string)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			assert.Equal(t, float64(1), metric.GetGauge().GetValue())
+
+			var labels []string
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, label.GetName()+"="+label.GetValue())
+			}
+			infos[family.GetName()] = append(infos[family.GetName()], strings.Join(labels, ","))
+		}
+		sort.Strings(infos[family.GetName()])
+	}
+
+	return infos
+}
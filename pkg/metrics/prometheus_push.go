@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+var (
+	prometheusPushTicker *time.Ticker
+	prometheusPusher     *push.Pusher
+)
+
+// initPrometheusPush pushes periodically the Prometheus metrics to the Pushgateway,
+// for the instances that cannot be scraped, e.g. because they are short-lived.
+func initPrometheusPush(ctx context.Context, pusher *push.Pusher, pushInterval time.Duration) *time.Ticker {
+	report := time.NewTicker(pushInterval)
+
+	safe.Go(func() {
+		for {
+			select {
+			case <-report.C:
+				// Push replaces all the metrics of the grouping key, so the removed metrics are removed from the Pushgateway too.
+				pushPrometheus(ctx, pusher)
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return report
+}
+
+func pushPrometheus(ctx context.Context, pusher *push.Pusher) {
+	if err := pusher.Push(); err != nil {
+		log.FromContext(ctx).WithField(log.MetricsProviderName, "prometheus").Errorf("Unable to push the metrics to the Pushgateway: %v", err)
+	}
+}
+
+func newPrometheusPusher(config *types.PrometheusPush) *push.Pusher {
+	pusher := push.New(config.URL, config.Job).
+		Gatherer(promRegistry).
+		Client(&http.Client{Timeout: time.Duration(config.PushInterval)})
+
+	for name, value := range config.Grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+
+	if config.Username != "" {
+		pusher = pusher.BasicAuth(config.Username, config.Password)
+	}
+
+	return pusher
+}
+
+// StopPrometheusPush stops internal prometheusPushTicker which controls the pushing of metrics to the Pushgateway and resets it to `nil`.
+// The metrics are pushed a last time, so the ones recorded since the last push, e.g. during the graceful shutdown, are not lost.
+func StopPrometheusPush() {
+	if prometheusPushTicker != nil {
+		prometheusPushTicker.Stop()
+		pushPrometheus(context.Background(), prometheusPusher)
+	}
+	prometheusPushTicker = nil
+	prometheusPusher = nil
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestPrometheusPusher(t *testing.T) {
+	var (
+		method   string
+		path     string
+		username string
+		password string
+	)
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		method = req.Method
+		path = req.URL.Path
+		username, password, _ = req.BasicAuth()
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer gateway.Close()
+
+	pusher := newPrometheusPusher(&types.PrometheusPush{
+		URL:          gateway.URL,
+		Job:          "traefik",
+		Grouping:     map[string]string{"instance": "traefik-1"},
+		PushInterval: ptypes.Duration(time.Second),
+		Username:     "user",
+		Password:     "secret",
+	})
+
+	require.NoError(t, pusher.Push())
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/traefik/instance/traefik-1", path)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "secret", password)
+}
+
+func TestStopPrometheusPush(t *testing.T) {
+	var pushes int32
+	gateway := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&pushes, 1)
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer gateway.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prometheusPusher = newPrometheusPusher(&types.PrometheusPush{URL: gateway.URL, Job: "traefik", PushInterval: ptypes.Duration(time.Hour)})
+	prometheusPushTicker = initPrometheusPush(ctx, prometheusPusher, time.Hour)
+
+	// The metrics are pushed on stop, without waiting for the next tick.
+	StopPrometheusPush()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pushes))
+	assert.Nil(t, prometheusPushTicker)
+	assert.Nil(t, prometheusPusher)
+
+	// Stopping again does not push.
+	StopPrometheusPush()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pushes))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegisterPromState(t *testing.T) {
@@ -104,10 +106,10 @@ func TestPrometheus(t *testing.T) {
 	// Reset state of global promState.
 	defer promState.reset()
 
-	prometheusRegistry := RegisterPrometheus(context.Background(), &types.Prometheus{AddEntryPointsLabels: true, AddServicesLabels: true})
+	prometheusRegistry := RegisterPrometheus(context.Background(), &types.Prometheus{AddEntryPointsLabels: true, AddRoutersLabels: true, AddServicesLabels: true})
 	defer promRegistry.Unregister(promState)
 
-	if !prometheusRegistry.IsEpEnabled() || !prometheusRegistry.IsSvcEnabled() {
+	if !prometheusRegistry.IsEpEnabled() || !prometheusRegistry.IsRouterEnabled() || !prometheusRegistry.IsSvcEnabled() {
 		t.Errorf("PrometheusRegistry should return true for IsEnabled()")
 	}
 
@@ -126,7 +128,20 @@ func TestPrometheus(t *testing.T) {
 		Observe(1)
 	prometheusRegistry.
 		EntryPointOpenConnsGauge().
-		With("method", http.MethodGet, "protocol", "http", "entrypoint", "http").
+		With("protocol", "http", "tls_version", "none", "entrypoint", "http").
+		Set(1)
+
+	prometheusRegistry.
+		RouterReqsCounter().
+		With("router", "demo", "service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http", "path", "/").
+		Add(1)
+	prometheusRegistry.
+		RouterReqDurationHistogram().
+		With("router", "demo", "service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http", "path", "/").
+		Observe(10000)
+	prometheusRegistry.
+		RouterOpenConnsGauge().
+		With("router", "demo", "service", "service1", "method", http.MethodGet, "protocol", "http").
 		Set(1)
 
 	prometheusRegistry.
@@ -198,12 +213,46 @@ func TestPrometheus(t *testing.T) {
 		{
 			name: entryPointOpenConnsName,
 			labels: map[string]string{
-				"method":     http.MethodGet,
-				"protocol":   "http",
-				"entrypoint": "http",
+				"protocol":    "http",
+				"tls_version": "none",
+				"entrypoint":  "http",
 			},
 			assert: buildGaugeAssert(t, entryPointOpenConnsName, 1),
 		},
+		{
+			name: routerReqsTotalName,
+			labels: map[string]string{
+				"code":     "200",
+				"method":   http.MethodGet,
+				"protocol": "http",
+				"router":   "demo",
+				"service":  "service1",
+				"path":     "/",
+			},
+			assert: buildCounterAssert(t, routerReqsTotalName, 1),
+		},
+		{
+			name: routerReqDurationName,
+			labels: map[string]string{
+				"code":     "200",
+				"method":   http.MethodGet,
+				"protocol": "http",
+				"router":   "demo",
+				"service":  "service1",
+				"path":     "/",
+			},
+			assert: buildHistogramAssert(t, routerReqDurationName, 1),
+		},
+		{
+			name: routerOpenConnsName,
+			labels: map[string]string{
+				"method":   http.MethodGet,
+				"protocol": "http",
+				"router":   "demo",
+				"service":  "service1",
+			},
+			assert: buildGaugeAssert(t, routerOpenConnsName, 1),
+		},
 		{
 			name: serviceReqsTotalName,
 			labels: map[string]string{
@@ -272,6 +321,55 @@ func TestPrometheus(t *testing.T) {
 	}
 }
 
+func TestRegisterRuntimeCollectors(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		namespace        string
+		expectedPrefixes []string
+	}{
+		{
+			desc:             "no namespace",
+			expectedPrefixes: []string{"go_", "process_"},
+		},
+		{
+			desc:             "namespace",
+			namespace:        "traefik_debug",
+			expectedPrefixes: []string{"traefik_debug_go_", "traefik_debug_process_"},
+		},
+		{
+			desc:      "invalid namespace",
+			namespace: "traefik-debug",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			promRegistry = prometheus.NewRegistry()
+
+			registerRuntimeCollectors(context.Background(), test.namespace)
+
+			families, err := promRegistry.Gather()
+			require.NoError(t, err)
+
+			if len(test.expectedPrefixes) == 0 {
+				assert.Empty(t, families)
+				return
+			}
+
+			for _, prefix := range test.expectedPrefixes {
+				var found bool
+				for _, family := range families {
+					if strings.HasPrefix(family.GetName(), prefix) {
+						found = true
+						break
+					}
+				}
+				assert.Truef(t, found, "no metric named with the prefix %s", prefix)
+			}
+		})
+	}
+}
+
 func TestPrometheusMetricRemoval(t *testing.T) {
 	promState = newPrometheusState()
 	promRegistry = prometheus.NewRegistry()
@@ -510,3 +608,175 @@ func buildTimestampAssert(t *testing.T, metricName string) func(family *dto.Metr
 		}
 	}
 }
+
+func TestNewHistogramFromConfig(t *testing.T) {
+	histograms := map[string]*types.PrometheusHistogram{
+		"duration": {
+			Buckets: []float64{0.5, 1},
+			Services: map[string]*types.PrometheusServiceHistogram{
+				"slow@file": {Buckets: []float64{1, 10, 60}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc            string
+		name            string
+		service         string
+		expectedBuckets []float64
+	}{
+		{
+			desc:            "default buckets",
+			name:            "size",
+			service:         "foo@file",
+			expectedBuckets: []float64{0.1, 0.3},
+		},
+		{
+			desc:            "metric buckets",
+			name:            "duration",
+			service:         "foo@file",
+			expectedBuckets: []float64{0.5, 1},
+		},
+		{
+			desc:            "service buckets",
+			name:            "duration",
+			service:         "slow@file",
+			expectedBuckets: []float64{1, 10, 60},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			collectors := make(chan *collector, 1)
+			h := newHistogramFromConfig(collectors, histograms, prometheus.HistogramOpts{
+				Name:    test.name,
+				Help:    "Test histogram",
+				Buckets: []float64{0.1, 0.3},
+			}, []string{"service"})
+
+			h.With("service", test.service).Observe(2)
+
+			c := <-collectors
+
+			metric := &dto.Metric{}
+			err := c.collector.(prometheus.Histogram).Write(metric)
+			require.NoError(t, err)
+
+			var buckets []float64
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				buckets = append(buckets, bucket.GetUpperBound())
+			}
+			assert.Equal(t, test.expectedBuckets, buckets)
+			assert.Equal(t, uint64(1), metric.GetHistogram().GetSampleCount())
+		})
+	}
+}
+
+func TestWithNativeHistogram(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         *types.PrometheusNativeHistograms
+		expectedNative bool
+	}{
+		{
+			desc: "disabled",
+		},
+		{
+			desc:           "enabled",
+			config:         &types.PrometheusNativeHistograms{BucketFactor: 1.1, MaxBucketNumber: 160},
+			expectedNative: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			collectors := make(chan *collector, 1)
+			h := newHistogramFrom(collectors, withNativeHistogram(test.config, prometheus.HistogramOpts{
+				Name:    "duration",
+				Help:    "Test histogram",
+				Buckets: []float64{0.1, 0.3},
+			}), []string{"service"})
+
+			h.With("service", "foo@file").Observe(0.2)
+
+			c := <-collectors
+
+			metric := &dto.Metric{}
+			err := c.collector.(prometheus.Histogram).Write(metric)
+			require.NoError(t, err)
+
+			// The classic buckets are kept for the scrapers not supporting the native histograms.
+			assert.Len(t, metric.GetHistogram().GetBucket(), 2)
+			assert.Equal(t, test.expectedNative, len(metric.GetHistogram().GetPositiveSpan()) > 0)
+		})
+	}
+}
+
+func TestHistogram_ObserveWithExemplar(t *testing.T) {
+	collectors := make(chan *collector, 1)
+	h := newHistogramFrom(collectors, prometheus.HistogramOpts{
+		Name:    "duration",
+		Help:    "Test histogram",
+		Buckets: []float64{0.1, 0.3},
+	}, []string{"service"})
+
+	h.With("service", "foo@file").(ExemplarHistogram).ObserveWithExemplar(0.2, map[string]string{"trace_id": "42"})
+
+	c := <-collectors
+
+	metric := &dto.Metric{}
+	err := c.collector.(prometheus.Histogram).Write(metric)
+	require.NoError(t, err)
+
+	buckets := metric.GetHistogram().GetBucket()
+	require.Len(t, buckets, 2)
+	assert.Nil(t, buckets[0].GetExemplar())
+
+	exemplar := buckets[1].GetExemplar()
+	require.NotNil(t, exemplar)
+	assert.Equal(t, 0.2, exemplar.GetValue())
+	require.Len(t, exemplar.GetLabel(), 1)
+	assert.Equal(t, "trace_id", exemplar.GetLabel()[0].GetName())
+	assert.Equal(t, "42", exemplar.GetLabel()[0].GetValue())
+}
+
+func TestCounter_Delete(t *testing.T) {
+	ps := newPrometheusState()
+	go ps.ListenValueUpdates()
+	defer close(ps.collectors)
+
+	c := newCounterFrom(ps.collectors, prometheus.CounterOpts{
+		Name: "requests",
+		Help: "Test counter",
+	}, []string{"source"})
+
+	c.With("source", "10.0.0.1").Add(1)
+	c.With("source", "10.0.0.2").Add(1)
+	c.With("source", "10.0.0.1").(Deleter).Delete()
+
+	assert.Eventually(t, func() bool {
+		ps.mtx.Lock()
+		defer ps.mtx.Unlock()
+
+		_, ok := ps.state[buildMetricID("requests", prometheus.Labels{"source": "10.0.0.1"})]
+		return !ok && len(ps.state) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	metrics := make(chan prometheus.Metric, 2)
+	c.cv.Collect(metrics)
+	close(metrics)
+
+	var sources []string
+	for metric := range metrics {
+		m := &dto.Metric{}
+		require.NoError(t, metric.Write(m))
+		sources = append(sources, m.GetLabel()[0].GetValue())
+	}
+	assert.Equal(t, []string{"10.0.0.2"}, sources)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+)
+
+// ProviderReloads records the configuration reloads of each provider.
+// The reload of a provider fails when elements of its configuration are disabled by errors.
+type ProviderReloads struct {
+	registry Registry
+
+	mu sync.Mutex
+	// disabled is the number of elements disabled by errors of each provider, in the last runtime configuration.
+	disabled map[string]int
+}
+
+// NewProviderReloads creates a new ProviderReloads.
+func NewProviderReloads(registry Registry) *ProviderReloads {
+	return &ProviderReloads{
+		registry: registry,
+		disabled: make(map[string]int),
+	}
+}
+
+// OnRuntimeConfiguration records the elements disabled by errors of each provider in the runtime configuration.
+func (r *ProviderReloads) OnRuntimeConfiguration(rtConf *runtime.Configuration) {
+	disabled := make(map[string]int)
+
+	count := func(name, status string) {
+		if status == runtime.StatusDisabled {
+			disabled[providerName(name)]++
+		}
+	}
+
+	for name, rt := range rtConf.Routers {
+		count(name, rt.Status)
+	}
+	for name, mid := range rtConf.Middlewares {
+		count(name, mid.Status)
+	}
+	for name, svc := range rtConf.Services {
+		count(name, svc.Status)
+	}
+	for name, rt := range rtConf.TCPRouters {
+		count(name, rt.Status)
+	}
+	for name, svc := range rtConf.TCPServices {
+		count(name, svc.Status)
+	}
+	for name, rt := range rtConf.UDPRouters {
+		count(name, rt.Status)
+	}
+	for name, svc := range rtConf.UDPServices {
+		count(name, svc.Status)
+	}
+
+	r.mu.Lock()
+	r.disabled = disabled
+	r.mu.Unlock()
+}
+
+// OnProviderReload records the reload of the configuration of the provider,
+// as a failure when elements of its configuration were disabled by errors.
+func (r *ProviderReloads) OnProviderReload(provider string, conf *dynamic.Configuration) {
+	r.mu.Lock()
+	failed := r.disabled[provider] > 0
+	r.mu.Unlock()
+
+	now := float64(time.Now().Unix())
+
+	r.registry.ProviderConfigReloadsCounter().With("provider", provider).Add(1)
+	if failed {
+		r.registry.ProviderConfigReloadsFailureCounter().With("provider", provider).Add(1)
+		r.registry.ProviderLastConfigReloadFailureGauge().With("provider", provider).Set(now)
+	} else {
+		r.registry.ProviderLastConfigReloadSuccessGauge().With("provider", provider).Set(now)
+	}
+
+	var routers, services [3]int
+	if conf.HTTP != nil {
+		routers[0], services[0] = len(conf.HTTP.Routers), len(conf.HTTP.Services)
+	}
+	if conf.TCP != nil {
+		routers[1], services[1] = len(conf.TCP.Routers), len(conf.TCP.Services)
+	}
+	if conf.UDP != nil {
+		routers[2], services[2] = len(conf.UDP.Routers), len(conf.UDP.Services)
+	}
+
+	for i, protocol := range []string{"http", "tcp", "udp"} {
+		r.registry.ProviderRoutersGauge().With("provider", provider, "protocol", protocol).Set(float64(routers[i]))
+		r.registry.ProviderServicesGauge().With("provider", provider, "protocol", protocol).Set(float64(services[i]))
+	}
+}
+
+func providerName(elementName string) string {
+	parts := strings.SplitN(elementName, "@", 2)
+	if len(parts) == 2 {
+		return parts[1]
+	}
+	return ""
+}
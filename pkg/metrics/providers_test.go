@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type collectingCounter struct {
+	values map[string]float64
+	labels string
+}
+
+func (c *collectingCounter) With(labelValues ...string) metrics.Counter {
+	return &collectingCounter{values: c.values, labels: strings.Join(labelValues, " ")}
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.values[c.labels] += delta
+}
+
+type providerRegistry struct {
+	Registry
+
+	reloads        *collectingCounter
+	reloadFailures *collectingCounter
+	lastSuccess    *collectingGauge
+	lastFailure    *collectingGauge
+	routers        *collectingGauge
+	services       *collectingGauge
+}
+
+func newProviderRegistry() *providerRegistry {
+	return &providerRegistry{
+		reloads:        &collectingCounter{values: map[string]float64{}},
+		reloadFailures: &collectingCounter{values: map[string]float64{}},
+		lastSuccess:    &collectingGauge{values: map[string]float64{}},
+		lastFailure:    &collectingGauge{values: map[string]float64{}},
+		routers:        &collectingGauge{values: map[string]float64{}},
+		services:       &collectingGauge{values: map[string]float64{}},
+	}
+}
+
+func (r *providerRegistry) ProviderConfigReloadsCounter() metrics.Counter { return r.reloads }
+func (r *providerRegistry) ProviderConfigReloadsFailureCounter() metrics.Counter {
+	return r.reloadFailures
+}
+func (r *providerRegistry) ProviderLastConfigReloadSuccessGauge() metrics.Gauge { return r.lastSuccess }
+func (r *providerRegistry) ProviderLastConfigReloadFailureGauge() metrics.Gauge { return r.lastFailure }
+func (r *providerRegistry) ProviderRoutersGauge() metrics.Gauge                 { return r.routers }
+func (r *providerRegistry) ProviderServicesGauge() metrics.Gauge                { return r.services }
+
+func TestProviderReloads(t *testing.T) {
+	registry := newProviderRegistry()
+	reloads := NewProviderReloads(registry)
+
+	fileConf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo": {Service: "foo"},
+				"bar": {Service: "bar"},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo": {},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"foo": {Service: "foo"},
+			},
+		},
+	}
+
+	reloads.OnRuntimeConfiguration(&runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@file":   {Status: runtime.StatusEnabled},
+			"bar@file":   {Status: runtime.StatusDisabled},
+			"foo@docker": {Status: runtime.StatusEnabled},
+		},
+	})
+	reloads.OnProviderReload("file", fileConf)
+	reloads.OnProviderReload("docker", &dynamic.Configuration{})
+
+	assert.Equal(t, map[string]float64{"provider file": 1, "provider docker": 1}, registry.reloads.values)
+	assert.Equal(t, map[string]float64{"provider file": 1}, registry.reloadFailures.values)
+	assert.Contains(t, registry.lastFailure.values, "provider file")
+	assert.NotContains(t, registry.lastSuccess.values, "provider file")
+	assert.Contains(t, registry.lastSuccess.values, "provider docker")
+
+	assert.Equal(t, map[string]float64{
+		"provider file protocol http":   2,
+		"provider file protocol tcp":    1,
+		"provider file protocol udp":    0,
+		"provider docker protocol http": 0,
+		"provider docker protocol tcp":  0,
+		"provider docker protocol udp":  0,
+	}, registry.routers.values)
+	assert.Equal(t, map[string]float64{
+		"provider file protocol http":   1,
+		"provider file protocol tcp":    0,
+		"provider file protocol udp":    0,
+		"provider docker protocol http": 0,
+		"provider docker protocol tcp":  0,
+		"provider docker protocol udp":  0,
+	}, registry.services.values)
+
+	// The router is fixed by the next configuration of the provider.
+	reloads.OnRuntimeConfiguration(&runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@file": {Status: runtime.StatusEnabled},
+		},
+	})
+	reloads.OnProviderReload("file", fileConf)
+
+	assert.Equal(t, float64(2), registry.reloads.values["provider file"])
+	assert.Equal(t, float64(1), registry.reloadFailures.values["provider file"])
+	assert.Contains(t, registry.lastSuccess.values, "provider file")
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// requestLabelNameRegexp matches the valid Prometheus label names.
+var requestLabelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// reservedRequestLabelNames are the names of the labels the request metrics already have.
+var reservedRequestLabelNames = []string{"code", "entrypoint", "method", "path", "protocol", "router", "service"}
+
+// ValidateRequestLabels checks that the request labels have valid names, not already used by the request metrics,
+// and are valued from either a header or the client certificate.
+func ValidateRequestLabels(config map[string]*types.PrometheusRequestLabel) error {
+	for name, label := range config {
+		if !requestLabelNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid request label name %q", name)
+		}
+
+		for _, reserved := range reservedRequestLabelNames {
+			if name == reserved {
+				return fmt.Errorf("the request label name %q is reserved", name)
+			}
+		}
+
+		if label == nil || (label.Header != "") == label.ClientCertCN {
+			return fmt.Errorf("the request label %q must be valued from either a header or the client certificate", name)
+		}
+
+		if label.MaxValues < 0 {
+			return fmt.Errorf("invalid maximum number of values %d of the request label %q", label.MaxValues, name)
+		}
+	}
+
+	return nil
+}
+
+// RequestLabel is an extra label of the request metrics, valued from a header or the client certificate of the requests.
+// Its number of values is capped, the new values seen beyond the cap being recorded as "other".
+type RequestLabel struct {
+	name         string
+	header       string
+	clientCertCN bool
+	maxValues    int
+
+	mu     sync.Mutex
+	seen   map[string]struct{}
+	warned bool
+}
+
+// NewRequestLabels creates the request labels of the configuration, sorted by name.
+func NewRequestLabels(config map[string]*types.PrometheusRequestLabel) []*RequestLabel {
+	var labels []*RequestLabel
+	for name, label := range config {
+		if label == nil {
+			continue
+		}
+
+		labels = append(labels, &RequestLabel{
+			name:         name,
+			header:       http.CanonicalHeaderKey(label.Header),
+			clientCertCN: label.ClientCertCN,
+			maxValues:    label.MaxValues,
+			seen:         make(map[string]struct{}),
+		})
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+
+	return labels
+}
+
+// requestLabelNames returns the names of the request labels.
+func requestLabelNames(labels []*RequestLabel) []string {
+	var names []string
+	for _, label := range labels {
+		names = append(names, label.name)
+	}
+
+	return names
+}
+
+// Name returns the name of the label.
+func (l *RequestLabel) Name() string {
+	return l.name
+}
+
+// Value returns the value of the label for the request,
+// which is empty when the request has no such header or client certificate.
+func (l *RequestLabel) Value(req *http.Request) string {
+	var value string
+	switch {
+	case l.clientCertCN:
+		if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+			value = req.TLS.PeerCertificates[0].Subject.CommonName
+		}
+	default:
+		value = req.Header.Get(l.header)
+	}
+
+	if value == "" || l.maxValues <= 0 {
+		return value
+	}
+
+	return l.limit(value)
+}
+
+// limit returns the value, or "other" when it is new and the label already has its maximum number of values.
+// As the seen values are never forgotten, a value is either always kept or always collapsed.
+func (l *RequestLabel) limit(value string) string {
+	l.mu.Lock()
+	_, seen := l.seen[value]
+	if !seen && len(l.seen) < l.maxValues {
+		l.seen[value] = struct{}{}
+		seen = true
+	}
+
+	warn := !seen && !l.warned
+	if warn {
+		l.warned = true
+	}
+	l.mu.Unlock()
+
+	if seen {
+		return value
+	}
+
+	if warn {
+		log.WithoutContext().Warnf("The request label %s reached its limit of %d values, the new values are recorded as %q", l.name, l.maxValues, cardinalityOverflowValue)
+	}
+
+	return cardinalityOverflowValue
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLabel_Value(t *testing.T) {
+	labels := NewRequestLabels(map[string]*types.PrometheusRequestLabel{
+		"tenant": {Header: "x-tenant-id", MaxValues: 2},
+		"client": {ClientCertCN: true},
+	})
+	require.Len(t, labels, 2)
+
+	client, tenant := labels[0], labels[1]
+	assert.Equal(t, "client", client.Name())
+	assert.Equal(t, "tenant", tenant.Name())
+
+	newRequest := func(tenantID string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tenantID != "" {
+			req.Header.Set("X-Tenant-ID", tenantID)
+		}
+		return req
+	}
+
+	assert.Equal(t, "", tenant.Value(newRequest("")))
+	assert.Equal(t, "foo", tenant.Value(newRequest("foo")))
+	assert.Equal(t, "bar", tenant.Value(newRequest("bar")))
+	assert.Equal(t, cardinalityOverflowValue, tenant.Value(newRequest("baz")))
+	// The values seen before the cap was reached are still kept.
+	assert.Equal(t, "foo", tenant.Value(newRequest("foo")))
+
+	req := newRequest("")
+	assert.Equal(t, "", client.Value(req))
+
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client.example.com"}}},
+	}
+	assert.Equal(t, "client.example.com", client.Value(req))
+}
+
+func TestValidateRequestLabels(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      map[string]*types.PrometheusRequestLabel
+		expectedErr string
+	}{
+		{
+			desc: "valid",
+			config: map[string]*types.PrometheusRequestLabel{
+				"tenant": {Header: "X-Tenant-ID", MaxValues: 100},
+				"client": {ClientCertCN: true},
+			},
+		},
+		{
+			desc:        "invalid name",
+			config:      map[string]*types.PrometheusRequestLabel{"tenant-id": {Header: "X-Tenant-ID"}},
+			expectedErr: `invalid request label name "tenant-id"`,
+		},
+		{
+			desc:        "reserved name",
+			config:      map[string]*types.PrometheusRequestLabel{"service": {Header: "X-Service"}},
+			expectedErr: `the request label name "service" is reserved`,
+		},
+		{
+			desc:        "no source",
+			config:      map[string]*types.PrometheusRequestLabel{"tenant": {}},
+			expectedErr: `the request label "tenant" must be valued from either a header or the client certificate`,
+		},
+		{
+			desc:        "both sources",
+			config:      map[string]*types.PrometheusRequestLabel{"tenant": {Header: "X-Tenant-ID", ClientCertCN: true}},
+			expectedErr: `the request label "tenant" must be valued from either a header or the client certificate`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateRequestLabels(test.config)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
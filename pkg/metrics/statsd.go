@@ -2,20 +2,32 @@ package metrics
 
 import (
 	"context"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/types"
 	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/dogstatsd"
 	"github.com/go-kit/kit/metrics/statsd"
+	"github.com/go-kit/kit/util/conn"
 )
 
 var (
-	statsdClient *statsd.Statsd
+	statsdClient statsdProvider
 	statsdTicker *time.Ticker
 )
 
+// Formats of the labels of the StatsD metrics.
+const (
+	statsdFormatPlain   = "plain"
+	statsdFormatDatadog = "datadog"
+	statsdFormatInflux  = "influx"
+)
+
 const (
 	statsdMetricsServiceReqsName      = "service.request.total"
 	statsdMetricsServiceLatencyName   = "service.request.duration"
@@ -28,7 +40,11 @@ const (
 	statsdEntryPointReqDurationName   = "entrypoint.request.duration"
 	statsdEntryPointOpenConnsName     = "entrypoint.connections.open"
 	statsdOpenConnsName               = "service.connections.open"
+	statsdRouterReqsName              = "router.request.total"
+	statsdRouterReqDurationName       = "router.request.duration"
+	statsdRouterOpenConnsName         = "router.connections.open"
 	statsdServerUpName                = "service.server.up"
+	statsdHealthCheckDurationName     = "service.healthcheck.duration"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -38,41 +54,147 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		config.Prefix = "traefik"
 	}
 
-	statsdClient = statsd.New(config.Prefix+".", kitlog.LoggerFunc(func(keyvals ...interface{}) error {
-		log.WithoutContext().WithField(log.MetricsProviderName, "statsd").Info(keyvals)
-		return nil
-	}))
+	statsdClient = newStatsdProvider(config)
 
 	if statsdTicker == nil {
 		statsdTicker = initStatsdTicker(ctx, config)
 	}
 
 	registry := &standardRegistry{
-		configReloadsCounter:         statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
-		configReloadsFailureCounter:  statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
+		configReloadsCounter:         statsdClient.NewCounter(statsdConfigReloadsName),
+		configReloadsFailureCounter:  statsdClient.NewCounter(statsdConfigReloadsFailureName),
 		lastConfigReloadSuccessGauge: statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
 		lastConfigReloadFailureGauge: statsdClient.NewGauge(statsdLastConfigReloadFailureName),
 	}
 
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
-		registry.entryPointReqsCounter = statsdClient.NewCounter(statsdEntryPointReqsName, 1.0)
-		registry.entryPointReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdEntryPointReqDurationName, 1.0), time.Millisecond)
+		registry.entryPointReqsCounter = statsdClient.NewCounter(statsdEntryPointReqsName)
+		registry.entryPointReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdEntryPointReqDurationName), time.Millisecond)
 		registry.entryPointOpenConnsGauge = statsdClient.NewGauge(statsdEntryPointOpenConnsName)
 	}
 
+	if config.AddRoutersLabels {
+		registry.routerEnabled = config.AddRoutersLabels
+		registry.routerReqsCounter = statsdClient.NewCounter(statsdRouterReqsName)
+		registry.routerReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdRouterReqDurationName), time.Millisecond)
+		registry.routerOpenConnsGauge = statsdClient.NewGauge(statsdRouterOpenConnsName)
+	}
+
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
-		registry.serviceReqsCounter = statsdClient.NewCounter(statsdMetricsServiceReqsName, 1.0)
-		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdMetricsServiceLatencyName, 1.0), time.Millisecond)
-		registry.serviceRetriesCounter = statsdClient.NewCounter(statsdRetriesTotalName, 1.0)
+		registry.serviceReqsCounter = statsdClient.NewCounter(statsdMetricsServiceReqsName)
+		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdMetricsServiceLatencyName), time.Millisecond)
+		registry.serviceRetriesCounter = statsdClient.NewCounter(statsdRetriesTotalName)
 		registry.serviceOpenConnsGauge = statsdClient.NewGauge(statsdOpenConnsName)
 		registry.serviceServerUpGauge = statsdClient.NewGauge(statsdServerUpName)
+		registry.serviceHealthCheckDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdHealthCheckDurationName), time.Millisecond)
 	}
 
 	return registry
 }
 
+var statsdLogger = kitlog.LoggerFunc(func(keyvals ...interface{}) error {
+	log.WithoutContext().WithField(log.MetricsProviderName, "statsd").Info(keyvals)
+	return nil
+})
+
+// statsdProvider creates the metrics of a StatsD client, and writes them on each tick.
+type statsdProvider interface {
+	NewCounter(name string) metrics.Counter
+	NewGauge(name string) metrics.Gauge
+	NewTiming(name string) metrics.Histogram
+	WriteLoop(ctx context.Context, c <-chan time.Time, w io.Writer)
+}
+
+// newStatsdProvider returns the StatsD client writing the labels in the configured format.
+// The plain StatsD format has no tags, the labels are dropped.
+func newStatsdProvider(config *types.Statsd) statsdProvider {
+	switch config.Format {
+	case statsdFormatDatadog, statsdFormatInflux:
+		return taggedStatsd{client: dogstatsd.New(config.Prefix+".", statsdLogger)}
+	case statsdFormatPlain, "":
+	default:
+		log.WithoutContext().WithField(log.MetricsProviderName, "statsd").
+			Warnf("Unsupported format %s: falling back on %s.", config.Format, statsdFormatPlain)
+	}
+
+	return plainStatsd{client: statsd.New(config.Prefix+".", statsdLogger)}
+}
+
+type plainStatsd struct {
+	client *statsd.Statsd
+}
+
+func (s plainStatsd) NewCounter(name string) metrics.Counter {
+	return s.client.NewCounter(name, 1.0)
+}
+
+func (s plainStatsd) NewGauge(name string) metrics.Gauge {
+	return s.client.NewGauge(name)
+}
+
+func (s plainStatsd) NewTiming(name string) metrics.Histogram {
+	return s.client.NewTiming(name, 1.0)
+}
+
+func (s plainStatsd) WriteLoop(ctx context.Context, c <-chan time.Time, w io.Writer) {
+	s.client.WriteLoop(ctx, c, w)
+}
+
+// taggedStatsd writes the labels as DogStatsD tags: `name:1|c|#tag:value`.
+type taggedStatsd struct {
+	client *dogstatsd.Dogstatsd
+}
+
+func (s taggedStatsd) NewCounter(name string) metrics.Counter {
+	return s.client.NewCounter(name, 1.0)
+}
+
+func (s taggedStatsd) NewGauge(name string) metrics.Gauge {
+	return s.client.NewGauge(name)
+}
+
+func (s taggedStatsd) NewTiming(name string) metrics.Histogram {
+	return s.client.NewTiming(name, 1.0)
+}
+
+func (s taggedStatsd) WriteLoop(ctx context.Context, c <-chan time.Time, w io.Writer) {
+	s.client.WriteLoop(ctx, c, w)
+}
+
+// influxTagsWriter rewrites the DogStatsD lines in the InfluxDB StatsD format understood by Telegraf,
+// where the tags follow the metric name: `name,tag=value:1|c`.
+// The DogStatsD client writes each line on its own.
+type influxTagsWriter struct {
+	w io.Writer
+}
+
+func (i influxTagsWriter) Write(p []byte) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+
+	index := strings.LastIndex(line, "|#")
+	nameEnd := strings.Index(line, ":")
+	if index < 0 || nameEnd < 0 || nameEnd > index {
+		return i.w.Write(p)
+	}
+
+	var b strings.Builder
+	b.WriteString(line[:nameEnd])
+	for _, tag := range strings.Split(line[index+2:], ",") {
+		b.WriteString(",")
+		b.WriteString(strings.Replace(tag, ":", "=", 1))
+	}
+	b.WriteString(line[nameEnd:index])
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(i.w, b.String()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
 // initStatsdTicker initializes metrics pusher and creates a statsdClient if not created already.
 func initStatsdTicker(ctx context.Context, config *types.Statsd) *time.Ticker {
 	address := config.Address
@@ -82,8 +204,13 @@ func initStatsdTicker(ctx context.Context, config *types.Statsd) *time.Ticker {
 
 	report := time.NewTicker(time.Duration(config.PushInterval))
 
+	var w io.Writer = conn.NewDefaultManager("udp", address, statsdLogger)
+	if config.Format == statsdFormatInflux {
+		w = influxTagsWriter{w: w}
+	}
+
 	safe.Go(func() {
-		statsdClient.SendLoop(ctx, report.C, "udp", address)
+		statsdClient.WriteLoop(ctx, report.C, w)
 	})
 
 	return report
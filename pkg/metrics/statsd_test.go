@@ -17,10 +17,10 @@ func TestStatsD(t *testing.T) {
 	// This is needed to make sure that UDP Listener listens for data a bit longer, otherwise it will quit after a millisecond
 	udp.Timeout = 5 * time.Second
 
-	statsdRegistry := RegisterStatsd(context.Background(), &types.Statsd{Address: ":18125", PushInterval: ptypes.Duration(time.Second), AddEntryPointsLabels: true, AddServicesLabels: true})
+	statsdRegistry := RegisterStatsd(context.Background(), &types.Statsd{Address: ":18125", PushInterval: ptypes.Duration(time.Second), AddEntryPointsLabels: true, AddRoutersLabels: true, AddServicesLabels: true})
 	defer StopStatsd()
 
-	if !statsdRegistry.IsEpEnabled() || !statsdRegistry.IsSvcEnabled() {
+	if !statsdRegistry.IsEpEnabled() || !statsdRegistry.IsRouterEnabled() || !statsdRegistry.IsSvcEnabled() {
 		t.Errorf("Statsd registry should return true for IsEnabled()")
 	}
 
@@ -34,6 +34,9 @@ func TestStatsD(t *testing.T) {
 		"traefik.entrypoint.request.total:1.000000|c\n",
 		"traefik.entrypoint.request.duration:10000.000000|ms",
 		"traefik.entrypoint.connections.open:1.000000|g\n",
+		"traefik.router.request.total:1.000000|c\n",
+		"traefik.router.request.duration:10000.000000|ms",
+		"traefik.router.connections.open:1.000000|g\n",
 		"traefik.service.server.up:1.000000|g\n",
 	}
 
@@ -48,6 +51,9 @@ func TestStatsD(t *testing.T) {
 		statsdRegistry.EntryPointReqsCounter().With("entrypoint", "test").Add(1)
 		statsdRegistry.EntryPointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
 		statsdRegistry.EntryPointOpenConnsGauge().With("entrypoint", "test").Set(1)
+		statsdRegistry.RouterReqsCounter().With("router", "demo", "service", "test", "code", strconv.Itoa(http.StatusOK)).Add(1)
+		statsdRegistry.RouterReqDurationHistogram().With("router", "demo", "service", "test", "code", strconv.Itoa(http.StatusOK)).Observe(10000)
+		statsdRegistry.RouterOpenConnsGauge().With("router", "demo", "service", "test").Set(1)
 		statsdRegistry.ServiceServerUpGauge().With("service:test", "url", "http://127.0.0.1").Set(1)
 	})
 }
@@ -91,3 +97,50 @@ func TestStatsDWithPrefix(t *testing.T) {
 		statsdRegistry.ServiceServerUpGauge().With("service:test", "url", "http://127.0.0.1").Set(1)
 	})
 }
+
+func TestStatsDWithFormat(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		format   string
+		expected []string
+	}{
+		{
+			desc:   "datadog",
+			format: "datadog",
+			expected: []string{
+				"traefik.service.request.total:1.000000|c|#service:test,code:200\n",
+				"traefik.service.request.duration:10000.000000|ms|#service:test,code:200\n",
+				"traefik.entrypoint.connections.open:1.000000|g|#entrypoint:test\n",
+				"traefik.service.server.up:1.000000|g|#service:test,url:http://127.0.0.1\n",
+			},
+		},
+		{
+			desc:   "influx",
+			format: "influx",
+			expected: []string{
+				"traefik.service.request.total,service=test,code=200:1.000000|c\n",
+				"traefik.service.request.duration,service=test,code=200:10000.000000|ms\n",
+				"traefik.entrypoint.connections.open,entrypoint=test:1.000000|g\n",
+				"traefik.service.server.up,service=test,url=http://127.0.0.1:1.000000|g\n",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			udp.SetAddr(":18125")
+			// This is needed to make sure that UDP Listener listens for data a bit longer, otherwise it will quit after a millisecond
+			udp.Timeout = 5 * time.Second
+
+			statsdRegistry := RegisterStatsd(context.Background(), &types.Statsd{Address: ":18125", PushInterval: ptypes.Duration(time.Second), AddEntryPointsLabels: true, AddServicesLabels: true, Format: test.format})
+			defer StopStatsd()
+
+			udp.ShouldReceiveAll(t, test.expected, func() {
+				statsdRegistry.ServiceReqsCounter().With("service", "test", "code", strconv.Itoa(http.StatusOK)).Add(1)
+				statsdRegistry.ServiceReqDurationHistogram().With("service", "test", "code", strconv.Itoa(http.StatusOK)).Observe(10000)
+				statsdRegistry.EntryPointOpenConnsGauge().With("entrypoint", "test").Set(1)
+				statsdRegistry.ServiceServerUpGauge().With("service", "test", "url", "http://127.0.0.1").Set(1)
+			})
+		})
+	}
+}
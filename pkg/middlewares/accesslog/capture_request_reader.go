@@ -7,11 +7,14 @@ type captureRequestReader struct {
 	source io.ReadCloser
 	// count Counts the number of bytes read (when captureRequestReader.Read is called).
 	count int64
+	// body captures the beginning of the body, when the bodies are captured.
+	body *capturedBody
 }
 
 func (r *captureRequestReader) Read(p []byte) (int, error) {
 	n, err := r.source.Read(p)
 	r.count += int64(n)
+	r.body.write(p[:n])
 	return n, err
 }
 
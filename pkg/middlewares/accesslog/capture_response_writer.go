@@ -17,8 +17,8 @@ type capturer interface {
 	Status() int
 }
 
-func newCaptureResponseWriter(rw http.ResponseWriter) capturer {
-	capt := &captureResponseWriter{rw: rw}
+func newCaptureResponseWriter(rw http.ResponseWriter, body *capturedBody) capturer {
+	capt := &captureResponseWriter{rw: rw, body: body}
 	if _, ok := rw.(http.CloseNotifier); !ok {
 		return capt
 	}
@@ -31,6 +31,7 @@ type captureResponseWriter struct {
 	rw     http.ResponseWriter
 	status int
 	size   int64
+	body   *capturedBody
 }
 
 type captureResponseWriterWithCloseNotify struct {
@@ -53,6 +54,7 @@ func (crw *captureResponseWriter) Write(b []byte) (int, error) {
 	}
 	size, err := crw.rw.Write(b)
 	crw.size += int64(size)
+	crw.body.write(b[:size])
 	return size, err
 }
 
@@ -42,7 +42,7 @@ func TestCloseNotifier(t *testing.T) {
 			_, ok := test.rw.(http.CloseNotifier)
 			assert.Equal(t, test.implementsCloseNotifier, ok)
 
-			rw := newCaptureResponseWriter(test.rw)
+			rw := newCaptureResponseWriter(test.rw, nil)
 			_, impl := rw.(http.CloseNotifier)
 			assert.Equal(t, test.implementsCloseNotifier, impl)
 		})
@@ -0,0 +1,310 @@
+package accesslog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultClickHouseQueueSize is the size of the queue when none is configured,
+// an unbuffered queue dropping every access log the sink is not ready to receive.
+const defaultClickHouseQueueSize = 10000
+
+type clickHouseColumn struct {
+	name       string
+	columnType string
+}
+
+// clickHouseColumns are the access log fields stored in their own column,
+// the other fields are stored in the Fields column.
+var clickHouseColumns = []clickHouseColumn{
+	{name: StartUTC, columnType: "DateTime64(9, 'UTC')"},
+	{name: Duration, columnType: "Int64"},
+	{name: log.EntryPointName, columnType: "LowCardinality(String)"},
+	{name: RouterName, columnType: "LowCardinality(String)"},
+	{name: ServiceName, columnType: "LowCardinality(String)"},
+	{name: ServiceURL, columnType: "String"},
+	{name: ClientHost, columnType: "String"},
+	{name: RequestHost, columnType: "String"},
+	{name: RequestMethod, columnType: "LowCardinality(String)"},
+	{name: RequestPath, columnType: "String"},
+	{name: RequestProtocol, columnType: "LowCardinality(String)"},
+	{name: RequestContentSize, columnType: "Int64"},
+	{name: OriginStatus, columnType: "UInt16"},
+	{name: OriginDuration, columnType: "Int64"},
+	{name: DownstreamStatus, columnType: "UInt16"},
+	{name: DownstreamContentSize, columnType: "Int64"},
+	{name: RetryAttempts, columnType: "UInt32"},
+}
+
+// clickHouseConn is the part of the ClickHouse connection used by the sink.
+type clickHouseConn interface {
+	Exec(ctx context.Context, query string, args ...interface{}) error
+	PrepareBatch(ctx context.Context, query string) (driver.Batch, error)
+	Close() error
+}
+
+// clickHouseSink is a logrus hook inserting the access logs into ClickHouse, in batches, through its native protocol.
+// When the insertions cannot keep up, the queue fills up and the access logs exceeding it are dropped,
+// so that the requests are never slowed down by the sink.
+type clickHouseSink struct {
+	config *types.ClickHouse
+	conn   clickHouseConn
+	queue  chan []interface{}
+
+	dropped uint64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newClickHouseSink(config *types.ClickHouse) (*clickHouseSink, error) {
+	conn, err := clickhouse.Open(&clickhouse.Options{
+		Addr: strings.Split(config.Address, ","),
+		Auth: clickhouse.Auth{
+			Database: config.Database,
+			Username: config.Username,
+			Password: config.Password,
+		},
+		DialTimeout: 5 * time.Second,
+		Compression: &clickhouse.Compression{Method: clickhouse.CompressionLZ4},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to ClickHouse: %w", err)
+	}
+
+	sink, err := newClickHouseSinkWithConn(config, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func newClickHouseSinkWithConn(config *types.ClickHouse, conn clickHouseConn) (*clickHouseSink, error) {
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultClickHouseQueueSize
+	}
+
+	sink := &clickHouseSink{
+		config: config,
+		conn:   conn,
+		queue:  make(chan []interface{}, queueSize),
+	}
+
+	if config.CreateTable {
+		if err := sink.createTable(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sink.cancel = cancel
+
+	sink.wg.Add(1)
+	go func() {
+		defer sink.wg.Done()
+		sink.run(ctx, batchSize)
+	}()
+
+	return sink, nil
+}
+
+// Levels implements logrus.Hook.
+func (s *clickHouseSink) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (s *clickHouseSink) Fire(entry *logrus.Entry) error {
+	row := clickHouseRow(entry.Data)
+
+	select {
+	case s.queue <- row:
+	default:
+		if atomic.AddUint64(&s.dropped, 1) == 1 {
+			log.WithoutContext().Warn("The ClickHouse access log queue is full, dropping access logs")
+		}
+	}
+
+	return nil
+}
+
+// Close inserts the queued access logs and stops the sink.
+func (s *clickHouseSink) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return s.conn.Close()
+}
+
+func (s *clickHouseSink) run(ctx context.Context, batchSize int) {
+	interval := time.Duration(s.config.FlushInterval)
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([][]interface{}, 0, batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := s.insert(batch); err != nil {
+			log.WithoutContext().Errorf("Unable to insert %d access logs into ClickHouse: %v", len(batch), err)
+		}
+
+		if dropped := atomic.SwapUint64(&s.dropped, 0); dropped > 0 {
+			log.WithoutContext().Warnf("%d access logs were dropped because the ClickHouse access log queue was full", dropped)
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case row := <-s.queue:
+					batch = append(batch, row)
+					if len(batch) >= batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case <-ticker.C:
+			flush()
+		case row := <-s.queue:
+			batch = append(batch, row)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		}
+	}
+}
+
+func (s *clickHouseSink) insert(rows [][]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batch, err := s.conn.PrepareBatch(ctx, "INSERT INTO "+s.tableName())
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := batch.Append(row...); err != nil {
+			_ = batch.Abort()
+			return err
+		}
+	}
+
+	return batch.Send()
+}
+
+func (s *clickHouseSink) createTable() error {
+	var columns []string
+	for _, column := range clickHouseColumns {
+		columns = append(columns, fmt.Sprintf("`%s` %s", column.name, column.columnType))
+	}
+	columns = append(columns, "`Fields` Map(String, String)")
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = MergeTree ORDER BY `%s`",
+		s.tableName(), strings.Join(columns, ", "), StartUTC)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("unable to create the ClickHouse access log table: %w", err)
+	}
+
+	return nil
+}
+
+func (s *clickHouseSink) tableName() string {
+	return fmt.Sprintf("`%s`.`%s`", s.config.Database, s.config.Table)
+}
+
+// clickHouseRow converts the access log fields to the values of the columns of the table,
+// in the order of clickHouseColumns, followed by the Fields column.
+func clickHouseRow(data logrus.Fields) []interface{} {
+	row := make([]interface{}, 0, len(clickHouseColumns)+1)
+	fields := make(map[string]string)
+
+	for k, v := range data {
+		fields[k] = fmt.Sprint(v)
+	}
+
+	for _, column := range clickHouseColumns {
+		v, ok := data[column.name]
+		if ok {
+			delete(fields, column.name)
+		}
+
+		row = append(row, clickHouseValue(column.columnType, v))
+	}
+
+	return append(row, fields)
+}
+
+// clickHouseValue converts an access log field to the Go type of its column,
+// a missing field being stored as the zero value of the column.
+func clickHouseValue(columnType string, v interface{}) interface{} {
+	switch columnType {
+	case "DateTime64(9, 'UTC')":
+		t, _ := v.(time.Time)
+		return t.UTC()
+	case "Int64":
+		return toInt64(v)
+	case "UInt16":
+		return uint16(toInt64(v))
+	case "UInt32":
+		return uint32(toInt64(v))
+	default:
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+func toInt64(v interface{}) int64 {
+	switch value := v.(type) {
+	case time.Duration:
+		return int64(value)
+	case int:
+		return int64(value)
+	case int64:
+		return value
+	case uint64:
+		return int64(value)
+	case float64:
+		return int64(value)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,163 @@
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+type clickHouseConnMock struct {
+	mu      sync.Mutex
+	execErr error
+	queries []string
+	batches [][][]interface{}
+	closed  bool
+}
+
+func (c *clickHouseConnMock) Exec(_ context.Context, query string, _ ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queries = append(c.queries, query)
+	return c.execErr
+}
+
+func (c *clickHouseConnMock) PrepareBatch(_ context.Context, query string) (driver.Batch, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queries = append(c.queries, query)
+	return &clickHouseBatchMock{conn: c}, nil
+}
+
+func (c *clickHouseConnMock) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+type clickHouseBatchMock struct {
+	conn *clickHouseConnMock
+	rows [][]interface{}
+}
+
+func (b *clickHouseBatchMock) Abort() error { return nil }
+
+func (b *clickHouseBatchMock) Append(v ...interface{}) error {
+	b.rows = append(b.rows, v)
+	return nil
+}
+
+func (b *clickHouseBatchMock) AppendStruct(interface{}) error { return errors.New("not implemented") }
+
+func (b *clickHouseBatchMock) Column(int) driver.BatchColumn { return nil }
+
+func (b *clickHouseBatchMock) Send() error {
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+
+	b.conn.batches = append(b.conn.batches, b.rows)
+	return nil
+}
+
+func TestClickHouseSink(t *testing.T) {
+	conn := &clickHouseConnMock{}
+
+	sink, err := newClickHouseSinkWithConn(&types.ClickHouse{
+		Database:      "default",
+		Table:         "logs",
+		CreateTable:   true,
+		BatchSize:     2,
+		FlushInterval: ptypes.Duration(time.Hour),
+		QueueSize:     10,
+	}, conn)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		err = sink.Fire(&logrus.Entry{Data: logrus.Fields{
+			StartUTC:           time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Duration:           123 * time.Millisecond,
+			log.EntryPointName: "web",
+			DownstreamStatus:   http.StatusTeapot,
+			RequestMethod:      http.MethodGet,
+			RequestPath:        "/baz",
+			ClientAddr:         "10.0.0.1:1234",
+		}})
+		require.NoError(t, err)
+	}
+
+	// Closing the sink inserts the last access log, outside of a full batch.
+	require.NoError(t, sink.Close())
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	assert.True(t, conn.closed)
+
+	require.Len(t, conn.queries, 3)
+	assert.Contains(t, conn.queries[0], "CREATE TABLE IF NOT EXISTS `default`.`logs`")
+	assert.Equal(t, "INSERT INTO `default`.`logs`", conn.queries[1])
+	assert.Equal(t, "INSERT INTO `default`.`logs`", conn.queries[2])
+
+	require.Len(t, conn.batches, 2)
+	assert.Len(t, conn.batches[0], 2)
+	assert.Len(t, conn.batches[1], 1)
+
+	row := conn.batches[0][0]
+	require.Len(t, row, len(clickHouseColumns)+1)
+
+	values := make(map[string]interface{})
+	for i, column := range clickHouseColumns {
+		values[column.name] = row[i]
+	}
+
+	assert.Equal(t, time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC), values[StartUTC])
+	assert.Equal(t, int64(123*time.Millisecond), values[Duration])
+	assert.Equal(t, "web", values[log.EntryPointName])
+	assert.Equal(t, uint16(http.StatusTeapot), values[DownstreamStatus])
+	assert.Equal(t, "GET", values[RequestMethod])
+	assert.Equal(t, "/baz", values[RequestPath])
+	assert.Equal(t, "", values[RouterName])
+	assert.Equal(t, uint16(0), values[OriginStatus])
+
+	fields := row[len(clickHouseColumns)]
+	assert.Equal(t, map[string]string{ClientAddr: "10.0.0.1:1234"}, fields)
+}
+
+func TestClickHouseSinkCreateTableError(t *testing.T) {
+	conn := &clickHouseConnMock{execErr: errors.New("code: 516, message: authentication failed")}
+
+	_, err := newClickHouseSinkWithConn(&types.ClickHouse{
+		Database:    "default",
+		Table:       "logs",
+		CreateTable: true,
+	}, conn)
+	assert.Error(t, err)
+}
+
+func TestClickHouseSinkDefaultQueueSize(t *testing.T) {
+	sink, err := newClickHouseSinkWithConn(&types.ClickHouse{
+		Database:      "default",
+		Table:         "logs",
+		BatchSize:     1000,
+		FlushInterval: ptypes.Duration(time.Hour),
+	}, &clickHouseConnMock{})
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultClickHouseQueueSize, cap(sink.queue))
+
+	require.NoError(t, sink.Close())
+}
@@ -0,0 +1,120 @@
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/geoip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// anonymityFlags are the flags of the anonymous IP databases, reported in the ClientProxy field.
+var anonymityFlags = []string{
+	"is_anonymous",
+	"is_anonymous_vpn",
+	"is_hosting_provider",
+	"is_public_proxy",
+	"is_residential_proxy",
+	"is_tor_exit_node",
+}
+
+// geoIPEnricher enriches the access logs with the data of the MaxMind databases about the client IPs.
+type geoIPEnricher struct {
+	country   *geoip.Database
+	asn       *geoip.Database
+	anonymous *geoip.Database
+
+	cancel context.CancelFunc
+}
+
+func newGeoIPEnricher(config *types.AccessLogGeoIP) (*geoIPEnricher, error) {
+	e := &geoIPEnricher{}
+
+	var err error
+	if e.country, err = openDatabase(config.CountryDatabase); err != nil {
+		return nil, err
+	}
+	if e.asn, err = openDatabase(config.ASNDatabase); err != nil {
+		return nil, err
+	}
+	if e.anonymous, err = openDatabase(config.AnonymousIPDatabase); err != nil {
+		return nil, err
+	}
+
+	if e.country == nil && e.asn == nil && e.anonymous == nil {
+		return nil, errors.New("no MaxMind database defined")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	if interval := time.Duration(config.ReloadInterval); interval > 0 {
+		for _, db := range []*geoip.Database{e.country, e.asn, e.anonymous} {
+			if db != nil {
+				go db.Watch(ctx, interval)
+			}
+		}
+	}
+
+	return e, nil
+}
+
+func openDatabase(path string) (*geoip.Database, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	return geoip.Open(path)
+}
+
+// enrich adds the fields about the client IP to the core data.
+// The client IP is the first one of the X-Forwarded-For header when present, as it is in the ClientHost field.
+func (e *geoIPEnricher) enrich(core CoreLogData) {
+	host, _ := core[ClientHost].(string)
+	ip := net.ParseIP(strings.TrimSpace(strings.Split(host, ",")[0]))
+	if ip == nil {
+		return
+	}
+
+	logger := log.WithoutContext()
+
+	if e.country != nil {
+		if value, ok, err := e.country.Lookup(ip, "country", "iso_code"); err != nil {
+			logger.Debugf("Unable to look up the country of %s: %v", ip, err)
+		} else if ok {
+			core[ClientCountry] = value
+		}
+	}
+
+	if e.asn != nil {
+		if value, ok, err := e.asn.Lookup(ip, "autonomous_system_number"); err != nil {
+			logger.Debugf("Unable to look up the ASN of %s: %v", ip, err)
+		} else if ok {
+			core[ClientASN] = value
+		}
+		if value, ok, err := e.asn.Lookup(ip, "autonomous_system_organization"); err == nil && ok {
+			core[ClientASOrganization] = value
+		}
+	}
+
+	if e.anonymous != nil {
+		var flags []string
+		for _, flag := range anonymityFlags {
+			if value, ok, err := e.anonymous.Lookup(ip, flag); err == nil && ok && value == true {
+				flags = append(flags, flag)
+			}
+		}
+		if len(flags) > 0 {
+			core[ClientProxy] = strings.Join(flags, ",")
+		}
+	}
+}
+
+// Close stops the reloading of the databases.
+func (e *geoIPEnricher) Close() {
+	e.cancel()
+}
@@ -30,6 +30,15 @@ const (
 	ClientPort = "ClientPort"
 	// ClientUsername is the map key used for the username provided in the URL, if present.
 	ClientUsername = "ClientUsername"
+	// ClientCountry is the map key used for the ISO code of the country of the client IP, when the GeoIP enrichment is enabled.
+	ClientCountry = "ClientCountry"
+	// ClientASN is the map key used for the autonomous system number of the client IP, when the GeoIP enrichment is enabled.
+	ClientASN = "ClientASN"
+	// ClientASOrganization is the map key used for the organization of the autonomous system of the client IP, when the GeoIP enrichment is enabled.
+	ClientASOrganization = "ClientASOrganization"
+	// ClientProxy is the map key used for the comma-separated anonymity flags of the client IP (e.g. is_anonymous_vpn,is_tor_exit_node),
+	// when the GeoIP enrichment is enabled.
+	ClientProxy = "ClientProxy"
 	// RequestAddr is the map key used for the HTTP Host header (usually IP:port). This is treated as not a header by the Go API.
 	RequestAddr = "RequestAddr"
 	// RequestHost is the map key used for the HTTP Host server name (not including port).
@@ -70,6 +79,10 @@ const (
 	Overhead = "Overhead"
 	// RetryAttempts is the map key used for the amount of attempts the request was retried.
 	RetryAttempts = "RetryAttempts"
+	// RequestBody is the map key used for the redacted request body, when the bodies are captured.
+	RequestBody = "RequestBody"
+	// DownstreamBody is the map key used for the redacted response body returned to the client, when the bodies are captured.
+	DownstreamBody = "DownstreamBody"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -111,6 +124,12 @@ func init() {
 	allCoreKeys[StartLocal] = struct{}{}
 	allCoreKeys[Overhead] = struct{}{}
 	allCoreKeys[RetryAttempts] = struct{}{}
+	allCoreKeys[RequestBody] = struct{}{}
+	allCoreKeys[DownstreamBody] = struct{}{}
+	allCoreKeys[ClientCountry] = struct{}{}
+	allCoreKeys[ClientASN] = struct{}{}
+	allCoreKeys[ClientASOrganization] = struct{}{}
+	allCoreKeys[ClientProxy] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
@@ -128,10 +147,12 @@ type downstreamResponse struct {
 	headers http.Header
 	status  int
 	size    int64
+	body    *capturedBody
 }
 
 type request struct {
 	headers http.Header
 	// Request body size
 	size int64
+	body *capturedBody
 }
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -47,6 +48,16 @@ func (n noopCloser) Close() error {
 	return nil
 }
 
+type discardCloser struct{}
+
+func (discardCloser) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (discardCloser) Close() error {
+	return nil
+}
+
 type handlerParams struct {
 	logDataTable *LogData
 }
@@ -60,6 +71,12 @@ type Handler struct {
 	httpCodeRanges types.HTTPCodeRanges
 	logHandlerChan chan handlerParams
 	wg             sync.WaitGroup
+	clickHouse     *clickHouseSink
+	redactor       *redactor
+	geoIP          *geoIPEnricher
+	// requestHeaders and responseHeaders are the captured headers, replacing the header modes when not nil.
+	requestHeaders  *types.HeaderCapture
+	responseHeaders *types.HeaderCapture
 }
 
 // WrapHandler Wraps access log handler into an Alice Constructor.
@@ -74,6 +91,10 @@ func WrapHandler(handler *Handler) alice.Constructor {
 // NewHandler creates a new Handler.
 func NewHandler(config *types.AccessLog) (*Handler, error) {
 	var file io.WriteCloser = noopCloser{os.Stdout}
+	if config.ClickHouse != nil && len(config.FilePath) == 0 {
+		// The access logs only go to ClickHouse.
+		file = discardCloser{}
+	}
 	if len(config.FilePath) > 0 {
 		f, err := openAccessLogFile(config.FilePath)
 		if err != nil {
@@ -120,6 +141,44 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		logHandlerChan: logHandlerChan,
 	}
 
+	if config.Fields != nil && config.Fields.Headers != nil {
+		var err error
+		logHandler.requestHeaders, err = newHeaderCapture(config.Fields.Headers.Request)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring the captured request headers: %w", err)
+		}
+
+		logHandler.responseHeaders, err = newHeaderCapture(config.Fields.Headers.Response)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring the captured response headers: %w", err)
+		}
+	}
+
+	if config.ClickHouse != nil {
+		sink, err := newClickHouseSink(config.ClickHouse)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the ClickHouse access log sink: %w", err)
+		}
+		logger.AddHook(sink)
+		logHandler.clickHouse = sink
+	}
+
+	if config.Bodies != nil {
+		r, err := newRedactor(config.Bodies.Redaction)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the body redaction: %w", err)
+		}
+		logHandler.redactor = r
+	}
+
+	if config.GeoIP != nil {
+		enricher, err := newGeoIPEnricher(config.GeoIP)
+		if err != nil {
+			return nil, fmt.Errorf("error opening the GeoIP databases: %w", err)
+		}
+		logHandler.geoIP = enricher
+	}
+
 	if config.Filters != nil {
 		if httpCodeRanges, err := types.NewHTTPCodeRanges(config.Filters.StatusCodes); err != nil {
 			log.WithoutContext().Errorf("Failed to create new HTTP code ranges: %s", err)
@@ -185,6 +244,9 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 	var crr *captureRequestReader
 	if req.Body != nil {
 		crr = &captureRequestReader{source: req.Body, count: 0}
+		if h.redactor != nil {
+			crr.body = newCapturedBody(h.config.Bodies.MaxSize)
+		}
 		reqWithDataTable.Body = crr
 	}
 
@@ -218,7 +280,12 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		core[ClientHost] = forwardedFor
 	}
 
-	crw := newCaptureResponseWriter(rw)
+	var downstreamBody *capturedBody
+	if h.redactor != nil {
+		downstreamBody = newCapturedBody(h.config.Bodies.MaxSize)
+	}
+
+	crw := newCaptureResponseWriter(rw, downstreamBody)
 
 	next.ServeHTTP(crw, reqWithDataTable)
 
@@ -230,9 +297,11 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		headers: crw.Header().Clone(),
 		status:  crw.Status(),
 		size:    crw.Size(),
+		body:    downstreamBody,
 	}
 	if crr != nil {
 		logDataTable.Request.size = crr.count
+		logDataTable.Request.body = crr.body
 	}
 
 	if h.config.BufferingSize > 0 {
@@ -248,6 +317,17 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 func (h *Handler) Close() error {
 	close(h.logHandlerChan)
 	h.wg.Wait()
+
+	if h.geoIP != nil {
+		h.geoIP.Close()
+	}
+
+	if h.clickHouse != nil {
+		if err := h.clickHouse.Close(); err != nil {
+			return err
+		}
+	}
+
 	return h.file.Close()
 }
 
@@ -322,6 +402,20 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData) {
 			core[Overhead] = totalDuration - origin.(time.Duration)
 		}
 
+		if h.redactor != nil {
+			// The bodies are redacted before anything is written, be it to the file or to ClickHouse.
+			if body := h.redactor.redact(logDataTable.Request.body, logDataTable.Request.headers.Get("Content-Type")); body != "" {
+				core[RequestBody] = body
+			}
+			if body := h.redactor.redact(logDataTable.DownstreamResponse.body, logDataTable.DownstreamResponse.headers.Get("Content-Type")); body != "" {
+				core[DownstreamBody] = body
+			}
+		}
+
+		if h.geoIP != nil {
+			h.geoIP.enrich(core)
+		}
+
 		fields := logrus.Fields{}
 
 		for k, v := range logDataTable.Core {
@@ -330,9 +424,9 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData) {
 			}
 		}
 
-		h.redactHeaders(logDataTable.Request.headers, fields, "request_")
-		h.redactHeaders(logDataTable.OriginResponse, fields, "origin_")
-		h.redactHeaders(logDataTable.DownstreamResponse.headers, fields, "downstream_")
+		h.logHeaders(logDataTable.Request.headers, fields, "request_", h.requestHeaders)
+		h.logHeaders(logDataTable.OriginResponse, fields, "origin_", h.responseHeaders)
+		h.logHeaders(logDataTable.DownstreamResponse.headers, fields, "downstream_", h.responseHeaders)
 
 		h.mu.Lock()
 		defer h.mu.Unlock()
@@ -340,6 +434,24 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData) {
 	}
 }
 
+// logHeaders adds the headers to the fields, the captured ones if capture is not nil,
+// or else according to their modes.
+func (h *Handler) logHeaders(headers http.Header, fields logrus.Fields, prefix string, capture *types.HeaderCapture) {
+	if capture == nil {
+		h.redactHeaders(headers, fields, prefix)
+		return
+	}
+
+	for _, name := range capture.Names {
+		values := headers[name]
+		if len(values) == 0 {
+			continue
+		}
+
+		fields[prefix+name] = captureHeaderValue(values, capture)
+	}
+}
+
 func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, prefix string) {
 	for k := range headers {
 		v := h.config.Fields.KeepHeader(k)
@@ -351,6 +463,49 @@ func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, prefi
 	}
 }
 
+// newHeaderCapture returns a copy of the header capture, with the canonical form of the header names.
+func newHeaderCapture(config *types.HeaderCapture) (*types.HeaderCapture, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	switch config.MultiValue {
+	case "", types.HeaderMultiValueFirst, types.HeaderMultiValueLast, types.HeaderMultiValueJoin:
+	default:
+		return nil, fmt.Errorf("unsupported multi-value handling: %q", config.MultiValue)
+	}
+
+	if config.MaxLength < 0 {
+		return nil, fmt.Errorf("negative maximum length: %d", config.MaxLength)
+	}
+
+	capture := &types.HeaderCapture{MaxLength: config.MaxLength, MultiValue: config.MultiValue}
+	for _, name := range config.Names {
+		capture.Names = append(capture.Names, textproto.CanonicalMIMEHeaderKey(name))
+	}
+
+	return capture, nil
+}
+
+// captureHeaderValue returns the value captured from the values of a header, truncated to the maximum length.
+func captureHeaderValue(values []string, capture *types.HeaderCapture) string {
+	var value string
+	switch capture.MultiValue {
+	case types.HeaderMultiValueLast:
+		value = values[len(values)-1]
+	case types.HeaderMultiValueJoin:
+		value = strings.Join(values, ", ")
+	default:
+		value = values[0]
+	}
+
+	if capture.MaxLength > 0 && len(value) > capture.MaxLength {
+		value = value[:capture.MaxLength]
+	}
+
+	return value
+}
+
 func (h *Handler) keepAccessLog(statusCode, retryAttempts int, duration time.Duration) bool {
 	if h.config.Filters == nil {
 		// no filters were specified
@@ -800,3 +800,180 @@ func logWriterTestHandlerFunc(rw http.ResponseWriter, r *http.Request) {
 
 	rw.WriteHeader(testStatus)
 }
+
+func TestLoggerGeoIP(t *testing.T) {
+	fixtures := filepath.Join("..", "..", "geoip", "testdata")
+
+	testCases := []struct {
+		desc         string
+		forwardedFor string
+		expected     map[string]interface{}
+		absent       []string
+	}{
+		{
+			desc:         "country and anonymity flags",
+			forwardedFor: "81.2.69.160, 10.0.0.2",
+			expected: map[string]interface{}{
+				ClientCountry: "GB",
+				ClientProxy:   "is_anonymous,is_anonymous_vpn,is_hosting_provider,is_public_proxy,is_residential_proxy,is_tor_exit_node",
+			},
+			absent: []string{ClientASN, ClientASOrganization},
+		},
+		{
+			desc:         "autonomous system",
+			forwardedFor: "1.128.0.1",
+			expected: map[string]interface{}{
+				ClientASN:            float64(1221),
+				ClientASOrganization: "Telstra Pty Ltd",
+			},
+			absent: []string{ClientCountry, ClientProxy},
+		},
+		{
+			desc:         "unknown IP",
+			forwardedFor: "192.0.2.10",
+			absent:       []string{ClientCountry, ClientASN, ClientASOrganization, ClientProxy},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			geoIP := &types.AccessLogGeoIP{
+				CountryDatabase:     filepath.Join(fixtures, "GeoIP2-Country-Test.mmdb"),
+				ASNDatabase:         filepath.Join(fixtures, "GeoLite2-ASN-Test.mmdb"),
+				AnonymousIPDatabase: filepath.Join(fixtures, "GeoIP2-Anonymous-IP-Test.mmdb"),
+			}
+			geoIP.SetDefaults()
+
+			logFilePath := filepath.Join(createTempDir(t, "geoip"), "access.log")
+			config := &types.AccessLog{FilePath: logFilePath, Format: JSONFormat, GeoIP: geoIP}
+			config.Fields = &types.AccessLogFields{}
+			config.Fields.SetDefaults()
+
+			logger, err := NewHandler(config)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", test.forwardedFor)
+
+			logger.ServeHTTP(httptest.NewRecorder(), req, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+			require.NoError(t, logger.Close())
+
+			logData, err := ioutil.ReadFile(logFilePath)
+			require.NoError(t, err)
+
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(logData, &fields))
+
+			for name, value := range test.expected {
+				assert.Equal(t, value, fields[name], name)
+			}
+			for _, name := range test.absent {
+				assert.NotContains(t, fields, name)
+			}
+		})
+	}
+}
+
+func TestNewHandler_GeoIPInvalidDatabase(t *testing.T) {
+	path := filepath.Join(createTempDir(t, "geoip"), "invalid.mmdb")
+	require.NoError(t, ioutil.WriteFile(path, []byte("invalid"), 0o600))
+
+	_, err := NewHandler(&types.AccessLog{GeoIP: &types.AccessLogGeoIP{CountryDatabase: path}})
+	assert.Error(t, err)
+}
+
+func TestLoggerHeaderCapture(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		request  *types.HeaderCapture
+		response *types.HeaderCapture
+		expected map[string]interface{}
+		absent   []string
+	}{
+		{
+			desc:     "first value",
+			request:  &types.HeaderCapture{Names: []string{"user-agent", "X-Request-Id"}, MultiValue: types.HeaderMultiValueFirst},
+			expected: map[string]interface{}{"request_User-Agent": "agent", "request_X-Request-Id": "first"},
+			absent:   []string{"request_Authorization"},
+		},
+		{
+			desc:     "last value",
+			request:  &types.HeaderCapture{Names: []string{"X-Request-Id"}, MultiValue: types.HeaderMultiValueLast},
+			expected: map[string]interface{}{"request_X-Request-Id": "second"},
+			absent:   []string{"request_User-Agent"},
+		},
+		{
+			desc:     "joined values",
+			request:  &types.HeaderCapture{Names: []string{"X-Request-Id"}, MultiValue: types.HeaderMultiValueJoin},
+			response: &types.HeaderCapture{},
+			expected: map[string]interface{}{"request_X-Request-Id": "first, second"},
+			absent:   []string{"downstream_X-Backend"},
+		},
+		{
+			desc:     "truncated values",
+			request:  &types.HeaderCapture{Names: []string{"X-Request-Id"}, MaxLength: 3, MultiValue: types.HeaderMultiValueJoin},
+			response: &types.HeaderCapture{Names: []string{"X-Backend"}, MaxLength: 4},
+			expected: map[string]interface{}{"request_X-Request-Id": "fir", "downstream_X-Backend": "back"},
+		},
+		{
+			desc:     "modes kept for the response headers",
+			request:  &types.HeaderCapture{Names: []string{"User-Agent"}},
+			expected: map[string]interface{}{"request_User-Agent": "agent", "downstream_X-Backend": "backend"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			logFilePath := filepath.Join(createTempDir(t, "headers"), "access.log")
+
+			config := &types.AccessLog{FilePath: logFilePath, Format: JSONFormat}
+			config.Fields = &types.AccessLogFields{}
+			config.Fields.SetDefaults()
+			config.Fields.Headers.DefaultMode = types.AccessLogKeep
+			config.Fields.Headers.Request = test.request
+			config.Fields.Headers.Response = test.response
+
+			logger, err := NewHandler(config)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+			req.Header.Set("User-Agent", "agent")
+			req.Header.Set("Authorization", "secret")
+			req.Header.Add("X-Request-Id", "first")
+			req.Header.Add("X-Request-Id", "second")
+
+			logger.ServeHTTP(httptest.NewRecorder(), req, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("X-Backend", "backend")
+				rw.WriteHeader(http.StatusOK)
+			}))
+			require.NoError(t, logger.Close())
+
+			logData, err := ioutil.ReadFile(logFilePath)
+			require.NoError(t, err)
+
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(logData, &fields))
+
+			for name, value := range test.expected {
+				assert.Equal(t, value, fields[name], name)
+			}
+			for _, name := range test.absent {
+				assert.NotContains(t, fields, name)
+			}
+		})
+	}
+}
+
+func TestNewHandler_InvalidHeaderCapture(t *testing.T) {
+	config := &types.AccessLog{Fields: &types.AccessLogFields{
+		Headers: &types.FieldHeaders{Request: &types.HeaderCapture{MultiValue: "all"}},
+	}}
+
+	_, err := NewHandler(config)
+	assert.Error(t, err)
+}
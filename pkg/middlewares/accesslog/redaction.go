@@ -0,0 +1,186 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+var (
+	// cardNumberPattern matches the candidate payment card numbers, of 13 to 19 digits which may be grouped by spaces or dashes.
+	cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// socialSecurityNumberPattern matches the US social security numbers.
+	socialSecurityNumberPattern = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// capturedBody holds the beginning of a body, up to a maximum size.
+type capturedBody struct {
+	data      []byte
+	maxSize   int
+	truncated bool
+}
+
+func newCapturedBody(maxSize int64) *capturedBody {
+	return &capturedBody{maxSize: int(maxSize)}
+}
+
+func (b *capturedBody) write(p []byte) {
+	if b == nil {
+		return
+	}
+
+	if room := b.maxSize - len(b.data); len(p) > room {
+		b.truncated = true
+		if room <= 0 {
+			return
+		}
+		p = p[:room]
+	}
+
+	b.data = append(b.data, p...)
+}
+
+// redactor redacts the sensitive data of the bodies before they are logged.
+type redactor struct {
+	jsonPaths             [][]string
+	patterns              []*regexp.Regexp
+	cardNumbers           bool
+	socialSecurityNumbers bool
+	replacement           string
+}
+
+func newRedactor(config *types.BodyRedaction) (*redactor, error) {
+	if config == nil {
+		return &redactor{}, nil
+	}
+
+	r := &redactor{
+		cardNumbers:           config.CardNumbers,
+		socialSecurityNumbers: config.SocialSecurityNumbers,
+		replacement:           config.Replacement,
+	}
+
+	for _, path := range config.JSONPaths {
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if trimmed == "" {
+			return nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+		r.jsonPaths = append(r.jsonPaths, strings.Split(trimmed, "."))
+	}
+
+	for _, pattern := range config.Patterns {
+		exp, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, exp)
+	}
+
+	return r, nil
+}
+
+// redact returns the body once redacted.
+// The JSON bodies which cannot be parsed, e.g. because they were truncated, are entirely redacted
+// when JSON paths are defined, as the values to redact cannot be found.
+func (r *redactor) redact(body *capturedBody, contentType string) string {
+	if body == nil || len(body.data) == 0 {
+		return ""
+	}
+
+	data := body.data
+
+	if len(r.jsonPaths) > 0 && strings.Contains(strings.ToLower(contentType), "json") {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if body.truncated || decoder.Decode(&value) != nil {
+			return r.replacement
+		}
+
+		for _, path := range r.jsonPaths {
+			value = r.redactJSON(value, path)
+		}
+
+		redacted, err := json.Marshal(value)
+		if err != nil {
+			return r.replacement
+		}
+		data = redacted
+	}
+
+	for _, pattern := range r.patterns {
+		data = pattern.ReplaceAll(data, []byte(r.replacement))
+	}
+
+	if r.cardNumbers {
+		data = cardNumberPattern.ReplaceAllFunc(data, func(candidate []byte) []byte {
+			if luhn(candidate) {
+				return []byte(r.replacement)
+			}
+			return candidate
+		})
+	}
+
+	if r.socialSecurityNumbers {
+		data = socialSecurityNumberPattern.ReplaceAll(data, []byte(r.replacement))
+	}
+
+	redacted := string(data)
+	if body.truncated {
+		redacted += "..."
+	}
+
+	return redacted
+}
+
+// redactJSON replaces the values at the path, where "*" matches all the keys of an object, or all the elements of an array.
+func (r *redactor) redactJSON(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return r.replacement
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if path[0] == "*" || path[0] == key {
+				v[key] = r.redactJSON(child, path[1:])
+			}
+		}
+
+	case []interface{}:
+		if path[0] == "*" {
+			for i, child := range v {
+				v[i] = r.redactJSON(child, path[1:])
+			}
+		}
+	}
+
+	return value
+}
+
+// luhn reports whether the digits pass the Luhn check of the payment card numbers.
+func luhn(candidate []byte) bool {
+	var sum, count int
+	for i := len(candidate) - 1; i >= 0; i-- {
+		c := candidate[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		digit := int(c - '0')
+		if count%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		count++
+	}
+
+	return sum%10 == 0
+}
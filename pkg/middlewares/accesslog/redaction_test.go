@@ -0,0 +1,129 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+	config := &types.BodyRedaction{}
+	config.SetDefaults()
+	config.JSONPaths = []string{"$.user.password", "cards.*.number"}
+	config.Patterns = []string{`token=\w+`}
+
+	r, err := newRedactor(config)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc        string
+		body        string
+		maxSize     int64
+		contentType string
+		expected    string
+	}{
+		{
+			desc:        "JSON paths",
+			body:        `{"user":{"name":"bob","password":"secret"},"cards":[{"number":"x","type":"visa"}]}`,
+			contentType: "application/json",
+			expected:    `{"cards":[{"number":"REDACTED","type":"visa"}],"user":{"name":"bob","password":"REDACTED"}}`,
+		},
+		{
+			desc:        "JSON paths not applied to other content types",
+			body:        `{"user":{"password":"secret"}}`,
+			contentType: "text/plain",
+			expected:    `{"user":{"password":"secret"}}`,
+		},
+		{
+			desc:        "truncated JSON",
+			body:        `{"user":{"password":"secret"}}`,
+			maxSize:     10,
+			contentType: "application/json",
+			expected:    "REDACTED",
+		},
+		{
+			desc:     "card numbers passing the Luhn check",
+			body:     "card 4111 1111 1111 1111, order 1234567890123",
+			expected: "card REDACTED, order 1234567890123",
+		},
+		{
+			desc:     "social security numbers",
+			body:     "ssn=078-05-1120",
+			expected: "ssn=REDACTED",
+		},
+		{
+			desc:     "patterns",
+			body:     "a=1&token=abc123&b=2",
+			expected: "a=1&REDACTED&b=2",
+		},
+		{
+			desc:     "truncated body",
+			body:     "0123456789",
+			maxSize:  4,
+			expected: "0123...",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			maxSize := test.maxSize
+			if maxSize == 0 {
+				maxSize = 4096
+			}
+
+			body := newCapturedBody(maxSize)
+			body.write([]byte(test.body))
+
+			assert.Equal(t, test.expected, r.redact(body, test.contentType))
+		})
+	}
+}
+
+func TestNewRedactor_InvalidPattern(t *testing.T) {
+	_, err := newRedactor(&types.BodyRedaction{Patterns: []string{"("}})
+	assert.Error(t, err)
+}
+
+func TestLoggerBodies(t *testing.T) {
+	logFilePath := filepath.Join(createTempDir(t, "bodies"), "access.log")
+
+	bodies := &types.AccessLogBodies{}
+	bodies.SetDefaults()
+	bodies.Redaction.JSONPaths = []string{"password"}
+
+	config := &types.AccessLog{FilePath: logFilePath, Format: JSONFormat, Bodies: bodies}
+	config.Fields = &types.AccessLogFields{}
+	config.Fields.SetDefaults()
+
+	logger, err := NewHandler(config)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/login", strings.NewReader(`{"login":"bob","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	logger.ServeHTTP(httptest.NewRecorder(), req, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = ioutil.ReadAll(req.Body)
+		_, _ = rw.Write([]byte("welcome, your card is 4111-1111-1111-1111"))
+	}))
+	require.NoError(t, logger.Close())
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(logData, &fields))
+
+	assert.Equal(t, `{"login":"bob","password":"REDACTED"}`, fields[RequestBody])
+	assert.Equal(t, "welcome, your card is REDACTED", fields[DownstreamBody])
+}
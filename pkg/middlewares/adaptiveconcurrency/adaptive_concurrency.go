@@ -0,0 +1,61 @@
+package adaptiveconcurrency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "AdaptiveConcurrency"
+)
+
+type adaptiveConcurrency struct {
+	next    http.Handler
+	limiter *Limiter
+	name    string
+}
+
+// New creates an adaptive concurrency middleware.
+// The limiter is shared by the middlewares targeting the same service, one is created from the config when it is nil.
+func New(ctx context.Context, next http.Handler, config dynamic.AdaptiveConcurrency, limiter *Limiter, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if limiter == nil {
+		var err error
+		limiter, err = NewLimiter(config)
+		if err != nil {
+			return nil, fmt.Errorf("error creating concurrency limiter: %w", err)
+		}
+	}
+
+	return &adaptiveConcurrency{
+		next:    next,
+		limiter: limiter,
+		name:    name,
+	}, nil
+}
+
+func (a *adaptiveConcurrency) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *adaptiveConcurrency) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	release, ok := a.limiter.Acquire(req.Context())
+	if !ok {
+		logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), a.name, typeName))
+		logger.Debugf("Concurrency limit of %d reached", a.limiter.Limit())
+
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
+	a.next.ServeHTTP(rw, req)
+}
@@ -0,0 +1,117 @@
+package adaptiveconcurrency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		config           dynamic.AdaptiveConcurrency
+		expectedQueued   int
+		expectedStatuses []int
+	}{
+		{
+			desc:             "rejects the requests beyond the limit",
+			config:           dynamic.AdaptiveConcurrency{InitialLimit: 1, MaxLimit: 1},
+			expectedStatuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+		},
+		{
+			desc: "queues the requests beyond the limit",
+			config: dynamic.AdaptiveConcurrency{
+				InitialLimit: 1,
+				MaxLimit:     1,
+				QueueSize:    1,
+				QueueTimeout: ptypes.Duration(time.Minute),
+			},
+			expectedQueued:   1,
+			expectedStatuses: []int{http.StatusOK, http.StatusServiceUnavailable},
+		},
+		{
+			desc: "rejects the requests waiting too long in the queue",
+			config: dynamic.AdaptiveConcurrency{
+				InitialLimit: 1,
+				MaxLimit:     1,
+				QueueSize:    2,
+				QueueTimeout: ptypes.Duration(10 * time.Millisecond),
+			},
+			expectedStatuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			processing := make(chan struct{}, 1)
+			unblock := make(chan struct{})
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				processing <- struct{}{}
+				<-unblock
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			limiter, err := NewLimiter(test.config)
+			require.NoError(t, err)
+
+			handler, err := New(context.Background(), next, test.config, limiter, "traefikTest")
+			require.NoError(t, err)
+
+			serve := func() <-chan int {
+				status := make(chan int, 1)
+				go func() {
+					rw := httptest.NewRecorder()
+					handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+					status <- rw.Code
+				}()
+				return status
+			}
+
+			first := serve()
+			<-processing
+
+			var statuses []<-chan int
+			for range test.expectedStatuses {
+				statuses = append(statuses, serve())
+
+				// Waits for the requests expected to be queued to be, before sending the next ones.
+				if len(statuses) <= test.expectedQueued {
+					assert.Eventually(t, func() bool {
+						limiter.mu.Lock()
+						defer limiter.mu.Unlock()
+						return limiter.waiters.Len() == len(statuses)
+					}, time.Second, time.Millisecond)
+				}
+			}
+
+			// The rejected requests do not wait for the others.
+			for i, status := range statuses[test.expectedQueued:] {
+				assert.Equal(t, test.expectedStatuses[test.expectedQueued+i], <-status)
+			}
+
+			close(unblock)
+			assert.Equal(t, http.StatusOK, <-first)
+
+			for i, status := range statuses[:test.expectedQueued] {
+				assert.Equal(t, test.expectedStatuses[i], <-status)
+			}
+		})
+	}
+}
+
+func TestAdaptiveConcurrency_InvalidConfig(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.AdaptiveConcurrency{MinLimit: 10, MaxLimit: 5}, nil, "traefikTest")
+	assert.Error(t, err)
+}
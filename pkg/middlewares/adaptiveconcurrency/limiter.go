@@ -0,0 +1,272 @@
+package adaptiveconcurrency
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+const (
+	// sampleWindow is the minimal duration over which the latency is averaged before updating the limit.
+	sampleWindow = time.Second
+	// minWindowSamples is the minimal number of requests over which the latency is averaged before updating the limit.
+	minWindowSamples = 10
+	// longWindow is the number of sample windows over which the long-term latency is averaged.
+	longWindow = 600
+	// tolerance is the ratio by which the latency may exceed its long-term average before the limit shrinks.
+	tolerance = 1.5
+	// smoothing is the weight of a new estimation of the limit.
+	smoothing = 0.2
+)
+
+// Limiter limits the requests processed concurrently, to a limit inferred from the gradient of their latency,
+// as the Gradient2 limit of Netflix's concurrency-limits.
+// The limit grows while the latency stays close to its long-term average,
+// and shrinks as soon as the requests start queuing up in the service.
+type Limiter struct {
+	minLimit     float64
+	maxLimit     float64
+	queueSize    int
+	queueTimeout time.Duration
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	waiters  list.List
+	longRTT  float64
+
+	windowStart       time.Time
+	windowRTT         time.Duration
+	windowSamples     int
+	windowMaxInFlight int
+
+	now func() time.Time
+}
+
+// NewLimiter creates a Limiter.
+func NewLimiter(config dynamic.AdaptiveConcurrency) (*Limiter, error) {
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = 1000
+	}
+
+	if config.MinLimit > config.MaxLimit {
+		return nil, errors.New("the minimal limit is greater than the maximal limit")
+	}
+
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = 20
+	}
+
+	if config.QueueSize < 0 {
+		return nil, errors.New("the queue size is negative")
+	}
+
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = ptypes.Duration(time.Second)
+	}
+
+	limiter := &Limiter{
+		minLimit:     float64(config.MinLimit),
+		maxLimit:     float64(config.MaxLimit),
+		queueSize:    config.QueueSize,
+		queueTimeout: time.Duration(config.QueueTimeout),
+		now:          time.Now,
+	}
+	limiter.limit = math.Max(limiter.minLimit, math.Min(limiter.maxLimit, float64(config.InitialLimit)))
+	limiter.windowStart = limiter.now()
+
+	return limiter, nil
+}
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// Acquire takes a slot for a request, waiting in the queue when the limit is reached.
+// It returns false when the queue is full or the slot was not freed in time,
+// otherwise the returned func must be called once the request has been processed.
+func (l *Limiter) Acquire(ctx context.Context) (func(), bool) {
+	l.mu.Lock()
+
+	if l.inFlight < int(l.limit) {
+		l.take()
+		l.mu.Unlock()
+		return l.releaser(), true
+	}
+
+	if l.waiters.Len() >= l.queueSize {
+		l.mu.Unlock()
+		return nil, false
+	}
+
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(ready)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return l.releaser(), true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-ready:
+		// The slot was handed over while giving up.
+		return l.releaser(), true
+	default:
+		l.waiters.Remove(elem)
+		return nil, false
+	}
+}
+
+func (l *Limiter) releaser() func() {
+	start := l.now()
+
+	return func() {
+		l.release(l.now().Sub(start))
+	}
+}
+
+func (l *Limiter) release(rtt time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sample(rtt)
+	l.inFlight--
+
+	// Hands over the free slots to the waiting requests.
+	for l.waiters.Len() > 0 && l.inFlight < int(l.limit) {
+		ready := l.waiters.Remove(l.waiters.Front()).(chan struct{})
+		l.take()
+		close(ready)
+	}
+}
+
+func (l *Limiter) take() {
+	l.inFlight++
+	if l.inFlight > l.windowMaxInFlight {
+		l.windowMaxInFlight = l.inFlight
+	}
+}
+
+// sample records the latency of a request, and updates the limit at the end of the sample window.
+func (l *Limiter) sample(rtt time.Duration) {
+	l.windowRTT += rtt
+	l.windowSamples++
+
+	now := l.now()
+	if now.Sub(l.windowStart) < sampleWindow || l.windowSamples < minWindowSamples {
+		return
+	}
+
+	shortRTT := float64(l.windowRTT) / float64(l.windowSamples)
+	maxInFlight := l.windowMaxInFlight
+
+	l.windowStart = now
+	l.windowRTT = 0
+	l.windowSamples = 0
+	l.windowMaxInFlight = l.inFlight
+
+	if shortRTT <= 0 {
+		return
+	}
+
+	if l.longRTT == 0 {
+		l.longRTT = shortRTT
+	} else {
+		l.longRTT += (shortRTT - l.longRTT) / longWindow
+	}
+
+	// The long-term latency recovers faster once the service is not overloaded anymore.
+	if l.longRTT/shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+
+	// The latency tells nothing about the limit when the requests do not reach it.
+	if float64(maxInFlight) < l.limit/2 {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, tolerance*l.longRTT/shortRTT))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	newLimit = l.limit*(1-smoothing) + newLimit*smoothing
+
+	l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, newLimit))
+}
+
+type limiterKey struct {
+	middlewareName string
+	serviceName    string
+}
+
+type sharedLimiter struct {
+	config  dynamic.AdaptiveConcurrency
+	limiter *Limiter
+}
+
+// Limiters holds the limiters of the middlewares, for each service they target.
+// They are kept across the configuration reloads, as long as the configuration of their middleware does not change.
+type Limiters struct {
+	mu       sync.Mutex
+	limiters map[limiterKey]sharedLimiter
+}
+
+// NewLimiters creates Limiters.
+func NewLimiters() *Limiters {
+	return &Limiters{limiters: make(map[limiterKey]sharedLimiter)}
+}
+
+// Get returns the limiter of the middleware for the service,
+// created with the configuration when it does not exist yet, or when the configuration changed.
+func (l *Limiters) Get(middlewareName, serviceName string, config dynamic.AdaptiveConcurrency) (*Limiter, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := limiterKey{middlewareName: middlewareName, serviceName: serviceName}
+
+	if shared, ok := l.limiters[key]; ok && shared.config == config {
+		return shared.limiter, nil
+	}
+
+	limiter, err := NewLimiter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	l.limiters[key] = sharedLimiter{config: config, limiter: limiter}
+
+	return limiter, nil
+}
+
+// Prune removes the limiters of the middlewares which are no longer defined.
+func (l *Limiters) Prune(defined func(middlewareName string) bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.limiters {
+		if !defined(key.middlewareName) {
+			delete(l.limiters, key)
+		}
+	}
+}
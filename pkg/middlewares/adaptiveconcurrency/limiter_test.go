@@ -0,0 +1,124 @@
+package adaptiveconcurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Gradient(t *testing.T) {
+	limiter, err := NewLimiter(dynamic.AdaptiveConcurrency{InitialLimit: 20, MinLimit: 1, MaxLimit: 100})
+	require.NoError(t, err)
+
+	clock := time.Unix(0, 0)
+	limiter.now = func() time.Time { return clock }
+	limiter.windowStart = clock
+
+	// load sends as many concurrent requests as allowed, for the given duration.
+	load := func(rtt, duration time.Duration) {
+		for end := clock.Add(duration); clock.Before(end); {
+			var releases []func()
+			for {
+				release, ok := limiter.Acquire(context.Background())
+				if !ok {
+					break
+				}
+				releases = append(releases, release)
+			}
+
+			clock = clock.Add(rtt)
+
+			for _, release := range releases {
+				release()
+			}
+		}
+	}
+
+	// The limit grows while the latency is steady.
+	load(10*time.Millisecond, 10*time.Second)
+	steadyLimit := limiter.Limit()
+	assert.Greater(t, steadyLimit, 20)
+
+	// The limit shrinks once the requests queue up in the service.
+	load(100*time.Millisecond, 10*time.Second)
+	assert.Less(t, limiter.Limit(), steadyLimit)
+}
+
+func TestLimiter_ApplicationLimited(t *testing.T) {
+	limiter, err := NewLimiter(dynamic.AdaptiveConcurrency{InitialLimit: 20, MinLimit: 1, MaxLimit: 100})
+	require.NoError(t, err)
+
+	clock := time.Unix(0, 0)
+	limiter.now = func() time.Time { return clock }
+	limiter.windowStart = clock
+
+	// A single request at a time tells nothing about the limit.
+	for i := 0; i < 1000; i++ {
+		release, ok := limiter.Acquire(context.Background())
+		require.True(t, ok)
+
+		clock = clock.Add(10 * time.Millisecond)
+		release()
+	}
+
+	assert.Equal(t, 20, limiter.Limit())
+}
+
+func TestNewLimiter_InvalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.AdaptiveConcurrency
+	}{
+		{
+			desc:   "minimal limit greater than the maximal limit",
+			config: dynamic.AdaptiveConcurrency{MinLimit: 10, MaxLimit: 5},
+		},
+		{
+			desc:   "negative queue size",
+			config: dynamic.AdaptiveConcurrency{QueueSize: -1},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewLimiter(test.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLimiters_Get(t *testing.T) {
+	limiters := NewLimiters()
+
+	limiter, err := limiters.Get("limit@file", "foo@file", dynamic.AdaptiveConcurrency{InitialLimit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 10, limiter.Limit())
+
+	shared, err := limiters.Get("limit@file", "foo@file", dynamic.AdaptiveConcurrency{InitialLimit: 10})
+	require.NoError(t, err)
+	assert.Same(t, limiter, shared)
+
+	other, err := limiters.Get("limit@file", "bar@file", dynamic.AdaptiveConcurrency{InitialLimit: 10})
+	require.NoError(t, err)
+	assert.NotSame(t, limiter, other)
+
+	other, err = limiters.Get("other@file", "foo@file", dynamic.AdaptiveConcurrency{InitialLimit: 10})
+	require.NoError(t, err)
+	assert.NotSame(t, limiter, other)
+
+	// The limiter is created again when the configuration of the middleware changes.
+	changed, err := limiters.Get("limit@file", "foo@file", dynamic.AdaptiveConcurrency{InitialLimit: 50})
+	require.NoError(t, err)
+	assert.NotSame(t, limiter, changed)
+	assert.Equal(t, 50, changed.Limit())
+
+	limiters.Prune(func(middlewareName string) bool { return middlewareName != "limit@file" })
+	assert.Len(t, limiters.limiters, 1)
+}
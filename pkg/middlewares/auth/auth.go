@@ -3,6 +3,8 @@ package auth
 import (
 	"io/ioutil"
 	"strings"
+	"sync"
+	"time"
 )
 
 // UserParser Parses a string and return a userName/userHash. An error if the format of the string is incorrect.
@@ -63,3 +65,32 @@ func getLinesFromFile(filename string) ([]string, error) {
 
 	return filteredLines, nil
 }
+
+// replayCache holds the authenticators, or assertions, seen until they expire.
+type replayCache struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	lastPurge time.Time
+}
+
+// add adds an entry to the cache, and returns false if it was already seen and is not yet expired.
+func (c *replayCache) add(key string, now, expiration time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastPurge) > maxClockSkew {
+		for k, exp := range c.entries {
+			if now.After(exp) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastPurge = now
+	}
+
+	if exp, ok := c.entries[key]; ok && !now.After(exp) {
+		return false
+	}
+
+	c.entries[key] = expiration
+	return true
+}
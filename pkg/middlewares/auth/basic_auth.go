@@ -105,6 +105,11 @@ func (b *basicAuth) secretBasic(user, realm string) string {
 	return ""
 }
 
+// GetBasicUsers returns the password hashes of the users defined in the htpasswd format, indexed by user name.
+func GetBasicUsers(fileName string, users []string) (map[string]string, error) {
+	return getUsers(fileName, users, basicUserParser)
+}
+
 func basicUserParser(user string) (string, string, error) {
 	split := strings.Split(user, ":")
 	if len(split) != 2 {
@@ -0,0 +1,4 @@
+// Package extauthz holds the subset of the Envoy ext_authz gRPC API called by the forwardAuth middleware.
+package extauthz
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. external_auth.proto
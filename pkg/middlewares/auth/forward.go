@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -12,6 +17,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/auth/extauthz"
 	"github.com/containous/traefik/v2/pkg/tracing"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/forward"
@@ -24,6 +30,11 @@ const (
 	forwardedTypeName = "ForwardedAuthType"
 )
 
+// defaultMaxBodySize is the maximum size of the forwarded request bodies, when not configured.
+const defaultMaxBodySize int64 = 1 << 20
+
+var errBodyTooLarge = errors.New("request body too large")
+
 type forwardAuth struct {
 	address             string
 	authResponseHeaders []string
@@ -31,6 +42,11 @@ type forwardAuth struct {
 	name                string
 	client              http.Client
 	trustForwardHeader  bool
+	authRequestHeaders  []string
+	forwardBody         bool
+	maxBodySize         int64
+	grpc                bool
+	authzClient         extauthz.AuthorizationClient
 }
 
 // NewForward creates a forward auth middleware.
@@ -43,6 +59,14 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		next:                next,
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
+		authRequestHeaders:  config.AuthRequestHeaders,
+		forwardBody:         config.ForwardBody,
+		maxBodySize:         config.MaxBodySize,
+		grpc:                config.GRPC,
+	}
+
+	if fa.maxBodySize == 0 {
+		fa.maxBodySize = defaultMaxBodySize
 	}
 
 	// Ensure our request client does not follow redirects
@@ -53,15 +77,52 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		Timeout: 30 * time.Second,
 	}
 
+	// The TLS options would be ignored with the addresses in clear text.
+	isHTTPS := strings.HasPrefix(config.Address, "https://")
+	if config.SPIFFE != nil && !isHTTPS {
+		return nil, fmt.Errorf("the SPIFFE validation requires an https address, got %s", config.Address)
+	}
+	if fa.grpc && config.TLS != nil && !isHTTPS {
+		return nil, fmt.Errorf("the tls options of a gRPC authentication server require an https address, got %s", config.Address)
+	}
+
+	var tlsConfig *tls.Config
 	if config.TLS != nil {
-		tlsConfig, err := config.TLS.CreateTLSConfig()
+		var err error
+		tlsConfig, err = config.TLS.CreateTLSConfig()
 		if err != nil {
 			return nil, err
 		}
 
+		if config.SPIFFE != nil {
+			if config.TLS.CA == "" {
+				return nil, errors.New("the SPIFFE validation requires the tls.ca option")
+			}
+
+			if len(config.SPIFFE.IDs) == 0 && config.SPIFFE.TrustDomain == "" {
+				return nil, errors.New("the SPIFFE validation requires at least one ID or a trust domain")
+			}
+
+			// The certificate chain is verified by the SPIFFE validation,
+			// as the SPIFFE certificates have no DNS names to verify.
+			tlsConfig.VerifyPeerCertificate = verifySPIFFE(config.SPIFFE, tlsConfig.RootCAs, !config.TLS.InsecureSkipVerify)
+			tlsConfig.InsecureSkipVerify = true
+		}
+
 		tr := http.DefaultTransport.(*http.Transport).Clone()
 		tr.TLSClientConfig = tlsConfig
 		fa.client.Transport = tr
+	} else if config.SPIFFE != nil {
+		return nil, errors.New("the SPIFFE validation requires the tls options")
+	}
+
+	if fa.grpc {
+		conn, err := getGRPCConn(ctx, name, config, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		fa.authzClient = extauthz.NewAuthorizationClient(conn)
 	}
 
 	return fa, nil
@@ -74,7 +135,31 @@ func (fa *forwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), fa.name, forwardedTypeName))
 
-	forwardReq, err := http.NewRequest(http.MethodGet, fa.address, nil)
+	var reqBody []byte
+	if fa.forwardBody {
+		var err error
+		reqBody, err = readBody(req, fa.maxBodySize)
+		if err != nil {
+			logMessage := fmt.Sprintf("Error reading request body. Cause: %s", err)
+			logger.Debug(logMessage)
+			tracing.SetErrorWithEvent(req, logMessage)
+
+			if errors.Is(err, errBodyTooLarge) {
+				rw.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	method, forwardBody := http.MethodGet, io.Reader(nil)
+	if fa.forwardBody {
+		method, forwardBody = http.MethodPost, bytes.NewReader(reqBody)
+	}
+
+	forwardReq, err := http.NewRequest(method, fa.address, forwardBody)
 	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
 	if err != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause %s", fa.address, err)
@@ -89,7 +174,12 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	// forwardReq.
 	tracing.InjectRequestHeaders(req)
 
-	writeHeader(req, forwardReq, fa.trustForwardHeader)
+	writeHeader(req, forwardReq, fa.trustForwardHeader, fa.authRequestHeaders)
+
+	if fa.grpc {
+		fa.serveGRPC(rw, req, forwardReq.Header, reqBody)
+		return
+	}
 
 	forwardResponse, forwardErr := fa.client.Do(forwardReq)
 	if forwardErr != nil {
@@ -158,10 +248,148 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	fa.next.ServeHTTP(rw, req)
 }
 
-func writeHeader(req, forwardReq *http.Request, trustForwardHeader bool) {
+func (fa *forwardAuth) serveGRPC(rw http.ResponseWriter, req *http.Request, headers http.Header, body []byte) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), fa.name, forwardedTypeName))
+
+	checkResp, err := fa.check(req.Context(), newCheckRequest(req, headers, body, fa.forwardBody))
+	if err != nil {
+		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if checkResp.GetStatus().GetCode() != 0 {
+		statusCode := http.StatusForbidden
+		if denied := checkResp.GetDeniedResponse(); denied != nil {
+			if denied.Status != nil && denied.Status.Code != 0 {
+				statusCode = int(denied.Status.Code)
+			}
+			applyHeaders(rw.Header(), denied.Headers)
+		}
+
+		logger.Debugf("Remote error %s. gRPC status: %d, StatusCode: %d", fa.address, checkResp.Status.Code, statusCode)
+
+		tracing.LogResponseCode(tracing.GetSpan(req), statusCode)
+		rw.WriteHeader(statusCode)
+
+		if denied := checkResp.GetDeniedResponse(); denied != nil {
+			if _, err = rw.Write([]byte(denied.Body)); err != nil {
+				logger.Error(err)
+			}
+		}
+		return
+	}
+
+	if ok := checkResp.GetOkResponse(); ok != nil {
+		applyHeaders(req.Header, ok.Headers)
+		for _, name := range ok.HeadersToRemove {
+			req.Header.Del(name)
+		}
+	}
+
+	req.RequestURI = req.URL.RequestURI()
+	fa.next.ServeHTTP(rw, req)
+}
+
+// readBody reads the request body, up to maxBodySize bytes (without limit when negative),
+// and restores it so it can still be read by the next handler.
+func readBody(req *http.Request, maxBodySize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	reader := io.Reader(req.Body)
+	if maxBodySize >= 0 {
+		reader = io.LimitReader(req.Body, maxBodySize+1)
+	}
+
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxBodySize >= 0 && int64(len(body)) > maxBodySize {
+		return nil, errBodyTooLarge
+	}
+
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// verifySPIFFE returns a function verifying the certificate chain of the authentication server against the roots,
+// and its SPIFFE ID against the allowed IDs and trust domain.
+func verifySPIFFE(config *dynamic.SPIFFE, roots *x509.CertPool, verifyChain bool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("no server certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		if verifyChain {
+			intermediates := x509.NewCertPool()
+			for _, cert := range certs[1:] {
+				intermediates.AddCert(cert)
+			}
+
+			_, err := certs[0].Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, uri := range certs[0].URIs {
+			if uri.Scheme != "spiffe" {
+				continue
+			}
+
+			id := uri.String()
+			for _, allowedID := range config.IDs {
+				if id == allowedID {
+					return nil
+				}
+			}
+
+			if config.TrustDomain != "" && uri.Host == config.TrustDomain {
+				return nil
+			}
+		}
+
+		return errors.New("the server certificate has no allowed SPIFFE ID")
+	}
+}
+
+func writeHeader(req, forwardReq *http.Request, trustForwardHeader bool, allowedHeaders []string) {
 	utils.CopyHeaders(forwardReq.Header, req.Header)
 	utils.RemoveHeaders(forwardReq.Header, forward.HopHeaders...)
 
+	if len(allowedHeaders) > 0 {
+		filtered := make(http.Header)
+		for _, name := range allowedHeaders {
+			name = http.CanonicalHeaderKey(name)
+			if values, ok := forwardReq.Header[name]; ok {
+				filtered[name] = values
+			}
+		}
+		forwardReq.Header = filtered
+	}
+
 	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
 		if trustForwardHeader {
 			if prior, ok := req.Header[forward.XForwardedFor]; ok {
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/auth/extauthz"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// grpcCheckTimeout is the timeout of the calls to the authentication server.
+const grpcCheckTimeout = 30 * time.Second
+
+// grpcConnCloseDelay is the delay before closing a connection replaced after a configuration reload,
+// for the checks in flight to complete.
+const grpcConnCloseDelay = time.Minute
+
+// grpcConns holds the connections to the authentication servers, by middleware name,
+// to keep them across the configuration reloads not changing the server.
+var grpcConns = struct {
+	sync.Mutex
+	conns map[string]*grpcConn
+}{conns: make(map[string]*grpcConn)}
+
+type grpcConn struct {
+	key  string
+	conn *grpc.ClientConn
+}
+
+// getGRPCConn returns the connection to the authentication server of the middleware,
+// creating it when the middleware is new or its server configuration has changed.
+func getGRPCConn(ctx context.Context, name string, config dynamic.ForwardAuth, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	key := config.Address
+	if config.TLS != nil {
+		key = fmt.Sprintf("%s %+v", key, *config.TLS)
+	}
+	if config.SPIFFE != nil {
+		key = fmt.Sprintf("%s %+v", key, *config.SPIFFE)
+	}
+
+	grpcConns.Lock()
+	defer grpcConns.Unlock()
+
+	previous, ok := grpcConns.conns[name]
+	if ok && previous.key == key {
+		return previous.conn, nil
+	}
+
+	conn, err := dialGRPC(config.Address, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcConns.conns[name] = &grpcConn{key: key, conn: conn}
+
+	if ok {
+		logger := log.FromContext(ctx)
+		time.AfterFunc(grpcConnCloseDelay, func() {
+			if err := previous.conn.Close(); err != nil {
+				logger.Debugf("Error while closing the connection to the authentication server: %v", err)
+			}
+		})
+	}
+
+	return conn, nil
+}
+
+// dialGRPC creates the connection to the authentication server,
+// in clear text (h2c) for the http addresses.
+// The connection is established on the first call.
+func dialGRPC(address string, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("the address %s of a gRPC authentication server cannot have a path", address)
+	}
+
+	target := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		target = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	switch u.Scheme {
+	case "https":
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		return grpc.Dial(target, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	case "http":
+		return grpc.Dial(target, grpc.WithInsecure())
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q for the gRPC authentication server", u.Scheme)
+	}
+}
+
+// newCheckRequest builds the ext_authz check request of the given request,
+// whose headers are the ones which would be sent to an HTTP authentication server.
+func newCheckRequest(req *http.Request, headers http.Header, body []byte, withBody bool) *extauthz.CheckRequest {
+	httpReq := &extauthz.AttributeContext_HttpRequest{
+		Id:       req.Header.Get("X-Request-Id"),
+		Method:   req.Method,
+		Headers:  make(map[string]string),
+		Path:     req.URL.RequestURI(),
+		Host:     req.Host,
+		Scheme:   "http",
+		Query:    req.URL.RawQuery,
+		Fragment: req.URL.Fragment,
+		Size:     req.ContentLength,
+		Protocol: req.Proto,
+	}
+
+	if req.TLS != nil {
+		httpReq.Scheme = "https"
+	}
+
+	for name, values := range headers {
+		httpReq.Headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	if withBody {
+		httpReq.Size = int64(len(body))
+		if utf8.Valid(body) {
+			httpReq.Body = string(body)
+		} else {
+			httpReq.RawBody = body
+		}
+	}
+
+	attributes := &extauthz.AttributeContext{
+		Source:  newPeer(req.RemoteAddr),
+		Request: &extauthz.AttributeContext_Request{Http: httpReq},
+	}
+
+	if localAddr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		attributes.Destination = newPeer(localAddr.String())
+	}
+
+	if now, err := ptypes.TimestampProto(time.Now()); err == nil {
+		attributes.Request.Time = now
+	}
+
+	return &extauthz.CheckRequest{Attributes: attributes}
+}
+
+func newPeer(hostPort string) *extauthz.AttributeContext_Peer {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil
+	}
+
+	portValue, _ := strconv.ParseUint(port, 10, 32)
+
+	return &extauthz.AttributeContext_Peer{
+		Address: &extauthz.Address{SocketAddress: &extauthz.SocketAddress{Address: host, PortValue: uint32(portValue)}},
+	}
+}
+
+// check calls the Check method of the authentication server.
+func (fa *forwardAuth) check(ctx context.Context, checkReq *extauthz.CheckRequest) (*extauthz.CheckResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, grpcCheckTimeout)
+	defer cancel()
+
+	return fa.authzClient.Check(ctx, checkReq)
+}
+
+// applyHeaders applies the headers of an ext_authz response.
+func applyHeaders(header http.Header, options []*extauthz.HeaderValueOption) {
+	for _, option := range options {
+		if option == nil || option.Header == nil {
+			continue
+		}
+
+		if option.Append != nil && option.Append.Value {
+			header.Add(option.Header.Key, option.Header.Value)
+		} else {
+			header.Set(option.Header.Key, option.Header.Value)
+		}
+	}
+}
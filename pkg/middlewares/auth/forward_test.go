@@ -2,14 +2,24 @@ package auth
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/auth/extauthz"
 	tracingMiddleware "github.com/containous/traefik/v2/pkg/middlewares/tracing"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/containous/traefik/v2/pkg/tracing"
@@ -18,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/vulcand/oxy/forward"
+	"google.golang.org/grpc"
 )
 
 func TestForwardAuthFail(t *testing.T) {
@@ -383,7 +394,7 @@ func Test_writeHeader(t *testing.T) {
 
 			forwardReq := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/path?q=1", nil)
 
-			writeHeader(req, forwardReq, test.trustForwardHeader)
+			writeHeader(req, forwardReq, test.trustForwardHeader, nil)
 
 			actualHeaders := forwardReq.Header
 			expectedHeaders := test.expectedHeaders
@@ -440,3 +451,309 @@ type mockBackend struct {
 func (b *mockBackend) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
 	return b.Tracer, ioutil.NopCloser(nil), nil
 }
+
+func TestForwardAuthForwardBody(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		maxBodySize    int64
+		body           string
+		expectedStatus int
+	}{
+		{
+			desc:           "body forwarded",
+			body:           "request body",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "body too large",
+			maxBodySize:    4,
+			body:           "request body",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:           "no limit",
+			maxBodySize:    -1,
+			body:           "request body",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, test.body, string(body))
+				fmt.Fprintln(w, "Success")
+			}))
+			t.Cleanup(server.Close)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.body, string(body))
+				fmt.Fprintln(w, "traefik")
+			})
+
+			auth := dynamic.ForwardAuth{
+				Address:     server.URL,
+				ForwardBody: true,
+				MaxBodySize: test.maxBodySize,
+			}
+			middleware, err := NewForward(context.Background(), next, auth, "authTest")
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(middleware)
+			t.Cleanup(ts.Close)
+
+			req := testhelpers.MustNewRequest(http.MethodPost, ts.URL, strings.NewReader(test.body))
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestForwardAuthRequestHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("Cookie"))
+		assert.Equal(t, http.MethodGet, r.Header.Get(xForwardedMethod))
+		fmt.Fprintln(w, "Success")
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "session=foo", r.Header.Get("Cookie"))
+		fmt.Fprintln(w, "traefik")
+	})
+
+	auth := dynamic.ForwardAuth{
+		Address:            server.URL,
+		AuthRequestHeaders: []string{"authorization"},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Cookie", "session=foo")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+}
+
+func TestForwardAuthGRPC(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "allowed",
+			authorization:  "Bearer token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "traefik\n",
+		},
+		{
+			desc:           "denied",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "denied",
+		},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	extauthz.RegisterAuthorizationServer(server, authorizationServerFunc(func(ctx context.Context, checkReq *extauthz.CheckRequest) (*extauthz.CheckResponse, error) {
+		httpReq := checkReq.GetAttributes().GetRequest().GetHttp()
+		assert.Equal(t, http.MethodPost, httpReq.GetMethod())
+		assert.Equal(t, "/foo?bar=baz", httpReq.GetPath())
+		assert.Equal(t, "bar=baz", httpReq.GetQuery())
+		assert.Equal(t, "body", httpReq.GetBody())
+
+		if httpReq.GetHeaders()["authorization"] == "Bearer token" {
+			return &extauthz.CheckResponse{
+				Status: &extauthz.Status{},
+				HttpResponse: &extauthz.CheckResponse_OkResponse{OkResponse: &extauthz.OkHttpResponse{
+					Headers:         []*extauthz.HeaderValueOption{{Header: &extauthz.HeaderValue{Key: "X-Auth-User", Value: "user"}}},
+					HeadersToRemove: []string{"authorization"},
+				}},
+			}, nil
+		}
+
+		return &extauthz.CheckResponse{
+			Status: &extauthz.Status{Code: 7},
+			HttpResponse: &extauthz.CheckResponse_DeniedResponse{DeniedResponse: &extauthz.DeniedHttpResponse{
+				Status:  &extauthz.HttpStatus{Code: http.StatusUnauthorized},
+				Headers: []*extauthz.HeaderValueOption{{Header: &extauthz.HeaderValue{Key: "Www-Authenticate", Value: "Bearer"}}},
+				Body:    "denied",
+			}},
+		}, nil
+	}))
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user", r.Header.Get("X-Auth-User"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprintln(w, "traefik")
+	})
+
+	auth := dynamic.ForwardAuth{
+		Address:     "http://" + listener.Addr().String(),
+		GRPC:        true,
+		ForwardBody: true,
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			req := testhelpers.MustNewRequest(http.MethodPost, ts.URL+"/foo?bar=baz", strings.NewReader("body"))
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, test.expectedBody, string(body))
+		})
+	}
+}
+
+type authorizationServerFunc func(context.Context, *extauthz.CheckRequest) (*extauthz.CheckResponse, error)
+
+func (f authorizationServerFunc) Check(ctx context.Context, checkReq *extauthz.CheckRequest) (*extauthz.CheckResponse, error) {
+	return f(ctx, checkReq)
+}
+
+func TestNewForwardTLSAddress(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.ForwardAuth
+		expectedErr bool
+	}{
+		{
+			desc:   "tls with an https address",
+			config: dynamic.ForwardAuth{Address: "https://auth.example.com", TLS: &dynamic.ClientTLS{InsecureSkipVerify: true}},
+		},
+		{
+			desc:   "tls with an http address",
+			config: dynamic.ForwardAuth{Address: "http://auth.example.com", TLS: &dynamic.ClientTLS{InsecureSkipVerify: true}},
+		},
+		{
+			desc:        "gRPC tls with an http address",
+			config:      dynamic.ForwardAuth{Address: "http://auth.example.com", GRPC: true, TLS: &dynamic.ClientTLS{InsecureSkipVerify: true}},
+			expectedErr: true,
+		},
+		{
+			desc: "SPIFFE with an http address",
+			config: dynamic.ForwardAuth{
+				Address: "http://auth.example.com",
+				TLS:     &dynamic.ClientTLS{},
+				SPIFFE:  &dynamic.SPIFFE{TrustDomain: "example.org"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:        "gRPC with a path",
+			config:      dynamic.ForwardAuth{Address: "http://auth.example.com/auth", GRPC: true},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewForward(context.Background(), http.NotFoundHandler(), test.config, "authTest-"+test.desc)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_verifySPIFFE(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.SPIFFE
+		expectedErr bool
+	}{
+		{
+			desc:   "allowed ID",
+			config: dynamic.SPIFFE{IDs: []string{"spiffe://example.org/auth"}},
+		},
+		{
+			desc:   "allowed trust domain",
+			config: dynamic.SPIFFE{TrustDomain: "example.org"},
+		},
+		{
+			desc:        "other ID",
+			config:      dynamic.SPIFFE{IDs: []string{"spiffe://example.org/other"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "other trust domain",
+			config:      dynamic.SPIFFE{TrustDomain: "example.com"},
+			expectedErr: true,
+		},
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	id, err := url.Parse("spiffe://example.org/auth")
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{id},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := verifySPIFFE(&test.config, nil, false)([][]byte{cert}, nil)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
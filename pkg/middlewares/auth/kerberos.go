@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	krbasn1 "github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/asn1tools"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/chksumtype"
+	"github.com/jcmturner/gokrb5/v8/iana/flags"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/iana/patype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+// Kerberos constrained delegation (S4U2Proxy, MS-SFU): Traefik asks the KDC for a ticket to the service on behalf of the client,
+// with the ticket the client authenticated with.
+
+// defaultKrb5Conf is the krb5.conf file read when none is configured.
+const defaultKrb5Conf = "/etc/krb5.conf"
+
+// kdcOptionCNameInAddlTkt is the KDC option requesting a ticket for the client of the additional ticket (MS-SFU, 2.2.3).
+const kdcOptionCNameInAddlTkt = 14
+
+// ticketRenewMargin is the time before their end the tickets are requested again.
+const ticketRenewMargin = time.Minute
+
+// errNotForwardable is returned when the ticket of the client cannot be used for the delegation.
+var errNotForwardable = errors.New("the ticket of the client is not forwardable")
+
+// apReqTokenID is the token ID of the Kerberos AP-REQ in a GSS-API token (RFC 1964, 1.1.1).
+var apReqTokenID = []byte{0x01, 0x00}
+
+type kerberosTicket struct {
+	ticket  messages.Ticket
+	key     types.EncryptionKey
+	endTime time.Time
+}
+
+func (t *kerberosTicket) valid(now time.Time) bool {
+	return t != nil && now.Add(ticketRenewMargin).Before(t.endTime)
+}
+
+type delegation struct {
+	client  *client.Client
+	config  *config.Config
+	cname   types.PrincipalName
+	realm   string
+	service types.PrincipalName
+
+	mu      sync.Mutex
+	tgt     *kerberosTicket
+	tickets map[string]*kerberosTicket
+}
+
+func newDelegation(kt *keytab.Keytab, servicePrincipal string, cfg dynamic.SPNEGODelegation) (*delegation, error) {
+	if cfg.ServicePrincipal == "" {
+		return nil, errors.New("the service principal of the delegation is required")
+	}
+
+	krb5Conf := cfg.Krb5Conf
+	if krb5Conf == "" {
+		krb5Conf = defaultKrb5Conf
+	}
+
+	krbConfig, err := config.Load(krb5Conf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", krb5Conf, err)
+	}
+
+	cname, realm := types.ParseSPNString(servicePrincipal)
+	if realm == "" {
+		realm = krbConfig.LibDefaults.DefaultRealm
+	}
+
+	service, _ := types.ParseSPNString(cfg.ServicePrincipal)
+
+	return &delegation{
+		client:  client.NewWithKeytab(cname.PrincipalNameString(), realm, kt, krbConfig, client.DisablePAFXFAST(true)),
+		config:  krbConfig,
+		cname:   cname,
+		realm:   realm,
+		service: service,
+		tickets: make(map[string]*kerberosTicket),
+	}, nil
+}
+
+// token returns the Negotiate token authenticating the client of the ticket to the service.
+func (d *delegation) token(clientTicket messages.Ticket) (string, error) {
+	encPart := clientTicket.DecryptedEncPart
+	if !types.IsFlagSet(&encPart.Flags, flags.Forwardable) {
+		return "", errNotForwardable
+	}
+
+	tkt, err := d.serviceTicket(clientTicket)
+	if err != nil {
+		return "", err
+	}
+
+	return newNegotiateToken(tkt, encPart.CRealm, encPart.CName)
+}
+
+// serviceTicket returns the ticket to the service, issued for the client of the given ticket.
+func (d *delegation) serviceTicket(clientTicket messages.Ticket) (*kerberosTicket, error) {
+	principal := clientTicket.DecryptedEncPart.CName.PrincipalNameString() + "@" + clientTicket.DecryptedEncPart.CRealm
+	now := time.Now()
+
+	d.mu.Lock()
+	tkt := d.tickets[principal]
+	d.mu.Unlock()
+
+	if tkt.valid(now) {
+		return tkt, nil
+	}
+
+	tgt, err := d.ticketGrantingTicket(now)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newS4U2ProxyRequest(d.cname, d.realm, d.config, tgt, d.service, clientTicket)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rep, err := d.client.TGSExchange(req, d.realm, tgt.ticket, tgt.key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tkt = &kerberosTicket{ticket: rep.Ticket, key: rep.DecryptedEncPart.Key, endTime: rep.DecryptedEncPart.EndTime}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, t := range d.tickets {
+		if !t.valid(now) {
+			delete(d.tickets, name)
+		}
+	}
+	d.tickets[principal] = tkt
+
+	return tkt, nil
+}
+
+// ticketGrantingTicket returns the TGT of Traefik, requested with the keys of the keytab.
+func (d *delegation) ticketGrantingTicket(now time.Time) (*kerberosTicket, error) {
+	d.mu.Lock()
+	tgt := d.tgt
+	d.mu.Unlock()
+
+	if tgt.valid(now) {
+		return tgt, nil
+	}
+
+	req, err := messages.NewASReqForTGT(d.realm, d.config, d.cname)
+	if err != nil {
+		return nil, err
+	}
+
+	rep, err := d.client.ASExchange(d.realm, req, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tgt = &kerberosTicket{ticket: rep.Ticket, key: rep.DecryptedEncPart.Key, endTime: rep.DecryptedEncPart.EndTime}
+
+	d.mu.Lock()
+	d.tgt = tgt
+	d.mu.Unlock()
+
+	return tgt, nil
+}
+
+// newS4U2ProxyRequest creates the TGS-REQ of a ticket to the service, for the client of the given ticket (MS-SFU, 3.2.5.2).
+func newS4U2ProxyRequest(cname types.PrincipalName, realm string, cfg *config.Config, tgt *kerberosTicket, service types.PrincipalName, clientTicket messages.Ticket) (messages.TGSReq, error) {
+	req, err := messages.NewTGSReq(cname, realm, cfg, tgt.ticket, tgt.key, service, false)
+	if err != nil {
+		return req, err
+	}
+
+	req.ReqBody.AdditionalTickets = []messages.Ticket{clientTicket}
+	types.SetFlag(&req.ReqBody.KDCOptions, flags.Forwardable)
+	types.SetFlag(&req.ReqBody.KDCOptions, kdcOptionCNameInAddlTkt)
+
+	// The reply is checked against the name of the body, and is issued to the client of the additional ticket.
+	req.ReqBody.CName = clientTicket.DecryptedEncPart.CName
+
+	// The body changed, the authenticator of Traefik, and its checksum of the body, are computed again.
+	body, err := req.ReqBody.Marshal()
+	if err != nil {
+		return req, err
+	}
+
+	etype, err := crypto.GetEtype(tgt.key.KeyType)
+	if err != nil {
+		return req, err
+	}
+
+	checksum, err := etype.GetChecksumHash(tgt.key.KeyValue, body, keyusage.TGS_REQ_PA_TGS_REQ_AP_REQ_AUTHENTICATOR_CHKSUM)
+	if err != nil {
+		return req, err
+	}
+
+	auth, err := types.NewAuthenticator(tgt.ticket.Realm, cname)
+	if err != nil {
+		return req, err
+	}
+	auth.Cksum = types.Checksum{CksumType: etype.GetHashID(), Checksum: checksum}
+
+	apReq, err := messages.NewAPReq(tgt.ticket, tgt.key, auth)
+	if err != nil {
+		return req, err
+	}
+
+	data, err := apReq.Marshal()
+	if err != nil {
+		return req, err
+	}
+
+	req.PAData = types.PADataSequence{{PADataType: patype.PA_TGS_REQ, PADataValue: data}}
+
+	return req, nil
+}
+
+// newNegotiateToken creates the SPNEGO token of the client, with the ticket to the service.
+func newNegotiateToken(tkt *kerberosTicket, crealm string, cname types.PrincipalName) (string, error) {
+	auth, err := types.NewAuthenticator(crealm, cname)
+	if err != nil {
+		return "", err
+	}
+
+	// The GSS-API checksum (RFC 4121, 4.1.1), with the integrity and confidentiality flags.
+	checksum := make([]byte, 24)
+	checksum[0] = 16
+	checksum[20] = byte(gssapi.ContextFlagInteg | gssapi.ContextFlagConf)
+	auth.Cksum = types.Checksum{CksumType: chksumtype.GSSAPI, Checksum: checksum}
+
+	return marshalNegotiateToken(tkt, auth)
+}
+
+// marshalNegotiateToken returns the SPNEGO token with the AP-REQ of the ticket and authenticator.
+func marshalNegotiateToken(tkt *kerberosTicket, auth types.Authenticator) (string, error) {
+	apReq, err := messages.NewAPReq(tkt.ticket, tkt.key, auth)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := apReq.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	oid, err := krbasn1.Marshal(gssapi.OIDKRB5.OID())
+	if err != nil {
+		return "", err
+	}
+
+	mechToken := append(append(oid, apReqTokenID...), data...)
+
+	negToken := spnego.SPNEGOToken{
+		Init: true,
+		NegTokenInit: spnego.NegTokenInit{
+			MechTypes:      []krbasn1.ObjectIdentifier{gssapi.OIDKRB5.OID()},
+			MechTokenBytes: asn1tools.AddASNAppTag(mechToken, 0),
+		},
+	}
+
+	token, err := negToken.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(token), nil
+}
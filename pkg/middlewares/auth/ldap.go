@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	goauth "github.com/abbot/go-http-auth"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const ldapTypeName = "LDAPAuth"
+
+const (
+	defaultLDAPAttribute            = "uid"
+	defaultLDAPGroupMemberAttribute = "member"
+	defaultLDAPPoolSize             = 10
+)
+
+type ldapAuth struct {
+	next                 http.Handler
+	name                 string
+	auth                 *goauth.BasicAuth
+	pool                 *ldapPool
+	baseDN               string
+	attribute            string
+	searchFilter         string
+	bindDN               string
+	bindPassword         string
+	allowedGroups        []string
+	groupMemberAttribute string
+	cache                *ldapCache
+	headerField          string
+	removeHeader         bool
+}
+
+// NewLDAP creates a LDAP authentication middleware.
+func NewLDAP(ctx context.Context, next http.Handler, config dynamic.LDAPAuth, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, ldapTypeName)).Debug("Creating middleware")
+
+	if config.URL == "" {
+		return nil, errors.New("the URL of the LDAP server is empty")
+	}
+
+	if !strings.HasPrefix(config.URL, "ldap://") && !strings.HasPrefix(config.URL, "ldaps://") {
+		return nil, fmt.Errorf("the URL of the LDAP server must use the ldap or ldaps scheme: %s", config.URL)
+	}
+
+	if config.BaseDN == "" {
+		return nil, errors.New("the base DN is empty")
+	}
+
+	if _, err := ldap.CompileFilter(config.SearchFilter); config.SearchFilter != "" && err != nil {
+		return nil, fmt.Errorf("invalid search filter %q: %w", config.SearchFilter, err)
+	}
+
+	var tlsConfig *tls.Config
+	if config.TLS != nil {
+		var err error
+		tlsConfig, err = config.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+	}
+
+	l := &ldapAuth{
+		next:                 next,
+		name:                 name,
+		baseDN:               config.BaseDN,
+		attribute:            config.Attribute,
+		searchFilter:         config.SearchFilter,
+		bindDN:               config.BindDN,
+		bindPassword:         config.BindPassword,
+		allowedGroups:        config.AllowedGroups,
+		groupMemberAttribute: config.GroupMemberAttribute,
+		headerField:          config.HeaderField,
+		removeHeader:         config.RemoveHeader,
+	}
+
+	if l.attribute == "" {
+		l.attribute = defaultLDAPAttribute
+	}
+
+	if l.groupMemberAttribute == "" {
+		l.groupMemberAttribute = defaultLDAPGroupMemberAttribute
+	}
+
+	poolSize := config.PoolSize
+	if poolSize == 0 {
+		poolSize = defaultLDAPPoolSize
+	}
+
+	l.pool = &ldapPool{
+		size: poolSize,
+		dial: func() (*ldap.Conn, error) {
+			return dialLDAP(config.URL, config.StartTLS, tlsConfig)
+		},
+	}
+
+	if config.CacheDuration > 0 {
+		l.cache = &ldapCache{ttl: time.Duration(config.CacheDuration), entries: make(map[[sha256.Size]byte]time.Time)}
+	}
+
+	realm := defaultRealm
+	if len(config.Realm) > 0 {
+		realm = config.Realm
+	}
+
+	l.auth = &goauth.BasicAuth{Realm: realm}
+
+	return l, nil
+}
+
+func (l *ldapAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return l.name, ext.SpanKindRPCClientEnum
+}
+
+func (l *ldapAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), l.name, ldapTypeName))
+
+	user, password, ok := req.BasicAuth()
+	if ok {
+		var err error
+		ok, err = l.check(user, password)
+		if err != nil {
+			logMessage := fmt.Sprintf("Error authenticating %s with the LDAP server. Cause: %s", user, err)
+			logger.Debug(logMessage)
+			tracing.SetErrorWithEvent(req, logMessage)
+
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = user
+	}
+
+	if !ok {
+		logger.Debug("Authentication failed")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		l.auth.RequireAuth(rw, req)
+		return
+	}
+
+	logger.Debug("Authentication succeeded")
+	req.URL.User = url.User(user)
+
+	if l.headerField != "" {
+		req.Header[l.headerField] = []string{user}
+	}
+
+	if l.removeHeader {
+		logger.Debug("Removing authorization header")
+		req.Header.Del(authorizationHeader)
+	}
+	l.next.ServeHTTP(rw, req)
+}
+
+// check returns whether the credentials are valid,
+// and whether the user is a member of one of the allowed groups.
+func (l *ldapAuth) check(user, password string) (bool, error) {
+	// An empty password would be an unauthenticated bind, which succeeds for any DN.
+	if user == "" || password == "" {
+		return false, nil
+	}
+
+	if l.cache != nil && l.cache.contains(user, password) {
+		return true, nil
+	}
+
+	for {
+		conn, reused, err := l.pool.get()
+		if err != nil {
+			return false, err
+		}
+
+		ok, err := l.authenticate(conn, user, password)
+		var ldapErr *ldap.Error
+		if err != nil && (!errors.As(err, &ldapErr) || ldapErr.ResultCode >= ldap.ErrorNetwork) {
+			// Not a result of the server: the idle connections may have been closed by the server.
+			conn.Close()
+			if reused {
+				continue
+			}
+			return false, err
+		}
+
+		l.pool.put(conn)
+
+		if ok && l.cache != nil {
+			l.cache.add(user, password)
+		}
+		return ok, err
+	}
+}
+
+func (l *ldapAuth) authenticate(conn *ldap.Conn, user, password string) (bool, error) {
+	userDN := l.attribute + "=" + escapeLDAPDNValue(user) + "," + l.baseDN
+
+	if l.bindDN != "" {
+		if err := conn.Bind(l.bindDN, l.bindPassword); err != nil {
+			return false, fmt.Errorf("unable to bind with %s: %w", l.bindDN, err)
+		}
+
+		filter := "(" + l.attribute + "=" + ldap.EscapeFilter(user) + ")"
+		if l.searchFilter != "" {
+			filter = "(&" + filter + l.searchFilter + ")"
+		}
+
+		dns, err := searchLDAP(conn, l.baseDN, ldap.ScopeWholeSubtree, filter, 2)
+		if isLDAPError(err, ldap.LDAPResultSizeLimitExceeded) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("unable to search the user: %w", err)
+		}
+
+		if len(dns) != 1 {
+			return false, nil
+		}
+		userDN = dns[0]
+	}
+
+	if err := conn.Bind(userDN, password); err != nil {
+		if isLDAPError(err, ldap.LDAPResultInvalidCredentials) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if len(l.allowedGroups) == 0 {
+		return true, nil
+	}
+
+	if l.bindDN != "" {
+		if err := conn.Bind(l.bindDN, l.bindPassword); err != nil {
+			return false, fmt.Errorf("unable to bind with %s: %w", l.bindDN, err)
+		}
+	}
+
+	filter := "(" + l.groupMemberAttribute + "=" + ldap.EscapeFilter(userDN) + ")"
+	for _, group := range l.allowedGroups {
+		dns, err := searchLDAP(conn, group, ldap.ScopeBaseObject, filter, 1)
+		if err != nil {
+			if isLDAPError(err, ldap.LDAPResultNoSuchObject) {
+				continue
+			}
+			return false, fmt.Errorf("unable to search the group %s: %w", group, err)
+		}
+
+		if len(dns) > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ldapCache holds the successful authentications, by hash of the credentials.
+type ldapCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[[sha256.Size]byte]time.Time
+	lastPurge time.Time
+}
+
+func (c *ldapCache) contains(user, password string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiration, ok := c.entries[ldapCacheKey(user, password)]
+	return ok && time.Now().Before(expiration)
+}
+
+func (c *ldapCache) add(user, password string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastPurge) > c.ttl {
+		for key, expiration := range c.entries {
+			if now.After(expiration) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastPurge = now
+	}
+
+	c.entries[ldapCacheKey(user, password)] = now.Add(c.ttl)
+}
+
+func ldapCacheKey(user, password string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(user + "\x00" + password))
+}
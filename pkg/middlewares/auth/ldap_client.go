@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapTimeout is the timeout of the connections to the LDAP server, and of the LDAP operations.
+const ldapTimeout = 10 * time.Second
+
+// isLDAPError returns whether the error is a LDAP error with the given result code.
+func isLDAPError(err error, code uint16) bool {
+	var ldapErr *ldap.Error
+	return errors.As(err, &ldapErr) && ldapErr.ResultCode == code
+}
+
+// dialLDAP connects to the LDAP server of the given URL (ldap:// or ldaps://).
+func dialLDAP(rawURL string, startTLS bool, tlsConfig *tls.Config) (*ldap.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{}
+	if tlsConfig != nil {
+		config = tlsConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(rawURL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}), ldap.DialWithTLSConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetTimeout(ldapTimeout)
+
+	if startTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(config); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("StartTLS failed: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// searchLDAP returns the DNs of the entries matching the filter.
+func searchLDAP(conn *ldap.Conn, baseDN string, scope int, filter string, sizeLimit int) ([]string, error) {
+	// No attributes are requested.
+	req := ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, sizeLimit, int(ldapTimeout/time.Second), false, filter, []string{"1.1"}, nil)
+
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	dns := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		dns = append(dns, entry.DN)
+	}
+
+	return dns, nil
+}
+
+// ldapPool holds the idle connections to the LDAP server.
+type ldapPool struct {
+	dial func() (*ldap.Conn, error)
+	size int
+
+	mu   sync.Mutex
+	idle []*ldap.Conn
+}
+
+// get returns an idle connection, whether it was reused, or a new connection.
+func (p *ldapPool) get() (*ldap.Conn, bool, error) {
+	p.mu.Lock()
+	for n := len(p.idle); n > 0; n = len(p.idle) {
+		conn := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+
+		// The connections closed by the server are detected by their reader.
+		if conn.IsClosing() {
+			conn.Close()
+			continue
+		}
+
+		p.mu.Unlock()
+		return conn, true, nil
+	}
+	p.mu.Unlock()
+
+	conn, err := p.dial()
+	return conn, false, err
+}
+
+// put gives back a healthy connection to the pool, or closes it if the pool is full.
+func (p *ldapPool) put(conn *ldap.Conn) {
+	p.mu.Lock()
+	if len(p.idle) < p.size {
+		p.idle = append(p.idle, conn)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+
+	conn.Close()
+}
+
+// escapeLDAPDNValue escapes an attribute value to be used in a DN (RFC 4514, section 2.4).
+func escapeLDAPDNValue(value string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == ',' || c == '+' || c == '"' || c == '\\' || c == '<' || c == '>' || c == ';' || c == '=',
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(value)-1 && c == ' ':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(c)
+		case c == 0:
+			escaped.WriteString(`\00`)
+		default:
+			escaped.WriteByte(c)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,475 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+type ldapEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+// fakeLDAPServer is a LDAP server handling the bind and search operations on a fixed directory.
+type fakeLDAPServer struct {
+	listener net.Listener
+	entries  map[string]ldapEntry
+	binds    int32
+}
+
+func newFakeLDAPServer(t *testing.T) *fakeLDAPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeLDAPServer{
+		listener: listener,
+		entries: map[string]ldapEntry{
+			"cn=admin,dc=example,dc=org": {password: "admin"},
+			"uid=bob,ou=people,dc=example,dc=org": {
+				password:   "secret",
+				attributes: map[string][]string{"objectClass": {"person"}, "uid": {"bob"}, "mail": {"bob@example.org"}},
+			},
+			"uid=alice,ou=people,dc=example,dc=org": {
+				password:   "secret",
+				attributes: map[string][]string{"objectClass": {"person"}, "uid": {"alice"}},
+			},
+			"uid=backup,ou=people,dc=example,dc=org": {
+				password:   "secret",
+				attributes: map[string][]string{"objectClass": {"account"}, "uid": {"backup"}},
+			},
+			"cn=admins,ou=groups,dc=example,dc=org": {
+				attributes: map[string][]string{"objectClass": {"groupOfNames"}, "member": {"uid=bob,ou=people,dc=example,dc=org"}},
+			},
+		},
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return s
+}
+
+func (s *fakeLDAPServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeLDAPServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var boundDN string
+	for {
+		msg, err := ber.ReadPacket(conn)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+
+		id, op := msg.Children[0].Value.(int64), msg.Children[1]
+		respond := func(ops ...*ber.Packet) {
+			for _, o := range ops {
+				envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+				envelope.AppendChild(o)
+				_, _ = conn.Write(envelope.Bytes())
+			}
+		}
+		result := func(tag ber.Tag, code int64) *ber.Packet {
+			p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			return p
+		}
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			atomic.AddInt32(&s.binds, 1)
+
+			dn, password := berString(op.Children[1]), berString(op.Children[2])
+			entry, ok := s.entries[dn]
+			if !ok || entry.password == "" || entry.password != password {
+				boundDN = ""
+				respond(result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials))
+				continue
+			}
+
+			boundDN = dn
+			respond(result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationSearchRequest:
+			if boundDN == "" {
+				respond(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+
+			base, scope, sizeLimit, filter := berString(op.Children[0]), op.Children[1].Value.(int64), op.Children[3].Value.(int64), op.Children[6]
+
+			if _, ok := s.entries[base]; !ok && scope == ldap.ScopeBaseObject {
+				respond(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultNoSuchObject))
+				continue
+			}
+
+			var found int64
+			code := int64(ldap.LDAPResultSuccess)
+			for dn, entry := range s.entries {
+				inScope := dn == base || (scope == ldap.ScopeWholeSubtree && strings.HasSuffix(dn, ","+base))
+				if !inScope || !matchLDAPFilter(filter, entry.attributes) {
+					continue
+				}
+
+				if sizeLimit > 0 && found == sizeLimit {
+					code = ldap.LDAPResultSizeLimitExceeded
+					break
+				}
+				found++
+
+				resultEntry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+				resultEntry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+				resultEntry.AppendChild(ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, ""))
+				respond(resultEntry)
+			}
+			respond(result(ldap.ApplicationSearchResultDone, code))
+
+		default:
+			return
+		}
+	}
+}
+
+func berString(p *ber.Packet) string {
+	return string(p.Data.Bytes())
+}
+
+// matchLDAPFilter evaluates an encoded filter on the attributes of an entry.
+func matchLDAPFilter(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchLDAPFilter(child, attributes) {
+				return false
+			}
+		}
+		return true
+
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchLDAPFilter(child, attributes) {
+				return true
+			}
+		}
+		return false
+
+	case ldap.FilterNot:
+		return !matchLDAPFilter(filter.Children[0], attributes)
+
+	case ldap.FilterEqualityMatch:
+		for _, value := range attributes[berString(filter.Children[0])] {
+			if value == berString(filter.Children[1]) {
+				return true
+			}
+		}
+		return false
+
+	case ldap.FilterPresent:
+		return len(attributes[berString(filter)]) > 0
+
+	case ldap.FilterSubstrings:
+		for _, value := range attributes[berString(filter.Children[0])] {
+			matched := true
+			for _, substring := range filter.Children[1].Children {
+				switch substring.Tag {
+				case ldap.FilterSubstringsInitial:
+					matched = matched && strings.HasPrefix(value, berString(substring))
+				case ldap.FilterSubstringsAny:
+					matched = matched && strings.Contains(value, berString(substring))
+				case ldap.FilterSubstringsFinal:
+					matched = matched && strings.HasSuffix(value, berString(substring))
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+		return false
+
+	default:
+		return false
+	}
+}
+
+func TestLDAPAuth(t *testing.T) {
+	server := newFakeLDAPServer(t)
+
+	testCases := []struct {
+		desc           string
+		config         dynamic.LDAPAuth
+		user           string
+		password       string
+		expectedStatus int
+	}{
+		{
+			desc:           "search bind",
+			config:         dynamic.LDAPAuth{BaseDN: "dc=example,dc=org", BindDN: "cn=admin,dc=example,dc=org", BindPassword: "admin"},
+			user:           "bob",
+			password:       "secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "search bind with an invalid password",
+			config:         dynamic.LDAPAuth{BaseDN: "dc=example,dc=org", BindDN: "cn=admin,dc=example,dc=org", BindPassword: "admin"},
+			user:           "bob",
+			password:       "invalid",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "search bind with an empty password",
+			config:         dynamic.LDAPAuth{BaseDN: "dc=example,dc=org", BindDN: "cn=admin,dc=example,dc=org", BindPassword: "admin"},
+			user:           "bob",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "search bind with an unknown user",
+			config:         dynamic.LDAPAuth{BaseDN: "dc=example,dc=org", BindDN: "cn=admin,dc=example,dc=org", BindPassword: "admin"},
+			user:           "carol",
+			password:       "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "search bind with a filter injection",
+			config:         dynamic.LDAPAuth{BaseDN: "dc=example,dc=org", BindDN: "cn=admin,dc=example,dc=org", BindPassword: "admin"},
+			user:           "*",
+			password:       "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "search bind with a search filter",
+			config: dynamic.LDAPAuth{
+				BaseDN:       "dc=example,dc=org",
+				BindDN:       "cn=admin,dc=example,dc=org",
+				BindPassword: "admin",
+				SearchFilter: "(objectClass=person)",
+			},
+			user:           "backup",
+			password:       "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "search bind with an invalid service account",
+			config:         dynamic.LDAPAuth{BaseDN: "dc=example,dc=org", BindDN: "cn=admin,dc=example,dc=org", BindPassword: "invalid"},
+			user:           "bob",
+			password:       "secret",
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			desc:           "direct bind",
+			config:         dynamic.LDAPAuth{BaseDN: "ou=people,dc=example,dc=org"},
+			user:           "bob",
+			password:       "secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "direct bind with an invalid password",
+			config:         dynamic.LDAPAuth{BaseDN: "ou=people,dc=example,dc=org"},
+			user:           "bob",
+			password:       "invalid",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "member of an allowed group",
+			config: dynamic.LDAPAuth{
+				BaseDN:        "dc=example,dc=org",
+				BindDN:        "cn=admin,dc=example,dc=org",
+				BindPassword:  "admin",
+				AllowedGroups: []string{"cn=unknown,ou=groups,dc=example,dc=org", "cn=admins,ou=groups,dc=example,dc=org"},
+			},
+			user:           "bob",
+			password:       "secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "not a member of the allowed groups",
+			config: dynamic.LDAPAuth{
+				BaseDN:        "dc=example,dc=org",
+				BindDN:        "cn=admin,dc=example,dc=org",
+				BindPassword:  "admin",
+				AllowedGroups: []string{"cn=admins,ou=groups,dc=example,dc=org"},
+			},
+			user:           "alice",
+			password:       "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Empty(t, r.Header.Get("Authorization"))
+				fmt.Fprintln(w, r.Header.Get("X-Forwarded-User"))
+			})
+
+			test.config.URL = server.URL()
+			test.config.HeaderField = "X-Forwarded-User"
+			test.config.RemoveHeader = true
+
+			middleware, err := NewLDAP(context.Background(), next, test.config, "ldapTest")
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(middleware)
+			t.Cleanup(ts.Close)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+			req.SetBasicAuth(test.user, test.password)
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			switch test.expectedStatus {
+			case http.StatusOK:
+				assert.Equal(t, test.user+"\n", string(body))
+			case http.StatusUnauthorized:
+				assert.Equal(t, `Basic realm="traefik"`, res.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestLDAPAuthCache(t *testing.T) {
+	server := newFakeLDAPServer(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "traefik")
+	})
+
+	config := dynamic.LDAPAuth{
+		URL:           server.URL(),
+		BaseDN:        "ou=people,dc=example,dc=org",
+		CacheDuration: ptypes.Duration(time.Minute),
+	}
+	middleware, err := NewLDAP(context.Background(), next, config, "ldapTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	for i := 0; i < 3; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+		req.SetBasicAuth("bob", "secret")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.binds))
+
+	// The failed authentications are not cached.
+	for i := 0; i < 2; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+		req.SetBasicAuth("bob", "invalid")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&server.binds))
+}
+
+func TestLDAPAuthServerUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "ldap://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "traefik")
+	})
+
+	middleware, err := NewLDAP(context.Background(), next, dynamic.LDAPAuth{URL: url, BaseDN: "dc=example,dc=org"}, "ldapTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	req.SetBasicAuth("bob", "secret")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+}
+
+func TestNewLDAPInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.LDAPAuth
+	}{
+		{
+			desc:   "no URL",
+			config: dynamic.LDAPAuth{BaseDN: "dc=example,dc=org"},
+		},
+		{
+			desc:   "invalid scheme",
+			config: dynamic.LDAPAuth{URL: "http://localhost", BaseDN: "dc=example,dc=org"},
+		},
+		{
+			desc:   "no base DN",
+			config: dynamic.LDAPAuth{URL: "ldap://localhost"},
+		},
+		{
+			desc:   "invalid search filter",
+			config: dynamic.LDAPAuth{URL: "ldap://localhost", BaseDN: "dc=example,dc=org", SearchFilter: "(&(objectClass=person)"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewLDAP(context.Background(), nil, test.config, "ldapTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_escapeLDAPDNValue(t *testing.T) {
+	assert.Equal(t, `\#bob\,ou\=admins\+x\ `, escapeLDAPDNValue(`#bob,ou=admins+x `))
+}
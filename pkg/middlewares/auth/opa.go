@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const opaTypeName = "OPA"
+
+// Decisions, as reported by the metrics.
+const (
+	opaDecisionAllow = "allow"
+	opaDecisionDeny  = "deny"
+	opaDecisionError = "error"
+)
+
+type opa struct {
+	next             http.Handler
+	name             string
+	client           http.Client
+	decisionURL      string
+	bundle           *opaBundle
+	forwardBody      bool
+	maxBodySize      int64
+	decisionLog      bool
+	decisionsCounter metrics.Counter
+}
+
+// opaInput is the input document given to the policy.
+type opaInput struct {
+	Method     string              `json:"method"`
+	Scheme     string              `json:"scheme"`
+	Host       string              `json:"host"`
+	Path       string              `json:"path"`
+	ParsedPath []string            `json:"parsedPath"`
+	RawQuery   string              `json:"rawQuery"`
+	Query      map[string][]string `json:"query"`
+	Headers    map[string][]string `json:"headers"`
+	RemoteAddr string              `json:"remoteAddr"`
+	ClientIP   string              `json:"clientIP"`
+	Protocol   string              `json:"protocol"`
+	Body       *string             `json:"body,omitempty"`
+}
+
+// opaResponse is the response of the OPA data API.
+type opaResponse struct {
+	DecisionID string          `json:"decision_id"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// opaResult is the decision of a policy returning an object instead of a boolean.
+type opaResult struct {
+	Allow   bool              `json:"allow"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// NewOPA creates an Open Policy Agent middleware.
+func NewOPA(ctx context.Context, next http.Handler, config dynamic.OPA, decisionsCounter metrics.Counter, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, opaTypeName)).Debug("Creating middleware")
+
+	if config.URL == "" && config.Bundle == "" {
+		return nil, errors.New("either the URL of the OPA server or a bundle is required")
+	}
+
+	if config.URL != "" && config.Bundle != "" {
+		return nil, errors.New("the URL of the OPA server and the bundle are mutually exclusive")
+	}
+
+	if config.Policy == "" {
+		return nil, errors.New("the policy is empty")
+	}
+
+	o := &opa{
+		next:             next,
+		name:             name,
+		forwardBody:      config.ForwardBody,
+		maxBodySize:      config.MaxBodySize,
+		decisionLog:      config.DecisionLog,
+		decisionsCounter: decisionsCounter,
+		client:           http.Client{Timeout: 30 * time.Second},
+	}
+
+	if o.maxBodySize == 0 {
+		o.maxBodySize = defaultMaxBodySize
+	}
+
+	if config.Bundle != "" {
+		bundle, err := newOPABundle(ctx, config.Bundle, config.Policy, time.Duration(config.ReloadInterval))
+		if err != nil {
+			return nil, err
+		}
+
+		o.bundle = bundle
+		return o, nil
+	}
+
+	o.decisionURL = strings.TrimSuffix(config.URL, "/") + "/v1/data/" + strings.Trim(config.Policy, "/")
+
+	return o, nil
+}
+
+func (o *opa) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, ext.SpanKindRPCClientEnum
+}
+
+func (o *opa) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), o.name, opaTypeName))
+
+	input, err := o.newInput(req)
+	if err != nil {
+		logMessage := fmt.Sprintf("Error reading request body. Cause: %s", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		if errors.Is(err, errBodyTooLarge) {
+			rw.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	result, decisionID, err := o.query(req.Context(), input)
+	if err != nil {
+		o.countDecision(opaDecisionError)
+
+		logMessage := fmt.Sprintf("Error querying %s. Cause: %s", o.source(), err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if o.decisionLog {
+		logger.WithField("decisionID", decisionID).Infof("Decision for %s %s from %s: allow=%t", req.Method, req.URL.RequestURI(), req.RemoteAddr, result.Allow)
+	}
+
+	if !result.Allow {
+		o.countDecision(opaDecisionDeny)
+
+		for name, value := range result.Headers {
+			rw.Header().Set(name, value)
+		}
+
+		statusCode := result.Status
+		if statusCode == 0 {
+			statusCode = http.StatusForbidden
+		}
+
+		tracing.LogResponseCode(tracing.GetSpan(req), statusCode)
+		rw.WriteHeader(statusCode)
+
+		if _, err = rw.Write([]byte(result.Body)); err != nil {
+			logger.Error(err)
+		}
+		return
+	}
+
+	o.countDecision(opaDecisionAllow)
+
+	for name, value := range result.Headers {
+		req.Header.Set(name, value)
+	}
+
+	o.next.ServeHTTP(rw, req)
+}
+
+func (o *opa) newInput(req *http.Request) (*opaInput, error) {
+	input := &opaInput{
+		Method:     req.Method,
+		Scheme:     "http",
+		Host:       req.Host,
+		Path:       req.URL.Path,
+		ParsedPath: strings.Split(strings.Trim(req.URL.Path, "/"), "/"),
+		RawQuery:   req.URL.RawQuery,
+		Query:      req.URL.Query(),
+		Headers:    req.Header,
+		RemoteAddr: req.RemoteAddr,
+		Protocol:   req.Proto,
+	}
+
+	if req.TLS != nil {
+		input.Scheme = "https"
+	}
+
+	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		input.ClientIP = clientIP
+	}
+
+	if o.forwardBody {
+		body, err := readBody(req, o.maxBodySize)
+		if err != nil {
+			return nil, err
+		}
+
+		bodyString := string(body)
+		input.Body = &bodyString
+	}
+
+	return input, nil
+}
+
+// source returns the source of the decisions, for the logs.
+func (o *opa) source() string {
+	if o.bundle != nil {
+		return o.bundle.path
+	}
+	return o.decisionURL
+}
+
+// query queries the decision of the policy for the given input.
+// An undefined decision denies the request.
+func (o *opa) query(ctx context.Context, input *opaInput) (*opaResult, string, error) {
+	if o.bundle != nil {
+		return o.bundle.eval(ctx, input)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, "", err
+	}
+
+	opaReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.decisionURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, "", err
+	}
+	opaReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(opaReq)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var opaResp opaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&opaResp); err != nil {
+		return nil, "", err
+	}
+
+	result, err := parseOPAResult(opaResp.Result)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result, opaResp.DecisionID, nil
+}
+
+// parseOPAResult parses a decision, either a boolean or an object.
+func parseOPAResult(data json.RawMessage) (*opaResult, error) {
+	result := &opaResult{}
+
+	raw := bytes.TrimSpace(data)
+	switch {
+	case len(raw) == 0:
+	case raw[0] == '{':
+		if err := json.Unmarshal(raw, result); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(raw, &result.Allow); err != nil {
+			return nil, fmt.Errorf("the decision must be a boolean or an object: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (o *opa) countDecision(decision string) {
+	if o.decisionsCounter != nil {
+		o.decisionsCounter.With("middleware", o.name, "decision", decision).Add(1)
+	}
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/google/uuid"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// defaultOPAReloadInterval is the interval at which the bundles are checked for changes, when not configured.
+const defaultOPAReloadInterval = 10 * time.Second
+
+// opaBundle evaluates the decisions of the policies of a bundle with the embedded OPA engine,
+// and reloads the bundle when it is modified.
+type opaBundle struct {
+	path           string
+	query          string
+	reloadInterval time.Duration
+
+	mu        sync.RWMutex
+	prepared  rego.PreparedEvalQuery
+	modTime   time.Time
+	checkedAt time.Time
+
+	reloading int32
+}
+
+// newOPABundle loads the bundle at the given path, and prepares the query of the decision of the given policy.
+func newOPABundle(ctx context.Context, path, policy string, reloadInterval time.Duration) (*opaBundle, error) {
+	b := &opaBundle{
+		path:           path,
+		query:          "data." + strings.ReplaceAll(strings.Trim(policy, "/"), "/", "."),
+		reloadInterval: reloadInterval,
+	}
+
+	if b.reloadInterval == 0 {
+		b.reloadInterval = defaultOPAReloadInterval
+	}
+
+	modTime, err := bundleModTime(path)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared, err := b.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.prepared = prepared
+	b.modTime = modTime
+	b.checkedAt = time.Now()
+
+	return b, nil
+}
+
+// eval evaluates the decision of the policy for the given input.
+// An undefined decision denies the request.
+func (b *opaBundle) eval(ctx context.Context, input *opaInput) (*opaResult, string, error) {
+	b.reloadIfDue(ctx)
+
+	b.mu.RLock()
+	prepared := b.prepared
+	b.mu.RUnlock()
+
+	results, err := prepared.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, "", err
+	}
+
+	decisionID := uuid.New().String()
+
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return &opaResult{}, decisionID, nil
+	}
+
+	raw, err := json.Marshal(results[0].Expressions[0].Value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, err := parseOPAResult(raw)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result, decisionID, nil
+}
+
+// reloadIfDue checks, in the background, whether the bundle has been modified once the reload interval has elapsed,
+// the decisions being evaluated with the previous policies until the bundle is reloaded.
+func (b *opaBundle) reloadIfDue(ctx context.Context) {
+	if b.reloadInterval < 0 {
+		return
+	}
+
+	b.mu.RLock()
+	due := time.Since(b.checkedAt) >= b.reloadInterval
+	b.mu.RUnlock()
+
+	if !due || !atomic.CompareAndSwapInt32(&b.reloading, 0, 1) {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	safe.Go(func() {
+		defer atomic.StoreInt32(&b.reloading, 0)
+
+		if err := b.reload(context.Background()); err != nil {
+			logger.Errorf("Error while reloading the OPA bundle %s, keeping the previous policies: %v", b.path, err)
+		}
+	})
+}
+
+// reload reloads the bundle when it has been modified since its last load.
+// A bundle failing to load is only loaded again once modified.
+func (b *opaBundle) reload(ctx context.Context) error {
+	modTime, err := bundleModTime(b.path)
+
+	b.mu.Lock()
+	b.checkedAt = time.Now()
+	modified := err == nil && !modTime.Equal(b.modTime)
+	if modified {
+		b.modTime = modTime
+	}
+	b.mu.Unlock()
+
+	if err != nil || !modified {
+		return err
+	}
+
+	prepared, err := b.prepare(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.prepared = prepared
+	b.mu.Unlock()
+
+	log.FromContext(ctx).Debugf("OPA bundle %s reloaded", b.path)
+
+	return nil
+}
+
+func (b *opaBundle) prepare(ctx context.Context) (rego.PreparedEvalQuery, error) {
+	bundle, err := loader.NewFileLoader().AsBundle(b.path)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("loading the bundle %s: %w", b.path, err)
+	}
+
+	prepared, err := rego.New(rego.Query(b.query), rego.ParsedBundle(b.path, bundle)).PrepareForEval(ctx)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("preparing the query %s: %w", b.query, err)
+	}
+
+	return prepared, nil
+}
+
+// bundleModTime returns the last modification time of the bundle,
+// i.e. the one of its most recently modified file for the bundle directories.
+func bundleModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !info.IsDir() {
+		return info.ModTime(), nil
+	}
+
+	modTime := info.ModTime()
+	err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return modTime, nil
+}
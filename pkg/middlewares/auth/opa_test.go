@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+// collectingCounter is a counter implementation that enables access to the LastLabelValues.
+type collectingCounter struct {
+	CounterValue    float64
+	LastLabelValues []string
+}
+
+func (c *collectingCounter) With(labelValues ...string) metrics.Counter {
+	c.LastLabelValues = labelValues
+	return c
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.CounterValue += delta
+}
+
+func TestOPA(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		result           string
+		opaStatus        int
+		expectedStatus   int
+		expectedBody     string
+		expectedHeaders  map[string]string
+		expectedDecision string
+	}{
+		{
+			desc:             "allowed",
+			result:           `{"result": true}`,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     "traefik\n",
+			expectedDecision: "allow",
+		},
+		{
+			desc:             "denied",
+			result:           `{"result": false}`,
+			expectedStatus:   http.StatusForbidden,
+			expectedDecision: "deny",
+		},
+		{
+			desc:             "undefined decision",
+			result:           `{}`,
+			expectedStatus:   http.StatusForbidden,
+			expectedDecision: "deny",
+		},
+		{
+			desc:             "allowed with headers",
+			result:           `{"result": {"allow": true, "headers": {"X-User": "bob"}}}`,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     "traefik bob\n",
+			expectedDecision: "allow",
+		},
+		{
+			desc:             "denied with response",
+			result:           `{"result": {"allow": false, "status": 401, "headers": {"WWW-Authenticate": "Bearer"}, "body": "unauthorized"}}`,
+			expectedStatus:   http.StatusUnauthorized,
+			expectedBody:     "unauthorized",
+			expectedHeaders:  map[string]string{"WWW-Authenticate": "Bearer"},
+			expectedDecision: "deny",
+		},
+		{
+			desc:             "invalid decision",
+			result:           `{"result": "yes"}`,
+			expectedStatus:   http.StatusInternalServerError,
+			expectedDecision: "error",
+		},
+		{
+			desc:             "OPA server error",
+			opaStatus:        http.StatusInternalServerError,
+			expectedStatus:   http.StatusInternalServerError,
+			expectedDecision: "error",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/data/httpapi/authz/allow", r.URL.Path)
+
+				var payload struct {
+					Input opaInput `json:"input"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				assert.Equal(t, http.MethodPost, payload.Input.Method)
+				assert.Equal(t, "/foo/bar", payload.Input.Path)
+				assert.Equal(t, []string{"foo", "bar"}, payload.Input.ParsedPath)
+				assert.Equal(t, []string{"baz"}, payload.Input.Query["q"])
+				require.NotNil(t, payload.Input.Body)
+				assert.Equal(t, "body", *payload.Input.Body)
+
+				if test.opaStatus != 0 {
+					w.WriteHeader(test.opaStatus)
+					return
+				}
+				fmt.Fprint(w, test.result)
+			}))
+			t.Cleanup(server.Close)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "body", string(body))
+
+				fmt.Fprintln(w, strings.TrimSpace("traefik "+r.Header.Get("X-User")))
+			})
+
+			counter := &collectingCounter{}
+			config := dynamic.OPA{
+				URL:         server.URL,
+				Policy:      "httpapi/authz/allow",
+				ForwardBody: true,
+			}
+			middleware, err := NewOPA(context.Background(), next, config, counter, "opaTest")
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(middleware)
+			t.Cleanup(ts.Close)
+
+			req := testhelpers.MustNewRequest(http.MethodPost, ts.URL+"/foo/bar?q=baz", strings.NewReader("body"))
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedStatus, res.StatusCode)
+
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, res.Header.Get(name))
+			}
+
+			assert.Equal(t, float64(1), counter.CounterValue)
+			assert.Equal(t, []string{"middleware", "opaTest", "decision", test.expectedDecision}, counter.LastLabelValues)
+		})
+	}
+}
+
+func TestNewOPAInvalidConfig(t *testing.T) {
+	_, err := NewOPA(context.Background(), nil, dynamic.OPA{Policy: "httpapi/authz/allow"}, nil, "opaTest")
+	assert.Error(t, err)
+
+	_, err = NewOPA(context.Background(), nil, dynamic.OPA{URL: "http://localhost:8181"}, nil, "opaTest")
+	assert.Error(t, err)
+
+	_, err = NewOPA(context.Background(), nil, dynamic.OPA{URL: "http://localhost:8181", Bundle: t.TempDir(), Policy: "httpapi/authz/allow"}, nil, "opaTest")
+	assert.Error(t, err)
+
+	bundle := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bundle, "policy.rego"), []byte("package httpapi.authz\n\nallow {"), 0o600))
+
+	_, err = NewOPA(context.Background(), nil, dynamic.OPA{Bundle: bundle, Policy: "httpapi/authz/allow"}, nil, "opaTest")
+	assert.Error(t, err)
+}
+
+func TestOPABundle(t *testing.T) {
+	const policy = `package httpapi.authz
+
+default allow = false
+
+allow {
+	input.method == "GET"
+	input.parsedPath[0] == "%s"
+}
+
+decision = {"allow": false, "status": 401, "headers": {"WWW-Authenticate": "Bearer"}, "body": "unauthorized"} {
+	not allow
+}
+
+decision = {"allow": true, "headers": {"X-User": "bob"}} {
+	allow
+}
+`
+
+	bundle := t.TempDir()
+	policyPath := filepath.Join(bundle, "policy.rego")
+	require.NoError(t, ioutil.WriteFile(policyPath, []byte(fmt.Sprintf(policy, "public")), 0o600))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, strings.TrimSpace("traefik "+r.Header.Get("X-User")))
+	})
+
+	counter := &collectingCounter{}
+	config := dynamic.OPA{
+		Bundle:         bundle,
+		Policy:         "httpapi/authz/decision",
+		ReloadInterval: ptypes.Duration(time.Millisecond),
+	}
+	middleware, err := NewOPA(context.Background(), next, config, counter, "opaTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	get := func(path string) (int, string, http.Header) {
+		res, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		return res.StatusCode, string(body), res.Header
+	}
+
+	status, body, _ := get("/public/foo")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "traefik bob\n", body)
+	assert.Equal(t, []string{"middleware", "opaTest", "decision", "allow"}, counter.LastLabelValues)
+
+	status, body, header := get("/private/foo")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "unauthorized", body)
+	assert.Equal(t, "Bearer", header.Get("WWW-Authenticate"))
+	assert.Equal(t, []string{"middleware", "opaTest", "decision", "deny"}, counter.LastLabelValues)
+
+	// The modified bundle is reloaded in the background.
+	require.NoError(t, ioutil.WriteFile(policyPath, []byte(fmt.Sprintf(policy, "private")), 0o600))
+	require.NoError(t, os.Chtimes(policyPath, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+
+	assert.Eventually(t, func() bool {
+		status, _, _ := get("/private/foo")
+		return status == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// An invalid bundle does not replace the loaded policies.
+	require.NoError(t, ioutil.WriteFile(policyPath, []byte("package httpapi.authz\n\nallow {"), 0o600))
+	require.NoError(t, os.Chtimes(policyPath, time.Now().Add(2*time.Minute), time.Now().Add(2*time.Minute)))
+
+	// The first request triggers the reload.
+	status, _, _ = get("/private/foo")
+	assert.Equal(t, http.StatusOK, status)
+
+	time.Sleep(100 * time.Millisecond)
+
+	status, _, _ = get("/private/foo")
+	assert.Equal(t, http.StatusOK, status)
+}
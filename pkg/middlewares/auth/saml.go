@@ -0,0 +1,826 @@
+package auth
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const samlTypeName = "SAML"
+
+const (
+	samlNamespace         = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlProtocolNamespace = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlMetadataNamespace = "urn:oasis:names:tc:SAML:2.0:metadata"
+	samlRedirectBinding   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	samlStatusSuccess     = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlBearerMethod      = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+const (
+	defaultSAMLCookieName      = "traefik_saml"
+	defaultSAMLSessionDuration = 8 * time.Hour
+
+	// samlClockSkew is the maximum difference allowed between the clocks of the identity provider and the one of Traefik.
+	samlClockSkew = 90 * time.Second
+	// samlRequestLifetime is the time given to the users to authenticate with the identity provider.
+	samlRequestLifetime = 10 * time.Minute
+	// samlMaxResponseSize is the maximum size of the form posted to the assertion consumer service.
+	samlMaxResponseSize = 1 << 20
+)
+
+type samlIDP struct {
+	entityID     string
+	ssoURL       string
+	certificates []*x509.Certificate
+}
+
+type samlSP struct {
+	next             http.Handler
+	name             string
+	idp              *samlIDP
+	entityID         string
+	acsURL           string
+	acsPath          string
+	metadataPath     string
+	cookiePath       string
+	secure           bool
+	certificate      *x509.Certificate
+	key              *rsa.PrivateKey
+	sessionKey       []byte
+	nameIDFormat     string
+	authnContexts    []string
+	sessionDuration  time.Duration
+	cookieName       string
+	headerField      string
+	attributeHeaders map[string]string
+	assertions       *replayCache
+}
+
+// samlPendingRequest is the authentication request waiting for the response of the identity provider.
+type samlPendingRequest struct {
+	ID  string `json:"i"`
+	URI string `json:"u"`
+	// AuthnContexts holds the authentication context classes requested to the identity provider.
+	AuthnContexts []string `json:"c,omitempty"`
+	Expiration    int64    `json:"e"`
+}
+
+type samlSession struct {
+	NameID string `json:"n"`
+	// AuthnContext is the authentication context class with which the user authenticated with the identity provider.
+	AuthnContext string `json:"c,omitempty"`
+	// Attributes holds the values of the forwarded attributes, by header.
+	Attributes map[string][]string `json:"a,omitempty"`
+	Expiration int64               `json:"e"`
+}
+
+// NewSAML creates a SAML service provider middleware.
+func NewSAML(ctx context.Context, next http.Handler, config dynamic.SAML, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, samlTypeName)).Debug("Creating middleware")
+
+	rootURL, err := url.Parse(config.RootURL)
+	if err != nil || (rootURL.Scheme != "http" && rootURL.Scheme != "https") || rootURL.Host == "" {
+		return nil, fmt.Errorf("invalid root URL %q", config.RootURL)
+	}
+
+	if config.IDPMetadata == "" {
+		return nil, errors.New("the metadata of the identity provider is empty")
+	}
+
+	metadata, err := traefiktls.FileOrContent(config.IDPMetadata).Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the metadata of the identity provider: %w", err)
+	}
+
+	idp, err := parseIDPMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of the identity provider: %w", err)
+	}
+
+	certificate, key, err := loadSAMLKeyPair(config.Certificate, config.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	rootPath := strings.TrimSuffix(rootURL.Path, "/")
+	baseURL := rootURL.Scheme + "://" + rootURL.Host + rootPath
+
+	s := &samlSP{
+		next:             next,
+		name:             name,
+		idp:              idp,
+		entityID:         config.EntityID,
+		acsURL:           baseURL + "/saml/acs",
+		acsPath:          rootPath + "/saml/acs",
+		metadataPath:     rootPath + "/saml/metadata",
+		cookiePath:       rootPath + "/",
+		secure:           rootURL.Scheme == "https",
+		certificate:      certificate,
+		key:              key,
+		nameIDFormat:     config.NameIDFormat,
+		authnContexts:    config.AuthnContextClassRefs,
+		sessionDuration:  time.Duration(config.SessionDuration),
+		cookieName:       config.CookieName,
+		headerField:      config.HeaderField,
+		attributeHeaders: make(map[string]string),
+		assertions:       &replayCache{entries: make(map[string]time.Time)},
+	}
+
+	if s.entityID == "" {
+		s.entityID = baseURL + "/saml/metadata"
+	}
+
+	if s.sessionDuration <= 0 {
+		s.sessionDuration = defaultSAMLSessionDuration
+	}
+
+	if s.cookieName == "" {
+		s.cookieName = defaultSAMLCookieName
+	}
+
+	for header, attribute := range config.AttributeHeaders {
+		s.attributeHeaders[http.CanonicalHeaderKey(header)] = attribute
+	}
+
+	// The session cookies are signed with a key derived from the private key of the service provider,
+	// so they stay valid across the restarts and the instances sharing the key.
+	sessionKey := sha256.Sum256(append([]byte("traefik saml session\x00"), x509.MarshalPKCS1PrivateKey(key)...))
+	s.sessionKey = sessionKey[:]
+
+	return s, nil
+}
+
+func (s *samlSP) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return s.name, ext.SpanKindRPCServerEnum
+}
+
+func (s *samlSP) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), s.name, samlTypeName))
+
+	switch req.URL.Path {
+	case s.metadataPath:
+		s.serveMetadata(rw)
+		return
+	case s.acsPath:
+		s.serveACS(rw, req)
+		return
+	}
+
+	var session samlSession
+	if err := s.readCookie(req, s.cookieName, &session); err != nil {
+		logger.Debugf("No valid session: %v", err)
+
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			tracing.SetErrorWithEvent(req, "Authentication required")
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err := s.redirectToIDP(rw, req, false); err != nil {
+			logger.Errorf("Unable to create the authentication request: %v", err)
+			tracing.SetErrorWithEvent(req, "Unable to create the authentication request")
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if len(s.authnContexts) > 0 && !contains(s.authnContexts, session.AuthnContext) {
+		logger.Debugf("The session of %s must be elevated from the authentication context %q", session.NameID, session.AuthnContext)
+
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			tracing.SetErrorWithEvent(req, "Session elevation required")
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// The user must authenticate again, even if the identity provider already has a session.
+		if err := s.redirectToIDP(rw, req, true); err != nil {
+			logger.Errorf("Unable to create the authentication request: %v", err)
+			tracing.SetErrorWithEvent(req, "Unable to create the authentication request")
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The forwarded headers are always removed, so that the clients cannot spoof them.
+	if s.headerField != "" {
+		req.Header.Del(s.headerField)
+	}
+	for header := range s.attributeHeaders {
+		req.Header.Del(header)
+	}
+
+	if s.headerField != "" {
+		req.Header.Set(s.headerField, session.NameID)
+	}
+	for header, values := range session.Attributes {
+		if _, ok := s.attributeHeaders[header]; ok {
+			req.Header[header] = values
+		}
+	}
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = session.NameID
+	}
+
+	s.next.ServeHTTP(rw, req)
+}
+
+const samlSPMetadataTemplate = `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%s">` +
+	`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">` +
+	`<md:KeyDescriptor use="encryption"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>%s</ds:X509Certificate></ds:X509Data></ds:KeyInfo>` +
+	`<md:EncryptionMethod Algorithm="http://www.w3.org/2009/xmlenc11#aes128-gcm"/>` +
+	`<md:EncryptionMethod Algorithm="http://www.w3.org/2001/04/xmlenc#aes256-cbc"/>` +
+	`<md:EncryptionMethod Algorithm="http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p"/></md:KeyDescriptor>` +
+	`%s<md:AssertionConsumerService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="%s" index="0" isDefault="true"/>` +
+	`</md:SPSSODescriptor></md:EntityDescriptor>`
+
+func (s *samlSP) serveMetadata(rw http.ResponseWriter) {
+	var nameIDFormat string
+	if s.nameIDFormat != "" {
+		nameIDFormat = "<md:NameIDFormat>" + escapeXML(s.nameIDFormat) + "</md:NameIDFormat>"
+	}
+
+	metadata := fmt.Sprintf(samlSPMetadataTemplate,
+		escapeXML(s.entityID), base64.StdEncoding.EncodeToString(s.certificate.Raw), nameIDFormat, escapeXML(s.acsURL))
+
+	rw.Header().Set("Content-Type", "application/samlmetadata+xml")
+	_, _ = rw.Write([]byte(xml.Header + metadata))
+}
+
+const samlAuthnRequestTemplate = `<samlp:AuthnRequest xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion"` +
+	` ID="%s" Version="2.0" IssueInstant="%s" Destination="%s" AssertionConsumerServiceURL="%s"` +
+	` ProtocolBinding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"%s>` +
+	`<saml:Issuer>%s</saml:Issuer>%s%s</samlp:AuthnRequest>`
+
+// redirectToIDP redirects the user to the single sign-on service of the identity provider, with the HTTP-Redirect binding.
+// With forceAuthn, the identity provider must authenticate the user again, instead of relying on its own session.
+func (s *samlSP) redirectToIDP(rw http.ResponseWriter, req *http.Request, forceAuthn bool) error {
+	random := make([]byte, 20)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	// The IDs must not start with a digit.
+	id := "id-" + hex.EncodeToString(random)
+
+	var nameIDPolicy string
+	if s.nameIDFormat != "" {
+		nameIDPolicy = `<samlp:NameIDPolicy Format="` + escapeXML(s.nameIDFormat) + `" AllowCreate="true"/>`
+	}
+
+	var requestedAuthnContext string
+	if len(s.authnContexts) > 0 {
+		requestedAuthnContext = `<samlp:RequestedAuthnContext Comparison="exact">`
+		for _, authnContext := range s.authnContexts {
+			requestedAuthnContext += "<saml:AuthnContextClassRef>" + escapeXML(authnContext) + "</saml:AuthnContextClassRef>"
+		}
+		requestedAuthnContext += "</samlp:RequestedAuthnContext>"
+	}
+
+	var forceAuthnAttr string
+	if forceAuthn {
+		forceAuthnAttr = ` ForceAuthn="true"`
+	}
+
+	now := time.Now()
+	authnRequest := fmt.Sprintf(samlAuthnRequestTemplate,
+		id, now.UTC().Format(time.RFC3339), escapeXML(s.idp.ssoURL), escapeXML(s.acsURL), forceAuthnAttr,
+		escapeXML(s.entityID), nameIDPolicy, requestedAuthnContext)
+
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write([]byte(authnRequest)); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	pending := samlPendingRequest{
+		ID:            id,
+		URI:           localRedirectURI(req.URL.RequestURI()),
+		AuthnContexts: s.authnContexts,
+		Expiration:    now.Add(samlRequestLifetime).Unix(),
+	}
+	// The identity provider posts the response from its own site, so the request cookie must be sent cross-site,
+	// which the browsers only allow for the secure cookies.
+	sameSite := http.SameSiteDefaultMode
+	if s.secure {
+		sameSite = http.SameSiteNoneMode
+	}
+
+	cookie, err := s.newCookie(s.cookieName+"_request", s.acsPath, pending, now.Add(samlRequestLifetime), sameSite)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(rw, cookie)
+
+	query := url.Values{}
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	query.Set("RelayState", id)
+
+	separator := "?"
+	if strings.Contains(s.idp.ssoURL, "?") {
+		separator = "&"
+	}
+
+	http.Redirect(rw, req, s.idp.ssoURL+separator+query.Encode(), http.StatusFound)
+	return nil
+}
+
+// serveACS serves the assertion consumer service, receiving the responses of the identity provider with the HTTP-POST binding.
+func (s *samlSP) serveACS(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), s.name, samlTypeName))
+
+	if req.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(rw, req.Body, samlMaxResponseSize)
+	if err := req.ParseForm(); err != nil {
+		logger.Debugf("Invalid SAML response form: %v", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var pending samlPendingRequest
+	if err := s.readCookie(req, s.cookieName+"_request", &pending); err != nil {
+		logger.Debugf("No pending authentication request: %v", err)
+		tracing.SetErrorWithEvent(req, "No pending authentication request")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if req.PostForm.Get("RelayState") != pending.ID {
+		logger.Debug("The relay state does not match the pending authentication request")
+		tracing.SetErrorWithEvent(req, "Invalid relay state")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(req.PostForm.Get("SAMLResponse")), ""))
+	if err != nil {
+		logger.Debugf("Invalid SAML response encoding: %v", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	session, err := s.validateResponse(response, pending, now)
+	if err != nil {
+		logMessage := fmt.Sprintf("Invalid SAML response: %v", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	cookie, err := s.newCookie(s.cookieName, s.cookiePath, session, time.Unix(session.Expiration, 0), http.SameSiteLaxMode)
+	if err != nil {
+		logger.Errorf("Unable to create the session: %v", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(rw, cookie)
+
+	http.SetCookie(rw, &http.Cookie{Name: s.cookieName + "_request", Path: s.acsPath, MaxAge: -1, HttpOnly: true, Secure: s.secure})
+
+	logger.Debugf("Authentication succeeded for %s", session.NameID)
+	http.Redirect(rw, req, localRedirectURI(pending.URI), http.StatusSeeOther)
+}
+
+// localRedirectURI returns the URI if it is a path on the same host, and the root path otherwise,
+// so that the protocol-relative URIs (//host/path, and /\host/path for the browsers) cannot redirect to other sites.
+func localRedirectURI(uri string) string {
+	if !strings.HasPrefix(uri, "/") || strings.HasPrefix(uri, "//") || strings.HasPrefix(uri, "/\\") {
+		return "/"
+	}
+	return uri
+}
+
+// validateResponse validates the SAML response to the pending request, and returns the session of the authenticated user.
+func (s *samlSP) validateResponse(data []byte, pending samlPendingRequest, now time.Time) (*samlSession, error) {
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.is(samlProtocolNamespace, "Response") {
+		return nil, errors.New("not a SAML response")
+	}
+
+	// The signatures reference the elements by ID, which must then be unique.
+	if err = checkUniqueXMLIDs(response); err != nil {
+		return nil, err
+	}
+
+	if destination := response.attr("Destination"); destination != "" && destination != s.acsURL {
+		return nil, fmt.Errorf("unexpected destination %q", destination)
+	}
+
+	if inResponseTo := response.attr("InResponseTo"); inResponseTo != pending.ID {
+		return nil, fmt.Errorf("the response is not for the pending request %s: %q", pending.ID, inResponseTo)
+	}
+
+	statusCode := response.child(samlProtocolNamespace, "Status").child(samlProtocolNamespace, "StatusCode").attr("Value")
+	if statusCode != samlStatusSuccess {
+		return nil, fmt.Errorf("authentication failed with status %q", statusCode)
+	}
+
+	if issuer := response.child(samlNamespace, "Issuer"); issuer != nil && strings.TrimSpace(issuer.text()) != s.idp.entityID {
+		return nil, fmt.Errorf("unexpected issuer %q", strings.TrimSpace(issuer.text()))
+	}
+
+	responseSigned := response.child(dsigNamespace, "Signature") != nil
+	if responseSigned {
+		response, err = verifyXMLSignature(response, s.idp.certificates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response signature: %w", err)
+		}
+	}
+
+	assertions := response.childrenNamed(samlNamespace, "Assertion")
+	encryptedAssertions := response.childrenNamed(samlNamespace, "EncryptedAssertion")
+	if len(assertions)+len(encryptedAssertions) != 1 {
+		return nil, errors.New("the response must have exactly one assertion")
+	}
+
+	var assertion *xmlNode
+	if len(assertions) == 1 {
+		assertion = assertions[0]
+	} else {
+		assertion, err = decryptXML(encryptedAssertions[0], s.key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt the assertion: %w", err)
+		}
+
+		if !assertion.is(samlNamespace, "Assertion") {
+			return nil, errors.New("the encrypted data is not an assertion")
+		}
+
+		if err = checkUniqueXMLIDs(assertion); err != nil {
+			return nil, err
+		}
+	}
+
+	if assertion.child(dsigNamespace, "Signature") != nil {
+		assertion, err = verifyXMLSignature(assertion, s.idp.certificates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assertion signature: %w", err)
+		}
+	} else if !responseSigned {
+		return nil, errors.New("neither the response nor the assertion is signed")
+	}
+
+	return s.validateAssertion(assertion, pending, now)
+}
+
+func (s *samlSP) validateAssertion(assertion *xmlNode, pending samlPendingRequest, now time.Time) (*samlSession, error) {
+	if issuer := strings.TrimSpace(assertion.child(samlNamespace, "Issuer").text()); issuer != s.idp.entityID {
+		return nil, fmt.Errorf("unexpected assertion issuer %q", issuer)
+	}
+
+	subject := assertion.child(samlNamespace, "Subject")
+	nameID := strings.TrimSpace(subject.child(samlNamespace, "NameID").text())
+	if nameID == "" {
+		return nil, errors.New("missing NameID")
+	}
+
+	var expiration time.Time
+	for _, confirmation := range subject.childrenNamed(samlNamespace, "SubjectConfirmation") {
+		if confirmation.attr("Method") != samlBearerMethod {
+			continue
+		}
+
+		data := confirmation.child(samlNamespace, "SubjectConfirmationData")
+		if data.attr("Recipient") != s.acsURL || data.attr("InResponseTo") != pending.ID {
+			continue
+		}
+
+		notOnOrAfter, err := time.Parse(time.RFC3339Nano, data.attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(samlClockSkew)) {
+			continue
+		}
+
+		expiration = notOnOrAfter
+		break
+	}
+	if expiration.IsZero() {
+		return nil, errors.New("the subject is not confirmed by a valid bearer confirmation")
+	}
+
+	conditions := assertion.child(samlNamespace, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("missing conditions")
+	}
+
+	if value := conditions.attr("NotBefore"); value != "" {
+		notBefore, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NotBefore condition: %w", err)
+		}
+		if now.Add(samlClockSkew).Before(notBefore) {
+			return nil, errors.New("the assertion is not yet valid")
+		}
+	}
+
+	if value := conditions.attr("NotOnOrAfter"); value != "" {
+		notOnOrAfter, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NotOnOrAfter condition: %w", err)
+		}
+		if !now.Before(notOnOrAfter.Add(samlClockSkew)) {
+			return nil, errors.New("the assertion is expired")
+		}
+		if notOnOrAfter.After(expiration) {
+			expiration = notOnOrAfter
+		}
+	}
+
+	restrictions := conditions.childrenNamed(samlNamespace, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, errors.New("the assertion has no audience restriction")
+	}
+	for _, restriction := range restrictions {
+		var found bool
+		for _, audience := range restriction.childrenNamed(samlNamespace, "Audience") {
+			if strings.TrimSpace(audience.text()) == s.entityID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("the service provider is not in the audience of the assertion")
+		}
+	}
+
+	id := assertion.attr("ID")
+	if id == "" {
+		return nil, errors.New("missing assertion ID")
+	}
+	if !s.assertions.add(id, now, expiration.Add(samlClockSkew)) {
+		return nil, fmt.Errorf("the assertion %s has already been used", id)
+	}
+
+	session := &samlSession{NameID: nameID, Expiration: now.Add(s.sessionDuration).Unix()}
+
+	for _, statement := range assertion.childrenNamed(samlNamespace, "AuthnStatement") {
+		if session.AuthnContext == "" {
+			session.AuthnContext = strings.TrimSpace(statement.child(samlNamespace, "AuthnContext").child(samlNamespace, "AuthnContextClassRef").text())
+		}
+
+		if value := statement.attr("SessionNotOnOrAfter"); value != "" {
+			sessionNotOnOrAfter, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SessionNotOnOrAfter: %w", err)
+			}
+			if sessionNotOnOrAfter.Unix() < session.Expiration {
+				session.Expiration = sessionNotOnOrAfter.Unix()
+			}
+		}
+	}
+
+	// The response can be consumed by another middleware sharing the session, which must then enforce the requested authentication context.
+	if len(pending.AuthnContexts) > 0 && !contains(pending.AuthnContexts, session.AuthnContext) {
+		return nil, fmt.Errorf("the authentication context %q was not requested", session.AuthnContext)
+	}
+
+	for _, statement := range assertion.childrenNamed(samlNamespace, "AttributeStatement") {
+		for _, attribute := range statement.childrenNamed(samlNamespace, "Attribute") {
+			for header, name := range s.attributeHeaders {
+				if attribute.attr("Name") != name && attribute.attr("FriendlyName") != name {
+					continue
+				}
+
+				if session.Attributes == nil {
+					session.Attributes = make(map[string][]string)
+				}
+				for _, value := range attribute.childrenNamed(samlNamespace, "AttributeValue") {
+					session.Attributes[header] = append(session.Attributes[header], strings.TrimSpace(value.text()))
+				}
+			}
+		}
+	}
+
+	return session, nil
+}
+
+// newCookie creates a cookie holding the value, signed with the session key.
+func (s *samlSP) newCookie(name, path string, value interface{}, expiration time.Time, sameSite http.SameSite) (*http.Cookie, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, s.sessionKey)
+	_, _ = mac.Write([]byte(name + "=" + encoded))
+
+	return &http.Cookie{
+		Name:     name,
+		Value:    encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)),
+		Path:     path,
+		Expires:  expiration,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: sameSite,
+	}, nil
+}
+
+// readCookie reads a cookie set by setCookie, and rejects it if its signature is invalid or if it is expired.
+func (s *samlSP) readCookie(req *http.Request, name string, value interface{}) error {
+	cookie, err := req.Cookie(name)
+	if err != nil {
+		return err
+	}
+
+	parts := strings.SplitN(cookie.Value, ".", 2)
+	if len(parts) != 2 {
+		return errors.New("malformed cookie")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("malformed cookie signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, s.sessionKey)
+	_, _ = mac.Write([]byte(name + "=" + parts[0]))
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return errors.New("invalid cookie signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed cookie payload: %w", err)
+	}
+
+	var expiration struct {
+		Expiration int64 `json:"e"`
+	}
+	if err = json.Unmarshal(payload, &expiration); err != nil {
+		return err
+	}
+	if time.Now().Unix() >= expiration.Expiration {
+		return errors.New("expired cookie")
+	}
+
+	return json.Unmarshal(payload, value)
+}
+
+// parseIDPMetadata parses the metadata of the identity provider,
+// which can be an EntityDescriptor or an EntitiesDescriptor containing it.
+func parseIDPMetadata(data []byte) (*samlIDP, error) {
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var descriptor *xmlNode
+	root.walk(func(n *xmlNode) {
+		if descriptor == nil && n.is(samlMetadataNamespace, "EntityDescriptor") && n.child(samlMetadataNamespace, "IDPSSODescriptor") != nil {
+			descriptor = n
+		}
+	})
+	if descriptor == nil {
+		return nil, errors.New("no identity provider descriptor")
+	}
+
+	idp := &samlIDP{entityID: descriptor.attr("entityID")}
+	if idp.entityID == "" {
+		return nil, errors.New("missing entity ID")
+	}
+
+	ssoDescriptor := descriptor.child(samlMetadataNamespace, "IDPSSODescriptor")
+	for _, service := range ssoDescriptor.childrenNamed(samlMetadataNamespace, "SingleSignOnService") {
+		if service.attr("Binding") == samlRedirectBinding {
+			idp.ssoURL = service.attr("Location")
+			break
+		}
+	}
+	if idp.ssoURL == "" {
+		return nil, errors.New("no single sign-on service with the HTTP-Redirect binding")
+	}
+
+	for _, keyDescriptor := range ssoDescriptor.childrenNamed(samlMetadataNamespace, "KeyDescriptor") {
+		if use := keyDescriptor.attr("use"); use != "" && use != "signing" {
+			continue
+		}
+
+		x509Data := keyDescriptor.child(dsigNamespace, "KeyInfo").child(dsigNamespace, "X509Data")
+		for _, value := range x509Data.childrenNamed(dsigNamespace, "X509Certificate") {
+			der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value.text()), ""))
+			if err != nil {
+				return nil, fmt.Errorf("invalid signing certificate: %w", err)
+			}
+
+			certificate, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("invalid signing certificate: %w", err)
+			}
+			idp.certificates = append(idp.certificates, certificate)
+		}
+	}
+	if len(idp.certificates) == 0 {
+		return nil, errors.New("no signing certificate")
+	}
+
+	return idp, nil
+}
+
+func loadSAMLKeyPair(certificate, key string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	if certificate == "" || key == "" {
+		return nil, nil, errors.New("the certificate and the key of the service provider are required")
+	}
+
+	certPEM, err := traefiktls.FileOrContent(certificate).Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the certificate: %w", err)
+	}
+
+	keyPEM, err := traefiktls.FileOrContent(key).Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the key: %w", err)
+	}
+
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid key pair: %w", err)
+	}
+
+	rsaKey, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("the key of the service provider must be a RSA key")
+	}
+
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	return cert, rsaKey, nil
+}
+
+// checkUniqueXMLIDs returns an error if several elements of the document have the same ID.
+func checkUniqueXMLIDs(root *xmlNode) error {
+	ids := make(map[string]bool)
+	var duplicated string
+	root.walk(func(n *xmlNode) {
+		if id := n.attr("ID"); id != "" {
+			if ids[id] {
+				duplicated = id
+			}
+			ids[id] = true
+		}
+	})
+
+	if duplicated != "" {
+		return fmt.Errorf("duplicated ID %s", duplicated)
+	}
+	return nil
+}
+
+func escapeXML(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
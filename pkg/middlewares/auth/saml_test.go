@@ -0,0 +1,753 @@
+package auth
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	samlTestIDPEntityID = "https://idp.example.com/metadata"
+	samlTestSSOURL      = "https://idp.example.com/sso"
+	samlTestACSURL      = "https://app.example.com/saml/acs"
+	samlTestEntityID    = "https://app.example.com/saml/metadata"
+)
+
+func TestSAML(t *testing.T) {
+	idpCertPEM, _, idpKey := newSAMLTestKeyPair(t, "idp.example.com")
+	spCertPEM, spKeyPEM, _ := newSAMLTestKeyPair(t, "app.example.com")
+
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sp.crt"), spCertPEM, 0o600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sp.key"), spKeyPEM, 0o600))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", r.Header.Get("X-Forwarded-User"), strings.Join(r.Header.Values("X-Forwarded-Groups"), ","), r.Header.Get("X-Forwarded-Email"))
+	})
+
+	config := dynamic.SAML{
+		RootURL:     "https://app.example.com",
+		IDPMetadata: newSAMLTestIDPMetadata(idpCertPEM),
+		Certificate: filepath.Join(dir, "sp.crt"),
+		Key:         filepath.Join(dir, "sp.key"),
+		HeaderField: "X-Forwarded-User",
+		AttributeHeaders: map[string]string{
+			"X-Forwarded-Groups": "isMemberOf",
+			"x-forwarded-email":  "mail",
+		},
+	}
+	middleware, err := NewSAML(context.Background(), next, config, "samlTest")
+	require.NoError(t, err)
+
+	// Without session, the users are redirected to the identity provider.
+	rw := httptest.NewRecorder()
+	middleware.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, "https://app.example.com/private?page=1", nil))
+	require.Equal(t, http.StatusFound, rw.Code)
+
+	location, err := url.Parse(rw.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, samlTestSSOURL, location.Scheme+"://"+location.Host+location.Path)
+
+	authnRequest := inflateSAMLRequest(t, location.Query().Get("SAMLRequest"))
+	assert.True(t, authnRequest.is(samlProtocolNamespace, "AuthnRequest"))
+	assert.Equal(t, samlTestACSURL, authnRequest.attr("AssertionConsumerServiceURL"))
+	assert.Equal(t, samlTestEntityID, strings.TrimSpace(authnRequest.child(samlNamespace, "Issuer").text()))
+
+	requestID := authnRequest.attr("ID")
+	assert.Equal(t, requestID, location.Query().Get("RelayState"))
+
+	requestCookies := rw.Result().Cookies()
+	require.Len(t, requestCookies, 1)
+
+	// The other methods are not redirected.
+	rw = httptest.NewRecorder()
+	middleware.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodPost, "https://app.example.com/private", nil))
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	// The identity provider posts the response to the assertion consumer service.
+	response := newSAMLTestResponse(t, samlTestResponse{requestID: requestID, signAssertion: idpKey})
+	rw = postSAMLResponse(middleware, response, requestID, requestCookies)
+	require.Equal(t, http.StatusSeeOther, rw.Code)
+	assert.Equal(t, "/private?page=1", rw.Header().Get("Location"))
+
+	var session *http.Cookie
+	for _, cookie := range rw.Result().Cookies() {
+		if cookie.Name == "traefik_saml" {
+			session = cookie
+		}
+	}
+	require.NotNil(t, session)
+	assert.True(t, session.HttpOnly)
+	assert.True(t, session.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, session.SameSite)
+
+	// With the session, the attributes are forwarded, and cannot be spoofed.
+	req := testhelpers.MustNewRequest(http.MethodGet, "https://app.example.com/private?page=1", nil)
+	req.AddCookie(session)
+	req.Header.Set("X-Forwarded-Groups", "root")
+	req.Header.Set("X-Forwarded-User", "mallory")
+	rw = httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "alice admins,devs alice@example.com", rw.Body.String())
+
+	// With a tampered session.
+	req = testhelpers.MustNewRequest(http.MethodGet, "https://app.example.com/private", nil)
+	req.AddCookie(&http.Cookie{Name: session.Name, Value: "x" + session.Value})
+	rw = httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusFound, rw.Code)
+
+	// With the same response, replayed.
+	rw = postSAMLResponse(middleware, response, requestID, requestCookies)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	// Without the request cookie.
+	rw = postSAMLResponse(middleware, response, requestID, nil)
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+}
+
+func Test_localRedirectURI(t *testing.T) {
+	testCases := []struct {
+		uri      string
+		expected string
+	}{
+		{uri: "/private?page=1", expected: "/private?page=1"},
+		{uri: "/", expected: "/"},
+		{uri: "//evil.example.com/path", expected: "/"},
+		{uri: "/\\evil.example.com/path", expected: "/"},
+		{uri: "https://evil.example.com", expected: "/"},
+		{uri: "", expected: "/"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.uri, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, localRedirectURI(test.uri))
+		})
+	}
+}
+
+func TestSAMLResponseValidation(t *testing.T) {
+	idpCertPEM, _, idpKey := newSAMLTestKeyPair(t, "idp.example.com")
+	spCertPEM, spKeyPEM, spKey := newSAMLTestKeyPair(t, "app.example.com")
+	_, _, otherKey := newSAMLTestKeyPair(t, "other.example.com")
+
+	testCases := []struct {
+		desc     string
+		response samlTestResponse
+		tamper   func(string) string
+		expected int
+	}{
+		{
+			desc:     "signed assertion",
+			response: samlTestResponse{signAssertion: idpKey},
+			expected: http.StatusSeeOther,
+		},
+		{
+			desc:     "signed response",
+			response: samlTestResponse{signResponse: idpKey},
+			expected: http.StatusSeeOther,
+		},
+		{
+			desc:     "encrypted assertion with AES-GCM",
+			response: samlTestResponse{signAssertion: idpKey, encrypt: &spKey.PublicKey, encryption: "http://www.w3.org/2009/xmlenc11#aes128-gcm"},
+			expected: http.StatusSeeOther,
+		},
+		{
+			desc:     "encrypted assertion with AES-CBC and signed response",
+			response: samlTestResponse{signResponse: idpKey, encrypt: &spKey.PublicKey, encryption: "http://www.w3.org/2001/04/xmlenc#aes128-cbc"},
+			expected: http.StatusSeeOther,
+		},
+		{
+			desc:     "unsigned",
+			response: samlTestResponse{},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "signed with another key",
+			response: samlTestResponse{signAssertion: otherKey},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "tampered after the signature",
+			response: samlTestResponse{signAssertion: idpKey},
+			tamper: func(response string) string {
+				return strings.Replace(response, ">alice<", ">mallory<", 1)
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "signature wrapping",
+			response: samlTestResponse{signAssertion: idpKey},
+			tamper: func(response string) string {
+				start := strings.Index(response, "<saml:Assertion ")
+				end := strings.Index(response, "</saml:Assertion>") + len("</saml:Assertion>")
+				signed := response[start:end]
+				forged := strings.Replace(strings.Replace(signed, ">alice<", ">mallory<", 1), `ID="assertion-1"`, `ID="assertion-2"`, 1)
+				return response[:start] + "<samlp:Extensions>" + signed + "</samlp:Extensions>" + stripSAMLSignature(forged) + response[end:]
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "two assertions",
+			response: samlTestResponse{signAssertion: idpKey},
+			tamper: func(response string) string {
+				start := strings.Index(response, "<saml:Assertion ")
+				end := strings.Index(response, "</saml:Assertion>") + len("</saml:Assertion>")
+				return response[:end] + strings.Replace(response[start:end], `ID="assertion-1"`, `ID="assertion-2"`, 1) + response[end:]
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "other audience",
+			response: samlTestResponse{signAssertion: idpKey, audience: "https://other.example.com"},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "other recipient",
+			response: samlTestResponse{signAssertion: idpKey, recipient: "https://other.example.com/saml/acs"},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "other issuer",
+			response: samlTestResponse{signAssertion: idpKey, issuer: "https://other.example.com"},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "other request",
+			response: samlTestResponse{signAssertion: idpKey, requestID: "id-other"},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "expired",
+			response: samlTestResponse{signAssertion: idpKey, issueInstant: time.Now().Add(-time.Hour)},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "not yet valid",
+			response: samlTestResponse{signAssertion: idpKey, issueInstant: time.Now().Add(time.Hour)},
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "failure status",
+			response: samlTestResponse{signResponse: idpKey, status: "urn:oasis:names:tc:SAML:2.0:status:Requester"},
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			config := dynamic.SAML{
+				RootURL:     "https://app.example.com/",
+				IDPMetadata: newSAMLTestIDPMetadata(idpCertPEM),
+				Certificate: string(spCertPEM),
+				Key:         string(spKeyPEM),
+			}
+			middleware, err := NewSAML(context.Background(), next, config, "samlTest")
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			middleware.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, "https://app.example.com/", nil))
+			require.Equal(t, http.StatusFound, rw.Code)
+
+			location, err := url.Parse(rw.Header().Get("Location"))
+			require.NoError(t, err)
+			requestID := location.Query().Get("RelayState")
+
+			if test.response.requestID == "" {
+				test.response.requestID = requestID
+			}
+
+			response := newSAMLTestResponse(t, test.response)
+			if test.tamper != nil {
+				response = test.tamper(response)
+			}
+
+			rw = postSAMLResponse(middleware, response, requestID, rw.Result().Cookies())
+			assert.Equal(t, test.expected, rw.Code)
+		})
+	}
+}
+
+func TestSAMLStepUp(t *testing.T) {
+	idpCertPEM, _, idpKey := newSAMLTestKeyPair(t, "idp.example.com")
+	spCertPEM, spKeyPEM, _ := newSAMLTestKeyPair(t, "app.example.com")
+
+	const mfa = "https://refeds.org/profile/mfa"
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.SAML{
+		RootURL:     "https://app.example.com",
+		IDPMetadata: newSAMLTestIDPMetadata(idpCertPEM),
+		Certificate: string(spCertPEM),
+		Key:         string(spKeyPEM),
+	}
+	middleware, err := NewSAML(context.Background(), next, config, "samlTest")
+	require.NoError(t, err)
+
+	// The admin routes share the session, but require a multi-factor authentication.
+	config.AuthnContextClassRefs = []string{mfa}
+	adminMiddleware, err := NewSAML(context.Background(), next, config, "samlAdminTest")
+	require.NoError(t, err)
+
+	login := func(handler http.Handler, target string, session *http.Cookie, authnContext string) (*httptest.ResponseRecorder, *xmlNode) {
+		t.Helper()
+
+		req := testhelpers.MustNewRequest(http.MethodGet, target, nil)
+		if session != nil {
+			req.AddCookie(session)
+		}
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusFound, rw.Code)
+
+		location, err := url.Parse(rw.Header().Get("Location"))
+		require.NoError(t, err)
+		authnRequest := inflateSAMLRequest(t, location.Query().Get("SAMLRequest"))
+
+		response := newSAMLTestResponse(t, samlTestResponse{
+			requestID:     authnRequest.attr("ID"),
+			assertionID:   "assertion-" + authnRequest.attr("ID"),
+			signAssertion: idpKey,
+			authnContext:  authnContext,
+		})
+
+		// The assertion consumer service is served by the middleware of the main routes.
+		return postSAMLResponse(middleware, response, authnRequest.attr("ID"), rw.Result().Cookies()), authnRequest
+	}
+
+	sessionCookie := func(rw *httptest.ResponseRecorder) *http.Cookie {
+		t.Helper()
+
+		for _, cookie := range rw.Result().Cookies() {
+			if cookie.Name == "traefik_saml" {
+				return cookie
+			}
+		}
+		require.Fail(t, "no session cookie")
+		return nil
+	}
+
+	rw, authnRequest := login(middleware, "https://app.example.com/", nil, "")
+	require.Equal(t, http.StatusSeeOther, rw.Code)
+	assert.Empty(t, authnRequest.attr("ForceAuthn"))
+	assert.Nil(t, authnRequest.child(samlProtocolNamespace, "RequestedAuthnContext"))
+	session := sessionCookie(rw)
+
+	// The session is elevated on the admin routes.
+	rw, authnRequest = login(adminMiddleware, "https://app.example.com/admin", session, "")
+	assert.Equal(t, "true", authnRequest.attr("ForceAuthn"))
+	requested := authnRequest.child(samlProtocolNamespace, "RequestedAuthnContext")
+	assert.Equal(t, "exact", requested.attr("Comparison"))
+	assert.Equal(t, mfa, requested.child(samlNamespace, "AuthnContextClassRef").text())
+
+	// The identity provider did not authenticate the user with the requested context.
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	rw, _ = login(adminMiddleware, "https://app.example.com/admin", session, mfa)
+	require.Equal(t, http.StatusSeeOther, rw.Code)
+	assert.Equal(t, "/admin", rw.Header().Get("Location"))
+	elevated := sessionCookie(rw)
+
+	for _, handler := range []http.Handler{middleware, adminMiddleware} {
+		req := testhelpers.MustNewRequest(http.MethodGet, "https://app.example.com/admin", nil)
+		req.AddCookie(elevated)
+		rw = httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		assert.Equal(t, http.StatusOK, rw.Code)
+	}
+
+	// The other methods are not redirected.
+	req := testhelpers.MustNewRequest(http.MethodPost, "https://app.example.com/admin", nil)
+	req.AddCookie(session)
+	rw = httptest.NewRecorder()
+	adminMiddleware.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+}
+
+func TestSAMLMetadata(t *testing.T) {
+	idpCertPEM, _, _ := newSAMLTestKeyPair(t, "idp.example.com")
+	spCertPEM, spKeyPEM, _ := newSAMLTestKeyPair(t, "app.example.com")
+
+	config := dynamic.SAML{
+		RootURL:      "https://app.example.com/app",
+		EntityID:     "urn:example:app",
+		IDPMetadata:  newSAMLTestIDPMetadata(idpCertPEM),
+		Certificate:  string(spCertPEM),
+		Key:          string(spKeyPEM),
+		NameIDFormat: "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress",
+	}
+	middleware, err := NewSAML(context.Background(), nil, config, "samlTest")
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	middleware.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, "https://app.example.com/app/saml/metadata", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	metadata, err := parseXML(rw.Body.Bytes())
+	require.NoError(t, err)
+	assert.True(t, metadata.is(samlMetadataNamespace, "EntityDescriptor"))
+	assert.Equal(t, "urn:example:app", metadata.attr("entityID"))
+
+	descriptor := metadata.child(samlMetadataNamespace, "SPSSODescriptor")
+	assert.Equal(t, "https://app.example.com/app/saml/acs", descriptor.child(samlMetadataNamespace, "AssertionConsumerService").attr("Location"))
+	assert.Equal(t, config.NameIDFormat, descriptor.child(samlMetadataNamespace, "NameIDFormat").text())
+
+	certificate := descriptor.child(samlMetadataNamespace, "KeyDescriptor").child(dsigNamespace, "KeyInfo").child(dsigNamespace, "X509Data").child(dsigNamespace, "X509Certificate")
+	block, _ := pem.Decode(spCertPEM)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(block.Bytes), certificate.text())
+}
+
+func TestNewSAMLInvalidConfig(t *testing.T) {
+	idpCertPEM, _, _ := newSAMLTestKeyPair(t, "idp.example.com")
+	spCertPEM, spKeyPEM, _ := newSAMLTestKeyPair(t, "app.example.com")
+	_, otherKeyPEM, _ := newSAMLTestKeyPair(t, "other.example.com")
+
+	valid := dynamic.SAML{
+		RootURL:     "https://app.example.com",
+		IDPMetadata: newSAMLTestIDPMetadata(idpCertPEM),
+		Certificate: string(spCertPEM),
+		Key:         string(spKeyPEM),
+	}
+
+	testCases := []struct {
+		desc   string
+		update func(*dynamic.SAML)
+	}{
+		{
+			desc:   "missing root URL",
+			update: func(config *dynamic.SAML) { config.RootURL = "" },
+		},
+		{
+			desc:   "relative root URL",
+			update: func(config *dynamic.SAML) { config.RootURL = "/app" },
+		},
+		{
+			desc:   "missing metadata",
+			update: func(config *dynamic.SAML) { config.IDPMetadata = "" },
+		},
+		{
+			desc: "metadata without signing certificate",
+			update: func(config *dynamic.SAML) {
+				config.IDPMetadata = strings.Replace(config.IDPMetadata, `use="signing"`, `use="encryption"`, 1)
+			},
+		},
+		{
+			desc:   "missing key",
+			update: func(config *dynamic.SAML) { config.Key = "" },
+		},
+		{
+			desc:   "mismatched key",
+			update: func(config *dynamic.SAML) { config.Key = string(otherKeyPEM) },
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := valid
+			test.update(&config)
+
+			_, err := NewSAML(context.Background(), nil, config, "samlTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
+// samlTestResponse describes a response of the test identity provider.
+type samlTestResponse struct {
+	requestID     string
+	assertionID   string
+	issueInstant  time.Time
+	issuer        string
+	audience      string
+	recipient     string
+	status        string
+	authnContext  string
+	signAssertion *rsa.PrivateKey
+	signResponse  *rsa.PrivateKey
+	encrypt       *rsa.PublicKey
+	encryption    string
+}
+
+const samlTestAssertionTemplate = `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="%[8]s" Version="2.0" IssueInstant="%[1]s">` +
+	`<saml:Issuer>%[2]s</saml:Issuer><!--signature %[8]s-->` +
+	`<saml:Subject><saml:NameID>alice</saml:NameID>` +
+	`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+	`<saml:SubjectConfirmationData InResponseTo="%[3]s" NotOnOrAfter="%[4]s" Recipient="%[5]s"/>` +
+	`</saml:SubjectConfirmation></saml:Subject>` +
+	`<saml:Conditions NotBefore="%[1]s" NotOnOrAfter="%[4]s">` +
+	`<saml:AudienceRestriction><saml:Audience>%[6]s</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+	`<saml:AuthnStatement AuthnInstant="%[1]s"><saml:AuthnContext>` +
+	`<saml:AuthnContextClassRef>%[7]s</saml:AuthnContextClassRef>` +
+	`</saml:AuthnContext></saml:AuthnStatement>` +
+	`<saml:AttributeStatement>` +
+	`<saml:Attribute Name="urn:oid:1.3.6.1.4.1.5923.1.5.1.1" FriendlyName="isMemberOf"><saml:AttributeValue>admins</saml:AttributeValue><saml:AttributeValue>devs</saml:AttributeValue></saml:Attribute>` +
+	`<saml:Attribute Name="mail"><saml:AttributeValue>alice@example.com</saml:AttributeValue></saml:Attribute>` +
+	`</saml:AttributeStatement></saml:Assertion>`
+
+const samlTestResponseTemplate = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion"` +
+	` ID="response-1" Version="2.0" IssueInstant="%[1]s" Destination="https://app.example.com/saml/acs" InResponseTo="%[2]s">` +
+	`<saml:Issuer>%[3]s</saml:Issuer><!--signature response-1-->` +
+	`<samlp:Status><samlp:StatusCode Value="%[4]s"/></samlp:Status>%[5]s</samlp:Response>`
+
+func newSAMLTestResponse(t *testing.T, r samlTestResponse) string {
+	t.Helper()
+
+	if r.issueInstant.IsZero() {
+		r.issueInstant = time.Now()
+	}
+	if r.assertionID == "" {
+		r.assertionID = "assertion-1"
+	}
+	if r.issuer == "" {
+		r.issuer = samlTestIDPEntityID
+	}
+	if r.audience == "" {
+		r.audience = samlTestEntityID
+	}
+	if r.recipient == "" {
+		r.recipient = samlTestACSURL
+	}
+	if r.status == "" {
+		r.status = samlStatusSuccess
+	}
+	if r.authnContext == "" {
+		r.authnContext = "urn:oasis:names:tc:SAML:2.0:ac:classes:PasswordProtectedTransport"
+	}
+
+	issueInstant := r.issueInstant.UTC().Format(time.RFC3339)
+	notOnOrAfter := r.issueInstant.Add(5 * time.Minute).UTC().Format(time.RFC3339)
+
+	assertion := fmt.Sprintf(samlTestAssertionTemplate, issueInstant, r.issuer, r.requestID, notOnOrAfter, r.recipient, r.audience, r.authnContext, r.assertionID)
+	if r.signAssertion != nil {
+		assertion = signSAMLTestDocument(t, assertion, r.assertionID, r.signAssertion)
+	}
+	if r.encrypt != nil {
+		assertion = encryptSAMLTestAssertion(t, assertion, r.encrypt, r.encryption)
+	}
+
+	response := fmt.Sprintf(samlTestResponseTemplate, issueInstant, r.requestID, r.issuer, r.status, assertion)
+	if r.signResponse != nil {
+		response = signSAMLTestDocument(t, response, "response-1", r.signResponse)
+	}
+
+	return response
+}
+
+// samlTestKeyStore is the key of an identity provider signing the test documents.
+type samlTestKeyStore struct {
+	key *rsa.PrivateKey
+}
+
+func (s samlTestKeyStore) GetKeyPair() (*rsa.PrivateKey, []byte, error) {
+	return s.key, nil, nil
+}
+
+// signSAMLTestDocument signs the element with the given ID, replacing its signature placeholder.
+func signSAMLTestDocument(t *testing.T, document, id string, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	placeholder := "<!--signature " + id + "-->"
+
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromString(strings.Replace(document, placeholder, "", 1)))
+
+	element := doc.FindElement(fmt.Sprintf("//[@ID='%s']", id))
+	require.NotNil(t, element)
+
+	ctx := dsig.NewDefaultSigningContext(samlTestKeyStore{key: key})
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	signature, err := ctx.ConstructSignature(element, true)
+	require.NoError(t, err)
+
+	// The signature does not carry the certificate, which is taken from the metadata of the identity provider.
+	if keyInfo := signature.SelectElement("KeyInfo"); keyInfo != nil {
+		signature.RemoveChild(keyInfo)
+	}
+
+	element.InsertChildAt(element.SelectElement("Issuer").Index()+1, signature)
+
+	signed, err := doc.WriteToString()
+	require.NoError(t, err)
+	return signed
+}
+
+func stripSAMLSignature(element string) string {
+	start := strings.Index(element, "<ds:Signature ")
+	end := strings.Index(element, "</ds:Signature>")
+	if start < 0 || end < 0 {
+		return element
+	}
+	return element[:start] + element[end+len("</ds:Signature>"):]
+}
+
+const samlTestEncryptedAssertionTemplate = `<saml:EncryptedAssertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">` +
+	`<xenc:EncryptedData xmlns:xenc="http://www.w3.org/2001/04/xmlenc#" Type="http://www.w3.org/2001/04/xmlenc#Element">` +
+	`<xenc:EncryptionMethod Algorithm="%s"/>` +
+	`<ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><xenc:EncryptedKey>` +
+	`<xenc:EncryptionMethod Algorithm="http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p"><ds:DigestMethod Algorithm="http://www.w3.org/2000/09/xmldsig#sha1"/></xenc:EncryptionMethod>` +
+	`<xenc:CipherData><xenc:CipherValue>%s</xenc:CipherValue></xenc:CipherData></xenc:EncryptedKey></ds:KeyInfo>` +
+	`<xenc:CipherData><xenc:CipherValue>%s</xenc:CipherValue></xenc:CipherData>` +
+	`</xenc:EncryptedData></saml:EncryptedAssertion>`
+
+// samlTestBlockAlgorithms holds the key size of the block encryption algorithms, and whether they are AES-GCM.
+var samlTestBlockAlgorithms = map[string]struct {
+	keySize int
+	gcm     bool
+}{
+	"http://www.w3.org/2001/04/xmlenc#aes128-cbc": {keySize: 16},
+	"http://www.w3.org/2001/04/xmlenc#aes256-cbc": {keySize: 32},
+	"http://www.w3.org/2009/xmlenc11#aes128-gcm":  {keySize: 16, gcm: true},
+}
+
+func encryptSAMLTestAssertion(t *testing.T, assertion string, publicKey *rsa.PublicKey, algorithm string) string {
+	t.Helper()
+
+	key := make([]byte, samlTestBlockAlgorithms[algorithm].keySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+
+	var ciphertext []byte
+	if samlTestBlockAlgorithms[algorithm].gcm {
+		aead, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+
+		nonce := make([]byte, aead.NonceSize())
+		_, err = rand.Read(nonce)
+		require.NoError(t, err)
+
+		ciphertext = aead.Seal(nonce, nonce, []byte(assertion), nil)
+	} else {
+		padding := aes.BlockSize - len(assertion)%aes.BlockSize
+		plaintext := append([]byte(assertion), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+		ciphertext = make([]byte, aes.BlockSize+len(plaintext))
+		_, err = rand.Read(ciphertext[:aes.BlockSize])
+		require.NoError(t, err)
+
+		cipher.NewCBCEncrypter(block, ciphertext[:aes.BlockSize]).CryptBlocks(ciphertext[aes.BlockSize:], plaintext)
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, publicKey, key, nil)
+	require.NoError(t, err)
+
+	return fmt.Sprintf(samlTestEncryptedAssertionTemplate, algorithm,
+		base64.StdEncoding.EncodeToString(encryptedKey), base64.StdEncoding.EncodeToString(ciphertext))
+}
+
+func postSAMLResponse(handler http.Handler, response, relayState string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	form := url.Values{}
+	form.Set("SAMLResponse", base64.StdEncoding.EncodeToString([]byte(response)))
+	form.Set("RelayState", relayState)
+
+	req := testhelpers.MustNewRequest(http.MethodPost, samlTestACSURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	return rw
+}
+
+func inflateSAMLRequest(t *testing.T, encoded string) *xmlNode {
+	t.Helper()
+
+	deflated, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+
+	request, err := parseXML(data)
+	require.NoError(t, err)
+	return request
+}
+
+func findXMLID(root *xmlNode, id string) *xmlNode {
+	var found *xmlNode
+	root.walk(func(n *xmlNode) {
+		if n.attr("ID") == id {
+			found = n
+		}
+	})
+	return found
+}
+
+func newSAMLTestIDPMetadata(certPEM []byte) string {
+	block, _ := pem.Decode(certPEM)
+
+	return `<md:EntitiesDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata">` +
+		`<md:EntityDescriptor entityID="` + samlTestIDPEntityID + `">` +
+		`<md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">` +
+		`<md:KeyDescriptor use="signing"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>` +
+		base64.StdEncoding.EncodeToString(block.Bytes) +
+		`</ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor>` +
+		`<md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>` +
+		`<md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="` + samlTestSSOURL + `"/>` +
+		`</md:IDPSSODescriptor></md:EntityDescriptor></md:EntitiesDescriptor>`
+}
+
+func newSAMLTestKeyPair(t *testing.T, commonName string) ([]byte, []byte, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, key
+}
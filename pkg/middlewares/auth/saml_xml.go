@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml/xmlenc"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	dsigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+	xencNamespace = "http://www.w3.org/2001/04/xmlenc#"
+)
+
+// rsaOAEPAlgorithm is the only key transport accepted for the encrypted assertions,
+// as RSA PKCS#1 v1.5 is vulnerable to padding oracle attacks.
+const rsaOAEPAlgorithm = "http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p"
+
+// xmlNode is an element of a parsed XML document, whose accessors accept a nil element.
+type xmlNode struct {
+	el *etree.Element
+}
+
+// parseXML parses a XML document, rejecting the DTDs,
+// and the documents which would not round-trip through encoding/xml unchanged.
+func parseXML(data []byte) (*xmlNode, error) {
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+
+	for _, token := range doc.Child {
+		if _, ok := token.(*etree.Directive); ok {
+			return nil, errors.New("xml: DTDs are not supported")
+		}
+	}
+
+	root := doc.Root()
+	if root == nil {
+		return nil, errors.New("xml: no root element")
+	}
+
+	return &xmlNode{el: root}, nil
+}
+
+func (n *xmlNode) is(namespace, local string) bool {
+	return n != nil && n.el.Tag == local && n.el.NamespaceURI() == namespace
+}
+
+// childrenNamed returns the child elements with the given name.
+func (n *xmlNode) childrenNamed(namespace, local string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+
+	var children []*xmlNode
+	for _, el := range n.el.ChildElements() {
+		child := &xmlNode{el: el}
+		if child.is(namespace, local) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// child returns the first child element with the given name.
+func (n *xmlNode) child(namespace, local string) *xmlNode {
+	children := n.childrenNamed(namespace, local)
+	if len(children) == 0 {
+		return nil
+	}
+	return children[0]
+}
+
+// attr returns the value of an attribute without namespace.
+func (n *xmlNode) attr(local string) string {
+	if n == nil {
+		return ""
+	}
+
+	for _, attr := range n.el.Attr {
+		if attr.Space == "" && attr.Key == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// text returns the text content of the element.
+func (n *xmlNode) text() string {
+	if n == nil {
+		return ""
+	}
+
+	var text bytes.Buffer
+	var write func(el *etree.Element)
+	write = func(el *etree.Element) {
+		for _, token := range el.Child {
+			switch t := token.(type) {
+			case *etree.CharData:
+				text.WriteString(t.Data)
+			case *etree.Element:
+				write(t)
+			}
+		}
+	}
+	write(n.el)
+
+	return text.String()
+}
+
+// walk calls fn for the element and all its descendants.
+func (n *xmlNode) walk(fn func(*xmlNode)) {
+	fn(n)
+	for _, el := range n.el.ChildElements() {
+		(&xmlNode{el: el}).walk(fn)
+	}
+}
+
+// detach returns a copy of the element, without parent,
+// declaring the namespaces it inherits from its ancestors.
+func (n *xmlNode) detach() *etree.Element {
+	el := n.el.Copy()
+
+	declared := make(map[string]bool)
+	for _, attr := range el.Attr {
+		if attr.Space == "xmlns" {
+			declared[attr.Key] = true
+		} else if attr.Space == "" && attr.Key == "xmlns" {
+			declared[""] = true
+		}
+	}
+
+	for parent := n.el.Parent(); parent != nil; parent = parent.Parent() {
+		for _, attr := range parent.Attr {
+			prefix := attr.Key
+			switch {
+			case attr.Space == "xmlns":
+			case attr.Space == "" && attr.Key == "xmlns":
+				prefix = ""
+			default:
+				continue
+			}
+
+			if !declared[prefix] {
+				declared[prefix] = true
+				el.CreateAttr(attr.FullKey(), attr.Value)
+			}
+		}
+	}
+
+	return el
+}
+
+// verifyXMLSignature verifies the enveloped signature of the element, with one of the given certificates,
+// and returns the signed element, which is the only one which can be trusted.
+func verifyXMLSignature(signed *xmlNode, certificates []*x509.Certificate) (*xmlNode, error) {
+	if len(signed.childrenNamed(dsigNamespace, "Signature")) != 1 {
+		return nil, errors.New("the element must have exactly one signature")
+	}
+
+	el := signed.detach()
+
+	// The certificates are tried one by one, as the signatures do not always reference theirs.
+	var err error
+	for _, certificate := range certificates {
+		ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{certificate}})
+
+		var validated *etree.Element
+		validated, err = ctx.Validate(el)
+		if err == nil {
+			return &xmlNode{el: validated}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid signature: %w", err)
+}
+
+// decryptXML decrypts the EncryptedData child of the element, with the given private key,
+// and returns the decrypted element.
+func decryptXML(encrypted *xmlNode, key *rsa.PrivateKey) (*xmlNode, error) {
+	encryptedData := encrypted.child(xencNamespace, "EncryptedData")
+	if encryptedData == nil {
+		return nil, errors.New("missing EncryptedData")
+	}
+
+	// The key is either in the KeyInfo of the data, or next to it.
+	encryptedKey := encryptedData.child(dsigNamespace, "KeyInfo").child(xencNamespace, "EncryptedKey")
+	if encryptedKey == nil {
+		encryptedKey = encrypted.child(xencNamespace, "EncryptedKey")
+	}
+	if encryptedKey == nil {
+		return nil, errors.New("missing EncryptedKey")
+	}
+
+	if algorithm := encryptedKey.child(xencNamespace, "EncryptionMethod").attr("Algorithm"); algorithm != rsaOAEPAlgorithm {
+		return nil, fmt.Errorf("unsupported key transport %q", algorithm)
+	}
+
+	blockKey, err := xmlenc.Decrypt(key, encryptedKey.el)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the key: %w", err)
+	}
+
+	data := encryptedData.el.Copy()
+	if keyInfo := data.SelectElement("KeyInfo"); keyInfo != nil {
+		data.RemoveChild(keyInfo)
+	}
+
+	plaintext, err := xmlenc.Decrypt(blockKey, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the data: %w", err)
+	}
+
+	decrypted, err := parseXML(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// The decrypted element replaces the encrypted one, in the scope of its namespaces.
+	if parent := encrypted.el.Parent(); parent != nil {
+		parent.InsertChildAt(encrypted.el.Index(), decrypted.el)
+		parent.RemoveChild(encrypted.el)
+	}
+
+	return decrypted, nil
+}
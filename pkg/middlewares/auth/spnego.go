@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	spnegoTypeName = "SPNEGOAuth"
+	negotiate      = "Negotiate"
+)
+
+// maxClockSkew is the maximum difference allowed between the clocks of the clients and the one of Traefik.
+const maxClockSkew = 5 * time.Minute
+
+// acceptCompleted is the SPNEGO NegTokenResp with the accept-completed state and the Kerberos mechanism,
+// sent to the clients once authenticated.
+var acceptCompleted = base64.StdEncoding.EncodeToString([]byte{
+	0xa1, 0x14, 0x30, 0x12, 0xa0, 0x03, 0x0a, 0x01, 0x00, 0xa1, 0x0b,
+	0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x12, 0x01, 0x02, 0x02,
+})
+
+type spnegoAuth struct {
+	next         http.Handler
+	name         string
+	keytab       *keytab.Keytab
+	settings     []func(*service.Settings)
+	serviceRealm string
+	headerField  string
+	removeHeader bool
+	stripRealm   bool
+	delegation   *delegation
+}
+
+// NewSPNEGO creates a Kerberos authentication middleware, with the SPNEGO (Negotiate) HTTP authentication scheme.
+func NewSPNEGO(ctx context.Context, next http.Handler, config dynamic.SPNEGOAuth, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, spnegoTypeName)).Debug("Creating middleware")
+
+	if config.Keytab == "" {
+		return nil, errors.New("the keytab is required")
+	}
+
+	kt, err := keytab.Load(config.Keytab)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the keytab %s: %w", config.Keytab, err)
+	}
+
+	auth := &spnegoAuth{
+		next:         next,
+		name:         name,
+		keytab:       kt,
+		settings:     []func(*service.Settings){service.DecodePAC(false), service.MaxClockSkew(maxClockSkew)},
+		headerField:  config.HeaderField,
+		removeHeader: config.RemoveHeader,
+		stripRealm:   config.StripRealm,
+	}
+
+	if config.ServicePrincipal != "" {
+		_, auth.serviceRealm = types.ParseSPNString(config.ServicePrincipal)
+		auth.settings = append(auth.settings, service.KeytabPrincipal(config.ServicePrincipal))
+	}
+
+	if config.Delegation != nil {
+		if config.ServicePrincipal == "" {
+			return nil, errors.New("the service principal is required by the delegation")
+		}
+
+		auth.delegation, err = newDelegation(kt, config.ServicePrincipal, *config.Delegation)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure the delegation: %w", err)
+		}
+	}
+
+	return auth, nil
+}
+
+func (s *spnegoAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return s.name, tracing.SpanKindNoneEnum
+}
+
+func (s *spnegoAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), s.name, spnegoTypeName))
+
+	authorization := req.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, negotiate+" ") {
+		logger.Debug("Authentication required")
+		tracing.SetErrorWithEvent(req, "Authentication required")
+
+		rw.Header().Set("WWW-Authenticate", negotiate)
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	apReq, err := s.authenticate(req, strings.TrimSpace(strings.TrimPrefix(authorization, negotiate)))
+	if err != nil {
+		logger.Debugf("Authentication failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		rw.Header().Set("WWW-Authenticate", negotiate)
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	principal := apReq.Ticket.DecryptedEncPart.CName.PrincipalNameString()
+	if !s.stripRealm {
+		principal += "@" + apReq.Ticket.DecryptedEncPart.CRealm
+	}
+
+	logger.Debugf("Authentication succeeded for %s", principal)
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.ClientUsername] = principal
+	}
+
+	if s.headerField != "" {
+		req.Header[s.headerField] = []string{principal}
+	}
+
+	if s.removeHeader {
+		logger.Debug("Removing authorization header")
+		req.Header.Del("Authorization")
+	}
+
+	if s.delegation != nil {
+		token, err := s.delegation.token(apReq.Ticket)
+		if err != nil {
+			if errors.Is(err, errNotForwardable) {
+				logger.Debugf("Unable to delegate the credentials of %s: %v", principal, err)
+				tracing.SetErrorWithEvent(req, "Delegation forbidden")
+
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			logger.Errorf("Unable to delegate the credentials of %s: %v", principal, err)
+			tracing.SetErrorWithEvent(req, "Delegation failed")
+
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		req.Header.Set("Authorization", negotiate+" "+token)
+	}
+
+	rw.Header().Set("WWW-Authenticate", negotiate+" "+acceptCompleted)
+	s.next.ServeHTTP(rw, req)
+}
+
+// authenticate verifies the Kerberos AP-REQ of the Negotiate token,
+// and returns it with the decrypted ticket of the client.
+func (s *spnegoAuth) authenticate(req *http.Request, encodedToken string) (*messages.APReq, error) {
+	data, err := base64.StdEncoding.DecodeString(encodedToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token encoding: %w", err)
+	}
+
+	token, err := parseNegotiateToken(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.serviceRealm != "" && token.APReq.Ticket.Realm != s.serviceRealm {
+		return nil, fmt.Errorf("the ticket is for the realm %s", token.APReq.Ticket.Realm)
+	}
+
+	settings := s.settings
+	if addr, err := types.GetHostAddress(req.RemoteAddr); err == nil {
+		settings = append([]func(*service.Settings){service.ClientAddress(addr)}, settings...)
+	}
+
+	ok, _, err := service.VerifyAPREQ(&token.APReq, service.NewSettings(s.keytab, settings...))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("invalid AP-REQ")
+	}
+
+	return &token.APReq, nil
+}
+
+// parseNegotiateToken returns the Kerberos token of a SPNEGO, or raw Kerberos, GSS-API token.
+func parseNegotiateToken(data []byte) (*spnego.KRB5Token, error) {
+	var negToken spnego.SPNEGOToken
+	if err := negToken.Unmarshal(data); err == nil {
+		if !negToken.Init {
+			return nil, errors.New("invalid SPNEGO token: not a NegTokenInit")
+		}
+
+		mechTypes := negToken.NegTokenInit.MechTypes
+		if len(mechTypes) == 0 || !(mechTypes[0].Equal(gssapi.OIDKRB5.OID()) || mechTypes[0].Equal(gssapi.OIDMSLegacyKRB5.OID())) {
+			return nil, errors.New("invalid SPNEGO token: unsupported mechanism")
+		}
+
+		if len(negToken.NegTokenInit.MechTokenBytes) == 0 {
+			return nil, errors.New("invalid SPNEGO token: no Kerberos token")
+		}
+
+		data = negToken.NegTokenInit.MechTokenBytes
+	}
+
+	var token spnego.KRB5Token
+	if err := token.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("invalid Kerberos token: %w", err)
+	}
+
+	if !token.IsAPReq() {
+		return nil, errors.New("invalid Kerberos token: not an AP-REQ")
+	}
+
+	return &token, nil
+}
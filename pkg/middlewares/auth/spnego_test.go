@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/iana/chksumtype"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/flags"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/iana/patype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPNEGOAuth(t *testing.T) {
+	kt := newKeytab(t, "HTTP/www.example.com", "secret")
+
+	keytabPath := filepath.Join(t.TempDir(), "service.keytab")
+	writeKeytab(t, keytabPath, kt)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprintln(w, r.Header.Get("X-Forwarded-User"))
+	})
+
+	config := dynamic.SPNEGOAuth{
+		Keytab:           keytabPath,
+		ServicePrincipal: "HTTP/www.example.com@EXAMPLE.COM",
+		HeaderField:      "X-Forwarded-User",
+		RemoveHeader:     true,
+	}
+	middleware, err := NewSPNEGO(context.Background(), next, config, "spnegoTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	// Without token.
+	res, err := http.DefaultClient.Do(testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, "Negotiate", res.Header.Get("WWW-Authenticate"))
+	require.NoError(t, res.Body.Close())
+
+	// With a valid token.
+	tkt := newTicket(t, kt, "HTTP/www.example.com", flags.Forwardable)
+	token := newClientToken(t, tkt, time.Now())
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Authorization", "Negotiate "+token)
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Negotiate "+acceptCompleted, res.Header.Get("WWW-Authenticate"))
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, "alice@EXAMPLE.COM\n", string(body))
+
+	// With the same token, replayed.
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	// With an old authenticator.
+	req.Header.Set("Authorization", "Negotiate "+newClientToken(t, tkt, time.Now().Add(-time.Hour)))
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	// With a ticket encrypted with another key.
+	otherTicket := newTicket(t, newKeytab(t, "HTTP/www.example.com", "other"), "HTTP/www.example.com", flags.Forwardable)
+
+	req.Header.Set("Authorization", "Negotiate "+newClientToken(t, otherTicket, time.Now()))
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+}
+
+func TestNewSPNEGOInvalidKeytab(t *testing.T) {
+	_, err := NewSPNEGO(context.Background(), nil, dynamic.SPNEGOAuth{}, "spnegoTest")
+	assert.Error(t, err)
+
+	keytabPath := filepath.Join(t.TempDir(), "service.keytab")
+	require.NoError(t, ioutil.WriteFile(keytabPath, []byte("invalid"), 0o600))
+
+	_, err = NewSPNEGO(context.Background(), nil, dynamic.SPNEGOAuth{Keytab: keytabPath}, "spnegoTest")
+	assert.Error(t, err)
+
+	_, err = NewSPNEGO(context.Background(), nil, dynamic.SPNEGOAuth{Keytab: filepath.Join(t.TempDir(), "missing")}, "spnegoTest")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestSPNEGOAuth_delegation(t *testing.T) {
+	kt := newKeytab(t, "HTTP/www.example.com", "secret")
+	backendKeytab := newKeytab(t, "HTTP/backend.example.com", "backend")
+
+	keytabPath := filepath.Join(t.TempDir(), "service.keytab")
+	writeKeytab(t, keytabPath, kt)
+
+	krb5Conf := filepath.Join(t.TempDir(), "krb5.conf")
+	err := ioutil.WriteFile(krb5Conf, []byte("[libdefaults]\n  default_realm = EXAMPLE.COM\n"), 0o600)
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The token of the client is verified by the service, with its own keys.
+		token, err := base64.StdEncoding.DecodeString(r.Header.Get("Authorization")[len("Negotiate "):])
+		require.NoError(t, err)
+
+		krb5Token, err := parseNegotiateToken(token)
+		require.NoError(t, err)
+
+		ok, creds, err := service.VerifyAPREQ(&krb5Token.APReq, service.NewSettings(backendKeytab, service.DecodePAC(false)))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		fmt.Fprintln(w, creds.UserName()+"@"+creds.Domain())
+	})
+
+	config := dynamic.SPNEGOAuth{
+		Keytab:           keytabPath,
+		ServicePrincipal: "HTTP/www.example.com@EXAMPLE.COM",
+		Delegation: &dynamic.SPNEGODelegation{
+			Krb5Conf:         krb5Conf,
+			ServicePrincipal: "HTTP/backend.example.com",
+		},
+	}
+	middleware, err := NewSPNEGO(context.Background(), next, config, "spnegoTest")
+	require.NoError(t, err)
+
+	// The ticket to the service, issued by the KDC for the client, is already known.
+	backendTicket, backendKey, err := messages.NewTicket(
+		types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "alice"), "EXAMPLE.COM",
+		types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "HTTP/backend.example.com"), "EXAMPLE.COM",
+		types.NewKrbFlags(), backendKeytab, etypeID.AES256_CTS_HMAC_SHA1_96, 1,
+		time.Now(), time.Now(), time.Now().Add(time.Hour), time.Now().Add(time.Hour),
+	)
+	require.NoError(t, err)
+
+	middleware.(*spnegoAuth).delegation.tickets["alice@EXAMPLE.COM"] = &kerberosTicket{
+		ticket:  backendTicket,
+		key:     backendKey,
+		endTime: time.Now().Add(time.Hour),
+	}
+
+	ts := httptest.NewServer(middleware)
+	t.Cleanup(ts.Close)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Authorization", "Negotiate "+newClientToken(t, newTicket(t, kt, "HTTP/www.example.com", flags.Forwardable), time.Now()))
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, "alice@EXAMPLE.COM\n", string(body))
+
+	// The ticket of the client cannot be delegated.
+	req.Header.Set("Authorization", "Negotiate "+newClientToken(t, newTicket(t, kt, "HTTP/www.example.com"), time.Now()))
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+}
+
+func Test_newS4U2ProxyRequest(t *testing.T) {
+	kt := newKeytab(t, "HTTP/www.example.com", "secret")
+	krbtgtKeytab := newKeytab(t, "krbtgt/EXAMPLE.COM", "krbtgt")
+
+	cfg, err := config.NewFromString("[libdefaults]\n  default_realm = EXAMPLE.COM\n")
+	require.NoError(t, err)
+
+	cname := types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "HTTP/www.example.com")
+
+	tgtTicket, tgtKey, err := messages.NewTicket(
+		cname, "EXAMPLE.COM",
+		types.NewPrincipalName(nametype.KRB_NT_SRV_INST, "krbtgt/EXAMPLE.COM"), "EXAMPLE.COM",
+		types.NewKrbFlags(), krbtgtKeytab, etypeID.AES256_CTS_HMAC_SHA1_96, 1,
+		time.Now(), time.Now(), time.Now().Add(time.Hour), time.Now().Add(time.Hour),
+	)
+	require.NoError(t, err)
+
+	clientTicket := newTicket(t, kt, "HTTP/www.example.com", flags.Forwardable)
+	backend := types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "HTTP/backend.example.com")
+
+	req, err := newS4U2ProxyRequest(cname, "EXAMPLE.COM", cfg, &kerberosTicket{ticket: tgtTicket, key: tgtKey}, backend, clientTicket)
+	require.NoError(t, err)
+
+	assert.True(t, types.IsFlagSet(&req.ReqBody.KDCOptions, kdcOptionCNameInAddlTkt))
+	assert.True(t, types.IsFlagSet(&req.ReqBody.KDCOptions, flags.Forwardable))
+	require.Len(t, req.ReqBody.AdditionalTickets, 1)
+	assert.Equal(t, clientTicket.EncPart, req.ReqBody.AdditionalTickets[0].EncPart)
+	assert.True(t, req.ReqBody.SName.Equal(backend))
+	assert.True(t, req.ReqBody.CName.Equal(types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "alice")))
+
+	// The KDC authenticates Traefik with the PA-TGS-REQ, checking the checksum of the body.
+	require.Len(t, req.PAData, 1)
+	require.Equal(t, patype.PA_TGS_REQ, req.PAData[0].PADataType)
+
+	var apReq messages.APReq
+	require.NoError(t, apReq.Unmarshal(req.PAData[0].PADataValue))
+	require.NoError(t, apReq.DecryptAuthenticator(tgtKey))
+	assert.True(t, apReq.Authenticator.CName.Equal(cname))
+
+	body, err := req.ReqBody.Marshal()
+	require.NoError(t, err)
+
+	etype, err := crypto.GetEtype(tgtKey.KeyType)
+	require.NoError(t, err)
+	assert.True(t, etype.VerifyChecksum(tgtKey.KeyValue, body, apReq.Authenticator.Cksum.Checksum, keyusage.TGS_REQ_PA_TGS_REQ_AP_REQ_AUTHENTICATOR_CHKSUM))
+}
+
+func newKeytab(t *testing.T, principal, password string) *keytab.Keytab {
+	t.Helper()
+
+	kt := keytab.New()
+	require.NoError(t, kt.AddEntry(principal, "EXAMPLE.COM", password, time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96))
+
+	return kt
+}
+
+func writeKeytab(t *testing.T, path string, kt *keytab.Keytab) {
+	t.Helper()
+
+	data, err := kt.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, data, 0o600))
+}
+
+// newTicket creates the ticket of the alice@EXAMPLE.COM client for the service, with its decrypted part.
+func newTicket(t *testing.T, kt *keytab.Keytab, servicePrincipal string, ticketFlags ...int) messages.Ticket {
+	t.Helper()
+
+	krbFlags := types.NewKrbFlags()
+	for _, flag := range ticketFlags {
+		types.SetFlag(&krbFlags, flag)
+	}
+
+	now := time.Now().UTC()
+	sname := types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, servicePrincipal)
+
+	tkt, _, err := messages.NewTicket(
+		types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "alice"), "EXAMPLE.COM",
+		sname, "EXAMPLE.COM", krbFlags, kt, etypeID.AES256_CTS_HMAC_SHA1_96, 1,
+		now, now, now.Add(10*time.Hour), now.Add(24*time.Hour),
+	)
+	require.NoError(t, err)
+	require.NoError(t, tkt.DecryptEncPart(kt, &sname))
+
+	return tkt
+}
+
+// newClientToken creates the SPNEGO token of the client of the ticket.
+func newClientToken(t *testing.T, tkt messages.Ticket, ctime time.Time) string {
+	t.Helper()
+
+	auth, err := types.NewAuthenticator(tkt.DecryptedEncPart.CRealm, tkt.DecryptedEncPart.CName)
+	require.NoError(t, err)
+	auth.CTime = ctime.UTC()
+	auth.Cksum = types.Checksum{CksumType: chksumtype.GSSAPI, Checksum: make([]byte, 24)}
+
+	token, err := marshalNegotiateToken(&kerberosTicket{ticket: tkt, key: tkt.DecryptedEncPart.Key}, auth)
+	require.NoError(t, err)
+
+	return token
+}
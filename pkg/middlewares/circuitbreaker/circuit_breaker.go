@@ -8,6 +8,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/go-kit/kit/metrics"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/cbreaker"
 )
@@ -22,14 +23,21 @@ type circuitBreaker struct {
 }
 
 // New creates a new circuit breaker middleware.
-func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, name string) (http.Handler, error) {
+// Its state, and how many times it opened, are recorded in the metrics, labelled with the name of the service it protects.
+func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, stateGauge metrics.Gauge, tripsCounter metrics.Counter, name, serviceName string) (http.Handler, error) {
 	expression := confCircuitBreaker.Expression
 
 	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
 	logger.Debug("Creating middleware")
 	logger.Debug("Setting up with expression: %s", expression)
 
-	oxyCircuitBreaker, err := cbreaker.New(next, expression, createCircuitBreakerOptions(expression))
+	recorder := newStateRecorder(stateGauge, tripsCounter, name, serviceName)
+
+	oxyCircuitBreaker, err := cbreaker.New(recorder.wrap(next), expression,
+		createCircuitBreakerOptions(expression),
+		cbreaker.OnTripped(sideEffect(recorder.tripped)),
+		cbreaker.OnStandby(sideEffect(recorder.standby)),
+	)
 	if err != nil {
 		return nil, err
 	}
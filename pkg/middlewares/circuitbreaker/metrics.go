@@ -0,0 +1,104 @@
+package circuitbreaker
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	stateClosed     = "closed"
+	stateOpen       = "open"
+	stateRecovering = "recovering"
+)
+
+var states = []string{stateClosed, stateOpen, stateRecovering}
+
+// stateRecorder records the state of a circuit breaker, and how many times it opened, in the metrics.
+// The state gauge is valued with 1 for the current state, and 0 for the others.
+type stateRecorder struct {
+	stateGauge   metrics.Gauge
+	tripsCounter metrics.Counter
+	labels       []string
+
+	mu    sync.Mutex
+	state string
+}
+
+func newStateRecorder(stateGauge metrics.Gauge, tripsCounter metrics.Counter, name, serviceName string) *stateRecorder {
+	r := &stateRecorder{
+		stateGauge:   stateGauge,
+		tripsCounter: tripsCounter,
+		labels:       []string{"middleware", name, "service", serviceName},
+	}
+
+	r.mu.Lock()
+	r.setState(stateClosed)
+	r.mu.Unlock()
+
+	return r
+}
+
+// tripped records the opening of the circuit breaker.
+func (r *stateRecorder) tripped() {
+	if r.tripsCounter != nil {
+		r.tripsCounter.With(r.labels...).Add(1)
+	}
+
+	r.mu.Lock()
+	r.setState(stateOpen)
+	r.mu.Unlock()
+}
+
+// standby records the closing of the circuit breaker.
+func (r *stateRecorder) standby() {
+	r.mu.Lock()
+	r.setState(stateClosed)
+	r.mu.Unlock()
+}
+
+// forwarded records a request forwarded by the circuit breaker,
+// which, as an open circuit breaker does not forward any, means that an open one is recovering.
+func (r *stateRecorder) forwarded() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == stateOpen {
+		r.setState(stateRecovering)
+	}
+}
+
+// wrap returns the handler recording the requests forwarded to the next one.
+func (r *stateRecorder) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		r.forwarded()
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// setState must be called with the lock held.
+func (r *stateRecorder) setState(state string) {
+	r.state = state
+
+	if r.stateGauge == nil {
+		return
+	}
+
+	for _, s := range states {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		r.stateGauge.With(append(r.labels, "state", s)...).Set(value)
+	}
+}
+
+// sideEffect is a circuit breaker side effect calling a function.
+type sideEffect func()
+
+// Exec implements cbreaker.SideEffect.
+func (s sideEffect) Exec() error {
+	s()
+	return nil
+}
@@ -0,0 +1,122 @@
+package circuitbreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateRecorder(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		events        []func(r *stateRecorder)
+		expectedState string
+		expectedTrips float64
+	}{
+		{
+			desc:          "closed on creation",
+			expectedState: stateClosed,
+		},
+		{
+			desc:          "forwarded while closed",
+			events:        []func(r *stateRecorder){(*stateRecorder).forwarded},
+			expectedState: stateClosed,
+		},
+		{
+			desc:          "tripped",
+			events:        []func(r *stateRecorder){(*stateRecorder).tripped},
+			expectedState: stateOpen,
+			expectedTrips: 1,
+		},
+		{
+			desc:          "forwarded while open",
+			events:        []func(r *stateRecorder){(*stateRecorder).tripped, (*stateRecorder).forwarded},
+			expectedState: stateRecovering,
+			expectedTrips: 1,
+		},
+		{
+			desc:          "standby after recovering",
+			events:        []func(r *stateRecorder){(*stateRecorder).tripped, (*stateRecorder).forwarded, (*stateRecorder).standby},
+			expectedState: stateClosed,
+			expectedTrips: 1,
+		},
+		{
+			desc:          "tripped again while recovering",
+			events:        []func(r *stateRecorder){(*stateRecorder).tripped, (*stateRecorder).forwarded, (*stateRecorder).tripped},
+			expectedState: stateOpen,
+			expectedTrips: 2,
+		},
+		{
+			desc:          "forwarded after standby",
+			events:        []func(r *stateRecorder){(*stateRecorder).tripped, (*stateRecorder).standby, (*stateRecorder).forwarded},
+			expectedState: stateClosed,
+			expectedTrips: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gauge := &stateGauge{values: make(map[string]float64)}
+			counter := &testhelpers.CollectingCounter{}
+
+			recorder := newStateRecorder(gauge, counter, "cb@file", "api@file")
+			for _, event := range test.events {
+				event(recorder)
+			}
+
+			expected := make(map[string]float64)
+			for _, state := range states {
+				expected["middleware,cb@file,service,api@file,state,"+state] = 0
+			}
+			expected["middleware,cb@file,service,api@file,state,"+test.expectedState] = 1
+
+			assert.Equal(t, expected, gauge.values)
+			assert.Equal(t, test.expectedTrips, counter.CounterValue)
+			if test.expectedTrips > 0 {
+				assert.Equal(t, []string{"middleware", "cb@file", "service", "api@file"}, counter.LastLabelValues)
+			}
+		})
+	}
+}
+
+func TestStateRecorder_wrap(t *testing.T) {
+	gauge := &stateGauge{values: make(map[string]float64)}
+	recorder := newStateRecorder(gauge, nil, "cb@file", "api@file")
+	recorder.tripped()
+
+	handler := recorder.wrap(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+	assert.Equal(t, float64(1), gauge.values["middleware,cb@file,service,api@file,state,recovering"])
+}
+
+// stateGauge is a gauge recording the last value set for each label values.
+type stateGauge struct {
+	values      map[string]float64
+	labelValues []string
+}
+
+func (g *stateGauge) With(labelValues ...string) metrics.Gauge {
+	return &stateGauge{values: g.values, labelValues: labelValues}
+}
+
+func (g *stateGauge) Set(value float64) {
+	g.values[strings.Join(g.labelValues, ",")] = value
+}
+
+func (g *stateGauge) Add(delta float64) {
+	g.values[strings.Join(g.labelValues, ",")] += delta
+}
@@ -0,0 +1,304 @@
+package coalescing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+)
+
+const (
+	typeName = "Coalescing"
+
+	defaultMaxBodySize = 10 * 1024 * 1024
+)
+
+// keyHeaders are the request headers always distinguishing the requests,
+// so that the responses are never shared between different users, or different parts of a resource.
+var keyHeaders = []string{"Authorization", "Cookie", "Range"}
+
+// coalescing sends a single request to the next handler for the concurrent identical GET requests,
+// and sends its response to all of them.
+type coalescing struct {
+	next        http.Handler
+	headers     []string
+	maxBodySize int64
+	name        string
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is a request in flight, whose response is shared with the identical requests.
+type call struct {
+	done    chan struct{}
+	waiters int
+
+	// shared tells whether the response can be sent to the identical requests.
+	shared  bool
+	status  int
+	header  http.Header
+	body    []byte
+	trailer http.Header
+}
+
+// New creates a coalescing middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Coalescing, name string) http.Handler {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	headers := append([]string{}, keyHeaders...)
+	for _, header := range config.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(header))
+	}
+
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
+	return &coalescing{
+		next:        next,
+		headers:     headers,
+		maxBodySize: maxBodySize,
+		name:        name,
+		calls:       make(map[string]*call),
+	}
+}
+
+func (c *coalescing) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet || req.ContentLength > 0 || req.Header.Get("Upgrade") != "" {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	key := c.key(req)
+
+	c.mu.Lock()
+	if inFlight, ok := c.calls[key]; ok {
+		inFlight.waiters++
+		c.mu.Unlock()
+		c.wait(rw, req, inFlight)
+		return
+	}
+
+	current := &call{done: make(chan struct{})}
+	c.calls[key] = current
+	c.mu.Unlock()
+
+	c.lead(rw, req, key, current)
+}
+
+// lead sends the request to the next handler, and records its response for the identical requests.
+func (c *coalescing) lead(rw http.ResponseWriter, req *http.Request, key string, current *call) {
+	recorder := &responseRecorder{ResponseWriter: rw, header: make(http.Header), maxBodySize: c.maxBodySize}
+	var completed bool
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+
+		// The response is incomplete when the request was canceled, or the next handler panicked,
+		// and the cookies set by the response, or the responses private to the client, are not for the other clients.
+		current.shared = completed && !recorder.overflow && req.Context().Err() == nil &&
+			len(recorder.sentHeader["Set-Cookie"]) == 0 && !private(recorder.sentHeader) && c.coversVary(recorder.sentHeader)
+		current.status = recorder.status
+		current.header = recorder.sentHeader
+		current.body = recorder.body.Bytes()
+		current.trailer = recorder.trailer()
+		close(current.done)
+	}()
+
+	c.next.ServeHTTP(recorder, req)
+
+	if !recorder.wroteHeader {
+		recorder.WriteHeader(http.StatusOK)
+	}
+
+	// The trailers are set on the header of the recorder once the body is written.
+	for name, values := range recorder.trailer() {
+		rw.Header()[http.TrailerPrefix+name] = values
+	}
+
+	completed = true
+}
+
+// private tells whether the response must not be sent to other clients, according to its Cache-Control header.
+func private(header http.Header) bool {
+	for _, value := range header["Cache-Control"] {
+		for _, directive := range strings.Split(value, ",") {
+			name := strings.ToLower(strings.TrimSpace(directive))
+			if i := strings.IndexByte(name, '='); i >= 0 {
+				name = strings.TrimSpace(name[:i])
+			}
+
+			if name == "private" || name == "no-store" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// coversVary tells whether the request headers the response varies on are all part of the key of the requests.
+func (c *coalescing) coversVary(header http.Header) bool {
+	for _, value := range header["Vary"] {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			if !c.isKeyHeader(name) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (c *coalescing) isKeyHeader(name string) bool {
+	for _, header := range c.headers {
+		if header == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wait sends the response of the identical request in flight,
+// or sends the request to the next handler when the response cannot be shared.
+func (c *coalescing) wait(rw http.ResponseWriter, req *http.Request, inFlight *call) {
+	select {
+	case <-inFlight.done:
+	case <-req.Context().Done():
+		return
+	}
+
+	if !inFlight.shared {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	for name, values := range inFlight.header {
+		rw.Header()[name] = append([]string{}, values...)
+	}
+	rw.WriteHeader(inFlight.status)
+
+	if _, err := rw.Write(inFlight.body); err != nil {
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName)).Debugf("Error while writing the coalesced response: %v", err)
+	}
+
+	for name, values := range inFlight.trailer {
+		rw.Header()[http.TrailerPrefix+name] = append([]string{}, values...)
+	}
+}
+
+// key returns the key identifying the identical requests.
+func (c *coalescing) key(req *http.Request) string {
+	var key strings.Builder
+	key.WriteString(req.Host)
+	key.WriteString(req.URL.RequestURI())
+
+	for _, header := range c.headers {
+		key.WriteByte(0)
+		key.WriteString(header)
+		for _, value := range req.Header[header] {
+			key.WriteByte(0)
+			key.WriteString(value)
+		}
+	}
+
+	return key.String()
+}
+
+// responseRecorder writes the response, and records it up to the maximal body size.
+// The headers are recorded on their own, to leave out the ones set on the response writer before the call.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	header      http.Header
+	sentHeader  http.Header
+	wroteHeader bool
+	status      int
+
+	maxBodySize int64
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+// trailer returns the trailers set on the header, the declared ones and the ones with the trailer prefix.
+func (r *responseRecorder) trailer() http.Header {
+	trailer := make(http.Header)
+	for _, value := range r.sentHeader["Trailer"] {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if values, ok := r.header[name]; ok && name != "" {
+				trailer[name] = append([]string{}, values...)
+			}
+		}
+	}
+
+	for name, values := range r.header {
+		if strings.HasPrefix(name, http.TrailerPrefix) {
+			trailer[strings.TrimPrefix(name, http.TrailerPrefix)] = append([]string{}, values...)
+		}
+	}
+
+	return trailer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+
+	r.wroteHeader = true
+	r.status = status
+	r.sentHeader = r.header.Clone()
+
+	for name, values := range r.header {
+		r.ResponseWriter.Header()[name] = values
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.overflow {
+		if int64(r.body.Len()+len(data)) > r.maxBodySize {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(data)
+		}
+	}
+
+	return r.ResponseWriter.Write(data)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,222 @@
+package coalescing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoalescing(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.Coalescing
+		header        http.Header
+		body          string
+		trailer       http.Header
+		expectedCalls int32
+	}{
+		{
+			desc:          "identical requests",
+			body:          "foo",
+			expectedCalls: 1,
+		},
+		{
+			desc:          "response setting cookies",
+			header:        http.Header{"Set-Cookie": {"session=foo"}},
+			body:          "foo",
+			expectedCalls: 3,
+		},
+		{
+			desc:          "response varying on a header of the key",
+			header:        http.Header{"Vary": {"Cookie, Authorization"}},
+			body:          "foo",
+			expectedCalls: 1,
+		},
+		{
+			desc:          "response varying on another header",
+			header:        http.Header{"Vary": {"Cookie, Accept-Language"}},
+			body:          "foo",
+			expectedCalls: 3,
+		},
+		{
+			desc:          "private response",
+			header:        http.Header{"Cache-Control": {"max-age=60, private=\"X-User\""}},
+			body:          "foo",
+			expectedCalls: 3,
+		},
+		{
+			desc:          "response not to be stored",
+			header:        http.Header{"Cache-Control": {"No-Store"}},
+			body:          "foo",
+			expectedCalls: 3,
+		},
+		{
+			desc:          "response with trailers",
+			header:        http.Header{"Trailer": {"X-Checksum"}},
+			body:          "foo",
+			trailer:       http.Header{"X-Checksum": {"abc"}, "X-Status": {"ok"}},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "response body too large",
+			config:        dynamic.Coalescing{MaxBodySize: 2},
+			body:          "foo",
+			expectedCalls: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			unblock := make(chan struct{})
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				<-unblock
+
+				for name, values := range test.header {
+					rw.Header()[name] = values
+				}
+				rw.Header().Set("X-Upstream", "bar")
+				rw.WriteHeader(http.StatusAccepted)
+				_, _ = rw.Write([]byte(test.body))
+
+				if test.trailer != nil {
+					rw.Header().Set("X-Checksum", test.trailer.Get("X-Checksum"))
+					rw.Header().Set(http.TrailerPrefix+"X-Status", test.trailer.Get("X-Status"))
+				}
+			})
+
+			handler := New(context.Background(), next, test.config, "test").(*coalescing)
+
+			recorders := make([]*httptest.ResponseRecorder, 3)
+			var wg sync.WaitGroup
+			for i := range recorders {
+				recorders[i] = httptest.NewRecorder()
+				// The header set before the call are not shared.
+				recorders[i].Header().Set("X-Router", "router")
+
+				wg.Add(1)
+				go func(rw http.ResponseWriter) {
+					defer wg.Done()
+					handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://localhost/foo?bar=baz", nil))
+				}(recorders[i])
+
+				// Waits for the request to be sent upstream, or to wait for the response of the first one.
+				waiters := i
+				assert.Eventually(t, func() bool {
+					handler.mu.Lock()
+					defer handler.mu.Unlock()
+
+					inFlight := handler.calls[handler.key(httptest.NewRequest(http.MethodGet, "http://localhost/foo?bar=baz", nil))]
+					return inFlight != nil && inFlight.waiters == waiters && atomic.LoadInt32(&calls) == 1
+				}, time.Second, time.Millisecond)
+			}
+
+			close(unblock)
+			wg.Wait()
+
+			assert.Equal(t, test.expectedCalls, atomic.LoadInt32(&calls))
+			for _, recorder := range recorders {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+				assert.Equal(t, test.body, recorder.Body.String())
+				assert.Equal(t, "bar", recorder.Header().Get("X-Upstream"))
+				assert.Equal(t, "router", recorder.Header().Get("X-Router"))
+
+				if test.trailer != nil {
+					assert.Equal(t, test.trailer, recorder.Result().Trailer)
+				}
+			}
+		})
+	}
+}
+
+func TestCoalescing_DistinctRequests(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   dynamic.Coalescing
+		requests []*http.Request
+	}{
+		{
+			desc: "different paths",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil),
+				httptest.NewRequest(http.MethodGet, "http://localhost/bar", nil),
+			},
+		},
+		{
+			desc: "different users",
+			requests: []*http.Request{
+				withHeader(httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil), "Authorization", "Basic Zm9vOmJhcg=="),
+				withHeader(httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil), "Cookie", "session=foo"),
+			},
+		},
+		{
+			desc:   "different configured headers",
+			config: dynamic.Coalescing{Headers: []string{"accept-encoding"}},
+			requests: []*http.Request{
+				withHeader(httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil), "Accept-Encoding", "gzip"),
+				httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil),
+			},
+		},
+		{
+			desc: "not a GET request",
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodPost, "http://localhost/foo", nil),
+				httptest.NewRequest(http.MethodPost, "http://localhost/foo", nil),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The requests are sent upstream concurrently.
+			var started sync.WaitGroup
+			started.Add(len(test.requests))
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				started.Done()
+				started.Wait()
+			})
+
+			handler := New(context.Background(), next, test.config, "test")
+
+			var wg sync.WaitGroup
+			for _, req := range test.requests {
+				wg.Add(1)
+				go func(req *http.Request) {
+					defer wg.Done()
+					handler.ServeHTTP(httptest.NewRecorder(), req)
+				}(req)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the requests were coalesced")
+			}
+		})
+	}
+}
+
+func withHeader(req *http.Request, name, value string) *http.Request {
+	req.Header.Set(name, value)
+	return req
+}
@@ -20,15 +20,20 @@ const (
 
 // Compress is a middleware that allows to compress the response.
 type compress struct {
-	next     http.Handler
-	name     string
-	excludes []string
+	next       http.Handler
+	name       string
+	excludes   []string
+	etagPolicy string
 }
 
 // New creates a new compress middleware.
 func New(ctx context.Context, next http.Handler, conf dynamic.Compress, name string) (http.Handler, error) {
 	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
 
+	if err := validateETagPolicy(conf.ETagPolicy); err != nil {
+		return nil, err
+	}
+
 	excludes := []string{"application/grpc"}
 	for _, v := range conf.ExcludedContentTypes {
 		mediaType, _, err := mime.ParseMediaType(v)
@@ -39,7 +44,7 @@ func New(ctx context.Context, next http.Handler, conf dynamic.Compress, name str
 		excludes = append(excludes, mediaType)
 	}
 
-	return &compress{next: next, name: name, excludes: excludes}, nil
+	return &compress{next: next, name: name, excludes: excludes, etagPolicy: conf.ETagPolicy}, nil
 }
 
 func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -48,14 +53,35 @@ func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		log.FromContext(middlewares.GetLoggerCtx(context.Background(), c.name, typeName)).Debug(err)
 	}
 
-	if contains(c.excludes, mediaType) {
+	switch {
+	case contains(c.excludes, mediaType):
 		c.next.ServeHTTP(rw, req)
-	} else {
+	case !acceptsGzip(req) || req.Header.Get("Range") != "":
+		// The range requests are compressed as they always were, their ETag being left as is.
 		ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
 		gzipHandler(ctx, c.next).ServeHTTP(rw, req)
+	default:
+		etagRW := &etagResponseWriter{rw: rw, policy: c.etagPolicy, ifNoneMatch: req.Header.Get("If-None-Match")}
+
+		if c.etagPolicy == etagPolicySuffix {
+			removeETagSuffix(req.Header)
+		}
+
+		ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
+		gzipHandler(ctx, &upstreamHandler{next: c.next, etag: etagRW}).ServeHTTP(etagRW, req)
 	}
 }
 
+// upstreamHandler gives the next handler a response writer recording whether it already encoded the response.
+type upstreamHandler struct {
+	next http.Handler
+	etag *etagResponseWriter
+}
+
+func (h *upstreamHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.next.ServeHTTP(&upstreamResponseWriter{ResponseWriter: rw, etag: h.etag}, req)
+}
+
 func (c *compress) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return c.name, tracing.SpanKindNoneEnum
 }
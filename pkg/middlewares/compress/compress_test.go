@@ -5,10 +5,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
 	"testing"
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -284,6 +287,216 @@ func TestIntegrationShouldCompress(t *testing.T) {
 	}
 }
 
+func TestIntegrationShouldCompressAfterInformationalResponse(t *testing.T) {
+	if !middlewares.InformationalSupported {
+		t.Skip("the informational responses are not supported by this Go version")
+	}
+
+	fakeBody := generateBytes(100000)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		rw.WriteHeader(http.StatusEarlyHints)
+		rw.Header().Del("Link")
+
+		_, err := rw.Write(fakeBody)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	ts := httptest.NewServer(&compress{next: next})
+	defer ts.Close()
+
+	var informationalCodes []int
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			informationalCodes = append(informationalCodes, code)
+			return nil
+		},
+	}
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	req.Header.Add(acceptEncodingHeader, gzipValue)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{http.StatusEarlyHints}, informationalCodes)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, gzipValue, resp.Header.Get(contentEncodingHeader))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	if assert.ObjectsAreEqualValues(body, fakeBody) {
+		assert.Fail(t, "expected a compressed body", "got %v", body)
+	}
+}
+
+func TestETagPolicy(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		policy              string
+		etag                string
+		contentEncoding     string
+		status              int
+		ifNoneMatch         string
+		expectedETag        string
+		expectedIfNoneMatch string
+	}{
+		{
+			desc:         "strong ETag turned into a weak one by default",
+			etag:         `"abc"`,
+			status:       http.StatusOK,
+			expectedETag: `W/"abc"`,
+		},
+		{
+			desc:         "weak ETag kept",
+			policy:       "weak",
+			etag:         `W/"abc"`,
+			status:       http.StatusOK,
+			expectedETag: `W/"abc"`,
+		},
+		{
+			desc:         "suffix added to the ETag",
+			policy:       "suffix",
+			etag:         `"abc"`,
+			status:       http.StatusOK,
+			expectedETag: `"abc-gzip"`,
+		},
+		{
+			desc:         "ETag removed",
+			policy:       "remove",
+			etag:         `"abc"`,
+			status:       http.StatusOK,
+			expectedETag: "",
+		},
+		{
+			desc:         "ETag kept",
+			policy:       "keep",
+			etag:         `"abc"`,
+			status:       http.StatusOK,
+			expectedETag: `"abc"`,
+		},
+		{
+			desc:            "ETag of an already encoded response kept",
+			etag:            `"abc"`,
+			contentEncoding: "br",
+			status:          http.StatusOK,
+			expectedETag:    `"abc"`,
+		},
+		{
+			desc:                "suffix removed from the conditional request and added to the Not Modified response",
+			policy:              "suffix",
+			etag:                `"abc"`,
+			status:              http.StatusNotModified,
+			ifNoneMatch:         `"abc-gzip", "def"`,
+			expectedETag:        `"abc-gzip"`,
+			expectedIfNoneMatch: `"abc", "def"`,
+		},
+		{
+			desc:         "Not Modified response validating a compressed response",
+			etag:         `"abc"`,
+			status:       http.StatusNotModified,
+			ifNoneMatch:  `W/"abc"`,
+			expectedETag: `W/"abc"`,
+		},
+		{
+			desc:         "Not Modified response validating an uncompressed response",
+			etag:         `"abc"`,
+			status:       http.StatusNotModified,
+			ifNoneMatch:  `"abc"`,
+			expectedETag: `"abc"`,
+		},
+		{
+			desc:         "Not Modified response validating an uncompressed response with the suffix policy",
+			policy:       "suffix",
+			etag:         `"abc"`,
+			status:       http.StatusNotModified,
+			ifNoneMatch:  `"abc"`,
+			expectedETag: `"abc"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var ifNoneMatch string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				ifNoneMatch = r.Header.Get("If-None-Match")
+
+				rw.Header().Set("ETag", test.etag)
+				rw.Header().Set("Accept-Ranges", "bytes")
+				if test.contentEncoding != "" {
+					rw.Header().Set(contentEncodingHeader, test.contentEncoding)
+				}
+				rw.WriteHeader(test.status)
+
+				if test.status != http.StatusNotModified {
+					_, err := rw.Write(generateBytes(gziphandler.DefaultMinSize))
+					assert.NoError(t, err)
+				}
+			})
+
+			handler, err := New(context.Background(), next, dynamic.Compress{ETagPolicy: test.policy}, "test")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Add(acceptEncodingHeader, gzipValue)
+			if test.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", test.ifNoneMatch)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.status, rw.Code)
+			assert.Equal(t, test.expectedETag, rw.Header().Get("ETag"))
+
+			if test.expectedIfNoneMatch != "" {
+				assert.Equal(t, test.expectedIfNoneMatch, ifNoneMatch)
+			}
+
+			if rw.Header().Get(contentEncodingHeader) == gzipValue {
+				assert.Empty(t, rw.Header().Get("Accept-Ranges"))
+			}
+		})
+	}
+}
+
+func TestShouldCompressRangeRequests(t *testing.T) {
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Add(acceptEncodingHeader, gzipValue)
+	req.Header.Set("Range", "bytes=0-1499")
+
+	baseBody := generateBytes(gziphandler.DefaultMinSize + 100)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("ETag", `"abc"`)
+		rw.Header().Set("Content-Range", "bytes 0-1499/1500")
+		rw.WriteHeader(http.StatusPartialContent)
+		_, err := rw.Write(baseBody)
+		assert.NoError(t, err)
+	})
+	handler := &compress{next: next}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusPartialContent, rw.Code)
+	assert.Equal(t, gzipValue, rw.Header().Get(contentEncodingHeader))
+	assert.Equal(t, `"abc"`, rw.Header().Get("ETag"))
+	assert.NotEqualValues(t, baseBody, rw.Body.Bytes())
+}
+
+func TestNewInvalidETagPolicy(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.Compress{ETagPolicy: "foo"}, "test")
+	require.Error(t, err)
+}
+
 func generateBytes(len int) []byte {
 	var value []byte
 	for i := 0; i < len; i++ {
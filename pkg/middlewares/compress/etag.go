@@ -0,0 +1,252 @@
+package compress
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/middlewares"
+)
+
+// ETag policies, applied to the ETag of the responses compressed by the middleware.
+const (
+	// etagPolicyWeak turns the strong ETags into weak ones,
+	// as the compressed representation is not byte-for-byte identical to the one the ETag was computed for.
+	etagPolicyWeak = "weak"
+	// etagPolicySuffix adds a suffix to the ETags, so they stay strong but differ from the ones of the uncompressed representation.
+	// The suffix is removed from the conditional request headers before forwarding them.
+	etagPolicySuffix = "suffix"
+	// etagPolicyRemove removes the ETags.
+	etagPolicyRemove = "remove"
+	// etagPolicyKeep keeps the ETags as they are.
+	etagPolicyKeep = "keep"
+)
+
+const etagSuffix = "-gzip"
+
+func validateETagPolicy(policy string) error {
+	switch policy {
+	case "", etagPolicyWeak, etagPolicySuffix, etagPolicyRemove, etagPolicyKeep:
+		return nil
+	default:
+		return fmt.Errorf("unknown ETag policy %q, the valid ones are: %s, %s, %s, %s", policy, etagPolicyWeak, etagPolicySuffix, etagPolicyRemove, etagPolicyKeep)
+	}
+}
+
+// etagResponseWriter is the response writer given to the gzip handler.
+// When the response is compressed by the gzip handler, it rewrites the ETag according to the policy,
+// and removes the Accept-Ranges header, as the ranges cannot be served on the compressed representation.
+type etagResponseWriter struct {
+	rw     http.ResponseWriter
+	policy string
+	// ifNoneMatch is the If-None-Match header sent by the client, before the removal of the suffixes.
+	ifNoneMatch string
+
+	// upstreamEncoded is true when the response was already encoded by the next handler.
+	upstreamEncoded bool
+	headerWritten   bool
+}
+
+func (w *etagResponseWriter) Header() http.Header {
+	return w.rw.Header()
+}
+
+func (w *etagResponseWriter) Write(b []byte) (int, error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.rw.Write(b)
+}
+
+func (w *etagResponseWriter) WriteHeader(code int) {
+	if !w.headerWritten {
+		w.headerWritten = true
+		w.rewriteHeaders(code)
+	}
+
+	w.rw.WriteHeader(code)
+}
+
+func (w *etagResponseWriter) rewriteHeaders(code int) {
+	if w.upstreamEncoded {
+		return
+	}
+
+	compressed := w.Header().Get("Content-Encoding") == "gzip"
+	if compressed {
+		w.Header().Del("Accept-Ranges")
+	}
+
+	// A Not Modified response has no body to compress,
+	// but it must hold the same ETag as the compressed response it validates, if the client got a compressed one.
+	if compressed || code == http.StatusNotModified && w.validatesCompressed() {
+		rewriteETag(w.Header(), w.policy)
+	}
+}
+
+// validatesCompressed returns whether the conditional request validates the ETag of a compressed response,
+// i.e. the ETag of the response rewritten according to the policy is one of the ones of the If-None-Match header.
+func (w *etagResponseWriter) validatesCompressed() bool {
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.ifNoneMatch == "" {
+		return false
+	}
+
+	rewritten := rewrittenETag(etag, w.policy)
+	if rewritten == "" || rewritten == etag {
+		return false
+	}
+
+	for _, tag := range strings.Split(w.ifNoneMatch, ",") {
+		if strings.TrimSpace(tag) == rewritten {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Flush sends any buffered data to the client.
+func (w *etagResponseWriter) Flush() {
+	if f, ok := w.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (w *etagResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.rw.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.rw)
+}
+
+// upstreamResponseWriter is the response writer given to the next handler.
+// It records whether the next handler already encoded the response,
+// before the gzip handler sets its own Content-Encoding header.
+type upstreamResponseWriter struct {
+	http.ResponseWriter
+	etag *etagResponseWriter
+	seen bool
+}
+
+func (w *upstreamResponseWriter) record() {
+	if !w.seen {
+		w.seen = true
+		w.etag.upstreamEncoded = w.Header().Get("Content-Encoding") != ""
+	}
+}
+
+func (w *upstreamResponseWriter) Write(b []byte) (int, error) {
+	w.record()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *upstreamResponseWriter) WriteHeader(code int) {
+	// The informational responses have no body to compress, and must not be taken as the final response by the gzip handler.
+	if middlewares.IsInformational(code) {
+		w.etag.rw.WriteHeader(code)
+		return
+	}
+
+	w.record()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush sends any buffered data to the client.
+func (w *upstreamResponseWriter) Flush() {
+	w.record()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (w *upstreamResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+}
+
+func rewriteETag(header http.Header, policy string) {
+	etag := header.Get("ETag")
+	if etag == "" {
+		return
+	}
+
+	if rewritten := rewrittenETag(etag, policy); rewritten != "" {
+		header.Set("ETag", rewritten)
+	} else {
+		header.Del("ETag")
+	}
+}
+
+// rewrittenETag returns the ETag of the compressed representation, empty if it is removed.
+func rewrittenETag(etag, policy string) string {
+	switch policy {
+	case etagPolicyKeep:
+		return etag
+	case etagPolicyRemove:
+		return ""
+	case etagPolicySuffix:
+		if strings.HasSuffix(etag, `"`) && !strings.HasSuffix(etag, etagSuffix+`"`) {
+			return strings.TrimSuffix(etag, `"`) + etagSuffix + `"`
+		}
+		return etag
+	default:
+		if !strings.HasPrefix(etag, "W/") {
+			return "W/" + etag
+		}
+		return etag
+	}
+}
+
+// removeETagSuffix removes the suffix added to the ETags from the If-Match and If-None-Match request headers,
+// so the server can validate them against its own ETags.
+// The range requests are not concerned, as their ETag is left as is.
+func removeETagSuffix(header http.Header) {
+	for _, name := range []string{"If-Match", "If-None-Match"} {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+
+		tags := strings.Split(value, ",")
+		for i, tag := range tags {
+			tag = strings.TrimSpace(tag)
+			if strings.HasSuffix(tag, etagSuffix+`"`) {
+				tag = strings.TrimSuffix(tag, etagSuffix+`"`) + `"`
+			}
+			tags[i] = tag
+		}
+
+		header.Set(name, strings.Join(tags, ", "))
+	}
+}
+
+// acceptsGzip returns whether the client accepts the gzip encoding.
+func acceptsGzip(req *http.Request) bool {
+	for _, value := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(value, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			param = strings.ReplaceAll(param, " ", "")
+			if param == "q=0" || param == "q=0.0" || param == "q=0.00" || param == "q=0.000" {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -43,6 +43,7 @@ type customErrors struct {
 	backendHandler http.Handler
 	httpCodeRanges types.HTTPCodeRanges
 	backendQuery   string
+	templates      []*pageTemplate
 }
 
 // New creates a new custom error pages middleware.
@@ -54,17 +55,27 @@ func New(ctx context.Context, next http.Handler, config dynamic.ErrorPage, servi
 		return nil, err
 	}
 
-	backend, err := serviceBuilder.BuildHTTP(ctx, config.Service)
+	templates, err := newPageTemplates(config.Templates)
 	if err != nil {
 		return nil, err
 	}
 
+	// The service is optional when the pages are rendered from the templates.
+	var backend http.Handler
+	if config.Service != "" || len(templates) == 0 {
+		backend, err = serviceBuilder.BuildHTTP(ctx, config.Service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &customErrors{
 		name:           name,
 		next:           next,
 		backendHandler: backend,
 		httpCodeRanges: httpCodeRanges,
 		backendQuery:   config.Query,
+		templates:      templates,
 	}, nil
 }
 
@@ -76,7 +87,7 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
 	logger := log.FromContext(ctx)
 
-	if c.backendHandler == nil {
+	if c.backendHandler == nil && len(c.templates) == 0 {
 		logger.Error("Error pages: no backend handler.")
 		tracing.SetErrorWithEvent(req, "Error pages: no backend handler.")
 		c.next.ServeHTTP(rw, req)
@@ -95,6 +106,13 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		if code >= block[0] && code <= block[1] {
 			logger.Errorf("Caught HTTP Status Code %d, returning error page", code)
 
+			if page := negotiate(req.Header.Get("Accept"), c.templates, c.backendHandler != nil); page != nil {
+				if err := page.render(rw, code); err != nil {
+					logger.Errorf("Unable to render the %s error page: %v", page.mediaType, err)
+				}
+				return
+			}
+
 			var query string
 			if len(c.backendQuery) > 0 {
 				query = "/" + strings.TrimPrefix(c.backendQuery, "/")
@@ -233,6 +251,11 @@ func (cc *codeCatcher) WriteHeader(code int) {
 		return
 	}
 
+	if middlewares.IsInformational(code) {
+		middlewares.WriteInformational(cc.responseWriter, cc.Header(), code)
+		return
+	}
+
 	cc.code = code
 	for _, block := range cc.httpCodeRanges {
 		if cc.code >= block[0] && cc.code <= block[1] {
@@ -146,6 +146,77 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestHandler_templates(t *testing.T) {
+	templates := []dynamic.ErrorPageTemplate{
+		{MediaType: "application/problem+json", Body: `{"status":{{.Status}},"title":"{{.Title}}"}`},
+	}
+
+	testCases := []struct {
+		desc                string
+		service             string
+		accept              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			desc:                "API client",
+			service:             "error",
+			accept:              "application/json",
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"status":503,"title":"Service Unavailable"}`,
+		},
+		{
+			desc:         "browser",
+			service:      "error",
+			accept:       "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			expectedBody: "My error page.\n",
+		},
+		{
+			desc:         "any media type",
+			service:      "error",
+			accept:       "*/*",
+			expectedBody: "My error page.\n",
+		},
+		{
+			desc:                "browser without service",
+			accept:              "text/html",
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"status":503,"title":"Service Unavailable"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			serviceBuilderMock := &mockServiceBuilder{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "My error page.")
+			})}
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+
+			config := dynamic.ErrorPage{Service: test.service, Status: []string{"500-599"}, Templates: templates}
+			errorPageHandler, err := New(context.Background(), handler, config, serviceBuilderMock, "test")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/test", nil)
+			req.Header.Set("Accept", test.accept)
+
+			recorder := httptest.NewRecorder()
+			errorPageHandler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+			if test.expectedContentType != "" {
+				assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
 type mockServiceBuilder struct {
 	handler http.Handler
 }
@@ -0,0 +1,160 @@
+package customerrors
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// servicePageMediaType is the media type of the error pages of the service, compared with the ones of the templates.
+const servicePageMediaType = "text/html"
+
+// pageTemplate is an error page rendered by Traefik.
+type pageTemplate struct {
+	mediaType string
+	template  *template.Template
+}
+
+// pageData is the data given to the error page templates.
+type pageData struct {
+	Status int
+	Title  string
+}
+
+func newPageTemplates(configs []dynamic.ErrorPageTemplate) ([]*pageTemplate, error) {
+	var templates []*pageTemplate
+	for i, config := range configs {
+		mediaType, _, err := mime.ParseMediaType(config.MediaType)
+		if err == nil && !strings.Contains(mediaType, "/") {
+			err = errors.New("missing subtype")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid media type %q of the template %d: %w", config.MediaType, i, err)
+		}
+
+		tmpl, err := template.New(mediaType).Parse(config.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body of the template %d: %w", i, err)
+		}
+
+		templates = append(templates, &pageTemplate{mediaType: config.MediaType, template: tmpl})
+	}
+
+	return templates, nil
+}
+
+// render writes the error page for the status code.
+func (p *pageTemplate) render(rw http.ResponseWriter, code int) error {
+	rw.Header().Set("Content-Type", p.mediaType)
+	rw.Header().Del("Content-Length")
+	rw.WriteHeader(code)
+
+	return p.template.Execute(rw, pageData{Status: code, Title: http.StatusText(code)})
+}
+
+// negotiate returns the template of the media type preferred by the client,
+// or nil when the page of the service is, the ties going to the page of the service.
+// The first template is returned to the clients accepting none of the pages when there is no service.
+func negotiate(accept string, templates []*pageTemplate, hasService bool) *pageTemplate {
+	if len(templates) == 0 {
+		return nil
+	}
+
+	ranges := parseAccept(accept)
+
+	var best *pageTemplate
+	var bestQuality float64
+	if hasService {
+		bestQuality = quality(ranges, servicePageMediaType)
+	}
+
+	for _, tmpl := range templates {
+		mediaType, _, _ := mime.ParseMediaType(tmpl.mediaType)
+		if q := quality(ranges, mediaType); q > bestQuality {
+			best, bestQuality = tmpl, q
+		}
+	}
+
+	if best == nil && !hasService {
+		return templates[0]
+	}
+
+	return best
+}
+
+// mediaRange is a media range of an Accept header, with its quality.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept returns the media ranges of the Accept header, all media types being accepted when it is empty.
+func parseAccept(accept string) []mediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return []mediaRange{{mediaType: "*/*", quality: 1}}
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: q})
+	}
+
+	return ranges
+}
+
+// quality returns the quality of the media type, given by the most specific media range matching it.
+// A structured syntax suffix also matches its base media type, e.g. application/problem+json matches application/json.
+func quality(ranges []mediaRange, mediaType string) float64 {
+	mainType := mediaType
+	if i := strings.Index(mediaType, "/"); i >= 0 {
+		mainType = mediaType[:i]
+	}
+
+	var suffixType string
+	if i := strings.LastIndex(mediaType, "+"); i > len(mainType) {
+		suffixType = mainType + "/" + mediaType[i+1:]
+	}
+
+	specificity := -1
+	var q float64
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.mediaType == mediaType:
+			s = 3
+		case r.mediaType == suffixType:
+			s = 2
+		case r.mediaType == mainType+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			specificity, q = s, r.quality
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,107 @@
+package customerrors
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	templates, err := newPageTemplates([]dynamic.ErrorPageTemplate{
+		{MediaType: "application/problem+json", Body: "json"},
+		{MediaType: "text/plain; charset=utf-8", Body: "text"},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		accept     string
+		noService  bool
+		expected   string
+		noTemplate bool
+	}{
+		{
+			desc:       "no Accept header",
+			noTemplate: true,
+		},
+		{
+			desc:      "no Accept header without service",
+			noService: true,
+			expected:  "application/problem+json",
+		},
+		{
+			desc:     "exact media type",
+			accept:   "application/problem+json",
+			expected: "application/problem+json",
+		},
+		{
+			desc:     "structured syntax suffix",
+			accept:   "application/json",
+			expected: "application/problem+json",
+		},
+		{
+			desc:       "media range matching the service page",
+			accept:     "text/*",
+			noTemplate: true,
+		},
+		{
+			desc:      "media range",
+			accept:    "text/*",
+			noService: true,
+			expected:  "text/plain; charset=utf-8",
+		},
+		{
+			desc:     "qualities",
+			accept:   "application/json;q=0.5, text/plain",
+			expected: "text/plain; charset=utf-8",
+		},
+		{
+			desc:       "service page preferred",
+			accept:     "text/html, application/json;q=0.9",
+			noTemplate: true,
+		},
+		{
+			desc:      "specific media range over wildcard",
+			accept:    "application/json;q=0.1, */*",
+			noService: true,
+			expected:  "text/plain; charset=utf-8",
+		},
+		{
+			desc:       "refused media type",
+			accept:     "application/json;q=0, text/html",
+			noTemplate: true,
+		},
+		{
+			desc:      "nothing acceptable without service",
+			accept:    "image/png",
+			noService: true,
+			expected:  "application/problem+json",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			page := negotiate(test.accept, templates, !test.noService)
+			if test.noTemplate {
+				assert.Nil(t, page)
+				return
+			}
+
+			require.NotNil(t, page)
+			assert.Equal(t, test.expected, page.mediaType)
+		})
+	}
+}
+
+func TestNewPageTemplates(t *testing.T) {
+	_, err := newPageTemplates([]dynamic.ErrorPageTemplate{{MediaType: "json", Body: "{}"}})
+	assert.Error(t, err)
+
+	_, err = newPageTemplates([]dynamic.ErrorPageTemplate{{MediaType: "application/json", Body: "{{.Status"}})
+	assert.Error(t, err)
+}
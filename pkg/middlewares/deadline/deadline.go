@@ -0,0 +1,170 @@
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+)
+
+const (
+	typeName = "Deadline"
+)
+
+const grpcTimeoutHeader = "Grpc-Timeout"
+
+// maxGRPCTimeoutValue is the greatest value of the grpc-timeout header, which has at most 8 digits.
+const maxGRPCTimeoutValue = 100000000 - 1
+
+var grpcTimeoutUnits = []struct {
+	unit     string
+	duration time.Duration
+}{
+	{unit: "n", duration: time.Nanosecond},
+	{unit: "u", duration: time.Microsecond},
+	{unit: "m", duration: time.Millisecond},
+	{unit: "S", duration: time.Second},
+	{unit: "M", duration: time.Minute},
+	{unit: "H", duration: time.Hour},
+}
+
+// deadline sets the deadline of the requests handled by a router.
+type deadline struct {
+	next    http.Handler
+	name    string
+	timeout time.Duration
+	header  string
+}
+
+// New creates a handler setting the deadline of the requests,
+// which is the earliest of the one set by the client, and the end of the timeout of the router.
+func New(ctx context.Context, next http.Handler, config dynamic.RouterDeadline, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.Timeout < 0 {
+		return nil, fmt.Errorf("the deadline timeout of the router %s is negative", name)
+	}
+
+	return &deadline{
+		next:    next,
+		name:    name,
+		timeout: time.Duration(config.Timeout),
+		header:  config.Header,
+	}, nil
+}
+
+func (d *deadline) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	now := time.Now()
+
+	end, ok := clientDeadline(req, d.header, now)
+	if d.timeout > 0 {
+		if routerEnd := now.Add(d.timeout); !ok || routerEnd.Before(end) {
+			end, ok = routerEnd, true
+		}
+	}
+
+	if !ok {
+		d.next.ServeHTTP(rw, req)
+		return
+	}
+
+	ctx, cancel := context.WithDeadline(req.Context(), end)
+	defer cancel()
+
+	d.next.ServeHTTP(rw, req.WithContext(ctx))
+}
+
+// Propagate creates a handler giving the remaining time before the deadline of the requests to the servers,
+// in the grpc-timeout header for the gRPC requests, and in the header, in milliseconds, for the others.
+// The requests whose deadline is already exceeded are answered with a 504 status code.
+func Propagate(next http.Handler, header string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		end, ok := req.Context().Deadline()
+		if !ok {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		remaining := time.Until(end)
+		if remaining <= 0 {
+			http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+			return
+		}
+
+		switch {
+		case isGRPC(req):
+			req.Header.Set(grpcTimeoutHeader, encodeGRPCTimeout(remaining))
+		case header != "":
+			req.Header.Set(header, strconv.FormatInt(int64(remaining/time.Millisecond), 10))
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// clientDeadline returns the deadline set by the client,
+// in the grpc-timeout header for the gRPC requests, and in the header, in milliseconds, for the others.
+func clientDeadline(req *http.Request, header string, now time.Time) (time.Time, bool) {
+	if isGRPC(req) {
+		timeout, ok := decodeGRPCTimeout(req.Header.Get(grpcTimeoutHeader))
+		if !ok {
+			return time.Time{}, false
+		}
+		return now.Add(timeout), true
+	}
+
+	if header == "" {
+		return time.Time{}, false
+	}
+
+	milliseconds, err := strconv.ParseInt(req.Header.Get(header), 10, 64)
+	if err != nil || milliseconds < 0 {
+		return time.Time{}, false
+	}
+
+	return now.Add(time.Duration(milliseconds) * time.Millisecond), true
+}
+
+func isGRPC(req *http.Request) bool {
+	return strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc")
+}
+
+// encodeGRPCTimeout returns the grpc-timeout header value of the timeout,
+// in the finest unit keeping it under 8 digits, rounded up.
+func encodeGRPCTimeout(timeout time.Duration) string {
+	for _, u := range grpcTimeoutUnits {
+		value := (timeout + u.duration - 1) / u.duration
+		if value <= maxGRPCTimeoutValue {
+			return strconv.FormatInt(int64(value), 10) + u.unit
+		}
+	}
+
+	return strconv.Itoa(maxGRPCTimeoutValue) + "H"
+}
+
+// decodeGRPCTimeout returns the timeout of a grpc-timeout header value.
+func decodeGRPCTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+
+	unit := value[len(value)-1:]
+	for _, u := range grpcTimeoutUnits {
+		if u.unit == unit {
+			return time.Duration(amount) * u.duration, true
+		}
+	}
+
+	return 0, false
+}
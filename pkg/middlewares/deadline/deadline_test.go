@@ -0,0 +1,278 @@
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestDeadline(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		timeout          time.Duration
+		headers          map[string]string
+		expectedDeadline bool
+		expectedMin      time.Duration
+		expectedMax      time.Duration
+	}{
+		{
+			desc: "no timeout and no client deadline",
+		},
+		{
+			desc:             "router timeout",
+			timeout:          time.Minute,
+			expectedDeadline: true,
+			expectedMin:      59 * time.Second,
+			expectedMax:      time.Minute,
+		},
+		{
+			desc:             "client deadline without timeout",
+			headers:          map[string]string{"X-Request-Deadline": "2000"},
+			expectedDeadline: true,
+			expectedMin:      time.Second,
+			expectedMax:      2 * time.Second,
+		},
+		{
+			desc:             "client deadline earlier than the timeout",
+			timeout:          time.Minute,
+			headers:          map[string]string{"X-Request-Deadline": "2000"},
+			expectedDeadline: true,
+			expectedMin:      time.Second,
+			expectedMax:      2 * time.Second,
+		},
+		{
+			desc:             "client deadline later than the timeout",
+			timeout:          time.Minute,
+			headers:          map[string]string{"X-Request-Deadline": "3600000"},
+			expectedDeadline: true,
+			expectedMin:      59 * time.Second,
+			expectedMax:      time.Minute,
+		},
+		{
+			desc:             "invalid client deadline",
+			timeout:          time.Minute,
+			headers:          map[string]string{"X-Request-Deadline": "soon"},
+			expectedDeadline: true,
+			expectedMin:      59 * time.Second,
+			expectedMax:      time.Minute,
+		},
+		{
+			desc:             "gRPC client deadline",
+			timeout:          time.Minute,
+			headers:          map[string]string{"Content-Type": "application/grpc", "Grpc-Timeout": "2S"},
+			expectedDeadline: true,
+			expectedMin:      time.Second,
+			expectedMax:      2 * time.Second,
+		},
+		{
+			desc:             "header ignored on gRPC requests",
+			timeout:          time.Minute,
+			headers:          map[string]string{"Content-Type": "application/grpc+proto", "X-Request-Deadline": "2000"},
+			expectedDeadline: true,
+			expectedMin:      59 * time.Second,
+			expectedMax:      time.Minute,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var remaining time.Duration
+			var hasDeadline bool
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var end time.Time
+				end, hasDeadline = req.Context().Deadline()
+				remaining = time.Until(end)
+			})
+
+			handler, err := New(context.Background(), next, dynamic.RouterDeadline{
+				Timeout: ptypes.Duration(test.timeout),
+				Header:  "X-Request-Deadline",
+			}, "foo")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Equal(t, test.expectedDeadline, hasDeadline)
+			if test.expectedDeadline {
+				assert.Greater(t, int64(remaining), int64(test.expectedMin))
+				assert.LessOrEqual(t, int64(remaining), int64(test.expectedMax))
+			}
+		})
+	}
+}
+
+func TestNew_negativeTimeout(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.RouterDeadline{Timeout: ptypes.Duration(-time.Second)}, "foo")
+	assert.Error(t, err)
+}
+
+func TestPropagate(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		remaining      time.Duration
+		noDeadline     bool
+		grpc           bool
+		header         string
+		expectedStatus int
+		expectedHeader bool
+		expectedGRPC   bool
+	}{
+		{
+			desc:           "no deadline",
+			noDeadline:     true,
+			header:         "X-Request-Deadline",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "HTTP request",
+			remaining:      time.Minute,
+			header:         "X-Request-Deadline",
+			expectedStatus: http.StatusOK,
+			expectedHeader: true,
+		},
+		{
+			desc:           "HTTP request without header",
+			remaining:      time.Minute,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "gRPC request",
+			remaining:      time.Minute,
+			grpc:           true,
+			header:         "X-Request-Deadline",
+			expectedStatus: http.StatusOK,
+			expectedGRPC:   true,
+		},
+		{
+			desc:           "exceeded deadline",
+			remaining:      -time.Second,
+			header:         "X-Request-Deadline",
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var headers http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				headers = req.Header
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo", nil)
+			if test.grpc {
+				req.Header.Set("Content-Type", "application/grpc")
+			}
+
+			if !test.noDeadline {
+				ctx, cancel := context.WithTimeout(req.Context(), test.remaining)
+				defer cancel()
+				req = req.WithContext(ctx)
+			}
+
+			rw := httptest.NewRecorder()
+			Propagate(next, test.header).ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			if test.expectedStatus != http.StatusOK {
+				assert.Nil(t, headers)
+				return
+			}
+
+			// The remaining time decreases during the test.
+			if test.expectedHeader {
+				milliseconds, err := strconv.Atoi(headers.Get("X-Request-Deadline"))
+				require.NoError(t, err)
+				assert.InDelta(t, time.Minute.Milliseconds(), milliseconds, 1000)
+			} else {
+				assert.Empty(t, headers.Get("X-Request-Deadline"))
+			}
+
+			if test.expectedGRPC {
+				timeout, ok := decodeGRPCTimeout(headers.Get("Grpc-Timeout"))
+				require.True(t, ok)
+				assert.InDelta(t, time.Minute, timeout, float64(time.Second))
+			} else {
+				assert.Empty(t, headers.Get("Grpc-Timeout"))
+			}
+		})
+	}
+}
+
+func TestEncodeGRPCTimeout(t *testing.T) {
+	testCases := []struct {
+		timeout  time.Duration
+		expected string
+	}{
+		{timeout: 0, expected: "0n"},
+		{timeout: 50 * time.Millisecond, expected: "50000000n"},
+		{timeout: 2 * time.Second, expected: "2000000u"},
+		{timeout: time.Minute, expected: "60000000u"},
+		{timeout: 2 * time.Minute, expected: "120000m"},
+		{timeout: 30 * time.Hour, expected: "108000S"},
+		{timeout: time.Second + time.Nanosecond, expected: "1000001u"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.timeout.String(), func(t *testing.T) {
+			t.Parallel()
+
+			value := encodeGRPCTimeout(test.timeout)
+			assert.Equal(t, test.expected, value)
+
+			timeout, ok := decodeGRPCTimeout(value)
+			require.True(t, ok)
+			assert.GreaterOrEqual(t, int64(timeout), int64(test.timeout))
+		})
+	}
+}
+
+func TestDecodeGRPCTimeout(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		invalid  bool
+	}{
+		{value: "1H", expected: time.Hour},
+		{value: "5M", expected: 5 * time.Minute},
+		{value: "30S", expected: 30 * time.Second},
+		{value: "100m", expected: 100 * time.Millisecond},
+		{value: "10u", expected: 10 * time.Microsecond},
+		{value: "99999999n", expected: 99999999 * time.Nanosecond},
+		{value: "", invalid: true},
+		{value: "1", invalid: true},
+		{value: "1s", invalid: true},
+		{value: "-1S", invalid: true},
+		{value: "123456789S", invalid: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			timeout, ok := decodeGRPCTimeout(test.value)
+			assert.Equal(t, !test.invalid, ok)
+			assert.Equal(t, test.expected, timeout)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package earlyhints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "EarlyHints"
+)
+
+// earlyHints is a middleware sending a 103 Early Hints response before the final response.
+type earlyHints struct {
+	next   http.Handler
+	name   string
+	header http.Header
+}
+
+// New creates an early hints middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.EarlyHints, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if !middlewares.InformationalSupported {
+		return nil, errors.New("the early hints cannot be sent, Traefik must be built with Go 1.20 or later")
+	}
+
+	if len(config.Links) == 0 {
+		return nil, errors.New("at least one link is required")
+	}
+
+	header := make(http.Header)
+	for _, link := range config.Links {
+		if !strings.HasPrefix(strings.TrimSpace(link), "<") {
+			return nil, fmt.Errorf("invalid link %q: the target must be enclosed in angle brackets, e.g. </style.css>; rel=preload; as=style", link)
+		}
+		header.Add("Link", link)
+	}
+
+	return &earlyHints{
+		next:   next,
+		name:   name,
+		header: header,
+	}, nil
+}
+
+func (e *earlyHints) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return e.name, tracing.SpanKindNoneEnum
+}
+
+func (e *earlyHints) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The early hints are only sent to the HTTP/2 clients,
+	// as some HTTP/1.1 clients take the informational responses as the final response.
+	if req.ProtoMajor >= 2 && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		middlewares.WriteInformational(rw, e.header, http.StatusEarlyHints)
+	}
+
+	e.next.ServeHTTP(rw, req)
+}
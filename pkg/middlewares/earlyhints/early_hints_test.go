@@ -0,0 +1,132 @@
+package earlyhints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEarlyHints(t *testing.T) {
+	if !middlewares.InformationalSupported {
+		t.Skip("the informational responses are not supported by this Go version")
+	}
+
+	testCases := []struct {
+		desc          string
+		http2         bool
+		method        string
+		expectedHints []string
+	}{
+		{
+			desc:          "HTTP/2 GET request",
+			http2:         true,
+			method:        http.MethodGet,
+			expectedHints: []string{"</style.css>; rel=preload; as=style", "</script.js>; rel=preload; as=script"},
+		},
+		{
+			desc:   "HTTP/2 POST request",
+			http2:  true,
+			method: http.MethodPost,
+		},
+		{
+			desc:   "HTTP/1.1 GET request",
+			method: http.MethodGet,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "text/html")
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			config := dynamic.EarlyHints{
+				Links: []string{"</style.css>; rel=preload; as=style", "</script.js>; rel=preload; as=script"},
+			}
+
+			handler, err := New(context.Background(), next, config, "earlyHintsTest")
+			require.NoError(t, err)
+
+			server := httptest.NewUnstartedServer(handler)
+			server.EnableHTTP2 = test.http2
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			var hints []string
+			trace := &httptrace.ClientTrace{
+				Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+					assert.Equal(t, http.StatusEarlyHints, code)
+					hints = append(hints, header["Link"]...)
+					return nil
+				},
+			}
+
+			req, err := http.NewRequest(test.method, server.URL, nil)
+			require.NoError(t, err)
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/html", resp.Header.Get("Content-Type"))
+			assert.Empty(t, resp.Header.Values("Link"))
+			assert.Equal(t, test.expectedHints, hints)
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	if !middlewares.InformationalSupported {
+		t.Skip("the informational responses are not supported by this Go version")
+	}
+
+	testCases := []struct {
+		desc   string
+		config dynamic.EarlyHints
+	}{
+		{
+			desc: "no links",
+		},
+		{
+			desc:   "link without angle brackets",
+			config: dynamic.EarlyHints{Links: []string{"/style.css; rel=preload; as=style"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "earlyHintsTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewUnsupported(t *testing.T) {
+	if middlewares.InformationalSupported {
+		t.Skip("the informational responses are supported by this Go version")
+	}
+
+	config := dynamic.EarlyHints{
+		Links: []string{"</style.css>; rel=preload; as=style"},
+	}
+
+	_, err := New(context.Background(), http.NotFoundHandler(), config, "earlyHintsTest")
+	assert.Error(t, err)
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/vulcand/oxy/utils"
 )
@@ -14,7 +17,8 @@ import (
 // GetSourceExtractor returns the SourceExtractor function corresponding to the given sourceMatcher.
 // It defaults to a RemoteAddrStrategy IPStrategy if need be.
 // It returns an error if more than one source criterion is provided.
-func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriterion) (utils.SourceExtractor, error) {
+// The IP sets of the IPStrategy are looked up in ipSets.
+func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriterion, ipSets ip.Sets) (utils.SourceExtractor, error) {
 	if sourceMatcher != nil {
 		if sourceMatcher.IPStrategy != nil && sourceMatcher.RequestHeaderName != "" {
 			return nil, errors.New("iPStrategy and RequestHeaderName are mutually exclusive")
@@ -25,11 +29,29 @@ func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriter
 		if sourceMatcher.RequestHeaderName != "" && sourceMatcher.RequestHost {
 			return nil, errors.New("requestHost and RequestHeaderName are mutually exclusive")
 		}
+
+		var criteria []string
+		for name, set := range map[string]bool{
+			"iPStrategy":                sourceMatcher.IPStrategy != nil,
+			"RequestHeaderName":         sourceMatcher.RequestHeaderName != "",
+			"RequestHost":               sourceMatcher.RequestHost,
+			"RequestQueryParameterName": sourceMatcher.RequestQueryParameterName != "",
+			"RequestCookieName":         sourceMatcher.RequestCookieName != "",
+		} {
+			if set {
+				criteria = append(criteria, name)
+			}
+		}
+		if len(criteria) > 1 {
+			sort.Strings(criteria)
+			return nil, fmt.Errorf("%s are mutually exclusive", strings.Join(criteria, " and "))
+		}
 	}
 
 	if sourceMatcher == nil ||
 		sourceMatcher.IPStrategy == nil &&
-			sourceMatcher.RequestHeaderName == "" && !sourceMatcher.RequestHost {
+			sourceMatcher.RequestHeaderName == "" && !sourceMatcher.RequestHost &&
+			sourceMatcher.RequestQueryParameterName == "" && sourceMatcher.RequestCookieName == "" {
 		sourceMatcher = &dynamic.SourceCriterion{
 			IPStrategy: &dynamic.IPStrategy{},
 		}
@@ -37,7 +59,7 @@ func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriter
 
 	logger := log.FromContext(ctx)
 	if sourceMatcher.IPStrategy != nil {
-		strategy, err := sourceMatcher.IPStrategy.Get()
+		strategy, err := sourceMatcher.IPStrategy.Get(ctx, ipSets)
 		if err != nil {
 			return nil, err
 		}
@@ -58,5 +80,25 @@ func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriter
 		return utils.NewExtractor("request.host")
 	}
 
+	if sourceMatcher.RequestQueryParameterName != "" {
+		logger.Debug("Using RequestQueryParameterName")
+		name := sourceMatcher.RequestQueryParameterName
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			return req.URL.Query().Get(name), 1, nil
+		}), nil
+	}
+
+	if sourceMatcher.RequestCookieName != "" {
+		logger.Debug("Using RequestCookieName")
+		name := sourceMatcher.RequestCookieName
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			cookie, err := req.Cookie(name)
+			if err != nil {
+				return "", 1, nil
+			}
+			return cookie.Value, 1, nil
+		}), nil
+	}
+
 	return nil, errors.New("no SourceCriterion criterion defined")
 }
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSourceExtractor(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		criterion *dynamic.SourceCriterion
+		target    string
+		headers   map[string]string
+		expected  string
+	}{
+		{
+			desc:      "query parameter",
+			criterion: &dynamic.SourceCriterion{RequestQueryParameterName: "api_key"},
+			target:    "http://foo/bar?api_key=key1&other=value",
+			expected:  "key1",
+		},
+		{
+			desc:      "missing query parameter",
+			criterion: &dynamic.SourceCriterion{RequestQueryParameterName: "api_key"},
+			target:    "http://foo/bar?other=value",
+		},
+		{
+			desc:      "cookie",
+			criterion: &dynamic.SourceCriterion{RequestCookieName: "session"},
+			headers:   map[string]string{"Cookie": "other=value; session=session1"},
+			expected:  "session1",
+		},
+		{
+			desc:      "missing cookie",
+			criterion: &dynamic.SourceCriterion{RequestCookieName: "session"},
+			headers:   map[string]string{"Cookie": "other=value"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			extractor, err := GetSourceExtractor(context.Background(), test.criterion, nil)
+			require.NoError(t, err)
+
+			target := test.target
+			if target == "" {
+				target = "http://foo/bar"
+			}
+
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			source, amount, err := extractor.Extract(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, source)
+			assert.Equal(t, int64(1), amount)
+		})
+	}
+}
+
+func TestGetSourceExtractor_mutuallyExclusive(t *testing.T) {
+	_, err := GetSourceExtractor(context.Background(), &dynamic.SourceCriterion{
+		RequestQueryParameterName: "api_key",
+		RequestCookieName:         "session",
+	}, nil)
+	assert.EqualError(t, err, "RequestCookieName and RequestQueryParameterName are mutually exclusive")
+}
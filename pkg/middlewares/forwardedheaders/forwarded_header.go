@@ -49,12 +49,12 @@ type XForwarded struct {
 	hostname   string
 }
 
-// NewXForwarded creates a new XForwarded.
-func NewXForwarded(insecure bool, trustedIps []string, next http.Handler) (*XForwarded, error) {
+// NewXForwarded creates a new XForwarded, trusting the IPs of trustedIps and trustedSets.
+func NewXForwarded(insecure bool, trustedIps []string, trustedSets []*ip.Set, next http.Handler) (*XForwarded, error) {
 	var ipChecker *ip.Checker
-	if len(trustedIps) > 0 {
+	if len(trustedIps) > 0 || len(trustedSets) > 0 {
 		var err error
-		ipChecker, err = ip.NewChecker(trustedIps)
+		ipChecker, err = ip.NewChecker(trustedIps, trustedSets...)
 		if err != nil {
 			return nil, err
 		}
@@ -283,7 +283,7 @@ func TestServeHTTP(t *testing.T) {
 				req.Header.Set(k, v)
 			}
 
-			m, err := NewXForwarded(test.insecure, test.trustedIps,
+			m, err := NewXForwarded(test.insecure, test.trustedIps, nil,
 				http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
 			require.NoError(t, err)
 
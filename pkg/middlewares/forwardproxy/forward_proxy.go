@@ -0,0 +1,504 @@
+package forwardproxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goauth "github.com/abbot/go-http-auth"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/auth"
+	"golang.org/x/time/rate"
+)
+
+const (
+	proxyAuthorizationHeader = "Proxy-Authorization"
+	proxyAuthenticateHeader  = "Proxy-Authenticate"
+	realm                    = "traefik"
+)
+
+const bufferSize = 32 * 1024
+
+// privateDestinations are the private, shared, loopback, link-local (e.g. the metadata endpoints of the cloud providers),
+// and unspecified IP ranges, which the tunnels cannot be opened to unless explicitly allowed.
+var privateDestinations = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// resolver resolves the IPs of the destinations.
+type resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// forwardProxy tunnels the CONNECT requests to their destination.
+type forwardProxy struct {
+	users map[string]string
+
+	allowedDomains []string
+	allowedIPs     *ip.Checker
+	deniedDomains  []string
+	deniedIPs      *ip.Checker
+	privateIPs     *ip.Checker
+	allowedPorts   map[int]struct{}
+
+	// connections holds a token for each open tunnel, when the number of tunnels is limited.
+	connections    chan struct{}
+	bandwidthLimit int64
+
+	dialer   *net.Dialer
+	resolver resolver
+}
+
+// IsTunnelRequest returns whether the request is a CONNECT request to be tunneled by the forward proxy.
+// The extended CONNECT requests (RFC 8441) are not, as they are handled by the services.
+func IsTunnelRequest(req *http.Request) bool {
+	return req.Method == http.MethodConnect && req.Header.Get(":protocol") == ""
+}
+
+// New creates a forward proxy handler, tunneling the CONNECT requests to their destination.
+// It is shared by all the HTTP servers of an entry point, so the limits apply to all their tunnels.
+func New(ctx context.Context, config *static.ForwardProxy) (http.Handler, error) {
+	users, err := auth.GetBasicUsers(config.UsersFile, config.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &forwardProxy{
+		users:          users,
+		bandwidthLimit: config.BandwidthLimit,
+		dialer:         &net.Dialer{Timeout: time.Duration(config.DialTimeout), KeepAlive: 30 * time.Second},
+		resolver:       net.DefaultResolver,
+	}
+
+	p.allowedDomains, p.allowedIPs, err = parseDestinations(config.AllowedDestinations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed destinations: %w", err)
+	}
+
+	if len(p.allowedDomains) == 0 && p.allowedIPs == nil {
+		return nil, errors.New("at least one allowed destination is required")
+	}
+
+	p.deniedDomains, p.deniedIPs, err = parseDestinations(config.DeniedDestinations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denied destinations: %w", err)
+	}
+
+	if !config.AllowPrivateDestinations {
+		p.privateIPs, err = ip.NewChecker(privateDestinations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.AllowedPorts) > 0 {
+		p.allowedPorts = make(map[int]struct{})
+		for _, port := range config.AllowedPorts {
+			if port <= 0 || port > 65535 {
+				return nil, fmt.Errorf("invalid allowed port %d", port)
+			}
+			p.allowedPorts[port] = struct{}{}
+		}
+	}
+
+	if config.MaxConnections < 0 {
+		return nil, fmt.Errorf("invalid max connections %d", config.MaxConnections)
+	}
+	if config.MaxConnections > 0 {
+		p.connections = make(chan struct{}, config.MaxConnections)
+	}
+
+	if config.BandwidthLimit < 0 {
+		return nil, fmt.Errorf("invalid bandwidth limit %d", config.BandwidthLimit)
+	}
+
+	log.FromContext(ctx).Infof("Enabling the forward proxy mode for %d users", len(users))
+
+	return p, nil
+}
+
+func (p *forwardProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !IsTunnelRequest(req) {
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	logger := log.FromContext(req.Context())
+
+	user, ok := p.authenticate(req)
+	if !ok {
+		logger.Debug("Forward proxy authentication failed")
+		rw.Header().Set(proxyAuthenticateHeader, fmt.Sprintf("Basic realm=%q", realm))
+		http.Error(rw, http.StatusText(http.StatusProxyAuthRequired), http.StatusProxyAuthRequired)
+		return
+	}
+
+	host, port, err := splitDestination(req.Host)
+	if err != nil {
+		logger.Debugf("Invalid CONNECT destination %q: %v", req.Host, err)
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if p.allowedPorts != nil {
+		if _, ok := p.allowedPorts[port]; !ok {
+			logger.Debugf("CONNECT to %q denied: port not allowed", req.Host)
+			http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	ips, err := p.resolve(req.Context(), host)
+	if err != nil {
+		logger.Debugf("CONNECT to %q failed: %v", req.Host, err)
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	if !p.isAllowed(host, ips) {
+		logger.Debugf("CONNECT to %q denied: destination not allowed", req.Host)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if !p.acquire() {
+		logger.Debugf("CONNECT to %q denied: too many open tunnels", req.Host)
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	defer p.release()
+
+	// The checked IPs are dialed, so the destination cannot be changed by resolving its name again.
+	destination, err := p.dial(req.Context(), ips, port)
+	if err != nil {
+		logger.Debugf("CONNECT to %q failed: %v", req.Host, err)
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = destination.Close() }()
+
+	logger.Debugf("Tunnel opened to %q by %q", req.Host, user)
+
+	if req.ProtoMajor >= 2 {
+		p.tunnelStream(rw, req, destination)
+	} else {
+		p.tunnelConn(rw, destination)
+	}
+
+	logger.Debugf("Tunnel closed to %q by %q", req.Host, user)
+}
+
+// authenticate checks the credentials of the Proxy-Authorization header, when users are defined.
+func (p *forwardProxy) authenticate(req *http.Request) (string, bool) {
+	if len(p.users) == 0 {
+		return "", true
+	}
+
+	// The Proxy-Authorization header has the same format as the Authorization one.
+	authReq := &http.Request{Header: http.Header{"Authorization": req.Header[proxyAuthorizationHeader]}}
+	user, password, ok := authReq.BasicAuth()
+	if !ok {
+		return "", false
+	}
+
+	secret, ok := p.users[user]
+	if !ok || !goauth.CheckSecret(password, secret) {
+		return "", false
+	}
+
+	// The credentials are not forwarded to the destination, but they are removed in case a handler logs the headers.
+	req.Header.Del(proxyAuthorizationHeader)
+
+	return user, true
+}
+
+func (p *forwardProxy) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ipAddr := net.ParseIP(host); ipAddr != nil {
+		return []net.IP{ipAddr}, nil
+	}
+
+	addrs, err := p.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no IP found for %s", host)
+	}
+
+	return ips, nil
+}
+
+// isAllowed checks the destination against the allowed and denied destinations.
+// The denied destinations, and the private ones, take precedence, and all the IPs of a domain name are checked.
+func (p *forwardProxy) isAllowed(host string, ips []net.IP) bool {
+	if matchDomain(p.deniedDomains, host) {
+		return false
+	}
+
+	for _, ipAddr := range ips {
+		if p.deniedIPs != nil && p.deniedIPs.ContainsIP(ipAddr) {
+			return false
+		}
+
+		if p.privateIPs != nil && p.privateIPs.ContainsIP(ipAddr) {
+			return false
+		}
+	}
+
+	if matchDomain(p.allowedDomains, host) {
+		return true
+	}
+
+	if p.allowedIPs == nil {
+		return false
+	}
+
+	for _, ipAddr := range ips {
+		if !p.allowedIPs.ContainsIP(ipAddr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *forwardProxy) acquire() bool {
+	if p.connections == nil {
+		return true
+	}
+
+	select {
+	case p.connections <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *forwardProxy) release() {
+	if p.connections != nil {
+		<-p.connections
+	}
+}
+
+func (p *forwardProxy) dial(ctx context.Context, ips []net.IP, port int) (net.Conn, error) {
+	var err error
+	for _, ipAddr := range ips {
+		var conn net.Conn
+		conn, err = p.dialer.DialContext(ctx, "tcp", net.JoinHostPort(ipAddr.String(), strconv.Itoa(port)))
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
+
+// tunnelConn tunnels an HTTP/1.x CONNECT request, by taking over the client connection.
+func (p *forwardProxy) tunnelConn(rw http.ResponseWriter, destination net.Conn) {
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		log.WithoutContext().Errorf("The response writer %T cannot be hijacked", rw)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to hijack the connection: %v", err)
+		return
+	}
+	defer func() { _ = client.Close() }()
+
+	// The deadlines of the entry point do not apply to the tunnels.
+	if err := client.SetDeadline(time.Time{}); err != nil {
+		log.WithoutContext().Debugf("Unable to reset the connection deadlines: %v", err)
+	}
+
+	if _, err := buffered.WriteString("HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+	if err := buffered.Flush(); err != nil {
+		return
+	}
+
+	p.copyStreams(&bufferedConn{Conn: client, reader: buffered.Reader}, client, nil, destination)
+}
+
+// tunnelStream tunnels an HTTP/2 CONNECT request, through the request and response bodies.
+func (p *forwardProxy) tunnelStream(rw http.ResponseWriter, req *http.Request, destination net.Conn) {
+	rw.WriteHeader(http.StatusOK)
+
+	flusher, ok := rw.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+
+	p.copyStreams(req.Body, rw, flusher, destination)
+}
+
+// copyStreams copies the data in both directions, until one of them is closed.
+func (p *forwardProxy) copyStreams(clientReader io.ReadCloser, clientWriter io.Writer, flusher http.Flusher, destination net.Conn) {
+	var once sync.Once
+	closeTunnel := func() {
+		once.Do(func() {
+			_ = clientReader.Close()
+			_ = destination.Close()
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer closeTunnel()
+
+		_ = p.copy(destination, clientReader, nil)
+	}()
+
+	_ = p.copy(clientWriter, destination, flusher)
+
+	closeTunnel()
+	<-done
+}
+
+// copy copies the data from src to dst, at the bandwidth limit if any.
+func (p *forwardProxy) copy(dst io.Writer, src io.Reader, flusher http.Flusher) error {
+	size := bufferSize
+	var limiter *rate.Limiter
+	if p.bandwidthLimit > 0 {
+		if p.bandwidthLimit < int64(size) {
+			size = int(p.bandwidthLimit)
+		}
+		limiter = rate.NewLimiter(rate.Limit(p.bandwidthLimit), size)
+	}
+
+	buf := make([]byte, size)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if limiter != nil {
+				if werr := limiter.WaitN(context.Background(), n); werr != nil {
+					return werr
+				}
+			}
+
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return werr
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// bufferedConn is a connection reading first the data already buffered by the HTTP server.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func splitDestination(hostPort string) (string, int, error) {
+	host, rawPort, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", 0, err
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port %q", rawPort)
+	}
+
+	if host == "" {
+		return "", 0, errors.New("empty host")
+	}
+
+	return strings.ToLower(host), port, nil
+}
+
+// parseDestinations splits the destinations in domain names and IPs.
+func parseDestinations(destinations []string) ([]string, *ip.Checker, error) {
+	var domains, ips []string
+	for _, destination := range destinations {
+		destination = strings.ToLower(strings.TrimSpace(destination))
+		if destination == "" {
+			continue
+		}
+
+		if net.ParseIP(destination) != nil || strings.Contains(destination, "/") {
+			ips = append(ips, destination)
+			continue
+		}
+
+		domains = append(domains, destination)
+	}
+
+	if len(ips) == 0 {
+		return domains, nil, nil
+	}
+
+	checker, err := ip.NewChecker(ips)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return domains, checker, nil
+}
+
+// matchDomain checks whether the host is one of the domains,
+// a domain starting with "*." matching all its sub-domains.
+func matchDomain(domains []string, host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	for _, domain := range domains {
+		if strings.HasPrefix(domain, "*.") {
+			if strings.HasSuffix(host, domain[1:]) {
+				return true
+			}
+			continue
+		}
+
+		if host == domain {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,251 @@
+package forwardproxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardProxyRejections(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         static.ForwardProxy
+		host           string
+		username       string
+		password       string
+		expectedStatus int
+	}{
+		{
+			desc:           "missing credentials",
+			config:         static.ForwardProxy{Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}, AllowedDestinations: []string{"*.example.com"}},
+			host:           "foo.example.com:443",
+			expectedStatus: http.StatusProxyAuthRequired,
+		},
+		{
+			desc:           "invalid credentials",
+			config:         static.ForwardProxy{Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}, AllowedDestinations: []string{"*.example.com"}},
+			host:           "foo.example.com:443",
+			username:       "test",
+			password:       "foo",
+			expectedStatus: http.StatusProxyAuthRequired,
+		},
+		{
+			desc:           "port not allowed",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}, AllowedPorts: []int{443}},
+			host:           "foo.example.com:22",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "missing port",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}},
+			host:           "foo.example.com",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "denied IP",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"203.0.113.0/24"}, DeniedDestinations: []string{"203.0.113.1"}},
+			host:           "203.0.113.1:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "denied domain",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}, DeniedDestinations: []string{"internal.example.com"}},
+			host:           "internal.example.com:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "domain resolved to a denied IP",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}, DeniedDestinations: []string{"203.0.113.0/24"}},
+			host:           "public.example.com:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "destination not allowed",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.org", "192.168.0.0/16"}},
+			host:           "public.example.com:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "domain resolved to a private IP",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}},
+			host:           "foo.example.com:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "allowed private IP",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"0.0.0.0/0"}},
+			host:           "10.0.0.1:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "metadata endpoint",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"0.0.0.0/0"}},
+			host:           "169.254.169.254:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "IPv4-mapped loopback IP",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"::/0"}},
+			host:           "[::ffff:127.0.0.1]:443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "private IP denied with the private destinations allowed",
+			config:         static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}, DeniedDestinations: []string{"10.0.0.0/8"}, AllowPrivateDestinations: true},
+			host:           "foo.example.com:443",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(context.Background(), &test.config)
+			require.NoError(t, err)
+			handler.(*forwardProxy).resolver = staticResolver{"foo.example.com": "10.0.0.1", "internal.example.com": "10.0.0.2", "public.example.com": "203.0.113.1"}
+
+			req := httptest.NewRequest(http.MethodConnect, "http://"+test.host, nil)
+			req.Host = test.host
+			if test.username != "" {
+				req.SetBasicAuth(test.username, test.password)
+				req.Header.Set(proxyAuthorizationHeader, req.Header.Get("Authorization"))
+				req.Header.Del("Authorization")
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus == http.StatusProxyAuthRequired {
+				assert.Equal(t, `Basic realm="traefik"`, recorder.Header().Get(proxyAuthenticateHeader))
+			}
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config static.ForwardProxy
+	}{
+		{
+			desc:   "invalid user",
+			config: static.ForwardProxy{Users: []string{"test"}, AllowedDestinations: []string{"*.example.com"}},
+		},
+		{
+			desc:   "invalid CIDR",
+			config: static.ForwardProxy{AllowedDestinations: []string{"10.0.0.0/42"}},
+		},
+		{
+			desc:   "no allowed destination",
+			config: static.ForwardProxy{DeniedDestinations: []string{"10.0.0.0/8"}},
+		},
+		{
+			desc:   "invalid port",
+			config: static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}, AllowedPorts: []int{0}},
+		},
+		{
+			desc:   "negative max connections",
+			config: static.ForwardProxy{AllowedDestinations: []string{"*.example.com"}, MaxConnections: -1},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), &test.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestForwardProxyTunnel(t *testing.T) {
+	destination, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = destination.Close() })
+
+	go func() {
+		for {
+			conn, err := destination.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	_, port, err := net.SplitHostPort(destination.Addr().String())
+	require.NoError(t, err)
+
+	handler, err := New(context.Background(), &static.ForwardProxy{
+		Users:                    []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+		AllowedDestinations:      []string{"127.0.0.1"},
+		AllowPrivateDestinations: true,
+		MaxConnections:           1,
+		BandwidthLimit:           1024,
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	openTunnel := func() (net.Conn, *bufio.Reader, *http.Response) {
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		require.NoError(t, err)
+
+		_, err = io.WriteString(conn, "CONNECT 127.0.0.1:"+port+" HTTP/1.1\r\n"+
+			"Host: 127.0.0.1:"+port+"\r\n"+
+			"Proxy-Authorization: Basic dGVzdDp0ZXN0\r\n\r\n")
+		require.NoError(t, err)
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+		require.NoError(t, err)
+
+		return conn, reader, resp
+	}
+
+	conn, reader, resp := openTunnel()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = io.WriteString(conn, "hello")
+	require.NoError(t, err)
+
+	echo := make([]byte, 5)
+	_, err = io.ReadFull(reader, echo)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(echo))
+
+	// Only one tunnel can be open at a time.
+	other, _, resp := openTunnel()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	_ = other.Close()
+
+	require.NoError(t, conn.Close())
+}
+
+type staticResolver map[string]string
+
+func (r staticResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ipAddr, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return []net.IPAddr{{IP: net.ParseIP(ipAddr)}}, nil
+}
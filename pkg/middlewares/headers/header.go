@@ -1,13 +1,11 @@
 package headers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
-	"github.com/containous/traefik/v2/pkg/log"
 )
 
 // Header is a middleware that helps setup a few basic security features.
@@ -25,8 +23,7 @@ func NewHeader(next http.Handler, cfg dynamic.Headers) *Header {
 	hasCustomHeaders := cfg.HasCustomHeadersDefined()
 	hasCorsHeaders := cfg.HasCorsHeadersDefined()
 
-	ctx := log.With(context.Background(), log.Str(log.MiddlewareType, typeName))
-	handleDeprecation(ctx, &cfg)
+	handleDeprecation(&cfg)
 
 	return &Header{
 		next:             next,
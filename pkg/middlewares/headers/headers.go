@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
@@ -17,9 +18,9 @@ const (
 	typeName = "Headers"
 )
 
-func handleDeprecation(ctx context.Context, cfg *dynamic.Headers) {
+// handleDeprecation converts the deprecated fields, whose uses are reported by the deprecation registry.
+func handleDeprecation(cfg *dynamic.Headers) {
 	if cfg.AccessControlAllowOrigin != "" {
-		log.FromContext(ctx).Warn("accessControlAllowOrigin is deprecated, please use accessControlAllowOriginList instead.")
 		cfg.AccessControlAllowOriginList = append(cfg.AccessControlAllowOriginList, cfg.AccessControlAllowOrigin)
 		cfg.AccessControlAllowOrigin = ""
 	}
@@ -37,7 +38,15 @@ func New(ctx context.Context, next http.Handler, cfg dynamic.Headers, name strin
 	logger := log.FromContext(mCtx)
 	logger.Debug("Creating middleware")
 
-	handleDeprecation(mCtx, &cfg)
+	handleDeprecation(&cfg)
+
+	if err := applySecurityPreset(&cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.ContentSecurityPolicyNonceHeader != "" && !strings.Contains(cfg.ContentSecurityPolicy, "$NONCE") {
+		return nil, errors.New("contentSecurityPolicyNonceHeader requires a $NONCE placeholder in the contentSecurityPolicy")
+	}
 
 	hasSecureHeaders := cfg.HasSecureHeadersDefined()
 	hasCustomHeaders := cfg.HasCustomHeadersDefined()
@@ -6,9 +6,12 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
 	"testing"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -98,6 +101,162 @@ func TestNew_customHeaders(t *testing.T) {
 	assert.Equal(t, "test_response", rw.Header().Get("X-Custom-Response-Header"))
 }
 
+func TestNew_informationalResponse(t *testing.T) {
+	if !middlewares.InformationalSupported {
+		t.Skip("the informational responses are not supported by this Go version")
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Del("Link")
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	cfg := dynamic.Headers{
+		CustomResponseHeaders: map[string]string{
+			"X-Custom-Response-Header": "test_response",
+		},
+	}
+
+	mid, err := New(context.Background(), next, cfg, "testing")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(mid)
+	t.Cleanup(server.Close)
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			assert.Equal(t, http.StatusEarlyHints, code)
+			hints = append(hints, header.Get("Link"))
+			return nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, []string{"</style.css>; rel=preload; as=style"}, hints)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "test_response", resp.Header.Get("X-Custom-Response-Header"))
+	assert.Empty(t, resp.Header.Get("Link"))
+}
+
+func TestNew_securityHeaders(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		cfg      dynamic.Headers
+		expected map[string]string
+	}{
+		{
+			desc: "modern preset",
+			cfg:  dynamic.Headers{SecurityHeaders: "modern"},
+			expected: map[string]string{
+				"Strict-Transport-Security":    "max-age=31536000; includeSubDomains",
+				"X-Content-Type-Options":       "nosniff",
+				"X-Frame-Options":              "SAMEORIGIN",
+				"Content-Security-Policy":      "object-src 'none'; base-uri 'self'; frame-ancestors 'self'; upgrade-insecure-requests",
+				"Referrer-Policy":              "strict-origin-when-cross-origin",
+				"Permissions-Policy":           "camera=(), geolocation=(), microphone=(), payment=(), usb=()",
+				"Cross-Origin-Opener-Policy":   "same-origin-allow-popups",
+				"Cross-Origin-Embedder-Policy": "",
+			},
+		},
+		{
+			desc: "strict preset",
+			cfg:  dynamic.Headers{SecurityHeaders: "strict"},
+			expected: map[string]string{
+				"Strict-Transport-Security":    "max-age=63072000; includeSubDomains; preload",
+				"X-Frame-Options":              "DENY",
+				"Referrer-Policy":              "no-referrer",
+				"Cross-Origin-Opener-Policy":   "same-origin",
+				"Cross-Origin-Embedder-Policy": "require-corp",
+				"Cross-Origin-Resource-Policy": "same-origin",
+			},
+		},
+		{
+			desc: "api preset",
+			cfg:  dynamic.Headers{SecurityHeaders: "api"},
+			expected: map[string]string{
+				"X-Frame-Options":         "DENY",
+				"Content-Security-Policy": "default-src 'none'; frame-ancestors 'none'",
+				"Permissions-Policy":      "",
+			},
+		},
+		{
+			desc: "preset overridden by the options",
+			cfg: dynamic.Headers{
+				SecurityHeaders:         "strict",
+				STSSeconds:              300,
+				CustomFrameOptionsValue: "SAMEORIGIN",
+				ReferrerPolicy:          "same-origin",
+				CustomResponseHeaders: map[string]string{
+					"cross-origin-embedder-policy": "credentialless",
+					"Cross-Origin-Resource-Policy": "",
+					"Content-Security-Policy":      "",
+				},
+			},
+			expected: map[string]string{
+				"Strict-Transport-Security":    "max-age=300",
+				"X-Frame-Options":              "SAMEORIGIN",
+				"Referrer-Policy":              "same-origin",
+				"Cross-Origin-Opener-Policy":   "same-origin",
+				"Cross-Origin-Embedder-Policy": "credentialless",
+				"Cross-Origin-Resource-Policy": "",
+				"Content-Security-Policy":      "",
+			},
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mid, err := New(context.Background(), next, test.cfg, "testing")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/foo", nil)
+			rw := httptest.NewRecorder()
+
+			mid.ServeHTTP(rw, req)
+
+			assert.Equal(t, http.StatusOK, rw.Code)
+			for header, value := range test.expected {
+				assert.Equal(t, value, rw.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestNew_contentSecurityPolicyNonceWithoutPlaceholder(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	cfg := dynamic.Headers{
+		ContentSecurityPolicy:            "script-src 'self'",
+		ContentSecurityPolicyNonceHeader: "X-CSP-Nonce",
+	}
+
+	_, err := New(context.Background(), next, cfg, "testing")
+	assert.Error(t, err)
+}
+
+func TestNew_unknownSecurityHeaders(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.Headers{SecurityHeaders: "lax"}, "testing")
+	assert.Error(t, err)
+}
+
 func Test_headers_getTracingInformation(t *testing.T) {
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 
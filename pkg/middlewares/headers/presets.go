@@ -0,0 +1,110 @@
+package headers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// securityPreset is a curated set of security headers.
+type securityPreset struct {
+	stsSeconds            int64
+	stsIncludeSubdomains  bool
+	stsPreload            bool
+	frameDeny             bool
+	frameOptions          string
+	contentSecurityPolicy string
+	referrerPolicy        string
+	// responseHeaders holds the headers without dedicated option, e.g. Permissions-Policy and the cross-origin policies.
+	responseHeaders map[string]string
+}
+
+var securityPresets = map[string]securityPreset{
+	// modern is meant for the web applications, without breaking the embedded contents nor the popups (e.g. OAuth).
+	"modern": {
+		stsSeconds:            31536000,
+		stsIncludeSubdomains:  true,
+		frameOptions:          "SAMEORIGIN",
+		contentSecurityPolicy: "object-src 'none'; base-uri 'self'; frame-ancestors 'self'; upgrade-insecure-requests",
+		referrerPolicy:        "strict-origin-when-cross-origin",
+		responseHeaders: map[string]string{
+			"Permissions-Policy":         "camera=(), geolocation=(), microphone=(), payment=(), usb=()",
+			"Cross-Origin-Opener-Policy": "same-origin-allow-popups",
+		},
+	},
+	// strict isolates the application, which must serve all its contents itself.
+	"strict": {
+		stsSeconds:            63072000,
+		stsIncludeSubdomains:  true,
+		stsPreload:            true,
+		frameDeny:             true,
+		contentSecurityPolicy: "default-src 'self'; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'; upgrade-insecure-requests",
+		referrerPolicy:        "no-referrer",
+		responseHeaders: map[string]string{
+			"Permissions-Policy":           "accelerometer=(), camera=(), display-capture=(), geolocation=(), gyroscope=(), magnetometer=(), microphone=(), payment=(), usb=()",
+			"Cross-Origin-Opener-Policy":   "same-origin",
+			"Cross-Origin-Embedder-Policy": "require-corp",
+			"Cross-Origin-Resource-Policy": "same-origin",
+		},
+	},
+	// api is meant for the APIs, whose responses are never rendered as documents.
+	"api": {
+		stsSeconds:            31536000,
+		stsIncludeSubdomains:  true,
+		frameDeny:             true,
+		contentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+		referrerPolicy:        "no-referrer",
+	},
+}
+
+// applySecurityPreset sets the options left empty to the values of the preset.
+// The X-Content-Type-Options header is always enabled by the presets.
+func applySecurityPreset(cfg *dynamic.Headers) error {
+	if cfg.SecurityHeaders == "" {
+		return nil
+	}
+
+	preset, ok := securityPresets[cfg.SecurityHeaders]
+	if !ok {
+		return fmt.Errorf("unknown security headers preset %q, must be modern, strict, or api", cfg.SecurityHeaders)
+	}
+
+	cfg.ContentTypeNosniff = true
+
+	if cfg.STSSeconds == 0 {
+		cfg.STSSeconds = preset.stsSeconds
+		cfg.STSIncludeSubdomains = cfg.STSIncludeSubdomains || preset.stsIncludeSubdomains
+		cfg.STSPreload = cfg.STSPreload || preset.stsPreload
+	}
+
+	if !cfg.FrameDeny && cfg.CustomFrameOptionsValue == "" {
+		cfg.FrameDeny = preset.frameDeny
+		cfg.CustomFrameOptionsValue = preset.frameOptions
+	}
+
+	if cfg.ContentSecurityPolicy == "" {
+		cfg.ContentSecurityPolicy = preset.contentSecurityPolicy
+	}
+
+	if cfg.ReferrerPolicy == "" {
+		cfg.ReferrerPolicy = preset.referrerPolicy
+	}
+
+	if len(preset.responseHeaders) == 0 {
+		return nil
+	}
+
+	// The custom response headers, including the empty ones removing a header, override the preset.
+	responseHeaders := make(map[string]string, len(cfg.CustomResponseHeaders)+len(preset.responseHeaders))
+	for header, value := range preset.responseHeaders {
+		responseHeaders[header] = value
+	}
+	for header, value := range cfg.CustomResponseHeaders {
+		delete(responseHeaders, http.CanonicalHeaderKey(header))
+		responseHeaders[header] = value
+	}
+	cfg.CustomResponseHeaders = responseHeaders
+
+	return nil
+}
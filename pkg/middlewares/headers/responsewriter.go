@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
 )
 
 type responseModifier struct {
@@ -35,6 +36,13 @@ func (w *responseModifier) WriteHeader(code int) {
 	if w.headersSent {
 		return
 	}
+
+	// The informational responses are sent as is, the modifier is applied on the final response.
+	if middlewares.IsInformational(code) {
+		w.w.WriteHeader(code)
+		return
+	}
+
 	defer func() {
 		w.code = code
 		w.headersSent = true
@@ -49,6 +49,10 @@ func newSecure(next http.Handler, cfg dynamic.Headers, contextKey string) *secur
 
 func (s secureHeader) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	s.secure.HandlerFuncWithNextForRequestOnly(rw, req, func(writer http.ResponseWriter, request *http.Request) {
+		if s.cfg.ContentSecurityPolicyNonceHeader != "" {
+			request.Header.Set(s.cfg.ContentSecurityPolicyNonceHeader, secure.CSPNonce(request.Context()))
+		}
+
 		s.next.ServeHTTP(newResponseModifier(writer, request, s.secure.ModifyResponseHeaders), request)
 	})
 }
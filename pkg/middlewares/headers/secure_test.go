@@ -7,6 +7,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Middleware tests based on https://github.com/unrolled/secure
@@ -189,3 +190,33 @@ func Test_newSecure_modifyResponse(t *testing.T) {
 		})
 	}
 }
+
+func Test_newSecure_contentSecurityPolicyNonce(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Csp-Nonce")))
+	})
+
+	cfg := dynamic.Headers{
+		ContentSecurityPolicy:            "script-src $NONCE 'strict-dynamic'; object-src 'none'",
+		ContentSecurityPolicyNonceHeader: "X-CSP-Nonce",
+	}
+	secure := newSecure(next, cfg, "mymiddleware")
+
+	var nonces []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+		req.Header.Set("X-CSP-Nonce", "spoofed")
+
+		rw := httptest.NewRecorder()
+		secure.ServeHTTP(rw, req)
+
+		nonce := rw.Body.String()
+		require.NotEmpty(t, nonce)
+		assert.NotEqual(t, "spoofed", nonce)
+		assert.Equal(t, "script-src 'nonce-"+nonce+"' 'strict-dynamic'; object-src 'none'", rw.Header().Get("Content-Security-Policy"))
+
+		nonces = append(nonces, nonce)
+	}
+
+	assert.NotEqual(t, nonces[0], nonces[1])
+}
@@ -0,0 +1,106 @@
+package httpversion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	typeName = "HTTPVersion"
+)
+
+const (
+	http10 = "HTTP/1.0"
+	http11 = "HTTP/1.1"
+	http2  = "HTTP/2"
+)
+
+// httpVersion enforces the HTTP versions policy of a router.
+type httpVersion struct {
+	next              http.Handler
+	name              string
+	versions          map[string]bool
+	rejectionsCounter metrics.Counter
+}
+
+// New creates a handler rejecting, with a 505 status code, the requests made with an HTTP version not allowed by the policy.
+func New(ctx context.Context, next http.Handler, config dynamic.RouterHTTPVersions, rejectionsCounter metrics.Counter, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	versions := map[string]bool{http10: true, http11: true, http2: true}
+
+	if len(config.Allowed) > 0 {
+		versions = make(map[string]bool)
+		for _, version := range config.Allowed {
+			if err := checkVersion(version); err != nil {
+				return nil, err
+			}
+			versions[version] = true
+		}
+	}
+
+	for _, version := range config.Forbidden {
+		if err := checkVersion(version); err != nil {
+			return nil, err
+		}
+		delete(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("the HTTP versions policy of the router %s forbids all the HTTP versions", name)
+	}
+
+	return &httpVersion{
+		next:              next,
+		name:              name,
+		versions:          versions,
+		rejectionsCounter: rejectionsCounter,
+	}, nil
+}
+
+func (h *httpVersion) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	version := requestVersion(req)
+	if h.versions[version] {
+		h.next.ServeHTTP(rw, req)
+		return
+	}
+
+	log.FromContext(middlewares.GetLoggerCtx(req.Context(), h.name, typeName)).Debugf("Rejecting %s request", req.Proto)
+
+	if h.rejectionsCounter != nil {
+		h.rejectionsCounter.With("router", h.name, "version", version).Add(1)
+	}
+
+	http.Error(rw, http.StatusText(http.StatusHTTPVersionNotSupported), http.StatusHTTPVersionNotSupported)
+}
+
+func checkVersion(version string) error {
+	switch version {
+	case http10, http11, http2:
+		return nil
+	case "HTTP/3":
+		return fmt.Errorf("unsupported HTTP version %s: HTTP/3 is not served by Traefik", version)
+	default:
+		return fmt.Errorf("unknown HTTP version %q, must be %s, %s, or %s", version, http10, http11, http2)
+	}
+}
+
+// requestVersion returns the HTTP version of the request, as written in the policy.
+func requestVersion(req *http.Request) string {
+	switch {
+	case req.ProtoMajor == 2:
+		return http2
+	case req.ProtoMajor == 1 && req.ProtoMinor == 0:
+		return http10
+	case req.ProtoMajor == 1 && req.ProtoMinor == 1:
+		return http11
+	default:
+		return req.Proto
+	}
+}
@@ -0,0 +1,147 @@
+package httpversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectingCounter is a counter implementation that enables access to the LastLabelValues.
+type collectingCounter struct {
+	CounterValue    float64
+	LastLabelValues []string
+}
+
+func (c *collectingCounter) With(labelValues ...string) metrics.Counter {
+	c.LastLabelValues = labelValues
+	return c
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.CounterValue += delta
+}
+
+func TestHTTPVersion(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         dynamic.RouterHTTPVersions
+		protoMajor     int
+		protoMinor     int
+		expectedStatus int
+	}{
+		{
+			desc:           "empty policy",
+			protoMajor:     1,
+			protoMinor:     0,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "forbidden HTTP/1.0",
+			config:         dynamic.RouterHTTPVersions{Forbidden: []string{"HTTP/1.0"}},
+			protoMajor:     1,
+			protoMinor:     0,
+			expectedStatus: http.StatusHTTPVersionNotSupported,
+		},
+		{
+			desc:           "not forbidden HTTP/1.1",
+			config:         dynamic.RouterHTTPVersions{Forbidden: []string{"HTTP/1.0"}},
+			protoMajor:     1,
+			protoMinor:     1,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "allowed HTTP/2",
+			config:         dynamic.RouterHTTPVersions{Allowed: []string{"HTTP/2"}},
+			protoMajor:     2,
+			protoMinor:     0,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "not allowed HTTP/1.1",
+			config:         dynamic.RouterHTTPVersions{Allowed: []string{"HTTP/2"}},
+			protoMajor:     1,
+			protoMinor:     1,
+			expectedStatus: http.StatusHTTPVersionNotSupported,
+		},
+		{
+			desc:           "allowed then forbidden",
+			config:         dynamic.RouterHTTPVersions{Allowed: []string{"HTTP/1.1", "HTTP/2"}, Forbidden: []string{"HTTP/1.1"}},
+			protoMajor:     1,
+			protoMinor:     1,
+			expectedStatus: http.StatusHTTPVersionNotSupported,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			counter := &collectingCounter{}
+
+			handler, err := New(context.Background(), next, test.config, counter, "routerTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.ProtoMajor = test.protoMajor
+			req.ProtoMinor = test.protoMinor
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			if test.expectedStatus != http.StatusHTTPVersionNotSupported {
+				assert.Zero(t, counter.CounterValue)
+				return
+			}
+
+			assert.Equal(t, float64(1), counter.CounterValue)
+			assert.Equal(t, []string{"router", "routerTest", "version", requestVersion(req)}, counter.LastLabelValues)
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.RouterHTTPVersions
+	}{
+		{
+			desc:   "unknown allowed version",
+			config: dynamic.RouterHTTPVersions{Allowed: []string{"HTTP/2.0"}},
+		},
+		{
+			desc:   "unknown forbidden version",
+			config: dynamic.RouterHTTPVersions{Forbidden: []string{"http/1.0"}},
+		},
+		{
+			desc:   "HTTP/3",
+			config: dynamic.RouterHTTPVersions{Allowed: []string{"HTTP/3"}},
+		},
+		{
+			desc:   "all versions forbidden",
+			config: dynamic.RouterHTTPVersions{Allowed: []string{"HTTP/2"}, Forbidden: []string{"HTTP/2"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, nil, "routerTest")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
@@ -24,19 +25,20 @@ type inFlightReq struct {
 
 // New creates a max request middleware.
 // If no source criterion is provided in the config, it defaults to RequestHost.
-func New(ctx context.Context, next http.Handler, config dynamic.InFlightReq, name string) (http.Handler, error) {
+func New(ctx context.Context, next http.Handler, config dynamic.InFlightReq, ipSets ip.Sets, name string) (http.Handler, error) {
 	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
 	log.FromContext(ctxLog).Debug("Creating middleware")
 
 	if config.SourceCriterion == nil ||
 		config.SourceCriterion.IPStrategy == nil &&
-			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost &&
+			config.SourceCriterion.RequestQueryParameterName == "" && config.SourceCriterion.RequestCookieName == "" {
 		config.SourceCriterion = &dynamic.SourceCriterion{
 			RequestHost: true,
 		}
 	}
 
-	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion, ipSets)
 	if err != nil {
 		return nil, fmt.Errorf("error creating requests limiter: %w", err)
 	}
@@ -0,0 +1,40 @@
+package middlewares
+
+import "net/http"
+
+// IsInformational returns whether the status code is the one of an informational response (e.g. 103 Early Hints),
+// which is sent before the final response.
+// The 101 Switching Protocols status code is considered as a final response.
+func IsInformational(code int) bool {
+	return code >= 100 && code < http.StatusOK && code != http.StatusSwitchingProtocols
+}
+
+// WriteInformational sends an informational response,
+// with the given headers added to the ones already set on the response writer.
+// The given headers are removed afterwards, so they are not sent with the final response.
+// Nothing is sent when Traefik is built with a Go version not supporting the informational responses.
+func WriteInformational(rw http.ResponseWriter, header http.Header, code int) {
+	if !InformationalSupported {
+		return
+	}
+
+	dst := rw.Header()
+
+	previous := make(http.Header)
+	for key, values := range header {
+		if current, ok := dst[key]; ok {
+			previous[key] = current
+		}
+		dst[key] = append(append([]string(nil), dst[key]...), values...)
+	}
+
+	rw.WriteHeader(code)
+
+	for key := range header {
+		if values, ok := previous[key]; ok {
+			dst[key] = values
+			continue
+		}
+		delete(dst, key)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build go1.20
+// +build go1.20
+
+package middlewares
+
+// InformationalSupported is whether the informational responses can be sent to the clients:
+// net/http sends them since Go 1.19, and the reverse proxy relays the ones of the services since Go 1.20.
+const InformationalSupported = true
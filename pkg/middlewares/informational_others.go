@@ -0,0 +1,8 @@
+//go:build !go1.20
+// +build !go1.20
+
+package middlewares
+
+// InformationalSupported is whether the informational responses can be sent to the clients:
+// before Go 1.19, net/http takes them for the final response.
+const InformationalSupported = false
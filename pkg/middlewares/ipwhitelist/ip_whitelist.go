@@ -27,25 +27,31 @@ type ipWhiteLister struct {
 }
 
 // New builds a new IPWhiteLister given a list of CIDR-Strings to whitelist.
-func New(ctx context.Context, next http.Handler, config dynamic.IPWhiteList, name string) (http.Handler, error) {
+// The IP sets are looked up in ipSets.
+func New(ctx context.Context, next http.Handler, config dynamic.IPWhiteList, ipSets ip.Sets, name string) (http.Handler, error) {
 	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
 	logger.Debug("Creating middleware")
 
-	if len(config.SourceRange) == 0 {
-		return nil, errors.New("sourceRange is empty, IPWhiteLister not created")
+	if len(config.SourceRange) == 0 && len(config.IPSets) == 0 {
+		return nil, errors.New("sourceRange and ipSets are empty, IPWhiteLister not created")
 	}
 
-	checker, err := ip.NewChecker(config.SourceRange)
+	sets, err := ip.GetSets(ctx, ipSets, config.IPSets)
+	if err != nil {
+		return nil, err
+	}
+
+	checker, err := ip.NewChecker(config.SourceRange, sets...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse CIDR whitelist %s: %w", config.SourceRange, err)
 	}
 
-	strategy, err := config.IPStrategy.Get()
+	strategy, err := config.IPStrategy.Get(ctx, ipSets)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debugf("Setting up IPWhiteLister with sourceRange: %s", config.SourceRange)
+	logger.Debugf("Setting up IPWhiteLister with sourceRange: %s and ipSets: %s", config.SourceRange, config.IPSets)
 
 	return &ipWhiteLister{
 		strategy:    strategy,
@@ -2,11 +2,13 @@ package ipwhitelist
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,7 +40,7 @@ func TestNewIPWhiteLister(t *testing.T) {
 			t.Parallel()
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
-			whiteLister, err := New(context.Background(), next, test.whiteList, "traefikTest")
+			whiteLister, err := New(context.Background(), next, test.whiteList, nil, "traefikTest")
 
 			if test.expectedError {
 				assert.Error(t, err)
@@ -81,7 +83,7 @@ func TestIPWhiteLister_ServeHTTP(t *testing.T) {
 			t.Parallel()
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
-			whiteLister, err := New(context.Background(), next, test.whiteList, "traefikTest")
+			whiteLister, err := New(context.Background(), next, test.whiteList, nil, "traefikTest")
 			require.NoError(t, err)
 
 			recorder := httptest.NewRecorder()
@@ -98,3 +100,61 @@ func TestIPWhiteLister_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+type ipSetsMock map[string]*ip.Set
+
+func (s ipSetsMock) Get(_ context.Context, name string) (*ip.Set, error) {
+	set, ok := s[name]
+	if !ok {
+		return nil, fmt.Errorf("the IP set %s does not exist", name)
+	}
+
+	return set, nil
+}
+
+func TestIPWhiteLister_ipSets(t *testing.T) {
+	set := &ip.Set{}
+	require.NoError(t, set.Update([]string{"20.20.20.0/24"}))
+
+	ipSets := ipSetsMock{"foo": set}
+
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.IPWhiteList{IPSets: []string{"bar"}}, ipSets, "traefikTest")
+	assert.Error(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	whiteLister, err := New(context.Background(), next, dynamic.IPWhiteList{
+		SourceRange: []string{"10.10.10.10"},
+		IPSets:      []string{"foo"},
+	}, ipSets, "traefikTest")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		remoteAddr string
+		expected   int
+	}{
+		{remoteAddr: "10.10.10.10:1234", expected: http.StatusOK},
+		{remoteAddr: "20.20.20.21:1234", expected: http.StatusOK},
+		{remoteAddr: "20.20.21.21:1234", expected: http.StatusForbidden},
+	}
+
+	for _, test := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+		req.RemoteAddr = test.remoteAddr
+		recorder := httptest.NewRecorder()
+
+		whiteLister.ServeHTTP(recorder, req)
+
+		assert.Equal(t, test.expected, recorder.Code, test.remoteAddr)
+	}
+
+	// The white list follows the updates of the set.
+	require.NoError(t, set.Update([]string{"20.20.21.0/24"}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+	req.RemoteAddr = "20.20.21.21:1234"
+	recorder := httptest.NewRecorder()
+
+	whiteLister.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
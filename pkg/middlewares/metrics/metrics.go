@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,11 +11,13 @@ import (
 	"unicode/utf8"
 
 	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/middlewares/retry"
 	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tracing"
 	gokitmetrics "github.com/go-kit/kit/metrics"
 )
 
@@ -24,16 +27,35 @@ const (
 	protoWebsocket = "websocket"
 	typeName       = "Metrics"
 	nameEntrypoint = "metrics-entrypoint"
+	nameRouter     = "metrics-router"
 	nameService    = "metrics-service"
 )
 
+// traceIDExemplarLabel is the label of the exemplars of the request durations, linking them to the trace of the request.
+const traceIDExemplarLabel = "trace_id"
+
+// Protocols of the connections, labeling the open connections of the entry points.
+const (
+	connProtoHTTPS     = "https"
+	connProtoH2        = "h2"
+	connProtoH2C       = "h2c"
+	connProtoWebsocket = "ws"
+)
+
 type metricsMiddleware struct {
 	next                 http.Handler
 	reqsCounter          gokitmetrics.Counter
 	reqsTLSCounter       gokitmetrics.Counter
 	reqDurationHistogram metrics.ScalableHistogram
 	openConnsGauge       gokitmetrics.Gauge
+	reqSizeHistogram     gokitmetrics.Histogram
+	respSizeHistogram    gokitmetrics.Histogram
 	baseLabels           []string
+	requestLabels        []*metrics.RequestLabel
+	pathNormalizer       *pathNormalizer
+	// connectionLabels tells whether the open connections are partitioned by connection protocol and TLS version,
+	// rather than by method and request protocol.
+	connectionLabels bool
 }
 
 // NewEntryPointMiddleware creates a new metrics middleware for an Entrypoint.
@@ -47,21 +69,52 @@ func NewEntryPointMiddleware(ctx context.Context, next http.Handler, registry me
 		reqDurationHistogram: registry.EntryPointReqDurationHistogram(),
 		openConnsGauge:       registry.EntryPointOpenConnsGauge(),
 		baseLabels:           []string{"entrypoint", entryPointName},
+		requestLabels:        registry.RequestLabels(),
+		connectionLabels:     true,
 	}
 }
 
-// NewServiceMiddleware creates a new metrics middleware for a Service.
-func NewServiceMiddleware(ctx context.Context, next http.Handler, registry metrics.Registry, serviceName string) http.Handler {
+// NewRouterMiddleware creates a new metrics middleware for a Router.
+func NewRouterMiddleware(ctx context.Context, next http.Handler, registry metrics.Registry, routerName, serviceName string) http.Handler {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, nameRouter, typeName)).Debug("Creating middleware")
+
+	return &metricsMiddleware{
+		next:                 next,
+		reqsCounter:          registry.RouterReqsCounter(),
+		reqsTLSCounter:       registry.RouterReqsTLSCounter(),
+		reqDurationHistogram: registry.RouterReqDurationHistogram(),
+		openConnsGauge:       registry.RouterOpenConnsGauge(),
+		baseLabels:           []string{"router", routerName, "service", serviceName},
+		requestLabels:        registry.RequestLabels(),
+	}
+}
+
+// NewServiceMiddleware creates a new metrics middleware for a Service,
+// whose path label is normalized according to the given rules, if any.
+func NewServiceMiddleware(ctx context.Context, next http.Handler, registry metrics.Registry, serviceName string, pathNormalization *dynamic.PathNormalization) (http.Handler, error) {
 	log.FromContext(middlewares.GetLoggerCtx(ctx, nameService, typeName)).Debug("Creating middleware")
 
+	var normalizer *pathNormalizer
+	if pathNormalization != nil {
+		var err error
+		normalizer, err = newPathNormalizer(pathNormalization)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &metricsMiddleware{
 		next:                 next,
 		reqsCounter:          registry.ServiceReqsCounter(),
 		reqsTLSCounter:       registry.ServiceReqsTLSCounter(),
 		reqDurationHistogram: registry.ServiceReqDurationHistogram(),
 		openConnsGauge:       registry.ServiceOpenConnsGauge(),
+		reqSizeHistogram:     registry.ServiceReqSizeHistogram(),
+		respSizeHistogram:    registry.ServiceRespSizeHistogram(),
 		baseLabels:           []string{"service", serviceName},
-	}
+		requestLabels:        registry.RequestLabels(),
+		pathNormalizer:       normalizer,
+	}, nil
 }
 
 // WrapEntryPointHandler Wraps metrics entrypoint to alice.Constructor.
@@ -71,10 +124,17 @@ func WrapEntryPointHandler(ctx context.Context, registry metrics.Registry, entry
 	}
 }
 
+// WrapRouterHandler Wraps metrics router to alice.Constructor.
+func WrapRouterHandler(ctx context.Context, registry metrics.Registry, routerName, serviceName string) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		return NewRouterMiddleware(ctx, next, registry, routerName, serviceName), nil
+	}
+}
+
 // WrapServiceHandler Wraps metrics service to alice.Constructor.
-func WrapServiceHandler(ctx context.Context, registry metrics.Registry, serviceName string) alice.Constructor {
+func WrapServiceHandler(ctx context.Context, registry metrics.Registry, serviceName string, pathNormalization *dynamic.PathNormalization) alice.Constructor {
 	return func(next http.Handler) (http.Handler, error) {
-		return NewServiceMiddleware(ctx, next, registry, serviceName), nil
+		return NewServiceMiddleware(ctx, next, registry, serviceName, pathNormalization)
 	}
 }
 
@@ -83,8 +143,13 @@ func (m *metricsMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	labels = append(labels, m.baseLabels...)
 	labels = append(labels, "method", getMethod(req), "protocol", getRequestProtocol(req))
 
-	m.openConnsGauge.With(labels...).Add(1)
-	defer m.openConnsGauge.With(labels...).Add(-1)
+	openConnsLabels := labels
+	if m.connectionLabels {
+		openConnsLabels = append(append([]string{}, m.baseLabels...), "protocol", getConnectionProtocol(req), "tls_version", getConnectionTLSVersion(req))
+	}
+
+	m.openConnsGauge.With(openConnsLabels...).Add(1)
+	defer m.openConnsGauge.With(openConnsLabels...).Add(-1)
 
 	// TLS metrics
 	if req.TLS != nil {
@@ -95,19 +160,64 @@ func (m *metricsMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 		m.reqsTLSCounter.With(tlsLabels...).Add(1)
 	}
 
+	var body *countingReadCloser
+	if m.reqSizeHistogram != nil && req.Body != nil && req.Body != http.NoBody {
+		body = &countingReadCloser{ReadCloser: req.Body}
+		req.Body = body
+	}
+
+	// The request labels are valued before the next handlers get a chance to modify the request.
+	var requestLabels []string
+	for _, label := range m.requestLabels {
+		requestLabels = append(requestLabels, label.Name(), label.Value(req))
+	}
+
 	recorder := newResponseRecorder(rw)
 	start := time.Now()
 
 	m.next.ServeHTTP(recorder, req)
 
-	labels = append(labels, "code", strconv.Itoa(recorder.getCode()), "path", getPath(req))
+	m.observeSizes(req, body, recorder)
+
+	labels = append(labels, "code", strconv.Itoa(recorder.getCode()), "path", m.getPath(req))
+	labels = append(labels, requestLabels...)
 
 	histograms := m.reqDurationHistogram.With(labels...)
-	histograms.ObserveFromStart(start)
+	if traceID := tracing.GetTraceID(req); traceID != "" {
+		histograms.ObserveFromStartWithExemplar(start, map[string]string{traceIDExemplarLabel: traceID})
+	} else {
+		histograms.ObserveFromStart(start)
+	}
 
 	m.reqsCounter.With(labels...).Add(1)
 }
 
+func (m *metricsMiddleware) observeSizes(req *http.Request, body *countingReadCloser, recorder recorder) {
+	if m.reqSizeHistogram == nil || m.respSizeHistogram == nil {
+		return
+	}
+
+	var labels []string
+	labels = append(labels, m.baseLabels...)
+	labels = append(labels, "router", getRouterName(req), "method", getMethod(req))
+
+	var reqSize int64
+	if body != nil {
+		reqSize = body.size
+	}
+
+	m.reqSizeHistogram.With(labels...).Observe(float64(reqSize))
+	m.respSizeHistogram.With(labels...).Observe(float64(recorder.getSize()))
+}
+
+func (m *metricsMiddleware) getPath(req *http.Request) string {
+	if m.pathNormalizer != nil {
+		return m.pathNormalizer.normalize(req)
+	}
+
+	return getPath(req)
+}
+
 func getPath(req *http.Request) string {
 	path := req.URL.Path
 	if path != "" {
@@ -128,6 +238,32 @@ func getRequestProtocol(req *http.Request) string {
 	}
 }
 
+// getConnectionProtocol returns the protocol of the connection the request is received on:
+// ws for the WebSocket connections, which are long-lived, h2 or h2c for HTTP/2, and https or http otherwise.
+func getConnectionProtocol(req *http.Request) string {
+	switch {
+	case isWebsocketRequest(req):
+		return connProtoWebsocket
+	case req.ProtoMajor == 2 && req.TLS != nil:
+		return connProtoH2
+	case req.ProtoMajor == 2:
+		return connProtoH2C
+	case req.TLS != nil:
+		return connProtoHTTPS
+	default:
+		return protoHTTP
+	}
+}
+
+// getConnectionTLSVersion returns the TLS version of the connection the request is received on, or none.
+func getConnectionTLSVersion(req *http.Request) string {
+	if req.TLS == nil {
+		return "none"
+	}
+
+	return getRequestTLSVersion(req)
+}
+
 // isWebsocketRequest determines if the specified HTTP request is a websocket handshake request.
 func isWebsocketRequest(req *http.Request) bool {
 	return containsHeader(req, "Connection", "upgrade") && containsHeader(req, "Upgrade", "websocket")
@@ -181,6 +317,7 @@ func getRequestTLSCipher(req *http.Request) string {
 
 type retryMetrics interface {
 	ServiceRetriesCounter() gokitmetrics.Counter
+	ServiceRetryDurationHistogram() metrics.ScalableHistogram
 }
 
 // NewRetryListener instantiates a MetricsRetryListener with the given retryMetrics.
@@ -195,7 +332,29 @@ type RetryListener struct {
 	serviceName  string
 }
 
-// Retried tracks the retry in the RequestMetrics implementation.
-func (m *RetryListener) Retried(req *http.Request, attempt int) {
-	m.retryMetrics.ServiceRetriesCounter().With("service", m.serviceName).Add(1)
+// Retried does nothing, the retries being tracked once their outcome is known.
+func (m *RetryListener) Retried(req *http.Request, attempt int) {}
+
+// Finished tracks the retries of the request, by attempt number and outcome,
+// and the duration of all its attempts, in the RequestMetrics implementation.
+func (m *RetryListener) Finished(req *http.Request, attempts int, outcome string, duration time.Duration) {
+	for attempt := 2; attempt <= attempts; attempt++ {
+		m.retryMetrics.ServiceRetriesCounter().With("service", m.serviceName, "attempt", strconv.Itoa(attempt), "outcome", outcome).Add(1)
+	}
+
+	if histogram := m.retryMetrics.ServiceRetryDurationHistogram(); histogram != nil {
+		histogram.With("service", m.serviceName, "outcome", outcome).Observe(duration.Seconds())
+	}
+}
+
+// countingReadCloser counts the bytes read from the request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	size int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.size += int64(n)
+	return n, err
 }
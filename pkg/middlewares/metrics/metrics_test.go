@@ -1,13 +1,24 @@
 package metrics
 
 import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	traefikmetrics "github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/retry"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // CollectingCounter is a metrics.Counter implementation that enables access to the CounterValue and LastLabelValues.
@@ -31,33 +42,42 @@ func TestMetricsRetryListener(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	retryMetrics := newCollectingRetryMetrics()
 	retryListener := NewRetryListener(retryMetrics, "serviceName")
-	retryListener.Retried(req, 1)
 	retryListener.Retried(req, 2)
+	retryListener.Retried(req, 3)
+	retryListener.Finished(req, 3, retry.OutcomeExhausted, 2*time.Second)
 
 	wantCounterValue := float64(2)
 	if retryMetrics.retriesCounter.CounterValue != wantCounterValue {
 		t.Errorf("got counter value of %f, want %f", retryMetrics.retriesCounter.CounterValue, wantCounterValue)
 	}
 
-	wantLabelValues := []string{"service", "serviceName"}
+	wantLabelValues := []string{"service", "serviceName", "attempt", "3", "outcome", "exhausted"}
 	if !reflect.DeepEqual(retryMetrics.retriesCounter.LastLabelValues, wantLabelValues) {
 		t.Errorf("wrong label values %v used, want %v", retryMetrics.retriesCounter.LastLabelValues, wantLabelValues)
 	}
+
+	assert.Equal(t, []float64{2}, retryMetrics.retryDurations.Values)
+	assert.Equal(t, []string{"service", "serviceName", "outcome", "exhausted"}, retryMetrics.retryDurations.LastLabelValues)
 }
 
 // collectingRetryMetrics is an implementation of the retryMetrics interface that can be used inside tests to collect the times Add() was called.
 type collectingRetryMetrics struct {
 	retriesCounter *CollectingCounter
+	retryDurations *collectingHistogram
 }
 
 func newCollectingRetryMetrics() *collectingRetryMetrics {
-	return &collectingRetryMetrics{retriesCounter: &CollectingCounter{}}
+	return &collectingRetryMetrics{retriesCounter: &CollectingCounter{}, retryDurations: &collectingHistogram{}}
 }
 
 func (m *collectingRetryMetrics) ServiceRetriesCounter() metrics.Counter {
 	return m.retriesCounter
 }
 
+func (m *collectingRetryMetrics) ServiceRetryDurationHistogram() traefikmetrics.ScalableHistogram {
+	return collectingScalableHistogram{m.retryDurations}
+}
+
 type rwWithCloseNotify struct {
 	*httptest.ResponseRecorder
 }
@@ -98,3 +118,343 @@ func TestCloseNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestPathNormalizer(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  dynamic.PathNormalization
+		target  string
+		expPath string
+	}{
+		{
+			desc:    "no rules",
+			target:  "http://example.com/users/42",
+			expPath: "/users/42",
+		},
+		{
+			desc: "first matching rule",
+			config: dynamic.PathNormalization{
+				Rules: []dynamic.PathNormalizationRule{
+					{Regex: `^/orders/\d+$`, Replacement: "/orders/{id}"},
+					{Regex: `^/users/\d+(/.*)?$`, Replacement: "/users/{id}$1"},
+					{Regex: `^/users/.*$`, Replacement: "/users/*"},
+				},
+			},
+			target:  "http://example.com/users/42/profile",
+			expPath: "/users/{id}/profile",
+		},
+		{
+			desc: "no matching rule",
+			config: dynamic.PathNormalization{
+				Rules: []dynamic.PathNormalizationRule{
+					{Regex: `^/orders/\d+$`, Replacement: "/orders/{id}"},
+				},
+			},
+			target:  "http://example.com/users/42",
+			expPath: "/users/42",
+		},
+		{
+			desc: "allowed host",
+			config: dynamic.PathNormalization{
+				Hosts: []string{"Example.com"},
+			},
+			target:  "http://example.com:8080/users/42",
+			expPath: "/users/42",
+		},
+		{
+			desc: "other host",
+			config: dynamic.PathNormalization{
+				Rules: []dynamic.PathNormalizationRule{
+					{Regex: `^/users/\d+$`, Replacement: "/users/{id}"},
+				},
+				Hosts: []string{"example.com"},
+			},
+			target:  "http://scanner.example.org/users/42",
+			expPath: "other",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			normalizer, err := newPathNormalizer(&test.config)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+
+			assert.Equal(t, test.expPath, normalizer.normalize(req))
+		})
+	}
+}
+
+func TestNewPathNormalizer_invalidRegex(t *testing.T) {
+	_, err := newPathNormalizer(&dynamic.PathNormalization{
+		Rules: []dynamic.PathNormalizationRule{{Regex: "^/users/(", Replacement: "/users"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestMetricsMiddleware_sizes(t *testing.T) {
+	registry := traefikmetrics.NewVoidRegistry()
+	reqSizes := &collectingHistogram{}
+	respSizes := &collectingHistogram{}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = ioutil.ReadAll(req.Body)
+		_, _ = rw.Write([]byte("hello"))
+		_, _ = rw.Write([]byte(" world"))
+	})
+
+	handler := &metricsMiddleware{
+		next:                 next,
+		reqsCounter:          registry.ServiceReqsCounter(),
+		reqsTLSCounter:       registry.ServiceReqsTLSCounter(),
+		reqDurationHistogram: registry.ServiceReqDurationHistogram(),
+		openConnsGauge:       registry.ServiceOpenConnsGauge(),
+		reqSizeHistogram:     reqSizes,
+		respSizeHistogram:    respSizes,
+		baseLabels:           []string{"service", "foo"},
+	}
+
+	routerHandler, err := WrapRouterNameHandler("bar")(handler)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("ping"))
+	routerHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+	wantLabelValues := []string{"service", "foo", "router", "bar", "method", http.MethodPost}
+
+	assert.Equal(t, []float64{4}, reqSizes.Values)
+	assert.Equal(t, wantLabelValues, reqSizes.LastLabelValues)
+	assert.Equal(t, []float64{11}, respSizes.Values)
+	assert.Equal(t, wantLabelValues, respSizes.LastLabelValues)
+}
+
+// routerRegistry is a registry collecting the requests counted on routers.
+type routerRegistry struct {
+	traefikmetrics.Registry
+	reqsCounter *CollectingCounter
+}
+
+func (r routerRegistry) RouterReqsCounter() metrics.Counter {
+	return r.reqsCounter
+}
+
+func TestNewRouterMiddleware(t *testing.T) {
+	registry := routerRegistry{Registry: traefikmetrics.NewVoidRegistry(), reqsCounter: &CollectingCounter{}}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	handler := NewRouterMiddleware(context.Background(), next, registry, "foo@file", "bar@file")
+
+	req := httptest.NewRequest(http.MethodGet, "/baz", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	wantLabelValues := []string{"router", "foo@file", "service", "bar@file", "method", http.MethodGet, "protocol", "http", "code", "418", "path", "/baz"}
+
+	assert.Equal(t, float64(1), registry.reqsCounter.CounterValue)
+	assert.Equal(t, wantLabelValues, registry.reqsCounter.LastLabelValues)
+}
+
+// entryPointRegistry is a registry collecting the open connections of entry points.
+type entryPointRegistry struct {
+	traefikmetrics.Registry
+	openConnsGauge *testhelpers.CollectingGauge
+}
+
+func (r entryPointRegistry) EntryPointOpenConnsGauge() metrics.Gauge {
+	return r.openConnsGauge
+}
+
+func TestNewEntryPointMiddleware_openConnections(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		protoMajor int
+		tls        *tls.ConnectionState
+		headers    map[string]string
+		wantLabels []string
+	}{
+		{
+			desc:       "HTTP",
+			protoMajor: 1,
+			wantLabels: []string{"entrypoint", "web", "protocol", "http", "tls_version", "none"},
+		},
+		{
+			desc:       "HTTPS",
+			protoMajor: 1,
+			tls:        &tls.ConnectionState{Version: tls.VersionTLS12},
+			wantLabels: []string{"entrypoint", "web", "protocol", "https", "tls_version", "1.2"},
+		},
+		{
+			desc:       "HTTP/2",
+			protoMajor: 2,
+			tls:        &tls.ConnectionState{Version: tls.VersionTLS13},
+			wantLabels: []string{"entrypoint", "web", "protocol", "h2", "tls_version", "1.3"},
+		},
+		{
+			desc:       "HTTP/2 cleartext",
+			protoMajor: 2,
+			wantLabels: []string{"entrypoint", "web", "protocol", "h2c", "tls_version", "none"},
+		},
+		{
+			desc:       "WebSocket",
+			protoMajor: 1,
+			tls:        &tls.ConnectionState{Version: tls.VersionTLS13},
+			headers:    map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"},
+			wantLabels: []string{"entrypoint", "web", "protocol", "ws", "tls_version", "1.3"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry := entryPointRegistry{Registry: traefikmetrics.NewVoidRegistry(), openConnsGauge: &testhelpers.CollectingGauge{}}
+
+			handler := NewEntryPointMiddleware(context.Background(), http.NotFoundHandler(), registry, "web")
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.ProtoMajor = test.protoMajor
+			req.TLS = test.tls
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.wantLabels, registry.openConnsGauge.LastLabelValues)
+		})
+	}
+}
+
+// requestLabelsRegistry is a registry collecting the requests counted on routers, with extra request labels.
+type requestLabelsRegistry struct {
+	routerRegistry
+	requestLabels []*traefikmetrics.RequestLabel
+}
+
+func (r requestLabelsRegistry) RequestLabels() []*traefikmetrics.RequestLabel {
+	return r.requestLabels
+}
+
+func TestNewRouterMiddleware_requestLabels(t *testing.T) {
+	registry := requestLabelsRegistry{
+		routerRegistry: routerRegistry{Registry: traefikmetrics.NewVoidRegistry(), reqsCounter: &CollectingCounter{}},
+		requestLabels: traefikmetrics.NewRequestLabels(map[string]*types.PrometheusRequestLabel{
+			"tenant": {Header: "X-Tenant-ID", MaxValues: 10},
+		}),
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// The label is valued before the next handlers modify the request.
+		req.Header.Del("X-Tenant-ID")
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	handler := NewRouterMiddleware(context.Background(), next, registry, "foo@file", "bar@file")
+
+	req := httptest.NewRequest(http.MethodGet, "/baz", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	wantLabelValues := []string{"router", "foo@file", "service", "bar@file", "method", http.MethodGet, "protocol", "http", "code", "418", "path", "/baz", "tenant", "acme"}
+
+	assert.Equal(t, float64(1), registry.reqsCounter.CounterValue)
+	assert.Equal(t, wantLabelValues, registry.reqsCounter.LastLabelValues)
+}
+
+// middlewareRegistry is a registry collecting the requests counted on middlewares.
+type middlewareRegistry struct {
+	traefikmetrics.Registry
+	reqsCounter          *CollectingCounter
+	shortCircuitsCounter *CollectingCounter
+	errorsCounter        *CollectingCounter
+}
+
+func (r middlewareRegistry) MiddlewareReqsCounter() metrics.Counter {
+	return r.reqsCounter
+}
+
+func (r middlewareRegistry) MiddlewareShortCircuitsCounter() metrics.Counter {
+	return r.shortCircuitsCounter
+}
+
+func (r middlewareRegistry) MiddlewareErrorsCounter() metrics.Counter {
+	return r.errorsCounter
+}
+
+func TestWrapMiddlewareHandler(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		middleware        func(next http.Handler) http.Handler
+		wantShortCircuits float64
+		wantErrors        float64
+		wantLabelValues   []string
+	}{
+		{
+			desc: "calling the next handler",
+			middleware: func(next http.Handler) http.Handler {
+				return next
+			},
+		},
+		{
+			desc: "rejecting the request",
+			middleware: func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.WriteHeader(http.StatusUnauthorized)
+				})
+			},
+			wantShortCircuits: 1,
+			wantLabelValues:   []string{"router", "bar@file", "middleware", "foo@file", "type", "BasicAuth", "code", "401"},
+		},
+		{
+			desc: "failing",
+			middleware: func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.WriteHeader(http.StatusBadGateway)
+				})
+			},
+			wantShortCircuits: 1,
+			wantErrors:        1,
+			wantLabelValues:   []string{"router", "bar@file", "middleware", "foo@file", "type", "BasicAuth", "code", "502"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry := middlewareRegistry{
+				Registry:             traefikmetrics.NewVoidRegistry(),
+				reqsCounter:          &CollectingCounter{},
+				shortCircuitsCounter: &CollectingCounter{},
+				errorsCounter:        &CollectingCounter{},
+			}
+
+			constructor := WrapMiddlewareHandler(context.Background(), registry, "bar@file", "foo@file", "BasicAuth", func(next http.Handler) (http.Handler, error) {
+				return test.middleware(next), nil
+			})
+
+			handler, err := constructor(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusTeapot)
+			}))
+			require.NoError(t, err)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, float64(1), registry.reqsCounter.CounterValue)
+			assert.Equal(t, []string{"router", "bar@file", "middleware", "foo@file", "type", "BasicAuth"}, registry.reqsCounter.LastLabelValues)
+			assert.Equal(t, test.wantShortCircuits, registry.shortCircuitsCounter.CounterValue)
+			assert.Equal(t, test.wantErrors, registry.errorsCounter.CounterValue)
+			if test.wantShortCircuits > 0 {
+				assert.Equal(t, test.wantLabelValues, registry.shortCircuitsCounter.LastLabelValues)
+			}
+		})
+	}
+}
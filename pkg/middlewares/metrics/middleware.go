@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+const nameMiddleware = "metrics-middleware"
+
+// middlewareMetrics measures a middleware instance,
+// whose next handler reports to it, through the request context, when and how long it is called.
+type middlewareMetrics struct {
+	handler              http.Handler
+	reqsCounter          gokitmetrics.Counter
+	reqDurationHistogram metrics.ScalableHistogram
+	shortCircuitsCounter gokitmetrics.Counter
+	errorsCounter        gokitmetrics.Counter
+	baseLabels           []string
+}
+
+// nextCall holds the calls of the next handler during a request.
+type nextCall struct {
+	called   bool
+	duration time.Duration
+}
+
+// WrapMiddlewareHandler wraps the constructor of a middleware to measure its invocations,
+// the time spent in the middleware itself, excluding the next handlers,
+// and the requests it answers without calling the next handler,
+// labelled with the router using it, as the same middleware may cost differently on each router.
+func WrapMiddlewareHandler(ctx context.Context, registry metrics.Registry, routerName, middlewareName, middlewareType string, constructor alice.Constructor) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		log.FromContext(middlewares.GetLoggerCtx(ctx, nameMiddleware, typeName)).Debug("Creating middleware")
+
+		m := &middlewareMetrics{
+			reqsCounter:          registry.MiddlewareReqsCounter(),
+			reqDurationHistogram: registry.MiddlewareReqDurationHistogram(),
+			shortCircuitsCounter: registry.MiddlewareShortCircuitsCounter(),
+			errorsCounter:        registry.MiddlewareErrorsCounter(),
+			baseLabels:           []string{"router", routerName, "middleware", middlewareName, "type", middlewareType},
+		}
+
+		handler, err := constructor(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			call, ok := req.Context().Value(m).(*nextCall)
+			if !ok {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			call.called = true
+			start := time.Now()
+			next.ServeHTTP(rw, req)
+			call.duration += time.Since(start)
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		m.handler = handler
+
+		return m, nil
+	}
+}
+
+func (m *middlewareMetrics) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	call := &nextCall{}
+	recorder := newResponseRecorder(rw)
+	start := time.Now()
+
+	// The middleware is used as the context key, to tell its calls apart from the ones of the other instances.
+	m.handler.ServeHTTP(recorder, req.WithContext(context.WithValue(req.Context(), m, call)))
+
+	duration := time.Since(start) - call.duration
+	if duration < 0 {
+		duration = 0
+	}
+
+	m.reqsCounter.With(m.baseLabels...).Add(1)
+	m.reqDurationHistogram.With(m.baseLabels...).Observe(duration.Seconds())
+
+	if call.called {
+		return
+	}
+
+	code := recorder.getCode()
+
+	var labels []string
+	labels = append(labels, m.baseLabels...)
+	labels = append(labels, "code", strconv.Itoa(code))
+
+	m.shortCircuitsCounter.With(labels...).Add(1)
+	if code >= http.StatusInternalServerError {
+		m.errorsCounter.With(labels...).Add(1)
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// otherHostsPath is the path label of the requests to the hosts missing from the allowlist.
+const otherHostsPath = "other"
+
+type pathRule struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// pathNormalizer computes the path label of the requests according to the path normalization rules.
+type pathNormalizer struct {
+	rules []pathRule
+	hosts map[string]struct{}
+}
+
+func newPathNormalizer(config *dynamic.PathNormalization) (*pathNormalizer, error) {
+	normalizer := &pathNormalizer{}
+
+	for _, rule := range config.Rules {
+		regex, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path normalization regex %q: %w", rule.Regex, err)
+		}
+
+		normalizer.rules = append(normalizer.rules, pathRule{regex: regex, replacement: rule.Replacement})
+	}
+
+	if len(config.Hosts) > 0 {
+		normalizer.hosts = make(map[string]struct{}, len(config.Hosts))
+		for _, host := range config.Hosts {
+			normalizer.hosts[types.CanonicalDomain(host)] = struct{}{}
+		}
+	}
+
+	return normalizer, nil
+}
+
+func (p *pathNormalizer) normalize(req *http.Request) string {
+	if p.hosts != nil {
+		if _, ok := p.hosts[getHost(req)]; !ok {
+			return otherHostsPath
+		}
+	}
+
+	path := getPath(req)
+	for _, rule := range p.rules {
+		if rule.regex.MatchString(path) {
+			return rule.regex.ReplaceAllString(path, rule.replacement)
+		}
+	}
+
+	return path
+}
+
+func getHost(req *http.Request) string {
+	if host := requestdecorator.GetCanonizedHost(req.Context()); host != "" {
+		return host
+	}
+
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		host = req.Host
+	}
+
+	return types.CanonicalDomain(host)
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/containous/alice"
+)
+
+type routerNameKey struct{}
+
+// WrapRouterNameHandler stores the name of the router in the request context,
+// for the router label of the service size metrics.
+func WrapRouterNameHandler(routerName string) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), routerNameKey{}, routerName)))
+		}), nil
+	}
+}
+
+func getRouterName(req *http.Request) string {
+	if routerName, ok := req.Context().Value(routerNameKey{}).(string); ok {
+		return routerName
+	}
+
+	return ""
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptrace"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tcpinfo"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+const nameServiceTCPInfo = "metrics-service-tcpinfo"
+
+// tcpInfoMiddleware records the TCP statistics of the connection used to forward a request,
+// once the response has been forwarded.
+type tcpInfoMiddleware struct {
+	next                  http.Handler
+	rttHistogram          metrics.ScalableHistogram
+	retransmitsCounter    gokitmetrics.Counter
+	deliveryRateHistogram gokitmetrics.Histogram
+	baseLabels            []string
+}
+
+// NewServiceTCPInfoMiddleware creates a new middleware recording the TCP statistics of the connections to the servers of a Service.
+func NewServiceTCPInfoMiddleware(ctx context.Context, next http.Handler, registry metrics.Registry, serviceName string) http.Handler {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, nameServiceTCPInfo, typeName)).Debug("Creating middleware")
+
+	return &tcpInfoMiddleware{
+		next:                  next,
+		rttHistogram:          registry.ServiceTCPRTTHistogram(),
+		retransmitsCounter:    registry.ServiceTCPRetransmitsCounter(),
+		deliveryRateHistogram: registry.ServiceTCPDeliveryRateHistogram(),
+		baseLabels:            []string{"service", serviceName},
+	}
+}
+
+// WrapServiceTCPInfoHandler Wraps the TCP statistics service middleware to alice.Constructor.
+func WrapServiceTCPInfoHandler(ctx context.Context, registry metrics.Registry, serviceName string) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		return NewServiceTCPInfoMiddleware(ctx, next, registry, serviceName), nil
+	}
+}
+
+func (m *tcpInfoMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var conn *tcpinfo.Conn
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if c, ok := tcpinfo.FromConn(info.Conn); ok {
+				conn = c
+			}
+		},
+	}
+
+	m.next.ServeHTTP(rw, req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+	if conn == nil {
+		return
+	}
+
+	info, retransmits, err := conn.Stats()
+	if err != nil {
+		log.FromContext(req.Context()).Debugf("Unable to read the TCP statistics of the connection to %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	m.rttHistogram.With(m.baseLabels...).Observe(info.RTT.Seconds())
+	if retransmits > 0 {
+		m.retransmitsCounter.With(m.baseLabels...).Add(float64(retransmits))
+	}
+	if info.DeliveryRate > 0 {
+		m.deliveryRateHistogram.With(m.baseLabels...).Observe(float64(info.DeliveryRate))
+	}
+}
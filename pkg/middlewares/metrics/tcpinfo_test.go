@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+
+	traefikmetrics "github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/tcpinfo"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectingHistogram is a histogram implementation that enables access to the observed values and LastLabelValues.
+type collectingHistogram struct {
+	Values          []float64
+	LastLabelValues []string
+}
+
+func (h *collectingHistogram) With(labelValues ...string) metrics.Histogram {
+	h.LastLabelValues = labelValues
+	return h
+}
+
+func (h *collectingHistogram) Observe(v float64) {
+	h.Values = append(h.Values, v)
+}
+
+type collectingScalableHistogram struct {
+	*collectingHistogram
+}
+
+func (h collectingScalableHistogram) With(labelValues ...string) traefikmetrics.ScalableHistogram {
+	h.collectingHistogram.With(labelValues...)
+	return h
+}
+
+func (h collectingScalableHistogram) ObserveFromStart(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h collectingScalableHistogram) ObserveFromStartWithExemplar(start time.Time, _ map[string]string) {
+	h.ObserveFromStart(start)
+}
+
+func TestTCPInfoMiddleware(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the TCP statistics are only supported on Linux")
+	}
+
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	backendURL, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	dialer := &net.Dialer{}
+	proxy := httputil.NewSingleHostReverseProxy(backendURL)
+	proxy.Transport = &http.Transport{DialContext: tcpinfo.WrapDialContext(dialer.DialContext)}
+
+	rtt := &collectingHistogram{}
+	deliveryRate := &collectingHistogram{}
+	handler := &tcpInfoMiddleware{
+		next:                  proxy,
+		rttHistogram:          collectingScalableHistogram{rtt},
+		retransmitsCounter:    &CollectingCounter{},
+		deliveryRateHistogram: deliveryRate,
+		baseLabels:            []string{"service", "foo"},
+	}
+
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	assert.Len(t, rtt.Values, 2)
+	assert.Equal(t, []string{"service", "foo"}, rtt.LastLabelValues)
+}
+
+func TestTCPInfoMiddleware_TLSBackend(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the TCP statistics are only supported on Linux")
+	}
+
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	backendURL, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	// The transport wraps the dialed connection into a TLS connection, which the middleware gets.
+	dialer := &net.Dialer{}
+	proxy := httputil.NewSingleHostReverseProxy(backendURL)
+	proxy.Transport = &http.Transport{
+		DialContext:     tcpinfo.WrapDialContext(dialer.DialContext),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	rtt := &collectingHistogram{}
+	handler := &tcpInfoMiddleware{
+		next:                  proxy,
+		rttHistogram:          collectingScalableHistogram{rtt},
+		retransmitsCounter:    &CollectingCounter{},
+		deliveryRateHistogram: &collectingHistogram{},
+		baseLabels:            []string{"service", "foo"},
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Len(t, rtt.Values, 1)
+}
@@ -0,0 +1,148 @@
+package normalization
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// Actions applied to the ambiguous requests.
+const (
+	ActionReject    = "reject"
+	ActionNormalize = "normalize"
+	ActionLog       = "log"
+)
+
+// Normalization is an HTTP handler wrapper handling the ambiguous request targets and hosts of an entry point,
+// which the services may interpret differently than the routers.
+// The requests are either rejected, normalized before reaching the routers, or only logged.
+type Normalization struct {
+	absoluteForm string
+	hostMismatch string
+	userInfo     string
+	// mixedScriptHost is the action applied to the requests whose host is a possible lookalike,
+	// which cannot be normalized.
+	mixedScriptHost string
+	logger          log.Logger
+	next            http.Handler
+}
+
+// New creates a new Normalization.
+func New(ctx context.Context, config *static.Normalization, next http.Handler) (*Normalization, error) {
+	for _, action := range []string{config.AbsoluteForm, config.HostMismatch, config.UserInfo} {
+		switch action {
+		case "", ActionReject, ActionNormalize, ActionLog:
+		default:
+			return nil, fmt.Errorf("unknown normalization action %q", action)
+		}
+	}
+
+	switch config.MixedScriptHost {
+	case "", ActionReject, ActionLog:
+	default:
+		return nil, fmt.Errorf("unknown normalization action %q of the mixed-script hosts", config.MixedScriptHost)
+	}
+
+	return &Normalization{
+		absoluteForm:    config.AbsoluteForm,
+		hostMismatch:    config.HostMismatch,
+		userInfo:        config.UserInfo,
+		mixedScriptHost: config.MixedScriptHost,
+		logger:          log.FromContext(ctx),
+		next:            next,
+	}, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (n *Normalization) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The target of the CONNECT requests is an authority.
+	if req.Method == http.MethodConnect {
+		n.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if req.URL.User != nil || strings.Contains(req.Host, "@") {
+		if !n.handle(rw, req, n.userInfo, "userinfo", stripUserInfo) {
+			return
+		}
+	}
+
+	if req.URL.Scheme != "" || req.URL.Host != "" {
+		if !n.handle(rw, req, n.absoluteForm, "absolute-form target", toOriginForm) {
+			return
+		}
+	}
+
+	// The HTTP/1 server drops the Host header of the requests with an absolute-form target,
+	// whereas the HTTP/2 server keeps it next to the authority.
+	if host := req.Header.Get("Host"); host != "" && !strings.EqualFold(host, req.Host) {
+		if !n.handle(rw, req, n.hostMismatch, "Host header mismatch", dropHostHeader) {
+			return
+		}
+	}
+
+	if n.mixedScriptHost != "" && types.IsMixedScriptDomain(requestHost(req)) {
+		if !n.handle(rw, req, n.mixedScriptHost, "mixed-script host", nil) {
+			return
+		}
+	}
+
+	n.next.ServeHTTP(rw, req)
+}
+
+// handle applies the action to the ambiguous request, and tells whether the request can be served.
+func (n *Normalization) handle(rw http.ResponseWriter, req *http.Request, action, problem string, normalize func(*http.Request)) bool {
+	switch action {
+	case ActionReject:
+		n.logger.Debugf("Rejecting the request with %s from %s: %s %s", problem, req.RemoteAddr, req.Method, req.RequestURI)
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return false
+	case ActionNormalize:
+		normalize(req)
+	case ActionLog:
+		n.logger.Infof("Request with %s from %s: %s %s (Host: %s)", problem, req.RemoteAddr, req.Method, req.RequestURI, req.Host)
+	}
+
+	return true
+}
+
+// stripUserInfo removes the userinfo of the target and of the host.
+func stripUserInfo(req *http.Request) {
+	if i := strings.LastIndex(req.Host, "@"); i >= 0 {
+		req.Host = req.Host[i+1:]
+	}
+
+	if req.URL.User != nil {
+		req.URL.User = nil
+		req.RequestURI = req.URL.String()
+	}
+}
+
+// toOriginForm turns the absolute-form target in the origin-form, the host being already taken from the target.
+func toOriginForm(req *http.Request) {
+	req.URL.Scheme = ""
+	req.URL.Host = ""
+	req.URL.User = nil
+	req.RequestURI = req.URL.RequestURI()
+}
+
+// dropHostHeader removes the Host header, so that the authority of the request prevails everywhere.
+func dropHostHeader(req *http.Request) {
+	req.Header.Del("Host")
+}
+
+// requestHost returns the host of the request, without its port.
+func requestHost(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		return req.Host
+	}
+
+	return host
+}
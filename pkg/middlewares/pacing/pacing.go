@@ -0,0 +1,155 @@
+package pacing
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+)
+
+const (
+	typeName = "Pacing"
+)
+
+// maxChunkSize is the size of the largest write sent to the client without pausing.
+const maxChunkSize = 32 * 1024
+
+// pacing limits the bandwidth of the responses of a router.
+type pacing struct {
+	next  http.Handler
+	rate  int64
+	burst int64
+}
+
+// New creates a handler sending the body of each response at the rate, in bytes/s, once its burst is sent.
+func New(ctx context.Context, next http.Handler, config dynamic.RouterPacing, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.Rate <= 0 {
+		return nil, fmt.Errorf("the pacing rate of the router %s must be positive", name)
+	}
+
+	if config.Burst < 0 {
+		return nil, fmt.Errorf("the pacing burst of the router %s is negative", name)
+	}
+
+	return &pacing{
+		next:  next,
+		rate:  config.Rate,
+		burst: config.Burst,
+	}, nil
+}
+
+func (p *pacing) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.next.ServeHTTP(newPacedWriter(req.Context(), rw, p.rate, p.burst), req)
+}
+
+// pacedWriter pauses the writes of a response body exceeding its burst,
+// so that the body is sent at the rate from the first write.
+type pacedWriter struct {
+	http.ResponseWriter
+
+	ctx       context.Context
+	rate      int64
+	burst     int64
+	chunkSize int
+
+	start time.Time
+	sent  int64
+}
+
+func newPacedWriter(ctx context.Context, rw http.ResponseWriter, rate, burst int64) *pacedWriter {
+	// The writes are split in chunks of a tenth of a second, for the pauses to stay short.
+	chunkSize := rate / 10
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	if chunkSize > maxChunkSize {
+		chunkSize = maxChunkSize
+	}
+
+	return &pacedWriter{
+		ResponseWriter: rw,
+		ctx:            ctx,
+		rate:           rate,
+		burst:          burst,
+		chunkSize:      int(chunkSize),
+	}
+}
+
+func (w *pacedWriter) Write(b []byte) (int, error) {
+	if w.start.IsZero() {
+		w.start = time.Now()
+	}
+
+	var written int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > w.chunkSize {
+			chunk = chunk[:w.chunkSize]
+		}
+
+		if err := w.wait(int64(len(chunk))); err != nil {
+			return written, err
+		}
+
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		w.sent += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		b = b[len(chunk):]
+	}
+
+	return written, nil
+}
+
+// wait pauses until the next bytes can be sent, or the request is canceled.
+func (w *pacedWriter) wait(n int64) error {
+	excess := w.sent + n - w.burst
+	if excess <= 0 {
+		return nil
+	}
+
+	delay := time.Until(w.start.Add(time.Duration(float64(excess) / float64(w.rate) * float64(time.Second))))
+	if delay <= 0 {
+		return nil
+	}
+
+	// The bytes written so far are sent before pausing, instead of being buffered.
+	w.Flush()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+// Hijack hijacks the connection.
+func (w *pacedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, fmt.Errorf("not a hijacker: %T", w.ResponseWriter)
+}
+
+// Flush sends any buffered data to the client.
+func (w *pacedWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
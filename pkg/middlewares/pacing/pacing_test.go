@@ -0,0 +1,120 @@
+package pacing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacing(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.RouterPacing
+		size        int
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{
+			desc:        "body within the burst",
+			config:      dynamic.RouterPacing{Rate: 1000, Burst: 2000},
+			size:        2000,
+			expectedMax: 100 * time.Millisecond,
+		},
+		{
+			desc:        "body exceeding the burst",
+			config:      dynamic.RouterPacing{Rate: 1000, Burst: 500},
+			size:        1000,
+			expectedMin: 500 * time.Millisecond,
+			expectedMax: 700 * time.Millisecond,
+		},
+		{
+			desc:        "no burst",
+			config:      dynamic.RouterPacing{Rate: 2000},
+			size:        1000,
+			expectedMin: 500 * time.Millisecond,
+			expectedMax: 700 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			body := bytes.Repeat([]byte("a"), test.size)
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				// The body is written in several writes, as by the reverse proxy.
+				_, _ = rw.Write(body[:test.size/2])
+				_, _ = rw.Write(body[test.size/2:])
+			})
+
+			handler, err := New(context.Background(), next, test.config, "foo")
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			start := time.Now()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+			elapsed := time.Since(start)
+
+			assert.Equal(t, body, rw.Body.Bytes())
+			assert.GreaterOrEqual(t, int64(elapsed), int64(test.expectedMin))
+			assert.Less(t, int64(elapsed), int64(test.expectedMax))
+		})
+	}
+}
+
+func TestPacing_canceled(t *testing.T) {
+	var written int
+	var writeErr error
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		written, writeErr = rw.Write(make([]byte, 1000))
+	})
+
+	handler, err := New(context.Background(), next, dynamic.RouterPacing{Rate: 100}, "foo")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, context.DeadlineExceeded, writeErr)
+	assert.Less(t, written, 1000)
+}
+
+func TestNew_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.RouterPacing
+	}{
+		{
+			desc: "no rate",
+		},
+		{
+			desc:   "negative rate",
+			config: dynamic.RouterPacing{Rate: -1},
+		},
+		{
+			desc:   "negative burst",
+			config: dynamic.RouterPacing{Rate: 1000, Burst: -1},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "foo")
+			assert.Error(t, err)
+		})
+	}
+}
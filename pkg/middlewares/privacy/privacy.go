@@ -0,0 +1,52 @@
+package privacy
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/ip"
+)
+
+const (
+	xForwardedFor = "X-Forwarded-For"
+	xRealIP       = "X-Real-Ip"
+)
+
+// Privacy is an HTTP handler wrapper that anonymizes the client IPs of the requests of an entry point,
+// so the access logs, the metrics, the middlewares and the forwarded headers all get the anonymized IPs.
+// It replaces the IP of the remote address, and the IPs of the X-Forwarded-For and X-Real-Ip headers.
+type Privacy struct {
+	anonymizer *ip.Anonymizer
+	next       http.Handler
+}
+
+// New creates a new Privacy.
+func New(anonymizer *ip.Anonymizer, next http.Handler) *Privacy {
+	return &Privacy{
+		anonymizer: anonymizer,
+		next:       next,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Privacy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if host, port, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		req.RemoteAddr = net.JoinHostPort(p.anonymizer.Anonymize(host), port)
+	} else {
+		req.RemoteAddr = p.anonymizer.Anonymize(req.RemoteAddr)
+	}
+
+	if values := req.Header.Values(xForwardedFor); len(values) > 0 {
+		anonymized := make([]string, 0, len(values))
+		for _, value := range values {
+			anonymized = append(anonymized, p.anonymizer.AnonymizeList(value))
+		}
+		req.Header[xForwardedFor] = anonymized
+	}
+
+	if value := req.Header.Get(xRealIP); value != "" {
+		req.Header.Set(xRealIP, p.anonymizer.Anonymize(value))
+	}
+
+	p.next.ServeHTTP(rw, req)
+}
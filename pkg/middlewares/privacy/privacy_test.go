@@ -0,0 +1,34 @@
+package privacy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivacy(t *testing.T) {
+	anonymizer, err := ip.NewAnonymizer(ip.AnonymizationTruncate, "", 0)
+	require.NoError(t, err)
+
+	var got *http.Request
+	handler := New(anonymizer, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	req.Header.Add(xForwardedFor, "192.168.1.10, 2001:db8:1:2::10")
+	req.Header.Add(xForwardedFor, "172.16.5.6")
+	req.Header.Set(xRealIP, "192.168.1.10")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, got)
+	assert.Equal(t, "10.1.2.0:1234", got.RemoteAddr)
+	assert.Equal(t, []string{"192.168.1.0, 2001:db8:1:2::", "172.16.5.0"}, got.Header.Values(xForwardedFor))
+	assert.Equal(t, "192.168.1.0", got.Header.Get(xRealIP))
+}
@@ -0,0 +1,83 @@
+package qos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrNotSupported is returned when the connections cannot be marked on the platform.
+var ErrNotSupported = errors.New("the DSCP marking is not supported on this platform")
+
+// dscpClasses are the DSCP values of the standard classes (RFC 2474, RFC 2597, RFC 3246, RFC 5865 and RFC 8622).
+var dscpClasses = map[string]int{
+	"CS0": 0, "CS1": 8, "CS2": 16, "CS3": 24, "CS4": 32, "CS5": 40, "CS6": 48, "CS7": 56,
+	"AF11": 10, "AF12": 12, "AF13": 14,
+	"AF21": 18, "AF22": 20, "AF23": 22,
+	"AF31": 26, "AF32": 28, "AF33": 30,
+	"AF41": 34, "AF42": 36, "AF43": 38,
+	"EF": 46, "VA": 44, "LE": 1,
+}
+
+// ParseDSCP returns the DSCP value of a class name (e.g. EF or AF41), or of a number between 0 and 63.
+func ParseDSCP(value string) (int, error) {
+	if dscp, ok := dscpClasses[strings.ToUpper(value)]; ok {
+		return dscp, nil
+	}
+
+	dscp, err := strconv.Atoi(value)
+	if err != nil || dscp < 0 || dscp > 63 {
+		return 0, fmt.Errorf("invalid DSCP %q: neither a class name nor a number between 0 and 63", value)
+	}
+
+	return dscp, nil
+}
+
+// SetDSCP marks the packets sent on the connection with the DSCP value.
+func SetDSCP(conn *net.TCPConn, dscp int) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	network := "tcp4"
+	if addr, ok := conn.LocalAddr().(*net.TCPAddr); ok && addr.IP.To4() == nil {
+		network = "tcp6"
+	}
+
+	return setTOS(rawConn, network, dscp<<2)
+}
+
+// Control returns the control function of the dialers marking their sockets with the DSCP value,
+// so all the packets of their connections are marked.
+// The sockets are left unmarked on the platforms not supporting the marking.
+func Control(dscp int) func(network, address string, c syscall.RawConn) error {
+	return func(network, _ string, c syscall.RawConn) error {
+		if err := setTOS(c, network, dscp<<2); err != nil && !errors.Is(err, ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+}
+
+type dscpKey struct{}
+
+// WithDSCP returns a copy of the context holding the DSCP value of the request,
+// unless the context already holds one.
+func WithDSCP(ctx context.Context, dscp int) context.Context {
+	if _, ok := FromContext(ctx); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, dscpKey{}, dscp)
+}
+
+// FromContext returns the DSCP value of the request, if any.
+func FromContext(ctx context.Context) (int, bool) {
+	dscp, ok := ctx.Value(dscpKey{}).(int)
+	return dscp, ok
+}
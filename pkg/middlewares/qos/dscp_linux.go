@@ -0,0 +1,25 @@
+package qos
+
+import (
+	"syscall"
+)
+
+func setTOS(rawConn syscall.RawConn, network string, tos int) error {
+	var sockErr error
+	err := rawConn.Control(func(fd uintptr) {
+		if network != "tcp6" {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, tos)
+			if sockErr == nil {
+				return
+			}
+		}
+
+		// The IPv6 sockets, and the dual-stack ones carrying IPv4 traffic, are marked with the traffic class.
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS, tos)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sockErr
+}
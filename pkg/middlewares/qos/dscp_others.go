@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package qos
+
+import "syscall"
+
+func setTOS(_ syscall.RawConn, _ string, _ int) error {
+	return ErrNotSupported
+}
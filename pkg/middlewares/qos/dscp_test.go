@@ -0,0 +1,43 @@
+package qos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDSCP(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected int
+		expErr   bool
+	}{
+		{desc: "expedited forwarding", value: "EF", expected: 46},
+		{desc: "assured forwarding", value: "af41", expected: 34},
+		{desc: "class selector", value: "CS1", expected: 8},
+		{desc: "number", value: "26", expected: 26},
+		{desc: "zero", value: "0", expected: 0},
+		{desc: "number too large", value: "64", expErr: true},
+		{desc: "negative number", value: "-1", expErr: true},
+		{desc: "unknown class", value: "AF51", expErr: true},
+		{desc: "empty", value: "", expErr: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dscp, err := ParseDSCP(test.value)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, dscp)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package qos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "QoS"
+)
+
+// qos classifies the requests with a DSCP value.
+// The requests of a class are sent to the servers on connections dedicated to the class, marked with its DSCP value,
+// so a connection is never shared by the requests of different classes.
+type qos struct {
+	next http.Handler
+	dscp int
+	name string
+}
+
+// New creates a QoS middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.QoS, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	dscp, err := ParseDSCP(config.DSCP)
+	if err != nil {
+		return nil, fmt.Errorf("error creating QoS middleware: %w", err)
+	}
+
+	return &qos{
+		next: next,
+		dscp: dscp,
+		name: name,
+	}, nil
+}
+
+func (q *qos) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return q.name, tracing.SpanKindNoneEnum
+}
+
+func (q *qos) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The first classification of the request wins, so the middlewares win over the option of the load-balancer.
+	q.next.ServeHTTP(rw, req.WithContext(WithDSCP(req.Context(), q.dscp)))
+}
@@ -0,0 +1,57 @@
+package qos
+
+import (
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControl(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	dialer := &net.Dialer{Control: Control(46)}
+	conn, err := dialer.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	assert.Equal(t, 46<<2, getTOS(t, conn))
+}
+
+func TestSetDSCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	assert.Equal(t, 0, getTOS(t, conn))
+
+	err = SetDSCP(conn.(*net.TCPConn), 34)
+	require.NoError(t, err)
+
+	assert.Equal(t, 34<<2, getTOS(t, conn))
+}
+
+func getTOS(t *testing.T, conn net.Conn) int {
+	t.Helper()
+
+	rawConn, err := conn.(*net.TCPConn).SyscallConn()
+	require.NoError(t, err)
+
+	var tos int
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		tos, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS)
+	})
+	require.NoError(t, err)
+	require.NoError(t, sockErr)
+
+	return tos
+}
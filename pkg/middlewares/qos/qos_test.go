@@ -0,0 +1,37 @@
+package qos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQoS(t *testing.T) {
+	var dscp int
+	var classified bool
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		dscp, classified = FromContext(req.Context())
+	})
+
+	// The load-balancer option, then the middleware of the route, which classifies the request first.
+	service, err := New(context.Background(), next, dynamic.QoS{DSCP: "AF41"}, "service")
+	require.NoError(t, err)
+
+	route, err := New(context.Background(), service, dynamic.QoS{DSCP: "EF"}, "route")
+	require.NoError(t, err)
+
+	route.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.True(t, classified)
+	assert.Equal(t, 46, dscp)
+}
+
+func TestQoS_invalidDSCP(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.QoS{DSCP: "foo"}, "route")
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package ratelimiter
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/cluster"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// maxSharedSources is the maximum number of sources whose counts are shared at each synchronization.
+const maxSharedSources = 4096
+
+// idleIntervals is the number of gossip intervals after which the count of a source without requests is no longer shared.
+const idleIntervals = 60
+
+type sharedCount struct {
+	total   int64
+	pending int64
+	updated time.Time
+}
+
+// publishedCounts are the counts published by an instance.
+type publishedCounts struct {
+	// Totals are the requests counted since the creation of the middleware, keyed by source.
+	Totals map[string]int64 `json:"totals"`
+	// Last are the requests counted since the previous publication, keyed by source.
+	Last map[string]int64 `json:"last,omitempty"`
+}
+
+// sharedCounts shares the requests counted by the rate limiter with the other instances of the cluster,
+// and consumes the tokens of the requests they counted, so the rate is limited cluster-wide.
+// The counts are published and consumed at most once per gossip interval,
+// so the limit is approximate: it can be exceeded during a gossip interval.
+//
+// The published counts are the totals since the creation of the middleware,
+// so the requests counted in a version which was replaced before reaching an instance are consumed with the next one.
+// The requests counted since the previous publication are published too,
+// for the instances which did not see the previous totals yet.
+type sharedCounts struct {
+	node   *cluster.Node
+	prefix string
+
+	mu        sync.Mutex
+	counts    map[string]*sharedCount
+	scheduled bool
+	lastSync  time.Time
+	// consumed are the last totals consumed, keyed by origin, then by source.
+	consumed map[string]map[string]int64
+}
+
+func newSharedCounts(node *cluster.Node, name string) *sharedCounts {
+	return &sharedCounts{
+		node:     node,
+		prefix:   "ratelimit/" + name + "/",
+		counts:   make(map[string]*sharedCount),
+		consumed: make(map[string]map[string]int64),
+	}
+}
+
+// add counts a request allowed for the source.
+// The counts are published at the end of the gossip interval.
+func (s *sharedCounts) add(source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, ok := s.counts[source]
+	if !ok {
+		if len(s.counts) >= maxSharedSources {
+			return
+		}
+		count = &sharedCount{}
+		s.counts[source] = count
+	}
+	count.total++
+	count.pending++
+	count.updated = time.Now()
+
+	if !s.scheduled {
+		s.scheduled = true
+		time.AfterFunc(s.node.Interval(), s.publish)
+	}
+}
+
+// publish publishes the totals of the sources which recently had requests.
+func (s *sharedCounts) publish() {
+	s.mu.Lock()
+	s.scheduled = false
+
+	idle := time.Now().Add(-idleIntervals * s.node.Interval())
+	counts := publishedCounts{
+		Totals: make(map[string]int64, len(s.counts)),
+		Last:   make(map[string]int64),
+	}
+	for source, count := range s.counts {
+		if count.updated.Before(idle) {
+			delete(s.counts, source)
+			continue
+		}
+
+		counts.Totals[source] = count.total
+		if count.pending > 0 {
+			counts.Last[source] = count.pending
+			count.pending = 0
+		}
+	}
+	s.mu.Unlock()
+
+	value, err := json.Marshal(counts)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to encode the rate limiter counts: %v", err)
+		return
+	}
+
+	s.node.Set(s.prefix+s.node.Name(), value)
+}
+
+// sync calls consume with the requests counted by the other instances since the last synchronization.
+// Only the requests of the last publication are consumed for an instance seen for the first time,
+// its totals are then used as a reference for the next ones.
+func (s *sharedCounts) sync(now time.Time, consume func(source string, count int64)) {
+	s.mu.Lock()
+	if now.Sub(s.lastSync) < s.node.Interval() {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSync = now
+	s.mu.Unlock()
+
+	origins := make(map[string]struct{})
+	for _, entry := range s.node.Entries(s.prefix) {
+		if entry.Origin == s.node.Name() {
+			continue
+		}
+		origins[entry.Origin] = struct{}{}
+
+		var counts publishedCounts
+		if err := json.Unmarshal(entry.Value, &counts); err != nil {
+			log.WithoutContext().Debugf("Invalid rate limiter counts from %s: %v", entry.Origin, err)
+			continue
+		}
+
+		s.mu.Lock()
+		consumed, known := s.consumed[entry.Origin]
+		s.consumed[entry.Origin] = counts.Totals
+		s.mu.Unlock()
+
+		if !known {
+			for source, count := range counts.Last {
+				consume(source, count)
+			}
+			continue
+		}
+
+		for source, total := range counts.Totals {
+			count := total - consumed[source]
+			if count < 0 {
+				// The counts were reset, by a restart or a configuration reload of the instance.
+				count = total
+			}
+			if count > 0 {
+				consume(source, count)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	for origin := range s.consumed {
+		if _, ok := origins[origin]; !ok {
+			delete(s.consumed, origin)
+		}
+	}
+	s.mu.Unlock()
+}
@@ -0,0 +1,109 @@
+package ratelimiter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	resultAllowed  = "allowed"
+	resultRejected = "rejected"
+)
+
+// seriesRetention is the minimum time the series of a source are kept after its last request,
+// and the interval at which the series of the expired buckets are looked for.
+const seriesRetention = time.Minute
+
+// deleter is implemented by the metrics whose series can be deleted,
+// which they are not when the source label is dropped, the series gathering all the sources.
+type deleter interface {
+	Delete()
+}
+
+// sourceMetrics records the requests allowed and rejected, and the tokens left in the buckets, by source.
+// The series of a source are deleted once its bucket expired, i.e. once it is full again,
+// as the bucket of a source without requests is then the same as a new one.
+type sourceMetrics struct {
+	name            string
+	rate            float64
+	burst           float64
+	requestsCounter metrics.Counter
+	tokensGauge     metrics.Gauge
+
+	mu        sync.Mutex
+	expiries  map[string]time.Time
+	nextSweep time.Time
+}
+
+func newSourceMetrics(name string, rate float64, burst int64, requestsCounter metrics.Counter, tokensGauge metrics.Gauge) *sourceMetrics {
+	if requestsCounter == nil && tokensGauge == nil {
+		return nil
+	}
+
+	return &sourceMetrics{
+		name:            name,
+		rate:            rate,
+		burst:           float64(burst),
+		requestsCounter: requestsCounter,
+		tokensGauge:     tokensGauge,
+		expiries:        make(map[string]time.Time),
+	}
+}
+
+// record records the result of a request, and the tokens left in the bucket of its source.
+func (m *sourceMetrics) record(now time.Time, source, result string, tokens float64) {
+	if m == nil {
+		return
+	}
+
+	// The series are recorded and deleted under the lock, for a source recorded again not to be deleted meanwhile.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.requestsCounter != nil {
+		m.requestsCounter.With("middleware", m.name, "source", source, "result", result).Add(1)
+	}
+	if m.tokensGauge != nil {
+		m.tokensGauge.With("middleware", m.name, "source", source).Set(tokens)
+	}
+
+	expiry := now.Add(seriesRetention)
+	if m.rate > 0 {
+		if full := now.Add(time.Duration((m.burst - tokens) / m.rate * float64(time.Second))); full.After(expiry) {
+			expiry = full
+		}
+	}
+
+	m.expiries[source] = expiry
+
+	if now.Before(m.nextSweep) {
+		return
+	}
+	m.nextSweep = now.Add(seriesRetention)
+
+	for source, expiry := range m.expiries {
+		if now.After(expiry) {
+			m.delete(source)
+			delete(m.expiries, source)
+		}
+	}
+}
+
+// delete deletes the series of the source.
+func (m *sourceMetrics) delete(source string) {
+	if m.requestsCounter != nil {
+		for _, result := range []string{resultAllowed, resultRejected} {
+			if series, ok := m.requestsCounter.With("middleware", m.name, "source", source, "result", result).(deleter); ok {
+				series.Delete()
+			}
+		}
+	}
+
+	if m.tokensGauge != nil {
+		if series, ok := m.tokensGauge.With("middleware", m.name, "source", source).(deleter); ok {
+			series.Delete()
+		}
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/go-kit/kit/metrics"
 	"github.com/mailgun/ttlmap"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/utils"
@@ -35,22 +38,33 @@ type rateLimiter struct {
 	next          http.Handler
 
 	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+	shared  *sharedCounts
+	redis   *redisBuckets // buckets shared through Redis, used instead of the actual buckets while it is available.
+
+	metrics *sourceMetrics
 }
 
 // New returns a rate limiter middleware.
-func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name string) (http.Handler, error) {
+// The requests allowed and rejected, and the tokens left in the buckets, are recorded in the given metrics, if any.
+func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, ipSets ip.Sets, requestsCounter metrics.Counter, tokensGauge metrics.Gauge, name string) (http.Handler, error) {
+	return NewWithCluster(ctx, next, config, ipSets, requestsCounter, tokensGauge, name, nil)
+}
+
+// NewWithCluster returns a rate limiter middleware, limiting the rate across the instances of the cluster.
+func NewWithCluster(ctx context.Context, next http.Handler, config dynamic.RateLimit, ipSets ip.Sets, requestsCounter metrics.Counter, tokensGauge metrics.Gauge, name string, node *cluster.Node) (http.Handler, error) {
 	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
 	log.FromContext(ctxLog).Debug("Creating middleware")
 
 	if config.SourceCriterion == nil ||
 		config.SourceCriterion.IPStrategy == nil &&
-			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost &&
+			config.SourceCriterion.RequestQueryParameterName == "" && config.SourceCriterion.RequestCookieName == "" {
 		config.SourceCriterion = &dynamic.SourceCriterion{
 			IPStrategy: &dynamic.IPStrategy{},
 		}
 	}
 
-	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion, ipSets)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +100,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		}
 	}
 
-	return &rateLimiter{
+	rl := &rateLimiter{
 		name:          name,
 		rate:          rate.Limit(rtl),
 		burst:         burst,
@@ -94,7 +108,22 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		next:          next,
 		sourceMatcher: sourceMatcher,
 		buckets:       buckets,
-	}, nil
+		metrics:       newSourceMetrics(name, rtl, burst, requestsCounter, tokensGauge),
+	}
+
+	if node != nil {
+		rl.shared = newSharedCounts(node, name)
+	}
+
+	// Without rate limiting, there is nothing to share.
+	if config.Redis != nil && rtl > 0 {
+		rl.redis, err = newRedisBuckets(config.Redis, name, rtl, burst, maxDelay)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rl, nil
 }
 
 func (rl *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -116,35 +145,92 @@ func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logger.Infof("ignoring token bucket amount > 1: %d", amount)
 	}
 
-	var bucket *rate.Limiter
-	if rlSource, exists := rl.buckets.Get(source); exists {
-		bucket = rlSource.(*rate.Limiter)
-	} else {
-		bucket = rate.NewLimiter(rl.rate, int(rl.burst))
-		if err := rl.buckets.Set(source, bucket, int(rl.maxDelay)*10+1); err != nil {
-			logger.Errorf("could not insert bucket: %v", err)
-			http.Error(w, "could not insert bucket", http.StatusInternalServerError)
+	if rl.redis != nil && rl.redis.available(time.Now()) {
+		now := time.Now()
+		taken, tokens, delay, err := rl.redis.take(source, now)
+		if err == nil {
+			if !taken {
+				rl.metrics.record(now, source, resultRejected, tokens)
+				rl.serveDelayError(ctx, w, r, delay)
+				return
+			}
+
+			rl.metrics.record(now, source, resultAllowed, tokens)
+
+			time.Sleep(delay)
+			rl.next.ServeHTTP(w, r)
 			return
 		}
+
+		logger.Warnf("Unable to use the token buckets stored in Redis, using the local ones for %s: %v", redisRetryInterval, err)
+	}
+
+	if rl.shared != nil {
+		rl.shared.sync(time.Now(), rl.consume)
+	}
+
+	bucket, err := rl.bucket(source)
+	if err != nil {
+		logger.Errorf("could not insert bucket: %v", err)
+		http.Error(w, "could not insert bucket", http.StatusInternalServerError)
+		return
 	}
 
-	res := bucket.Reserve()
+	now := time.Now()
+	res := bucket.ReserveN(now, 1)
 	if !res.OK() {
+		rl.metrics.record(now, source, resultRejected, bucket.TokensAt(now))
 		http.Error(w, "No bursty traffic allowed", http.StatusTooManyRequests)
 		return
 	}
 
-	delay := res.Delay()
+	delay := res.DelayFrom(now)
 	if delay > rl.maxDelay {
-		res.Cancel()
+		res.CancelAt(now)
+		rl.metrics.record(now, source, resultRejected, bucket.TokensAt(now))
 		rl.serveDelayError(ctx, w, r, delay)
 		return
 	}
 
+	rl.metrics.record(now, source, resultAllowed, bucket.TokensAt(now))
+
+	if rl.shared != nil {
+		rl.shared.add(source)
+	}
+
 	time.Sleep(delay)
 	rl.next.ServeHTTP(w, r)
 }
 
+func (rl *rateLimiter) bucket(source string) (*rate.Limiter, error) {
+	if rlSource, exists := rl.buckets.Get(source); exists {
+		return rlSource.(*rate.Limiter), nil
+	}
+
+	bucket := rate.NewLimiter(rl.rate, int(rl.burst))
+	if err := rl.buckets.Set(source, bucket, int(rl.maxDelay)*10+1); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
+
+// consume consumes the tokens of the requests counted by another instance of the cluster.
+func (rl *rateLimiter) consume(source string, count int64) {
+	bucket, err := rl.bucket(source)
+	if err != nil {
+		log.WithoutContext().Debugf("Unable to consume the tokens counted by the cluster: %v", err)
+		return
+	}
+
+	if count > rl.burst {
+		count = rl.burst
+	}
+
+	// The reservation is kept, so the tokens are consumed even when they are not available yet.
+	bucket.ReserveN(time.Now(), int(count))
+}
+
 func (rl *rateLimiter) serveDelayError(ctx context.Context, w http.ResponseWriter, r *http.Request, delay time.Duration) {
 	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", delay.Seconds()))
 	w.Header().Set("X-Retry-In", delay.String())
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/traefik/paerser/types"
@@ -82,7 +86,7 @@ func TestNewRateLimiter(t *testing.T) {
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 
-			h, err := New(context.Background(), next, test.config, "rate-limiter")
+			h, err := New(context.Background(), next, test.config, nil, nil, nil, "rate-limiter")
 			if test.expectedError != "" {
 				assert.EqualError(t, err, test.expectedError)
 			} else {
@@ -244,7 +248,7 @@ func TestRateLimit(t *testing.T) {
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				reqCount++
 			})
-			h, err := New(context.Background(), next, test.config, "rate-limiter")
+			h, err := New(context.Background(), next, test.config, nil, nil, nil, "rate-limiter")
 			require.NoError(t, err)
 
 			loadPeriod := time.Duration(1e9 / test.incomingLoad)
@@ -314,3 +318,123 @@ func TestRateLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestRateLimitCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := safe.NewPool(ctx)
+	t.Cleanup(func() {
+		cancel()
+		pool.Stop()
+	})
+
+	nodeA, err := cluster.New(&cluster.Configuration{Name: "a", Address: "127.0.0.1:0", Secret: "secret", GossipInterval: ptypes.Duration(20 * time.Millisecond)})
+	require.NoError(t, err)
+	nodeA.Start(pool)
+
+	nodeB, err := cluster.New(&cluster.Configuration{Name: "b", Address: "127.0.0.1:0", Peers: []string{nodeA.Address()}, Secret: "secret", GossipInterval: ptypes.Duration(20 * time.Millisecond)})
+	require.NoError(t, err)
+	nodeB.Start(pool)
+
+	config := dynamic.RateLimit{
+		Average: 1,
+		Period:  ptypes.Duration(time.Hour),
+		Burst:   5,
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rateLimiterA, err := NewWithCluster(context.Background(), next, config, nil, nil, nil, "rate-limiter", nodeA)
+	require.NoError(t, err)
+
+	rateLimiterB, err := NewWithCluster(context.Background(), next, config, nil, nil, nil, "rate-limiter", nodeB)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		rateLimiterA.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	// The tokens consumed by the first instance are consumed on the second one once the counts are gossiped.
+	require.Eventually(t, func() bool {
+		entries := nodeB.Entries("ratelimit/rate-limiter/")
+		return len(entries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		rateLimiterB.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+		codes = append(codes, recorder.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestRateLimitMetrics(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+	requests := &requestsCounter{values: make(map[string]float64)}
+	tokens := &testhelpers.CollectingGauge{}
+
+	h, err := New(context.Background(), next, dynamic.RateLimit{Average: 1, Burst: 2}, nil, requests, tokens, "rate-limiter")
+	require.NoError(t, err)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		codes = append(codes, rw.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, map[string]float64{
+		"middleware,rate-limiter,source,10.0.0.1,result,allowed":  2,
+		"middleware,rate-limiter,source,10.0.0.1,result,rejected": 1,
+	}, requests.values)
+	assert.Equal(t, []string{"middleware", "rate-limiter", "source", "10.0.0.1"}, tokens.LastLabelValues)
+	assert.InDelta(t, 0, tokens.GaugeValue, 0.1)
+}
+
+// requestsCounter is a counter recording the total for each label values.
+type requestsCounter struct {
+	values      map[string]float64
+	labelValues []string
+}
+
+func (c *requestsCounter) With(labelValues ...string) metrics.Counter {
+	return &requestsCounter{values: c.values, labelValues: labelValues}
+}
+
+func (c *requestsCounter) Add(delta float64) {
+	c.values[strings.Join(c.labelValues, ",")] += delta
+}
+
+func (c *requestsCounter) Delete() {
+	delete(c.values, strings.Join(c.labelValues, ","))
+}
+
+func TestSourceMetrics_expiry(t *testing.T) {
+	requests := &requestsCounter{values: make(map[string]float64)}
+
+	m := newSourceMetrics("rate-limiter", 10, 5, requests, nil)
+
+	now := time.Now()
+
+	// The bucket of the first source is full again before the retention, and the one of the second source after 100.5s.
+	m.record(now, "10.0.0.1", resultAllowed, 4)
+	m.record(now, "10.0.0.2", resultRejected, -1000)
+
+	m.record(now.Add(90*time.Second), "10.0.0.3", resultAllowed, 4)
+	assert.Equal(t, map[string]float64{
+		"middleware,rate-limiter,source,10.0.0.2,result,rejected": 1,
+		"middleware,rate-limiter,source,10.0.0.3,result,allowed":  1,
+	}, requests.values)
+
+	m.record(now.Add(200*time.Second), "10.0.0.3", resultAllowed, 4)
+	assert.Equal(t, map[string]float64{
+		"middleware,rate-limiter,source,10.0.0.3,result,allowed": 2,
+	}, requests.values)
+}
@@ -0,0 +1,178 @@
+package ratelimiter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/redis"
+	goredis "github.com/go-redis/redis/v7"
+)
+
+// redisRetryInterval is how long the local token buckets are used after Redis failed.
+const redisRetryInterval = 10 * time.Second
+
+// takeScript takes a token from the token bucket of the key, refilled at the rate, in tokens/s, up to the burst,
+// unless the request would be delayed longer than the max delay, in microseconds.
+// It returns whether the token is taken, the tokens left, and the delay in microseconds.
+// The numbers are stored with all their digits, as Lua converts them to strings with 14 significant digits only.
+var takeScript = goredis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local max_delay = tonumber(ARGV[4])
+
+local bucket = redis.call('hmget', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+if now > last then
+  tokens = math.min(burst, tokens + (now - last) * rate / 1000000)
+  last = now
+end
+
+local delay = 0
+if tokens < 1 then
+  delay = math.ceil((1 - tokens) * 1000000 / rate)
+end
+if delay > max_delay then
+  return {0, string.format('%.17g', tokens), delay}
+end
+
+tokens = tokens - 1
+redis.call('hmset', KEYS[1], 'tokens', string.format('%.17g', tokens), 'last', string.format('%.17g', last))
+redis.call('pexpire', KEYS[1], ARGV[5])
+return {1, string.format('%.17g', tokens), delay}
+`)
+
+// redisClients are the Redis clients, by configuration,
+// shared by the middlewares, which are created again on each configuration reload.
+var (
+	redisClientsMu sync.Mutex
+	redisClients   = make(map[string]goredis.UniversalClient)
+)
+
+// redisBuckets are token buckets stored in Redis, and thus shared by the instances using the same Redis.
+type redisBuckets struct {
+	client   goredis.UniversalClient
+	prefix   string
+	rate     float64
+	burst    int64
+	maxDelay time.Duration
+	ttl      time.Duration
+
+	mu               sync.Mutex
+	unavailableUntil time.Time
+}
+
+func newRedisBuckets(config *dynamic.RateLimitRedis, name string, rate float64, burst int64, maxDelay time.Duration) (*redisBuckets, error) {
+	client, err := redisClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// A bucket is kept until it is full again.
+	ttl := time.Duration(float64(burst)/rate*float64(time.Second)) + maxDelay + time.Second
+
+	prefix := strings.TrimSuffix(config.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &redisBuckets{
+		client:   client,
+		prefix:   prefix + name + "/",
+		rate:     rate,
+		burst:    burst,
+		maxDelay: maxDelay,
+		ttl:      ttl,
+	}, nil
+}
+
+// available reports whether Redis is used, i.e. whether it did not fail in the last retry interval.
+func (b *redisBuckets) available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !now.Before(b.unavailableUntil)
+}
+
+// take takes a token from the bucket of the source, unless the request would be delayed longer than the max delay.
+// It returns whether the token is taken, the tokens left, and the delay of the request.
+func (b *redisBuckets) take(source string, now time.Time) (bool, float64, time.Duration, error) {
+	result, err := takeScript.Run(b.client, []string{b.prefix + source},
+		b.rate, b.burst, now.UnixNano()/int64(time.Microsecond), b.maxDelay.Microseconds(), b.ttl.Milliseconds()).Result()
+	if err != nil {
+		b.mu.Lock()
+		b.unavailableUntil = now.Add(redisRetryInterval)
+		b.mu.Unlock()
+
+		return false, 0, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected result %v", result)
+	}
+
+	taken, _ := values[0].(int64)
+	delay, _ := values[2].(int64)
+	rawTokens, _ := values[1].(string)
+
+	tokens, err := strconv.ParseFloat(rawTokens, 64)
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("unexpected tokens %q: %w", rawTokens, err)
+	}
+
+	return taken == 1, tokens, time.Duration(delay) * time.Microsecond, nil
+}
+
+// redisClient returns the client of the Redis described by the configuration, created on first use.
+func redisClient(config *dynamic.RateLimitRedis) (goredis.UniversalClient, error) {
+	key, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	redisClientsMu.Lock()
+	defer redisClientsMu.Unlock()
+
+	if client, ok := redisClients[string(key)]; ok {
+		return client, nil
+	}
+
+	options := redis.Options{
+		Endpoints: config.Endpoints,
+		Username:  config.Username,
+		Password:  config.Password,
+		DB:        config.DB,
+		Cluster:   config.Cluster,
+	}
+
+	if config.Sentinel != nil {
+		options.Sentinel = &redis.Sentinel{
+			MasterName: config.Sentinel.MasterName,
+			Username:   config.Sentinel.Username,
+			Password:   config.Sentinel.Password,
+		}
+	}
+
+	if config.TLS != nil {
+		options.TLS, err = config.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the TLS configuration of Redis: %w", err)
+		}
+	}
+
+	client, err := redis.NewClient(options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the Redis client: %w", err)
+	}
+
+	redisClients[string(key)] = client
+
+	return client, nil
+}
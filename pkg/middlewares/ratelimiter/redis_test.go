@@ -0,0 +1,136 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateLimiter_redis(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         dynamic.RateLimit
+		expectedRedis  bool
+		expectedPrefix string
+		expectedError  bool
+	}{
+		{
+			desc: "redis",
+			config: dynamic.RateLimit{
+				Average: 10,
+				Redis:   &dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:6379"}, Prefix: "traefik/ratelimit"},
+			},
+			expectedRedis:  true,
+			expectedPrefix: "traefik/ratelimit/rate-limiter/",
+		},
+		{
+			desc: "sentinel",
+			config: dynamic.RateLimit{
+				Average: 10,
+				Redis: &dynamic.RateLimitRedis{
+					Endpoints: []string{"127.0.0.1:26379", "127.0.0.1:26380"},
+					Sentinel:  &dynamic.RedisSentinel{MasterName: "master"},
+				},
+			},
+			expectedRedis:  true,
+			expectedPrefix: "rate-limiter/",
+		},
+		{
+			desc: "no rate limiting",
+			config: dynamic.RateLimit{
+				Redis: &dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:6379"}},
+			},
+		},
+		{
+			desc: "multiple endpoints without sentinel or cluster",
+			config: dynamic.RateLimit{
+				Average: 10,
+				Redis:   &dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:6379", "127.0.0.1:6380"}},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "sentinel without master name",
+			config: dynamic.RateLimit{
+				Average: 10,
+				Redis: &dynamic.RateLimitRedis{
+					Endpoints: []string{"127.0.0.1:26379"},
+					Sentinel:  &dynamic.RedisSentinel{},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := New(context.Background(), http.NotFoundHandler(), test.config, nil, nil, nil, "rate-limiter")
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			rl := h.(*rateLimiter)
+			if !test.expectedRedis {
+				assert.Nil(t, rl.redis)
+				return
+			}
+
+			require.NotNil(t, rl.redis)
+			assert.Equal(t, test.expectedPrefix, rl.redis.prefix)
+		})
+	}
+}
+
+func TestRedisClient_shared(t *testing.T) {
+	config := &dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:6379"}, DB: 2}
+
+	client, err := redisClient(config)
+	require.NoError(t, err)
+
+	same, err := redisClient(&dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:6379"}, DB: 2})
+	require.NoError(t, err)
+	assert.Same(t, client, same)
+
+	other, err := redisClient(&dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:6379"}, DB: 3})
+	require.NoError(t, err)
+	assert.NotSame(t, client, other)
+}
+
+func TestRateLimit_redisFallback(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+	// Nothing listens on the port, so the local token buckets are used.
+	h, err := New(context.Background(), next, dynamic.RateLimit{
+		Average: 1,
+		Burst:   1,
+		Redis:   &dynamic.RateLimitRedis{Endpoints: []string{"127.0.0.1:1"}, Prefix: "traefik/ratelimit"},
+	}, nil, nil, nil, "rate-limiter")
+	require.NoError(t, err)
+
+	var codes []int
+	for i := 0; i < 2; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		codes = append(codes, rw.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+
+	rl := h.(*rateLimiter)
+	assert.False(t, rl.redis.available(time.Now()))
+	assert.True(t, rl.redis.available(time.Now().Add(redisRetryInterval)))
+}
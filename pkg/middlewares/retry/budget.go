@@ -0,0 +1,152 @@
+package retry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// budgetBuckets is the number of buckets of the sliding window of the budgets.
+const budgetBuckets = 10
+
+// Budget limits the retries to a percentage of the requests over a sliding window, as the retry budgets of Finagle and Linkerd,
+// so that the retries cannot amplify an outage of the servers.
+// A reserve of retries per second allows the retries when there are few requests.
+type Budget struct {
+	percent    int
+	reserve    float64
+	bucketSize time.Duration
+
+	mu      sync.Mutex
+	buckets [budgetBuckets]budgetBucket
+	now     func() time.Time
+}
+
+type budgetBucket struct {
+	epoch    int64
+	requests int
+	retries  int
+}
+
+// NewBudget creates a Budget.
+func NewBudget(config dynamic.RetryBudget) *Budget {
+	window := time.Duration(config.Window)
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+
+	bucketSize := window / budgetBuckets
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+
+	return &Budget{
+		percent:    config.Percent,
+		reserve:    float64(config.MinRetriesPerSecond) * window.Seconds(),
+		bucketSize: bucketSize,
+		now:        time.Now,
+	}
+}
+
+// Requested records a request.
+func (b *Budget) Requested() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket().requests++
+}
+
+// Retried records a retry.
+func (b *Budget) Retried() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket().retries++
+}
+
+// Allowed tells whether the budget allows another retry.
+func (b *Budget) Allowed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	epoch := b.epoch()
+
+	var requests, retries int
+	for _, bucket := range b.buckets {
+		if bucket.epoch > epoch-budgetBuckets {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	balance := b.reserve + float64(requests*b.percent)/100 - float64(retries)
+	return balance >= 1
+}
+
+func (b *Budget) epoch() int64 {
+	return b.now().UnixNano() / int64(b.bucketSize)
+}
+
+// bucket returns the bucket of the current time, reset when it held an expired period.
+func (b *Budget) bucket() *budgetBucket {
+	epoch := b.epoch()
+
+	bucket := &b.buckets[epoch%budgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = budgetBucket{epoch: epoch}
+	}
+
+	return bucket
+}
+
+type budgetKey struct {
+	middlewareName string
+	serviceName    string
+}
+
+type sharedBudget struct {
+	config dynamic.RetryBudget
+	budget *Budget
+}
+
+// Budgets holds the budgets of the retry middlewares, for each service they target.
+// They are kept across the configuration reloads, as long as the configuration of their middleware does not change.
+type Budgets struct {
+	mu      sync.Mutex
+	budgets map[budgetKey]sharedBudget
+}
+
+// NewBudgets creates Budgets.
+func NewBudgets() *Budgets {
+	return &Budgets{budgets: make(map[budgetKey]sharedBudget)}
+}
+
+// Get returns the budget of the middleware for the service,
+// created with the configuration when it does not exist yet, or when the configuration changed.
+func (b *Budgets) Get(middlewareName, serviceName string, config dynamic.RetryBudget) *Budget {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := budgetKey{middlewareName: middlewareName, serviceName: serviceName}
+
+	shared, ok := b.budgets[key]
+	if !ok || shared.config != config {
+		shared = sharedBudget{config: config, budget: NewBudget(config)}
+		b.budgets[key] = shared
+	}
+
+	return shared.budget
+}
+
+// Prune removes the budgets of the middlewares which are no longer defined.
+func (b *Budgets) Prune(defined func(middlewareName string) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key := range b.budgets {
+		if !defined(key.middlewareName) {
+			delete(b.budgets, key)
+		}
+	}
+}
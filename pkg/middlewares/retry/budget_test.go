@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestBudget(t *testing.T) {
+	now := time.Now()
+
+	budget := NewBudget(dynamic.RetryBudget{Percent: 20, MinRetriesPerSecond: 1, Window: ptypes.Duration(10 * time.Second)})
+	budget.now = func() time.Time { return now }
+
+	// The reserve allows 10 retries over the window.
+	for i := 0; i < 10; i++ {
+		assert.True(t, budget.Allowed())
+		budget.Retried()
+	}
+	assert.False(t, budget.Allowed())
+
+	// 20% of 10 requests.
+	for i := 0; i < 10; i++ {
+		budget.Requested()
+	}
+	for i := 0; i < 2; i++ {
+		assert.True(t, budget.Allowed())
+		budget.Retried()
+	}
+	assert.False(t, budget.Allowed())
+
+	// The retries are forgotten at the end of the window.
+	now = now.Add(5 * time.Second)
+	assert.False(t, budget.Allowed())
+
+	now = now.Add(6 * time.Second)
+	assert.True(t, budget.Allowed())
+}
+
+func TestBudgets_Get(t *testing.T) {
+	budgets := NewBudgets()
+
+	budget := budgets.Get("retry@file", "foo@file", dynamic.RetryBudget{Percent: 10})
+	assert.Same(t, budget, budgets.Get("retry@file", "foo@file", dynamic.RetryBudget{Percent: 10}))
+	assert.NotSame(t, budget, budgets.Get("retry@file", "bar@file", dynamic.RetryBudget{Percent: 10}))
+	assert.NotSame(t, budget, budgets.Get("other@file", "foo@file", dynamic.RetryBudget{Percent: 10}))
+
+	// The budget is created again when the configuration of the middleware changes.
+	changed := budgets.Get("retry@file", "foo@file", dynamic.RetryBudget{Percent: 50})
+	assert.NotSame(t, budget, changed)
+	assert.Same(t, changed, budgets.Get("retry@file", "foo@file", dynamic.RetryBudget{Percent: 50}))
+
+	budgets.Prune(func(middlewareName string) bool { return middlewareName != "retry@file" })
+	assert.NotSame(t, changed, budgets.Get("retry@file", "foo@file", dynamic.RetryBudget{Percent: 50}))
+	assert.Len(t, budgets.budgets, 2)
+}
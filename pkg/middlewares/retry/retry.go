@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
@@ -23,11 +24,23 @@ const (
 	typeName = "Retry"
 )
 
+// Outcomes of the retried requests.
+const (
+	// OutcomeSuccess is the outcome of the requests whose last attempt reached the backend.
+	OutcomeSuccess = "success"
+	// OutcomeExhausted is the outcome of the requests whose last attempt failed to reach the backend,
+	// no attempt being left, or the retry budget being exhausted.
+	OutcomeExhausted = "exhausted"
+)
+
 // Listener is used to inform about retry attempts.
 type Listener interface {
 	// Retried will be called when a retry happens, with the request attempt passed to it.
 	// For the first retry this will be attempt 2.
 	Retried(req *http.Request, attempt int)
+	// Finished will be called once a retried request has been answered,
+	// with the number of attempts, the outcome of the last one, and the duration of all of them.
+	Finished(req *http.Request, attempts int, outcome string, duration time.Duration)
 }
 
 // Listeners is a convenience type to construct a list of Listener and notify
@@ -37,21 +50,29 @@ type Listeners []Listener
 // retry is a middleware that retries requests.
 type retry struct {
 	attempts int
+	budget   *Budget
 	next     http.Handler
 	listener Listener
 	name     string
 }
 
 // New returns a new retry middleware.
-func New(ctx context.Context, next http.Handler, config dynamic.Retry, listener Listener, name string) (http.Handler, error) {
+// The retries are limited by the budget, shared with the other middlewares targeting the same service,
+// or by a budget of the middleware when it is nil and the configuration defines one.
+func New(ctx context.Context, next http.Handler, config dynamic.Retry, budget *Budget, listener Listener, name string) (http.Handler, error) {
 	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
 
 	if config.Attempts <= 0 {
 		return nil, fmt.Errorf("incorrect (or empty) value for attempt (%d)", config.Attempts)
 	}
 
+	if config.Budget != nil && budget == nil {
+		budget = NewBudget(*config.Budget)
+	}
+
 	return &retry{
 		attempts: config.Attempts,
+		budget:   budget,
 		next:     next,
 		listener: listener,
 		name:     name,
@@ -71,17 +92,25 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		req.Body = ioutil.NopCloser(body)
 	}
 
+	if r.budget != nil {
+		r.budget.Requested()
+	}
+
+	start := time.Now()
 	attempts := 1
 	for {
-		shouldRetry := attempts < r.attempts
+		shouldRetry := attempts < r.attempts && (r.budget == nil || r.budget.Allowed())
 		retryResponseWriter := newResponseWriter(rw, shouldRetry)
 
 		// Disable retries when the backend already received request data
+		var reached bool
 		trace := &httptrace.ClientTrace{
 			WroteHeaders: func() {
+				reached = true
 				retryResponseWriter.DisableRetries()
 			},
 			WroteRequest: func(httptrace.WroteRequestInfo) {
+				reached = true
 				retryResponseWriter.DisableRetries()
 			},
 		}
@@ -90,11 +119,23 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		r.next.ServeHTTP(retryResponseWriter, req.WithContext(newCtx))
 
 		if !retryResponseWriter.ShouldRetry() {
+			if attempts > 1 {
+				outcome := OutcomeSuccess
+				if !reached {
+					outcome = OutcomeExhausted
+				}
+
+				r.listener.Finished(req, attempts, outcome, time.Since(start))
+			}
 			break
 		}
 
 		attempts++
 
+		if r.budget != nil {
+			r.budget.Retried()
+		}
+
 		log.FromContext(middlewares.GetLoggerCtx(req.Context(), r.name, typeName)).
 			Debugf("New attempt %d for request: %v", attempts, req.URL)
 
@@ -109,6 +150,13 @@ func (l Listeners) Retried(req *http.Request, attempt int) {
 	}
 }
 
+// Finished exists to implement the Listener interface. It calls Finished on each of its slice entries.
+func (l Listeners) Finished(req *http.Request, attempts int, outcome string, duration time.Duration) {
+	for _, listener := range l {
+		listener.Finished(req, attempts, outcome, duration)
+	}
+}
+
 type responseWriter interface {
 	http.ResponseWriter
 	http.Flusher
@@ -173,6 +221,11 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 		return
 	}
 
+	if middlewares.IsInformational(code) {
+		middlewares.WriteInformational(r.responseWriter, r.headers, code)
+		return
+	}
+
 	// In that case retry case is set to false which means we at least managed
 	// to write headers to the backend : we are not going to perform any further retry.
 	// So it is now safe to alter current response headers with headers collected during
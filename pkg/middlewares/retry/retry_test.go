@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/middlewares/emptybackendhandler"
@@ -16,6 +17,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/vulcand/oxy/forward"
 	"github.com/vulcand/oxy/roundrobin"
 )
@@ -25,6 +27,7 @@ func TestRetry(t *testing.T) {
 		desc                  string
 		config                dynamic.Retry
 		wantRetryAttempts     int
+		wantOutcome           string
 		wantResponseStatus    int
 		amountFaultyEndpoints int
 	}{
@@ -44,6 +47,7 @@ func TestRetry(t *testing.T) {
 		},
 		{
 			desc:                  "one retry when one server is faulty",
+			wantOutcome:           OutcomeSuccess,
 			config:                dynamic.Retry{Attempts: 2},
 			wantRetryAttempts:     1,
 			wantResponseStatus:    http.StatusOK,
@@ -51,6 +55,7 @@ func TestRetry(t *testing.T) {
 		},
 		{
 			desc:                  "two retries when two servers are faulty",
+			wantOutcome:           OutcomeSuccess,
 			config:                dynamic.Retry{Attempts: 3},
 			wantRetryAttempts:     2,
 			wantResponseStatus:    http.StatusOK,
@@ -58,6 +63,7 @@ func TestRetry(t *testing.T) {
 		},
 		{
 			desc:                  "max attempts exhausted delivers the 5xx response",
+			wantOutcome:           OutcomeExhausted,
 			config:                dynamic.Retry{Attempts: 3},
 			wantRetryAttempts:     2,
 			wantResponseStatus:    http.StatusBadGateway,
@@ -100,7 +106,7 @@ func TestRetry(t *testing.T) {
 			require.NoError(t, err)
 
 			retryListener := &countingRetryListener{}
-			retry, err := New(context.Background(), loadBalancer, test.config, retryListener, "traefikTest")
+			retry, err := New(context.Background(), loadBalancer, test.config, nil, retryListener, "traefikTest")
 			require.NoError(t, err)
 
 			recorder := httptest.NewRecorder()
@@ -110,6 +116,10 @@ func TestRetry(t *testing.T) {
 
 			assert.Equal(t, test.wantResponseStatus, recorder.Code)
 			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+			assert.Equal(t, test.wantOutcome, retryListener.outcome)
+			if test.wantOutcome != "" {
+				assert.Equal(t, test.wantRetryAttempts+1, retryListener.attempts)
+			}
 		})
 	}
 }
@@ -126,7 +136,7 @@ func TestRetryEmptyServerList(t *testing.T) {
 	next := emptybackendhandler.New(loadBalancer)
 
 	retryListener := &countingRetryListener{}
-	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 3}, retryListener, "traefikTest")
+	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 3}, nil, retryListener, "traefikTest")
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -174,7 +184,7 @@ func TestMultipleRetriesShouldNotLooseHeaders(t *testing.T) {
 		rw.WriteHeader(http.StatusNoContent)
 	})
 
-	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 3}, &countingRetryListener{}, "traefikTest")
+	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 3}, nil, &countingRetryListener{}, "traefikTest")
 	require.NoError(t, err)
 
 	responseRecorder := httptest.NewRecorder()
@@ -197,15 +207,48 @@ func TestMultipleRetriesShouldNotLooseHeaders(t *testing.T) {
 	}
 }
 
+func TestRetryBudget(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	budget := NewBudgets().Get("retry@file", "foo@file", dynamic.RetryBudget{Percent: 50, Window: ptypes.Duration(time.Minute)})
+	retryListener := &countingRetryListener{}
+
+	// The middlewares targeting the same service share the budget.
+	var handlers []http.Handler
+	for i := 0; i < 2; i++ {
+		handler, err := New(context.Background(), next, dynamic.Retry{Attempts: 3}, budget, retryListener, "traefikTest")
+		require.NoError(t, err)
+		handlers = append(handlers, handler)
+	}
+
+	for i := 0; i < 10; i++ {
+		recorder := httptest.NewRecorder()
+		handlers[i%2].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+		assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	}
+
+	assert.Equal(t, 5, retryListener.timesCalled)
+}
+
 // countingRetryListener is a Listener implementation to count the times the Retried fn is called.
 type countingRetryListener struct {
 	timesCalled int
+	attempts    int
+	outcome     string
 }
 
 func (l *countingRetryListener) Retried(req *http.Request, attempt int) {
 	l.timesCalled++
 }
 
+func (l *countingRetryListener) Finished(req *http.Request, attempts int, outcome string, duration time.Duration) {
+	l.attempts = attempts
+	l.outcome = outcome
+}
+
 func TestRetryWithFlush(t *testing.T) {
 	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(200)
@@ -220,7 +263,7 @@ func TestRetryWithFlush(t *testing.T) {
 		}
 	})
 
-	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 1}, &countingRetryListener{}, "traefikTest")
+	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 1}, nil, &countingRetryListener{}, "traefikTest")
 	require.NoError(t, err)
 
 	responseRecorder := httptest.NewRecorder()
@@ -296,7 +339,7 @@ func TestRetryWebsocket(t *testing.T) {
 			}
 
 			retryListener := &countingRetryListener{}
-			retryH, err := New(context.Background(), loadBalancer, dynamic.Retry{Attempts: test.maxRequestAttempts}, retryListener, "traefikTest")
+			retryH, err := New(context.Background(), loadBalancer, dynamic.Retry{Attempts: test.maxRequestAttempts}, nil, retryListener, "traefikTest")
 			require.NoError(t, err)
 
 			retryServer := httptest.NewServer(retryH)
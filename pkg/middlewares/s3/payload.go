@@ -0,0 +1,144 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var errPayloadMismatch = errors.New("the payload does not match its hash")
+
+// verifyingReader verifies the payload against the hashes given by the client (X-Amz-Content-Sha256 and Content-MD5),
+// while it is streamed to the storage, without buffering it.
+// The last byte of the payload is held back until the payload is verified,
+// so the storage never receives a complete payload that does not match its hashes:
+// the request is aborted instead.
+type verifyingReader struct {
+	body io.ReadCloser
+
+	sha256         hash.Hash
+	expectedSHA256 []byte
+	md5            hash.Hash
+	expectedMD5    []byte
+
+	buf      []byte
+	last     byte
+	held     bool
+	verified bool
+}
+
+// newVerifyingReader returns a reader verifying the body, or the body itself when the client gave no hash.
+func newVerifyingReader(body io.ReadCloser, header http.Header) (io.ReadCloser, error) {
+	r := &verifyingReader{body: body}
+
+	if value := header.Get(contentSHA256Header); value != "" && value != unsignedPayload && !strings.HasPrefix(value, streamingPayload) {
+		expected, err := hex.DecodeString(value)
+		if err != nil || len(expected) != sha256.Size {
+			return nil, fmt.Errorf("invalid %s header %q", contentSHA256Header, value)
+		}
+		r.sha256 = sha256.New()
+		r.expectedSHA256 = expected
+	}
+
+	if value := header.Get("Content-Md5"); value != "" {
+		expected, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(expected) != md5.Size {
+			return nil, fmt.Errorf("invalid Content-MD5 header %q", value)
+		}
+		r.md5 = md5.New()
+		r.expectedMD5 = expected
+	}
+
+	if r.sha256 == nil && r.md5 == nil {
+		return body, nil
+	}
+
+	return r, nil
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if r.verified {
+		return r.flush(p, 0)
+	}
+
+	if cap(r.buf) < len(p) {
+		r.buf = make([]byte, len(p))
+	}
+	buf := r.buf[:len(p)]
+
+	m, err := r.body.Read(buf)
+
+	var n int
+	if m > 0 {
+		r.hash(buf[:m])
+
+		if r.held {
+			p[0] = r.last
+			n = 1
+		}
+		n += copy(p[n:], buf[:m-1])
+		r.last, r.held = buf[m-1], true
+	}
+
+	if errors.Is(err, io.EOF) {
+		if !r.verify() {
+			return 0, errPayloadMismatch
+		}
+		r.verified = true
+
+		return r.flush(p, n)
+	}
+
+	return n, err
+}
+
+// flush returns the held back byte, once the payload is verified.
+func (r *verifyingReader) flush(p []byte, n int) (int, error) {
+	if r.held {
+		if n == len(p) {
+			return n, nil
+		}
+		p[n] = r.last
+		n++
+		r.held = false
+	}
+
+	return n, io.EOF
+}
+
+func (r *verifyingReader) hash(b []byte) {
+	if r.sha256 != nil {
+		_, _ = r.sha256.Write(b)
+	}
+	if r.md5 != nil {
+		_, _ = r.md5.Write(b)
+	}
+}
+
+func (r *verifyingReader) verify() bool {
+	if r.sha256 != nil && !bytes.Equal(r.sha256.Sum(nil), r.expectedSHA256) {
+		return false
+	}
+
+	if r.md5 != nil && !bytes.Equal(r.md5.Sum(nil), r.expectedMD5) {
+		return false
+	}
+
+	return true
+}
+
+func (r *verifyingReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,126 @@
+package s3
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyingReader(t *testing.T) {
+	payload := "The quick brown fox jumps over the lazy dog"
+
+	sha256Sum := sha256.Sum256([]byte(payload))
+	md5Sum := md5.Sum([]byte(payload))
+
+	testCases := []struct {
+		desc          string
+		header        http.Header
+		body          string
+		expectedError bool
+	}{
+		{
+			desc:   "valid SHA-256",
+			header: http.Header{contentSHA256Header: {hex.EncodeToString(sha256Sum[:])}},
+			body:   payload,
+		},
+		{
+			desc:   "valid MD5",
+			header: http.Header{"Content-Md5": {base64.StdEncoding.EncodeToString(md5Sum[:])}},
+			body:   payload,
+		},
+		{
+			desc: "valid SHA-256 and MD5",
+			header: http.Header{
+				contentSHA256Header: {hex.EncodeToString(sha256Sum[:])},
+				"Content-Md5":       {base64.StdEncoding.EncodeToString(md5Sum[:])},
+			},
+			body: payload,
+		},
+		{
+			desc:          "SHA-256 mismatch",
+			header:        http.Header{contentSHA256Header: {hex.EncodeToString(sha256Sum[:])}},
+			body:          payload + ".",
+			expectedError: true,
+		},
+		{
+			desc: "MD5 mismatch",
+			header: http.Header{
+				contentSHA256Header: {hex.EncodeToString(sha256Sum[:])},
+				"Content-Md5":       {base64.StdEncoding.EncodeToString(make([]byte, md5.Size))},
+			},
+			body:          payload,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := newVerifyingReader(ioutil.NopCloser(iotest.OneByteReader(strings.NewReader(test.body))), test.header)
+			require.NoError(t, err)
+
+			data, err := ioutil.ReadAll(reader)
+			if test.expectedError {
+				assert.Equal(t, errPayloadMismatch, err)
+				// The last byte is held back, so the storage never receives a complete payload.
+				assert.Less(t, len(data), len(test.body))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.body, string(data))
+		})
+	}
+}
+
+func TestVerifyingReaderUnverified(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		header http.Header
+	}{
+		{
+			desc: "no hash",
+		},
+		{
+			desc:   "unsigned payload",
+			header: http.Header{contentSHA256Header: {unsignedPayload}},
+		},
+		{
+			desc:   "streaming payload",
+			header: http.Header{contentSHA256Header: {"STREAMING-AWS4-HMAC-SHA256-PAYLOAD"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			body := ioutil.NopCloser(strings.NewReader("foo"))
+
+			reader, err := newVerifyingReader(body, test.header)
+			require.NoError(t, err)
+
+			assert.Equal(t, body, reader)
+		})
+	}
+}
+
+func TestVerifyingReaderInvalidHeader(t *testing.T) {
+	_, err := newVerifyingReader(ioutil.NopCloser(strings.NewReader("foo")), http.Header{contentSHA256Header: {"foo"}})
+	assert.Error(t, err)
+
+	_, err = newVerifyingReader(ioutil.NopCloser(strings.NewReader("foo")), http.Header{"Content-Md5": {"foo"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "S3"
+)
+
+// Bucket addressing styles.
+const (
+	pathStyle          = "path"
+	virtualHostedStyle = "virtualHosted"
+)
+
+// s3 is a middleware fronting an S3-compatible object storage.
+type s3 struct {
+	next           http.Handler
+	name           string
+	domain         string
+	upstreamDomain string
+	bucketStyle    string
+	signer         *signer
+	verifier       *verifier
+	verifyPayload  bool
+}
+
+// New creates an S3 middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.S3, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.Domain == "" {
+		return nil, errors.New("the domain is required")
+	}
+
+	switch config.BucketStyle {
+	case "", pathStyle, virtualHostedStyle:
+	default:
+		return nil, fmt.Errorf("unknown bucket style %q, it must be %q or %q", config.BucketStyle, pathStyle, virtualHostedStyle)
+	}
+
+	upstreamDomain := config.UpstreamDomain
+	if upstreamDomain == "" {
+		upstreamDomain = config.Domain
+	}
+
+	m := &s3{
+		next:           next,
+		name:           name,
+		domain:         strings.ToLower(config.Domain),
+		upstreamDomain: strings.ToLower(upstreamDomain),
+		bucketStyle:    config.BucketStyle,
+		verifyPayload:  config.VerifyPayload,
+	}
+
+	if config.Signature != nil {
+		var err error
+		m.signer, err = newSigner(*config.Signature)
+		if err != nil {
+			return nil, err
+		}
+
+		m.verifier, err = newVerifier(config.Signature.ClientCredentials, m.signer.region, m.signer.service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *s3) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return m.name, tracing.SpanKindNoneEnum
+}
+
+func (m *s3) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), m.name, typeName))
+
+	now := time.Now()
+
+	// The signature of the client is verified before the rewriting, as it covers the host and the path.
+	if m.verifier != nil {
+		if err := m.verifier.verify(req, now); err != nil {
+			logger.Debugf("Unable to verify the signature of the request: %v", err)
+			http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	if err := m.rewrite(req); err != nil {
+		logger.Debugf("Unable to rewrite the request: %v", err)
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if m.verifyPayload && req.Body != nil && req.Body != http.NoBody {
+		body, err := newVerifyingReader(req.Body, req.Header)
+		if err != nil {
+			logger.Debugf("Unable to verify the payload: %v", err)
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		req.Body = body
+	}
+
+	if m.signer != nil {
+		if err := m.signer.sign(req, now); err != nil {
+			logger.Debugf("Unable to sign the request: %v", err)
+			http.Error(rw, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+			return
+		}
+	}
+
+	m.next.ServeHTTP(rw, req)
+}
+
+// rewrite rewrites the host of the request to the upstream domain, and the bucket addressing to the bucket style.
+// The requests to another domain are not rewritten.
+func (m *s3) rewrite(req *http.Request) error {
+	host := strings.ToLower(req.Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	var bucket, key string
+	switch {
+	case host == m.domain:
+		// Path-style request: /<bucket>/<key>.
+		path := strings.TrimPrefix(req.URL.Path, "/")
+		if i := strings.Index(path, "/"); i >= 0 {
+			bucket, key = path[:i], path[i:]
+		} else {
+			bucket, key = path, "/"
+		}
+
+		if m.bucketStyle != virtualHostedStyle || bucket == "" {
+			req.Host = m.upstreamDomain
+			return nil
+		}
+
+	case strings.HasSuffix(host, "."+m.domain):
+		// Virtual-hosted-style request: <bucket>.<domain>/<key>.
+		bucket = strings.TrimSuffix(host, "."+m.domain)
+		key = req.URL.Path
+		if key == "" {
+			key = "/"
+		}
+
+		if m.bucketStyle != pathStyle {
+			req.Host = bucket + "." + m.upstreamDomain
+			return nil
+		}
+
+	default:
+		return nil
+	}
+
+	if m.bucketStyle == pathStyle {
+		req.Host = m.upstreamDomain
+		req.URL.Path = "/" + bucket + key
+	} else {
+		if strings.Contains(bucket, ".") {
+			return fmt.Errorf("the bucket %q cannot be addressed with the virtual-hosted style", bucket)
+		}
+		req.Host = bucket + "." + m.upstreamDomain
+		req.URL.Path = key
+	}
+
+	req.URL.RawPath = ""
+	req.RequestURI = req.URL.RequestURI()
+
+	return nil
+}
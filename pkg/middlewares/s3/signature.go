@@ -0,0 +1,288 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+const (
+	signatureAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat      = "20060102T150405Z"
+	amzShortDateFormat = "20060102"
+
+	contentSHA256Header = "X-Amz-Content-Sha256"
+	dateHeader          = "X-Amz-Date"
+	securityTokenHeader = "X-Amz-Security-Token"
+
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	streamingPayload = "STREAMING-"
+
+	// maxPresignedExpires is the longest validity of a presigned URL, in seconds (7 days).
+	maxPresignedExpires = 7 * 24 * 60 * 60
+)
+
+// presignedQueryParams are the query parameters of the signature of the presigned URLs.
+var presignedQueryParams = []string{
+	"X-Amz-Algorithm",
+	"X-Amz-Credential",
+	"X-Amz-Date",
+	"X-Amz-Expires",
+	"X-Amz-SignedHeaders",
+	"X-Amz-Signature",
+	"X-Amz-Security-Token",
+}
+
+// signer signs the requests with the AWS Signature Version 4.
+type signer struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+	service         string
+}
+
+func newSigner(config dynamic.S3Signature) (*signer, error) {
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("the access key ID and the secret access key are required to sign the requests")
+	}
+
+	if config.Region == "" {
+		return nil, errors.New("the region is required to sign the requests")
+	}
+
+	service := config.Service
+	if service == "" {
+		service = "s3"
+	}
+
+	return &signer{
+		accessKeyID:     config.AccessKeyID,
+		secretAccessKey: config.SecretAccessKey,
+		sessionToken:    config.SessionToken,
+		region:          config.Region,
+		service:         service,
+	}, nil
+}
+
+// sign replaces the signature of the request, be it in the Authorization header or in the query of a presigned URL.
+// The payload keeps the hash given by the client, as it is the one verified by the storage.
+func (s *signer) sign(req *http.Request, now time.Time) error {
+	payloadHash := req.Header.Get(contentSHA256Header)
+	if strings.HasPrefix(payloadHash, streamingPayload) {
+		// The signatures of the chunks are chained to the signature of the request, so they cannot be signed again.
+		return errors.New("the payloads signed by chunks cannot be signed again")
+	}
+
+	query := req.URL.Query()
+	if query.Get("X-Amz-Signature") != "" {
+		return s.presign(req, query)
+	}
+
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+	}
+
+	now = now.UTC()
+
+	req.Header.Del("Authorization")
+	req.Header.Set(dateHeader, now.Format(amzDateFormat))
+	req.Header.Set(contentSHA256Header, payloadHash)
+	req.Header.Del(securityTokenHeader)
+	if s.sessionToken != "" {
+		req.Header.Set(securityTokenHeader, s.sessionToken)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+	signature := s.signature(req, now, signedHeaders, canonicalHeaders, payloadHash)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signatureAlgorithm, s.accessKeyID, s.scope(now), signedHeaders, signature))
+
+	return nil
+}
+
+// presign replaces the signature of a presigned URL, keeping its date and expiration,
+// so the URL does not stay valid longer than the one given to the client.
+// The date and expiration must have been checked by the verification of the signature of the client.
+func (s *signer) presign(req *http.Request, query url.Values) error {
+	date, err := time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return fmt.Errorf("invalid date %q", query.Get("X-Amz-Date"))
+	}
+
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil {
+		return fmt.Errorf("invalid expiration %q", query.Get("X-Amz-Expires"))
+	}
+
+	for _, param := range presignedQueryParams {
+		query.Del(param)
+	}
+
+	req.Header.Del("Authorization")
+	req.Header.Del(securityTokenHeader)
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+
+	query.Set("X-Amz-Algorithm", signatureAlgorithm)
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+s.scope(date))
+	query.Set("X-Amz-Date", date.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(expires))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if s.sessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	// The payloads of the presigned URLs are never signed.
+	query.Set("X-Amz-Signature", s.signature(req, date, signedHeaders, canonicalHeaders, unsignedPayload))
+	req.URL.RawQuery = query.Encode()
+	req.RequestURI = req.URL.RequestURI()
+
+	return nil
+}
+
+// signature returns the signature of the canonical request, at the given date.
+func (s *signer) signature(req *http.Request, date time.Time, signedHeaders, canonicalHeaders, payloadHash string) string {
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(canonicalPath(req), false),
+		canonicalQuery(req),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signatureAlgorithm,
+		date.Format(amzDateFormat),
+		s.scope(date),
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date.Format(amzShortDateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// scope returns the credential scope of the signatures at the given date.
+func (s *signer) scope(date time.Time) string {
+	return strings.Join([]string{date.UTC().Format(amzShortDateFormat), s.region, s.service, "aws4_request"}, "/")
+}
+
+func canonicalPath(req *http.Request) string {
+	if req.URL.Path == "" {
+		return "/"
+	}
+
+	return req.URL.Path
+}
+
+// canonicalHeaders returns the signed headers, and their canonical form.
+// The host, the content headers, the range, and the x-amz-* headers are signed.
+func canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{
+		"host": req.Host,
+	}
+
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name != "content-md5" && name != "content-type" && name != "range" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+
+		headers[name] = canonicalHeaderValue(values)
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name)
+		canonical.WriteString(":")
+		canonical.WriteString(headers[name])
+		canonical.WriteString("\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalHeaderValue returns the canonical form of the values of a header,
+// trimmed and separated by commas.
+func canonicalHeaderValue(values []string) string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+	}
+
+	return strings.Join(trimmed, ",")
+}
+
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+
+	params := make([][2]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, [2]string{uriEncode(key, true), uriEncode(value, true)})
+		}
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+
+	encoded := make([]string, 0, len(params))
+	for _, param := range params {
+		encoded = append(encoded, param[0]+"="+param[1])
+	}
+
+	return strings.Join(encoded, "&")
+}
+
+// uriEncode encodes all the characters but the unreserved ones (RFC 3986),
+// and the slashes when encodeSlash is false.
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
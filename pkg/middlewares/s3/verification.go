@@ -0,0 +1,214 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxClockSkew is the largest difference accepted between the date of a signature and the current time.
+const maxClockSkew = 15 * time.Minute
+
+// errInvalidSignature is returned for the requests whose signature cannot be verified with the credentials of the clients.
+var errInvalidSignature = errors.New("invalid signature")
+
+// verifier verifies the AWS Signature Version 4 of the clients, before the requests are signed again.
+type verifier struct {
+	// secretAccessKeys holds the secret access keys of the clients, by access key ID.
+	secretAccessKeys map[string]string
+	region           string
+	service          string
+}
+
+// newVerifier creates a verifier from the credentials of the clients, given as accessKeyID:secretAccessKey.
+func newVerifier(clientCredentials []string, region, service string) (*verifier, error) {
+	if len(clientCredentials) == 0 {
+		return nil, errors.New("the credentials of the clients are required to verify their signatures")
+	}
+
+	secretAccessKeys := make(map[string]string)
+	for _, credentials := range clientCredentials {
+		parts := strings.SplitN(credentials, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New("invalid client credentials, they must be given as accessKeyID:secretAccessKey")
+		}
+
+		if _, ok := secretAccessKeys[parts[0]]; ok {
+			return nil, fmt.Errorf("duplicated client access key ID %q", parts[0])
+		}
+		secretAccessKeys[parts[0]] = parts[1]
+	}
+
+	return &verifier{
+		secretAccessKeys: secretAccessKeys,
+		region:           region,
+		service:          service,
+	}, nil
+}
+
+// verify verifies the signature of the request, be it in the Authorization header or in the query of a presigned URL.
+// It must be called before the request is rewritten, as the signature covers its host and path.
+func (v *verifier) verify(req *http.Request, now time.Time) error {
+	if req.URL.Query().Get("X-Amz-Signature") != "" {
+		return v.verifyPresignedURL(req, now)
+	}
+
+	return v.verifyAuthorization(req, now)
+}
+
+func (v *verifier) verifyAuthorization(req *http.Request, now time.Time) error {
+	authorization := req.Header.Get("Authorization")
+	if authorization == "" {
+		return fmt.Errorf("%w: missing Authorization header", errInvalidSignature)
+	}
+
+	params, err := parseAuthorization(authorization)
+	if err != nil {
+		return err
+	}
+
+	date, err := time.Parse(amzDateFormat, req.Header.Get(dateHeader))
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s header %q", errInvalidSignature, dateHeader, req.Header.Get(dateHeader))
+	}
+
+	if date.Before(now.Add(-maxClockSkew)) || date.After(now.Add(maxClockSkew)) {
+		return fmt.Errorf("%w: the date %s is too far from the current time", errInvalidSignature, date.Format(time.RFC3339))
+	}
+
+	payloadHash := req.Header.Get(contentSHA256Header)
+	if payloadHash == "" {
+		return fmt.Errorf("%w: missing %s header", errInvalidSignature, contentSHA256Header)
+	}
+
+	return v.verifySignature(req, date, params["Credential"], params["SignedHeaders"], params["Signature"], payloadHash)
+}
+
+func (v *verifier) verifyPresignedURL(req *http.Request, now time.Time) error {
+	query := req.URL.Query()
+
+	if query.Get("X-Amz-Algorithm") != signatureAlgorithm {
+		return fmt.Errorf("%w: unsupported algorithm %q", errInvalidSignature, query.Get("X-Amz-Algorithm"))
+	}
+
+	date, err := time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return fmt.Errorf("%w: invalid date %q", errInvalidSignature, query.Get("X-Amz-Date"))
+	}
+
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires <= 0 || expires > maxPresignedExpires {
+		return fmt.Errorf("%w: invalid expiration %q", errInvalidSignature, query.Get("X-Amz-Expires"))
+	}
+
+	if date.After(now.Add(maxClockSkew)) {
+		return fmt.Errorf("%w: not valid before %s", errInvalidSignature, date.Format(time.RFC3339))
+	}
+
+	if expiration := date.Add(time.Duration(expires) * time.Second); now.After(expiration) {
+		return fmt.Errorf("%w: expired since %s", errInvalidSignature, expiration.Format(time.RFC3339))
+	}
+
+	signature := query.Get("X-Amz-Signature")
+
+	// The signature is computed on the query without the signature itself.
+	query.Del("X-Amz-Signature")
+	unsigned := req.Clone(req.Context())
+	unsigned.URL.RawQuery = query.Encode()
+
+	// The payloads of the presigned URLs are never signed.
+	return v.verifySignature(unsigned, date, query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"), signature, unsignedPayload)
+}
+
+// verifySignature verifies the signature of the request, made with the given credential on the given signed headers.
+func (v *verifier) verifySignature(req *http.Request, date time.Time, credential, signedHeaders, signature, payloadHash string) error {
+	parts := strings.SplitN(credential, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("%w: invalid credential %q", errInvalidSignature, credential)
+	}
+
+	secretAccessKey, ok := v.secretAccessKeys[parts[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown access key ID %q", errInvalidSignature, parts[0])
+	}
+
+	s := &signer{secretAccessKey: secretAccessKey, region: v.region, service: v.service}
+	if parts[1] != s.scope(date) {
+		return fmt.Errorf("%w: unexpected credential scope %q", errInvalidSignature, parts[1])
+	}
+
+	canonicalHeaders, err := signedCanonicalHeaders(req, signedHeaders)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidSignature, err)
+	}
+
+	expected := s.signature(req, date, signedHeaders, canonicalHeaders, payloadHash)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("%w: signature mismatch for the access key ID %q", errInvalidSignature, parts[0])
+	}
+
+	return nil
+}
+
+// parseAuthorization returns the parameters of an Authorization header signed with the AWS Signature Version 4.
+func parseAuthorization(authorization string) (map[string]string, error) {
+	if !strings.HasPrefix(authorization, signatureAlgorithm+" ") {
+		return nil, fmt.Errorf("%w: unsupported authorization scheme", errInvalidSignature)
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(strings.TrimPrefix(authorization, signatureAlgorithm+" "), ",") {
+		parts := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: invalid authorization parameter %q", errInvalidSignature, param)
+		}
+		params[parts[0]] = parts[1]
+	}
+
+	for _, name := range []string{"Credential", "SignedHeaders", "Signature"} {
+		if params[name] == "" {
+			return nil, fmt.Errorf("%w: missing authorization parameter %s", errInvalidSignature, name)
+		}
+	}
+
+	return params, nil
+}
+
+// signedCanonicalHeaders returns the canonical form of the headers signed by the client,
+// which must include the host.
+func signedCanonicalHeaders(req *http.Request, signedHeaders string) (string, error) {
+	var host bool
+	var canonical strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		var value string
+		switch name {
+		case "host":
+			host = true
+			value = req.Host
+		case "content-length":
+			// The content length is not in the headers of the incoming requests.
+			value = strconv.FormatInt(req.ContentLength, 10)
+		default:
+			values := req.Header.Values(name)
+			if len(values) == 0 {
+				return "", fmt.Errorf("the signed header %q is missing", name)
+			}
+			value = canonicalHeaderValue(values)
+		}
+
+		canonical.WriteString(name)
+		canonical.WriteString(":")
+		canonical.WriteString(value)
+		canonical.WriteString("\n")
+	}
+
+	if !host {
+		return "", errors.New("the host header is not signed")
+	}
+
+	return canonical.String(), nil
+}
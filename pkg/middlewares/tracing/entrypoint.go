@@ -43,6 +43,10 @@ func (e *entryPointMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 	span, req, finish := e.StartSpanf(req, ext.SpanKindRPCServerEnum, "EntryPoint", []string{e.entryPoint, req.Host}, " ", ext.RPCServerOption(spanCtx))
 	defer finish()
 
+	if e.IsSamplingForced(req) {
+		ext.SamplingPriority.Set(span, 1)
+	}
+
 	ext.Component.Set(span, e.ServiceName)
 	tracing.LogRequest(span, req)
 
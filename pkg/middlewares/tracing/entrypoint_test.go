@@ -84,3 +84,54 @@ func TestEntryPointMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestEntryPointMiddleware_forceSampling(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		header     string
+		expected   bool
+	}{
+		{
+			desc:       "no header",
+			remoteAddr: "10.0.0.1:1234",
+		},
+		{
+			desc:       "header from a trusted IP",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "force",
+			expected:   true,
+		},
+		{
+			desc:       "header from an untrusted IP",
+			remoteAddr: "192.168.0.1:1234",
+			header:     "force",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			backend := &trackingBackenMock{
+				tracer: &MockTracer{Span: &MockSpan{Tags: make(map[string]interface{})}},
+			}
+
+			newTracing, err := tracing.NewTracing("", 0, backend)
+			require.NoError(t, err)
+			require.NoError(t, newTracing.SetForceSampling("X-Traefik-Trace", []string{"10.0.0.0/8"}))
+
+			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.header != "" {
+				req.Header.Set("X-Traefik-Trace", test.header)
+			}
+
+			next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				_, ok := backend.tracer.(*MockTracer).Span.Tags[string(ext.SamplingPriority)]
+				assert.Equal(t, test.expected, ok)
+			})
+
+			handler := NewEntryPoint(context.Background(), newTracing, "test", next)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package trailingslash
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+)
+
+const (
+	typeName = "TrailingSlash"
+)
+
+// Trailing slash policies of the routers.
+const (
+	// Redirect redirects the requests to the path matching the rule.
+	Redirect = "redirect"
+	// Strip forwards the requests with the path matching the rule.
+	Strip = "strip"
+	// Keep does not handle the requests, which are answered with a 404 status code.
+	Keep = "keep"
+)
+
+// New creates a handler applying the trailing slash policy of a router,
+// to the requests whose path only matches the rule with its trailing slash added or removed.
+// The handler is expected to get the requests with the path matching the rule.
+func New(ctx context.Context, next http.Handler, policy string, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	switch policy {
+	case Redirect:
+		return http.HandlerFunc(redirect), nil
+	case Strip:
+		return next, nil
+	default:
+		return nil, fmt.Errorf("unknown trailing slash policy %q of the router %s", policy, name)
+	}
+}
+
+// redirect redirects the request permanently, the methods other than GET and HEAD being kept with a 308 status code.
+func redirect(rw http.ResponseWriter, req *http.Request) {
+	code := http.StatusMovedPermanently
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+
+	http.Redirect(rw, req, req.URL.RequestURI(), code)
+}
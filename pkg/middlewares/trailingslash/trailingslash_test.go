@@ -0,0 +1,77 @@
+package trailingslash
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		policy           string
+		method           string
+		expectedCode     int
+		expectedLocation string
+		expectedError    bool
+	}{
+		{
+			desc:             "redirect GET request",
+			policy:           Redirect,
+			method:           http.MethodGet,
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "/foo/?bar=baz",
+		},
+		{
+			desc:             "redirect POST request",
+			policy:           Redirect,
+			method:           http.MethodPost,
+			expectedCode:     http.StatusPermanentRedirect,
+			expectedLocation: "/foo/?bar=baz",
+		},
+		{
+			desc:         "strip",
+			policy:       Strip,
+			method:       http.MethodGet,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			desc:          "keep",
+			policy:        Keep,
+			expectedError: true,
+		},
+		{
+			desc:          "unknown policy",
+			policy:        "foo",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusNoContent)
+			})
+
+			handler, err := New(context.Background(), next, test.policy, "foo")
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(test.method, "http://foo/foo/?bar=baz", nil))
+
+			assert.Equal(t, test.expectedCode, rw.Code)
+			assert.Equal(t, test.expectedLocation, rw.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// The subsystems whose readiness is reported by the health endpoint.
+const (
+	// HealthCheckProviders is ready once every enabled provider has delivered its first configuration.
+	HealthCheckProviders = "providers"
+	// HealthCheckCertificates is ready once the certificates of the first configurations,
+	// including the ones of the ACME resolvers, are loaded.
+	HealthCheckCertificates = "certificates"
+	// HealthCheckEntryPoints is ready once the entry points are bound and accept connections.
+	HealthCheckEntryPoints = "entryPoints"
+)
+
+var healthChecks = []string{HealthCheckProviders, HealthCheckCertificates, HealthCheckEntryPoints}
+
+type healthCheckRepresentation struct {
+	Ready    bool `json:"ready"`
+	Required bool `json:"required"`
+}
+
+type healthRepresentation struct {
+	Status string                               `json:"status"`
+	Checks map[string]healthCheckRepresentation `json:"checks"`
+}
+
+// SetReady marks the given subsystem as ready.
+func (h *Handler) SetReady(check string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.readyChecks == nil {
+		h.readyChecks = make(map[string]bool)
+	}
+	h.readyChecks[check] = true
+}
+
+// Ready returns whether all the subsystems counted in the readiness are ready.
+func (h *Handler) Ready() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, check := range h.HealthChecks {
+		if !h.readyChecks[check] {
+			return false
+		}
+	}
+	return true
+}
+
+// HealthHandler returns the handler of the health endpoint,
+// reporting the readiness of each subsystem.
+func (h *Handler) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		health := healthRepresentation{
+			Status: "ready",
+			Checks: make(map[string]healthCheckRepresentation),
+		}
+
+		required := make(map[string]bool)
+		for _, check := range h.HealthChecks {
+			required[check] = true
+		}
+
+		h.mu.RLock()
+		for _, check := range healthChecks {
+			health.Checks[check] = healthCheckRepresentation{
+				Ready:    h.readyChecks[check],
+				Required: required[check],
+			}
+		}
+		h.mu.RUnlock()
+
+		statusCode := http.StatusOK
+		switch {
+		case h.terminating:
+			health.Status = "terminating"
+			statusCode = h.TerminatingStatusCode
+		case !h.Ready():
+			health.Status = "unready"
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(statusCode)
+		_ = json.NewEncoder(rw).Encode(health)
+	})
+}
+
+// ValidHealthChecks returns the names of the subsystems which can be counted in the readiness.
+func ValidHealthChecks() []string {
+	checks := append([]string(nil), healthChecks...)
+	sort.Strings(checks)
+	return checks
+}
@@ -0,0 +1,93 @@
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		healthChecks   []string
+		ready          []string
+		terminating    bool
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			desc:           "nothing ready",
+			healthChecks:   []string{HealthCheckProviders, HealthCheckCertificates, HealthCheckEntryPoints},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "unready",
+		},
+		{
+			desc:           "some checks ready",
+			healthChecks:   []string{HealthCheckProviders, HealthCheckCertificates, HealthCheckEntryPoints},
+			ready:          []string{HealthCheckProviders, HealthCheckEntryPoints},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "unready",
+		},
+		{
+			desc:           "all checks ready",
+			healthChecks:   []string{HealthCheckProviders, HealthCheckCertificates, HealthCheckEntryPoints},
+			ready:          []string{HealthCheckProviders, HealthCheckCertificates, HealthCheckEntryPoints},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "ready",
+		},
+		{
+			desc:           "only the required checks ready",
+			healthChecks:   []string{HealthCheckEntryPoints},
+			ready:          []string{HealthCheckEntryPoints},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "ready",
+		},
+		{
+			desc:           "terminating",
+			healthChecks:   []string{HealthCheckEntryPoints},
+			ready:          []string{HealthCheckEntryPoints},
+			terminating:    true,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "terminating",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &Handler{}
+			handler.SetDefaults()
+			handler.HealthChecks = test.healthChecks
+			handler.terminating = test.terminating
+
+			for _, check := range test.ready {
+				handler.SetReady(check)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+
+			var health healthRepresentation
+			err := json.NewDecoder(recorder.Body).Decode(&health)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, health.Status)
+			require.Len(t, health.Checks, 3)
+
+			for _, check := range test.ready {
+				assert.True(t, health.Checks[check].Ready, check)
+			}
+			for _, check := range test.healthChecks {
+				assert.True(t, health.Checks[check].Required, check)
+			}
+		})
+	}
+}
@@ -4,20 +4,26 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 )
 
 // Handler expose ping routes.
 type Handler struct {
-	EntryPoint            string `description:"EntryPoint" export:"true" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
-	ManualRouting         bool   `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty"`
-	TerminatingStatusCode int    `description:"Terminating status code" json:"terminatingStatusCode,omitempty" toml:"terminatingStatusCode,omitempty" yaml:"terminatingStatusCode,omitempty"`
+	EntryPoint            string   `description:"EntryPoint" export:"true" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
+	ManualRouting         bool     `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty"`
+	TerminatingStatusCode int      `description:"Terminating status code" json:"terminatingStatusCode,omitempty" toml:"terminatingStatusCode,omitempty" yaml:"terminatingStatusCode,omitempty"`
+	HealthChecks          []string `description:"Subsystems counted in the readiness reported by the health endpoint: providers, certificates, entryPoints." json:"healthChecks,omitempty" toml:"healthChecks,omitempty" yaml:"healthChecks,omitempty" export:"true"`
 	terminating           bool
+
+	mu          sync.RWMutex
+	readyChecks map[string]bool
 }
 
 // SetDefaults sets the default values.
 func (h *Handler) SetDefaults() {
 	h.EntryPoint = "traefik"
 	h.TerminatingStatusCode = http.StatusServiceUnavailable
+	h.HealthChecks = []string{HealthCheckProviders, HealthCheckCertificates, HealthCheckEntryPoints}
 }
 
 // WithContext causes the ping endpoint to serve non 200 responses.
@@ -0,0 +1,244 @@
+// Package policy validates the dynamic configuration sent by the providers against the policies of the administrators.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/tls"
+)
+
+// Kinds of the configuration objects.
+const (
+	KindRouter     = "router"
+	KindMiddleware = "middleware"
+	KindService    = "service"
+	KindTCPRouter  = "tcpRouter"
+	KindTCPService = "tcpService"
+	KindUDPRouter  = "udpRouter"
+	KindUDPService = "udpService"
+)
+
+// Violation is a configuration object violating a policy.
+type Violation struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// input is the input document given to the policies.
+// The certificates are left out, as they hold the private keys.
+type input struct {
+	HTTP *dynamic.HTTPConfiguration `json:"http,omitempty"`
+	TCP  *dynamic.TCPConfiguration  `json:"tcp,omitempty"`
+	UDP  *dynamic.UDPConfiguration  `json:"udp,omitempty"`
+	TLS  *tlsInput                  `json:"tls,omitempty"`
+}
+
+type tlsInput struct {
+	Options map[string]tls.Options `json:"options,omitempty"`
+	Stores  map[string]tls.Store   `json:"stores,omitempty"`
+}
+
+// opaResponse is the response of the OPA data API.
+type opaResponse struct {
+	Result []Violation `json:"result"`
+}
+
+// Engine evaluates the policies against the dynamic configuration, with an Open Policy Agent server.
+// The policy document lists the violations of the whole configuration,
+// so the policies can relate the objects to each other, for example the routers to their TLS options.
+type Engine struct {
+	client        http.Client
+	decisionURL   string
+	rejectOnError bool
+}
+
+// New creates a policy engine.
+func New(config *static.OPAPolicies) (*Engine, error) {
+	if config.URL == "" {
+		return nil, errors.New("the URL of the OPA server is empty")
+	}
+
+	if config.Policy == "" {
+		return nil, errors.New("the policy is empty")
+	}
+
+	return &Engine{
+		client:        http.Client{Timeout: time.Duration(config.Timeout)},
+		decisionURL:   strings.TrimSuffix(config.URL, "/") + "/v1/data/" + strings.Trim(config.Policy, "/"),
+		rejectOnError: config.RejectOnError,
+	}, nil
+}
+
+// Apply rejects the objects violating the policies, along with the routers using them.
+// The rejected objects are disabled, and their errors are reported by the API.
+func (e *Engine) Apply(ctx context.Context, conf dynamic.Configuration, rtConf *runtime.Configuration) {
+	logger := log.FromContext(ctx)
+
+	violations, err := e.evaluate(ctx, conf)
+	if err != nil {
+		if !e.rejectOnError {
+			logger.Errorf("Unable to evaluate the policies, the configuration is not validated: %v", err)
+			return
+		}
+
+		logger.Errorf("Unable to evaluate the policies, the configuration is rejected: %v", err)
+		for _, violation := range rejectAll(rtConf, fmt.Sprintf("the policies cannot be evaluated: %v", err)) {
+			reject(rtConf, violation)
+		}
+		return
+	}
+
+	for _, violation := range violations {
+		logger.WithField("kind", violation.Kind).Errorf("The configuration of %q violates the policies: %s", violation.Name, violation.Reason)
+
+		if !reject(rtConf, violation) {
+			logger.Debugf("Unknown %s %q violating the policies", violation.Kind, violation.Name)
+		}
+	}
+}
+
+// evaluate returns the violations of the policies.
+// An undefined policy document has no violation.
+func (e *Engine) evaluate(ctx context.Context, conf dynamic.Configuration) ([]Violation, error) {
+	in := input{HTTP: conf.HTTP, TCP: conf.TCP, UDP: conf.UDP}
+	if conf.TLS != nil {
+		in.TLS = &tlsInput{Options: conf.TLS.Options, Stores: conf.TLS.Stores}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"input": in})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.decisionURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var opaResp opaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&opaResp); err != nil {
+		return nil, fmt.Errorf("the violations must be a list of objects with a kind, a name and a reason: %w", err)
+	}
+
+	return opaResp.Result, nil
+}
+
+// reject rejects the object violating the policies, along with the routers using it.
+// It returns false if the object does not exist.
+func reject(rtConf *runtime.Configuration, violation Violation) bool {
+	err := fmt.Errorf("rejected by the policies: %s", violation.Reason)
+	usedErr := fmt.Errorf("the %s %q is rejected by the policies", violation.Kind, violation.Name)
+
+	switch violation.Kind {
+	case KindRouter:
+		if router, ok := rtConf.Routers[violation.Name]; ok {
+			router.AddError(err, true)
+			return true
+		}
+
+	case KindMiddleware:
+		if middleware, ok := rtConf.Middlewares[violation.Name]; ok {
+			middleware.AddError(err, true)
+			for name, router := range rtConf.Routers {
+				for _, used := range router.Middlewares {
+					if qualify(used, name) == violation.Name {
+						router.AddError(usedErr, true)
+					}
+				}
+			}
+			return true
+		}
+
+	case KindService:
+		if service, ok := rtConf.Services[violation.Name]; ok {
+			service.AddError(err, true)
+			for name, router := range rtConf.Routers {
+				if qualify(router.Service, name) == violation.Name {
+					router.AddError(usedErr, true)
+				}
+			}
+			return true
+		}
+
+	case KindTCPRouter:
+		if router, ok := rtConf.TCPRouters[violation.Name]; ok {
+			router.AddError(err, true)
+			return true
+		}
+
+	case KindTCPService:
+		if service, ok := rtConf.TCPServices[violation.Name]; ok {
+			service.AddError(err, true)
+			for name, router := range rtConf.TCPRouters {
+				if qualify(router.Service, name) == violation.Name {
+					router.AddError(usedErr, true)
+				}
+			}
+			return true
+		}
+
+	case KindUDPRouter:
+		if router, ok := rtConf.UDPRouters[violation.Name]; ok {
+			router.AddError(err, true)
+			return true
+		}
+
+	case KindUDPService:
+		if service, ok := rtConf.UDPServices[violation.Name]; ok {
+			service.AddError(err, true)
+			for name, router := range rtConf.UDPRouters {
+				if qualify(router.Service, name) == violation.Name {
+					router.AddError(usedErr, true)
+				}
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// rejectAll returns a violation for each router, so no router is built.
+func rejectAll(rtConf *runtime.Configuration, reason string) []Violation {
+	var violations []Violation
+	for name := range rtConf.Routers {
+		violations = append(violations, Violation{Kind: KindRouter, Name: name, Reason: reason})
+	}
+	for name := range rtConf.TCPRouters {
+		violations = append(violations, Violation{Kind: KindTCPRouter, Name: name, Reason: reason})
+	}
+	for name := range rtConf.UDPRouters {
+		violations = append(violations, Violation{Kind: KindUDPRouter, Name: name, Reason: reason})
+	}
+
+	return violations
+}
+
+// qualify returns the qualified name of an element used by another one, which is in the same provider when it is not given.
+func qualify(elementName, userName string) string {
+	return provider.GetQualifiedName(provider.AddInContext(context.Background(), userName), elementName)
+}
@@ -0,0 +1,160 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineApply(t *testing.T) {
+	var received map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/data/traefik/violations", req.URL.Path)
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&received))
+
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"result": []Violation{
+				{Kind: KindRouter, Name: "foo@file", Reason: "the TLS options must have a minimum version of TLS 1.2"},
+				{Kind: KindMiddleware, Name: "strip@file", Reason: "forbidden"},
+				{Kind: KindService, Name: "unsafe@file", Reason: "forbidden"},
+				{Kind: KindTCPService, Name: "tcp@file", Reason: "forbidden"},
+				{Kind: KindRouter, Name: "unknown@file", Reason: "forbidden"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	engine, err := New(&static.OPAPolicies{URL: server.URL, Policy: "traefik/violations"})
+	require.NoError(t, err)
+
+	conf := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo@file":  {Service: "foo", TLS: &dynamic.RouterTLSConfig{Options: "old"}},
+				"bar@file":  {Service: "foo", Middlewares: []string{"strip"}},
+				"baz@file":  {Service: "unsafe@file"},
+				"safe@file": {Service: "foo"},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"strip@file": {StripPrefix: &dynamic.StripPrefix{}},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo@file":    {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+				"unsafe@file": {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"tcp@file": {Service: "tcp"},
+			},
+			Services: map[string]*dynamic.TCPService{
+				"tcp@file": {LoadBalancer: &dynamic.TCPServersLoadBalancer{}},
+			},
+		},
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{{Certificate: tls.Certificate{KeyFile: "private key"}}},
+			Options:      map[string]tls.Options{"old": {MinVersion: "VersionTLS10"}},
+		},
+	}
+	rtConf := runtime.NewConfig(conf)
+
+	engine.Apply(context.Background(), conf, rtConf)
+
+	assert.Contains(t, received["input"], "http")
+	assert.Contains(t, received["input"]["tls"], "options")
+	assert.NotContains(t, received["input"]["tls"], "certificates")
+
+	assert.Equal(t, runtime.StatusDisabled, rtConf.Routers["foo@file"].Status)
+	assert.Equal(t, []string{"rejected by the policies: the TLS options must have a minimum version of TLS 1.2"}, rtConf.Routers["foo@file"].Err)
+
+	assert.Equal(t, runtime.StatusDisabled, rtConf.Middlewares["strip@file"].Status)
+	assert.Equal(t, runtime.StatusDisabled, rtConf.Routers["bar@file"].Status)
+	assert.Equal(t, []string{`the middleware "strip@file" is rejected by the policies`}, rtConf.Routers["bar@file"].Err)
+
+	assert.Equal(t, runtime.StatusDisabled, rtConf.Services["unsafe@file"].Status)
+	assert.Equal(t, runtime.StatusDisabled, rtConf.Routers["baz@file"].Status)
+
+	assert.Equal(t, runtime.StatusDisabled, rtConf.TCPServices["tcp@file"].Status)
+	assert.Equal(t, runtime.StatusDisabled, rtConf.TCPRouters["tcp@file"].Status)
+
+	assert.Equal(t, runtime.StatusEnabled, rtConf.Routers["safe@file"].Status)
+	assert.Equal(t, runtime.StatusEnabled, rtConf.Services["foo@file"].Status)
+}
+
+func TestEngineApplyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		desc           string
+		rejectOnError  bool
+		expectedStatus string
+	}{
+		{
+			desc:           "configuration not validated",
+			expectedStatus: runtime.StatusEnabled,
+		},
+		{
+			desc:           "configuration rejected",
+			rejectOnError:  true,
+			expectedStatus: runtime.StatusDisabled,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			engine, err := New(&static.OPAPolicies{URL: server.URL, Policy: "traefik/violations", RejectOnError: test.rejectOnError})
+			require.NoError(t, err)
+
+			conf := dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo@file": {Service: "foo"},
+					},
+				},
+			}
+			rtConf := runtime.NewConfig(conf)
+
+			engine.Apply(context.Background(), conf, rtConf)
+
+			assert.Equal(t, test.expectedStatus, rtConf.Routers["foo@file"].Status)
+		})
+	}
+}
+
+func TestEngineApplyUndefinedDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	t.Cleanup(server.Close)
+
+	engine, err := New(&static.OPAPolicies{URL: server.URL, Policy: "traefik/violations", RejectOnError: true})
+	require.NoError(t, err)
+
+	conf := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo@file": {Service: "foo"},
+			},
+		},
+	}
+	rtConf := runtime.NewConfig(conf)
+
+	engine.Apply(context.Background(), conf, rtConf)
+
+	assert.Equal(t, runtime.StatusEnabled, rtConf.Routers["foo@file"].Status)
+}
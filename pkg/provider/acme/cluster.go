@@ -0,0 +1,199 @@
+package acme
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/cluster"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+const (
+	// clusterResolveTimeout is the maximum duration an instance of the cluster waits for the owner of a certificate to obtain it,
+	// before obtaining it itself.
+	clusterResolveTimeout = 2 * time.Minute
+	// clusterRenewFallback is the remaining validity under which a certificate is renewed by any instance of the cluster,
+	// in case its owner failed to renew it.
+	clusterRenewFallback = 20 * 24 * time.Hour
+
+	challengeClusterPrefix = "acme-challenge/"
+)
+
+// SetCluster shares the certificates with the other instances of the cluster:
+// each certificate is obtained and renewed by the instance owning it, and loaded by the others.
+func (p *Provider) SetCluster(node *cluster.Node) {
+	p.cluster = node
+	p.ChallengeStore = &clusterChallengeStore{ChallengeStore: p.ChallengeStore, node: node}
+}
+
+func (p *Provider) clusterPrefix() string {
+	return "acme/" + p.ResolverName + "/"
+}
+
+func (p *Provider) clusterKey(tlsStore string, domains []string) string {
+	return p.clusterPrefix() + tlsStore + "/" + strings.Join(domains, ",")
+}
+
+// ownsCertificate tells whether the local instance is in charge of obtaining and renewing the certificate.
+func (p *Provider) ownsCertificate(tlsStore string, domains []string) bool {
+	return p.cluster == nil || p.cluster.Owns(p.clusterKey(tlsStore, domains))
+}
+
+// publishCertificate shares a certificate with the other instances of the cluster.
+// The certificate is sealed, as it holds its private key.
+func (p *Provider) publishCertificate(ctx context.Context, cert *CertAndStore) {
+	if p.cluster == nil {
+		return
+	}
+
+	value, err := json.Marshal(cert)
+	if err == nil {
+		value, err = p.cluster.Seal(value)
+	}
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to share the certificate for the domains %v with the cluster: %v", cert.Domain.ToStrArray(), err)
+		return
+	}
+
+	p.cluster.Set(p.clusterKey(cert.Store, cert.Domain.ToStrArray()), value)
+}
+
+// waitForCluster waits for the certificate to be obtained by its owner, and tells whether it was.
+func (p *Provider) waitForCluster(ctx context.Context, tlsStore string, domains []string) bool {
+	key := p.clusterKey(tlsStore, domains)
+
+	log.FromContext(ctx).Debugf("Waiting for the certificate for the domains %v to be obtained by another instance of the cluster...", domains)
+
+	ticker := time.NewTicker(p.cluster.Interval())
+	defer ticker.Stop()
+
+	timeout := time.NewTimer(clusterResolveTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, _, ok := p.cluster.Get(key); ok {
+				return true
+			}
+		case <-timeout.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// watchCluster loads the certificates obtained by the other instances of the cluster.
+func (p *Provider) watchCluster(ctx context.Context) {
+	if p.cluster == nil {
+		return
+	}
+
+	p.clusterCertsChan = make(chan *CertAndStore)
+
+	for _, cert := range p.getCertificates() {
+		p.publishCertificate(ctx, cert)
+	}
+
+	versions := make(map[string]uint64)
+
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		ticker := time.NewTicker(p.cluster.Interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				for _, entry := range p.cluster.Entries(p.clusterPrefix()) {
+					if entry.Origin == p.cluster.Name() || versions[entry.Key] >= entry.Version {
+						continue
+					}
+					versions[entry.Key] = entry.Version
+
+					cert, err := p.openCertificate(entry.Value)
+					if err != nil {
+						log.FromContext(ctx).Errorf("Unable to load the certificate shared by %s: %v", entry.Origin, err)
+						continue
+					}
+
+					select {
+					case p.clusterCertsChan <- cert:
+					case <-ctxPool.Done():
+						return
+					}
+				}
+			case <-ctxPool.Done():
+				return
+			}
+		}
+	})
+}
+
+func (p *Provider) openCertificate(sealed []byte) (*CertAndStore, error) {
+	value, err := p.cluster.Open(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &CertAndStore{}
+	if err := json.Unmarshal(value, cert); err != nil {
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+// isMoreRecent tells whether the certificate expires after the current one.
+func isMoreRecent(ctx context.Context, cert, current *Certificate) bool {
+	crt, err := getX509Certificate(ctx, cert)
+	if err != nil || crt == nil {
+		return false
+	}
+
+	currentCrt, err := getX509Certificate(ctx, current)
+	if err != nil || currentCrt == nil {
+		return true
+	}
+
+	return crt.NotAfter.After(currentCrt.NotAfter)
+}
+
+// clusterChallengeStore shares the HTTP challenge tokens with the other instances of the cluster,
+// as the certificate authority can reach any of them to validate a challenge.
+type clusterChallengeStore struct {
+	ChallengeStore
+	node *cluster.Node
+}
+
+func (s *clusterChallengeStore) GetHTTPChallengeToken(token, domain string) ([]byte, error) {
+	keyAuth, err := s.ChallengeStore.GetHTTPChallengeToken(token, domain)
+	if err == nil {
+		return keyAuth, nil
+	}
+
+	if value, _, ok := s.node.Get(challengeClusterPrefix + domain + "/" + token); ok && len(value) > 0 {
+		return value, nil
+	}
+
+	return nil, err
+}
+
+func (s *clusterChallengeStore) SetHTTPChallengeToken(token, domain string, keyAuth []byte) error {
+	if err := s.ChallengeStore.SetHTTPChallengeToken(token, domain, keyAuth); err != nil {
+		return err
+	}
+
+	s.node.Set(challengeClusterPrefix+domain+"/"+token, keyAuth)
+
+	return nil
+}
+
+func (s *clusterChallengeStore) RemoveHTTPChallengeToken(token, domain string) error {
+	// The entries cannot be deleted from the cluster, so the token is emptied instead.
+	s.node.Set(challengeClusterPrefix+domain+"/"+token, nil)
+
+	return s.ChallengeStore.RemoveHTTPChallengeToken(token, domain)
+}
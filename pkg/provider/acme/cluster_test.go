@@ -0,0 +1,80 @@
+package acme
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/cluster"
+	"github.com/containous/traefik/v2/pkg/tls/generate"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterChallengeStore(t *testing.T) {
+	node, err := cluster.New(&cluster.Configuration{Address: "127.0.0.1:0", Secret: "secret"})
+	require.NoError(t, err)
+
+	// The stores share the node, as if the gossip delivered the tokens of each instance to the other.
+	store := &clusterChallengeStore{ChallengeStore: NewLocalChallengeStore(), node: node}
+	otherStore := &clusterChallengeStore{ChallengeStore: NewLocalChallengeStore(), node: node}
+
+	require.NoError(t, store.SetHTTPChallengeToken("token", "example.com", []byte("keyAuth")))
+
+	keyAuth, err := otherStore.GetHTTPChallengeToken("token", "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "keyAuth", string(keyAuth))
+
+	_, err = otherStore.GetHTTPChallengeToken("token", "example.org")
+	assert.Error(t, err)
+
+	require.NoError(t, store.RemoveHTTPChallengeToken("token", "example.com"))
+
+	_, err = otherStore.GetHTTPChallengeToken("token", "example.com")
+	assert.Error(t, err)
+}
+
+func TestProvider_loadClusterCertificate(t *testing.T) {
+	newCert := func(expiration time.Time) *CertAndStore {
+		t.Helper()
+
+		certPEM, keyPEM, err := generate.KeyPair("example.com", expiration)
+		require.NoError(t, err)
+
+		return &CertAndStore{
+			Certificate: Certificate{Domain: types.Domain{Main: "example.com"}, Certificate: certPEM, Key: keyPEM},
+			Store:       "default",
+		}
+	}
+
+	current := newCert(time.Now().Add(24 * time.Hour))
+	p := &Provider{certificates: []*CertAndStore{current}}
+
+	// The certificates are read while the cluster ones are loaded.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			for _, cert := range p.getCertificates() {
+				_ = cert.Certificate.Certificate
+			}
+		}
+	}()
+
+	assert.False(t, p.loadClusterCertificate(context.Background(), newCert(time.Now().Add(time.Hour))))
+
+	recent := newCert(time.Now().Add(48 * time.Hour))
+	assert.True(t, p.loadClusterCertificate(context.Background(), recent))
+
+	wg.Wait()
+
+	certificates := p.getCertificates()
+	require.Len(t, certificates, 1)
+	assert.Equal(t, recent.Certificate.Certificate, certificates[0].Certificate.Certificate)
+
+	// The previous element is replaced rather than updated, for the copies being ranged over.
+	assert.NotEqual(t, recent.Certificate.Certificate, current.Certificate.Certificate)
+}
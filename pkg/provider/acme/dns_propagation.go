@@ -0,0 +1,196 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Strategies of the DNS propagation checks.
+const (
+	// propagationAuthoritative queries the authoritative nameservers of the domain, found with the resolvers.
+	propagationAuthoritative = "authoritative"
+	// propagationResolvers queries the resolvers themselves, e.g. when they answer for a split-horizon zone.
+	propagationResolvers = "resolvers"
+)
+
+const (
+	dnsOverTLSPrefix   = "tls://"
+	dnsOverHTTPSPrefix = "https://"
+
+	dnsQueryTimeout = 10 * time.Second
+)
+
+// validatePropagation validates the resolvers and the strategy of the DNS propagation checks.
+func validatePropagation(challenge *DNSChallenge) error {
+	switch challenge.PropagationStrategy {
+	case "", propagationAuthoritative:
+		for _, resolver := range challenge.Resolvers {
+			if !isPlainResolver(resolver) {
+				return fmt.Errorf("the resolver %q is only supported by the %q propagation strategy", resolver, propagationResolvers)
+			}
+		}
+	case propagationResolvers:
+		if len(challenge.Resolvers) == 0 {
+			return fmt.Errorf("the %q propagation strategy needs resolvers", propagationResolvers)
+		}
+	default:
+		return fmt.Errorf("unknown propagation strategy %q", challenge.PropagationStrategy)
+	}
+
+	return nil
+}
+
+// plainResolvers returns the resolvers queried with the plain DNS protocol.
+func plainResolvers(resolvers []string) []string {
+	var plain []string
+	for _, resolver := range resolvers {
+		if isPlainResolver(resolver) {
+			plain = append(plain, resolver)
+		}
+	}
+
+	return plain
+}
+
+func isPlainResolver(resolver string) bool {
+	return !strings.HasPrefix(resolver, dnsOverTLSPrefix) && !strings.HasPrefix(resolver, dnsOverHTTPSPrefix)
+}
+
+// checkResolversPropagation tells whether all the resolvers return the TXT record of the challenge.
+func checkResolversPropagation(client *http.Client, resolvers []string, fqdn, value string) (bool, error) {
+	for _, resolver := range resolvers {
+		records, err := queryTXT(client, resolver, fqdn)
+		if err != nil {
+			return false, fmt.Errorf("resolver %s: %w", resolver, err)
+		}
+
+		if !containsString(records, value) {
+			return false, fmt.Errorf("resolver %s did not return the expected TXT record [fqdn: %s, value: %s]: %s",
+				resolver, fqdn, value, strings.Join(records, ", "))
+		}
+	}
+
+	return true, nil
+}
+
+// queryTXT returns the TXT records of the FQDN, queried with the protocol of the resolver:
+// DNS-over-HTTPS for the https:// URLs, DNS-over-TLS for the tls:// addresses, and plain DNS otherwise.
+func queryTXT(client *http.Client, resolver, fqdn string) ([]string, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(fqdn), dns.TypeTXT)
+	msg.SetEdns0(4096, false)
+	msg.RecursionDesired = true
+
+	var (
+		answer *dns.Msg
+		err    error
+	)
+
+	switch {
+	case strings.HasPrefix(resolver, dnsOverHTTPSPrefix):
+		answer, err = exchangeHTTPS(client, resolver, msg)
+	case strings.HasPrefix(resolver, dnsOverTLSPrefix):
+		dnsClient := &dns.Client{Net: "tcp-tls", Timeout: dnsQueryTimeout, TLSConfig: &tls.Config{}}
+		address := withDefaultPort(strings.TrimPrefix(resolver, dnsOverTLSPrefix), "853")
+		dnsClient.TLSConfig.ServerName, _, _ = net.SplitHostPort(address)
+		answer, _, err = dnsClient.Exchange(msg, address)
+	default:
+		address := withDefaultPort(resolver, "53")
+		answer, _, err = (&dns.Client{Timeout: dnsQueryTimeout}).Exchange(msg, address)
+		if err == nil && answer.Truncated {
+			answer, _, err = (&dns.Client{Net: "tcp", Timeout: dnsQueryTimeout}).Exchange(msg, address)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if answer.Rcode != dns.RcodeSuccess && answer.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("unexpected response code %s for %s", dns.RcodeToString[answer.Rcode], fqdn)
+	}
+
+	var records []string
+	for _, rr := range answer.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			records = append(records, strings.Join(txt.Txt, ""))
+		}
+	}
+
+	return records, nil
+}
+
+// exchangeHTTPS sends the query to the DNS-over-HTTPS resolver, as specified by RFC 8484.
+func exchangeHTTPS(client *http.Client, resolver string, msg *dns.Msg) (*dns.Msg, error) {
+	query := msg.Copy()
+	// The ID is zero to make the responses cacheable.
+	query.Id = 0
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsQueryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, resolver, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := new(dns.Msg)
+	if err := answer.Unpack(body); err != nil {
+		return nil, err
+	}
+
+	if len(answer.Question) == 0 || !strings.EqualFold(answer.Question[0].Name, msg.Question[0].Name) {
+		return nil, errors.New("the response does not answer the query")
+	}
+
+	return answer, nil
+}
+
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+
+	return net.JoinHostPort(address, port)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
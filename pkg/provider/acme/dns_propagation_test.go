@@ -0,0 +1,189 @@
+package acme
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePropagation(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		challenge     DNSChallenge
+		expectedError bool
+	}{
+		{
+			desc:      "default strategy with plain resolvers",
+			challenge: DNSChallenge{Resolvers: []string{"1.1.1.1:53", "8.8.8.8"}},
+		},
+		{
+			desc:          "default strategy with a DNS-over-HTTPS resolver",
+			challenge:     DNSChallenge{Resolvers: []string{"https://dns.example.com/dns-query"}},
+			expectedError: true,
+		},
+		{
+			desc:          "authoritative strategy with a DNS-over-TLS resolver",
+			challenge:     DNSChallenge{PropagationStrategy: propagationAuthoritative, Resolvers: []string{"tls://1.1.1.1"}},
+			expectedError: true,
+		},
+		{
+			desc: "resolvers strategy",
+			challenge: DNSChallenge{
+				PropagationStrategy: propagationResolvers,
+				Resolvers:           []string{"1.1.1.1:53", "tls://1.1.1.1", "https://dns.example.com/dns-query"},
+			},
+		},
+		{
+			desc:          "resolvers strategy without resolvers",
+			challenge:     DNSChallenge{PropagationStrategy: propagationResolvers},
+			expectedError: true,
+		},
+		{
+			desc:          "unknown strategy",
+			challenge:     DNSChallenge{PropagationStrategy: "foo"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := validatePropagation(&test.challenge)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckResolversPropagation(t *testing.T) {
+	records := map[string][]string{
+		"_acme-challenge.propagated.example.com.": {"token"},
+		"_acme-challenge.partial.example.com.":    {"token"},
+	}
+
+	// The split-horizon resolver does not see the partial record yet.
+	plainAddress := startDNSServer(t, records)
+	dohServer := startDoHServer(t, map[string][]string{
+		"_acme-challenge.propagated.example.com.": {"token"},
+	})
+
+	resolvers := []string{plainAddress, dohServer.URL + "/dns-query"}
+
+	testCases := []struct {
+		desc     string
+		fqdn     string
+		expected bool
+	}{
+		{
+			desc:     "propagated to all the resolvers",
+			fqdn:     "_acme-challenge.propagated.example.com.",
+			expected: true,
+		},
+		{
+			desc: "propagated to some resolvers",
+			fqdn: "_acme-challenge.partial.example.com.",
+		},
+		{
+			desc: "not propagated",
+			fqdn: "_acme-challenge.missing.example.com.",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := checkResolversPropagation(dohServer.Client(), resolvers, test.fqdn, "token")
+			assert.Equal(t, test.expected, ok)
+			if test.expected {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// startDNSServer starts a plain DNS server answering the TXT records, and returns its address.
+func startDNSServer(t *testing.T, records map[string][]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{PacketConn: conn, Handler: txtHandler(records)}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+// startDoHServer starts a DNS-over-HTTPS server answering the TXT records.
+func startDoHServer(t *testing.T, records map[string][]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := new(dns.Msg)
+		if err := query.Unpack(body); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		packed, err := answerTXT(records, query).Pack()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/dns-message")
+		_, _ = rw.Write(packed)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func txtHandler(records map[string][]string) dns.HandlerFunc {
+	return func(rw dns.ResponseWriter, query *dns.Msg) {
+		_ = rw.WriteMsg(answerTXT(records, query))
+	}
+}
+
+func answerTXT(records map[string][]string, query *dns.Msg) *dns.Msg {
+	answer := new(dns.Msg)
+	answer.SetReply(query)
+
+	name := query.Question[0].Name
+	values, ok := records[name]
+	if !ok {
+		answer.Rcode = dns.RcodeNameError
+		return answer
+	}
+
+	for _, value := range values {
+		answer.Answer = append(answer.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+			Txt: []string{value},
+		})
+	}
+
+	return answer
+}
@@ -0,0 +1,149 @@
+package acme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var _ ChallengeStore = (*FileChallengeStore)(nil)
+
+// FileChallengeStore is an implementation of the ChallengeStore in a file,
+// which allows several Traefik processes to answer the challenges of the ACME resolver running in one of them.
+type FileChallengeStore struct {
+	filename string
+	lock     sync.Mutex
+}
+
+// NewFileChallengeStore initializes a new FileChallengeStore with a file name.
+func NewFileChallengeStore(filename string) *FileChallengeStore {
+	return &FileChallengeStore{filename: filename}
+}
+
+// GetHTTPChallengeToken Get the http challenge token from the store.
+func (s *FileChallengeStore) GetHTTPChallengeToken(token, domain string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	storedData, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := storedData.HTTPChallenges[token][domain]
+	if !ok {
+		return nil, fmt.Errorf("cannot find challenge for token %v", token)
+	}
+	return result, nil
+}
+
+// SetHTTPChallengeToken Set the http challenge token in the store.
+func (s *FileChallengeStore) SetHTTPChallengeToken(token, domain string, keyAuth []byte) error {
+	return s.update(func(storedData *StoredChallengeData) {
+		if _, ok := storedData.HTTPChallenges[token]; !ok {
+			storedData.HTTPChallenges[token] = map[string][]byte{}
+		}
+
+		storedData.HTTPChallenges[token][domain] = keyAuth
+	})
+}
+
+// RemoveHTTPChallengeToken Remove the http challenge token in the store.
+func (s *FileChallengeStore) RemoveHTTPChallengeToken(token, domain string) error {
+	return s.update(func(storedData *StoredChallengeData) {
+		if _, ok := storedData.HTTPChallenges[token]; ok {
+			delete(storedData.HTTPChallenges[token], domain)
+			if len(storedData.HTTPChallenges[token]) == 0 {
+				delete(storedData.HTTPChallenges, token)
+			}
+		}
+	})
+}
+
+// AddTLSChallenge Add a certificate to the ACME TLS-ALPN-01 certificates storage.
+func (s *FileChallengeStore) AddTLSChallenge(domain string, cert *Certificate) error {
+	return s.update(func(storedData *StoredChallengeData) {
+		storedData.TLSChallenges[domain] = cert
+	})
+}
+
+// GetTLSChallenge Get a certificate from the ACME TLS-ALPN-01 certificates storage.
+func (s *FileChallengeStore) GetTLSChallenge(domain string) (*Certificate, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	storedData, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return storedData.TLSChallenges[domain], nil
+}
+
+// RemoveTLSChallenge Remove a certificate from the ACME TLS-ALPN-01 certificates storage.
+func (s *FileChallengeStore) RemoveTLSChallenge(domain string) error {
+	return s.update(func(storedData *StoredChallengeData) {
+		delete(storedData.TLSChallenges, domain)
+	})
+}
+
+func (s *FileChallengeStore) update(fn func(storedData *StoredChallengeData)) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	storedData, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	fn(storedData)
+
+	data, err := json.Marshal(storedData)
+	if err != nil {
+		return err
+	}
+
+	// The file is replaced atomically, so that the other processes never read a partially written file.
+	tmp, err := ioutil.TempFile(filepath.Dir(s.filename), filepath.Base(s.filename)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.filename)
+}
+
+func (s *FileChallengeStore) read() (*StoredChallengeData, error) {
+	storedData := &StoredChallengeData{}
+
+	data, err := ioutil.ReadFile(s.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, storedData); err != nil {
+			return nil, err
+		}
+	}
+
+	if storedData.HTTPChallenges == nil {
+		storedData.HTTPChallenges = map[string]map[string][]byte{}
+	}
+	if storedData.TLSChallenges == nil {
+		storedData.TLSChallenges = map[string]*Certificate{}
+	}
+
+	return storedData, nil
+}
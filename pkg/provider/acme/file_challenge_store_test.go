@@ -0,0 +1,54 @@
+package acme
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileChallengeStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme-challenges")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	filename := filepath.Join(dir, "challenges.json")
+
+	// Two stores sharing the same file, as in two Traefik processes.
+	writer := NewFileChallengeStore(filename)
+	reader := NewFileChallengeStore(filename)
+
+	_, err = reader.GetHTTPChallengeToken("token", "example.com")
+	require.Error(t, err)
+
+	err = writer.SetHTTPChallengeToken("token", "example.com", []byte("keyAuth"))
+	require.NoError(t, err)
+
+	keyAuth, err := reader.GetHTTPChallengeToken("token", "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("keyAuth"), keyAuth)
+
+	err = writer.RemoveHTTPChallengeToken("token", "example.com")
+	require.NoError(t, err)
+
+	_, err = reader.GetHTTPChallengeToken("token", "example.com")
+	require.Error(t, err)
+
+	cert := &Certificate{Certificate: []byte("cert"), Key: []byte("key")}
+	err = writer.AddTLSChallenge("example.com", cert)
+	require.NoError(t, err)
+
+	got, err := reader.GetTLSChallenge("example.com")
+	require.NoError(t, err)
+	assert.Equal(t, cert, got)
+
+	err = writer.RemoveTLSChallenge("example.com")
+	require.NoError(t, err)
+
+	got, err = reader.GetTLSChallenge("example.com")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
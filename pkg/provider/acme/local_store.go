@@ -93,6 +93,14 @@ func (s *LocalStore) get(resolverName string) (*StoredData, error) {
 	return s.storedData[resolverName], nil
 }
 
+// invalidate drops the data read from the file, so that it is read again on the next access.
+func (s *LocalStore) invalidate() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.storedData = nil
+}
+
 // listenSaveAction listens to a chan to store ACME data in json format into `LocalStore.filename`.
 func (s *LocalStore) listenSaveAction() {
 	safe.Go(func() {
@@ -0,0 +1,124 @@
+package acme
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/go-acme/lego/v4/challenge"
+)
+
+const (
+	operationObtain = "obtain"
+	operationRenew  = "renew"
+)
+
+// SetMetricsRegistry sets the registry recording the certificates obtained and renewed, and the challenges failed.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
+}
+
+// recordIssue records an attempt to obtain or renew the certificate of the domains, started at start,
+// counting a challenge failure for each domain whose challenge was presented, when it failed.
+func (p *Provider) recordIssue(operation string, domains []string, start time.Time, err error) {
+	challenges := p.challenges.pop(domains)
+
+	if p.metricsRegistry == nil {
+		return
+	}
+
+	if err != nil {
+		for domain, challengeType := range challenges {
+			p.metricsRegistry.ACMEChallengeFailuresCounter().With("resolver", p.ResolverName, "challenge", challengeType, "domain", domain).Add(1)
+		}
+		return
+	}
+
+	if operation == operationRenew {
+		p.metricsRegistry.ACMECertsRenewedCounter().With("resolver", p.ResolverName).Add(1)
+	} else {
+		p.metricsRegistry.ACMECertsObtainedCounter().With("resolver", p.ResolverName).Add(1)
+	}
+
+	p.metricsRegistry.ACMECertIssueDurationHistogram().With("resolver", p.ResolverName, "operation", operation).ObserveFromStart(start)
+}
+
+// challengeTracker keeps the type of the last challenge presented for each domain,
+// until the attempt to get its certificate is over.
+type challengeTracker struct {
+	mu    sync.Mutex
+	types map[string]string
+}
+
+func (t *challengeTracker) presented(domain, challengeType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.types == nil {
+		t.types = make(map[string]string)
+	}
+
+	t.types[challengeDomain(domain)] = challengeType
+}
+
+// pop returns the types of the challenges presented for the domains, and forgets them.
+func (t *challengeTracker) pop(domains []string) map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	challenges := make(map[string]string)
+	for _, domain := range domains {
+		if challengeType, ok := t.types[challengeDomain(domain)]; ok {
+			challenges[domain] = challengeType
+		}
+	}
+
+	// The challenges are forgotten once all the domains are looked up,
+	// as a wildcard domain and its base domain share the same challenge domain.
+	for _, domain := range domains {
+		delete(t.types, challengeDomain(domain))
+	}
+
+	return challenges
+}
+
+// challengeDomain returns the domain the challenges of the domain are presented for,
+// as lego presents the challenges of the wildcard domains for their base domain, without the "*." prefix.
+func challengeDomain(domain string) string {
+	return strings.TrimPrefix(domain, "*.")
+}
+
+// track wraps the challenge provider to record the domains it presents a challenge for,
+// keeping the timeout of the provider, if any.
+func (t *challengeTracker) track(provider challenge.Provider, challengeType challenge.Type) challenge.Provider {
+	tracked := &trackedChallenge{Provider: provider, tracker: t, challengeType: challengeType.String()}
+
+	if timeout, ok := provider.(challenge.ProviderTimeout); ok {
+		return &trackedChallengeTimeout{trackedChallenge: tracked, timeout: timeout}
+	}
+
+	return tracked
+}
+
+type trackedChallenge struct {
+	challenge.Provider
+	tracker       *challengeTracker
+	challengeType string
+}
+
+// Present records the challenge and presents it.
+func (c *trackedChallenge) Present(domain, token, keyAuth string) error {
+	c.tracker.presented(domain, c.challengeType)
+	return c.Provider.Present(domain, token, keyAuth)
+}
+
+type trackedChallengeTimeout struct {
+	*trackedChallenge
+	timeout challenge.ProviderTimeout
+}
+
+// Timeout returns the timeout of the wrapped provider.
+func (c *trackedChallengeTimeout) Timeout() (timeout, interval time.Duration) {
+	return c.timeout.Timeout()
+}
@@ -0,0 +1,160 @@
+package acme
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/go-acme/lego/v4/challenge"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acmeRegistry is a registry collecting the ACME counters.
+type acmeRegistry struct {
+	metrics.Registry
+	obtained *testhelpers.CollectingCounter
+	renewed  *testhelpers.CollectingCounter
+	failures *testhelpers.CollectingCounter
+}
+
+func (r acmeRegistry) ACMECertsObtainedCounter() gokitmetrics.Counter {
+	return r.obtained
+}
+
+func (r acmeRegistry) ACMECertsRenewedCounter() gokitmetrics.Counter {
+	return r.renewed
+}
+
+func (r acmeRegistry) ACMEChallengeFailuresCounter() gokitmetrics.Counter {
+	return r.failures
+}
+
+type fakeChallenge struct{}
+
+func (f fakeChallenge) Present(domain, token, keyAuth string) error { return nil }
+
+func (f fakeChallenge) CleanUp(domain, token, keyAuth string) error { return nil }
+
+func TestProvider_recordIssue(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		operation      string
+		err            error
+		wantObtained   float64
+		wantRenewed    float64
+		wantFailures   float64
+		wantLastLabels []string
+	}{
+		{
+			desc:         "obtained",
+			operation:    operationObtain,
+			wantObtained: 1,
+		},
+		{
+			desc:        "renewed",
+			operation:   operationRenew,
+			wantRenewed: 1,
+		},
+		{
+			desc:           "failed",
+			operation:      operationObtain,
+			err:            errors.New("unauthorized"),
+			wantFailures:   1,
+			wantLastLabels: []string{"resolver", "foo", "challenge", "http-01", "domain", "foo.com"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry := acmeRegistry{
+				Registry: metrics.NewVoidRegistry(),
+				obtained: &testhelpers.CollectingCounter{},
+				renewed:  &testhelpers.CollectingCounter{},
+				failures: &testhelpers.CollectingCounter{},
+			}
+
+			p := &Provider{ResolverName: "foo"}
+			p.SetMetricsRegistry(registry)
+
+			provider := p.challenges.track(fakeChallenge{}, challenge.HTTP01)
+			require.NoError(t, provider.Present("foo.com", "token", "key"))
+
+			p.recordIssue(test.operation, []string{"foo.com", "bar.com"}, time.Now(), test.err)
+
+			assert.Equal(t, test.wantObtained, registry.obtained.CounterValue)
+			assert.Equal(t, test.wantRenewed, registry.renewed.CounterValue)
+			assert.Equal(t, test.wantFailures, registry.failures.CounterValue)
+			assert.Equal(t, test.wantLastLabels, registry.failures.LastLabelValues)
+
+			// The challenges are forgotten once the attempt is over.
+			assert.Empty(t, p.challenges.pop([]string{"foo.com"}))
+		})
+	}
+}
+
+func TestChallengeTracker_pop(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		presented []string
+		domains   []string
+		expected  map[string]string
+	}{
+		{
+			desc:      "domain",
+			presented: []string{"foo.com"},
+			domains:   []string{"foo.com", "bar.com"},
+			expected:  map[string]string{"foo.com": "dns-01"},
+		},
+		{
+			desc:      "wildcard domain",
+			presented: []string{"foo.com"},
+			domains:   []string{"*.foo.com"},
+			expected:  map[string]string{"*.foo.com": "dns-01"},
+		},
+		{
+			desc:      "wildcard domain and its base domain",
+			presented: []string{"foo.com", "foo.com"},
+			domains:   []string{"foo.com", "*.foo.com"},
+			expected:  map[string]string{"foo.com": "dns-01", "*.foo.com": "dns-01"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := &challengeTracker{}
+
+			// lego presents the challenges of the wildcard domains for their base domain.
+			provider := tracker.track(fakeChallenge{}, challenge.DNS01)
+			for _, domain := range test.presented {
+				require.NoError(t, provider.Present(domain, "token", "key"))
+			}
+
+			assert.Equal(t, test.expected, tracker.pop(test.domains))
+			assert.Empty(t, tracker.pop(test.domains))
+		})
+	}
+}
+
+func TestChallengeTracker_track(t *testing.T) {
+	tracker := &challengeTracker{}
+
+	_, ok := tracker.track(fakeChallenge{}, challenge.HTTP01).(challenge.ProviderTimeout)
+	assert.False(t, ok)
+
+	provider, ok := tracker.track(&challengeHTTP{}, challenge.HTTP01).(challenge.ProviderTimeout)
+	require.True(t, ok)
+
+	timeout, interval := provider.Timeout()
+	assert.Equal(t, 60*time.Second, timeout)
+	assert.Equal(t, 5*time.Second, interval)
+}
@@ -6,14 +6,18 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"os"
 	"reflect"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/rules"
 	"github.com/containous/traefik/v2/pkg/safe"
 	traefiktls "github.com/containous/traefik/v2/pkg/tls"
@@ -28,6 +32,9 @@ import (
 	ptypes "github.com/traefik/paerser/types"
 )
 
+// storageWatchInterval is the interval between two checks of the storage, when the certificates are resolved by another Traefik process.
+const storageWatchInterval = 5 * time.Second
+
 // oscpMustStaple enables OSCP stapling as from https://github.com/go-acme/lego/issues/270.
 var oscpMustStaple = false
 
@@ -67,8 +74,9 @@ type Certificate struct {
 type DNSChallenge struct {
 	Provider                string          `description:"Use a DNS-01 based challenge provider rather than HTTPS." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty"`
 	DelayBeforeCheck        ptypes.Duration `description:"Assume DNS propagates after a delay in seconds rather than finding and querying nameservers." json:"delayBeforeCheck,omitempty" toml:"delayBeforeCheck,omitempty" yaml:"delayBeforeCheck,omitempty"`
-	Resolvers               []string        `description:"Use following DNS servers to resolve the FQDN authority." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty"`
+	Resolvers               []string        `description:"Use following DNS servers to resolve the FQDN authority. The DNS-over-TLS (tls://) and DNS-over-HTTPS (https://) resolvers are supported by the 'resolvers' propagation strategy." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty"`
 	DisablePropagationCheck bool            `description:"Disable the DNS propagation checks before notifying ACME that the DNS challenge is ready. [not recommended]" json:"disablePropagationCheck,omitempty" toml:"disablePropagationCheck,omitempty" yaml:"disablePropagationCheck,omitempty"`
+	PropagationStrategy     string          `description:"Strategy of the DNS propagation checks: 'authoritative' queries the authoritative nameservers of the domain, 'resolvers' queries the resolvers themselves." json:"propagationStrategy,omitempty" toml:"propagationStrategy,omitempty" yaml:"propagationStrategy,omitempty"`
 }
 
 // HTTPChallenge contains HTTP challenge Configuration.
@@ -82,10 +90,12 @@ type TLSChallenge struct{}
 // Provider holds configurations of the provider.
 type Provider struct {
 	*Configuration
-	ResolverName           string
-	Store                  Store `json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty"`
-	ChallengeStore         ChallengeStore
-	certificates           []*CertAndStore
+	ResolverName   string
+	Store          Store `json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty"`
+	ChallengeStore ChallengeStore
+	certificates   []*CertAndStore
+	// certificatesMutex protects the certificates slice, whose elements are replaced rather than updated in place.
+	certificatesMutex      sync.RWMutex
 	account                *Account
 	client                 *lego.Client
 	certsChan              chan *CertAndStore
@@ -96,6 +106,11 @@ type Provider struct {
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	follower               bool
+	cluster                *cluster.Node
+	clusterCertsChan       chan *CertAndStore
+	metricsRegistry        metrics.Registry
+	challenges             challengeTracker
 }
 
 // SetTLSManager sets the tls manager to use.
@@ -103,6 +118,12 @@ func (p *Provider) SetTLSManager(tlsManager *traefiktls.Manager) {
 	p.tlsManager = tlsManager
 }
 
+// SetFollower makes the provider only load the certificates resolved by another Traefik process sharing the same storage,
+// without resolving nor renewing any certificate itself.
+func (p *Provider) SetFollower() {
+	p.follower = true
+}
+
 // SetConfigListenerChan initializes the configFromListenerChan.
 func (p *Provider) SetConfigListenerChan(configFromListenerChan chan dynamic.Configuration) {
 	p.configFromListenerChan = configFromListenerChan
@@ -170,6 +191,15 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 	p.pool = pool
 
+	if p.follower {
+		p.configurationChan = configurationChan
+		p.refreshCertificates()
+
+		p.watchStorage(ctx)
+		return nil
+	}
+
+	p.watchCluster(ctx)
 	p.watchCertificate(ctx)
 	p.watchNewDomains(ctx)
 
@@ -256,14 +286,22 @@ func (p *Provider) getClient() (*lego.Client, error) {
 	if p.DNSChallenge != nil && len(p.DNSChallenge.Provider) > 0 {
 		logger.Debugf("Using DNS Challenge provider: %s", p.DNSChallenge.Provider)
 
+		err = validatePropagation(p.DNSChallenge)
+		if err != nil {
+			return nil, err
+		}
+
 		var provider challenge.Provider
 		provider, err = dns.NewDNSChallengeProviderByName(p.DNSChallenge.Provider)
 		if err != nil {
 			return nil, err
 		}
 
-		err = client.Challenge.SetDNS01Provider(provider,
-			dns01.CondOption(len(p.DNSChallenge.Resolvers) > 0, dns01.AddRecursiveNameservers(p.DNSChallenge.Resolvers)),
+		resolvers := plainResolvers(p.DNSChallenge.Resolvers)
+		resolversClient := &http.Client{Timeout: dnsQueryTimeout}
+
+		err = client.Challenge.SetDNS01Provider(p.challenges.track(provider, challenge.DNS01),
+			dns01.CondOption(len(resolvers) > 0, dns01.AddRecursiveNameservers(resolvers)),
 			dns01.WrapPreCheck(func(domain, fqdn, value string, check dns01.PreCheckFunc) (bool, error) {
 				if p.DNSChallenge.DisablePropagationCheck {
 					return true, nil
@@ -274,6 +312,10 @@ func (p *Provider) getClient() (*lego.Client, error) {
 					time.Sleep(time.Duration(p.DNSChallenge.DelayBeforeCheck))
 				}
 
+				if p.DNSChallenge.PropagationStrategy == propagationResolvers {
+					return checkResolversPropagation(resolversClient, p.DNSChallenge.Resolvers, fqdn, value)
+				}
+
 				return check(fqdn, value)
 			}),
 		)
@@ -285,7 +327,7 @@ func (p *Provider) getClient() (*lego.Client, error) {
 	if p.HTTPChallenge != nil && len(p.HTTPChallenge.EntryPoint) > 0 {
 		logger.Debug("Using HTTP Challenge provider.")
 
-		err = client.Challenge.SetHTTP01Provider(&challengeHTTP{Store: p.ChallengeStore})
+		err = client.Challenge.SetHTTP01Provider(p.challenges.track(&challengeHTTP{Store: p.ChallengeStore}, challenge.HTTP01))
 		if err != nil {
 			return nil, err
 		}
@@ -294,7 +336,7 @@ func (p *Provider) getClient() (*lego.Client, error) {
 	if p.TLSChallenge != nil {
 		logger.Debug("Using TLS Challenge provider.")
 
-		err = client.Challenge.SetTLSALPN01Provider(&challengeTLSALPN{Store: p.ChallengeStore})
+		err = client.Challenge.SetTLSALPN01Provider(p.challenges.track(&challengeTLSALPN{Store: p.ChallengeStore}, challenge.TLSALPN01))
 		if err != nil {
 			return nil, err
 		}
@@ -440,6 +482,12 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 	defer p.removeResolvingDomains(uncheckedDomains)
 
 	logger := log.FromContext(ctx)
+
+	if !p.ownsCertificate(tlsStore, uncheckedDomains) && p.waitForCluster(ctx, tlsStore, uncheckedDomains) {
+		logger.Debugf("Certificates for domains %+v obtained by another instance of the cluster", uncheckedDomains)
+		return nil, nil
+	}
+
 	logger.Debugf("Loading ACME certificates %+v...", uncheckedDomains)
 
 	client, err := p.getClient()
@@ -453,7 +501,9 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 		MustStaple: oscpMustStaple,
 	}
 
+	start := time.Now()
 	cert, err := client.Certificate.Obtain(request)
+	p.recordIssue(operationObtain, domains, start, err)
 	if err != nil {
 		return nil, fmt.Errorf("unable to generate a certificate for the domains %v: %w", uncheckedDomains, err)
 	}
@@ -553,10 +603,13 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 		for {
 			select {
 			case cert := <-p.certsChan:
+				p.certificatesMutex.Lock()
 				certUpdated := false
-				for _, domainsCertificate := range p.certificates {
+				for i, domainsCertificate := range p.certificates {
 					if reflect.DeepEqual(cert.Domain, domainsCertificate.Certificate.Domain) {
-						domainsCertificate.Certificate = cert.Certificate
+						updated := *domainsCertificate
+						updated.Certificate = cert.Certificate
+						p.certificates[i] = &updated
 						certUpdated = true
 						break
 					}
@@ -564,11 +617,111 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 				if !certUpdated {
 					p.certificates = append(p.certificates, cert)
 				}
+				p.certificatesMutex.Unlock()
 
 				err := p.saveCertificates()
 				if err != nil {
 					log.FromContext(ctx).Error(err)
 				}
+
+				p.publishCertificate(ctx, cert)
+			case cert := <-p.clusterCertsChan:
+				if !p.loadClusterCertificate(ctx, cert) {
+					continue
+				}
+
+				log.FromContext(ctx).Debugf("Loading the certificate for the domains %v obtained by another instance of the cluster", cert.Domain.ToStrArray())
+
+				err := p.saveCertificates()
+				if err != nil {
+					log.FromContext(ctx).Error(err)
+				}
+			case <-ctxPool.Done():
+				return
+			}
+		}
+	})
+}
+
+// loadClusterCertificate stores the certificate obtained by another instance of the cluster,
+// unless the provider already has a more recent certificate for the same domains.
+// It reports whether the certificate was stored.
+func (p *Provider) loadClusterCertificate(ctx context.Context, cert *CertAndStore) bool {
+	p.certificatesMutex.Lock()
+	defer p.certificatesMutex.Unlock()
+
+	for i, domainsCertificate := range p.certificates {
+		if !reflect.DeepEqual(cert.Domain, domainsCertificate.Certificate.Domain) || cert.Store != domainsCertificate.Store {
+			continue
+		}
+
+		if !isMoreRecent(ctx, &cert.Certificate, &domainsCertificate.Certificate) {
+			return false
+		}
+
+		updated := *domainsCertificate
+		updated.Certificate = cert.Certificate
+		p.certificates[i] = &updated
+		return true
+	}
+
+	p.certificates = append(p.certificates, cert)
+	return true
+}
+
+// getCertificates returns a copy of the certificates slice, safe to range over without holding the lock.
+func (p *Provider) getCertificates() []*CertAndStore {
+	p.certificatesMutex.RLock()
+	defer p.certificatesMutex.RUnlock()
+
+	return append([]*CertAndStore(nil), p.certificates...)
+}
+
+// watchStorage reloads the certificates when another Traefik process updates the storage.
+func (p *Provider) watchStorage(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	store, ok := p.Store.(*LocalStore)
+	if !ok {
+		logger.Error("The certificates storage cannot be watched")
+		return
+	}
+
+	var lastModTime time.Time
+	if fi, err := os.Stat(p.Storage); err == nil {
+		lastModTime = fi.ModTime()
+	}
+
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		ticker := time.NewTicker(storageWatchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fi, err := os.Stat(p.Storage)
+				if err != nil || !fi.ModTime().After(lastModTime) {
+					continue
+				}
+				lastModTime = fi.ModTime()
+
+				store.invalidate()
+				certificates, err := store.GetCertificates(p.ResolverName)
+				if err != nil {
+					logger.Errorf("Unable to reload the ACME certificates: %v", err)
+					continue
+				}
+
+				logger.Debug("Reloading the ACME certificates updated by the certificates resolver")
+				p.certificatesMutex.Lock()
+				p.certificates = certificates
+				p.certificatesMutex.Unlock()
+
+				p.refreshCertificates()
+
+			case <-p.configFromListenerChan:
+				// The domains of the configuration are resolved by another Traefik process.
+
 			case <-ctxPool.Done():
 				return
 			}
@@ -577,7 +730,7 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 }
 
 func (p *Provider) saveCertificates() error {
-	err := p.Store.SaveCertificates(p.ResolverName, p.certificates)
+	err := p.Store.SaveCertificates(p.ResolverName, p.getCertificates())
 
 	p.refreshCertificates()
 
@@ -597,7 +750,7 @@ func (p *Provider) refreshCertificates() {
 		},
 	}
 
-	for _, cert := range p.certificates {
+	for _, cert := range p.getCertificates() {
 		certConf := &traefiktls.CertAndStores{
 			Certificate: traefiktls.Certificate{
 				CertFile: traefiktls.FileOrContent(cert.Certificate.Certificate),
@@ -615,11 +768,17 @@ func (p *Provider) renewCertificates(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
 	logger.Info("Testing certificate renew...")
-	for _, cert := range p.certificates {
+	for _, cert := range p.getCertificates() {
 		crt, err := getX509Certificate(ctx, &cert.Certificate)
 		// If there's an error, we assume the cert is broken, and needs update
 		// <= 30 days left, renew certificate
 		if err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(24*30*time.Hour)) {
+			// In cluster mode, the certificate is renewed by its owner,
+			// unless it is about to expire because its owner failed to renew it.
+			if crt != nil && !p.ownsCertificate(cert.Store, cert.Domain.ToStrArray()) && crt.NotAfter.After(time.Now().Add(clusterRenewFallback)) {
+				continue
+			}
+
 			client, err := p.getClient()
 			if err != nil {
 				logger.Infof("Error renewing certificate from LE : %+v, %v", cert.Domain, err)
@@ -628,11 +787,13 @@ func (p *Provider) renewCertificates(ctx context.Context) {
 
 			logger.Infof("Renewing certificate from LE : %+v", cert.Domain)
 
+			start := time.Now()
 			renewedCert, err := client.Certificate.Renew(certificate.Resource{
 				Domain:      cert.Domain.Main,
 				PrivateKey:  cert.Key,
 				Certificate: cert.Certificate.Certificate,
 			}, true, oscpMustStaple, p.PreferredChain)
+			p.recordIssue(operationRenew, cert.Domain.ToStrArray(), start, err)
 			if err != nil {
 				logger.Errorf("Error renewing certificate from LE: %v, %v", cert.Domain, err)
 				continue
@@ -659,7 +820,7 @@ func (p *Provider) getUncheckedDomains(ctx context.Context, domainsToCheck []str
 	allDomains := p.tlsManager.GetStore(tlsStore).GetAllDomains()
 
 	// Get ACME certificates
-	for _, cert := range p.certificates {
+	for _, cert := range p.getCertificates() {
 		allDomains = append(allDomains, strings.Join(cert.Domain.ToStrArray(), ","))
 	}
 
@@ -77,6 +77,14 @@ func NewProviderAggregator(conf static.Providers) ProviderAggregator {
 		p.quietAddProvider(conf.HTTP)
 	}
 
+	if conf.OCI != nil {
+		p.quietAddProvider(conf.OCI)
+	}
+
+	if conf.Git != nil {
+		p.quietAddProvider(conf.Git)
+	}
+
 	return p
 }
 
@@ -12,10 +12,12 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/job"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/provider"
 	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/hashicorp/consul/api"
 	ptypes "github.com/traefik/paerser/types"
 )
@@ -48,9 +50,11 @@ type Provider struct {
 	Cache             bool            `description:"Use local agent caching for catalog reads." json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" export:"true"`
 	ExposedByDefault  bool            `description:"Expose containers by default." json:"exposedByDefault,omitempty" toml:"exposedByDefault,omitempty" yaml:"exposedByDefault,omitempty" export:"true"`
 	DefaultRule       string          `description:"Default rule." json:"defaultRule,omitempty" toml:"defaultRule,omitempty" yaml:"defaultRule,omitempty"`
+	StaleTTL          ptypes.Duration `description:"How long the last discovered configuration is served while the Consul API is unreachable, before being dropped (0 to serve it until the API is reachable again)." json:"staleTTL,omitempty" toml:"staleTTL,omitempty" yaml:"staleTTL,omitempty" export:"true"`
 
-	client         *api.Client
-	defaultRuleTpl *template.Template
+	client          *api.Client
+	defaultRuleTpl  *template.Template
+	metricsRegistry metrics.Registry
 }
 
 // EndpointConfig holds configurations of the endpoint.
@@ -97,8 +101,20 @@ func (p *Provider) Init() error {
 	return nil
 }
 
+// SetMetricsRegistry sets the registry recording the staleness of the discovered configuration.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
+}
+
 // Provide allows the consul catalog provider to provide configurations to traefik using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	staleness := provider.NewStaleness("consulcatalog", time.Duration(p.StaleTTL), p.stalenessGauge())
+
+	pool.GoCtx(func(routineCtx context.Context) {
+		ctxLog := log.With(routineCtx, log.Str(log.ProviderName, "consulcatalog"))
+		staleness.Watch(ctxLog, time.Second, configurationChan)
+	})
+
 	pool.GoCtx(func(routineCtx context.Context) {
 		ctxLog := log.With(routineCtx, log.Str(log.ProviderName, "consulcatalog"))
 		logger := log.FromContext(ctxLog)
@@ -121,6 +137,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 						logger.Errorf("error get consul catalog data, %v", err)
 						return err
 					}
+					staleness.Refreshed()
 
 					configuration := p.buildConfiguration(routineCtx, data)
 					configurationChan <- dynamic.Message{
@@ -147,6 +164,14 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	return nil
 }
 
+// stalenessGauge returns the gauge recording the staleness of the discovered configuration, if any.
+func (p *Provider) stalenessGauge() gokitmetrics.Gauge {
+	if p.metricsRegistry == nil {
+		return nil
+	}
+	return p.metricsRegistry.ProviderConfigStalenessGauge()
+}
+
 func (p *Provider) getConsulServicesData(ctx context.Context) ([]itemData, error) {
 	consulServiceNames, err := p.fetchServices(ctx)
 	if err != nil {
@@ -18,6 +18,8 @@ import (
 func (p *Provider) buildConfiguration(ctx context.Context, containersInspected []dockerData) *dynamic.Configuration {
 	configurations := make(map[string]*dynamic.Configuration)
 
+	var issues []label.ProviderIssue
+
 	for _, container := range containersInspected {
 		containerName := getServiceName(container) + "-" + container.ID
 		ctxContainer := log.With(ctx, log.Str("container", containerName))
@@ -28,6 +30,11 @@ func (p *Provider) buildConfiguration(ctx context.Context, containersInspected [
 
 		logger := log.FromContext(ctxContainer)
 
+		for _, issue := range label.Lint(container.Labels, &dynamic.Configuration{}, &configuration{}) {
+			logger.WithField("label", issue.Key).Warnf("%s: %s", issue.Code, issue.Message)
+			issues = append(issues, label.ProviderIssue{Provider: "docker", Element: containerName, Issue: issue})
+		}
+
 		confFromLabel, err := label.DecodeConfiguration(container.Labels)
 		if err != nil {
 			logger.Error(err)
@@ -85,6 +92,8 @@ func (p *Provider) buildConfiguration(ctx context.Context, containersInspected [
 		configurations[containerName] = confFromLabel
 	}
 
+	label.GetIssueRegistry().Set("docker", issues)
+
 	return provider.Merge(ctx, configurations)
 }
 
@@ -18,17 +18,21 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/job"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/provider"
 	"github.com/containous/traefik/v2/pkg/safe"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/patrickmn/go-cache"
+	ptypes "github.com/traefik/paerser/types"
 )
 
 // Provider holds configurations of the provider.
 type Provider struct {
-	Constraints      string `description:"Constraints is an expression that Traefik matches against the container's labels to determine whether to create any route for that container." json:"constraints,omitempty" toml:"constraints,omitempty" yaml:"constraints,omitempty" export:"true"`
-	ExposedByDefault bool   `description:"Expose services by default" json:"exposedByDefault,omitempty" toml:"exposedByDefault,omitempty" yaml:"exposedByDefault,omitempty" export:"true"`
-	RefreshSeconds   int    `description:"Polling interval (in seconds)" json:"refreshSeconds,omitempty" toml:"refreshSeconds,omitempty" yaml:"refreshSeconds,omitempty" export:"true"`
-	DefaultRule      string `description:"Default rule." json:"defaultRule,omitempty" toml:"defaultRule,omitempty" yaml:"defaultRule,omitempty"`
+	Constraints      string          `description:"Constraints is an expression that Traefik matches against the container's labels to determine whether to create any route for that container." json:"constraints,omitempty" toml:"constraints,omitempty" yaml:"constraints,omitempty" export:"true"`
+	ExposedByDefault bool            `description:"Expose services by default" json:"exposedByDefault,omitempty" toml:"exposedByDefault,omitempty" yaml:"exposedByDefault,omitempty" export:"true"`
+	RefreshSeconds   int             `description:"Polling interval (in seconds)" json:"refreshSeconds,omitempty" toml:"refreshSeconds,omitempty" yaml:"refreshSeconds,omitempty" export:"true"`
+	DefaultRule      string          `description:"Default rule." json:"defaultRule,omitempty" toml:"defaultRule,omitempty" yaml:"defaultRule,omitempty"`
+	StaleTTL         ptypes.Duration `description:"How long the last discovered configuration is served while the AWS API is unreachable, before being dropped (0 to serve it until the API is reachable again)." json:"staleTTL,omitempty" toml:"staleTTL,omitempty" yaml:"staleTTL,omitempty" export:"true"`
 
 	// Provider lookup parameters.
 	Clusters             []string `description:"ECS Clusters name" json:"clusters,omitempty" toml:"clusters,omitempty" yaml:"clusters,omitempty" export:"true"`
@@ -37,6 +41,7 @@ type Provider struct {
 	AccessKeyID          string   `description:"The AWS credentials access key to use for making requests" json:"accessKeyID,omitempty" toml:"accessKeyID,omitempty" yaml:"accessKeyID,omitempty"`
 	SecretAccessKey      string   `description:"The AWS credentials access key to use for making requests" json:"secretAccessKey,omitempty" toml:"secretAccessKey,omitempty" yaml:"secretAccessKey,omitempty"`
 	defaultRuleTpl       *template.Template
+	metricsRegistry      metrics.Registry
 }
 
 type ecsInstance struct {
@@ -94,6 +99,19 @@ func (p *Provider) Init() error {
 	return nil
 }
 
+// SetMetricsRegistry sets the registry recording the staleness of the discovered configuration.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
+}
+
+// stalenessGauge returns the gauge recording the staleness of the discovered configuration, if any.
+func (p *Provider) stalenessGauge() gokitmetrics.Gauge {
+	if p.metricsRegistry == nil {
+		return nil
+	}
+	return p.metricsRegistry.ProviderConfigStalenessGauge()
+}
+
 func (p *Provider) createClient(logger log.Logger) (*awsClient, error) {
 	sess, err := session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
@@ -144,6 +162,12 @@ func (p *Provider) createClient(logger log.Logger) (*awsClient, error) {
 
 // Provide configuration to traefik from ECS.
 func (p Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	staleness := provider.NewStaleness("ecs", time.Duration(p.StaleTTL), p.stalenessGauge())
+
+	pool.GoCtx(func(routineCtx context.Context) {
+		staleness.Watch(log.With(routineCtx, log.Str(log.ProviderName, "ecs")), time.Second, configurationChan)
+	})
+
 	pool.GoCtx(func(routineCtx context.Context) {
 		ctxLog := log.With(routineCtx, log.Str(log.ProviderName, "ecs"))
 		logger := log.FromContext(ctxLog)
@@ -158,6 +182,7 @@ func (p Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.P
 			if err != nil {
 				return err
 			}
+			staleness.Refreshed()
 
 			configurationChan <- dynamic.Message{
 				ProviderName:  "ecs",
@@ -175,6 +200,7 @@ func (p Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.P
 						logger.Errorf("Failed to load ECS configuration, error %s", err)
 						return err
 					}
+					staleness.Refreshed()
 
 					configurationChan <- dynamic.Message{
 						ProviderName:  "ecs",
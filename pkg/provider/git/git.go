@@ -0,0 +1,299 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/job"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/file"
+	"github.com/containous/traefik/v2/pkg/safe"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+const providerName = "git"
+
+// minGitVersion is the minimum version of the git command, the first one reading its configuration from the environment (GIT_CONFIG_COUNT).
+var minGitVersion = [2]int{2, 31}
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Provider is a provider.Provider implementation that loads the configuration files of a Git repository.
+// The repository is fetched with the git command, which must be available on the host.
+type Provider struct {
+	Repository    string          `description:"URL of the Git repository." json:"repository,omitempty" toml:"repository,omitempty" yaml:"repository,omitempty" export:"true"`
+	Ref           string          `description:"Branch or tag to check out, the default branch of the repository by default." json:"ref,omitempty" toml:"ref,omitempty" yaml:"ref,omitempty" export:"true"`
+	Path          string          `description:"Directory of the repository holding the configuration files." json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+	Username      string          `description:"Username of the token authentication." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Token         string          `description:"Token authenticating the HTTPS fetches." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	SSHKey        string          `description:"Path to the private key authenticating the SSH fetches." json:"sshKey,omitempty" toml:"sshKey,omitempty" yaml:"sshKey,omitempty"`
+	KnownHosts    string          `description:"Path to the known_hosts file checking the SSH host keys." json:"knownHosts,omitempty" toml:"knownHosts,omitempty" yaml:"knownHosts,omitempty"`
+	Directory     string          `description:"Local directory the repository is fetched into, a temporary directory by default." json:"directory,omitempty" toml:"directory,omitempty" yaml:"directory,omitempty" export:"true"`
+	PollInterval  ptypes.Duration `description:"Polling interval for the repository." json:"pollInterval,omitempty" toml:"pollInterval,omitempty" yaml:"pollInterval,omitempty" export:"true"`
+	PollTimeout   ptypes.Duration `description:"Polling timeout for the repository." json:"pollTimeout,omitempty" toml:"pollTimeout,omitempty" yaml:"pollTimeout,omitempty" export:"true"`
+	WebhookSecret string          `description:"Secret of the webhook, served by the git@internal service, triggering a refresh." json:"webhookSecret,omitempty" toml:"webhookSecret,omitempty" yaml:"webhookSecret,omitempty"`
+
+	gitDir     string
+	workTree   string
+	refresh    chan struct{}
+	lastCommit string
+}
+
+// SetDefaults sets the default values.
+func (p *Provider) SetDefaults() {
+	p.PollInterval = ptypes.Duration(time.Minute)
+	p.PollTimeout = ptypes.Duration(time.Minute)
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	if p.Repository == "" {
+		return errors.New("non-empty repository is required")
+	}
+
+	if p.PollInterval <= 0 {
+		return errors.New("poll interval must be greater than 0")
+	}
+
+	if err := checkGitVersion(); err != nil {
+		return err
+	}
+
+	directory := p.Directory
+	if directory == "" {
+		var err error
+		directory, err = ioutil.TempDir("", "traefik-git-")
+		if err != nil {
+			return fmt.Errorf("unable to create the directory of the repository: %w", err)
+		}
+	}
+
+	// The Git directory is kept out of the work tree, so that it is not walked when loading the configuration files.
+	p.gitDir = filepath.Join(directory, "git")
+	p.workTree = filepath.Join(directory, "worktree")
+
+	p.refresh = make(chan struct{}, 1)
+
+	return nil
+}
+
+// Provide allows the provider to provide configurations to traefik using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	pool.GoCtx(func(routineCtx context.Context) {
+		ctxLog := log.With(routineCtx, log.Str(log.ProviderName, providerName))
+		logger := log.FromContext(ctxLog)
+
+		operation := func() error {
+			ticker := time.NewTicker(time.Duration(p.PollInterval))
+			defer ticker.Stop()
+
+			for {
+				configuration, err := p.pull(ctxLog)
+				if err != nil {
+					return fmt.Errorf("cannot pull the repository %s: %w", p.Repository, err)
+				}
+
+				if configuration != nil {
+					configurationChan <- dynamic.Message{
+						ProviderName:  providerName,
+						Configuration: configuration,
+					}
+				}
+
+				select {
+				case <-ticker.C:
+				case <-p.refresh:
+					logger.Debug("Refreshing the repository on webhook")
+				case <-routineCtx.Done():
+					return nil
+				}
+			}
+		}
+
+		notify := func(err error, time time.Duration) {
+			logger.Errorf("Provider connection error %+v, retrying in %s", err, time)
+		}
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
+		if err != nil {
+			logger.Errorf("Cannot pull the repository %+v", err)
+		}
+	})
+
+	return nil
+}
+
+// pull returns the configuration of the repository, or nil when the checked out commit did not change since the last pull.
+func (p *Provider) pull(ctx context.Context) (*dynamic.Configuration, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.PollTimeout))
+	defer cancel()
+
+	if _, err := os.Stat(filepath.Join(p.gitDir, "HEAD")); os.IsNotExist(err) {
+		if _, err = p.git(ctx, "init", "--quiet", "--bare", p.gitDir); err != nil {
+			return nil, err
+		}
+	}
+
+	ref := p.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	if _, err := p.git(ctx, "--git-dir", p.gitDir, "fetch", "--quiet", "--depth", "1", "--no-tags", p.Repository, ref); err != nil {
+		return nil, err
+	}
+
+	commit, err := p.git(ctx, "--git-dir", p.gitDir, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	if commit == p.lastCommit {
+		return nil, nil
+	}
+
+	if err = os.MkdirAll(p.workTree, 0o700); err != nil {
+		return nil, err
+	}
+
+	if _, err = p.git(ctx, "--git-dir", p.gitDir, "--work-tree", p.workTree, "checkout", "--quiet", "--force", "--detach", commit); err != nil {
+		return nil, err
+	}
+
+	// The configuration files are loaded the same way as the file provider loads a directory.
+	fileProvider := &file.Provider{Directory: filepath.Join(p.workTree, p.Path)}
+
+	configuration, err := fileProvider.BuildConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the configuration of the commit %s: %w", commit, err)
+	}
+
+	log.FromContext(ctx).Infof("Checked out the configuration of the commit %s", commit)
+	p.lastCommit = commit
+
+	return configuration, nil
+}
+
+// git runs the git command and returns its trimmed output.
+func (p *Provider) git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = p.env()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", gitSubcommand(args), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// env returns the environment of the git command, holding the credentials,
+// so that they are neither written in the Git directory nor visible in the command line.
+func (p *Provider) env() []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if p.Token != "" {
+		username := p.Username
+		if username == "" {
+			username = "git"
+		}
+
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + p.Token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	if p.SSHKey != "" || p.KnownHosts != "" {
+		command := "ssh -o BatchMode=yes"
+		if p.SSHKey != "" {
+			command += " -o IdentitiesOnly=yes -i " + shellQuote(p.SSHKey)
+		}
+		if p.KnownHosts != "" {
+			command += " -o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + shellQuote(p.KnownHosts)
+		}
+
+		env = append(env, "GIT_SSH_COMMAND="+command)
+	}
+
+	return env
+}
+
+// checkGitVersion checks that the git command is available, with the minimum version.
+func checkGitVersion() error {
+	output, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return fmt.Errorf("the git command, version %d.%d or later, is required: %w", minGitVersion[0], minGitVersion[1], err)
+	}
+
+	version, err := parseGitVersion(string(output))
+	if err != nil {
+		return err
+	}
+
+	if version[0] < minGitVersion[0] || version[0] == minGitVersion[0] && version[1] < minGitVersion[1] {
+		return fmt.Errorf("the git command, version %d.%d or later, is required: found version %d.%d", minGitVersion[0], minGitVersion[1], version[0], version[1])
+	}
+
+	return nil
+}
+
+// parseGitVersion returns the major and minor versions of the output of git --version,
+// e.g. "git version 2.32.0" or "git version 2.39.2 (Apple Git-143)".
+func parseGitVersion(output string) ([2]int, error) {
+	var version [2]int
+
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return version, fmt.Errorf("unexpected output of git --version: %q", strings.TrimSpace(output))
+	}
+
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return version, fmt.Errorf("unexpected git version %q", fields[2])
+	}
+
+	for i := range version {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return version, fmt.Errorf("unexpected git version %q", fields[2])
+		}
+		version[i] = n
+	}
+
+	return version, nil
+}
+
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--git-dir" || args[i] == "--work-tree" {
+			i++
+			continue
+		}
+
+		return args[i]
+	}
+
+	return ""
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
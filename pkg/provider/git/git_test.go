@@ -0,0 +1,248 @@
+package git
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestProvider_pull(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repository := createRepository(t)
+	writeConfiguration(t, repository, "foo")
+	commit(t, repository, "foo")
+
+	provider := &Provider{
+		Repository:   "file://" + repository,
+		Path:         "dynamic",
+		Directory:    t.TempDir(),
+		PollInterval: ptypes.Duration(time.Minute),
+		PollTimeout:  ptypes.Duration(time.Minute),
+	}
+	require.NoError(t, provider.Init())
+
+	configuration, err := provider.pull(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, configuration)
+	assert.Contains(t, configuration.HTTP.Routers, "foo")
+
+	// The commit did not change.
+	configuration, err = provider.pull(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, configuration)
+
+	writeConfiguration(t, repository, "bar")
+	commit(t, repository, "bar")
+
+	configuration, err = provider.pull(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, configuration)
+	assert.Contains(t, configuration.HTTP.Routers, "bar")
+	assert.NotContains(t, configuration.HTTP.Routers, "foo")
+}
+
+func TestProvider_pull_tag(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repository := createRepository(t)
+	writeConfiguration(t, repository, "foo")
+	commit(t, repository, "foo")
+	runGit(t, repository, "tag", "v1")
+	writeConfiguration(t, repository, "bar")
+	commit(t, repository, "bar")
+
+	provider := &Provider{
+		Repository:   "file://" + repository,
+		Ref:          "v1",
+		Path:         "dynamic",
+		Directory:    t.TempDir(),
+		PollInterval: ptypes.Duration(time.Minute),
+		PollTimeout:  ptypes.Duration(time.Minute),
+	}
+	require.NoError(t, provider.Init())
+
+	configuration, err := provider.pull(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, configuration)
+	assert.Contains(t, configuration.HTTP.Routers, "foo")
+}
+
+func TestParseGitVersion(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		output   string
+		expected [2]int
+		expErr   bool
+	}{
+		{
+			desc:     "release",
+			output:   "git version 2.32.0\n",
+			expected: [2]int{2, 32},
+		},
+		{
+			desc:     "vendor suffix",
+			output:   "git version 2.39.2 (Apple Git-143)\n",
+			expected: [2]int{2, 39},
+		},
+		{
+			desc:     "windows build",
+			output:   "git version 2.31.1.windows.1\n",
+			expected: [2]int{2, 31},
+		},
+		{
+			desc:   "unexpected output",
+			output: "command not found",
+			expErr: true,
+		},
+		{
+			desc:   "unexpected version",
+			output: "git version two",
+			expErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := parseGitVersion(test.output)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+func TestProvider_ServeHTTP(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte("payload"))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	testCases := []struct {
+		desc       string
+		method     string
+		header     http.Header
+		expCode    int
+		expRefresh bool
+	}{
+		{
+			desc:       "valid signature",
+			method:     http.MethodPost,
+			header:     http.Header{"X-Hub-Signature-256": []string{signature}},
+			expCode:    http.StatusAccepted,
+			expRefresh: true,
+		},
+		{
+			desc:    "invalid signature",
+			method:  http.MethodPost,
+			header:  http.Header{"X-Hub-Signature-256": []string{"sha256=" + strings.Repeat("0", 64)}},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:       "valid token",
+			method:     http.MethodPost,
+			header:     http.Header{"X-Gitlab-Token": []string{"secret"}},
+			expCode:    http.StatusAccepted,
+			expRefresh: true,
+		},
+		{
+			desc:    "invalid token",
+			method:  http.MethodPost,
+			header:  http.Header{"X-Gitlab-Token": []string{"foo"}},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "missing authentication",
+			method:  http.MethodPost,
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "invalid method",
+			method:  http.MethodGet,
+			header:  http.Header{"X-Gitlab-Token": []string{"secret"}},
+			expCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &Provider{
+				WebhookSecret: "secret",
+				refresh:       make(chan struct{}, 1),
+			}
+
+			req := httptest.NewRequest(test.method, "/", strings.NewReader("payload"))
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+
+			rw := httptest.NewRecorder()
+			provider.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expCode, rw.Code)
+			assert.Equal(t, test.expRefresh, len(provider.refresh) == 1)
+		})
+	}
+}
+
+func createRepository(t *testing.T) string {
+	t.Helper()
+
+	repository := t.TempDir()
+	runGit(t, repository, "init", "--quiet")
+	runGit(t, repository, "config", "user.email", "test@example.com")
+	runGit(t, repository, "config", "user.name", "test")
+
+	return repository
+}
+
+func writeConfiguration(t *testing.T, repository, routerName string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repository, "dynamic"), 0o755))
+
+	content := "[http.routers." + routerName + "]\n  rule = \"Host(`" + routerName + ".localhost`)\"\n  service = \"whoami\"\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repository, "dynamic", "routers.toml"), []byte(content), 0o600))
+}
+
+func commit(t *testing.T, repository, message string) {
+	t.Helper()
+
+	runGit(t, repository, "add", "--all")
+	runGit(t, repository, "commit", "--quiet", "--message", message)
+}
+
+func runGit(t *testing.T, repository string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", repository}, args...)...)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// maxWebhookBodySize is the size of the largest webhook payload whose signature is checked.
+const maxWebhookBodySize = 10 * 1024 * 1024
+
+// ServeHTTP triggers a refresh of the repository on the webhook requests authenticated with the webhook secret,
+// either by the HMAC signature of their payload (X-Hub-Signature-256 header, as sent by GitHub or Gitea),
+// or by the secret token (X-Gitlab-Token header, as sent by GitLab).
+func (p *Provider) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !p.authenticateWebhook(req, body) {
+		log.WithoutContext().WithField(log.ProviderName, providerName).Debug("Rejecting an unauthenticated webhook request")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// The refreshes requested while a pull is pending are merged into it.
+	select {
+	case p.refresh <- struct{}{}:
+	default:
+	}
+
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+func (p *Provider) authenticateWebhook(req *http.Request, body []byte) bool {
+	if p.WebhookSecret == "" {
+		return false
+	}
+
+	if signature := req.Header.Get("X-Hub-Signature-256"); signature != "" {
+		expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, []byte(p.WebhookSecret))
+		_, _ = mac.Write(body)
+
+		return hmac.Equal(mac.Sum(nil), expected)
+	}
+
+	if token := req.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(p.WebhookSecret)) == 1
+	}
+
+	return false
+}
@@ -56,6 +56,7 @@ type Client interface {
 
 	GetService(namespace, name string) (*corev1.Service, bool, error)
 	GetSecret(namespace, name string) (*corev1.Secret, bool, error)
+	GetSecrets(selector labels.Selector) []*corev1.Secret
 	GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error)
 }
 
@@ -342,6 +343,21 @@ func (c *clientWrapper) GetSecret(namespace, name string) (*corev1.Secret, bool,
 	return secret, exist, err
 }
 
+// GetSecrets returns the secrets matching the selector, in the observed namespaces.
+func (c *clientWrapper) GetSecrets(selector labels.Selector) []*corev1.Secret {
+	var result []*corev1.Secret
+
+	for ns, factory := range c.factoriesKube {
+		secrets, err := factory.Core().V1().Secrets().Lister().List(selector)
+		if err != nil {
+			log.Errorf("Failed to list secrets in namespace %s: %v", ns, err)
+		}
+		result = append(result, secrets...)
+	}
+
+	return result
+}
+
 // lookupNamespace returns the lookup namespace key for the given namespace.
 // When listening on all namespaces, it returns the client-go identifier ("")
 // for all-namespaces. Otherwise, it returns the given namespace.
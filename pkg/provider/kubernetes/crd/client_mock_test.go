@@ -8,6 +8,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/k8s"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -170,6 +171,16 @@ func (c clientMock) GetSecret(namespace, name string) (*corev1.Secret, bool, err
 	return nil, false, nil
 }
 
+func (c clientMock) GetSecrets(selector labels.Selector) []*corev1.Secret {
+	var result []*corev1.Secret
+	for _, secret := range c.secrets {
+		if selector.Matches(labels.Set(secret.Labels)) {
+			result = append(result, secret)
+		}
+	}
+	return result
+}
+
 func (c clientMock) WatchAll(namespaces []string, stopCh <-chan struct{}) (<-chan interface{}, error) {
 	return c.watchChan, nil
 }
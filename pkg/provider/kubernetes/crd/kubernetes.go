@@ -18,6 +18,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
+	"github.com/containous/traefik/v2/pkg/provider/kubernetes/k8s"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/mitchellh/hashstructure"
@@ -46,6 +47,7 @@ type Provider struct {
 	LabelSelector          string          `description:"Kubernetes label selector to use." json:"labelSelector,omitempty" toml:"labelSelector,omitempty" yaml:"labelSelector,omitempty" export:"true"`
 	IngressClass           string          `description:"Value of kubernetes.io/ingress.class annotation to watch for." json:"ingressClass,omitempty" toml:"ingressClass,omitempty" yaml:"ingressClass,omitempty" export:"true"`
 	ThrottleDuration       ptypes.Duration `description:"Ingress refresh throttle duration" json:"throttleDuration,omitempty" toml:"throttleDuration,omitempty" yaml:"throttleDuration,omitempty"`
+	TLSSecrets             *k8s.TLSSecrets `description:"Load the certificates of the labelled Secrets into a TLS store." json:"tlsSecrets,omitempty" toml:"tlsSecrets,omitempty" yaml:"tlsSecrets,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	lastConfiguration      safe.Safe
 }
 
@@ -83,6 +85,12 @@ func (p *Provider) newK8sClient(ctx context.Context, labelSelector string) (*cli
 
 // Init the provider.
 func (p *Provider) Init() error {
+	if p.TLSSecrets != nil {
+		if _, err := p.TLSSecrets.Selector(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -169,7 +177,7 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 		TCP:  p.loadIngressRouteTCPConfiguration(ctx, client, tlsConfigs),
 		UDP:  p.loadIngressRouteUDPConfiguration(ctx, client),
 		TLS: &dynamic.TLSConfiguration{
-			Certificates: getTLSConfig(tlsConfigs),
+			Certificates: append(getTLSConfig(tlsConfigs), p.loadTLSSecrets(ctx, client)...),
 			Options:      buildTLSOptions(ctx, client),
 			Stores:       buildTLSStores(ctx, client),
 		},
@@ -210,29 +218,38 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 		}
 
 		conf.HTTP.Middlewares[id] = &dynamic.Middleware{
-			AddPrefix:         middleware.Spec.AddPrefix,
-			StripPrefix:       middleware.Spec.StripPrefix,
-			StripPrefixRegex:  middleware.Spec.StripPrefixRegex,
-			ReplacePath:       middleware.Spec.ReplacePath,
-			ReplacePathRegex:  middleware.Spec.ReplacePathRegex,
-			Chain:             createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
-			IPWhiteList:       middleware.Spec.IPWhiteList,
-			Headers:           middleware.Spec.Headers,
-			Errors:            errorPage,
-			RateLimit:         middleware.Spec.RateLimit,
-			RedirectRegex:     middleware.Spec.RedirectRegex,
-			RedirectScheme:    middleware.Spec.RedirectScheme,
-			BasicAuth:         basicAuth,
-			DigestAuth:        digestAuth,
-			ForwardAuth:       forwardAuth,
-			InFlightReq:       middleware.Spec.InFlightReq,
-			Buffering:         middleware.Spec.Buffering,
-			CircuitBreaker:    middleware.Spec.CircuitBreaker,
-			Compress:          middleware.Spec.Compress,
-			PassTLSClientCert: middleware.Spec.PassTLSClientCert,
-			Retry:             middleware.Spec.Retry,
-			ContentType:       middleware.Spec.ContentType,
-			Plugin:            middleware.Spec.Plugin,
+			AddPrefix:           middleware.Spec.AddPrefix,
+			StripPrefix:         middleware.Spec.StripPrefix,
+			StripPrefixRegex:    middleware.Spec.StripPrefixRegex,
+			ReplacePath:         middleware.Spec.ReplacePath,
+			ReplacePathRegex:    middleware.Spec.ReplacePathRegex,
+			Chain:               createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
+			IPWhiteList:         middleware.Spec.IPWhiteList,
+			Headers:             middleware.Spec.Headers,
+			Errors:              errorPage,
+			RateLimit:           middleware.Spec.RateLimit,
+			RedirectRegex:       middleware.Spec.RedirectRegex,
+			RedirectScheme:      middleware.Spec.RedirectScheme,
+			BasicAuth:           basicAuth,
+			DigestAuth:          digestAuth,
+			ForwardAuth:         forwardAuth,
+			InFlightReq:         middleware.Spec.InFlightReq,
+			Buffering:           middleware.Spec.Buffering,
+			CircuitBreaker:      middleware.Spec.CircuitBreaker,
+			Compress:            middleware.Spec.Compress,
+			PassTLSClientCert:   middleware.Spec.PassTLSClientCert,
+			Retry:               middleware.Spec.Retry,
+			ContentType:         middleware.Spec.ContentType,
+			OPA:                 middleware.Spec.OPA,
+			SPNEGOAuth:          middleware.Spec.SPNEGOAuth,
+			LDAPAuth:            middleware.Spec.LDAPAuth,
+			SAML:                middleware.Spec.SAML,
+			EarlyHints:          middleware.Spec.EarlyHints,
+			S3:                  middleware.Spec.S3,
+			AdaptiveConcurrency: middleware.Spec.AdaptiveConcurrency,
+			QoS:                 middleware.Spec.QoS,
+			Plugin:              middleware.Spec.Plugin,
+			When:                middleware.Spec.When,
 		}
 	}
 
@@ -287,8 +304,9 @@ func createErrorPageMiddleware(client Client, namespace string, errorPage *v1alp
 	}
 
 	errorPageMiddleware := &dynamic.ErrorPage{
-		Status: errorPage.Status,
-		Query:  errorPage.Query,
+		Status:    errorPage.Status,
+		Query:     errorPage.Query,
+		Templates: errorPage.Templates,
 	}
 
 	balancerServerHTTP, err := configBuilder{client}.buildServersLB(namespace, errorPage.Service.LoadBalancerSpec)
@@ -311,6 +329,11 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *v1alp
 		Address:             auth.Address,
 		TrustForwardHeader:  auth.TrustForwardHeader,
 		AuthResponseHeaders: auth.AuthResponseHeaders,
+		AuthRequestHeaders:  auth.AuthRequestHeaders,
+		ForwardBody:         auth.ForwardBody,
+		MaxBodySize:         auth.MaxBodySize,
+		GRPC:                auth.GRPC,
+		SPIFFE:              auth.SPIFFE,
 	}
 
 	if auth.TLS == nil {
@@ -490,7 +513,7 @@ func createChainMiddleware(ctx context.Context, namespace string, chain *v1alpha
 		}
 		mds = append(mds, makeID(ns, mi.Name))
 	}
-	return &dynamic.Chain{Middlewares: mds}
+	return &dynamic.Chain{Middlewares: mds, Variables: chain.Variables}
 }
 
 func buildTLSOptions(ctx context.Context, client Client) map[string]tls.Options {
@@ -655,6 +678,21 @@ func getTLS(k8sClient Client, secretName, namespace string) (*tls.CertAndStores,
 	}, nil
 }
 
+// loadTLSSecrets returns the certificates of the Secrets labelled to be loaded into a TLS store.
+func (p *Provider) loadTLSSecrets(ctx context.Context, client Client) []*tls.CertAndStores {
+	if p.TLSSecrets == nil {
+		return nil
+	}
+
+	selector, err := p.TLSSecrets.Selector()
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+		return nil
+	}
+
+	return p.TLSSecrets.Certificates(ctx, client.GetSecrets(selector))
+}
+
 func getTLSConfig(tlsConfigs map[string]*tls.CertAndStores) []*tls.CertAndStores {
 	var secretNames []string
 	for secretName := range tlsConfigs {
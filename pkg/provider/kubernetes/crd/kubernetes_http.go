@@ -262,6 +262,9 @@ func (c configBuilder) buildServersLB(namespace string, svc v1alpha1.LoadBalance
 		lb.PassHostHeader = &passHostHeader
 	}
 	lb.ResponseForwarding = conf.ResponseForwarding
+	lb.Coalescing = conf.Coalescing
+	lb.QoS = conf.QoS
+	lb.PathNormalization = conf.PathNormalization
 
 	lb.Sticky = svc.Sticky
 
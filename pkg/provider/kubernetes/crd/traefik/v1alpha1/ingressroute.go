@@ -72,6 +72,9 @@ type LoadBalancerSpec struct {
 	Strategy           string                      `json:"strategy,omitempty"`
 	PassHostHeader     *bool                       `json:"passHostHeader,omitempty"`
 	ResponseForwarding *dynamic.ResponseForwarding `json:"responseForwarding,omitempty"`
+	Coalescing         *dynamic.Coalescing         `json:"coalescing,omitempty"`
+	QoS                *dynamic.QoS                `json:"qos,omitempty"`
+	PathNormalization  *dynamic.PathNormalization  `json:"pathNormalization,omitempty"`
 
 	// Weight should only be specified when Name references a TraefikService object
 	// (and to be precise, one that embeds a Weighted Round Robin).
@@ -20,45 +20,56 @@ type Middleware struct {
 
 // MiddlewareSpec holds the Middleware configuration.
 type MiddlewareSpec struct {
-	AddPrefix         *dynamic.AddPrefix            `json:"addPrefix,omitempty"`
-	StripPrefix       *dynamic.StripPrefix          `json:"stripPrefix,omitempty"`
-	StripPrefixRegex  *dynamic.StripPrefixRegex     `json:"stripPrefixRegex,omitempty"`
-	ReplacePath       *dynamic.ReplacePath          `json:"replacePath,omitempty"`
-	ReplacePathRegex  *dynamic.ReplacePathRegex     `json:"replacePathRegex,omitempty"`
-	Chain             *Chain                        `json:"chain,omitempty"`
-	IPWhiteList       *dynamic.IPWhiteList          `json:"ipWhiteList,omitempty"`
-	Headers           *dynamic.Headers              `json:"headers,omitempty"`
-	Errors            *ErrorPage                    `json:"errors,omitempty"`
-	RateLimit         *dynamic.RateLimit            `json:"rateLimit,omitempty"`
-	RedirectRegex     *dynamic.RedirectRegex        `json:"redirectRegex,omitempty"`
-	RedirectScheme    *dynamic.RedirectScheme       `json:"redirectScheme,omitempty"`
-	BasicAuth         *BasicAuth                    `json:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth                   `json:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth                  `json:"forwardAuth,omitempty"`
-	InFlightReq       *dynamic.InFlightReq          `json:"inFlightReq,omitempty"`
-	Buffering         *dynamic.Buffering            `json:"buffering,omitempty"`
-	CircuitBreaker    *dynamic.CircuitBreaker       `json:"circuitBreaker,omitempty"`
-	Compress          *dynamic.Compress             `json:"compress,omitempty"`
-	PassTLSClientCert *dynamic.PassTLSClientCert    `json:"passTLSClientCert,omitempty"`
-	Retry             *dynamic.Retry                `json:"retry,omitempty"`
-	ContentType       *dynamic.ContentType          `json:"contentType,omitempty"`
-	Plugin            map[string]dynamic.PluginConf `json:"plugin,omitempty"`
+	AddPrefix           *dynamic.AddPrefix            `json:"addPrefix,omitempty"`
+	StripPrefix         *dynamic.StripPrefix          `json:"stripPrefix,omitempty"`
+	StripPrefixRegex    *dynamic.StripPrefixRegex     `json:"stripPrefixRegex,omitempty"`
+	ReplacePath         *dynamic.ReplacePath          `json:"replacePath,omitempty"`
+	ReplacePathRegex    *dynamic.ReplacePathRegex     `json:"replacePathRegex,omitempty"`
+	Chain               *Chain                        `json:"chain,omitempty"`
+	IPWhiteList         *dynamic.IPWhiteList          `json:"ipWhiteList,omitempty"`
+	Headers             *dynamic.Headers              `json:"headers,omitempty"`
+	Errors              *ErrorPage                    `json:"errors,omitempty"`
+	RateLimit           *dynamic.RateLimit            `json:"rateLimit,omitempty"`
+	RedirectRegex       *dynamic.RedirectRegex        `json:"redirectRegex,omitempty"`
+	RedirectScheme      *dynamic.RedirectScheme       `json:"redirectScheme,omitempty"`
+	BasicAuth           *BasicAuth                    `json:"basicAuth,omitempty"`
+	DigestAuth          *DigestAuth                   `json:"digestAuth,omitempty"`
+	ForwardAuth         *ForwardAuth                  `json:"forwardAuth,omitempty"`
+	InFlightReq         *dynamic.InFlightReq          `json:"inFlightReq,omitempty"`
+	Buffering           *dynamic.Buffering            `json:"buffering,omitempty"`
+	CircuitBreaker      *dynamic.CircuitBreaker       `json:"circuitBreaker,omitempty"`
+	Compress            *dynamic.Compress             `json:"compress,omitempty"`
+	PassTLSClientCert   *dynamic.PassTLSClientCert    `json:"passTLSClientCert,omitempty"`
+	Retry               *dynamic.Retry                `json:"retry,omitempty"`
+	ContentType         *dynamic.ContentType          `json:"contentType,omitempty"`
+	OPA                 *dynamic.OPA                  `json:"opa,omitempty"`
+	SPNEGOAuth          *dynamic.SPNEGOAuth           `json:"spnegoAuth,omitempty"`
+	LDAPAuth            *dynamic.LDAPAuth             `json:"ldapAuth,omitempty"`
+	SAML                *dynamic.SAML                 `json:"saml,omitempty"`
+	EarlyHints          *dynamic.EarlyHints           `json:"earlyHints,omitempty"`
+	S3                  *dynamic.S3                   `json:"s3,omitempty"`
+	AdaptiveConcurrency *dynamic.AdaptiveConcurrency  `json:"adaptiveConcurrency,omitempty"`
+	QoS                 *dynamic.QoS                  `json:"qos,omitempty"`
+	Plugin              map[string]dynamic.PluginConf `json:"plugin,omitempty"`
+	When                string                        `json:"when,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
 
 // ErrorPage holds the custom error page configuration.
 type ErrorPage struct {
-	Status  []string `json:"status,omitempty"`
-	Service Service  `json:"service,omitempty"`
-	Query   string   `json:"query,omitempty"`
+	Status    []string                    `json:"status,omitempty"`
+	Service   Service                     `json:"service,omitempty"`
+	Query     string                      `json:"query,omitempty"`
+	Templates []dynamic.ErrorPageTemplate `json:"templates,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
 
 // Chain holds a chain of middlewares.
 type Chain struct {
-	Middlewares []MiddlewareRef `json:"middlewares,omitempty"`
+	Middlewares []MiddlewareRef   `json:"middlewares,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -85,10 +96,15 @@ type DigestAuth struct {
 
 // ForwardAuth holds the http forward authentication configuration.
 type ForwardAuth struct {
-	Address             string     `json:"address,omitempty"`
-	TrustForwardHeader  bool       `json:"trustForwardHeader,omitempty"`
-	AuthResponseHeaders []string   `json:"authResponseHeaders,omitempty"`
-	TLS                 *ClientTLS `json:"tls,omitempty"`
+	Address             string          `json:"address,omitempty"`
+	TrustForwardHeader  bool            `json:"trustForwardHeader,omitempty"`
+	AuthResponseHeaders []string        `json:"authResponseHeaders,omitempty"`
+	AuthRequestHeaders  []string        `json:"authRequestHeaders,omitempty"`
+	ForwardBody         bool            `json:"forwardBody,omitempty"`
+	MaxBodySize         int64           `json:"maxBodySize,omitempty"`
+	GRPC                bool            `json:"grpc,omitempty"`
+	SPIFFE              *dynamic.SPIFFE `json:"spiffe,omitempty"`
+	TLS                 *ClientTLS      `json:"tls,omitempty"`
 }
 
 // ClientTLS holds TLS specific configurations as client.
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -58,6 +59,13 @@ func (in *Chain) DeepCopyInto(out *Chain) {
 		*out = make([]MiddlewareRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -149,6 +157,11 @@ func (in *ErrorPage) DeepCopyInto(out *ErrorPage) {
 		copy(*out, *in)
 	}
 	in.Service.DeepCopyInto(&out.Service)
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]dynamic.ErrorPageTemplate, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -170,6 +183,16 @@ func (in *ForwardAuth) DeepCopyInto(out *ForwardAuth) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AuthRequestHeaders != nil {
+		in, out := &in.AuthRequestHeaders, &out.AuthRequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SPIFFE != nil {
+		in, out := &in.SPIFFE, &out.SPIFFE
+		*out = new(dynamic.SPIFFE)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(ClientTLS)
@@ -480,6 +503,21 @@ func (in *LoadBalancerSpec) DeepCopyInto(out *LoadBalancerSpec) {
 		*out = new(dynamic.ResponseForwarding)
 		**out = **in
 	}
+	if in.Coalescing != nil {
+		in, out := &in.Coalescing, &out.Coalescing
+		*out = new(dynamic.Coalescing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QoS != nil {
+		in, out := &in.QoS, &out.QoS
+		*out = new(dynamic.QoS)
+		**out = **in
+	}
+	if in.PathNormalization != nil {
+		in, out := &in.PathNormalization, &out.PathNormalization
+		*out = new(dynamic.PathNormalization)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Weight != nil {
 		in, out := &in.Weight, &out.Weight
 		*out = new(int)
@@ -680,13 +718,53 @@ func (in *MiddlewareSpec) DeepCopyInto(out *MiddlewareSpec) {
 	if in.Retry != nil {
 		in, out := &in.Retry, &out.Retry
 		*out = new(dynamic.Retry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.ContentType != nil {
 		in, out := &in.ContentType, &out.ContentType
 		*out = new(dynamic.ContentType)
 		**out = **in
 	}
+	if in.OPA != nil {
+		in, out := &in.OPA, &out.OPA
+		*out = new(dynamic.OPA)
+		**out = **in
+	}
+	if in.SPNEGOAuth != nil {
+		in, out := &in.SPNEGOAuth, &out.SPNEGOAuth
+		*out = new(dynamic.SPNEGOAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAPAuth != nil {
+		in, out := &in.LDAPAuth, &out.LDAPAuth
+		*out = new(dynamic.LDAPAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SAML != nil {
+		in, out := &in.SAML, &out.SAML
+		*out = new(dynamic.SAML)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EarlyHints != nil {
+		in, out := &in.EarlyHints, &out.EarlyHints
+		*out = new(dynamic.EarlyHints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(dynamic.S3)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdaptiveConcurrency != nil {
+		in, out := &in.AdaptiveConcurrency, &out.AdaptiveConcurrency
+		*out = new(dynamic.AdaptiveConcurrency)
+		**out = **in
+	}
+	if in.QoS != nil {
+		in, out := &in.QoS, &out.QoS
+		*out = new(dynamic.QoS)
+		**out = **in
+	}
 	if in.Plugin != nil {
 		in, out := &in.Plugin, &out.Plugin
 		*out = make(map[string]dynamic.PluginConf, len(*in))
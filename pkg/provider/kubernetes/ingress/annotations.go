@@ -13,6 +13,11 @@ const (
 	annotationsPrefix = "traefik.ingress.kubernetes.io/"
 )
 
+var (
+	annotationIndexExp = regexp.MustCompile(`(.+)\.(\w+)\.(\d+)\.(.+)`)
+	labelIndexExp      = regexp.MustCompile(`\[(\d+)\]`)
+)
+
 // RouterConfig is the router's root configuration from annotations.
 type RouterConfig struct {
 	Router *RouterIng `json:"router,omitempty"`
@@ -86,8 +91,6 @@ func convertAnnotations(annotations map[string]string) map[string]string {
 		return nil
 	}
 
-	exp := regexp.MustCompile(`(.+)\.(\w+)\.(\d+)\.(.+)`)
-
 	result := make(map[string]string)
 
 	for key, value := range annotations {
@@ -95,14 +98,46 @@ func convertAnnotations(annotations map[string]string) map[string]string {
 			continue
 		}
 
-		newKey := strings.ReplaceAll(key, "ingress.kubernetes.io/", "")
+		result[convertAnnotationKey(key)] = value
+	}
+
+	return result
+}
+
+func convertAnnotationKey(key string) string {
+	newKey := strings.ReplaceAll(key, "ingress.kubernetes.io/", "")
+
+	if annotationIndexExp.MatchString(newKey) {
+		newKey = annotationIndexExp.ReplaceAllString(newKey, "$1.$2[$3].$4")
+	}
+
+	return newKey
+}
 
-		if exp.MatchString(newKey) {
-			newKey = exp.ReplaceAllString(newKey, "$1.$2[$3].$4")
+// lintAnnotations checks the annotations against the options of the element,
+// and returns the issues found, with the keys of the annotations.
+func lintAnnotations(annotations map[string]string, element interface{}) []label.Issue {
+	keys := make(map[string]string)
+	labels := make(map[string]string)
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, annotationsPrefix) {
+			continue
 		}
 
-		result[newKey] = value
+		newKey := convertAnnotationKey(key)
+		keys[newKey] = key
+		labels[newKey] = value
 	}
 
-	return result
+	issues := label.Lint(labels, element)
+	for i, issue := range issues {
+		issues[i].Key = keys[issue.Key]
+
+		if issue.Suggestion != "" {
+			suggestion := labelIndexExp.ReplaceAllString(strings.TrimPrefix(issue.Suggestion, "traefik."), ".$1")
+			issues[i].Suggestion = annotationsPrefix + suggestion
+		}
+	}
+
+	return issues
 }
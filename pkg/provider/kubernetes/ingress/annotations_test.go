@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/label"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -243,3 +244,38 @@ func Test_convertAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func Test_lintAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		"traefik.ingress.kubernetes.io/router.entrypoints":         "web",
+		"traefik.ingress.kubernetes.io/router.pathmatchr":          "Path",
+		"traefik.ingress.kubernetes.io/router.priority":            "high",
+		"traefik.ingress.kubernetes.io/router.tls.domains.0.mainn": "foo.bar",
+		"traefik.ingress.kubernetes.io/router.tls.domains.0.sans":  "foo.bar",
+		"kubernetes.io/ingress.class":                              "traefik",
+	}
+
+	issues := lintAnnotations(annotations, &RouterConfig{})
+
+	expected := []label.Issue{
+		{
+			Code:       label.CodeMisspelledKey,
+			Key:        "traefik.ingress.kubernetes.io/router.pathmatchr",
+			Message:    `unknown option "pathmatchr", did you mean "pathmatcher"?`,
+			Suggestion: "traefik.ingress.kubernetes.io/router.pathmatcher",
+		},
+		{
+			Code:    label.CodeInvalidValue,
+			Key:     "traefik.ingress.kubernetes.io/router.priority",
+			Message: `invalid value "high": strconv.ParseInt: parsing "high": invalid syntax`,
+		},
+		{
+			Code:       label.CodeMisspelledKey,
+			Key:        "traefik.ingress.kubernetes.io/router.tls.domains.0.mainn",
+			Message:    `unknown option "mainn", did you mean "main"?`,
+			Suggestion: "traefik.ingress.kubernetes.io/router.tls.domains.0.main",
+		},
+	}
+
+	assert.Equal(t, expected, issues)
+}
@@ -53,6 +53,7 @@ type Client interface {
 	GetIngressClass() (*networkingv1beta1.IngressClass, error)
 	GetService(namespace, name string) (*corev1.Service, bool, error)
 	GetSecret(namespace, name string) (*corev1.Secret, bool, error)
+	GetSecrets(selector labels.Selector) []*corev1.Secret
 	GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error)
 	UpdateIngressStatus(ing *networkingv1beta1.Ingress, ip, hostname string) error
 	GetServerVersion() (*version.Version, error)
@@ -329,6 +330,21 @@ func (c *clientWrapper) GetSecret(namespace, name string) (*corev1.Secret, bool,
 	return secret, exist, err
 }
 
+// GetSecrets returns the secrets matching the selector, in the observed namespaces.
+func (c *clientWrapper) GetSecrets(selector labels.Selector) []*corev1.Secret {
+	var result []*corev1.Secret
+
+	for ns, factory := range c.factories {
+		secrets, err := factory.Core().V1().Secrets().Lister().List(selector)
+		if err != nil {
+			log.Errorf("Failed to list secrets in namespace %s: %v", ns, err)
+		}
+		result = append(result, secrets...)
+	}
+
+	return result
+}
+
 func (c *clientWrapper) GetIngressClass() (*networkingv1beta1.IngressClass, error) {
 	if c.clusterFactory == nil {
 		return nil, errors.New("failed to find ingressClass: factory not loaded")
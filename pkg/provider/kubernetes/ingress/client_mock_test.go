@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 var _ Client = (*clientMock)(nil)
@@ -117,6 +118,16 @@ func (c clientMock) GetSecret(namespace, name string) (*corev1.Secret, bool, err
 	return nil, false, nil
 }
 
+func (c clientMock) GetSecrets(selector labels.Selector) []*corev1.Secret {
+	var result []*corev1.Secret
+	for _, secret := range c.secrets {
+		if selector.Matches(labels.Set(secret.Labels)) {
+			result = append(result, secret)
+		}
+	}
+	return result
+}
+
 func (c clientMock) GetIngressClass() (*networkingv1beta1.IngressClass, error) {
 	return c.ingressClass, nil
 }
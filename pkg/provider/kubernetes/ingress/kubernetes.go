@@ -12,9 +12,11 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/label"
 	"github.com/containous/traefik/v2/pkg/job"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/kubernetes/k8s"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/mitchellh/hashstructure"
@@ -43,6 +45,7 @@ type Provider struct {
 	IngressClass           string           `description:"Value of kubernetes.io/ingress.class annotation to watch for." json:"ingressClass,omitempty" toml:"ingressClass,omitempty" yaml:"ingressClass,omitempty" export:"true"`
 	IngressEndpoint        *EndpointIngress `description:"Kubernetes Ingress Endpoint." json:"ingressEndpoint,omitempty" toml:"ingressEndpoint,omitempty" yaml:"ingressEndpoint,omitempty"`
 	ThrottleDuration       ptypes.Duration  `description:"Ingress refresh throttle duration" json:"throttleDuration,omitempty" toml:"throttleDuration,omitempty" yaml:"throttleDuration,omitempty"`
+	TLSSecrets             *k8s.TLSSecrets  `description:"Load the certificates of the labelled Secrets into a TLS store." json:"tlsSecrets,omitempty" toml:"tlsSecrets,omitempty" yaml:"tlsSecrets,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	lastConfiguration      safe.Safe
 }
 
@@ -90,6 +93,12 @@ func (p *Provider) newK8sClient(ctx context.Context, ingressLabelSelector string
 
 // Init the provider.
 func (p *Provider) Init() error {
+	if p.TLSSecrets != nil {
+		if _, err := p.TLSSecrets.Selector(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -201,6 +210,9 @@ func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Cl
 
 	ingresses := client.GetIngresses()
 
+	// The issues found in the annotations, by element.
+	issues := make(map[string][]label.ProviderIssue)
+
 	certConfigs := make(map[string]*tls.CertAndStores)
 	for _, ingress := range ingresses {
 		ctx = log.With(ctx, log.Str("ingress", ingress.Name), log.Str("namespace", ingress.Namespace))
@@ -209,6 +221,9 @@ func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Cl
 			continue
 		}
 
+		element := "ingress/" + ingress.Namespace + "/" + ingress.Name
+		issues[element] = lintElement(ctx, element, ingress.Annotations, &RouterConfig{})
+
 		rtConfig, err := parseRouterConfig(ingress.Annotations)
 		if err != nil {
 			log.FromContext(ctx).Errorf("Failed to parse annotations: %v", err)
@@ -235,6 +250,8 @@ func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Cl
 				continue
 			}
 
+			lintService(ctx, client, ingress.Namespace, ingress.Spec.Backend.ServiceName, issues)
+
 			rt := &dynamic.Router{
 				Rule:     "PathPrefix(`/`)",
 				Priority: math.MinInt32,
@@ -270,6 +287,8 @@ func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Cl
 					continue
 				}
 
+				lintService(ctx, client, ingress.Namespace, pa.Backend.ServiceName, issues)
+
 				serviceName := provider.Normalize(ingress.Namespace + "-" + pa.Backend.ServiceName + "-" + pa.Backend.ServicePort.String())
 				conf.HTTP.Services[serviceName] = service
 
@@ -280,16 +299,64 @@ func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Cl
 		}
 	}
 
-	certs := getTLSConfig(certConfigs)
+	certs := append(getTLSConfig(certConfigs), p.loadTLSSecrets(ctx, client)...)
 	if len(certs) > 0 {
 		conf.TLS = &dynamic.TLSConfiguration{
 			Certificates: certs,
 		}
 	}
 
+	var providerIssues []label.ProviderIssue
+	for _, elementIssues := range issues {
+		providerIssues = append(providerIssues, elementIssues...)
+	}
+	label.GetIssueRegistry().Set("kubernetes", providerIssues)
+
 	return conf
 }
 
+// loadTLSSecrets returns the certificates of the Secrets labelled to be loaded into a TLS store.
+func (p *Provider) loadTLSSecrets(ctx context.Context, client Client) []*tls.CertAndStores {
+	if p.TLSSecrets == nil {
+		return nil
+	}
+
+	selector, err := p.TLSSecrets.Selector()
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+		return nil
+	}
+
+	return p.TLSSecrets.Certificates(ctx, client.GetSecrets(selector))
+}
+
+// lintService checks the annotations of the service, once for all the ingresses referencing it.
+func lintService(ctx context.Context, client Client, namespace, name string, issues map[string][]label.ProviderIssue) {
+	element := "service/" + namespace + "/" + name
+	if _, ok := issues[element]; ok {
+		return
+	}
+
+	service, exists, err := client.GetService(namespace, name)
+	if err != nil || !exists {
+		return
+	}
+
+	issues[element] = lintElement(log.With(ctx, log.Str("service", name)), element, service.Annotations, &ServiceConfig{})
+}
+
+// lintElement checks the annotations of the element, and logs the issues found.
+// The returned slice is never nil, to record that the element has been checked.
+func lintElement(ctx context.Context, element string, annotations map[string]string, config interface{}) []label.ProviderIssue {
+	elementIssues := []label.ProviderIssue{}
+	for _, issue := range lintAnnotations(annotations, config) {
+		log.FromContext(ctx).WithField("annotation", issue.Key).Warnf("%s: %s", issue.Code, issue.Message)
+		elementIssues = append(elementIssues, label.ProviderIssue{Provider: "kubernetes", Element: element, Issue: issue})
+	}
+
+	return elementIssues
+}
+
 func (p *Provider) updateIngressStatus(ing *networkingv1beta1.Ingress, k8sClient Client) error {
 	// Only process if an EndpointIngress has been configured.
 	if p.IngressEndpoint == nil {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/kubernetes/k8s"
 	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -1354,3 +1355,101 @@ func TestGetCertificates(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadTLSSecrets(t *testing.T) {
+	secrets := []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "testing",
+				Labels:    map[string]string{k8s.DefaultTLSSecretsLabelSelector: "true"},
+			},
+			Data: map[string][]byte{
+				"tls.crt": []byte("foo-crt"),
+				"tls.key": []byte("foo-key"),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar",
+				Namespace: "default",
+				Labels:    map[string]string{k8s.DefaultTLSSecretsLabelSelector: "true", "team": "bar"},
+			},
+			Data: map[string][]byte{
+				"tls.crt": []byte("bar-crt"),
+				"tls.key": []byte("bar-key"),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "missing-key",
+				Namespace: "testing",
+				Labels:    map[string]string{k8s.DefaultTLSSecretsLabelSelector: "true"},
+			},
+			Data: map[string][]byte{
+				"tls.crt": []byte("missing-key-crt"),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "unlabelled",
+				Namespace: "testing",
+			},
+			Data: map[string][]byte{
+				"tls.crt": []byte("unlabelled-crt"),
+				"tls.key": []byte("unlabelled-key"),
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc       string
+		tlsSecrets *k8s.TLSSecrets
+		expected   []*tls.CertAndStores
+	}{
+		{
+			desc: "disabled",
+		},
+		{
+			desc:       "default label selector",
+			tlsSecrets: &k8s.TLSSecrets{LabelSelector: k8s.DefaultTLSSecretsLabelSelector, Store: "default"},
+			expected: []*tls.CertAndStores{
+				{
+					Certificate: tls.Certificate{CertFile: "bar-crt", KeyFile: "bar-key"},
+					Stores:      []string{"default"},
+				},
+				{
+					Certificate: tls.Certificate{CertFile: "foo-crt", KeyFile: "foo-key"},
+					Stores:      []string{"default"},
+				},
+			},
+		},
+		{
+			desc:       "custom label selector and store",
+			tlsSecrets: &k8s.TLSSecrets{LabelSelector: "team=bar", Store: "internal"},
+			expected: []*tls.CertAndStores{
+				{
+					Certificate: tls.Certificate{CertFile: "bar-crt", KeyFile: "bar-key"},
+					Stores:      []string{"internal"},
+				},
+			},
+		},
+		{
+			desc:       "invalid label selector",
+			tlsSecrets: &k8s.TLSSecrets{LabelSelector: "team=bar=baz", Store: "default"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{TLSSecrets: test.tlsSecrets}
+
+			certs := p.loadTLSSecrets(context.Background(), clientMock{secrets: secrets})
+
+			assert.Equal(t, test.expected, certs)
+		})
+	}
+}
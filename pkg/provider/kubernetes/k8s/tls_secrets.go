@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tls"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultTLSSecretsLabelSelector is the label selector of the Secrets loaded into a TLS store by default.
+const DefaultTLSSecretsLabelSelector = "traefik.containo.us/tls-store"
+
+// TLSSecrets holds the configuration of the Secrets whose certificates are loaded into a TLS store,
+// e.g. the Secrets of the certificates managed by cert-manager, without any route referencing them.
+type TLSSecrets struct {
+	LabelSelector string `description:"Label selector of the Secrets to load." json:"labelSelector,omitempty" toml:"labelSelector,omitempty" yaml:"labelSelector,omitempty" export:"true"`
+	Store         string `description:"Name of the TLS store the certificates are loaded into." json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (t *TLSSecrets) SetDefaults() {
+	t.LabelSelector = DefaultTLSSecretsLabelSelector
+	t.Store = "default"
+}
+
+// Selector returns the label selector of the Secrets to load.
+func (t *TLSSecrets) Selector() (labels.Selector, error) {
+	selector, err := labels.Parse(t.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS secrets label selector: %q", t.LabelSelector)
+	}
+
+	return selector, nil
+}
+
+// Certificates returns the certificates of the Secrets, sorted by namespace and name,
+// to be loaded into the TLS store.
+// The Secrets missing a certificate or a key are skipped.
+func (t *TLSSecrets) Certificates(ctx context.Context, secrets []*corev1.Secret) []*tls.CertAndStores {
+	sorted := make([]*corev1.Secret, len(secrets))
+	copy(sorted, secrets)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var certs []*tls.CertAndStores
+	for _, secret := range sorted {
+		cert := secret.Data[corev1.TLSCertKey]
+		key := secret.Data[corev1.TLSPrivateKeyKey]
+
+		if len(cert) == 0 || len(key) == 0 {
+			log.FromContext(ctx).Errorf("Skipping secret %s/%s: the %s and %s data entries are required", secret.Namespace, secret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+			continue
+		}
+
+		certs = append(certs, &tls.CertAndStores{
+			Certificate: tls.Certificate{
+				CertFile: tls.FileOrContent(cert),
+				KeyFile:  tls.FileOrContent(key),
+			},
+			Stores: []string{t.Store},
+		})
+	}
+
+	return certs
+}
@@ -11,6 +11,9 @@ var _ provider.Provider = (*Provider)(nil)
 // Provider holds configurations of the provider.
 type Provider struct {
 	kv.Provider
+
+	Namespace string      `description:"Namespace, as a chroot, prefixing all the keys of the provider." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	Backoff   *kv.Backoff `description:"Backoff between the connection attempts to etcd." json:"backoff,omitempty" toml:"backoff,omitempty" yaml:"backoff,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -21,5 +24,8 @@ func (p *Provider) SetDefaults() {
 
 // Init the provider.
 func (p *Provider) Init() error {
+	p.Provider.SetNamespace(p.Namespace)
+	p.Provider.SetBackoff(p.Backoff)
+
 	return p.Provider.Init(store.ETCDV3, "etcd")
 }
@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/abronan/valkeyrie"
 	"github.com/abronan/valkeyrie/store"
 	"github.com/abronan/valkeyrie/store/consul"
 	etcdv3 "github.com/abronan/valkeyrie/store/etcd/v3"
-	"github.com/abronan/valkeyrie/store/redis"
 	"github.com/abronan/valkeyrie/store/zookeeper"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
@@ -20,8 +20,46 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/types"
+	ptypes "github.com/traefik/paerser/types"
 )
 
+// Backoff holds the configuration of the exponential backoff between the connection attempts to the KV store.
+type Backoff struct {
+	InitialInterval ptypes.Duration `description:"Interval before the first retry." json:"initialInterval,omitempty" toml:"initialInterval,omitempty" yaml:"initialInterval,omitempty" export:"true"`
+	MaxInterval     ptypes.Duration `description:"Maximum interval between the retries." json:"maxInterval,omitempty" toml:"maxInterval,omitempty" yaml:"maxInterval,omitempty" export:"true"`
+	Jitter          float64         `description:"Randomization factor of the intervals, between 0 and 1, so that the Traefik instances do not retry all at once." json:"jitter,omitempty" toml:"jitter,omitempty" yaml:"jitter,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (b *Backoff) SetDefaults() {
+	b.InitialInterval = ptypes.Duration(time.Second)
+	b.MaxInterval = ptypes.Duration(backoff.DefaultMaxInterval)
+	b.Jitter = backoff.DefaultRandomizationFactor
+}
+
+func (b *Backoff) newBackOff() *backoff.ExponentialBackOff {
+	if b == nil {
+		b = &Backoff{}
+		b.SetDefaults()
+	}
+
+	backOff := backoff.NewExponentialBackOff()
+
+	if b.InitialInterval > 0 {
+		backOff.InitialInterval = time.Duration(b.InitialInterval)
+	}
+	if b.MaxInterval > 0 {
+		backOff.MaxInterval = time.Duration(b.MaxInterval)
+	}
+	if b.Jitter >= 0 && b.Jitter <= 1 {
+		backOff.RandomizationFactor = b.Jitter
+	}
+
+	backOff.Reset()
+
+	return backOff
+}
+
 // Provider holds configurations of the provider.
 type Provider struct {
 	RootKey string `description:"Root key used for KV store" export:"true" json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty"`
@@ -34,6 +72,8 @@ type Provider struct {
 	storeType store.Backend
 	kvClient  store.Store
 	name      string
+	namespace string
+	backoff   *Backoff
 }
 
 // SetDefaults sets the default values.
@@ -58,6 +98,33 @@ func (p *Provider) Init(storeType store.Backend, name string) error {
 	return nil
 }
 
+// InitStore initializes the provider with a store created by the caller,
+// for the backends whose options are not supported by valkeyrie.
+func (p *Provider) InitStore(kvStore store.Store, name string) {
+	p.name = name
+	p.kvClient = p.wrapStore(kvStore)
+}
+
+// SetNamespace sets the namespace prefixing all the keys of the provider, including the root key,
+// so that several Traefik clusters can share the KV store.
+// It must be called before the initialization of the provider.
+func (p *Provider) SetNamespace(namespace string) {
+	p.namespace = namespace
+}
+
+// SetBackoff sets the backoff between the connection attempts to the KV store.
+func (p *Provider) SetBackoff(b *Backoff) {
+	p.backoff = b
+}
+
+func (p *Provider) wrapStore(kvStore store.Store) store.Store {
+	if ns := strings.Trim(p.namespace, "/"); ns != "" {
+		kvStore = &namespaceStore{Store: kvStore, namespace: ns}
+	}
+
+	return &storeWrapper{Store: kvStore}
+}
+
 // Provide allows the docker provider to provide configurations to traefik using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
 	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.name))
@@ -73,7 +140,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	notify := func(err error, time time.Duration) {
 		logger.Errorf("KV connection error: %+v, retrying in %s", err, time)
 	}
-	err := backoff.RetryNotify(safe.OperationWithRecover(operation), job.NewBackOff(backoff.NewExponentialBackOff()), notify)
+	err := backoff.RetryNotify(safe.OperationWithRecover(operation), job.NewBackOff(p.backoff.newBackOff()), notify)
 	if err != nil {
 		return fmt.Errorf("cannot connect to KV server: %w", err)
 	}
@@ -136,13 +203,23 @@ func (p *Provider) watchKv(ctx context.Context, configurationChan chan<- dynamic
 	}
 
 	err := backoff.RetryNotify(safe.OperationWithRecover(operation),
-		backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctx), notify)
+		backoff.WithContext(job.NewBackOff(p.backoff.newBackOff()), ctx), notify)
 	if err != nil {
 		return fmt.Errorf("cannot connect to KV server: %w", err)
 	}
 	return nil
 }
 
+// Put stores the value at the given key, relative to the root key.
+// The provider must have been initialized.
+func (p *Provider) Put(key, value string) error {
+	if p.kvClient == nil {
+		return fmt.Errorf("the %s provider is not initialized", p.name)
+	}
+
+	return p.kvClient.Put(path.Join(p.RootKey, key), []byte(value), nil)
+}
+
 func (p *Provider) buildConfiguration() (*dynamic.Configuration, error) {
 	pairs, err := p.kvClient.List(p.RootKey, nil)
 	if err != nil {
@@ -181,8 +258,6 @@ func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
 		etcdv3.Register()
 	case store.ZK:
 		zookeeper.Register()
-	case store.REDIS:
-		redis.Register()
 	}
 
 	kvStore, err := valkeyrie.NewStore(p.storeType, p.Endpoints, storeConfig)
@@ -190,5 +265,5 @@ func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
 		return nil, err
 	}
 
-	return &storeWrapper{Store: kvStore}, nil
+	return p.wrapStore(kvStore), nil
 }
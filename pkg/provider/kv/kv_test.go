@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/abronan/valkeyrie/store"
+	"github.com/cenkalti/backoff/v4"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
@@ -894,6 +895,29 @@ func Test_buildConfiguration_KV_error(t *testing.T) {
 	assert.Nil(t, cfg)
 }
 
+func Test_buildConfiguration_namespace(t *testing.T) {
+	provider := &Provider{RootKey: "traefik", namespace: "cluster-a"}
+	provider.InitStore(&Mock{
+		KVPairs: mapToPairs(map[string]string{
+			"cluster-a/traefik/http/routers/foo/rule":    "Host(`foo.example.com`)",
+			"cluster-a/traefik/http/routers/foo/service": "foo",
+			"cluster-b/traefik/http/routers/bar/rule":    "Host(`bar.example.com`)",
+			"cluster-b/traefik/http/routers/bar/service": "bar",
+		}),
+	}, "mock")
+
+	cfg, err := provider.buildConfiguration()
+	require.NoError(t, err)
+
+	expected := map[string]*dynamic.Router{
+		"foo": {
+			Rule:    "Host(`foo.example.com`)",
+			Service: "foo",
+		},
+	}
+	assert.Equal(t, expected, cfg.HTTP.Routers)
+}
+
 func TestKvWatchTree(t *testing.T) {
 	returnedChans := make(chan chan []*store.KVPair)
 	provider := Provider{
@@ -943,3 +967,57 @@ func mapToPairs(in map[string]string) []*store.KVPair {
 	}
 	return out
 }
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		backoff  *Backoff
+		expected backoff.ExponentialBackOff
+	}{
+		{
+			desc: "default",
+			expected: backoff.ExponentialBackOff{
+				InitialInterval:     time.Second,
+				MaxInterval:         backoff.DefaultMaxInterval,
+				RandomizationFactor: backoff.DefaultRandomizationFactor,
+			},
+		},
+		{
+			desc: "configured",
+			backoff: &Backoff{
+				InitialInterval: ptypes.Duration(time.Second),
+				MaxInterval:     ptypes.Duration(time.Minute),
+				Jitter:          0.2,
+			},
+			expected: backoff.ExponentialBackOff{
+				InitialInterval:     time.Second,
+				MaxInterval:         time.Minute,
+				RandomizationFactor: 0.2,
+			},
+		},
+		{
+			desc: "without jitter",
+			backoff: &Backoff{
+				InitialInterval: ptypes.Duration(time.Second),
+			},
+			expected: backoff.ExponentialBackOff{
+				InitialInterval:     time.Second,
+				MaxInterval:         backoff.DefaultMaxInterval,
+				RandomizationFactor: 0,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backOff := test.backoff.newBackOff()
+
+			assert.Equal(t, test.expected.InitialInterval, backOff.InitialInterval)
+			assert.Equal(t, test.expected.MaxInterval, backOff.MaxInterval)
+			assert.Equal(t, test.expected.RandomizationFactor, backOff.RandomizationFactor)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package kv
+
+import (
+	"strings"
+
+	"github.com/abronan/valkeyrie/store"
+)
+
+// namespaceStore prefixes the keys with the namespace,
+// and removes it from the keys of the returned pairs, as a chroot of the store.
+type namespaceStore struct {
+	store.Store
+	namespace string
+}
+
+func (s *namespaceStore) Put(key string, value []byte, options *store.WriteOptions) error {
+	return s.Store.Put(s.key(key), value, options)
+}
+
+func (s *namespaceStore) Get(key string, options *store.ReadOptions) (*store.KVPair, error) {
+	pair, err := s.Store.Get(s.key(key), options)
+	return s.pair(pair), err
+}
+
+func (s *namespaceStore) Delete(key string) error {
+	return s.Store.Delete(s.key(key))
+}
+
+func (s *namespaceStore) Exists(key string, options *store.ReadOptions) (bool, error) {
+	return s.Store.Exists(s.key(key), options)
+}
+
+func (s *namespaceStore) Watch(key string, stopCh <-chan struct{}, options *store.ReadOptions) (<-chan *store.KVPair, error) {
+	events, err := s.Store.Watch(s.key(key), stopCh, options)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCh := make(chan *store.KVPair)
+	go func() {
+		defer close(watchCh)
+
+		for pair := range events {
+			select {
+			case watchCh <- s.pair(pair):
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+func (s *namespaceStore) WatchTree(directory string, stopCh <-chan struct{}, options *store.ReadOptions) (<-chan []*store.KVPair, error) {
+	events, err := s.Store.WatchTree(s.key(directory), stopCh, options)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCh := make(chan []*store.KVPair)
+	go func() {
+		defer close(watchCh)
+
+		for pairs := range events {
+			select {
+			case watchCh <- s.pairs(pairs):
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+func (s *namespaceStore) NewLock(key string, options *store.LockOptions) (store.Locker, error) {
+	return s.Store.NewLock(s.key(key), options)
+}
+
+func (s *namespaceStore) List(directory string, options *store.ReadOptions) ([]*store.KVPair, error) {
+	pairs, err := s.Store.List(s.key(directory), options)
+	return s.pairs(pairs), err
+}
+
+func (s *namespaceStore) DeleteTree(directory string) error {
+	return s.Store.DeleteTree(s.key(directory))
+}
+
+func (s *namespaceStore) AtomicPut(key string, value []byte, previous *store.KVPair, options *store.WriteOptions) (bool, *store.KVPair, error) {
+	if previous != nil {
+		previousCopy := *previous
+		previousCopy.Key = s.key(previous.Key)
+		previous = &previousCopy
+	}
+
+	ok, pair, err := s.Store.AtomicPut(s.key(key), value, previous, options)
+	return ok, s.pair(pair), err
+}
+
+func (s *namespaceStore) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	if previous != nil {
+		previousCopy := *previous
+		previousCopy.Key = s.key(previous.Key)
+		previous = &previousCopy
+	}
+
+	return s.Store.AtomicDelete(s.key(key), previous)
+}
+
+// key returns the key of the store, keeping its leading slash.
+func (s *namespaceStore) key(key string) string {
+	if strings.HasPrefix(key, "/") {
+		return "/" + s.namespace + key
+	}
+	return s.namespace + "/" + key
+}
+
+// stripKey returns the key without the namespace, keeping its leading slash.
+func (s *namespaceStore) stripKey(key string) string {
+	if strings.HasPrefix(key, "/") {
+		return "/" + strings.TrimPrefix(key[1:], s.namespace+"/")
+	}
+	return strings.TrimPrefix(key, s.namespace+"/")
+}
+
+func (s *namespaceStore) pair(pair *store.KVPair) *store.KVPair {
+	if pair == nil {
+		return nil
+	}
+
+	pairCopy := *pair
+	pairCopy.Key = s.stripKey(pair.Key)
+
+	return &pairCopy
+}
+
+func (s *namespaceStore) pairs(pairs []*store.KVPair) []*store.KVPair {
+	if pairs == nil {
+		return nil
+	}
+
+	stripped := make([]*store.KVPair, len(pairs))
+	for i, pair := range pairs {
+		stripped[i] = s.pair(pair)
+	}
+
+	return stripped
+}
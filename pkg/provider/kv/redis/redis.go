@@ -1,9 +1,13 @@
 package redis
 
 import (
-	"github.com/abronan/valkeyrie/store"
+	"context"
+	"fmt"
+
+	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
 	"github.com/containous/traefik/v2/pkg/provider/kv"
+	traefikredis "github.com/containous/traefik/v2/pkg/redis"
 )
 
 var _ provider.Provider = (*Provider)(nil)
@@ -11,6 +15,10 @@ var _ provider.Provider = (*Provider)(nil)
 // Provider holds configurations of the provider.
 type Provider struct {
 	kv.Provider
+
+	DB       int                    `description:"Database selected on the Redis server." json:"db,omitempty" toml:"db,omitempty" yaml:"db,omitempty" export:"true"`
+	Sentinel *traefikredis.Sentinel `description:"Enable the discovery of the master by Redis Sentinel, the endpoints being the sentinels." json:"sentinel,omitempty" toml:"sentinel,omitempty" yaml:"sentinel,omitempty" export:"true"`
+	Cluster  bool                   `description:"Enable Redis Cluster, the endpoints being seed nodes of the cluster." json:"cluster,omitempty" toml:"cluster,omitempty" yaml:"cluster,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -21,5 +29,31 @@ func (p *Provider) SetDefaults() {
 
 // Init the provider.
 func (p *Provider) Init() error {
-	return p.Provider.Init(store.REDIS, "redis")
+	ctx := log.With(context.Background(), log.Str(log.ProviderName, "redis"))
+
+	options := traefikredis.Options{
+		Endpoints: p.Endpoints,
+		Username:  p.Username,
+		Password:  p.Password,
+		DB:        p.DB,
+		Sentinel:  p.Sentinel,
+		Cluster:   p.Cluster,
+	}
+
+	if p.TLS != nil {
+		var err error
+		options.TLS, err = p.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	kvStore, err := traefikredis.NewStore(options)
+	if err != nil {
+		return fmt.Errorf("failed to Connect to KV store: %w", err)
+	}
+
+	p.Provider.InitStore(kvStore, "redis")
+
+	return nil
 }
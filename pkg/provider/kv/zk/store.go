@@ -0,0 +1,364 @@
+package zk
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+var _ store.Store = (*zkStore)(nil)
+
+// zkStore is a valkeyrie store backed by ZooKeeper,
+// which authenticates with the digest scheme and connects with TLS, unlike the valkeyrie ZooKeeper store.
+type zkStore struct {
+	conn *zk.Conn
+	// acl is the ACL of the created nodes, restricted to the user when authenticated.
+	acl []zk.ACL
+
+	authMu sync.Mutex
+	// auth is the digest credentials, still to be added to the session when not nil.
+	auth []byte
+}
+
+func newStore(endpoints []string, username, password string, tlsConfig *tls.Config) (*zkStore, error) {
+	dialer := net.DialTimeout
+	if tlsConfig != nil {
+		dialer = func(network, address string, timeout time.Duration) (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, tlsConfig)
+		}
+	}
+
+	conn, _, err := zk.Connect(endpoints, 3*time.Second, zk.WithDialer(dialer))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &zkStore{conn: conn, acl: zk.WorldACL(zk.PermAll)}
+
+	if username != "" {
+		s.acl = zk.DigestACL(zk.PermAll, username, password)
+		s.auth = []byte(username + ":" + password)
+	}
+
+	return s, nil
+}
+
+// authenticate adds the credentials to the session, once connected,
+// the client adding them again on each reconnection.
+func (s *zkStore) authenticate() error {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	if s.auth == nil {
+		return nil
+	}
+
+	if err := s.conn.AddAuth("digest", s.auth); err != nil {
+		return err
+	}
+
+	s.auth = nil
+
+	return nil
+}
+
+// Put stores the value at the key, creating its parent nodes.
+func (s *zkStore) Put(key string, value []byte, options *store.WriteOptions) error {
+	if err := s.authenticate(); err != nil {
+		return err
+	}
+
+	_, err := s.conn.Set(normalize(key), value, -1)
+	if !errors.Is(err, zk.ErrNoNode) {
+		return err
+	}
+
+	var flags int32
+	if options != nil && options.TTL > 0 {
+		flags = zk.FlagEphemeral
+	}
+
+	return s.create(key, value, flags)
+}
+
+// Get returns the value of the key.
+func (s *zkStore) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	if err := s.authenticate(); err != nil {
+		return nil, err
+	}
+
+	value, stat, err := s.conn.Get(normalize(key))
+	if errors.Is(err, zk.ErrNoNode) {
+		return nil, store.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &store.KVPair{Key: key, Value: value, LastIndex: uint64(stat.Version)}, nil
+}
+
+// Delete deletes the key.
+func (s *zkStore) Delete(key string) error {
+	if err := s.authenticate(); err != nil {
+		return err
+	}
+
+	err := s.conn.Delete(normalize(key), -1)
+	if errors.Is(err, zk.ErrNoNode) {
+		return store.ErrKeyNotFound
+	}
+
+	return err
+}
+
+// Exists reports whether the key exists.
+func (s *zkStore) Exists(key string, _ *store.ReadOptions) (bool, error) {
+	if err := s.authenticate(); err != nil {
+		return false, err
+	}
+
+	exists, _, err := s.conn.Exists(normalize(key))
+	return exists, err
+}
+
+// Watch sends the value of the key, and then its new value on each change, until stopCh is closed.
+func (s *zkStore) Watch(key string, stopCh <-chan struct{}, _ *store.ReadOptions) (<-chan *store.KVPair, error) {
+	if err := s.authenticate(); err != nil {
+		return nil, err
+	}
+
+	watchCh := make(chan *store.KVPair)
+
+	go func() {
+		defer close(watchCh)
+
+		for {
+			value, stat, events, err := s.conn.GetW(normalize(key))
+			if err != nil {
+				return
+			}
+
+			select {
+			case watchCh <- &store.KVPair{Key: key, Value: value, LastIndex: uint64(stat.Version)}:
+			case <-stopCh:
+				return
+			}
+
+			select {
+			case <-events:
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+// WatchTree sends the pairs of the directory, and then its new pairs each time its children change, until stopCh is closed.
+func (s *zkStore) WatchTree(directory string, stopCh <-chan struct{}, _ *store.ReadOptions) (<-chan []*store.KVPair, error) {
+	if err := s.authenticate(); err != nil {
+		return nil, err
+	}
+
+	watchCh := make(chan []*store.KVPair)
+
+	go func() {
+		defer close(watchCh)
+
+		for {
+			_, _, events, err := s.conn.ChildrenW(normalize(directory))
+			if err != nil {
+				return
+			}
+
+			pairs, err := s.List(directory, nil)
+			if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+				return
+			}
+
+			select {
+			case watchCh <- pairs:
+			case <-stopCh:
+				return
+			}
+
+			select {
+			case <-events:
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+// NewLock is not supported.
+func (s *zkStore) NewLock(string, *store.LockOptions) (store.Locker, error) {
+	return nil, store.ErrCallNotSupported
+}
+
+// List returns the pairs of the nodes under the directory, recursively.
+func (s *zkStore) List(directory string, options *store.ReadOptions) ([]*store.KVPair, error) {
+	if err := s.authenticate(); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.children(strings.TrimSuffix(directory, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []*store.KVPair
+	for _, key := range keys {
+		pair, err := s.Get(key, options)
+		if errors.Is(err, store.ErrKeyNotFound) {
+			// The node was deleted in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
+
+// DeleteTree deletes the nodes under the directory.
+func (s *zkStore) DeleteTree(directory string) error {
+	if err := s.authenticate(); err != nil {
+		return err
+	}
+
+	keys, err := s.children(strings.TrimSuffix(directory, "/"))
+	if err != nil {
+		return err
+	}
+
+	// The children are deleted before their parent.
+	var reqs []interface{}
+	for i := len(keys) - 1; i >= 0; i-- {
+		reqs = append(reqs, &zk.DeleteRequest{Path: normalize(keys[i]), Version: -1})
+	}
+
+	_, err = s.conn.Multi(reqs...)
+	return err
+}
+
+// AtomicPut sets the key to the value, when its version is the one of the previous pair,
+// or when it does not exist if previous is nil.
+func (s *zkStore) AtomicPut(key string, value []byte, previous *store.KVPair, _ *store.WriteOptions) (bool, *store.KVPair, error) {
+	if err := s.authenticate(); err != nil {
+		return false, nil, err
+	}
+
+	if previous == nil {
+		err := s.create(key, value, 0)
+		if errors.Is(err, zk.ErrNodeExists) {
+			return false, nil, store.ErrKeyExists
+		}
+		if err != nil {
+			return false, nil, err
+		}
+
+		return true, &store.KVPair{Key: key, Value: value}, nil
+	}
+
+	stat, err := s.conn.Set(normalize(key), value, int32(previous.LastIndex))
+	if errors.Is(err, zk.ErrBadVersion) {
+		return false, nil, store.ErrKeyModified
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, &store.KVPair{Key: key, Value: value, LastIndex: uint64(stat.Version)}, nil
+}
+
+// AtomicDelete deletes the key, when its version is the one of the previous pair.
+func (s *zkStore) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	if previous == nil {
+		return false, store.ErrPreviousNotSpecified
+	}
+
+	if err := s.authenticate(); err != nil {
+		return false, err
+	}
+
+	err := s.conn.Delete(normalize(key), int32(previous.LastIndex))
+	if errors.Is(err, zk.ErrNoNode) {
+		return false, store.ErrKeyNotFound
+	}
+	if errors.Is(err, zk.ErrBadVersion) {
+		return false, store.ErrKeyModified
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Close closes the session.
+func (s *zkStore) Close() {
+	s.conn.Close()
+}
+
+// create creates the node of the key, and its missing parent nodes.
+func (s *zkStore) create(key string, value []byte, flags int32) error {
+	parts := store.SplitKey(strings.Trim(key, "/"))
+
+	for i := 1; i < len(parts); i++ {
+		_, err := s.conn.Create("/"+strings.Join(parts[:i], "/"), nil, 0, s.acl)
+		if err != nil && !errors.Is(err, zk.ErrNodeExists) {
+			return err
+		}
+	}
+
+	_, err := s.conn.Create(normalize(key), value, flags, s.acl)
+	return err
+}
+
+// children returns the keys of the nodes under the directory, recursively, the parents before their children.
+func (s *zkStore) children(directory string) ([]string, error) {
+	names, _, err := s.conn.Children(normalize(directory))
+	if errors.Is(err, zk.ErrNoNode) {
+		return nil, store.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, name := range names {
+		key := directory + "/" + name
+		keys = append(keys, key)
+
+		children, err := s.children(key)
+		if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+			return nil, err
+		}
+
+		keys = append(keys, children...)
+	}
+
+	return keys, nil
+}
+
+// normalize returns the path of the node of the key.
+func normalize(key string) string {
+	if key = strings.TrimSuffix(store.Normalize(key), "/"); key == "" {
+		return "/"
+	}
+	return key
+}
@@ -1,7 +1,11 @@
 package zk
 
 import (
-	"github.com/abronan/valkeyrie/store"
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
 	"github.com/containous/traefik/v2/pkg/provider/kv"
 )
@@ -11,6 +15,9 @@ var _ provider.Provider = (*Provider)(nil)
 // Provider holds configurations of the provider.
 type Provider struct {
 	kv.Provider
+
+	Namespace string      `description:"Namespace, as a chroot, prefixing all the keys of the provider." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	Backoff   *kv.Backoff `description:"Backoff between the connection attempts to ZooKeeper." json:"backoff,omitempty" toml:"backoff,omitempty" yaml:"backoff,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -21,5 +28,26 @@ func (p *Provider) SetDefaults() {
 
 // Init the provider.
 func (p *Provider) Init() error {
-	return p.Provider.Init(store.ZK, "zookeeper")
+	ctx := log.With(context.Background(), log.Str(log.ProviderName, "zookeeper"))
+
+	var tlsConfig *tls.Config
+	if p.TLS != nil {
+		var err error
+		tlsConfig, err = p.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The valkeyrie ZooKeeper store supports neither authentication nor TLS.
+	kvStore, err := newStore(p.Endpoints, p.Username, p.Password, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to Connect to KV store: %w", err)
+	}
+
+	p.Provider.SetNamespace(p.Namespace)
+	p.Provider.SetBackoff(p.Backoff)
+	p.Provider.InitStore(kvStore, "zookeeper")
+
+	return nil
 }
@@ -0,0 +1,119 @@
+package oci
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// cosignSignatureAnnotation is the annotation of the cosign signature layers holding the base64 signature of their payload.
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// cosignVerifier verifies the cosign signatures of the artifacts with a public key,
+// the signatures being stored by cosign in the same repository, under the sha256-<digest>.sig tag.
+type cosignVerifier struct {
+	publicKey crypto.PublicKey
+}
+
+func newCosignVerifier(publicKeyPEM []byte) (*cosignVerifier, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return &cosignVerifier{publicKey: publicKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// simpleSigning is the payload signed by cosign.
+type simpleSigning struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// verify checks that one of the signatures of the manifest is valid.
+func (v *cosignVerifier) verify(client *registryClient, manifestDigest string) error {
+	signatureTag := strings.Replace(manifestDigest, ":", "-", 1) + ".sig"
+
+	signatures, _, err := client.getManifest(signatureTag)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return fmt.Errorf("no signature found for %s", manifestDigest)
+		}
+		return fmt.Errorf("unable to get the signatures of %s: %w", manifestDigest, err)
+	}
+
+	for _, layer := range signatures.Layers {
+		encodedSignature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		payload, err := client.getBlob(layer.Digest)
+		if err != nil {
+			return fmt.Errorf("unable to get the signature payload: %w", err)
+		}
+
+		if v.verifyPayload(payload, encodedSignature, manifestDigest) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no valid signature found for %s", manifestDigest)
+}
+
+func (v *cosignVerifier) verifyPayload(payload []byte, encodedSignature, manifestDigest string) bool {
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return false
+	}
+
+	if !v.verifySignature(payload, signature) {
+		return false
+	}
+
+	var signed simpleSigning
+	if err = json.Unmarshal(payload, &signed); err != nil {
+		return false
+	}
+
+	// The signature of another artifact of the repository does not count.
+	return signed.Critical.Image.DockerManifestDigest == manifestDigest
+}
+
+func (v *cosignVerifier) verifySignature(payload, signature []byte) bool {
+	hashed := sha256.Sum256(payload)
+
+	switch publicKey := v.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(publicKey, hashed[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature) == nil ||
+			rsa.VerifyPSS(publicKey, crypto.SHA256, hashed[:], signature, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(publicKey, payload, signature)
+	default:
+		return false
+	}
+}
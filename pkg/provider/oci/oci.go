@@ -0,0 +1,217 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/job"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/traefik/paerser/file"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+const providerName = "oci"
+
+// titleAnnotation is the annotation holding the file names of the layers, e.g. set by oras push.
+const titleAnnotation = "org.opencontainers.image.title"
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Provider is a provider.Provider implementation that pulls the configuration files published as an OCI artifact.
+type Provider struct {
+	Artifact     string            `description:"Reference of the artifact, pinned by tag or digest." json:"artifact,omitempty" toml:"artifact,omitempty" yaml:"artifact,omitempty" export:"true"`
+	Username     string            `description:"Username of the registry." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password     string            `description:"Password of the registry." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	PlainHTTP    bool              `description:"Pull the artifact over plain HTTP." json:"plainHTTP,omitempty" toml:"plainHTTP,omitempty" yaml:"plainHTTP,omitempty" export:"true"`
+	PublicKey    tls.FileOrContent `description:"Public key verifying the cosign signature of the artifact." json:"publicKey,omitempty" toml:"publicKey,omitempty" yaml:"publicKey,omitempty"`
+	PollInterval ptypes.Duration   `description:"Polling interval for the artifact." json:"pollInterval,omitempty" toml:"pollInterval,omitempty" yaml:"pollInterval,omitempty" export:"true"`
+	PollTimeout  ptypes.Duration   `description:"Polling timeout for the registry." json:"pollTimeout,omitempty" toml:"pollTimeout,omitempty" yaml:"pollTimeout,omitempty" export:"true"`
+	TLS          *types.ClientTLS  `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+
+	reference  reference
+	client     *registryClient
+	verifier   *cosignVerifier
+	lastDigest string
+}
+
+// SetDefaults sets the default values.
+func (p *Provider) SetDefaults() {
+	p.PollInterval = ptypes.Duration(time.Minute)
+	p.PollTimeout = ptypes.Duration(30 * time.Second)
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	if p.Artifact == "" {
+		return errors.New("non-empty artifact is required")
+	}
+
+	if p.PollInterval <= 0 {
+		return errors.New("poll interval must be greater than 0")
+	}
+
+	var err error
+	p.reference, err = parseReference(p.Artifact)
+	if err != nil {
+		return err
+	}
+
+	httpClient := &http.Client{
+		Timeout: time.Duration(p.PollTimeout),
+	}
+
+	if p.TLS != nil {
+		tlsConfig, err := p.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return fmt.Errorf("unable to create TLS configuration: %w", err)
+		}
+
+		httpClient.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	p.client = newRegistryClient(httpClient, p.reference, p.PlainHTTP, p.Username, p.Password)
+
+	if p.PublicKey != "" {
+		publicKey, err := p.PublicKey.Read()
+		if err != nil {
+			return fmt.Errorf("unable to read the public key: %w", err)
+		}
+
+		p.verifier, err = newCosignVerifier(publicKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Provide allows the provider to provide configurations to traefik using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	pool.GoCtx(func(routineCtx context.Context) {
+		ctxLog := log.With(routineCtx, log.Str(log.ProviderName, providerName))
+		logger := log.FromContext(ctxLog)
+
+		operation := func() error {
+			ticker := time.NewTicker(time.Duration(p.PollInterval))
+			defer ticker.Stop()
+
+			for {
+				configuration, err := p.pull(ctxLog)
+				if err != nil {
+					return fmt.Errorf("cannot pull the artifact %s: %w", p.Artifact, err)
+				}
+
+				if configuration != nil {
+					configurationChan <- dynamic.Message{
+						ProviderName:  providerName,
+						Configuration: configuration,
+					}
+				}
+
+				select {
+				case <-ticker.C:
+				case <-routineCtx.Done():
+					return nil
+				}
+			}
+		}
+
+		notify := func(err error, time time.Duration) {
+			logger.Errorf("Provider connection error %+v, retrying in %s", err, time)
+		}
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
+		if err != nil {
+			logger.Errorf("Cannot pull the artifact %+v", err)
+		}
+	})
+
+	return nil
+}
+
+// pull returns the configuration of the artifact, or nil when the artifact did not change since the last pull.
+func (p *Provider) pull(ctx context.Context) (*dynamic.Configuration, error) {
+	m, digest, err := p.client.getManifest(p.reference.name())
+	if err != nil {
+		return nil, err
+	}
+
+	if digest == p.lastDigest {
+		return nil, nil
+	}
+
+	if p.verifier != nil {
+		if err = p.verifier.verify(p.client, digest); err != nil {
+			return nil, err
+		}
+	}
+
+	configuration := newConfiguration()
+
+	var files int
+	for _, layer := range m.Layers {
+		title := layer.Annotations[titleAnnotation]
+
+		ext := strings.ToLower(filepath.Ext(title))
+		if ext != ".yml" && ext != ".yaml" && ext != ".json" && ext != ".toml" {
+			log.FromContext(ctx).Debugf("Skipping the layer %s of the artifact: %q is not a configuration file", layer.Digest, title)
+			continue
+		}
+
+		data, err := p.client.getBlob(layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = file.DecodeContent(string(data), ext, configuration); err != nil {
+			return nil, fmt.Errorf("cannot decode the configuration file %s: %w", title, err)
+		}
+
+		files++
+	}
+
+	if files == 0 {
+		return nil, fmt.Errorf("no configuration file found in the artifact %s", digest)
+	}
+
+	log.FromContext(ctx).Infof("Pulled the configuration of the artifact %s", digest)
+	p.lastDigest = digest
+
+	return configuration, nil
+}
+
+func newConfiguration() *dynamic.Configuration {
+	return &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     make(map[string]*dynamic.Router),
+			Middlewares: make(map[string]*dynamic.Middleware),
+			Services:    make(map[string]*dynamic.Service),
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  make(map[string]*dynamic.TCPRouter),
+			Services: make(map[string]*dynamic.TCPService),
+		},
+		TLS: &dynamic.TLSConfiguration{
+			Stores:  make(map[string]tls.Store),
+			Options: make(map[string]tls.Options),
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers:  make(map[string]*dynamic.UDPRouter),
+			Services: make(map[string]*dynamic.UDPService),
+		},
+	}
+}
@@ -0,0 +1,301 @@
+package oci
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	testCases := []struct {
+		desc   string
+		value  string
+		expRef reference
+		expErr bool
+	}{
+		{
+			desc:   "tag",
+			value:  "registry.example.com/team/config:prod",
+			expRef: reference{registry: "registry.example.com", repository: "team/config", tag: "prod"},
+		},
+		{
+			desc:   "default tag",
+			value:  "registry.example.com:5000/config",
+			expRef: reference{registry: "registry.example.com:5000", repository: "config", tag: "latest"},
+		},
+		{
+			desc:   "digest",
+			value:  "registry.example.com:5000/team/config@" + digest,
+			expRef: reference{registry: "registry.example.com:5000", repository: "team/config", digest: digest},
+		},
+		{
+			desc:   "Docker Hub",
+			value:  "docker.io/team/config:prod",
+			expRef: reference{registry: "registry-1.docker.io", repository: "team/config", tag: "prod"},
+		},
+		{
+			desc:   "missing registry",
+			value:  "config:prod",
+			expErr: true,
+		},
+		{
+			desc:   "unsupported digest",
+			value:  "registry.example.com/team/config@md5:abc",
+			expErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ref, err := parseReference(test.value)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expRef, ref)
+		})
+	}
+}
+
+func TestProvider_pull(t *testing.T) {
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	registry := newFakeRegistry(t)
+
+	configuration := []byte("http:\n  routers:\n    api:\n      rule: Host(`api.example.com`)\n      service: api\n")
+	digest := registry.pushArtifact("config", "prod", map[string][]byte{"routers.yml": configuration, "README.md": []byte("# Config")})
+	registry.sign("config", digest, digest, signingKey)
+
+	unsignedDigest := registry.pushArtifact("config", "unsigned", map[string][]byte{"routers.yml": configuration})
+
+	forgedDigest := registry.pushArtifact("config", "forged", map[string][]byte{"routers.yml": configuration})
+	// The signature of the prod artifact is copied to the forged one.
+	registry.sign("config", forgedDigest, digest, signingKey)
+
+	testCases := []struct {
+		desc      string
+		tag       string
+		publicKey *ecdsa.PrivateKey
+		expDigest string
+		expErr    bool
+	}{
+		{
+			desc:      "signed artifact",
+			tag:       "prod",
+			publicKey: signingKey,
+			expDigest: digest,
+		},
+		{
+			desc:      "unsigned artifact without verification",
+			tag:       "unsigned",
+			expDigest: unsignedDigest,
+		},
+		{
+			desc:      "unsigned artifact",
+			tag:       "unsigned",
+			publicKey: signingKey,
+			expErr:    true,
+		},
+		{
+			desc:      "artifact signed with another key",
+			tag:       "prod",
+			publicKey: otherKey,
+			expErr:    true,
+		},
+		{
+			desc:      "signature of another artifact",
+			tag:       "forged",
+			publicKey: signingKey,
+			expErr:    true,
+		},
+		{
+			desc:   "unknown tag",
+			tag:    "staging",
+			expErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &Provider{
+				Artifact:     strings.TrimPrefix(registry.server.URL, "http://") + "/config:" + test.tag,
+				PlainHTTP:    true,
+				Username:     "user",
+				Password:     "secret",
+				PollInterval: ptypes.Duration(time.Second),
+			}
+			if test.publicKey != nil {
+				provider.PublicKey = tls.FileOrContent(encodePublicKey(t, test.publicKey))
+			}
+			require.NoError(t, provider.Init())
+
+			conf, err := provider.pull(context.Background())
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, conf)
+			require.Contains(t, conf.HTTP.Routers, "api")
+			assert.Equal(t, "Host(`api.example.com`)", conf.HTTP.Routers["api"].Rule)
+			assert.Equal(t, test.expDigest, provider.lastDigest)
+
+			// The configuration is only sent again once the artifact changed.
+			conf, err = provider.pull(context.Background())
+			require.NoError(t, err)
+			assert.Nil(t, conf)
+		})
+	}
+}
+
+// fakeRegistry is a registry serving the manifests and the blobs to the clients authenticated with a bearer token.
+type fakeRegistry struct {
+	t         *testing.T
+	server    *httptest.Server
+	manifests map[string][]byte
+	blobs     map[string][]byte
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	t.Helper()
+
+	registry := &fakeRegistry{
+		t:         t,
+		manifests: make(map[string][]byte),
+		blobs:     make(map[string][]byte),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"token":"valid-token"}`))
+	})
+	mux.HandleFunc("/v2/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer valid-token" {
+			rw.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, registry.server.URL))
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.TrimPrefix(req.URL.Path, "/v2/")
+
+		if content, ok := registry.manifests[path]; ok {
+			rw.Header().Set("Content-Type", mediaTypeOCIManifest)
+			_, _ = rw.Write(content)
+			return
+		}
+
+		if content, ok := registry.blobs[path]; ok {
+			_, _ = rw.Write(content)
+			return
+		}
+
+		rw.WriteHeader(http.StatusNotFound)
+	})
+
+	registry.server = httptest.NewServer(mux)
+	t.Cleanup(registry.server.Close)
+
+	return registry
+}
+
+// pushArtifact pushes an artifact made of the files, and returns the digest of its manifest.
+func (r *fakeRegistry) pushArtifact(repository, tag string, files map[string][]byte) string {
+	r.t.Helper()
+
+	m := manifest{MediaType: mediaTypeOCIManifest}
+	for name, content := range files {
+		m.Layers = append(m.Layers, r.pushBlob(repository, content, map[string]string{titleAnnotation: name}))
+	}
+
+	return r.pushManifest(repository, tag, m)
+}
+
+// sign pushes the signature of the signed digest under the signature tag of the digest.
+func (r *fakeRegistry) sign(repository, digest, signedDigest string, key *ecdsa.PrivateKey) {
+	r.t.Helper()
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, repository, signedDigest))
+
+	hashed := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hashed[:])
+	require.NoError(r.t, err)
+
+	m := manifest{
+		MediaType: mediaTypeOCIManifest,
+		Layers: []descriptor{
+			r.pushBlob(repository, payload, map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}),
+		},
+	}
+
+	r.pushManifest(repository, strings.Replace(digest, ":", "-", 1)+".sig", m)
+}
+
+func (r *fakeRegistry) pushBlob(repository string, content []byte, annotations map[string]string) descriptor {
+	digest := computeDigest(content)
+	r.blobs[repository+"/blobs/"+digest] = content
+
+	return descriptor{
+		MediaType:   "application/vnd.oci.image.layer.v1.tar",
+		Digest:      digest,
+		Size:        int64(len(content)),
+		Annotations: annotations,
+	}
+}
+
+func (r *fakeRegistry) pushManifest(repository, tag string, m manifest) string {
+	content, err := json.Marshal(m)
+	require.NoError(r.t, err)
+
+	digest := computeDigest(content)
+	r.manifests[repository+"/manifests/"+tag] = content
+	r.manifests[repository+"/manifests/"+digest] = content
+
+	return digest
+}
+
+func encodePublicKey(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
@@ -0,0 +1,344 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// maxBlobSize is the size of the largest manifest or configuration file read from the registry.
+	maxBlobSize = 4 * 1024 * 1024
+)
+
+var digestRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// reference is a reference to an artifact of a registry.
+type reference struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+// parseReference parses an artifact reference, e.g. registry.example.com/team/config:prod or registry.example.com/team/config@sha256:...
+func parseReference(value string) (reference, error) {
+	var ref reference
+
+	slash := strings.Index(value, "/")
+	if slash <= 0 {
+		return ref, fmt.Errorf("invalid artifact reference %q: missing registry", value)
+	}
+
+	ref.registry = value[:slash]
+	if ref.registry == "docker.io" {
+		ref.registry = "registry-1.docker.io"
+	}
+
+	remainder := value[slash+1:]
+
+	if at := strings.Index(remainder, "@"); at >= 0 {
+		ref.digest = remainder[at+1:]
+		remainder = remainder[:at]
+
+		if !digestRegexp.MatchString(ref.digest) {
+			return ref, fmt.Errorf("invalid artifact reference %q: unsupported digest %q", value, ref.digest)
+		}
+	}
+
+	// The tag follows the last colon of the repository path, the colons of the registry being ports.
+	if colon := strings.LastIndex(remainder, ":"); colon >= 0 && !strings.Contains(remainder[colon:], "/") {
+		ref.tag = remainder[colon+1:]
+		remainder = remainder[:colon]
+	}
+
+	ref.repository = remainder
+	if ref.repository == "" {
+		return ref, fmt.Errorf("invalid artifact reference %q: missing repository", value)
+	}
+
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = "latest"
+	}
+
+	return ref, nil
+}
+
+// name returns the tag or the digest the artifact is pulled with, the digest taking precedence.
+func (r reference) name() string {
+	if r.digest != "" {
+		return r.digest
+	}
+
+	return r.tag
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// registryClient pulls the manifests and the blobs of a repository with the distribution API,
+// authenticating with the bearer token or basic schemes requested by the registry.
+type registryClient struct {
+	httpClient *http.Client
+	baseURL    string
+	repository string
+	username   string
+	password   string
+
+	mu            sync.Mutex
+	authorization string
+}
+
+func newRegistryClient(httpClient *http.Client, ref reference, plainHTTP bool, username, password string) *registryClient {
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+
+	return &registryClient{
+		httpClient: httpClient,
+		baseURL:    scheme + "://" + ref.registry + "/v2/" + ref.repository,
+		repository: ref.repository,
+		username:   username,
+		password:   password,
+	}
+}
+
+// getManifest returns the manifest of the tag or digest, with its digest.
+func (c *registryClient) getManifest(name string) (*manifest, string, error) {
+	header := http.Header{}
+	header.Set("Accept", mediaTypeOCIManifest+", "+mediaTypeDockerManifest)
+
+	data, err := c.get("/manifests/"+name, header)
+	if err != nil {
+		return nil, "", err
+	}
+
+	digest := computeDigest(data)
+	if digestRegexp.MatchString(name) && digest != name {
+		return nil, "", fmt.Errorf("the manifest digest %s does not match the pinned digest %s", digest, name)
+	}
+
+	var m manifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, "", fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return &m, digest, nil
+}
+
+// getBlob returns the content of the blob, checked against its digest.
+func (c *registryClient) getBlob(digest string) ([]byte, error) {
+	if !digestRegexp.MatchString(digest) {
+		return nil, fmt.Errorf("unsupported blob digest %q", digest)
+	}
+
+	data, err := c.get("/blobs/"+digest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if computeDigest(data) != digest {
+		return nil, fmt.Errorf("the content of the blob %s does not match its digest", digest)
+	}
+
+	return data, nil
+}
+
+var errNotFound = errors.New("not found")
+
+func (c *registryClient) get(path string, header http.Header) ([]byte, error) {
+	resp, err := c.do(path, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		if err = c.authenticate(challenge); err != nil {
+			return nil, err
+		}
+
+		resp, err = c.do(path, header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s%s: %w", c.repository, path, errNotFound)
+	default:
+		return nil, fmt.Errorf("unexpected response code %d for %s%s", resp.StatusCode, c.repository, path)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBlobSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxBlobSize {
+		return nil, fmt.Errorf("%s%s exceeds %d bytes", c.repository, path, maxBlobSize)
+	}
+
+	return data, nil
+}
+
+func (c *registryClient) do(path string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	c.mu.Lock()
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	c.mu.Unlock()
+
+	return c.httpClient.Do(req)
+}
+
+// authenticate answers the authentication challenge of the registry.
+func (c *registryClient) authenticate(challenge string) error {
+	scheme, params := parseChallenge(challenge)
+
+	switch scheme {
+	case "basic":
+		if c.username == "" {
+			return errors.New("the registry requires credentials")
+		}
+
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(c.username, c.password)
+
+		c.mu.Lock()
+		c.authorization = req.Header.Get("Authorization")
+		c.mu.Unlock()
+
+		return nil
+
+	case "bearer":
+		token, err := c.fetchToken(params)
+		if err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		c.authorization = "Bearer " + token
+		c.mu.Unlock()
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+// fetchToken fetches a bearer token from the token server of the registry, anonymously or with the credentials.
+func (c *registryClient) fetchToken(params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + c.repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to get a token from %s: unexpected response code %d", realm.Host, resp.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxBlobSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+
+	return "", errors.New("empty token response")
+}
+
+var challengeParamRegexp = regexp.MustCompile(`([a-zA-Z]+)="([^"]*)"`)
+
+// parseChallenge parses a WWW-Authenticate challenge, e.g. Bearer realm="https://auth.example.com/token",service="registry.example.com".
+func parseChallenge(challenge string) (string, map[string]string) {
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+
+	params := make(map[string]string)
+	if len(parts) == 2 {
+		for _, match := range challengeParamRegexp.FindAllStringSubmatch(parts[1], -1) {
+			params[strings.ToLower(match[1])] = match[2]
+		}
+	}
+
+	return strings.ToLower(parts[0]), params
+}
+
+func computeDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// Staleness tracks the last successful discovery of a discovery provider.
+// While the discovery API is unreachable, the last discovered configuration keeps being served,
+// until it gets older than the TTL, when an empty configuration is sent to drop its servers.
+type Staleness struct {
+	providerName string
+	ttl          time.Duration
+	gauge        metrics.Gauge
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	expired     bool
+}
+
+// NewStaleness creates a Staleness for the provider, whose configuration is served until it is older than the TTL,
+// 0 meaning it is served until the discovery API is reachable again.
+// The gauge, when not nil, records how long, in seconds, since the configuration was last discovered.
+func NewStaleness(providerName string, ttl time.Duration, gauge metrics.Gauge) *Staleness {
+	return &Staleness{
+		providerName: providerName,
+		ttl:          ttl,
+		gauge:        gauge,
+	}
+}
+
+// Refreshed records a successful discovery.
+func (s *Staleness) Refreshed() {
+	s.mu.Lock()
+	s.lastSuccess = time.Now()
+	s.expired = false
+	s.mu.Unlock()
+
+	if s.gauge != nil {
+		s.gauge.With("provider", s.providerName).Set(0)
+	}
+}
+
+// Watch checks the staleness of the configuration on each interval, until the context is done.
+func (s *Staleness) Watch(ctx context.Context, interval time.Duration, configurationChan chan<- dynamic.Message) {
+	if s.ttl <= 0 && s.gauge == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.check(time.Now()) {
+				continue
+			}
+
+			log.FromContext(ctx).Warnf("The configuration was last discovered more than %s ago, dropping it", s.ttl)
+
+			configurationChan <- dynamic.Message{
+				ProviderName:  s.providerName,
+				Configuration: emptyConfiguration(),
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check updates the staleness gauge, and returns whether the configuration just expired.
+func (s *Staleness) check(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastSuccess.IsZero() {
+		return false
+	}
+
+	staleness := now.Sub(s.lastSuccess)
+	if s.gauge != nil {
+		s.gauge.With("provider", s.providerName).Set(staleness.Seconds())
+	}
+
+	if s.ttl <= 0 || s.expired || staleness <= s.ttl {
+		return false
+	}
+
+	s.expired = true
+	return true
+}
+
+// emptyConfiguration returns a configuration without any element,
+// which, unlike a nil one, replaces the previous configuration of the provider.
+func emptyConfiguration() *dynamic.Configuration {
+	return &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     make(map[string]*dynamic.Router),
+			Middlewares: make(map[string]*dynamic.Middleware),
+			Services:    make(map[string]*dynamic.Service),
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  make(map[string]*dynamic.TCPRouter),
+			Services: make(map[string]*dynamic.TCPService),
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers:  make(map[string]*dynamic.UDPRouter),
+			Services: make(map[string]*dynamic.UDPService),
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleness_check(t *testing.T) {
+	gauge := &testhelpers.CollectingGauge{}
+	staleness := NewStaleness("foo", time.Minute, gauge)
+
+	// Nothing was discovered yet.
+	assert.False(t, staleness.check(time.Now()))
+
+	staleness.Refreshed()
+	assert.Equal(t, []string{"provider", "foo"}, gauge.LastLabelValues)
+	assert.Equal(t, float64(0), gauge.GaugeValue)
+
+	now := staleness.lastSuccess
+
+	assert.False(t, staleness.check(now.Add(30*time.Second)))
+	assert.Equal(t, float64(30), gauge.GaugeValue)
+
+	// The configuration expires once.
+	assert.True(t, staleness.check(now.Add(2*time.Minute)))
+	assert.Equal(t, float64(120), gauge.GaugeValue)
+	assert.False(t, staleness.check(now.Add(3*time.Minute)))
+
+	// Until it is discovered again.
+	staleness.Refreshed()
+	assert.Equal(t, float64(0), gauge.GaugeValue)
+	assert.True(t, staleness.check(staleness.lastSuccess.Add(2*time.Minute)))
+}
+
+func TestStaleness_check_noTTL(t *testing.T) {
+	staleness := NewStaleness("foo", 0, nil)
+	staleness.Refreshed()
+
+	assert.False(t, staleness.check(staleness.lastSuccess.Add(24*time.Hour)))
+}
+
+func TestStaleness_Watch(t *testing.T) {
+	staleness := NewStaleness("foo", 10*time.Millisecond, nil)
+	staleness.Refreshed()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configurationChan := make(chan dynamic.Message)
+	go staleness.Watch(ctx, 5*time.Millisecond, configurationChan)
+
+	select {
+	case message := <-configurationChan:
+		assert.Equal(t, "foo", message.ProviderName)
+		require.NotNil(t, message.Configuration)
+		require.NotNil(t, message.Configuration.HTTP)
+		assert.NotNil(t, message.Configuration.HTTP.Routers)
+		assert.Empty(t, message.Configuration.HTTP.Routers)
+		assert.Empty(t, message.Configuration.HTTP.Services)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the expired configuration was not dropped")
+	}
+}
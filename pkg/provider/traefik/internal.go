@@ -236,9 +236,17 @@ func (i *Provider) pingConfiguration(cfg *dynamic.Configuration) {
 			Priority:    math.MaxInt32,
 			Rule:        "PathPrefix(`/ping`)",
 		}
+
+		cfg.HTTP.Routers["health"] = &dynamic.Router{
+			EntryPoints: []string{i.staticCfg.Ping.EntryPoint},
+			Service:     "health@internal",
+			Priority:    math.MaxInt32,
+			Rule:        "PathPrefix(`/health`)",
+		}
 	}
 
 	cfg.HTTP.Services["ping"] = &dynamic.Service{}
+	cfg.HTTP.Services["health"] = &dynamic.Service{}
 }
 
 func (i *Provider) restConfiguration(cfg *dynamic.Configuration) {
@@ -270,7 +278,66 @@ func (i *Provider) prometheusConfiguration(cfg *dynamic.Configuration) {
 			Priority:    math.MaxInt32,
 			Rule:        "PathPrefix(`/metrics`)",
 		}
+
+		i.prometheusSecurity(cfg, cfg.HTTP.Routers["prometheus"])
 	}
 
 	cfg.HTTP.Services["prometheus"] = &dynamic.Service{}
 }
+
+// prometheusSecurity adds the TLS and the access restrictions configured for the metrics to the router.
+func (i *Provider) prometheusSecurity(cfg *dynamic.Configuration, router *dynamic.Router) {
+	prom := i.staticCfg.Metrics.Prometheus
+
+	if len(prom.IPWhiteList) > 0 {
+		cfg.HTTP.Middlewares["prometheus-ipwhitelist"] = &dynamic.Middleware{
+			IPWhiteList: &dynamic.IPWhiteList{SourceRange: prom.IPWhiteList},
+		}
+		router.Middlewares = append(router.Middlewares, "prometheus-ipwhitelist")
+	}
+
+	if prom.BasicAuth != nil {
+		cfg.HTTP.Middlewares["prometheus-basicauth"] = &dynamic.Middleware{
+			BasicAuth: &dynamic.BasicAuth{
+				Users:        prom.BasicAuth.Users,
+				UsersFile:    prom.BasicAuth.UsersFile,
+				RemoveHeader: true,
+			},
+		}
+		router.Middlewares = append(router.Middlewares, "prometheus-basicauth")
+	}
+
+	if prom.TLS == nil {
+		return
+	}
+
+	router.TLS = &dynamic.RouterTLSConfig{}
+
+	// The certificate is the default one of a dedicated store, served on the entry point of the metrics whatever the server name,
+	// instead of being added to the default store of all the entry points.
+	if prom.TLS.CertFile != "" || prom.TLS.KeyFile != "" {
+		cfg.TLS.Stores["prometheus"] = tls.Store{
+			DefaultCertificate: &tls.Certificate{
+				CertFile: tls.FileOrContent(prom.TLS.CertFile),
+				KeyFile:  tls.FileOrContent(prom.TLS.KeyFile),
+			},
+		}
+	}
+
+	// The options are applied to all the connections of the entry point (see the router factory),
+	// as the router has no host to select them from the server name.
+	if len(prom.TLS.CAFiles) > 0 {
+		var caFiles []tls.FileOrContent
+		for _, caFile := range prom.TLS.CAFiles {
+			caFiles = append(caFiles, tls.FileOrContent(caFile))
+		}
+
+		cfg.TLS.Options["prometheus"] = tls.Options{
+			ClientAuth: tls.ClientAuth{
+				CAFiles:        caFiles,
+				ClientAuthType: "RequireAndVerifyClientCert",
+			},
+		}
+		router.TLS.Options = "prometheus"
+	}
+}
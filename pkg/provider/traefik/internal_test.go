@@ -168,6 +168,25 @@ func Test_createConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "prometheus_secured.json",
+			staticCfg: static.Configuration{
+				Metrics: &types.Metrics{
+					Prometheus: &types.Prometheus{
+						EntryPoint: "test",
+						TLS: &types.MetricsTLS{
+							CertFile: "cert.pem",
+							KeyFile:  "key.pem",
+							CAFiles:  []string{"ca.pem"},
+						},
+						BasicAuth: &types.MetricsBasicAuth{
+							Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+						},
+						IPWhiteList: []string{"10.0.0.0/8"},
+					},
+				},
+			},
+		},
 		{
 			desc: "models.json",
 			staticCfg: static.Configuration{
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"crypto/tls"
+	"errors"
+	"time"
+
+	goredis "github.com/go-redis/redis/v7"
+)
+
+// Sentinel holds the configuration of the Redis Sentinel monitoring the master.
+type Sentinel struct {
+	MasterName string `description:"Name of the master monitored by the sentinels." json:"masterName,omitempty" toml:"masterName,omitempty" yaml:"masterName,omitempty" export:"true"`
+	Username   string `description:"Username of the sentinels." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password   string `description:"Password of the sentinels." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// Options holds the options of the connection to Redis.
+type Options struct {
+	// Endpoints are the addresses of the Redis server,
+	// of the sentinels when Sentinel is set, or of the seed nodes of the cluster when Cluster is set.
+	Endpoints []string
+	// Username is the ACL user, the default user being used when empty.
+	Username string
+	Password string
+	DB       int
+	Sentinel *Sentinel
+	Cluster  bool
+	TLS      *tls.Config
+
+	// OnConnect is called on each new connection.
+	OnConnect func(conn *goredis.Conn) error
+}
+
+// NewClient creates a client of the standalone Redis server, of the master monitored by the sentinels,
+// or of the Redis Cluster, depending on the options.
+func NewClient(opts Options) (goredis.UniversalClient, error) {
+	if len(opts.Endpoints) == 0 {
+		return nil, errors.New("no endpoint")
+	}
+
+	switch {
+	case opts.Sentinel != nil && opts.Cluster:
+		return nil, errors.New("sentinel and cluster are mutually exclusive")
+
+	case opts.Sentinel != nil:
+		if opts.Sentinel.MasterName == "" {
+			return nil, errors.New("the name of the master monitored by the sentinels is required")
+		}
+
+		return goredis.NewFailoverClient(&goredis.FailoverOptions{
+			MasterName:       opts.Sentinel.MasterName,
+			SentinelAddrs:    opts.Endpoints,
+			SentinelUsername: opts.Sentinel.Username,
+			SentinelPassword: opts.Sentinel.Password,
+			OnConnect:        opts.OnConnect,
+			Username:         opts.Username,
+			Password:         opts.Password,
+			DB:               opts.DB,
+			DialTimeout:      3 * time.Second,
+			TLSConfig:        opts.TLS,
+		}), nil
+
+	case opts.Cluster:
+		// A Redis Cluster only has the database 0.
+		if opts.DB != 0 {
+			return nil, errors.New("the database cannot be selected in a cluster")
+		}
+
+		return goredis.NewClusterClient(&goredis.ClusterOptions{
+			Addrs:       opts.Endpoints,
+			OnConnect:   opts.OnConnect,
+			Username:    opts.Username,
+			Password:    opts.Password,
+			DialTimeout: 3 * time.Second,
+			TLSConfig:   opts.TLS,
+		}), nil
+
+	default:
+		if len(opts.Endpoints) > 1 {
+			return nil, errors.New("multiple endpoints are only supported with sentinel or cluster")
+		}
+
+		return goredis.NewClient(&goredis.Options{
+			Addr:        opts.Endpoints[0],
+			OnConnect:   opts.OnConnect,
+			Username:    opts.Username,
+			Password:    opts.Password,
+			DB:          opts.DB,
+			DialTimeout: 3 * time.Second,
+			TLSConfig:   opts.TLS,
+		}), nil
+	}
+}
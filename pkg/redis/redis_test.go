@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"testing"
+
+	goredis "github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		opts          Options
+		expectedError bool
+		expected      interface{}
+	}{
+		{
+			desc:          "no endpoint",
+			expectedError: true,
+		},
+		{
+			desc:     "standalone",
+			opts:     Options{Endpoints: []string{"127.0.0.1:6379"}, DB: 1},
+			expected: &goredis.Client{},
+		},
+		{
+			desc:          "standalone with multiple endpoints",
+			opts:          Options{Endpoints: []string{"127.0.0.1:6379", "127.0.0.1:6380"}},
+			expectedError: true,
+		},
+		{
+			desc:     "sentinel",
+			opts:     Options{Endpoints: []string{"127.0.0.1:26379", "127.0.0.1:26380"}, Sentinel: &Sentinel{MasterName: "master"}},
+			expected: &goredis.Client{},
+		},
+		{
+			desc:          "sentinel without master name",
+			opts:          Options{Endpoints: []string{"127.0.0.1:26379"}, Sentinel: &Sentinel{}},
+			expectedError: true,
+		},
+		{
+			desc:     "cluster",
+			opts:     Options{Endpoints: []string{"127.0.0.1:7000", "127.0.0.1:7001"}, Cluster: true},
+			expected: &goredis.ClusterClient{},
+		},
+		{
+			desc:          "cluster with database",
+			opts:          Options{Endpoints: []string{"127.0.0.1:7000"}, Cluster: true, DB: 1},
+			expectedError: true,
+		},
+		{
+			desc:          "sentinel and cluster",
+			opts:          Options{Endpoints: []string{"127.0.0.1:7000"}, Sentinel: &Sentinel{MasterName: "master"}, Cluster: true},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClient(test.opts)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = client.Close() })
+
+			assert.IsType(t, test.expected, client)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "traefik/http/routers", normalize("traefik/http/routers"))
+	assert.Equal(t, "traefik/http/routers/", prefix(normalize("traefik/http/routers")))
+	assert.Equal(t, "", prefix(normalize("")))
+}
+
+func TestStore_codec(t *testing.T) {
+	s, err := NewStore(Options{Endpoints: []string{"127.0.0.1:6379"}})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+
+	// The values are stored as is, as by the former valkeyrie Redis store.
+	data, err := s.encode("traefik/http/routers/foo/rule", []byte("Host(`foo.bar`)"))
+	require.NoError(t, err)
+	assert.Equal(t, "Host(`foo.bar`)", data)
+
+	pair, err := s.decode("traefik/http/routers/foo/rule", []byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "traefik/http/routers/foo/rule", pair.Key)
+	assert.Equal(t, []byte("Host(`foo.bar`)"), pair.Value)
+}
@@ -0,0 +1,477 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	valkeyrieredis "github.com/abronan/valkeyrie/store/redis"
+	goredis "github.com/go-redis/redis/v7"
+)
+
+// keyspaceEvents are the classes of the keyspace notifications enabled on the servers, to watch the keys.
+const keyspaceEvents = "KEA"
+
+// casScript sets the key to the new value, when its current value is the previous one.
+var casScript = goredis.NewScript(`
+local value = redis.call('get', KEYS[1])
+if not value then
+  return -1
+end
+if value ~= ARGV[1] then
+  return 0
+end
+if ARGV[3] == '0' then
+  redis.call('set', KEYS[1], ARGV[2])
+else
+  redis.call('set', KEYS[1], ARGV[2], 'px', ARGV[3])
+end
+return 1
+`)
+
+// cadScript deletes the key, when its current value is the previous one.
+var cadScript = goredis.NewScript(`
+local value = redis.call('get', KEYS[1])
+if not value then
+  return -1
+end
+if value ~= ARGV[1] then
+  return 0
+end
+redis.call('del', KEYS[1])
+return 1
+`)
+
+var _ store.Store = (*Store)(nil)
+
+// Store is a valkeyrie store backed by Redis, whatever its deployment.
+type Store struct {
+	client goredis.UniversalClient
+	// codec is the one of the valkeyrie Redis store, which stores the values as is,
+	// so the keys written by the former store, or by any Redis client, are still read.
+	codec valkeyrieredis.Codec
+}
+
+// NewStore creates a store on the Redis described by the options.
+func NewStore(opts Options) (*Store, error) {
+	// The keyspace notifications are enabled on each connection, as the master can change with Sentinel,
+	// and the nodes of a cluster only notify the changes of their own keys.
+	opts.OnConnect = func(conn *goredis.Conn) error {
+		// The notifications can be enabled in the configuration of the servers instead,
+		// e.g. when the ACL of the user forbids the CONFIG command, hence the error is ignored.
+		_ = conn.ConfigSet("notify-keyspace-events", keyspaceEvents).Err()
+		return nil
+	}
+
+	client, err := NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{client: client, codec: valkeyrieredis.RawCodec{}}, nil
+}
+
+// Put stores the value at the key.
+func (s *Store) Put(key string, value []byte, options *store.WriteOptions) error {
+	key = normalize(key)
+
+	data, err := s.encode(key, value)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(key, data, ttl(options)).Err()
+}
+
+// Get returns the value of the key.
+func (s *Store) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	key = normalize(key)
+
+	data, err := s.client.Get(key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, store.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decode(key, data)
+}
+
+// Delete deletes the key.
+func (s *Store) Delete(key string) error {
+	return s.client.Del(normalize(key)).Err()
+}
+
+// Exists reports whether the key exists.
+func (s *Store) Exists(key string, _ *store.ReadOptions) (bool, error) {
+	n, err := s.client.Exists(normalize(key)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// Watch sends the value of the key, and then its new value on each change, until stopCh is closed.
+// An empty pair is sent when the key is deleted.
+func (s *Store) Watch(key string, stopCh <-chan struct{}, _ *store.ReadOptions) (<-chan *store.KVPair, error) {
+	key = normalize(key)
+
+	events, err := s.keyspaceEvents(key, stopCh)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCh := make(chan *store.KVPair)
+
+	go func() {
+		defer close(watchCh)
+
+		for {
+			pair, err := s.Get(key, nil)
+			if errors.Is(err, store.ErrKeyNotFound) {
+				pair = &store.KVPair{}
+			} else if err != nil {
+				return
+			}
+
+			select {
+			case watchCh <- pair:
+			case <-stopCh:
+				return
+			}
+
+			if _, ok := <-events; !ok {
+				return
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+// WatchTree sends the pairs of the directory, and then its new pairs on each change, until stopCh is closed.
+func (s *Store) WatchTree(directory string, stopCh <-chan struct{}, _ *store.ReadOptions) (<-chan []*store.KVPair, error) {
+	directory = normalize(directory)
+
+	events, err := s.keyspaceEvents(prefix(directory)+"*", stopCh)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCh := make(chan []*store.KVPair)
+
+	go func() {
+		defer close(watchCh)
+
+		for {
+			pairs, err := s.List(directory, nil)
+			if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+				return
+			}
+
+			select {
+			case watchCh <- pairs:
+			case <-stopCh:
+				return
+			}
+
+			if _, ok := <-events; !ok {
+				return
+			}
+		}
+	}()
+
+	return watchCh, nil
+}
+
+// NewLock is not supported.
+func (s *Store) NewLock(string, *store.LockOptions) (store.Locker, error) {
+	return nil, store.ErrCallNotSupported
+}
+
+// List returns the pairs of the directory.
+func (s *Store) List(directory string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	directory = normalize(directory)
+
+	keys, err := s.keys(prefix(directory) + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	// The values are read with a GET per key, as the keys of a cluster are spread over its nodes.
+	cmds := make([]*goredis.StringCmd, len(keys))
+	_, err = s.client.Pipelined(func(pipe goredis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, err
+	}
+
+	var pairs []*store.KVPair
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if errors.Is(err, goredis.Nil) {
+			// The key was deleted in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pair, err := s.decode(keys[i], data)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
+
+// DeleteTree deletes the keys of the directory.
+func (s *Store) DeleteTree(directory string) error {
+	keys, err := s.keys(prefix(normalize(directory)) + "*")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Pipelined(func(pipe goredis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(key)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// AtomicPut sets the key to the value, when its current value is the previous one,
+// or when it does not exist if previous is nil.
+func (s *Store) AtomicPut(key string, value []byte, previous *store.KVPair, options *store.WriteOptions) (bool, *store.KVPair, error) {
+	key = normalize(key)
+	pair := &store.KVPair{Key: key, Value: value}
+
+	data, err := s.encode(key, value)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if previous == nil {
+		ok, err := s.client.SetNX(key, data, ttl(options)).Result()
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			return false, nil, store.ErrKeyExists
+		}
+		return true, pair, nil
+	}
+
+	previousData, err := s.encode(key, previous.Value)
+	if err != nil {
+		return false, nil, err
+	}
+
+	result, err := casScript.Run(s.client, []string{key}, previousData, data, ttl(options).Milliseconds()).Int()
+	if err != nil {
+		return false, nil, err
+	}
+
+	if err := scriptError(result); err != nil {
+		return false, nil, err
+	}
+
+	return true, pair, nil
+}
+
+// AtomicDelete deletes the key, when its current value is the previous one.
+func (s *Store) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	if previous == nil {
+		return false, store.ErrPreviousNotSpecified
+	}
+
+	key = normalize(key)
+
+	previousData, err := s.encode(key, previous.Value)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := cadScript.Run(s.client, []string{key}, previousData).Int()
+	if err != nil {
+		return false, err
+	}
+
+	if err := scriptError(result); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Close closes the connections to Redis.
+func (s *Store) Close() {
+	_ = s.client.Close()
+}
+
+// encode returns the data stored at the key for the value.
+func (s *Store) encode(key string, value []byte) (string, error) {
+	return s.codec.Encode(&store.KVPair{Key: key, Value: value})
+}
+
+// decode returns the pair of the data stored at the key.
+func (s *Store) decode(key string, data []byte) (*store.KVPair, error) {
+	pair := &store.KVPair{}
+	if err := s.codec.Decode(data, pair); err != nil {
+		return nil, err
+	}
+
+	if pair.Key == "" {
+		pair.Key = key
+	}
+
+	return pair, nil
+}
+
+// keys returns the keys matching the pattern.
+func (s *Store) keys(pattern string) ([]string, error) {
+	var mu sync.Mutex
+	var keys []string
+
+	err := s.forEachMaster(func(client goredis.UniversalClient) error {
+		iter := client.Scan(0, pattern, 100).Iterator()
+		for iter.Next() {
+			mu.Lock()
+			keys = append(keys, iter.Val())
+			mu.Unlock()
+		}
+		return iter.Err()
+	})
+
+	return keys, err
+}
+
+// keyspaceEvents subscribes to the keyspace notifications of the keys matching the pattern.
+// The returned channel receives a value when keys changed since the last received value,
+// and is closed once stopCh is closed.
+func (s *Store) keyspaceEvents(pattern string, stopCh <-chan struct{}) (<-chan struct{}, error) {
+	var mu sync.Mutex
+	var subscriptions []*goredis.PubSub
+
+	err := s.forEachMaster(func(client goredis.UniversalClient) error {
+		pubSub := client.PSubscribe("__keyspace*__:" + pattern)
+
+		// Waits for the confirmation of the subscription.
+		if _, err := pubSub.Receive(); err != nil {
+			_ = pubSub.Close()
+			return err
+		}
+
+		mu.Lock()
+		subscriptions = append(subscriptions, pubSub)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		for _, pubSub := range subscriptions {
+			_ = pubSub.Close()
+		}
+		return nil, err
+	}
+
+	events := make(chan struct{}, 1)
+
+	var wg sync.WaitGroup
+	for _, pubSub := range subscriptions {
+		wg.Add(1)
+		go func(messages <-chan *goredis.Message) {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stopCh:
+					return
+				case _, ok := <-messages:
+					if !ok {
+						return
+					}
+
+					select {
+					case events <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}(pubSub.Channel())
+	}
+
+	go func() {
+		<-stopCh
+
+		for _, pubSub := range subscriptions {
+			_ = pubSub.Close()
+		}
+
+		wg.Wait()
+		close(events)
+	}()
+
+	return events, nil
+}
+
+// forEachMaster calls fn with the client of each master,
+// as the keys, and their keyspace notifications, are spread over the masters of a cluster.
+func (s *Store) forEachMaster(fn func(client goredis.UniversalClient) error) error {
+	cluster, ok := s.client.(*goredis.ClusterClient)
+	if !ok {
+		return fn(s.client)
+	}
+
+	return cluster.ForEachMaster(func(master *goredis.Client) error {
+		return fn(master)
+	})
+}
+
+func scriptError(result int) error {
+	switch result {
+	case -1:
+		return store.ErrKeyNotFound
+	case 0:
+		return store.ErrKeyModified
+	default:
+		return nil
+	}
+}
+
+func ttl(options *store.WriteOptions) time.Duration {
+	if options == nil {
+		return 0
+	}
+	return options.TTL
+}
+
+// normalize returns the Redis key of the valkeyrie key, as the former valkeyrie Redis store.
+func normalize(key string) string {
+	return strings.TrimPrefix(store.Normalize(key), "/")
+}
+
+// prefix returns the prefix of the keys of the directory.
+func prefix(directory string) string {
+	if directory == "" {
+		return ""
+	}
+	return directory + "/"
+}
@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// maxConjunctions is the largest number of alternatives analyzed in a rule,
+// beyond which the rule is assumed to overlap with any other rule.
+const maxConjunctions = 64
+
+// conjunction is a list of matchers that must all match,
+// the Host, Path, PathPrefix and Method matchers having a single value.
+type conjunction []*tree
+
+// Overlap reports whether a request could match both rules.
+// Only the Host, Path, PathPrefix and Method matchers are analyzed,
+// the other matchers, as the path templates, being assumed to match any request.
+func Overlap(ruleA, ruleB string) (bool, error) {
+	conjunctionsA, err := parseConjunctions(ruleA)
+	if err != nil {
+		return false, err
+	}
+
+	conjunctionsB, err := parseConjunctions(ruleB)
+	if err != nil {
+		return false, err
+	}
+
+	if conjunctionsA == nil || conjunctionsB == nil {
+		return true, nil
+	}
+
+	for _, a := range conjunctionsA {
+		for _, b := range conjunctionsB {
+			if overlap(append(append(conjunction{}, a...), b...)) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// parseConjunctions returns the alternatives of the rule, or nil when the rule has too many of them.
+func parseConjunctions(rule string) ([]conjunction, error) {
+	parser, err := newParser()
+	if err != nil {
+		return nil, err
+	}
+
+	parse, err := parser.Parse(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	buildTree, ok := parse.(treeBuilder)
+	if !ok {
+		return nil, errors.New("cannot parse")
+	}
+
+	return disjunctiveNormalForm(buildTree()), nil
+}
+
+// disjunctiveNormalForm returns the rule as alternatives of conjunctions,
+// the matchers with several values being alternatives of matchers with a single value.
+func disjunctiveNormalForm(rule *tree) []conjunction {
+	var conjunctions []conjunction
+
+	switch rule.matcher {
+	case "or":
+		left := disjunctiveNormalForm(rule.ruleLeft)
+		right := disjunctiveNormalForm(rule.ruleRight)
+		if left == nil || right == nil {
+			return nil
+		}
+
+		conjunctions = append(left, right...)
+	case "and":
+		left := disjunctiveNormalForm(rule.ruleLeft)
+		right := disjunctiveNormalForm(rule.ruleRight)
+		if left == nil || right == nil {
+			return nil
+		}
+
+		for _, l := range left {
+			for _, r := range right {
+				conjunctions = append(conjunctions, append(append(conjunction{}, l...), r...))
+			}
+		}
+	case "Host", "HostHeader", "Path", "PathPrefix", "Method":
+		for _, value := range rule.value {
+			conjunctions = append(conjunctions, conjunction{{matcher: rule.matcher, value: []string{value}}})
+		}
+	default:
+		conjunctions = []conjunction{{rule}}
+	}
+
+	if len(conjunctions) > maxConjunctions {
+		return nil
+	}
+
+	return conjunctions
+}
+
+// overlap reports whether a request could match all the matchers.
+func overlap(matchers conjunction) bool {
+	var host, path, method string
+	var prefixes []string
+
+	for _, matcher := range matchers {
+		if len(matcher.value) == 0 {
+			continue
+		}
+		value := matcher.value[0]
+
+		switch matcher.matcher {
+		case "Host", "HostHeader":
+			value = strings.TrimSuffix(types.CanonicalDomain(value), ".")
+			if host != "" && host != value {
+				return false
+			}
+			host = value
+		case "Method":
+			value = strings.ToUpper(value)
+			if method != "" && method != value {
+				return false
+			}
+			method = value
+		case "Path":
+			if strings.Contains(value, "{") {
+				continue
+			}
+			if path != "" && path != value {
+				return false
+			}
+			path = value
+		case "PathPrefix":
+			if strings.Contains(value, "{") {
+				continue
+			}
+			prefixes = append(prefixes, value)
+		}
+	}
+
+	for i, prefix := range prefixes {
+		if path != "" && !strings.HasPrefix(path, prefix) {
+			return false
+		}
+
+		// All the prefixes of a path are prefixes of each other.
+		for _, other := range prefixes[i+1:] {
+			if !strings.HasPrefix(prefix, other) && !strings.HasPrefix(other, prefix) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
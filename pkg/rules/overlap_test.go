@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlap(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		ruleA         string
+		ruleB         string
+		expected      bool
+		expectedError bool
+	}{
+		{
+			desc:     "same host",
+			ruleA:    "Host(`foo.bar`)",
+			ruleB:    "Host(`FOO.bar`) && PathPrefix(`/api`)",
+			expected: true,
+		},
+		{
+			desc:  "different hosts",
+			ruleA: "Host(`foo.bar`)",
+			ruleB: "Host(`bar.foo`) && PathPrefix(`/api`)",
+		},
+		{
+			desc:     "one of the hosts",
+			ruleA:    "Host(`foo.bar`, `bar.foo`)",
+			ruleB:    "Host(`bar.foo`)",
+			expected: true,
+		},
+		{
+			desc:     "nested path prefixes",
+			ruleA:    "PathPrefix(`/api`)",
+			ruleB:    "PathPrefix(`/api/v1`)",
+			expected: true,
+		},
+		{
+			desc:  "disjoint path prefixes",
+			ruleA: "PathPrefix(`/api`)",
+			ruleB: "PathPrefix(`/web`)",
+		},
+		{
+			desc:     "path under the prefix",
+			ruleA:    "Path(`/api/users`)",
+			ruleB:    "PathPrefix(`/api`)",
+			expected: true,
+		},
+		{
+			desc:  "path outside the prefix",
+			ruleA: "Path(`/web`)",
+			ruleB: "PathPrefix(`/api`)",
+		},
+		{
+			desc:     "path template",
+			ruleA:    "Path(`/users/{id}`)",
+			ruleB:    "Path(`/web`)",
+			expected: true,
+		},
+		{
+			desc:  "different methods",
+			ruleA: "Host(`foo.bar`) && Method(`GET`)",
+			ruleB: "Host(`foo.bar`) && Method(`POST`)",
+		},
+		{
+			desc:     "alternatives",
+			ruleA:    "Host(`foo.bar`) || Host(`bar.foo`)",
+			ruleB:    "Host(`bar.foo`) && Headers(`X-Foo`, `bar`)",
+			expected: true,
+		},
+		{
+			desc:          "invalid rule",
+			ruleA:         "Host(`foo.bar`",
+			ruleB:         "Host(`foo.bar`)",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			overlap, err := Overlap(test.ruleA, test.ruleB)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, overlap)
+		})
+	}
+}
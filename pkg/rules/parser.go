@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/vulcand/predicate"
 )
 
@@ -53,7 +54,7 @@ func ParseHostSNI(rule string) ([]string, error) {
 func lower(slice []string) []string {
 	var lowerStrings []string
 	for _, value := range slice {
-		lowerStrings = append(lowerStrings, strings.ToLower(value))
+		lowerStrings = append(lowerStrings, types.CanonicalDomain(value))
 	}
 	return lowerStrings
 }
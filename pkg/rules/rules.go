@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/gorilla/mux"
 	"github.com/vulcand/predicate"
 )
@@ -21,12 +23,16 @@ var funcs = map[string]func(*mux.Route, ...string) error{
 	"Headers":       headers,
 	"HeadersRegexp": headersRegexp,
 	"Query":         query,
+	"ClientIP":      clientIP,
 }
 
 // Router handle routing with rules.
 type Router struct {
 	*mux.Router
 	parser predicate.Parser
+
+	// trailingSlash holds the routes matching the requests whose path has its trailing slash toggled.
+	trailingSlash *mux.Router
 }
 
 // NewRouter returns a new router instance.
@@ -62,6 +68,104 @@ func (r *Router) AddRoute(rule string, priority int, handler http.Handler) error
 	return addRuleOnRoute(route, buildTree())
 }
 
+// AddTrailingSlashRoute add a new route to the router, matching the requests whose path,
+// with its trailing slash added or removed, matches the rule.
+// These routes are only tried when no other route matches the request,
+// and their handler gets the request with the path matching the rule.
+func (r *Router) AddTrailingSlashRoute(rule string, priority int, handler http.Handler) error {
+	parse, err := r.parser.Parse(rule)
+	if err != nil {
+		return fmt.Errorf("error while parsing rule %s: %w", rule, err)
+	}
+
+	buildTree, ok := parse.(treeBuilder)
+	if !ok {
+		return fmt.Errorf("error while parsing rule %s", rule)
+	}
+
+	if priority == 0 {
+		priority = len(rule)
+	}
+
+	if r.trailingSlash == nil {
+		r.trailingSlash = mux.NewRouter().SkipClean(true)
+
+		// Keeps the requests matching a route with another method from being tried again.
+		r.Router.MethodNotAllowedHandler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		})
+		r.Router.NotFoundHandler = http.HandlerFunc(r.serveTrailingSlash)
+	}
+
+	route := r.trailingSlash.NewRoute().Handler(handler).Priority(priority)
+	return addRuleOnRoute(route, buildTree())
+}
+
+// SortRoutes sorts the routes by priority.
+func (r *Router) SortRoutes() {
+	r.Router.SortRoutes()
+
+	if r.trailingSlash != nil {
+		r.trailingSlash.SortRoutes()
+	}
+}
+
+func (r *Router) serveTrailingSlash(rw http.ResponseWriter, req *http.Request) {
+	toggled := toggleTrailingSlash(req)
+	if toggled == nil {
+		http.NotFound(rw, req)
+		return
+	}
+
+	var match mux.RouteMatch
+	if !r.trailingSlash.Match(toggled, &match) {
+		http.NotFound(rw, req)
+		return
+	}
+
+	match.Handler.ServeHTTP(rw, toggled)
+}
+
+// toggleTrailingSlash returns a copy of the request whose path has its trailing slash added or removed,
+// or nil for the root path.
+func toggleTrailingSlash(req *http.Request) *http.Request {
+	if req.URL.Path == "" || req.URL.Path == "/" {
+		return nil
+	}
+
+	toggled := req.Clone(req.Context())
+	toggled.URL.Path = toggle(req.URL.Path)
+	if req.URL.RawPath != "" {
+		toggled.URL.RawPath = toggle(req.URL.RawPath)
+	}
+	toggled.RequestURI = toggled.URL.RequestURI()
+
+	return toggled
+}
+
+func toggle(path string) string {
+	if strings.HasSuffix(path, "/") {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path + "/"
+}
+
+// Match reports whether the request matches the rule.
+// The request is expected to be decorated by the requestdecorator middleware, as for the Host matchers.
+func Match(rule string, req *http.Request) (bool, error) {
+	router, err := NewRouter()
+	if err != nil {
+		return false, err
+	}
+
+	err = router.AddRoute(rule, 0, http.NotFoundHandler())
+	if err != nil {
+		return false, err
+	}
+
+	return router.Match(req, &mux.RouteMatch{}), nil
+}
+
 type tree struct {
 	matcher   string
 	value     []string
@@ -95,7 +199,7 @@ func pathPrefix(route *mux.Route, paths ...string) error {
 
 func host(route *mux.Route, hosts ...string) error {
 	for i, host := range hosts {
-		hosts[i] = strings.ToLower(host)
+		hosts[i] = types.CanonicalDomain(host)
 	}
 
 	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
@@ -176,6 +280,25 @@ func query(route *mux.Route, query ...string) error {
 	return route.GetError()
 }
 
+func clientIP(route *mux.Route, clientIPs ...string) error {
+	checker, err := ip.NewChecker(clientIPs)
+	if err != nil {
+		return fmt.Errorf("invalid ClientIP matcher: %w", err)
+	}
+
+	strategy := ip.RemoteAddrStrategy{}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		ok, err := checker.Contains(strategy.GetIP(req))
+		if err != nil {
+			log.FromContext(req.Context()).Debugf("ClientIP matcher: unable to match the remote address %s: %v", req.RemoteAddr, err)
+			return false
+		}
+		return ok
+	})
+	return nil
+}
+
 func addRuleOnRouter(router *mux.Router, rule *tree) error {
 	switch rule.matcher {
 	case "and":
@@ -79,6 +79,22 @@ func Test_addRoute(t *testing.T) {
 				"http://localhost./foo": http.StatusOK,
 			},
 		},
+		{
+			desc: "Host with an internationalized domain name",
+			rule: "Host(`Bücher.example`)",
+			expected: map[string]int{
+				"http://xn--bcher-kva.example/foo": http.StatusOK,
+				"http://bücher.example/foo":        http.StatusOK,
+				"http://bucher.example/foo":        http.StatusNotFound,
+			},
+		},
+		{
+			desc: "Host with a punycode domain name",
+			rule: "Host(`xn--bcher-kva.example`)",
+			expected: map[string]int{
+				"http://bücher.example/foo": http.StatusOK,
+			},
+		},
 		{
 			desc: "wrong Host",
 			rule: "Host(`nope`)",
@@ -596,6 +612,100 @@ func Test_addRoutePriority(t *testing.T) {
 	}
 }
 
+func Test_addTrailingSlashRoute(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		method       string
+		url          string
+		expectedFrom string
+		expectedPath string
+		expectedCode int
+	}{
+		{
+			desc:         "exact match",
+			url:          "http://localhost/foo",
+			expectedFrom: "foo",
+			expectedPath: "/foo",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "trailing slash added",
+			url:          "http://localhost/foo/?bar=baz",
+			expectedFrom: "foo",
+			expectedPath: "/foo",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "trailing slash removed",
+			url:          "http://localhost/bar",
+			expectedFrom: "bar",
+			expectedPath: "/bar/",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "exact match of another route preferred",
+			url:          "http://localhost/baz/",
+			expectedFrom: "baz",
+			expectedPath: "/baz/",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "route without trailing slash policy",
+			url:          "http://localhost/qux/",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "root path",
+			url:          "http://localhost/",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "method not allowed",
+			method:       http.MethodPost,
+			url:          "http://localhost/baz/",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			newHandler := func(from string) http.Handler {
+				return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.Header().Set("X-From", from)
+					rw.Header().Set("X-Path", req.URL.Path)
+				})
+			}
+
+			require.NoError(t, router.AddRoute("Path(`/foo`)", 0, newHandler("foo")))
+			require.NoError(t, router.AddTrailingSlashRoute("Path(`/foo`)", 0, newHandler("foo")))
+			require.NoError(t, router.AddRoute("Path(`/bar/`)", 0, newHandler("bar")))
+			require.NoError(t, router.AddTrailingSlashRoute("Path(`/bar/`)", 0, newHandler("bar")))
+			require.NoError(t, router.AddRoute("Path(`/baz/`) && Method(`GET`)", 0, newHandler("baz")))
+			require.NoError(t, router.AddTrailingSlashRoute("Path(`/baz`)", 0, newHandler("baz-toggled")))
+			require.NoError(t, router.AddRoute("Path(`/qux`)", 0, newHandler("qux")))
+			router.SortRoutes()
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			rw := httptest.NewRecorder()
+			router.ServeHTTP(rw, testhelpers.MustNewRequest(method, test.url, nil))
+
+			assert.Equal(t, test.expectedCode, rw.Code)
+			assert.Equal(t, test.expectedFrom, rw.Header().Get("X-From"))
+			assert.Equal(t, test.expectedPath, rw.Header().Get("X-Path"))
+		})
+	}
+}
+
 func TestHostRegexp(t *testing.T) {
 	testCases := []struct {
 		desc    string
@@ -676,6 +786,128 @@ func TestHostRegexp(t *testing.T) {
 	}
 }
 
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		clientIPs     []string
+		remoteAddrs   map[string]bool
+		expectedError bool
+	}{
+		{
+			desc:      "single IP",
+			clientIPs: []string{"10.0.0.1"},
+			remoteAddrs: map[string]bool{
+				"10.0.0.1:1234": true,
+				"10.0.0.2:1234": false,
+			},
+		},
+		{
+			desc:      "IPv4 and IPv6 ranges",
+			clientIPs: []string{"10.0.0.0/8", "2001:db8::/32"},
+			remoteAddrs: map[string]bool{
+				"10.1.2.3:1234":      true,
+				"[2001:db8::1]:1234": true,
+				"192.168.1.1:1234":   false,
+				"[2001:db9::1]:1234": false,
+			},
+		},
+		{
+			desc:      "invalid remote address",
+			clientIPs: []string{"10.0.0.0/8"},
+			remoteAddrs: map[string]bool{
+				"invalid": false,
+			},
+		},
+		{
+			desc:          "invalid range",
+			clientIPs:     []string{"10.0.0.0/99"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rt := &mux.Route{}
+			err := clientIP(rt, test.clientIPs...)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for remoteAddr, match := range test.remoteAddrs {
+				req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+				req.RemoteAddr = remoteAddr
+				assert.Equal(t, match, rt.Match(req, &mux.RouteMatch{}), remoteAddr)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		method        string
+		url           string
+		expected      bool
+		expectedError bool
+	}{
+		{
+			desc:     "matching host and path",
+			rule:     "Host(`foo.bar`) && PathPrefix(`/api`)",
+			method:   http.MethodGet,
+			url:      "http://foo.bar/api/users",
+			expected: true,
+		},
+		{
+			desc:   "mismatching path",
+			rule:   "Host(`foo.bar`) && PathPrefix(`/api`)",
+			method: http.MethodGet,
+			url:    "http://foo.bar/web",
+		},
+		{
+			desc:   "mismatching method",
+			rule:   "Method(`POST`)",
+			method: http.MethodGet,
+			url:    "http://foo.bar/",
+		},
+		{
+			desc:          "invalid rule",
+			rule:          "Host(`foo.bar`",
+			method:        http.MethodGet,
+			url:           "http://foo.bar/",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := testhelpers.MustNewRequest(test.method, test.url, nil)
+
+			var match bool
+			var err error
+			requestdecorator.New(nil).ServeHTTP(nil, req, func(_ http.ResponseWriter, req *http.Request) {
+				match, err = Match(test.rule, req)
+			})
+
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, match)
+		})
+	}
+}
+
 func TestParseDomains(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -718,6 +950,12 @@ func TestParseDomains(t *testing.T) {
 			domain:        []string{"foo.bar"},
 			errorExpected: false,
 		},
+		{
+			description:   "Host rule with an internationalized domain name",
+			expression:    "Host(`Bücher.example`)",
+			domain:        []string{"xn--bcher-kva.example"},
+			errorExpected: false,
+		},
 		{
 			description:   "Host rule with no domain",
 			expression:    "Host() && Path(`/test`)",
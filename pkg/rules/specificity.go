@@ -0,0 +1,72 @@
+package rules
+
+// Weights of the matchers in the specificity of a rule.
+// A host is more specific than a path, itself more specific than a path prefix,
+// the longer paths being the more specific ones.
+const (
+	hostSpecificity       = 10000
+	hostRegexpSpecificity = 5000
+	pathSpecificity       = 1000
+	pathPrefixSpecificity = 500
+	otherSpecificity      = 100
+
+	// maxPathSpecificity bounds the length of the paths in the specificity, to keep the path prefixes less specific than the paths.
+	maxPathSpecificity = 499
+)
+
+// Specificity returns a score telling how specific the requests matched by a rule are,
+// which is used as the priority of the routers when the auto priority is enabled.
+// The score of a rule with alternatives is the one of its least specific alternative.
+func Specificity(rule string) (int, error) {
+	conjunctions, err := parseConjunctions(rule)
+	if err != nil {
+		return 0, err
+	}
+
+	// The rule has too many alternatives to be analyzed.
+	if conjunctions == nil {
+		return len(rule), nil
+	}
+
+	specificity := -1
+	for _, matchers := range conjunctions {
+		score := conjunctionSpecificity(matchers)
+		if specificity < 0 || score < specificity {
+			specificity = score
+		}
+	}
+
+	return specificity, nil
+}
+
+func conjunctionSpecificity(matchers conjunction) int {
+	var score int
+
+	for _, matcher := range matchers {
+		switch matcher.matcher {
+		case "Host", "HostHeader":
+			score += hostSpecificity
+		case "HostRegexp":
+			score += hostRegexpSpecificity
+		case "Path":
+			score += pathSpecificity + pathLength(matcher.value)
+		case "PathPrefix":
+			score += pathPrefixSpecificity + pathLength(matcher.value)
+		default:
+			score += otherSpecificity
+		}
+	}
+
+	return score
+}
+
+// pathLength returns the bounded length of the shortest path.
+func pathLength(paths []string) int {
+	length := maxPathSpecificity
+	for _, path := range paths {
+		if len(path) < length {
+			length = len(path)
+		}
+	}
+	return length
+}
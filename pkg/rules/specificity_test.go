@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecificity(t *testing.T) {
+	rules := []string{
+		"Host(`foo.bar`) && Path(`/api/users`)",
+		"Host(`foo.bar`) && PathPrefix(`/api/users`)",
+		"Host(`foo.bar`) && PathPrefix(`/api`)",
+		"Host(`foo.bar`)",
+		"HostRegexp(`{subdomain:[a-z]+}.foo.bar`)",
+		"Path(`/api/users`)",
+		"PathPrefix(`/`) && Method(`GET`)",
+		"PathPrefix(`/`)",
+	}
+
+	var previous int
+	for i, rule := range rules {
+		specificity, err := Specificity(rule)
+		require.NoError(t, err)
+
+		if i > 0 {
+			assert.Less(t, specificity, previous, rule)
+		}
+		previous = specificity
+	}
+
+	// A rule is as specific as its least specific alternative.
+	specificity, err := Specificity("Host(`foo.bar`) || PathPrefix(`/`)")
+	require.NoError(t, err)
+	assert.Equal(t, previous, specificity)
+
+	_, err = Specificity("Host(`foo.bar`")
+	assert.Error(t, err)
+}
@@ -14,6 +14,7 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			Middlewares: make(map[string]*dynamic.Middleware),
 			Services:    make(map[string]*dynamic.Service),
 			Models:      make(map[string]*dynamic.Model),
+			IPSets:      make(map[string]*dynamic.IPSet),
 		},
 		TCP: &dynamic.TCPConfiguration{
 			Routers:  make(map[string]*dynamic.TCPRouter),
@@ -52,6 +53,9 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			for modelName, model := range configuration.HTTP.Models {
 				conf.HTTP.Models[provider.MakeQualifiedName(pvd, modelName)] = model
 			}
+			for ipSetName, ipSet := range configuration.HTTP.IPSets {
+				conf.HTTP.IPSets[provider.MakeQualifiedName(pvd, ipSetName)] = ipSet
+			}
 		}
 
 		if configuration.TCP != nil {
@@ -22,6 +22,7 @@ func Test_mergeConfiguration(t *testing.T) {
 				Middlewares: make(map[string]*dynamic.Middleware),
 				Services:    make(map[string]*dynamic.Service),
 				Models:      make(map[string]*dynamic.Model),
+				IPSets:      make(map[string]*dynamic.IPSet),
 			},
 		},
 		{
@@ -38,6 +39,9 @@ func Test_mergeConfiguration(t *testing.T) {
 						Services: map[string]*dynamic.Service{
 							"service-1": {},
 						},
+						IPSets: map[string]*dynamic.IPSet{
+							"ipset-1": {},
+						},
 					},
 				},
 			},
@@ -54,6 +58,9 @@ func Test_mergeConfiguration(t *testing.T) {
 					"service-1@provider-1": {},
 				},
 				Models: make(map[string]*dynamic.Model),
+				IPSets: map[string]*dynamic.IPSet{
+					"ipset-1@provider-1": {},
+				},
 			},
 		},
 		{
@@ -104,6 +111,7 @@ func Test_mergeConfiguration(t *testing.T) {
 					"service-1@provider-2": {},
 				},
 				Models: make(map[string]*dynamic.Model),
+				IPSets: make(map[string]*dynamic.IPSet),
 			},
 		},
 	}
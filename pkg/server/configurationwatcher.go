@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
@@ -29,10 +30,20 @@ type ConfigurationWatcher struct {
 	providerConfigUpdateMap    map[string]chan dynamic.Message
 
 	configurationListeners []func(dynamic.Configuration)
+	providerListeners      []func(providerName string, conf *dynamic.Configuration)
+
+	syncListenersMu sync.Mutex
+	syncListeners   []*syncListener
 
 	routinesPool *safe.Pool
 }
 
+// syncListener is called once all the providers it waits for have delivered their first configuration.
+type syncListener struct {
+	pending  map[string]struct{}
+	listener func()
+}
+
 // NewConfigurationWatcher creates a new ConfigurationWatcher.
 func NewConfigurationWatcher(
 	routinesPool *safe.Pool,
@@ -77,6 +88,51 @@ func (c *ConfigurationWatcher) AddListener(listener func(dynamic.Configuration))
 	c.configurationListeners = append(c.configurationListeners, listener)
 }
 
+// AddProviderListener adds a listener function called with the configuration of the provider,
+// once the merged configuration including it has been passed to the configuration listeners.
+func (c *ConfigurationWatcher) AddProviderListener(listener func(providerName string, conf *dynamic.Configuration)) {
+	c.providerListeners = append(c.providerListeners, listener)
+}
+
+// AddSyncListener adds a listener function called once all the given providers have delivered their first configuration,
+// and this configuration has been passed to the configuration listeners.
+func (c *ConfigurationWatcher) AddSyncListener(providerNames []string, listener func()) {
+	pending := make(map[string]struct{}, len(providerNames))
+	for _, name := range providerNames {
+		pending[name] = struct{}{}
+	}
+
+	if len(pending) == 0 {
+		listener()
+		return
+	}
+
+	c.syncListenersMu.Lock()
+	defer c.syncListenersMu.Unlock()
+
+	c.syncListeners = append(c.syncListeners, &syncListener{pending: pending, listener: listener})
+}
+
+// providerSynced notifies the sync listeners that the given provider has delivered a configuration.
+func (c *ConfigurationWatcher) providerSynced(providerName string) {
+	c.syncListenersMu.Lock()
+	defer c.syncListenersMu.Unlock()
+
+	var remaining []*syncListener
+	for _, l := range c.syncListeners {
+		delete(l.pending, providerName)
+
+		if len(l.pending) > 0 {
+			remaining = append(remaining, l)
+			continue
+		}
+
+		l.listener()
+	}
+
+	c.syncListeners = remaining
+}
+
 func (c *ConfigurationWatcher) startProvider() {
 	logger := log.WithoutContext()
 
@@ -149,6 +205,12 @@ func (c *ConfigurationWatcher) loadMessage(configMsg dynamic.Message) {
 	for _, listener := range c.configurationListeners {
 		listener(conf)
 	}
+
+	for _, listener := range c.providerListeners {
+		listener(configMsg.ProviderName, configMsg.Configuration)
+	}
+
+	c.providerSynced(configMsg.ProviderName)
 }
 
 func (c *ConfigurationWatcher) preLoadConfiguration(configMsg dynamic.Message) {
@@ -176,6 +238,7 @@ func (c *ConfigurationWatcher) preLoadConfiguration(configMsg dynamic.Message) {
 
 	if isEmptyConfiguration(configMsg.Configuration) {
 		logger.Infof("Skipping empty Configuration for provider %s", configMsg.ProviderName)
+		c.providerSynced(configMsg.ProviderName)
 		return
 	}
 
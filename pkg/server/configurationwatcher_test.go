@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -394,3 +395,60 @@ func TestPublishConfigUpdatedByConfigWatcherListener(t *testing.T) {
 
 	assert.Equal(t, 1, publishedConfigCount)
 }
+
+func TestSyncListener(t *testing.T) {
+	routinesPool := safe.NewPool(context.Background())
+	defer routinesPool.Stop()
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{
+			{
+				ProviderName: "mock",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithRouters(th.WithRouter("foo")),
+					),
+				},
+			},
+			{
+				ProviderName:  "empty",
+				Configuration: &dynamic.Configuration{},
+			},
+			{
+				ProviderName: "mock2",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithRouters(th.WithRouter("bar")),
+					),
+				},
+			},
+		},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, 30*time.Millisecond, []string{})
+
+	var routers int32
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		atomic.StoreInt32(&routers, int32(len(conf.HTTP.Routers)))
+	})
+
+	synced := make(chan int32, 2)
+	watcher.AddSyncListener([]string{"mock", "empty"}, func() {
+		synced <- atomic.LoadInt32(&routers)
+	})
+	watcher.AddSyncListener([]string{"mock", "empty", "mock2"}, func() {
+		synced <- atomic.LoadInt32(&routers)
+	})
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	for _, expected := range []int32{1, 2} {
+		select {
+		case got := <-synced:
+			assert.Equal(t, expected, got)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for the providers to be synced")
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/server/provider"
+)
+
+// guardrails rejects the routers and middlewares of the namespaces exceeding their limits.
+// The rejected elements are disabled, and their errors are reported by the API.
+type guardrails struct {
+	namespaces map[string]*static.NamespaceGuardrails
+}
+
+func newGuardrails(config *static.Guardrails) *guardrails {
+	if config == nil || len(config.Namespaces) == 0 {
+		return nil
+	}
+
+	return &guardrails{namespaces: config.Namespaces}
+}
+
+// namespaceOf returns the name of the namespace of the element, if any.
+// When several namespaces match, the most specific one is used.
+func (g *guardrails) namespaceOf(qualifiedName string) string {
+	parts := strings.SplitN(qualifiedName, "@", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	name, providerName := parts[0], parts[1]
+
+	var match string
+	for namespaceName, namespace := range g.namespaces {
+		if namespace.Provider != providerName {
+			continue
+		}
+
+		if namespace.Namespace != "" && !strings.HasPrefix(name, namespace.Namespace+"-") {
+			continue
+		}
+
+		if match == "" || len(namespace.Namespace) > len(g.namespaces[match].Namespace) ||
+			(len(namespace.Namespace) == len(g.namespaces[match].Namespace) && namespaceName < match) {
+			match = namespaceName
+		}
+	}
+
+	return match
+}
+
+func (g *guardrails) apply(conf *runtime.Configuration) {
+	rejectedMiddlewares := g.applyToMiddlewares(conf)
+	g.applyToRouters(conf, rejectedMiddlewares)
+}
+
+// applyToMiddlewares rejects the middlewares of a forbidden type, and the ones exceeding the limit of their namespace.
+// It returns the names of the rejected middlewares.
+func (g *guardrails) applyToMiddlewares(conf *runtime.Configuration) map[string]struct{} {
+	rejected := make(map[string]struct{})
+	names := make(map[string][]string)
+
+	for name, middleware := range conf.Middlewares {
+		namespaceName := g.namespaceOf(name)
+		if namespaceName == "" {
+			continue
+		}
+
+		middlewareType := getMiddlewareType(middleware.Middleware)
+		if isForbidden(middlewareType, g.namespaces[namespaceName].ForbiddenMiddlewares) {
+			g.reject(middleware, rejected, name, fmt.Errorf("middlewares of type %s are forbidden in the namespace %q", middlewareType, namespaceName))
+			continue
+		}
+
+		names[namespaceName] = append(names[namespaceName], name)
+	}
+
+	for namespaceName, namespaceMiddlewares := range names {
+		limit := g.namespaces[namespaceName].MaxMiddlewares
+		if limit <= 0 || len(namespaceMiddlewares) <= limit {
+			continue
+		}
+
+		// The middlewares exceeding the limit are the last ones by name, so the rejected ones do not change at each reload.
+		sort.Strings(namespaceMiddlewares)
+		for _, name := range namespaceMiddlewares[limit:] {
+			g.reject(conf.Middlewares[name], rejected, name, fmt.Errorf("the namespace %q exceeds its limit of %d middlewares", namespaceName, limit))
+		}
+	}
+
+	// The chains are rejected when one of their middlewares is.
+	for changed := true; changed; {
+		changed = false
+		for name, middleware := range conf.Middlewares {
+			if _, ok := rejected[name]; ok || middleware.Chain == nil {
+				continue
+			}
+
+			for _, chained := range middleware.Chain.Middlewares {
+				chained = qualify(chained, name)
+				if _, ok := rejected[chained]; ok {
+					g.reject(middleware, rejected, name, fmt.Errorf("the middleware %q is rejected by the guardrails", chained))
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return rejected
+}
+
+func (g *guardrails) reject(middleware *runtime.MiddlewareInfo, rejected map[string]struct{}, name string, err error) {
+	log.WithoutContext().WithField(log.MiddlewareName, name).Error(err)
+	middleware.AddError(err, true)
+	rejected[name] = struct{}{}
+}
+
+// namespaceRouter is a router of a namespace, be it an HTTP, TCP, or UDP one.
+type namespaceRouter struct {
+	name     string
+	addError func(err error, critical bool)
+}
+
+// applyToRouters rejects the routers using a rejected middleware, and the ones exceeding the limit of their namespace.
+func (g *guardrails) applyToRouters(conf *runtime.Configuration, rejectedMiddlewares map[string]struct{}) {
+	routers := make(map[string][]namespaceRouter)
+
+	for name, router := range conf.Routers {
+		var rejected bool
+		for _, middleware := range router.Middlewares {
+			middleware = qualify(middleware, name)
+			if _, ok := rejectedMiddlewares[middleware]; ok {
+				g.rejectRouter(namespaceRouter{name: name, addError: router.AddError}, fmt.Errorf("the middleware %q is rejected by the guardrails", middleware))
+				rejected = true
+				break
+			}
+		}
+
+		if namespaceName := g.namespaceOf(name); namespaceName != "" && !rejected {
+			routers[namespaceName] = append(routers[namespaceName], namespaceRouter{name: name, addError: router.AddError})
+		}
+	}
+
+	for name, router := range conf.TCPRouters {
+		if namespaceName := g.namespaceOf(name); namespaceName != "" {
+			routers[namespaceName] = append(routers[namespaceName], namespaceRouter{name: name, addError: router.AddError})
+		}
+	}
+
+	for name, router := range conf.UDPRouters {
+		if namespaceName := g.namespaceOf(name); namespaceName != "" {
+			routers[namespaceName] = append(routers[namespaceName], namespaceRouter{name: name, addError: router.AddError})
+		}
+	}
+
+	for namespaceName, namespaceRouters := range routers {
+		limit := g.namespaces[namespaceName].MaxRouters
+		if limit <= 0 || len(namespaceRouters) <= limit {
+			continue
+		}
+
+		// The routers exceeding the limit are the last ones by name, so the rejected ones do not change at each reload.
+		sort.SliceStable(namespaceRouters, func(i, j int) bool {
+			return namespaceRouters[i].name < namespaceRouters[j].name
+		})
+		for _, router := range namespaceRouters[limit:] {
+			g.rejectRouter(router, fmt.Errorf("the namespace %q exceeds its limit of %d routers", namespaceName, limit))
+		}
+	}
+}
+
+func (g *guardrails) rejectRouter(router namespaceRouter, err error) {
+	log.WithoutContext().WithField(log.RouterName, router.name).Error(err)
+	router.addError(err, true)
+}
+
+// qualify returns the qualified name of the middleware used by the element, which is in the same provider when it is not given.
+func qualify(middleware, elementName string) string {
+	if strings.Contains(middleware, "@") {
+		return middleware
+	}
+
+	parts := strings.SplitN(elementName, "@", 2)
+	if len(parts) != 2 {
+		return middleware
+	}
+
+	return provider.MakeQualifiedName(parts[1], middleware)
+}
+
+// getMiddlewareType returns the type of the middleware, which is the name of its configuration field.
+func getMiddlewareType(middleware *dynamic.Middleware) string {
+	if middleware == nil {
+		return ""
+	}
+
+	if len(middleware.Plugin) > 0 {
+		return "Plugin"
+	}
+
+	v := reflect.ValueOf(middleware).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			return v.Type().Field(i).Name
+		}
+	}
+
+	return ""
+}
+
+func isForbidden(middlewareType string, forbidden []string) bool {
+	for _, forbiddenType := range forbidden {
+		if strings.EqualFold(middlewareType, forbiddenType) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/server/middleware"
+	"github.com/containous/traefik/v2/pkg/server/router"
+	"github.com/containous/traefik/v2/pkg/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardrails(t *testing.T) {
+	g := newGuardrails(&static.Guardrails{
+		Namespaces: map[string]*static.NamespaceGuardrails{
+			"crd": {
+				Provider:       "kubernetescrd",
+				MaxMiddlewares: 1,
+			},
+			"team-a": {
+				Provider:             "kubernetescrd",
+				Namespace:            "team-a",
+				MaxRouters:           2,
+				ForbiddenMiddlewares: []string{"headers"},
+			},
+		},
+	})
+
+	conf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"team-a-foo@kubernetescrd": {Service: "foo"},
+				"team-a-bar@kubernetescrd": {Service: "bar", Middlewares: []string{"team-a-chain"}},
+				"team-a-baz@kubernetescrd": {Service: "baz"},
+				"team-a-qux@kubernetescrd": {Service: "qux"},
+				"team-b-foo@kubernetescrd": {Service: "foo"},
+				"foo@file":                 {Service: "foo", Middlewares: []string{"team-a-headers@kubernetescrd"}},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"team-a-headers@kubernetescrd": {Headers: &dynamic.Headers{}},
+				"team-a-chain@kubernetescrd":   {Chain: &dynamic.Chain{Middlewares: []string{"team-a-headers"}}},
+				"team-b-strip@kubernetescrd":   {StripPrefix: &dynamic.StripPrefix{}},
+				"team-b-retry@kubernetescrd":   {Retry: &dynamic.Retry{}},
+				"foo@file":                     {Headers: &dynamic.Headers{}},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"team-a-aaa@kubernetescrd": {Service: "aaa"},
+			},
+		},
+	})
+
+	g.apply(conf)
+
+	assert.Equal(t, runtime.StatusDisabled, conf.Middlewares["team-a-headers@kubernetescrd"].Status)
+	assert.Equal(t, []string{`middlewares of type Headers are forbidden in the namespace "team-a"`}, conf.Middlewares["team-a-headers@kubernetescrd"].Err)
+	assert.Equal(t, runtime.StatusDisabled, conf.Middlewares["team-a-chain@kubernetescrd"].Status)
+	assert.Equal(t, runtime.StatusEnabled, conf.Middlewares["foo@file"].Status)
+
+	// The more specific namespace wins, so only the team-b middlewares are counted in the crd namespace.
+	assert.Equal(t, runtime.StatusEnabled, conf.Middlewares["team-b-retry@kubernetescrd"].Status)
+	assert.Equal(t, runtime.StatusDisabled, conf.Middlewares["team-b-strip@kubernetescrd"].Status)
+	assert.Equal(t, []string{`the namespace "crd" exceeds its limit of 1 middlewares`}, conf.Middlewares["team-b-strip@kubernetescrd"].Err)
+
+	assert.Equal(t, runtime.StatusDisabled, conf.Routers["team-a-bar@kubernetescrd"].Status)
+	assert.Equal(t, []string{`the middleware "team-a-chain@kubernetescrd" is rejected by the guardrails`}, conf.Routers["team-a-bar@kubernetescrd"].Err)
+	assert.Equal(t, runtime.StatusDisabled, conf.Routers["foo@file"].Status)
+
+	// The TCP routers are counted with the HTTP ones, and the last ones by name are rejected.
+	assert.Equal(t, runtime.StatusEnabled, conf.TCPRouters["team-a-aaa@kubernetescrd"].Status)
+	assert.Equal(t, runtime.StatusEnabled, conf.Routers["team-a-baz@kubernetescrd"].Status)
+	assert.Equal(t, runtime.StatusDisabled, conf.Routers["team-a-foo@kubernetescrd"].Status)
+	assert.Equal(t, []string{`the namespace "team-a" exceeds its limit of 2 routers`}, conf.Routers["team-a-foo@kubernetescrd"].Err)
+	assert.Equal(t, runtime.StatusDisabled, conf.Routers["team-a-qux@kubernetescrd"].Status)
+	assert.Equal(t, runtime.StatusEnabled, conf.Routers["team-b-foo@kubernetescrd"].Status)
+}
+
+func TestGuardrailsRejectedRoutersAreNotBuilt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"bar@file": {EntryPoints: []string{"web"}, Service: "foo@file", Rule: "Host(`bar.localhost`)"},
+				"foo@file": {EntryPoints: []string{"web"}, Service: "foo@file", Rule: "Host(`foo.localhost`)"},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo@file": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: server.URL}}}},
+			},
+		},
+	})
+
+	newGuardrails(&static.Guardrails{
+		Namespaces: map[string]*static.NamespaceGuardrails{
+			"file": {Provider: "file", MaxRouters: 1},
+		},
+	}).apply(rtConf)
+	assert.Equal(t, runtime.StatusDisabled, rtConf.Routers["foo@file"].Status)
+
+	serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, metrics.NewVoidRegistry())
+	chainBuilder := middleware.NewChainBuilder(static.Configuration{}, nil, nil)
+	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, chainBuilder, metrics.NewVoidRegistry())
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web"}, false)
+	require.Contains(t, handlers, "web")
+
+	for host, expected := range map[string]int{"bar.localhost": http.StatusOK, "foo.localhost": http.StatusNotFound} {
+		recorder := httptest.NewRecorder()
+		handlers["web"].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil))
+		assert.Equal(t, expected, recorder.Code, host)
+	}
+
+	// The rejected router is still reported with the entry points it would use.
+	rtConf.PopulateUsedBy()
+	assert.Equal(t, []string{"web"}, rtConf.Routers["foo@file"].Using)
+}
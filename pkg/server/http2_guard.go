@@ -0,0 +1,503 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"golang.org/x/net/http2"
+	"golang.org/x/time/rate"
+)
+
+// Reasons for which the HTTP/2 connections are closed.
+const (
+	abuseRapidReset  = "rapid_reset"
+	abuseStreamChurn = "stream_churn"
+	abuseHeaderFlood = "header_flood"
+)
+
+// https://tools.ietf.org/html/rfc7540#section-4.1
+const (
+	frameHeaderLen = 9
+
+	frameHeaders      = 0x1
+	frameRSTStream    = 0x3
+	frameContinuation = 0x9
+)
+
+// goAwayPingTimeout bounds the wait for the response to the ping following the first GOAWAY frame on shutdown,
+// which approximates the round-trip time as golang.org/x/net/http2 does.
+const goAwayPingTimeout = time.Second
+
+// https://tools.ietf.org/html/rfc7540#section-6.8
+const maxStreamID = 1<<31 - 1
+
+// The control frames written by the guard between the frames of the HTTP/2 server.
+var (
+	pingFrame = controlFrame(func(framer *http2.Framer) error {
+		return framer.WritePing(false, [8]byte{'t', 'r', 'a', 'e', 'f', 'i', 'k'})
+	})
+	goAwayFrame = controlFrame(func(framer *http2.Framer) error {
+		return framer.WriteGoAway(maxStreamID, http2.ErrCodeNo, nil)
+	})
+)
+
+var errHTTP2Abuse = errors.New("HTTP/2 connection closed for abusing the protocol")
+
+// http2ClientPreface is the first bytes sent by the HTTP/2 clients.
+var http2ClientPreface = []byte(http2.ClientPreface)
+
+// http2Guard closes the HTTP/2 connections whose clients reset streams too fast,
+// open streams too fast, or flood the server with CONTINUATION frames.
+// It also checks the health of the idle connections with pings,
+// and warns their clients before the graceful shutdown of the HTTP/2 server.
+type http2Guard struct {
+	ctx                 context.Context
+	resetsPerSecond     int
+	newStreamsPerSecond int
+	maxContinuations    int
+	readIdleTimeout     time.Duration
+	pingTimeout         time.Duration
+	abuses              gokitmetrics.Counter
+
+	connsMu sync.Mutex
+	conns   map[*guardedConn]struct{}
+}
+
+// newHTTP2Guard creates a guard from the HTTP/2 configuration of the entry point.
+func newHTTP2Guard(ctx context.Context, config *static.HTTP2Config, abuses gokitmetrics.Counter) (*http2Guard, error) {
+	if config == nil {
+		config = &static.HTTP2Config{}
+		config.SetDefaults()
+	}
+
+	if config.MaxResetStreamsPerSecond < 0 || config.MaxNewStreamsPerSecond < 0 || config.MaxContinuationFrames < 0 {
+		return nil, errors.New("the HTTP/2 abuse limits are negative")
+	}
+
+	if config.ReadIdleTimeout < 0 {
+		return nil, errors.New("the HTTP/2 read idle timeout is negative")
+	}
+
+	if config.ReadIdleTimeout > 0 && config.PingTimeout <= 0 {
+		return nil, errors.New("the HTTP/2 ping timeout must be positive to check the health of the connections")
+	}
+
+	return &http2Guard{
+		ctx:                 ctx,
+		resetsPerSecond:     int(config.MaxResetStreamsPerSecond),
+		newStreamsPerSecond: int(config.MaxNewStreamsPerSecond),
+		maxContinuations:    int(config.MaxContinuationFrames),
+		readIdleTimeout:     time.Duration(config.ReadIdleTimeout),
+		pingTimeout:         time.Duration(config.PingTimeout),
+		abuses:              abuses,
+		conns:               make(map[*guardedConn]struct{}),
+	}, nil
+}
+
+// wrap returns the connection whose frames are inspected by the guard.
+// The TLS connections keep exposing their state to the HTTP/2 server.
+func (g *http2Guard) wrap(conn net.Conn) net.Conn {
+	guarded := &guardedConn{Conn: conn, guard: g, done: make(chan struct{})}
+
+	if g.resetsPerSecond > 0 {
+		guarded.resets = rate.NewLimiter(rate.Limit(g.resetsPerSecond), g.resetsPerSecond)
+	}
+
+	if g.newStreamsPerSecond > 0 {
+		guarded.newStreams = rate.NewLimiter(rate.Limit(g.newStreamsPerSecond), g.newStreamsPerSecond)
+	}
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		return &guardedTLSConn{guardedConn: guarded, tlsConn: tlsConn}
+	}
+
+	return guarded
+}
+
+// serveTLS is the TLSNextProto function serving the HTTP/2 connections negotiated with ALPN through the guard.
+func (g *http2Guard) serveTLS(h2Server *http2.Server) func(*http.Server, *tls.Conn, http.Handler) {
+	return func(hs *http.Server, conn *tls.Conn, handler http.Handler) {
+		opts := &http2.ServeConnOpts{BaseConfig: hs, Handler: handler}
+
+		// The handler given by the HTTP server carries the context of the connection.
+		if bc, ok := handler.(interface{ BaseContext() context.Context }); ok {
+			opts.Context = bc.BaseContext()
+		}
+
+		h2Server.ServeConn(g.wrap(conn), opts)
+	}
+}
+
+func (g *http2Guard) report(conn net.Conn, reason string) {
+	log.FromContext(g.ctx).Debugf("Closing the HTTP/2 connection from %s: %s", conn.RemoteAddr(), reason)
+
+	g.abuses.With("reason", reason).Add(1)
+}
+
+// goAway warns the clients of the HTTP/2 connections that the server shuts down, and returns once they had the time to react.
+// Each connection is sent a GOAWAY frame allowing all the streams, followed by a ping,
+// so that the streams opened by the client in the meantime are known before the final GOAWAY frame sent by the HTTP/2 server.
+// https://tools.ietf.org/html/rfc7540#section-6.8
+func (g *http2Guard) goAway() {
+	g.connsMu.Lock()
+	conns := make([]*guardedConn, 0, len(g.conns))
+	for conn := range g.conns {
+		conns = append(conns, conn)
+	}
+	g.connsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn *guardedConn) {
+			defer wg.Done()
+			conn.goAwayOnce.Do(func() {
+				if conn.writeControlFrame(goAwayFrame) {
+					conn.ping(goAwayPingTimeout)
+				}
+			})
+		}(conn)
+	}
+	wg.Wait()
+}
+
+// track starts tracking the HTTP/2 connection, and checking its health when enabled.
+func (g *http2Guard) track(conn *guardedConn) {
+	g.connsMu.Lock()
+	defer g.connsMu.Unlock()
+
+	select {
+	case <-conn.done:
+		return
+	default:
+	}
+
+	g.conns[conn] = struct{}{}
+
+	if g.readIdleTimeout > 0 {
+		go conn.checkHealth()
+	}
+}
+
+func (g *http2Guard) untrack(conn *guardedConn) {
+	g.connsMu.Lock()
+	delete(g.conns, conn)
+	g.connsMu.Unlock()
+}
+
+// guardedListener wraps the accepted connections with the guard, for the HTTP/2 connections with prior knowledge (h2c).
+type guardedListener struct {
+	net.Listener
+	guard *http2Guard
+}
+
+func (l guardedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return l.guard.wrap(conn), nil
+}
+
+// guardedConn parses the HTTP/2 frames read from the connection, and closes it on abuse.
+// It lets the data through untouched once the client preface is missing.
+// The frames written by the server are parsed too, to write the control frames of the guard between them.
+type guardedConn struct {
+	net.Conn
+	guard *http2Guard
+
+	passthrough   bool
+	prefaceRead   int
+	header        [frameHeaderLen]byte
+	headerRead    int
+	payloadLeft   uint32
+	lastStreamID  uint32
+	continuations int
+
+	resets     *rate.Limiter
+	newStreams *rate.Limiter
+
+	writeMu          sync.Mutex
+	writeHeader      [frameHeaderLen]byte
+	writeHeaderLen   int
+	writePayloadLeft uint32
+
+	// readMu guards the time of the last read, and the channel closed on the next read.
+	readMu   sync.Mutex
+	lastRead time.Time
+	nextRead chan struct{}
+
+	goAwayOnce sync.Once
+	closeOnce  sync.Once
+	done       chan struct{}
+}
+
+func (c *guardedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	c.readMu.Lock()
+	c.lastRead = time.Now()
+	if c.nextRead != nil {
+		close(c.nextRead)
+		c.nextRead = nil
+	}
+	c.readMu.Unlock()
+
+	if c.passthrough {
+		return n, err
+	}
+
+	if reason := c.inspect(p[:n]); reason != "" {
+		c.guard.report(c.Conn, reason)
+		_ = c.Close()
+
+		return 0, errHTTP2Abuse
+	}
+
+	return n, err
+}
+
+func (c *guardedConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	n, err := c.Conn.Write(p)
+	c.written(p[:n])
+
+	return n, err
+}
+
+func (c *guardedConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.guard.untrack(c)
+	})
+
+	return c.Conn.Close()
+}
+
+// written follows the frame headers of the data written by the server.
+func (c *guardedConn) written(data []byte) {
+	for len(data) > 0 {
+		if c.writePayloadLeft > 0 {
+			n := c.writePayloadLeft
+			if n > uint32(len(data)) {
+				n = uint32(len(data))
+			}
+
+			c.writePayloadLeft -= n
+			data = data[n:]
+			continue
+		}
+
+		n := copy(c.writeHeader[c.writeHeaderLen:], data)
+		c.writeHeaderLen += n
+		data = data[n:]
+
+		if c.writeHeaderLen < frameHeaderLen {
+			return
+		}
+
+		c.writeHeaderLen = 0
+		c.writePayloadLeft = uint32(c.writeHeader[0])<<16 | uint32(c.writeHeader[1])<<8 | uint32(c.writeHeader[2])
+	}
+}
+
+// writeControlFrame writes the frame once the server is done writing its current frame,
+// and reports whether it was written before the connection is closed.
+func (c *guardedConn) writeControlFrame(frame []byte) bool {
+	for {
+		c.writeMu.Lock()
+		if c.writeHeaderLen == 0 && c.writePayloadLeft == 0 {
+			_, err := c.Conn.Write(frame)
+			c.writeMu.Unlock()
+
+			return err == nil
+		}
+		c.writeMu.Unlock()
+
+		select {
+		case <-c.done:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// ping sends a ping, and reports whether any data is read before the timeout, as the response.
+func (c *guardedConn) ping(timeout time.Duration) bool {
+	c.readMu.Lock()
+	if c.nextRead == nil {
+		c.nextRead = make(chan struct{})
+	}
+	nextRead := c.nextRead
+	c.readMu.Unlock()
+
+	if !c.writeControlFrame(pingFrame) {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-nextRead:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.done:
+		return false
+	}
+}
+
+// checkHealth sends a ping when nothing was read during the read idle timeout,
+// and closes the connection when the ping gets no response.
+func (c *guardedConn) checkHealth() {
+	timer := time.NewTimer(c.guard.readIdleTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-timer.C:
+		}
+
+		c.readMu.Lock()
+		idle := time.Since(c.lastRead)
+		c.readMu.Unlock()
+
+		if idle < c.guard.readIdleTimeout {
+			timer.Reset(c.guard.readIdleTimeout - idle)
+			continue
+		}
+
+		if !c.ping(c.guard.pingTimeout) {
+			log.FromContext(c.guard.ctx).Debugf("Closing the HTTP/2 connection from %s: no response to the ping", c.Conn.RemoteAddr())
+			_ = c.Close()
+
+			return
+		}
+
+		timer.Reset(c.guard.readIdleTimeout)
+	}
+}
+
+// inspect parses the frame headers of the data, and returns the reason of the abuse if any.
+func (c *guardedConn) inspect(data []byte) string {
+	for len(data) > 0 {
+		if c.prefaceRead < len(http2ClientPreface) {
+			n := len(http2ClientPreface) - c.prefaceRead
+			if n > len(data) {
+				n = len(data)
+			}
+
+			if !bytes.Equal(data[:n], http2ClientPreface[c.prefaceRead:c.prefaceRead+n]) {
+				c.passthrough = true
+				return ""
+			}
+
+			c.prefaceRead += n
+			data = data[n:]
+
+			if c.prefaceRead == len(http2ClientPreface) {
+				c.guard.track(c)
+			}
+			continue
+		}
+
+		if c.payloadLeft > 0 {
+			n := c.payloadLeft
+			if n > uint32(len(data)) {
+				n = uint32(len(data))
+			}
+
+			c.payloadLeft -= n
+			data = data[n:]
+			continue
+		}
+
+		n := copy(c.header[c.headerRead:], data)
+		c.headerRead += n
+		data = data[n:]
+
+		if c.headerRead < frameHeaderLen {
+			return ""
+		}
+
+		c.headerRead = 0
+		c.payloadLeft = uint32(c.header[0])<<16 | uint32(c.header[1])<<8 | uint32(c.header[2])
+
+		if reason := c.frame(c.header[3], binary.BigEndian.Uint32(c.header[5:])&(1<<31-1)); reason != "" {
+			return reason
+		}
+	}
+
+	return ""
+}
+
+// frame accounts for a frame of the type on the stream.
+func (c *guardedConn) frame(frameType byte, streamID uint32) string {
+	switch frameType {
+	case frameHeaders:
+		c.continuations = 0
+
+		// The client stream IDs only grow, the HEADERS frames of a known stream are trailers.
+		if streamID > c.lastStreamID {
+			c.lastStreamID = streamID
+
+			if c.newStreams != nil && !c.newStreams.Allow() {
+				return abuseStreamChurn
+			}
+		}
+
+	case frameContinuation:
+		c.continuations++
+
+		if c.guard.maxContinuations > 0 && c.continuations > c.guard.maxContinuations {
+			return abuseHeaderFlood
+		}
+
+	case frameRSTStream:
+		if c.resets != nil && !c.resets.Allow() {
+			return abuseRapidReset
+		}
+	}
+
+	return ""
+}
+
+// guardedTLSConn is a guarded TLS connection.
+type guardedTLSConn struct {
+	*guardedConn
+	tlsConn *tls.Conn
+}
+
+// ConnectionState returns the state of the TLS connection.
+func (c *guardedTLSConn) ConnectionState() tls.ConnectionState {
+	return c.tlsConn.ConnectionState()
+}
+
+// controlFrame returns the bytes of the frame written by the function.
+func controlFrame(write func(framer *http2.Framer) error) []byte {
+	var buf bytes.Buffer
+	if err := write(http2.NewFramer(&buf, nil)); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes()
+}
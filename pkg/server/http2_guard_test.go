@@ -0,0 +1,330 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+type readerConn struct {
+	net.Conn
+	reader io.Reader
+	closed bool
+}
+
+func (c *readerConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (c *readerConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *readerConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4242}
+}
+
+func TestHTTP2Guard(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    static.HTTP2Config
+		preface   string
+		frames    func(framer *http2.Framer)
+		expClosed bool
+	}{
+		{
+			desc:   "requests under the limits",
+			config: static.HTTP2Config{MaxResetStreamsPerSecond: 10, MaxNewStreamsPerSecond: 10, MaxContinuationFrames: 2},
+			frames: func(framer *http2.Framer) {
+				for id := uint32(1); id < 20; id += 2 {
+					writeHeaders(framer, id, false)
+					_ = framer.WriteContinuation(id, false, []byte{})
+					_ = framer.WriteContinuation(id, true, []byte{})
+					_ = framer.WriteData(id, true, bytes.Repeat([]byte("a"), 100))
+				}
+			},
+		},
+		{
+			desc:   "rapid reset",
+			config: static.HTTP2Config{MaxResetStreamsPerSecond: 10},
+			frames: func(framer *http2.Framer) {
+				for id := uint32(1); id < 30; id += 2 {
+					writeHeaders(framer, id, true)
+					_ = framer.WriteRSTStream(id, http2.ErrCodeCancel)
+				}
+			},
+			expClosed: true,
+		},
+		{
+			desc:   "stream churn",
+			config: static.HTTP2Config{MaxNewStreamsPerSecond: 5},
+			frames: func(framer *http2.Framer) {
+				for id := uint32(1); id < 20; id += 2 {
+					writeHeaders(framer, id, true)
+				}
+			},
+			expClosed: true,
+		},
+		{
+			desc:   "trailers are not new streams",
+			config: static.HTTP2Config{MaxNewStreamsPerSecond: 1},
+			frames: func(framer *http2.Framer) {
+				writeHeaders(framer, 1, false)
+				_ = framer.WriteData(1, false, []byte("a"))
+				writeHeaders(framer, 1, true)
+			},
+		},
+		{
+			desc:   "header flood",
+			config: static.HTTP2Config{MaxContinuationFrames: 5},
+			frames: func(framer *http2.Framer) {
+				writeHeaders(framer, 1, false)
+				for i := 0; i < 10; i++ {
+					_ = framer.WriteContinuation(1, false, []byte{})
+				}
+			},
+			expClosed: true,
+		},
+		{
+			desc:    "not HTTP/2",
+			config:  static.HTTP2Config{MaxResetStreamsPerSecond: 1},
+			preface: "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n",
+			frames: func(framer *http2.Framer) {
+				for i := 0; i < 10; i++ {
+					_ = framer.WriteRSTStream(1, http2.ErrCodeCancel)
+				}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			guard, err := newHTTP2Guard(context.Background(), &test.config, metrics.NewVoidRegistry().EntryPointHTTP2AbusesCounter())
+			require.NoError(t, err)
+			require.NotNil(t, guard)
+
+			preface := test.preface
+			if preface == "" {
+				preface = http2.ClientPreface
+			}
+
+			data := bytes.NewBufferString(preface)
+			framer := http2.NewFramer(data, nil)
+			require.NoError(t, framer.WriteSettings())
+			test.frames(framer)
+
+			conn := &readerConn{reader: &smallReader{data: data.Bytes()}}
+
+			_, err = ioutil.ReadAll(guard.wrap(conn))
+			if test.expClosed {
+				assert.Equal(t, errHTTP2Abuse, err)
+				assert.True(t, conn.closed)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.False(t, conn.closed)
+		})
+	}
+}
+
+func TestNewHTTP2Guard(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config static.HTTP2Config
+		expErr bool
+	}{
+		{
+			desc: "protections disabled",
+		},
+		{
+			desc:   "negative abuse limit",
+			config: static.HTTP2Config{MaxResetStreamsPerSecond: -1},
+			expErr: true,
+		},
+		{
+			desc:   "negative read idle timeout",
+			config: static.HTTP2Config{ReadIdleTimeout: ptypes.Duration(-time.Second)},
+			expErr: true,
+		},
+		{
+			desc:   "health check without ping timeout",
+			config: static.HTTP2Config{ReadIdleTimeout: ptypes.Duration(time.Second)},
+			expErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			guard, err := newHTTP2Guard(context.Background(), &test.config, metrics.NewVoidRegistry().EntryPointHTTP2AbusesCounter())
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, guard)
+		})
+	}
+}
+
+func TestHTTP2Guard_checkHealth(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		answer    bool
+		expClosed bool
+	}{
+		{
+			desc:   "pings answered",
+			answer: true,
+		},
+		{
+			desc:      "ping unanswered",
+			expClosed: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			guard, err := newHTTP2Guard(context.Background(), &static.HTTP2Config{
+				ReadIdleTimeout: ptypes.Duration(50 * time.Millisecond),
+				PingTimeout:     ptypes.Duration(50 * time.Millisecond),
+			}, metrics.NewVoidRegistry().EntryPointHTTP2AbusesCounter())
+			require.NoError(t, err)
+
+			server, client := net.Pipe()
+			guarded := guard.wrap(server)
+			go func() { _, _ = io.Copy(ioutil.Discard, guarded) }()
+			defer func() { _ = guarded.Close() }()
+
+			_, err = client.Write([]byte(http2.ClientPreface))
+			require.NoError(t, err)
+
+			framer := http2.NewFramer(client, client)
+			for i := 0; i < 3; i++ {
+				frame, err := framer.ReadFrame()
+				require.NoError(t, err)
+
+				ping, ok := frame.(*http2.PingFrame)
+				require.True(t, ok)
+				assert.False(t, ping.IsAck())
+
+				if !test.answer {
+					break
+				}
+				require.NoError(t, framer.WritePing(true, ping.Data))
+			}
+
+			if test.expClosed {
+				_, err = framer.ReadFrame()
+				assert.Equal(t, io.EOF, err)
+			}
+		})
+	}
+}
+
+func TestHTTP2Guard_goAway(t *testing.T) {
+	guard, err := newHTTP2Guard(context.Background(), &static.HTTP2Config{}, metrics.NewVoidRegistry().EntryPointHTTP2AbusesCounter())
+	require.NoError(t, err)
+
+	server, client := net.Pipe()
+	guarded := guard.wrap(server)
+	go func() { _, _ = io.Copy(ioutil.Discard, guarded) }()
+	defer func() { _ = guarded.Close() }()
+
+	framer := http2.NewFramer(client, client)
+	_, err = client.Write([]byte(http2.ClientPreface))
+	require.NoError(t, err)
+
+	// The guard tracks the connection once the client preface is read.
+	require.Eventually(t, func() bool {
+		guard.connsMu.Lock()
+		defer guard.connsMu.Unlock()
+		return len(guard.conns) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		guard.goAway()
+		close(done)
+	}()
+
+	frame, err := framer.ReadFrame()
+	require.NoError(t, err)
+
+	goAway, ok := frame.(*http2.GoAwayFrame)
+	require.True(t, ok)
+	assert.Equal(t, uint32(1<<31-1), goAway.LastStreamID)
+	assert.Equal(t, http2.ErrCodeNo, goAway.ErrCode)
+
+	frame, err = framer.ReadFrame()
+	require.NoError(t, err)
+
+	ping, ok := frame.(*http2.PingFrame)
+	require.True(t, ok)
+
+	// The response to the ping ends the wait before the final GOAWAY frame.
+	require.NoError(t, framer.WritePing(true, ping.Data))
+
+	select {
+	case <-done:
+	case <-time.After(goAwayPingTimeout / 2):
+		t.Fatal("the guard did not return once the ping was answered")
+	}
+}
+
+func writeHeaders(framer *http2.Framer, streamID uint32, endHeaders bool) {
+	var block bytes.Buffer
+	encoder := hpack.NewEncoder(&block)
+	_ = encoder.WriteField(hpack.HeaderField{Name: ":method", Value: "GET"})
+	_ = encoder.WriteField(hpack.HeaderField{Name: ":path", Value: "/"})
+
+	_ = framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: block.Bytes(),
+		EndStream:     true,
+		EndHeaders:    endHeaders,
+	})
+}
+
+// smallReader reads the data in chunks splitting the frame headers.
+type smallReader struct {
+	data []byte
+}
+
+func (r *smallReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	if len(p) > 7 {
+		p = p[:7]
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
@@ -128,5 +128,12 @@ func setupTracing(conf *static.Tracing) *tracing.Tracing {
 		log.WithoutContext().Warnf("Unable to create tracer: %v", err)
 		return nil
 	}
+
+	if conf.ForceSampling != nil {
+		if err := tracer.SetForceSampling(conf.ForceSampling.Header, conf.ForceSampling.TrustedIPs); err != nil {
+			log.WithoutContext().Warnf("Unable to force the sampling of the traces: %v", err)
+		}
+	}
+
 	return tracer
 }
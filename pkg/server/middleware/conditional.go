@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/rules"
+	"github.com/gorilla/mux"
+)
+
+// conditional applies a middleware only to the requests matching a rule,
+// the other requests are directly given to the next handler.
+type conditional struct {
+	router     *rules.Router
+	middleware http.Handler
+	next       http.Handler
+}
+
+// wrapConditional wraps the given middleware constructor,
+// so that the middleware is only applied to the requests matching the rule.
+func wrapConditional(rule string, constructor alice.Constructor) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		middleware, err := constructor(next)
+		if err != nil {
+			return nil, err
+		}
+
+		router, err := rules.NewRouter()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := router.AddRoute(rule, 0, middleware); err != nil {
+			return nil, fmt.Errorf("invalid when rule: %w", err)
+		}
+
+		return &conditional{router: router, middleware: middleware, next: next}, nil
+	}
+}
+
+func (c *conditional) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var match mux.RouteMatch
+	if c.router.Match(req, &match) {
+		c.middleware.ServeHTTP(rw, req)
+		return
+	}
+
+	c.next.ServeHTTP(rw, req)
+}
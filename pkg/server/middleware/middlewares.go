@@ -5,10 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/cluster"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/adaptiveconcurrency"
 	"github.com/containous/traefik/v2/pkg/middlewares/addprefix"
 	"github.com/containous/traefik/v2/pkg/middlewares/auth"
 	"github.com/containous/traefik/v2/pkg/middlewares/buffering"
@@ -16,15 +22,19 @@ import (
 	"github.com/containous/traefik/v2/pkg/middlewares/circuitbreaker"
 	"github.com/containous/traefik/v2/pkg/middlewares/compress"
 	"github.com/containous/traefik/v2/pkg/middlewares/customerrors"
+	"github.com/containous/traefik/v2/pkg/middlewares/earlyhints"
 	"github.com/containous/traefik/v2/pkg/middlewares/headers"
 	"github.com/containous/traefik/v2/pkg/middlewares/inflightreq"
 	"github.com/containous/traefik/v2/pkg/middlewares/ipwhitelist"
+	metricsmiddleware "github.com/containous/traefik/v2/pkg/middlewares/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/passtlsclientcert"
+	"github.com/containous/traefik/v2/pkg/middlewares/qos"
 	"github.com/containous/traefik/v2/pkg/middlewares/ratelimiter"
 	"github.com/containous/traefik/v2/pkg/middlewares/redirect"
 	"github.com/containous/traefik/v2/pkg/middlewares/replacepath"
 	"github.com/containous/traefik/v2/pkg/middlewares/replacepathregex"
 	"github.com/containous/traefik/v2/pkg/middlewares/retry"
+	"github.com/containous/traefik/v2/pkg/middlewares/s3"
 	"github.com/containous/traefik/v2/pkg/middlewares/stripprefix"
 	"github.com/containous/traefik/v2/pkg/middlewares/stripprefixregex"
 	"github.com/containous/traefik/v2/pkg/middlewares/tracing"
@@ -35,13 +45,21 @@ type middlewareStackType int
 
 const (
 	middlewareStackKey middlewareStackType = iota
+	middlewareVariablesKey
+	middlewareServiceKey
+	middlewareRouterKey
 )
 
 // Builder the middleware builder.
 type Builder struct {
-	configs        map[string]*runtime.MiddlewareInfo
-	pluginBuilder  PluginsBuilder
-	serviceBuilder serviceBuilder
+	configs         map[string]*runtime.MiddlewareInfo
+	pluginBuilder   PluginsBuilder
+	serviceBuilder  serviceBuilder
+	metricsRegistry metrics.Registry
+	cluster         *cluster.Node
+	ipSets          ip.Sets
+	retryBudgets    *retry.Budgets
+	limiters        *adaptiveconcurrency.Limiters
 }
 
 type serviceBuilder interface {
@@ -49,8 +67,48 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{
+		configs:         configs,
+		serviceBuilder:  serviceBuilder,
+		pluginBuilder:   pluginBuilder,
+		metricsRegistry: metricsRegistry,
+		retryBudgets:    retry.NewBudgets(),
+		limiters:        adaptiveconcurrency.NewLimiters(),
+	}
+}
+
+// WithServiceName returns a context holding the name of the service targeted by the middlewares built with it.
+// The routers using a retry middleware to target the same service share its budget,
+// and the ones using an adaptive concurrency middleware its limit.
+func WithServiceName(ctx context.Context, serviceName string) context.Context {
+	return context.WithValue(ctx, middlewareServiceKey, serviceName)
+}
+
+// WithRouterName returns a context holding the name of the router using the middlewares built with it,
+// which labels their metrics.
+func WithRouterName(ctx context.Context, routerName string) context.Context {
+	return context.WithValue(ctx, middlewareRouterKey, routerName)
+}
+
+// SetRetryBudgets sets the budgets of the retry middlewares, kept across the configuration reloads.
+func (b *Builder) SetRetryBudgets(budgets *retry.Budgets) {
+	b.retryBudgets = budgets
+}
+
+// SetLimiters sets the limiters of the adaptive concurrency middlewares, kept across the configuration reloads.
+func (b *Builder) SetLimiters(limiters *adaptiveconcurrency.Limiters) {
+	b.limiters = limiters
+}
+
+// SetCluster sets the cluster the middlewares share their state with.
+func (b *Builder) SetCluster(node *cluster.Node) {
+	b.cluster = node
+}
+
+// SetIPSets sets the named IP sets the middlewares reference.
+func (b *Builder) SetIPSets(ipSets ip.Sets) {
+	b.ipSets = ipSets
 }
 
 // BuildChain creates a middleware chain.
@@ -107,11 +165,36 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		return nil, fmt.Errorf("invalid middleware %q configuration", middlewareName)
 	}
 
+	if variables := getVariables(ctx); len(variables) > 0 {
+		// The middleware can also be used outside of the chain defining the variables,
+		// so they are expanded on a copy of its configuration.
+		config = &runtime.MiddlewareInfo{Middleware: expandVariables(config.Middleware, variables)}
+	}
+
 	var middleware alice.Constructor
 	badConf := errors.New("cannot create middleware: multi-types middleware not supported, consider declaring two different pieces of middleware instead")
 
+	// AdaptiveConcurrency
+	if config.AdaptiveConcurrency != nil {
+		middleware = func(next http.Handler) (http.Handler, error) {
+			var limiter *adaptiveconcurrency.Limiter
+			if serviceName, ok := ctx.Value(middlewareServiceKey).(string); ok {
+				var err error
+				limiter, err = b.limiters.Get(middlewareName, serviceName, *config.AdaptiveConcurrency)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return adaptiveconcurrency.New(ctx, next, *config.AdaptiveConcurrency, limiter, middlewareName)
+		}
+	}
+
 	// AddPrefix
 	if config.AddPrefix != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
 		middleware = func(next http.Handler) (http.Handler, error) {
 			return addprefix.New(ctx, next, *config.AddPrefix, middlewareName)
 		}
@@ -149,7 +232,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 		config.Chain.Middlewares = qualifiedNames
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return chain.New(ctx, next, *config.Chain, b, middlewareName)
+			return chain.New(addVariablesInContext(ctx, config.Chain.Variables), next, *config.Chain, b, middlewareName)
 		}
 	}
 
@@ -159,7 +242,10 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, middlewareName)
+			serviceName, _ := ctx.Value(middlewareServiceKey).(string)
+			return circuitbreaker.New(ctx, next, *config.CircuitBreaker,
+				b.metricsRegistry.MiddlewareCircuitBreakerStateGauge(), b.metricsRegistry.MiddlewareCircuitBreakerTripsCounter(),
+				middlewareName, serviceName)
 		}
 	}
 
@@ -208,6 +294,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// EarlyHints
+	if config.EarlyHints != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return earlyhints.New(ctx, next, *config.EarlyHints, middlewareName)
+		}
+	}
+
 	// ForwardAuth
 	if config.ForwardAuth != nil {
 		if middleware != nil {
@@ -234,7 +330,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return ipwhitelist.New(ctx, next, *config.IPWhiteList, middlewareName)
+			return ipwhitelist.New(ctx, next, *config.IPWhiteList, b.ipSets, middlewareName)
 		}
 	}
 
@@ -244,7 +340,27 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return inflightreq.New(ctx, next, *config.InFlightReq, middlewareName)
+			return inflightreq.New(ctx, next, *config.InFlightReq, b.ipSets, middlewareName)
+		}
+	}
+
+	// LDAPAuth
+	if config.LDAPAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewLDAP(ctx, next, *config.LDAPAuth, middlewareName)
+		}
+	}
+
+	// OPA
+	if config.OPA != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewOPA(ctx, next, *config.OPA, b.metricsRegistry.MiddlewareOPADecisionsCounter(), middlewareName)
 		}
 	}
 
@@ -258,13 +374,28 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// QoS
+	if config.QoS != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return qos.New(ctx, next, *config.QoS, middlewareName)
+		}
+	}
+
 	// RateLimit
 	if config.RateLimit != nil {
 		if middleware != nil {
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+			requestsCounter := b.metricsRegistry.MiddlewareRateLimitRequestsCounter()
+			tokensGauge := b.metricsRegistry.MiddlewareRateLimitBucketTokensGauge()
+			if b.cluster != nil {
+				return ratelimiter.NewWithCluster(ctx, next, *config.RateLimit, b.ipSets, requestsCounter, tokensGauge, middlewareName, b.cluster)
+			}
+			return ratelimiter.New(ctx, next, *config.RateLimit, b.ipSets, requestsCounter, tokensGauge, middlewareName)
 		}
 	}
 
@@ -314,8 +445,49 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			// FIXME missing metrics / accessLog
-			return retry.New(ctx, next, *config.Retry, retry.Listeners{}, middlewareName)
+			var budget *retry.Budget
+			listeners := retry.Listeners{}
+			if serviceName, ok := ctx.Value(middlewareServiceKey).(string); ok {
+				if config.Retry.Budget != nil {
+					budget = b.retryBudgets.Get(middlewareName, serviceName, *config.Retry.Budget)
+				}
+				if b.metricsRegistry.IsSvcEnabled() {
+					listeners = append(listeners, metricsmiddleware.NewRetryListener(b.metricsRegistry, serviceName))
+				}
+			}
+
+			// FIXME missing accessLog
+			return retry.New(ctx, next, *config.Retry, budget, listeners, middlewareName)
+		}
+	}
+
+	// S3
+	if config.S3 != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return s3.New(ctx, next, *config.S3, middlewareName)
+		}
+	}
+
+	// SAML
+	if config.SAML != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewSAML(ctx, next, *config.SAML, middlewareName)
+		}
+	}
+
+	// SPNEGOAuth
+	if config.SPNEGOAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewSPNEGO(ctx, next, *config.SPNEGOAuth, middlewareName)
 		}
 	}
 
@@ -364,9 +536,35 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		return nil, fmt.Errorf("invalid middleware %q configuration: invalid middleware type or middleware does not exist", middlewareName)
 	}
 
+	if b.metricsRegistry.IsMiddlewareEnabled() {
+		routerName, _ := ctx.Value(middlewareRouterKey).(string)
+		middleware = metricsmiddleware.WrapMiddlewareHandler(ctx, b.metricsRegistry, routerName, middlewareName, middlewareType(config.Middleware), middleware)
+	}
+
+	if config.When != "" {
+		return wrapConditional(config.When, tracing.Wrap(ctx, middleware)), nil
+	}
+
 	return tracing.Wrap(ctx, middleware), nil
 }
 
+// middlewareType returns the type of the middleware, which is the name of its configuration field.
+func middlewareType(config *dynamic.Middleware) string {
+	if config.Plugin != nil {
+		return "Plugin"
+	}
+
+	v := reflect.ValueOf(config).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			return v.Type().Field(i).Name
+		}
+	}
+
+	return ""
+}
+
 func inSlice(element string, stack []string) bool {
 	for _, value := range stack {
 		if value == element {
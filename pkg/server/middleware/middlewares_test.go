@@ -9,6 +9,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/server/provider"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +19,7 @@ func TestBuilder_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, metrics.NewVoidRegistry())
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -29,7 +30,7 @@ func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"foobar": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, metrics.NewVoidRegistry())
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -241,6 +242,42 @@ func TestBuilder_BuildChainWithContext(t *testing.T) {
 			},
 			expectedError: errors.New("could not instantiate middleware m2: recursion detected in m0->m1->m2->m3->m2"),
 		},
+		{
+			desc:       "Should expand the variables of a chain",
+			buildChain: []string{"middleware-chain-1"},
+			configuration: map[string]*dynamic.Middleware{
+				"middleware-1": {
+					Headers: &dynamic.Headers{
+						CustomRequestHeaders: map[string]string{
+							"middleware-1": "${prefix}-middleware-1",
+							"undefined":    "${undefined}-${1}",
+						},
+					},
+				},
+				"middleware-2": {
+					Headers: &dynamic.Headers{
+						CustomRequestHeaders: map[string]string{"middleware-2": "${prefix}-middleware-2"},
+					},
+				},
+				"middleware-chain-1": {
+					Chain: &dynamic.Chain{
+						Middlewares: []string{"middleware-1", "middleware-chain-2"},
+						Variables:   map[string]string{"prefix": "chain-1"},
+					},
+				},
+				"middleware-chain-2": {
+					Chain: &dynamic.Chain{
+						Middlewares: []string{"middleware-2"},
+						Variables:   map[string]string{"prefix": "chain-2"},
+					},
+				},
+			},
+			expected: map[string]string{
+				"middleware-1": "chain-1-middleware-1",
+				"middleware-2": "chain-2-middleware-2",
+				"undefined":    "${undefined}-${1}",
+			},
+		},
 		{
 			desc:       "--",
 			buildChain: []string{"m0"},
@@ -270,7 +307,7 @@ func TestBuilder_BuildChainWithContext(t *testing.T) {
 					Middlewares: test.configuration,
 				},
 			})
-			builder := NewBuilder(rtConf.Middlewares, nil, nil)
+			builder := NewBuilder(rtConf.Middlewares, nil, nil, metrics.NewVoidRegistry())
 
 			result := builder.BuildChain(ctx, test.buildChain)
 
@@ -322,6 +359,12 @@ func TestBuilder_buildConstructor(t *testing.T) {
 				MemResponseBodyBytes: 5,
 			},
 		},
+		"ap-when-invalid": {
+			AddPrefix: &dynamic.AddPrefix{
+				Prefix: "foo/",
+			},
+			When: "Foo(`/api`)",
+		},
 	}
 
 	rtConf := runtime.NewConfig(dynamic.Configuration{
@@ -329,7 +372,7 @@ func TestBuilder_buildConstructor(t *testing.T) {
 			Middlewares: testConfig,
 		},
 	})
-	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil)
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil, metrics.NewVoidRegistry())
 
 	testCases := []struct {
 		desc          string
@@ -361,6 +404,11 @@ func TestBuilder_buildConstructor(t *testing.T) {
 			middlewareID:  "ap-foo",
 			expectedError: false,
 		},
+		{
+			desc:          "Should not create a middleware with an invalid when rule",
+			middlewareID:  "ap-when-invalid",
+			expectedError: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -382,3 +430,68 @@ func TestBuilder_buildConstructor(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_buildConstructorWhen(t *testing.T) {
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"ap-when": {
+					AddPrefix: &dynamic.AddPrefix{
+						Prefix: "/foo",
+					},
+					When: "PathPrefix(`/api`) || Headers(`X-Foo`, `bar`)",
+				},
+			},
+		},
+	})
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil, metrics.NewVoidRegistry())
+
+	constructor, err := middlewaresBuilder.buildConstructor(context.Background(), "ap-when")
+	require.NoError(t, err)
+
+	handler, err := constructor(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.URL.Path))
+	}))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc         string
+		path         string
+		headers      map[string]string
+		expectedPath string
+	}{
+		{
+			desc:         "matching path",
+			path:         "/api/bar",
+			expectedPath: "/foo/api/bar",
+		},
+		{
+			desc:         "matching header",
+			path:         "/bar",
+			headers:      map[string]string{"X-Foo": "bar"},
+			expectedPath: "/foo/bar",
+		},
+		{
+			desc:         "not matching",
+			path:         "/bar",
+			expectedPath: "/bar",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+test.path, nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedPath, recorder.Body.String())
+		})
+	}
+}
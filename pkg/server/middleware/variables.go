@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+var variableRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// addVariablesInContext adds the variables of a chain to the ones of the enclosing chains,
+// the variables of the innermost chain taking precedence.
+func addVariablesInContext(ctx context.Context, variables map[string]string) context.Context {
+	if len(variables) == 0 {
+		return ctx
+	}
+
+	merged := make(map[string]string)
+	for name, value := range getVariables(ctx) {
+		merged[name] = value
+	}
+	for name, value := range variables {
+		merged[name] = value
+	}
+
+	return context.WithValue(ctx, middlewareVariablesKey, merged)
+}
+
+func getVariables(ctx context.Context) map[string]string {
+	variables, _ := ctx.Value(middlewareVariablesKey).(map[string]string)
+	return variables
+}
+
+// expandVariables returns a copy of the middleware configuration,
+// where the ${name} references to the given variables are replaced by their values.
+// The references to undefined variables are left as is,
+// so that e.g. the ${1} references to the regular expression groups are kept.
+func expandVariables(config *dynamic.Middleware, variables map[string]string) *dynamic.Middleware {
+	expanded := config.DeepCopy()
+	expandValue(reflect.ValueOf(expanded), variables)
+
+	return expanded
+}
+
+func expandValue(v reflect.Value, variables map[string]string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+
+		if v.Kind() == reflect.Interface {
+			// The value held by an interface is not addressable, so it is expanded on a copy.
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			expandValue(elem, variables)
+			v.Set(elem)
+			return
+		}
+
+		expandValue(v.Elem(), variables)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				expandValue(v.Field(i), variables)
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			expandValue(v.Index(i), variables)
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			expandValue(elem, variables)
+			v.SetMapIndex(key, elem)
+		}
+
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(expandString(v.String(), variables))
+		}
+	}
+}
+
+func expandString(value string, variables map[string]string) string {
+	return variableRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		name := variableRegexp.FindStringSubmatch(reference)[1]
+		if variable, ok := variables[name]; ok {
+			return variable
+		}
+
+		return reference
+	})
+}
@@ -8,9 +8,15 @@ import (
 	"github.com/containous/alice"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/middlewares/deadline"
+	"github.com/containous/traefik/v2/pkg/middlewares/httpversion"
+	metricsmiddleware "github.com/containous/traefik/v2/pkg/middlewares/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/pacing"
 	"github.com/containous/traefik/v2/pkg/middlewares/recovery"
 	"github.com/containous/traefik/v2/pkg/middlewares/tracing"
+	"github.com/containous/traefik/v2/pkg/middlewares/trailingslash"
 	"github.com/containous/traefik/v2/pkg/rules"
 	"github.com/containous/traefik/v2/pkg/server/middleware"
 	"github.com/containous/traefik/v2/pkg/server/provider"
@@ -18,6 +24,9 @@ import (
 
 const (
 	recoveryMiddlewareName = "traefik-internal-recovery"
+
+	// routerHeader is the response header telling the router that handled the request, on the entry points enabling it.
+	routerHeader = "X-Traefik-Router"
 )
 
 type middlewareBuilder interface {
@@ -35,20 +44,39 @@ type Manager struct {
 	serviceManager     serviceManager
 	middlewaresBuilder middlewareBuilder
 	chainBuilder       *middleware.ChainBuilder
+	metricsRegistry    metrics.Registry
 	conf               *runtime.Configuration
+	autoPriority       bool
+
+	routerHeaderEntryPoints map[string]bool
 }
 
 // NewManager Creates a new Manager.
-func NewManager(conf *runtime.Configuration, serviceManager serviceManager, middlewaresBuilder middlewareBuilder, chainBuilder *middleware.ChainBuilder) *Manager {
+func NewManager(conf *runtime.Configuration, serviceManager serviceManager, middlewaresBuilder middlewareBuilder, chainBuilder *middleware.ChainBuilder, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
 		routerHandlers:     make(map[string]http.Handler),
 		serviceManager:     serviceManager,
 		middlewaresBuilder: middlewaresBuilder,
 		chainBuilder:       chainBuilder,
+		metricsRegistry:    metricsRegistry,
 		conf:               conf,
 	}
 }
 
+// SetRouterHeader enables the X-Traefik-Router header on the given entry points,
+// telling the router that handled each request in the response.
+func (m *Manager) SetRouterHeader(entryPoints []string) {
+	m.routerHeaderEntryPoints = make(map[string]bool, len(entryPoints))
+	for _, entryPointName := range entryPoints {
+		m.routerHeaderEntryPoints[entryPointName] = true
+	}
+}
+
+// SetAutoPriority makes the specificity of their rule, instead of its length, the priority of the routers without priority.
+func (m *Manager) SetAutoPriority(autoPriority bool) {
+	m.autoPriority = autoPriority
+}
+
 func (m *Manager) getHTTPRouters(ctx context.Context, entryPoints []string, tls bool) map[string]map[string]*runtime.RouterInfo {
 	if m.conf != nil {
 		return m.conf.GetRoutersByEntryPoints(ctx, entryPoints, tls)
@@ -65,7 +93,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 		entryPointName := entryPointName
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers)
+		handler, err := m.buildEntryPointHandler(ctx, routers, m.routerHeaderEntryPoints[entryPointName])
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
@@ -101,7 +129,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.RouterInfo) (http.Handler, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.RouterInfo, routerHeader bool) (http.Handler, error) {
 	router, err := rules.NewRouter()
 	if err != nil {
 		return nil, err
@@ -118,12 +146,44 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 			continue
 		}
 
-		err = router.AddRoute(routerConfig.Rule, routerConfig.Priority, handler)
+		if routerHeader {
+			// The router handlers are shared by the entry points, the header is set by the ones enabling it only.
+			handler = withRouterHeader(handler, routerName)
+		}
+
+		priority := routerConfig.Priority
+		if m.autoPriority && priority == 0 {
+			// An invalid rule is reported when adding its route.
+			if specificity, err := rules.Specificity(routerConfig.Rule); err == nil {
+				priority = specificity
+			}
+		}
+
+		var trailingSlashHandler http.Handler
+		if routerConfig.TrailingSlash != "" && routerConfig.TrailingSlash != trailingslash.Keep {
+			trailingSlashHandler, err = trailingslash.New(ctxRouter, handler, routerConfig.TrailingSlash, routerName)
+			if err != nil {
+				routerConfig.AddError(err, true)
+				logger.Error(err)
+				continue
+			}
+		}
+
+		err = router.AddRoute(routerConfig.Rule, priority, handler)
 		if err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error(err)
 			continue
 		}
+
+		if trailingSlashHandler != nil {
+			err = router.AddTrailingSlashRoute(routerConfig.Rule, priority, trailingSlashHandler)
+			if err != nil {
+				routerConfig.AddError(err, true)
+				logger.Error(err)
+				continue
+			}
+		}
 	}
 
 	router.SortRoutes()
@@ -146,9 +206,19 @@ func (m *Manager) buildRouterHandler(ctx context.Context, routerName string, rou
 		return nil, err
 	}
 
-	handlerWithAccessLog, err := alice.New(func(next http.Handler) (http.Handler, error) {
+	chain := alice.New(func(next http.Handler) (http.Handler, error) {
 		return accesslog.NewFieldHandler(next, accesslog.RouterName, routerName, nil), nil
-	}).Then(handler)
+	})
+
+	if m.metricsRegistry != nil && m.metricsRegistry.IsRouterEnabled() {
+		chain = chain.Append(metricsmiddleware.WrapRouterHandler(ctx, m.metricsRegistry, routerName, provider.GetQualifiedName(ctx, routerConfig.Service)))
+	}
+
+	if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+		chain = chain.Append(metricsmiddleware.WrapRouterNameHandler(routerName))
+	}
+
+	handlerWithAccessLog, err := chain.Then(handler)
 	if err != nil {
 		log.FromContext(ctx).Error(err)
 		m.routerHandlers[routerName] = handler
@@ -175,16 +245,55 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *runtime.RouterIn
 		return nil, err
 	}
 
-	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+	chain := alice.New()
+
+	if router.HTTPVersions != nil {
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return httpversion.New(ctx, next, *router.HTTPVersions, m.metricsRegistry.RouterHTTPVersionRejectionsCounter(), routerName)
+		})
+	}
+
+	if router.Deadline != nil {
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return deadline.New(ctx, next, *router.Deadline, routerName)
+		})
+	}
+
+	// The responses are paced once they went through the middlewares, e.g. once they are compressed.
+	if router.Pacing != nil {
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return pacing.New(ctx, next, *router.Pacing, routerName)
+		})
+	}
+
+	mCtx := middleware.WithRouterName(middleware.WithServiceName(ctx, provider.GetQualifiedName(ctx, router.Service)), routerName)
+	mHandler := m.middlewaresBuilder.BuildChain(mCtx, router.Middlewares)
 
 	tHandler := func(next http.Handler) (http.Handler, error) {
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
 	}
 
-	return alice.New().Extend(*mHandler).Append(tHandler).Then(sHandler)
+	chain = chain.Extend(*mHandler).Append(tHandler)
+
+	// The remaining time is computed once the middlewares are done, right before forwarding the request.
+	if router.Deadline != nil {
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return deadline.Propagate(next, router.Deadline.Header), nil
+		})
+	}
+
+	return chain.Then(sHandler)
 }
 
 // BuildDefaultHTTPRouter creates a default HTTP router.
 func BuildDefaultHTTPRouter() http.Handler {
 	return http.NotFoundHandler()
 }
+
+// withRouterHeader returns the handler setting the X-Traefik-Router header of the responses to the name of the router.
+func withRouterHeader(next http.Handler, routerName string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(routerHeader, routerName)
+		next.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,111 @@
+package tcp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/tcp"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+const (
+	// meteringFlushInterval is the interval the bytes counted on a connection are recorded at, at most.
+	meteringFlushInterval = time.Second
+	// meteringFlushSize is the number of bytes counted on a connection before they are recorded.
+	meteringFlushSize = 64 * 1024
+)
+
+// connectionMetrics holds the metrics of the connections of the TCP routers of an entry point, labeled with its name.
+type connectionMetrics struct {
+	conns         gokitmetrics.Counter
+	openConns     gokitmetrics.Gauge
+	bytesReceived gokitmetrics.Counter
+	bytesSent     gokitmetrics.Counter
+}
+
+func newConnectionMetrics(entryPointName string, metricsRegistry metrics.Registry) *connectionMetrics {
+	return &connectionMetrics{
+		conns:         metricsRegistry.EntryPointTCPConnsCounter().With("entrypoint", entryPointName),
+		openConns:     metricsRegistry.EntryPointTCPOpenConnsGauge().With("entrypoint", entryPointName),
+		bytesReceived: metricsRegistry.EntryPointTCPBytesCounter().With("direction", "received", "entrypoint", entryPointName),
+		bytesSent:     metricsRegistry.EntryPointTCPBytesCounter().With("direction", "sent", "entrypoint", entryPointName),
+	}
+}
+
+// meter returns the handler metering the connections before passing them to the given handler.
+func (c *connectionMetrics) meter(next tcp.Handler) tcp.Handler {
+	if c == nil {
+		return next
+	}
+
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		next.ServeTCP(newMeteredConnection(conn, c))
+	})
+}
+
+func newMeteredConnection(conn tcp.WriteCloser, connMetrics *connectionMetrics) *meteredConnection {
+	connMetrics.conns.Add(1)
+	connMetrics.openConns.Add(1)
+
+	return &meteredConnection{
+		WriteCloser: conn,
+		metrics:     connMetrics,
+		lastFlush:   time.Now().UnixNano(),
+	}
+}
+
+// meteredConnection is a connection counting the bytes read and written on it.
+// The bytes are accumulated on the connection, and recorded periodically and when it is closed.
+type meteredConnection struct {
+	// The 64-bit atomic fields come first for their alignment on 32-bit platforms.
+	received  int64
+	sent      int64
+	lastFlush int64
+
+	tcp.WriteCloser
+	metrics   *connectionMetrics
+	closeOnce sync.Once
+}
+
+func (m *meteredConnection) Read(p []byte) (int, error) {
+	n, err := m.WriteCloser.Read(p)
+	if n > 0 && atomic.AddInt64(&m.received, int64(n)) >= meteringFlushSize || m.flushDue() {
+		m.flush()
+	}
+	return n, err
+}
+
+func (m *meteredConnection) Write(p []byte) (int, error) {
+	n, err := m.WriteCloser.Write(p)
+	if n > 0 && atomic.AddInt64(&m.sent, int64(n)) >= meteringFlushSize || m.flushDue() {
+		m.flush()
+	}
+	return n, err
+}
+
+// Close closes the connection, the counted bytes being recorded and the open connections gauge decremented on the first call only.
+func (m *meteredConnection) Close() error {
+	m.closeOnce.Do(func() {
+		m.flush()
+		m.metrics.openConns.Add(-1)
+	})
+	return m.WriteCloser.Close()
+}
+
+func (m *meteredConnection) flushDue() bool {
+	return time.Now().UnixNano()-atomic.LoadInt64(&m.lastFlush) >= int64(meteringFlushInterval)
+}
+
+// flush records the bytes counted since the last flush.
+func (m *meteredConnection) flush() {
+	atomic.StoreInt64(&m.lastFlush, time.Now().UnixNano())
+
+	if received := atomic.SwapInt64(&m.received, 0); received > 0 {
+		m.metrics.bytesReceived.Add(float64(received))
+	}
+	if sent := atomic.SwapInt64(&m.sent, 0); sent > 0 {
+		m.metrics.bytesSent.Add(float64(sent))
+	}
+}
@@ -0,0 +1,80 @@
+package tcp
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeConn is a tcp.WriteCloser over one end of a net.Pipe.
+type pipeConn struct {
+	net.Conn
+}
+
+func (p pipeConn) CloseWrite() error {
+	return nil
+}
+
+func TestMeteredConnection(t *testing.T) {
+	connMetrics := &connectionMetrics{
+		conns:         generic.NewCounter("conns"),
+		openConns:     generic.NewGauge("open_conns"),
+		bytesReceived: generic.NewCounter("bytes_received"),
+		bytesSent:     generic.NewCounter("bytes_sent"),
+	}
+
+	server, client := net.Pipe()
+	defer func() { _ = client.Close() }()
+
+	conn := newMeteredConnection(pipeConn{Conn: server}, connMetrics)
+	assert.Equal(t, float64(1), connMetrics.conns.(*generic.Counter).Value())
+	assert.Equal(t, float64(1), connMetrics.openConns.(*generic.Gauge).Value())
+
+	go func() {
+		_, _ = client.Write([]byte("ping"))
+		_, _ = io.ReadFull(client, make([]byte, 6))
+	}()
+
+	_, err := io.ReadFull(conn, make([]byte, 4))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("pong!!"))
+	require.NoError(t, err)
+
+	// The bytes are accumulated on the connection until it is closed.
+	assert.Equal(t, float64(0), connMetrics.bytesReceived.(*generic.Counter).Value())
+	assert.Equal(t, float64(0), connMetrics.bytesSent.(*generic.Counter).Value())
+
+	require.NoError(t, conn.Close())
+	_ = conn.Close()
+	assert.Equal(t, float64(4), connMetrics.bytesReceived.(*generic.Counter).Value())
+	assert.Equal(t, float64(6), connMetrics.bytesSent.(*generic.Counter).Value())
+	assert.Equal(t, float64(1), connMetrics.conns.(*generic.Counter).Value())
+	assert.Equal(t, float64(0), connMetrics.openConns.(*generic.Gauge).Value())
+}
+
+func TestMeteredConnection_flushSize(t *testing.T) {
+	connMetrics := &connectionMetrics{
+		conns:         generic.NewCounter("conns"),
+		openConns:     generic.NewGauge("open_conns"),
+		bytesReceived: generic.NewCounter("bytes_received"),
+		bytesSent:     generic.NewCounter("bytes_sent"),
+	}
+
+	server, client := net.Pipe()
+	defer func() { _ = client.Close() }()
+
+	conn := newMeteredConnection(pipeConn{Conn: server}, connMetrics)
+	defer func() { _ = conn.Close() }()
+
+	go func() { _, _ = io.Copy(ioutil.Discard, client) }()
+
+	_, err := conn.Write(make([]byte, meteringFlushSize))
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(meteringFlushSize), connMetrics.bytesSent.(*generic.Counter).Value())
+}
@@ -10,7 +10,9 @@ import (
 	"strings"
 
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/rules"
 	"github.com/containous/traefik/v2/pkg/server/provider"
 	tcpservice "github.com/containous/traefik/v2/pkg/server/service/tcp"
@@ -46,6 +48,19 @@ type Manager struct {
 	httpsHandlers  map[string]http.Handler
 	tlsManager     *traefiktls.Manager
 	conf           *runtime.Configuration
+	entryPointsTLS map[string]*static.EntryPointTLS
+
+	metricsRegistry metrics.Registry
+}
+
+// SetEntryPointsTLS sets the TLS store and the certificate selection of the entry points.
+func (m *Manager) SetEntryPointsTLS(entryPointsTLS map[string]*static.EntryPointTLS) {
+	m.entryPointsTLS = entryPointsTLS
+}
+
+// SetMetricsRegistry sets the registry recording the traffic of the TCP routers of each entry point.
+func (m *Manager) SetMetricsRegistry(metricsRegistry metrics.Registry) {
+	m.metricsRegistry = metricsRegistry
 }
 
 func (m *Manager) getTCPRouters(ctx context.Context, entryPoints []string) map[string]map[string]*runtime.TCPRouterInfo {
@@ -77,7 +92,12 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers, entryPointsRoutersHTTP[entryPointName], m.httpHandlers[entryPointName], m.httpsHandlers[entryPointName])
+		var connMetrics *connectionMetrics
+		if m.metricsRegistry != nil {
+			connMetrics = newConnectionMetrics(entryPointName, m.metricsRegistry)
+		}
+
+		handler, err := m.buildEntryPointHandler(ctx, m.getTLSConfigGetter(entryPointName), routers, entryPointsRoutersHTTP[entryPointName], m.httpHandlers[entryPointName], m.httpsHandlers[entryPointName], connMetrics)
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
@@ -87,16 +107,43 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 	return entryPointHandlers
 }
 
+// getTLSConfigGetter returns the function building the TLS configurations of the entry point,
+// from its TLS store, with its certificate selection and, when set, its TLS options.
+func (m *Manager) getTLSConfigGetter(entryPointName string) func(configName string) (*tls.Config, error) {
+	storeName := defaultTLSStoreName
+	selection := traefiktls.SelectionMostSpecific
+
+	if epTLS := m.entryPointsTLS[entryPointName]; epTLS != nil {
+		if epTLS.DefaultStore != "" {
+			storeName = epTLS.DefaultStore
+		}
+		if epTLS.CertificateSelection != "" {
+			selection = epTLS.CertificateSelection
+		}
+		if epTLS.Options != "" {
+			// The options of the entry point apply to all its connections, e.g. to require client certificates,
+			// so that a server name matching a router with weaker options cannot bypass them.
+			return func(string) (*tls.Config, error) {
+				return m.tlsManager.GetWithSelection(storeName, epTLS.Options, selection)
+			}
+		}
+	}
+
+	return func(configName string) (*tls.Config, error) {
+		return m.tlsManager.GetWithSelection(storeName, configName, selection)
+	}
+}
+
 type nameAndConfig struct {
 	routerName string // just so we have it as additional information when logging
 	TLSConfig  *tls.Config
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.TCPRouterInfo, configsHTTP map[string]*runtime.RouterInfo, handlerHTTP, handlerHTTPS http.Handler) (*tcp.Router, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, getTLSConfig func(configName string) (*tls.Config, error), configs map[string]*runtime.TCPRouterInfo, configsHTTP map[string]*runtime.RouterInfo, handlerHTTP, handlerHTTPS http.Handler, connMetrics *connectionMetrics) (*tcp.Router, error) {
 	router := &tcp.Router{}
 	router.HTTPHandler(handlerHTTP)
 
-	defaultTLSConf, err := m.tlsManager.Get(defaultTLSStoreName, defaultTLSConfigName)
+	defaultTLSConf, err := getTLSConfig(defaultTLSConfigName)
 	if err != nil {
 		log.FromContext(ctx).Errorf("Error during the build of the default TLS configuration: %v", err)
 	}
@@ -135,7 +182,7 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 					tlsOptionsName = provider.GetQualifiedName(ctxRouter, routerHTTPConfig.TLS.Options)
 				}
 
-				tlsConf, err := m.tlsManager.Get(defaultTLSStoreName, tlsOptionsName)
+				tlsConf, err := getTLSConfig(tlsOptionsName)
 				if err != nil {
 					routerHTTPConfig.AddError(err, true)
 					logger.Debug(err)
@@ -261,7 +308,7 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 			switch {
 			case routerConfig.TLS != nil:
 				if routerConfig.TLS.Passthrough {
-					router.AddRoute(domain, handler)
+					router.AddRoute(domain, connMetrics.meter(handler))
 				} else {
 					tlsOptionsName := routerConfig.TLS.Options
 
@@ -273,17 +320,18 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 						tlsOptionsName = provider.GetQualifiedName(ctxRouter, tlsOptionsName)
 					}
 
-					tlsConf, err := m.tlsManager.Get(defaultTLSStoreName, tlsOptionsName)
+					tlsConf, err := getTLSConfig(tlsOptionsName)
 					if err != nil {
 						routerConfig.AddError(err, true)
 						logger.Debug(err)
 						continue
 					}
 
-					router.AddRouteTLS(domain, handler, tlsConf)
+					// The connections are metered before the TLS termination, as the passthrough ones.
+					router.AddRoute(domain, connMetrics.meter(&tcp.TLSHandler{Next: handler, Config: tlsConf}))
 				}
 			case domain == "*":
-				router.AddCatchAllNoTLS(handler)
+				router.AddCatchAllNoTLS(connMetrics.meter(handler))
 			default:
 				logger.Warn("TCP Router ignored, cannot specify a Host rule without TLS")
 			}
@@ -2,13 +2,21 @@ package tcp
 
 import (
 	"context"
+	cryptotls "crypto/tls"
+	"io/ioutil"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/server/service/tcp"
+	tcpcore "github.com/containous/traefik/v2/pkg/tcp"
 	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tls/generate"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRuntimeConfiguration(t *testing.T) {
@@ -200,7 +208,7 @@ func TestRuntimeConfiguration(t *testing.T) {
 				TCPServices: test.serviceConfig,
 				TCPRouters:  test.routerConfig,
 			}
-			serviceManager := tcp.NewManager(conf)
+			serviceManager := tcp.NewManager(conf, nil)
 			tlsManager := tls.NewManager()
 			tlsManager.UpdateConfigs(
 				context.Background(),
@@ -241,3 +249,113 @@ func TestRuntimeConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_BuildHandlers_entryPointTLSOptions(t *testing.T) {
+	caCert, _, err := generate.KeyPair("ca.localhost", time.Time{})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		entryPointTLS  *static.EntryPointTLS
+		expectedFailed bool
+	}{
+		{
+			desc: "options of the routers",
+		},
+		{
+			desc:           "options of the entry point requiring a client certificate",
+			entryPointTLS:  &static.EntryPointTLS{Options: "mtls"},
+			expectedFailed: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &runtime.Configuration{
+				Routers: map[string]*runtime.RouterInfo{
+					"metrics": {
+						Router: &dynamic.Router{
+							EntryPoints: []string{"web"},
+							Service:     "metrics",
+							Rule:        "PathPrefix(`/metrics`)",
+							TLS:         &dynamic.RouterTLSConfig{Options: "mtls"},
+						},
+					},
+					"foo": {
+						Router: &dynamic.Router{
+							EntryPoints: []string{"web"},
+							Service:     "foo",
+							Rule:        "Host(`foo.localhost`)",
+							TLS:         &dynamic.RouterTLSConfig{},
+						},
+					},
+				},
+			}
+
+			tlsManager := tls.NewManager()
+			tlsManager.UpdateConfigs(context.Background(), nil,
+				map[string]tls.Options{
+					"default": {},
+					"mtls": {
+						ClientAuth: tls.ClientAuth{
+							CAFiles:        []tls.FileOrContent{tls.FileOrContent(caCert)},
+							ClientAuthType: "RequireAndVerifyClientCert",
+						},
+					},
+				},
+				nil)
+
+			routerManager := NewManager(conf, tcp.NewManager(conf, nil), nil, nil, tlsManager)
+			if test.entryPointTLS != nil {
+				routerManager.SetEntryPointsTLS(map[string]*static.EntryPointTLS{"web": test.entryPointTLS})
+			}
+
+			handlers := routerManager.BuildHandlers(context.Background(), []string{"web"})
+			router := handlers["web"]
+			require.NotNil(t, router)
+
+			router.HTTPSForwarder(tcpcore.HandlerFunc(func(conn tcpcore.WriteCloser) {
+				_, _ = conn.Write([]byte("OK"))
+				_ = conn.Close()
+			}))
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer func() { _ = listener.Close() }()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				router.ServeTCP(conn.(*net.TCPConn))
+			}()
+
+			// The server name matches the router without client authentication.
+			conn, err := cryptotls.Dial("tcp", listener.Addr().String(), &cryptotls.Config{
+				ServerName:         "foo.localhost",
+				InsecureSkipVerify: true,
+			})
+			if err == nil {
+				defer func() { _ = conn.Close() }()
+				_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+				var body []byte
+				body, err = ioutil.ReadAll(conn)
+				if err == nil && string(body) != "OK" {
+					t.Fatalf("unexpected response: %q", body)
+				}
+			}
+
+			if test.expectedFailed {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,9 +3,14 @@ package server
 import (
 	"context"
 
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/adaptiveconcurrency"
+	"github.com/containous/traefik/v2/pkg/middlewares/retry"
 	"github.com/containous/traefik/v2/pkg/server/middleware"
 	"github.com/containous/traefik/v2/pkg/server/router"
 	routertcp "github.com/containous/traefik/v2/pkg/server/router/tcp"
@@ -15,6 +20,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/server/service/udp"
 	tcpCore "github.com/containous/traefik/v2/pkg/tcp"
 	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
 	udpCore "github.com/containous/traefik/v2/pkg/udp"
 )
 
@@ -22,19 +28,44 @@ import (
 type RouterFactory struct {
 	entryPointsTCP []string
 	entryPointsUDP []string
+	entryPointsTLS map[string]*static.EntryPointTLS
 
 	managerFactory *service.ManagerFactory
 
 	pluginBuilder middleware.PluginsBuilder
 
+	metricsRegistry metrics.Registry
+
 	chainBuilder *middleware.ChainBuilder
 	tlsManager   *tls.Manager
+
+	guardrails *guardrails
+
+	routerHeaderEntryPoints []string
+	autoPriority            bool
+
+	cluster *cluster.Node
+	ipSets  ip.Sets
+
+	// retryBudgets are kept across the configuration reloads, so the retries are still limited right after a reload.
+	retryBudgets *retry.Budgets
+	// limiters are kept across the configuration reloads, so the measured limits are not lost on each reload.
+	limiters *adaptiveconcurrency.Limiters
 }
 
 // NewRouterFactory creates a new RouterFactory.
-func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager, chainBuilder *middleware.ChainBuilder, pluginBuilder middleware.PluginsBuilder) *RouterFactory {
-	var entryPointsTCP, entryPointsUDP []string
+func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager, chainBuilder *middleware.ChainBuilder, pluginBuilder middleware.PluginsBuilder, metricsRegistry metrics.Registry) *RouterFactory {
+	var entryPointsTCP, entryPointsUDP, routerHeaderEntryPoints []string
+	entryPointsTLS := make(map[string]*static.EntryPointTLS)
 	for name, cfg := range staticConfiguration.EntryPoints {
+		if cfg.TLS != nil {
+			entryPointsTLS[name] = cfg.TLS
+		}
+
+		if cfg.HTTP.RouterHeader {
+			routerHeaderEntryPoints = append(routerHeaderEntryPoints, name)
+		}
+
 		protocol, err := cfg.GetProtocol()
 		if err != nil {
 			// Should never happen because Traefik should not start if protocol is invalid.
@@ -48,36 +79,100 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 		}
 	}
 
+	if staticConfiguration.Metrics != nil && staticConfiguration.Metrics.Prometheus != nil {
+		prom := staticConfiguration.Metrics.Prometheus
+		if prom.TLS != nil && !prom.ManualRouting {
+			entryPointsTLS[prom.EntryPoint] = prometheusEntryPointTLS(prom.TLS, entryPointsTLS[prom.EntryPoint])
+		}
+	}
+
 	return &RouterFactory{
-		entryPointsTCP: entryPointsTCP,
-		entryPointsUDP: entryPointsUDP,
-		managerFactory: managerFactory,
-		tlsManager:     tlsManager,
-		chainBuilder:   chainBuilder,
-		pluginBuilder:  pluginBuilder,
+		entryPointsTCP:          entryPointsTCP,
+		entryPointsUDP:          entryPointsUDP,
+		entryPointsTLS:          entryPointsTLS,
+		managerFactory:          managerFactory,
+		tlsManager:              tlsManager,
+		chainBuilder:            chainBuilder,
+		pluginBuilder:           pluginBuilder,
+		metricsRegistry:         metricsRegistry,
+		guardrails:              newGuardrails(staticConfiguration.Guardrails),
+		routerHeaderEntryPoints: routerHeaderEntryPoints,
+		autoPriority:            staticConfiguration.Experimental != nil && staticConfiguration.Experimental.AutoPriority,
+		retryBudgets:            retry.NewBudgets(),
+		limiters:                adaptiveconcurrency.NewLimiters(),
+	}
+}
+
+// prometheusEntryPointTLS serves the certificate and enforces the client authentication of the metrics on all the connections of their entry point,
+// with the store and the options of the internal provider.
+func prometheusEntryPointTLS(promTLS *types.MetricsTLS, epTLS *static.EntryPointTLS) *static.EntryPointTLS {
+	result := &static.EntryPointTLS{}
+	if epTLS != nil {
+		*result = *epTLS
+	}
+
+	if promTLS.CertFile != "" || promTLS.KeyFile != "" {
+		result.DefaultStore = "prometheus@internal"
+	}
+	if len(promTLS.CAFiles) > 0 {
+		result.Options = "prometheus@internal"
 	}
+
+	return result
+}
+
+// SetCluster sets the cluster the middlewares share their state with.
+func (f *RouterFactory) SetCluster(node *cluster.Node) {
+	f.cluster = node
+}
+
+// SetIPSets sets the named IP sets the middlewares reference.
+func (f *RouterFactory) SetIPSets(ipSets ip.Sets) {
+	f.ipSets = ipSets
 }
 
 // CreateRouters creates new TCPRouters and UDPRouters.
 func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string]*tcpCore.Router, map[string]udpCore.Handler) {
 	ctx := context.Background()
 
+	if f.guardrails != nil {
+		f.guardrails.apply(rtConf)
+	}
+
 	// HTTP
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder, f.metricsRegistry)
+	if f.cluster != nil {
+		middlewaresBuilder.SetCluster(f.cluster)
+	}
+	middlewaresBuilder.SetIPSets(f.ipSets)
+	middlewaresBuilder.SetRetryBudgets(f.retryBudgets)
+	middlewaresBuilder.SetLimiters(f.limiters)
 
-	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, f.chainBuilder)
+	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, f.chainBuilder, f.metricsRegistry)
+	routerManager.SetRouterHeader(f.routerHeaderEntryPoints)
+	routerManager.SetAutoPriority(f.autoPriority)
 
 	handlersNonTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, false)
 	handlersTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, true)
 
+	middlewareDefined := func(middlewareName string) bool {
+		_, ok := rtConf.Middlewares[middlewareName]
+		return ok
+	}
+	f.retryBudgets.Prune(middlewareDefined)
+	f.limiters.Prune(middlewareDefined)
+
 	serviceManager.LaunchHealthCheck()
+	f.managerFactory.Commit()
 
 	// TCP
-	svcTCPManager := tcp.NewManager(rtConf)
+	svcTCPManager := tcp.NewManager(rtConf, f.managerFactory.AffinityTable())
 
 	rtTCPManager := routertcp.NewManager(rtConf, svcTCPManager, handlersNonTLS, handlersTLS, f.tlsManager)
+	rtTCPManager.SetEntryPointsTLS(f.entryPointsTLS)
+	rtTCPManager.SetMetricsRegistry(f.metricsRegistry)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
 
 	// UDP
@@ -13,6 +13,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/server/service"
 	th "github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,7 +52,7 @@ func TestReuseService(t *testing.T) {
 	managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry())
 	tlsManager := tls.NewManager()
 
-	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), nil)
+	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), nil, metrics.NewVoidRegistry())
 
 	entryPointsHandlers, _ := factory.CreateRouters(runtime.NewConfig(dynamic.Configuration{HTTP: dynamicConfigs}))
 
@@ -185,7 +186,7 @@ func TestServerResponseEmptyBackend(t *testing.T) {
 			managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry())
 			tlsManager := tls.NewManager()
 
-			factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), nil)
+			factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), nil, metrics.NewVoidRegistry())
 
 			entryPointsHandlers, _ := factory.CreateRouters(runtime.NewConfig(dynamic.Configuration{HTTP: test.config(testServer.URL)}))
 
@@ -224,7 +225,7 @@ func TestInternalServices(t *testing.T) {
 	managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry())
 	tlsManager := tls.NewManager()
 
-	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), nil)
+	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), nil, metrics.NewVoidRegistry())
 
 	entryPointsHandlers, _ := factory.CreateRouters(runtime.NewConfig(dynamic.Configuration{HTTP: dynamicConfigs}))
 
@@ -235,3 +236,69 @@ func TestInternalServices(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, responseRecorderOk.Result().StatusCode, "status code")
 }
+
+func TestNewRouterFactory_prometheusTLS(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		prom     *types.Prometheus
+		expected *static.EntryPointTLS
+	}{
+		{
+			desc: "without TLS",
+			prom: &types.Prometheus{EntryPoint: "metrics"},
+		},
+		{
+			desc: "with manual routing",
+			prom: &types.Prometheus{
+				EntryPoint:    "metrics",
+				ManualRouting: true,
+				TLS:           &types.MetricsTLS{CertFile: "cert.pem", KeyFile: "key.pem", CAFiles: []string{"ca.pem"}},
+			},
+		},
+		{
+			desc: "with a certificate",
+			prom: &types.Prometheus{
+				EntryPoint: "metrics",
+				TLS:        &types.MetricsTLS{CertFile: "cert.pem", KeyFile: "key.pem"},
+			},
+			expected: &static.EntryPointTLS{
+				DefaultStore:         "prometheus@internal",
+				CertificateSelection: "preferECDSA",
+			},
+		},
+		{
+			desc: "with client certificates",
+			prom: &types.Prometheus{
+				EntryPoint: "metrics",
+				TLS:        &types.MetricsTLS{CertFile: "cert.pem", KeyFile: "key.pem", CAFiles: []string{"ca.pem"}},
+			},
+			expected: &static.EntryPointTLS{
+				DefaultStore:         "prometheus@internal",
+				Options:              "prometheus@internal",
+				CertificateSelection: "preferECDSA",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			staticConfig := static.Configuration{
+				EntryPoints: map[string]*static.EntryPoint{
+					"metrics": {TLS: &static.EntryPointTLS{CertificateSelection: "preferECDSA"}},
+				},
+				Metrics: &types.Metrics{Prometheus: test.prom},
+			}
+
+			factory := NewRouterFactory(staticConfig, nil, nil, nil, nil, metrics.NewVoidRegistry())
+
+			expected := test.expected
+			if expected == nil {
+				expected = staticConfig.EntryPoints["metrics"].TLS
+			}
+			assert.Equal(t, expected, factory.entryPointsTLS["metrics"])
+		})
+	}
+}
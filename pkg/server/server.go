@@ -25,6 +25,11 @@ type Server struct {
 	signals  chan os.Signal
 	stopChan chan bool
 
+	// firstSync is closed once the providers the entry points wait for have delivered their first configuration.
+	firstSync          chan struct{}
+	firstSyncTimeout   time.Duration
+	entryPointsStarted func()
+
 	routinesPool *safe.Pool
 }
 
@@ -57,11 +62,57 @@ func (s *Server) Start(ctx context.Context) {
 		s.Stop()
 	}()
 
+	if s.firstSync == nil {
+		s.tcpEntryPoints.Start()
+		s.udpEntryPoints.Start()
+		s.watcher.Start()
+	} else {
+		s.watcher.Start()
+		s.routinesPool.GoCtx(s.startEntryPointsAfterFirstSync)
+	}
+
+	s.routinesPool.GoCtx(s.listenSignals)
+}
+
+// WaitForFirstSync delays the start of the entry points until all the given providers have delivered their first configuration,
+// or until the timeout expires. The started function, if any, is called once the entry points are started.
+// It must be called before Start.
+func (s *Server) WaitForFirstSync(providerNames []string, timeout time.Duration, started func()) {
+	s.firstSync = make(chan struct{})
+	s.firstSyncTimeout = timeout
+	s.entryPointsStarted = started
+
+	s.watcher.AddSyncListener(providerNames, func() {
+		close(s.firstSync)
+	})
+}
+
+func (s *Server) startEntryPointsAfterFirstSync(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	logger.Info("Waiting for the first configuration of the providers before starting the entry points")
+
+	var timeout <-chan time.Time
+	if s.firstSyncTimeout > 0 {
+		timer := time.NewTimer(s.firstSyncTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-s.firstSync:
+		logger.Info("The providers have delivered their first configuration, starting the entry points")
+	case <-timeout:
+		logger.Warnf("The providers have not delivered their first configuration after %s, starting the entry points anyway", s.firstSyncTimeout)
+	}
+
 	s.tcpEntryPoints.Start()
 	s.udpEntryPoints.Start()
-	s.watcher.Start()
 
-	s.routinesPool.GoCtx(s.listenSignals)
+	if s.entryPointsStarted != nil {
+		s.entryPointsStarted()
+	}
 }
 
 // Wait blocks until the server shutdown.
@@ -110,4 +161,6 @@ func stopMetricsClients() {
 	metrics.StopDatadog()
 	metrics.StopStatsd()
 	metrics.StopInfluxDB()
+	metrics.StopGraphite()
+	metrics.StopPrometheusPush()
 }
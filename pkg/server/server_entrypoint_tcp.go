@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	stdlog "log"
 	"net"
@@ -12,9 +14,15 @@ import (
 	proxyprotocol "github.com/c0va23/go-proxyprotocol"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/ipset"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/middlewares/forwardedheaders"
+	"github.com/containous/traefik/v2/pkg/middlewares/forwardproxy"
+	"github.com/containous/traefik/v2/pkg/middlewares/normalization"
+	"github.com/containous/traefik/v2/pkg/middlewares/privacy"
+	"github.com/containous/traefik/v2/pkg/middlewares/qos"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/server/router"
 	"github.com/containous/traefik/v2/pkg/tcp"
@@ -58,7 +66,7 @@ func (h *httpForwarder) Accept() (net.Conn, error) {
 type TCPEntryPoints map[string]*TCPEntryPoint
 
 // NewTCPEntryPoints creates a new TCPEntryPoints.
-func NewTCPEntryPoints(entryPointsConfig static.EntryPoints) (TCPEntryPoints, error) {
+func NewTCPEntryPoints(entryPointsConfig static.EntryPoints, metricsRegistry metrics.Registry, ipSets *ipset.Manager) (TCPEntryPoints, error) {
 	serverEntryPointsTCP := make(TCPEntryPoints)
 	for entryPointName, config := range entryPointsConfig {
 		protocol, err := config.GetProtocol()
@@ -72,7 +80,7 @@ func NewTCPEntryPoints(entryPointsConfig static.EntryPoints) (TCPEntryPoints, er
 
 		ctx := log.With(context.Background(), log.Str(log.EntryPointName, entryPointName))
 
-		serverEntryPointsTCP[entryPointName], err = NewTCPEntryPoint(ctx, config)
+		serverEntryPointsTCP[entryPointName], err = NewTCPEntryPoint(ctx, entryPointName, config, metricsRegistry, ipSets)
 		if err != nil {
 			return nil, fmt.Errorf("error while building entryPoint %s: %w", entryPointName, err)
 		}
@@ -123,12 +131,19 @@ type TCPEntryPoint struct {
 	tracker                *connectionTracker
 	httpServer             *httpServer
 	httpsServer            *httpServer
+	canary                 *canary
 }
 
 // NewTCPEntryPoint creates a new TCPEntryPoint.
-func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*TCPEntryPoint, error) {
+// The trusted IP sets of the forwarded headers are referenced in ipSets.
+func NewTCPEntryPoint(ctx context.Context, name string, configuration *static.EntryPoint, metricsRegistry metrics.Registry, ipSets *ipset.Manager) (*TCPEntryPoint, error) {
 	tracker := newConnectionTracker()
 
+	trustedSets, err := getTrustedIPSets(configuration.ForwardedHeaders, ipSets)
+	if err != nil {
+		return nil, err
+	}
+
 	listener, err := buildListener(ctx, configuration)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing server: %w", err)
@@ -136,14 +151,27 @@ func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*T
 
 	router := &tcp.Router{}
 
-	httpServer, err := createHTTPServer(ctx, listener, configuration, true)
+	var forwardProxy http.Handler
+	if configuration.ForwardProxy != nil {
+		forwardProxy, err = forwardproxy.New(ctx, configuration.ForwardProxy)
+		if err != nil {
+			return nil, fmt.Errorf("error preparing forward proxy: %w", err)
+		}
+	}
+
+	guard, err := newHTTP2Guard(ctx, configuration.HTTP2, metricsRegistry.EntryPointHTTP2AbusesCounter().With("entrypoint", name))
+	if err != nil {
+		return nil, fmt.Errorf("error preparing HTTP/2 guard: %w", err)
+	}
+
+	httpServer, err := createHTTPServer(ctx, listener, configuration, trustedSets, forwardProxy, guard, true)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing httpServer: %w", err)
 	}
 
 	router.HTTPForwarder(httpServer.Forwarder)
 
-	httpsServer, err := createHTTPServer(ctx, listener, configuration, false)
+	httpsServer, err := createHTTPServer(ctx, listener, configuration, trustedSets, forwardProxy, guard, false)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing httpsServer: %w", err)
 	}
@@ -153,6 +181,14 @@ func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*T
 	tcpSwitcher := &tcp.HandlerSwitcher{}
 	tcpSwitcher.Switch(router)
 
+	var entryPointCanary *canary
+	if configuration.Canary != nil {
+		entryPointCanary, err = newCanary(configuration.Canary)
+		if err != nil {
+			return nil, fmt.Errorf("error preparing canary: %w", err)
+		}
+	}
+
 	return &TCPEntryPoint{
 		listener:               listener,
 		switcher:               tcpSwitcher,
@@ -160,6 +196,7 @@ func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*T
 		tracker:                tracker,
 		httpServer:             httpServer,
 		httpsServer:            httpsServer,
+		canary:                 entryPointCanary,
 	}, nil
 }
 
@@ -185,29 +222,45 @@ func (e *TCPEntryPoint) Start(ctx context.Context) {
 			panic(err)
 		}
 
+		if e.canary != nil && e.canary.elect() {
+			safe.Go(func() {
+				// The forwarded connections get the same deadlines as the ones handled by the entry point,
+				// as the proxy to the canary does not set any.
+				e.setDeadlines(logger, writeCloser)
+
+				e.canary.ServeTCP(newTrackedConnection(writeCloser, e.tracker))
+			})
+			continue
+		}
+
 		safe.Go(func() {
 			// Enforce read/write deadlines at the connection level,
 			// because when we're peeking the first byte to determine whether we are doing TLS,
 			// the deadlines at the server level are not taken into account.
-			if e.transportConfiguration.RespondingTimeouts.ReadTimeout > 0 {
-				err := writeCloser.SetReadDeadline(time.Now().Add(time.Duration(e.transportConfiguration.RespondingTimeouts.ReadTimeout)))
-				if err != nil {
-					logger.Errorf("Error while setting read deadline: %v", err)
-				}
-			}
-
-			if e.transportConfiguration.RespondingTimeouts.WriteTimeout > 0 {
-				err = writeCloser.SetWriteDeadline(time.Now().Add(time.Duration(e.transportConfiguration.RespondingTimeouts.WriteTimeout)))
-				if err != nil {
-					logger.Errorf("Error while setting write deadline: %v", err)
-				}
-			}
+			e.setDeadlines(logger, writeCloser)
 
 			e.switcher.ServeTCP(newTrackedConnection(writeCloser, e.tracker))
 		})
 	}
 }
 
+// setDeadlines sets the read and write deadlines of the connection, from the responding timeouts of the entry point.
+func (e *TCPEntryPoint) setDeadlines(logger log.Logger, conn net.Conn) {
+	if e.transportConfiguration.RespondingTimeouts.ReadTimeout > 0 {
+		err := conn.SetReadDeadline(time.Now().Add(time.Duration(e.transportConfiguration.RespondingTimeouts.ReadTimeout)))
+		if err != nil {
+			logger.Errorf("Error while setting read deadline: %v", err)
+		}
+	}
+
+	if e.transportConfiguration.RespondingTimeouts.WriteTimeout > 0 {
+		err := conn.SetWriteDeadline(time.Now().Add(time.Duration(e.transportConfiguration.RespondingTimeouts.WriteTimeout)))
+		if err != nil {
+			logger.Errorf("Error while setting write deadline: %v", err)
+		}
+	}
+}
+
 // Shutdown stops the TCP connections.
 func (e *TCPEntryPoint) Shutdown(ctx context.Context) {
 	logger := log.FromContext(ctx)
@@ -324,9 +377,10 @@ func writeCloser(conn net.Conn) (tcp.WriteCloser, error) {
 }
 
 // tcpKeepAliveListener sets TCP keep-alive timeouts on accepted
-// connections.
+// connections, and marks them with the DSCP value of the entry point, if any.
 type tcpKeepAliveListener struct {
 	*net.TCPListener
+	dscp int
 }
 
 func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
@@ -343,6 +397,12 @@ func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
 		return nil, err
 	}
 
+	if ln.dscp > 0 {
+		if err = qos.SetDSCP(tc, ln.dscp); err != nil && !errors.Is(err, qos.ErrNotSupported) {
+			log.WithoutContext().Debugf("Unable to mark the connection from %s: %v", tc.RemoteAddr(), err)
+		}
+	}
+
 	return tc, nil
 }
 
@@ -385,12 +445,26 @@ func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoi
 }
 
 func buildListener(ctx context.Context, entryPoint *static.EntryPoint) (net.Listener, error) {
-	listener, err := net.Listen("tcp", entryPoint.GetAddress())
+	listenConfig := net.ListenConfig{}
+	if entryPoint.ReusePort {
+		listenConfig.Control = reusePortControl
+	}
+
+	var dscp int
+	if entryPoint.DSCP != "" {
+		var err error
+		dscp, err = qos.ParseDSCP(entryPoint.DSCP)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := listenConfig.Listen(ctx, "tcp", entryPoint.GetAddress())
 	if err != nil {
 		return nil, fmt.Errorf("error opening listener: %w", err)
 	}
 
-	listener = tcpKeepAliveListener{listener.(*net.TCPListener)}
+	listener = tcpKeepAliveListener{TCPListener: listener.(*net.TCPListener), dscp: dscp}
 
 	if entryPoint.ProxyProtocol != nil {
 		listener, err = buildProxyProtocolListener(ctx, entryPoint, listener)
@@ -472,22 +546,68 @@ type httpServer struct {
 	Switcher  *middlewares.HTTPHandlerSwitcher
 }
 
-func createHTTPServer(ctx context.Context, ln net.Listener, configuration *static.EntryPoint, withH2c bool) (*httpServer, error) {
+// getTrustedIPSets returns the IP sets trusted by the forwarded headers,
+// which are empty until the dynamic configuration defines them.
+func getTrustedIPSets(config *static.ForwardedHeaders, ipSets *ipset.Manager) ([]*ip.Set, error) {
+	if config == nil || len(config.TrustedIPSets) == 0 {
+		return nil, nil
+	}
+
+	if ipSets == nil {
+		return nil, errors.New("the trusted IP sets of the forwarded headers are not available")
+	}
+
+	sets := make([]*ip.Set, 0, len(config.TrustedIPSets))
+	for _, name := range config.TrustedIPSets {
+		sets = append(sets, ipSets.Reference(name))
+	}
+
+	return sets, nil
+}
+
+func createHTTPServer(ctx context.Context, ln net.Listener, configuration *static.EntryPoint, trustedSets []*ip.Set, forwardProxy http.Handler, guard *http2Guard, withH2c bool) (*httpServer, error) {
 	httpSwitcher := middlewares.NewHandlerSwitcher(router.BuildDefaultHTTPRouter())
 
-	var handler http.Handler
+	var handler http.Handler = httpSwitcher
+	if configuration.Privacy != nil {
+		anonymizer, err := ip.NewAnonymizer(configuration.Privacy.Mode, configuration.Privacy.Key, time.Duration(configuration.Privacy.KeyRotation))
+		if err != nil {
+			return nil, err
+		}
+
+		// The forwarded headers are trusted or removed according to the actual client IP, before the anonymization.
+		handler = privacy.New(anonymizer, handler)
+	}
+
 	var err error
 	handler, err = forwardedheaders.NewXForwarded(
 		configuration.ForwardedHeaders.Insecure,
 		configuration.ForwardedHeaders.TrustedIPs,
-		httpSwitcher)
+		trustedSets,
+		handler)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if configuration.Normalization != nil {
+		handler, err = normalization.New(ctx, configuration.Normalization, handler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if forwardProxy != nil {
+		handler = withForwardProxy(forwardProxy, handler)
+	}
+
+	h2Server, err := newHTTP2Server(configuration.HTTP2)
+	if err != nil {
+		return nil, err
+	}
+
 	if withH2c {
-		handler = h2c.NewHandler(handler, &http2.Server{})
+		handler = h2c.NewHandler(handler, h2Server)
 	}
 
 	serverHTTP := &http.Server{
@@ -498,9 +618,33 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		IdleTimeout:  time.Duration(configuration.Transport.RespondingTimeouts.IdleTimeout),
 	}
 
+	// The HTTP/2 server is configured on a server only used to start its graceful shutdown,
+	// which sends the final GOAWAY frame, once the guard warned the clients.
+	h2Shutdown := &http.Server{ReadTimeout: serverHTTP.ReadTimeout, IdleTimeout: serverHTTP.IdleTimeout}
+	err = http2.ConfigureServer(h2Shutdown, h2Server)
+	if err != nil {
+		return nil, fmt.Errorf("configure HTTP/2 server: %w", err)
+	}
+
+	serverHTTP.RegisterOnShutdown(func() {
+		guard.goAway()
+		_ = h2Shutdown.Shutdown(context.Background())
+	})
+
+	// The TLS connections are guarded once HTTP/2 is negotiated, to keep serving them as TLS connections.
+	serverHTTP.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){
+		http2.NextProtoTLS: guard.serveTLS(h2Server),
+	}
+
 	listener := newHTTPForwarder(ln)
+
+	var served net.Listener = listener
+	if withH2c {
+		served = guardedListener{Listener: listener, guard: guard}
+	}
+
 	go func() {
-		err := serverHTTP.Serve(listener)
+		err := serverHTTP.Serve(served)
 		if err != nil {
 			log.FromContext(ctx).Errorf("Error while starting server: %v", err)
 		}
@@ -512,6 +656,47 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 	}, nil
 }
 
+// newHTTP2Server creates the HTTP/2 server of the entry point configuration.
+func newHTTP2Server(config *static.HTTP2Config) (*http2.Server, error) {
+	if config == nil {
+		config = &static.HTTP2Config{}
+		config.SetDefaults()
+	}
+
+	if config.MaxConcurrentStreams < 0 {
+		return nil, errors.New("the maximal number of concurrent HTTP/2 streams is negative")
+	}
+
+	// https://tools.ietf.org/html/rfc7540#section-6.5.2
+	if config.MaxReadFrameSize != 0 && (config.MaxReadFrameSize < 1<<14 || config.MaxReadFrameSize > 1<<24-1) {
+		return nil, fmt.Errorf("the maximal HTTP/2 frame size must be between %d and %d", 1<<14, 1<<24-1)
+	}
+
+	// https://tools.ietf.org/html/rfc7540#section-6.9.2
+	if config.InitialStreamWindowSize < 0 || config.InitialConnectionWindowSize < 0 {
+		return nil, errors.New("the initial HTTP/2 window sizes are negative")
+	}
+
+	return &http2.Server{
+		MaxConcurrentStreams:         uint32(config.MaxConcurrentStreams),
+		MaxReadFrameSize:             uint32(config.MaxReadFrameSize),
+		MaxUploadBufferPerStream:     config.InitialStreamWindowSize,
+		MaxUploadBufferPerConnection: config.InitialConnectionWindowSize,
+	}, nil
+}
+
+// withForwardProxy sends the CONNECT requests to the forward proxy, and the other requests to the routers.
+func withForwardProxy(forwardProxy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if forwardproxy.IsTunnelRequest(req) {
+			forwardProxy.ServeHTTP(rw, req)
+			return
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
 func newTrackedConnection(conn tcp.WriteCloser, tracker *connectionTracker) *trackedConnection {
 	tracker.AddConnection(conn)
 	return &trackedConnection{
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/tcp"
+)
+
+// canaryTerminationDelay is the delay after which a forwarded connection is closed once one side closed it,
+// as the default one of the TCP services.
+const canaryTerminationDelay = 100 * time.Millisecond
+
+// canary forwards a percentage of the connections accepted by an entry point to another address,
+// e.g. a Traefik instance of another version listening on another port, before any routing.
+type canary struct {
+	proxy         *tcp.Proxy
+	weight        int
+	proxyProtocol bool
+}
+
+func newCanary(config *static.Canary) (*canary, error) {
+	if config.Address == "" {
+		return nil, errors.New("the address is required")
+	}
+
+	if config.Weight < 0 || config.Weight > 100 {
+		return nil, fmt.Errorf("invalid weight %d, a percentage between 0 and 100 is expected", config.Weight)
+	}
+
+	proxy, err := tcp.NewProxy(config.Address, canaryTerminationDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	return &canary{proxy: proxy, weight: config.Weight, proxyProtocol: config.ProxyProtocol}, nil
+}
+
+// elect reports whether an accepted connection is forwarded.
+func (c *canary) elect() bool {
+	return rand.Intn(100) < c.weight
+}
+
+// ServeTCP forwards the connection.
+func (c *canary) ServeTCP(conn tcp.WriteCloser) {
+	if c.proxyProtocol {
+		conn = &proxyProtocolConn{WriteCloser: conn, header: bytes.NewReader(proxyProtocolHeader(conn))}
+	}
+
+	c.proxy.ServeTCP(conn)
+}
+
+// proxyProtocolConn reads the PROXY protocol header before the data of the connection,
+// so that the header is forwarded first.
+type proxyProtocolConn struct {
+	tcp.WriteCloser
+	header io.Reader
+}
+
+func (c *proxyProtocolConn) Read(p []byte) (int, error) {
+	n, err := c.header.Read(p)
+	if errors.Is(err, io.EOF) {
+		return c.WriteCloser.Read(p)
+	}
+	return n, err
+}
+
+// proxyProtocolHeader returns the header of the version 1 of the PROXY protocol, telling the addresses of the connection.
+func proxyProtocolHeader(conn net.Conn) []byte {
+	srcIP, srcPort, errSrc := net.SplitHostPort(conn.RemoteAddr().String())
+	dstIP, dstPort, errDst := net.SplitHostPort(conn.LocalAddr().String())
+	if errSrc != nil || errDst != nil {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	src, dst := net.ParseIP(srcIP), net.ParseIP(dstIP)
+
+	switch {
+	case src == nil || dst == nil:
+		return []byte("PROXY UNKNOWN\r\n")
+	case src.To4() != nil && dst.To4() != nil:
+		return []byte(fmt.Sprintf("PROXY TCP4 %s %s %s %s\r\n", src, dst, srcPort, dstPort))
+	default:
+		return []byte(fmt.Sprintf("PROXY TCP6 %s %s %s %s\r\n", src, dst, srcPort, dstPort))
+	}
+}
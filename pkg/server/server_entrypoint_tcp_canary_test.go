@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestCanary(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		proxyProtocol  bool
+		expectedHeader bool
+	}{
+		{
+			desc: "without PROXY protocol",
+		},
+		{
+			desc:           "with PROXY protocol",
+			proxyProtocol:  true,
+			expectedHeader: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = backend.Close() })
+
+			received := make(chan string, 1)
+			go func() {
+				conn, err := backend.Accept()
+				if err != nil {
+					return
+				}
+				defer func() { _ = conn.Close() }()
+
+				data, _ := ioutil.ReadAll(conn)
+				received <- string(data)
+			}()
+
+			c, err := newCanary(&static.Canary{Address: backend.Addr().String(), Weight: 100, ProxyProtocol: test.proxyProtocol})
+			require.NoError(t, err)
+			assert.True(t, c.elect())
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = listener.Close() })
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				c.ServeTCP(conn.(*net.TCPConn))
+			}()
+
+			client, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+
+			_, err = client.Write([]byte("hello"))
+			require.NoError(t, err)
+			require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+			expected := "hello"
+			if test.expectedHeader {
+				clientAddr := client.LocalAddr().(*net.TCPAddr)
+				listenerAddr := listener.Addr().(*net.TCPAddr)
+				expected = fmt.Sprintf("PROXY TCP4 127.0.0.1 127.0.0.1 %d %d\r\nhello", clientAddr.Port, listenerAddr.Port)
+			}
+
+			assert.Equal(t, expected, <-received)
+			_ = client.Close()
+		})
+	}
+}
+
+func TestCanaryReadTimeout(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backend.Close() })
+
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		_, _ = io.Copy(ioutil.Discard, conn)
+	}()
+
+	epConfig := &static.EntryPointsTransport{}
+	epConfig.SetDefaults()
+	epConfig.RespondingTimeouts.ReadTimeout = ptypes.Duration(500 * time.Millisecond)
+
+	entryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		Canary:           &static.Canary{Address: backend.Addr().String(), Weight: 100},
+	}, metrics.NewVoidRegistry(), nil)
+	require.NoError(t, err)
+
+	conn, err := startEntrypoint(entryPoint, &tcp.Router{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// The idle connection forwarded to the canary is closed after the read timeout of the entry point.
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		assert.Equal(t, io.EOF, err)
+	case <-time.After(5 * time.Second):
+		t.Error("The connection forwarded to the canary was not closed")
+	}
+}
+
+func TestNewCanary(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config static.Canary
+	}{
+		{
+			desc:   "no address",
+			config: static.Canary{Weight: 10},
+		},
+		{
+			desc:   "negative weight",
+			config: static.Canary{Address: "127.0.0.1:8081", Weight: -1},
+		},
+		{
+			desc:   "weight above 100",
+			config: static.Canary{Address: "127.0.0.1:8081", Weight: 101},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newCanary(&test.config)
+			assert.Error(t, err)
+		})
+	}
+
+	c, err := newCanary(&static.Canary{Address: "127.0.0.1:8081"})
+	require.NoError(t, err)
+	assert.False(t, c.elect())
+}
@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePortControl fails, as SO_REUSEPORT is not available on Windows.
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return errors.New("reusePort is not supported on Windows")
+}
@@ -12,10 +12,12 @@ import (
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/tcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/traefik/paerser/types"
+	"golang.org/x/net/http2"
 )
 
 func TestShutdownHijacked(t *testing.T) {
@@ -67,13 +69,13 @@ func testShutdown(t *testing.T, router *tcp.Router) {
 	epConfig.LifeCycle.RequestAcceptGraceTimeout = 0
 	epConfig.LifeCycle.GraceTimeOut = ptypes.Duration(5 * time.Second)
 
-	entryPoint, err := NewTCPEntryPoint(context.Background(), &static.EntryPoint{
+	entryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
 		// We explicitly use an IPV4 address because on Alpine, with an IPV6 address
 		// there seems to be shenanigans related to properly cleaning up file descriptors
 		Address:          "127.0.0.1:0",
 		Transport:        epConfig,
 		ForwardedHeaders: &static.ForwardedHeaders{},
-	})
+	}, metrics.NewVoidRegistry(), nil)
 	require.NoError(t, err)
 
 	conn, err := startEntrypoint(entryPoint, router)
@@ -152,11 +154,11 @@ func TestReadTimeoutWithoutFirstByte(t *testing.T) {
 	epConfig.SetDefaults()
 	epConfig.RespondingTimeouts.ReadTimeout = ptypes.Duration(2 * time.Second)
 
-	entryPoint, err := NewTCPEntryPoint(context.Background(), &static.EntryPoint{
+	entryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
 		Address:          ":0",
 		Transport:        epConfig,
 		ForwardedHeaders: &static.ForwardedHeaders{},
-	})
+	}, metrics.NewVoidRegistry(), nil)
 	require.NoError(t, err)
 
 	router := &tcp.Router{}
@@ -188,11 +190,11 @@ func TestReadTimeoutWithFirstByte(t *testing.T) {
 	epConfig.SetDefaults()
 	epConfig.RespondingTimeouts.ReadTimeout = ptypes.Duration(2 * time.Second)
 
-	entryPoint, err := NewTCPEntryPoint(context.Background(), &static.EntryPoint{
+	entryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
 		Address:          ":0",
 		Transport:        epConfig,
 		ForwardedHeaders: &static.ForwardedHeaders{},
-	})
+	}, metrics.NewVoidRegistry(), nil)
 	require.NoError(t, err)
 
 	router := &tcp.Router{}
@@ -221,3 +223,149 @@ func TestReadTimeoutWithFirstByte(t *testing.T) {
 		t.Error("Timeout while read")
 	}
 }
+
+func TestReusePort(t *testing.T) {
+	epConfig := &static.EntryPointsTransport{}
+	epConfig.SetDefaults()
+
+	entryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		ReusePort:        true,
+	}, metrics.NewVoidRegistry(), nil)
+	require.NoError(t, err)
+	defer func() { _ = entryPoint.listener.Close() }()
+
+	// Binding the same address fails without reusePort.
+	_, err = NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
+		Address:          entryPoint.listener.Addr().String(),
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+	}, metrics.NewVoidRegistry(), nil)
+	require.Error(t, err)
+
+	sharedEntryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
+		Address:          entryPoint.listener.Addr().String(),
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		ReusePort:        true,
+	}, metrics.NewVoidRegistry(), nil)
+	require.NoError(t, err)
+	defer func() { _ = sharedEntryPoint.listener.Close() }()
+}
+
+func TestNewHTTP2Server(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        *static.HTTP2Config
+		expected      *http2.Server
+		expectedError bool
+	}{
+		{
+			desc: "defaults",
+			expected: &http2.Server{
+				MaxConcurrentStreams:         250,
+				MaxReadFrameSize:             1 << 20,
+				MaxUploadBufferPerStream:     1 << 20,
+				MaxUploadBufferPerConnection: 1 << 20,
+			},
+		},
+		{
+			desc: "tuned for gRPC",
+			config: &static.HTTP2Config{
+				MaxConcurrentStreams:        1000,
+				MaxReadFrameSize:            1 << 14,
+				InitialStreamWindowSize:     4 << 20,
+				InitialConnectionWindowSize: 16 << 20,
+			},
+			expected: &http2.Server{
+				MaxConcurrentStreams:         1000,
+				MaxReadFrameSize:             1 << 14,
+				MaxUploadBufferPerStream:     4 << 20,
+				MaxUploadBufferPerConnection: 16 << 20,
+			},
+		},
+		{
+			desc:          "frame size too small",
+			config:        &static.HTTP2Config{MaxReadFrameSize: 1024},
+			expectedError: true,
+		},
+		{
+			desc:          "negative window size",
+			config:        &static.HTTP2Config{InitialStreamWindowSize: -1},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server, err := newHTTP2Server(test.config)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, server)
+		})
+	}
+}
+
+func TestShutdownHTTP2(t *testing.T) {
+	epConfig := &static.EntryPointsTransport{}
+	epConfig.SetDefaults()
+	epConfig.LifeCycle.RequestAcceptGraceTimeout = 0
+	epConfig.LifeCycle.GraceTimeOut = ptypes.Duration(5 * time.Second)
+
+	entryPoint, err := NewTCPEntryPoint(context.Background(), "", &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+	}, metrics.NewVoidRegistry(), nil)
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	router := &tcp.Router{}
+	router.HTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-unblock
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	conn, err := startEntrypoint(entryPoint, router)
+	require.NoError(t, err)
+
+	// The connection sends the HTTP/2 requests with prior knowledge (h2c).
+	clientConn, err := (&http2.Transport{AllowHTTP: true}).NewClientConn(conn)
+	require.NoError(t, err)
+
+	respChan := make(chan *http.Response, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/", nil)
+		resp, err := clientConn.RoundTrip(req)
+		if err == nil {
+			respChan <- resp
+		}
+		close(respChan)
+	}()
+
+	<-started
+
+	go entryPoint.Shutdown(context.Background())
+
+	// The GOAWAY frame stops the new streams on the connection, while the ongoing one completes.
+	assert.Eventually(t, func() bool {
+		return !clientConn.CanTakeNewRequest()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(unblock)
+
+	resp, ok := <-respChan
+	require.True(t, ok)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -9,14 +9,16 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/udp"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 )
 
 // UDPEntryPoints maps UDP entry points by their names.
 type UDPEntryPoints map[string]*UDPEntryPoint
 
 // NewUDPEntryPoints returns all the UDP entry points, keyed by name.
-func NewUDPEntryPoints(cfg static.EntryPoints) (UDPEntryPoints, error) {
+func NewUDPEntryPoints(cfg static.EntryPoints, metricsRegistry metrics.Registry) (UDPEntryPoints, error) {
 	entryPoints := make(UDPEntryPoints)
 	for entryPointName, entryPoint := range cfg {
 		protocol, err := entryPoint.GetProtocol()
@@ -28,7 +30,7 @@ func NewUDPEntryPoints(cfg static.EntryPoints) (UDPEntryPoints, error) {
 			continue
 		}
 
-		ep, err := NewUDPEntryPoint(entryPoint)
+		ep, err := NewUDPEntryPoint(entryPointName, entryPoint, metricsRegistry)
 		if err != nil {
 			return nil, fmt.Errorf("error while building entryPoint %s: %w", entryPointName, err)
 		}
@@ -84,12 +86,12 @@ type UDPEntryPoint struct {
 }
 
 // NewUDPEntryPoint returns a UDP entry point.
-func NewUDPEntryPoint(cfg *static.EntryPoint) (*UDPEntryPoint, error) {
+func NewUDPEntryPoint(name string, cfg *static.EntryPoint, metricsRegistry metrics.Registry) (*UDPEntryPoint, error) {
 	addr, err := net.ResolveUDPAddr("udp", cfg.GetAddress())
 	if err != nil {
 		return nil, err
 	}
-	listener, err := udp.Listen("udp", addr)
+	listener, err := udp.ListenWithObserver("udp", addr, newDatagramMetrics(name, metricsRegistry))
 	if err != nil {
 		return nil, err
 	}
@@ -133,3 +135,32 @@ func (ep *UDPEntryPoint) Shutdown(ctx context.Context) {
 func (ep *UDPEntryPoint) Switch(handler udp.Handler) {
 	ep.switcher.Switch(handler)
 }
+
+// datagramMetrics is a udp.DatagramObserver counting the datagrams of an entry point, labeled with its name.
+type datagramMetrics struct {
+	datagramsReceived gokitmetrics.Counter
+	datagramsSent     gokitmetrics.Counter
+	bytesReceived     gokitmetrics.Counter
+	bytesSent         gokitmetrics.Counter
+}
+
+func newDatagramMetrics(entryPointName string, metricsRegistry metrics.Registry) *datagramMetrics {
+	return &datagramMetrics{
+		datagramsReceived: metricsRegistry.EntryPointUDPDatagramsCounter().With("direction", "received", "entrypoint", entryPointName),
+		datagramsSent:     metricsRegistry.EntryPointUDPDatagramsCounter().With("direction", "sent", "entrypoint", entryPointName),
+		bytesReceived:     metricsRegistry.EntryPointUDPBytesCounter().With("direction", "received", "entrypoint", entryPointName),
+		bytesSent:         metricsRegistry.EntryPointUDPBytesCounter().With("direction", "sent", "entrypoint", entryPointName),
+	}
+}
+
+// Received implements udp.DatagramObserver.
+func (m *datagramMetrics) Received(size int) {
+	m.datagramsReceived.Add(1)
+	m.bytesReceived.Add(float64(size))
+}
+
+// Sent implements udp.DatagramObserver.
+func (m *datagramMetrics) Sent(size int) {
+	m.datagramsSent.Add(1)
+	m.bytesSent.Add(float64(size))
+}
@@ -8,20 +8,21 @@ import (
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/udp"
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/traefik/paerser/types"
 )
 
 func TestShutdownUDPConn(t *testing.T) {
-	entryPoint, err := NewUDPEntryPoint(&static.EntryPoint{
+	entryPoint, err := NewUDPEntryPoint("udp", &static.EntryPoint{
 		Address: ":0",
 		Transport: &static.EntryPointsTransport{
 			LifeCycle: &static.LifeCycle{
 				GraceTimeOut: ptypes.Duration(5 * time.Second),
 			},
 		},
-	})
+	}, metrics.NewVoidRegistry())
 	require.NoError(t, err)
 
 	go entryPoint.Start(context.Background())
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/safe"
+)
+
+func TestServer_WaitForFirstSync(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		providers []string
+		timeout   time.Duration
+	}{
+		{
+			desc:      "first configuration delivered",
+			providers: []string{"mock"},
+		},
+		{
+			desc:      "first configuration never delivered",
+			providers: []string{"mock", "unavailable"},
+			timeout:   50 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			routinesPool := safe.NewPool(context.Background())
+			defer routinesPool.Stop()
+
+			pvd := &mockProvider{
+				messages: []dynamic.Message{{
+					ProviderName:  "mock",
+					Configuration: &dynamic.Configuration{},
+				}},
+			}
+
+			watcher := NewConfigurationWatcher(routinesPool, pvd, time.Millisecond, []string{})
+			srv := &Server{
+				watcher:        watcher,
+				tcpEntryPoints: TCPEntryPoints{},
+				udpEntryPoints: UDPEntryPoints{},
+				routinesPool:   routinesPool,
+			}
+
+			started := make(chan struct{})
+			srv.WaitForFirstSync(test.providers, test.timeout, func() {
+				close(started)
+			})
+
+			watcher.Start()
+			defer watcher.Stop()
+
+			routinesPool.GoCtx(srv.startEntryPointsAfterFirstSync)
+
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for the entry points to be started")
+			}
+		})
+	}
+}
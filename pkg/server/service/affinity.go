@@ -0,0 +1,96 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/containous/traefik/v2/pkg/healthcheck"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// affinitySticky identifies the clients of the sticky sessions based on the affinity table.
+type affinitySticky struct {
+	serviceName string
+	header      string
+	ttl         time.Duration
+	table       affinity.Table
+}
+
+// key returns the key of the client in the affinity table,
+// or an empty string when the request does not identify its client.
+func (s *affinitySticky) key(req *http.Request) string {
+	if s.header != "" {
+		value := req.Header.Get(s.header)
+		if value == "" {
+			return ""
+		}
+		return affinity.Key(s.serviceName, value)
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if host == "" {
+		return ""
+	}
+
+	return affinity.Key(s.serviceName, host)
+}
+
+// affinityRecorder records the server chosen for the client in the affinity table, before forwarding the request.
+// Recording it on each request extends the lifetime of the entry.
+type affinityRecorder struct {
+	next   http.Handler
+	sticky *affinitySticky
+}
+
+func (r *affinityRecorder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if key := r.sticky.key(req); key != "" {
+		if err := r.sticky.table.Set(key, req.URL.String(), r.sticky.ttl); err != nil {
+			log.FromContext(req.Context()).Debugf("Unable to record the server of the client in the affinity table: %v", err)
+		}
+	}
+
+	r.next.ServeHTTP(rw, req)
+}
+
+// affinityBalancer forwards the requests to the server recorded for their client in the affinity table,
+// as long as this server is still available.
+// The requests of the new clients are load-balanced.
+type affinityBalancer struct {
+	healthcheck.BalancerHandler
+	sticky   *affinitySticky
+	recorder http.Handler
+}
+
+func (b *affinityBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	key := b.sticky.key(req)
+	if key == "" {
+		b.BalancerHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	server, err := b.sticky.table.Get(key)
+	if err != nil {
+		log.FromContext(req.Context()).Debugf("Unable to get the server of the client from the affinity table: %v", err)
+	}
+
+	if server != "" {
+		for _, u := range b.Servers() {
+			if u.String() != server {
+				continue
+			}
+
+			// The request is copied, as done by the load-balancer.
+			newReq := *req
+			newReq.URL = u
+			b.recorder.ServeHTTP(rw, &newReq)
+			return
+		}
+	}
+
+	b.BalancerHandler.ServeHTTP(rw, req)
+}
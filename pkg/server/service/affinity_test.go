@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestAffinityBalancer(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-From", "first")
+	}))
+	t.Cleanup(server1.Close)
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-From", "second")
+	}))
+	t.Cleanup(server2.Close)
+
+	testCases := []struct {
+		desc      string
+		affinity  *dynamic.Affinity
+		newClient func(i int) *http.Request
+		// sticky tells whether the requests of the same client are expected to reach the same server.
+		sticky bool
+	}{
+		{
+			desc:     "header",
+			affinity: &dynamic.Affinity{Header: "X-Session", TTL: ptypes.Duration(time.Minute)},
+			newClient: func(i int) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "http://callme", nil)
+				req.Header.Set("X-Session", string(rune('a'+i)))
+				return req
+			},
+			sticky: true,
+		},
+		{
+			desc:     "client IP",
+			affinity: &dynamic.Affinity{},
+			newClient: func(i int) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "http://callme", nil)
+				req.RemoteAddr = "192.168.1." + string(rune('1'+i)) + ":1234"
+				return req
+			},
+			sticky: true,
+		},
+		{
+			desc:     "missing header",
+			affinity: &dynamic.Affinity{Header: "X-Session"},
+			newClient: func(i int) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "http://callme", nil)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The instances share the same table.
+			table := affinity.NewMemoryTable()
+
+			var handlers []http.Handler
+			for i := 0; i < 2; i++ {
+				manager := NewManager(nil, http.DefaultTransport, nil, nil)
+				manager.affinityTable = table
+
+				handler, err := manager.getLoadBalancerServiceHandler(context.Background(), "foo@file", &dynamic.ServersLoadBalancer{
+					Sticky:  &dynamic.Sticky{Affinity: test.affinity},
+					Servers: []dynamic.Server{{URL: server1.URL}, {URL: server2.URL}},
+				})
+				require.NoError(t, err)
+
+				handlers = append(handlers, handler)
+			}
+
+			for client := 0; client < 2; client++ {
+				var from []string
+				for i := 0; i < 4; i++ {
+					recorder := httptest.NewRecorder()
+					handlers[i%2].ServeHTTP(recorder, test.newClient(client))
+
+					assert.Equal(t, http.StatusOK, recorder.Code)
+					from = append(from, recorder.Header().Get("X-From"))
+				}
+
+				if test.sticky {
+					assert.Equal(t, []string{from[0], from[0], from[0], from[0]}, from)
+				} else {
+					assert.NotEqual(t, []string{from[0], from[0], from[0], from[0]}, from)
+				}
+			}
+		})
+	}
+}
+
+func TestAffinityBalancerUnavailableServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-From", "first")
+	}))
+	t.Cleanup(server.Close)
+
+	manager := NewManager(nil, http.DefaultTransport, nil, nil)
+
+	handler, err := manager.getLoadBalancerServiceHandler(context.Background(), "foo@file", &dynamic.ServersLoadBalancer{
+		Sticky:  &dynamic.Sticky{Affinity: &dynamic.Affinity{Header: "X-Session"}},
+		Servers: []dynamic.Server{{URL: server.URL}},
+	})
+	require.NoError(t, err)
+
+	// The recorded server is not a server of the service anymore.
+	require.NoError(t, manager.affinityTable.Set(affinity.Key("foo@file", "foo"), "http://10.0.0.1", time.Minute))
+
+	req := httptest.NewRequest(http.MethodGet, "http://callme", nil)
+	req.Header.Set("X-Session", "foo")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "first", recorder.Header().Get("X-From"))
+
+	recorded, err := manager.affinityTable.Get(affinity.Key("foo@file", "foo"))
+	require.NoError(t, err)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, u.String(), recorded)
+}
+
+func TestAffinityWithCookie(t *testing.T) {
+	manager := NewManager(nil, http.DefaultTransport, nil, nil)
+
+	_, err := manager.getLoadBalancerServiceHandler(context.Background(), "foo@file", &dynamic.ServersLoadBalancer{
+		Sticky:  &dynamic.Sticky{Cookie: &dynamic.Cookie{}, Affinity: &dynamic.Affinity{}},
+		Servers: []dynamic.Server{{URL: "http://10.0.0.1"}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToDevice returns the dialer control function binding the sockets to the network interface,
+// the VRF devices being interfaces as well.
+func bindToDevice(device string) (func(network, address string, c syscall.RawConn) error, error) {
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, device)
+		})
+		if err != nil {
+			return err
+		}
+
+		if sockErr != nil {
+			return fmt.Errorf("unable to bind to the interface %s: %w", device, sockErr)
+		}
+
+		return nil
+	}, nil
+}
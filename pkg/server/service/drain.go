@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/healthcheck"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// serverDrains tracks, across the configurations, the servers removed from the sticky load-balancers,
+// which keep serving the clients of their sessions until the end of the drain window of their service.
+type serverDrains struct {
+	mu sync.Mutex
+	// servers are the servers of each service in the last configuration.
+	servers map[string][]string
+	// deadlines are the ends of the drain windows of the drained servers of each service.
+	deadlines map[string]map[string]time.Time
+}
+
+func newServerDrains() *serverDrains {
+	return &serverDrains{
+		servers:   make(map[string][]string),
+		deadlines: make(map[string]map[string]time.Time),
+	}
+}
+
+// update records the servers of the services of a new configuration,
+// and starts draining the servers removed from the services with a drain window.
+func (d *serverDrains) update(services map[string]*runtime.ServiceInfo, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	servers := make(map[string][]string)
+	deadlines := make(map[string]map[string]time.Time)
+
+	for serviceName, service := range services {
+		if service.LoadBalancer == nil {
+			continue
+		}
+
+		current := make(map[string]bool)
+		for _, server := range service.LoadBalancer.Servers {
+			servers[serviceName] = append(servers[serviceName], server.URL)
+			current[server.URL] = true
+		}
+
+		window := drainWindow(service.LoadBalancer.Sticky)
+		if window <= 0 {
+			continue
+		}
+
+		drained := make(map[string]time.Time)
+
+		// The servers back in the configuration are not drained anymore.
+		for server, deadline := range d.deadlines[serviceName] {
+			if !current[server] && now.Before(deadline) {
+				drained[server] = deadline
+			}
+		}
+
+		for _, server := range d.servers[serviceName] {
+			if !current[server] {
+				drained[server] = now.Add(window)
+			}
+		}
+
+		if len(drained) > 0 {
+			deadlines[serviceName] = drained
+		}
+	}
+
+	d.servers = servers
+	d.deadlines = deadlines
+}
+
+// get returns the ends of the drain windows of the drained servers of the service.
+func (d *serverDrains) get(serviceName string) map[string]time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deadlines := make(map[string]time.Time, len(d.deadlines[serviceName]))
+	for server, deadline := range d.deadlines[serviceName] {
+		deadlines[server] = deadline
+	}
+
+	return deadlines
+}
+
+func drainWindow(sticky *dynamic.Sticky) time.Duration {
+	if sticky == nil || (sticky.Cookie == nil && sticky.Affinity == nil) {
+		return 0
+	}
+	return time.Duration(sticky.DrainWindow)
+}
+
+// drainingBalancer adds the drained servers to the load-balancer with a zero weight,
+// so that they only serve the clients of their sticky sessions, the new sessions being load-balanced on the other servers.
+// Once removed, by the health check or at the end of their drain window, the drained servers are never added back.
+type drainingBalancer struct {
+	healthcheck.BalancerHandler
+
+	mu sync.Mutex
+	// drained tells whether each drained server is still served.
+	drained map[string]bool
+}
+
+func newDrainingBalancer(ctx context.Context, lb healthcheck.BalancerHandler, deadlines map[string]time.Time) (*drainingBalancer, error) {
+	logger := log.FromContext(ctx)
+
+	balancer := &drainingBalancer{
+		BalancerHandler: lb,
+		drained:         make(map[string]bool),
+	}
+
+	for server, deadline := range deadlines {
+		u, err := url.Parse(server)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing server URL %s: %w", server, err)
+		}
+
+		logger.WithField(log.ServerName, server).Debugf("Draining server %s until %s", u, deadline.Format(time.RFC3339))
+
+		balancer.drained[server] = true
+		if err := balancer.upsertDrained(u); err != nil {
+			return nil, fmt.Errorf("error adding drained server %s to load balancer: %w", server, err)
+		}
+
+		time.AfterFunc(time.Until(deadline), func() {
+			if err := balancer.RemoveServer(u); err == nil {
+				logger.WithField(log.ServerName, u.String()).Debugf("End of the drain window, removing server %s", u)
+			}
+		})
+	}
+
+	return balancer, nil
+}
+
+// UpsertServer adds the server to the load-balancer, with a zero weight when it is drained.
+func (b *drainingBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	b.mu.Lock()
+	served, drained := b.drained[u.String()]
+	b.mu.Unlock()
+
+	if !drained {
+		return b.BalancerHandler.UpsertServer(u, options...)
+	}
+
+	if !served {
+		return fmt.Errorf("the drained server %s was removed", u)
+	}
+
+	return b.upsertDrained(u, options...)
+}
+
+// upsertDrained adds the drained server to the load-balancer with a zero weight.
+// The server is added before setting its weight, as the zero weight of a new server is replaced by the default one.
+func (b *drainingBalancer) upsertDrained(u *url.URL, options ...roundrobin.ServerOption) error {
+	if err := b.BalancerHandler.UpsertServer(u, options...); err != nil {
+		return err
+	}
+	return b.BalancerHandler.UpsertServer(u, roundrobin.Weight(0))
+}
+
+// RemoveServer removes the server from the load-balancer, for good when it is drained.
+func (b *drainingBalancer) RemoveServer(u *url.URL) error {
+	b.mu.Lock()
+	if _, drained := b.drained[u.String()]; drained {
+		b.drained[u.String()] = false
+	}
+	b.mu.Unlock()
+
+	return b.BalancerHandler.RemoveServer(u)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func TestServerDrains_update(t *testing.T) {
+	newServices := func(drainWindow time.Duration, urls ...string) map[string]*runtime.ServiceInfo {
+		lb := &dynamic.ServersLoadBalancer{
+			Sticky: &dynamic.Sticky{Cookie: &dynamic.Cookie{}, DrainWindow: ptypes.Duration(drainWindow)},
+		}
+		for _, u := range urls {
+			lb.Servers = append(lb.Servers, dynamic.Server{URL: u})
+		}
+		return map[string]*runtime.ServiceInfo{
+			"foo@file": {Service: &dynamic.Service{LoadBalancer: lb}},
+		}
+	}
+
+	now := time.Now()
+	drains := newServerDrains()
+
+	drains.update(newServices(time.Minute, "http://10.0.0.1", "http://10.0.0.2"), now)
+	assert.Empty(t, drains.get("foo@file"))
+
+	// The removed server is drained.
+	drains.update(newServices(time.Minute, "http://10.0.0.1"), now)
+	assert.Equal(t, map[string]time.Time{"http://10.0.0.2": now.Add(time.Minute)}, drains.get("foo@file"))
+
+	// The drain window is not extended by the next configurations.
+	drains.update(newServices(time.Minute, "http://10.0.0.1"), now.Add(30*time.Second))
+	assert.Equal(t, map[string]time.Time{"http://10.0.0.2": now.Add(time.Minute)}, drains.get("foo@file"))
+
+	// The drained server is forgotten at the end of its drain window.
+	drains.update(newServices(time.Minute, "http://10.0.0.1"), now.Add(2*time.Minute))
+	assert.Empty(t, drains.get("foo@file"))
+
+	// The server back in the configuration is not drained anymore.
+	drains.update(newServices(time.Minute, "http://10.0.0.3"), now)
+	assert.Equal(t, map[string]time.Time{"http://10.0.0.1": now.Add(time.Minute)}, drains.get("foo@file"))
+	drains.update(newServices(time.Minute, "http://10.0.0.1", "http://10.0.0.3"), now)
+	assert.Empty(t, drains.get("foo@file"))
+
+	// The servers are not drained without a drain window.
+	drains.update(newServices(0, "http://10.0.0.3"), now)
+	assert.Empty(t, drains.get("foo@file"))
+}
+
+func TestDrainingBalancer(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-From", "first")
+	}))
+	t.Cleanup(server1.Close)
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-From", "second")
+	}))
+	t.Cleanup(server2.Close)
+
+	manager := NewManager(nil, http.DefaultTransport, nil, nil)
+	manager.drains = newServerDrains()
+	manager.drains.deadlines["foo@file"] = map[string]time.Time{server2.URL: time.Now().Add(time.Minute)}
+
+	handler, err := manager.getLoadBalancerServiceHandler(context.Background(), "foo@file", &dynamic.ServersLoadBalancer{
+		Sticky:  &dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "session"}, DrainWindow: ptypes.Duration(time.Minute)},
+		Servers: []dynamic.Server{{URL: server1.URL}},
+	})
+	require.NoError(t, err)
+
+	// The new sessions are not load-balanced on the drained server.
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+		assert.Equal(t, "first", recorder.Header().Get("X-From"))
+	}
+
+	// The sessions of the drained server are still served.
+	req := httptest.NewRequest(http.MethodGet, "http://callme", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: server2.URL})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "second", recorder.Header().Get("X-From"))
+
+	// Once removed, e.g. by the health check, the drained server is never added back.
+	balancers := manager.balancers["foo@file"]
+	require.Len(t, balancers, 1)
+
+	u, err := url.Parse(server2.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, balancers[0].RemoveServer(u))
+	assert.Error(t, balancers[0].UpsertServer(u))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "first", recorder.Header().Get("X-From"))
+}
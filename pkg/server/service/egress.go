@@ -0,0 +1,357 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/circuitbreaker"
+	"github.com/containous/traefik/v2/pkg/middlewares/retry"
+	"github.com/sirupsen/logrus"
+)
+
+// egressGateway forwards the requests to the external destinations named by their Host header,
+// each destination having its own policy.
+// The requests to the other destinations are refused.
+type egressGateway struct {
+	serviceName  string
+	destinations []*egressDestination
+	auditLog     bool
+}
+
+type egressDestination struct {
+	host    string
+	handler http.Handler
+}
+
+func (m *Manager) getEgressServiceHandler(ctx context.Context, serviceName string, config *dynamic.Egress) (http.Handler, error) {
+	if len(config.Destinations) == 0 {
+		return nil, errors.New("at least one destination is required")
+	}
+
+	gateway := &egressGateway{
+		serviceName: serviceName,
+		auditLog:    config.AuditLog,
+	}
+
+	for i, destination := range config.Destinations {
+		handler, err := m.buildEgressDestination(ctx, serviceName, destination)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination %d: %w", i, err)
+		}
+
+		gateway.destinations = append(gateway.destinations, &egressDestination{
+			host:    strings.ToLower(destination.Host),
+			handler: handler,
+		})
+	}
+
+	return gateway, nil
+}
+
+func (m *Manager) buildEgressDestination(ctx context.Context, serviceName string, config dynamic.EgressDestination) (http.Handler, error) {
+	host := strings.ToLower(config.Host)
+	if host == "" || strings.Contains(host, ":") || (strings.Contains(host, "*") && !strings.HasPrefix(host, "*.")) {
+		return nil, fmt.Errorf("invalid host %q", config.Host)
+	}
+
+	scheme := config.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("invalid scheme %q", config.Scheme)
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		return nil, fmt.Errorf("invalid port %d", config.Port)
+	}
+
+	if len(config.PinnedPublicKeys) > 0 && scheme != "https" {
+		return nil, errors.New("the public keys can only be pinned with the https scheme")
+	}
+
+	roundTripper, err := m.egressTransports.get(config.PinnedPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	fwd, err := buildProxy(nil, nil, roundTripper, m.bufferPool)
+	if err != nil {
+		return nil, err
+	}
+
+	port := config.Port
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.URL.Scheme = scheme
+		req.URL.Host = requestHostName(req)
+		if port != 0 {
+			req.URL.Host = net.JoinHostPort(req.URL.Host, strconv.Itoa(port))
+		}
+
+		fwd.ServeHTTP(rw, req)
+	})
+
+	name := serviceName + "-" + host
+
+	var next http.Handler = handler
+	if config.Retry != nil {
+		next, err = retry.New(ctx, next, *config.Retry, nil, retry.Listeners{}, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The circuit breaker is applied once the retries are done, so it only sees the final responses.
+	if config.CircuitBreaker != nil {
+		next, err = circuitbreaker.New(ctx, next, *config.CircuitBreaker, nil, nil, name, serviceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return next, nil
+}
+
+func (g *egressGateway) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	host := strings.ToLower(requestHostName(req))
+
+	destination := g.match(host)
+	if destination == nil {
+		g.audit(req, host, http.StatusForbidden, 0)
+		log.FromContext(req.Context()).Debugf("Egress to %q refused: unknown destination", host)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if !g.auditLog {
+		destination.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+	destination.handler.ServeHTTP(recorder, req)
+	g.audit(req, host, recorder.status, time.Since(start))
+}
+
+// match returns the destination of the host.
+// The destinations with an exact host take precedence over the ones with a wildcard.
+func (g *egressGateway) match(host string) *egressDestination {
+	var wildcard *egressDestination
+	for _, destination := range g.destinations {
+		if destination.host == host {
+			return destination
+		}
+
+		if wildcard == nil && strings.HasPrefix(destination.host, "*.") && strings.HasSuffix(host, destination.host[1:]) {
+			wildcard = destination
+		}
+	}
+
+	return wildcard
+}
+
+func (g *egressGateway) audit(req *http.Request, host string, status int, duration time.Duration) {
+	if !g.auditLog {
+		return
+	}
+
+	log.FromContext(req.Context()).WithFields(logrus.Fields{
+		log.ServiceName:     g.serviceName,
+		"egressDestination": host,
+		"clientAddr":        req.RemoteAddr,
+		"method":            req.Method,
+		"path":              req.URL.Path,
+		"status":            status,
+		"duration":          duration.String(),
+	}).Info("Egress request")
+}
+
+// egressTransports holds, across the configurations, the round trippers of the egress destinations,
+// so that their connections are reused instead of being leaked at each configuration reload.
+// The destinations with the same pinned public keys share a round tripper.
+type egressTransports struct {
+	serversTransport *static.ServersTransport
+
+	mu            sync.Mutex
+	roundTrippers map[string]http.RoundTripper
+}
+
+func newEgressTransports(serversTransport *static.ServersTransport) *egressTransports {
+	if serversTransport == nil {
+		serversTransport = &static.ServersTransport{MaxIdleConnsPerHost: 200}
+	}
+
+	return &egressTransports{
+		serversTransport: serversTransport,
+		roundTrippers:    make(map[string]http.RoundTripper),
+	}
+}
+
+// get returns the round tripper of the destinations with the pinned public keys, creating it if needed.
+func (t *egressTransports) get(pinnedPublicKeys []string) (http.RoundTripper, error) {
+	key := pinnedPublicKeysKey(pinnedPublicKeys)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if roundTripper, ok := t.roundTrippers[key]; ok {
+		return roundTripper, nil
+	}
+
+	transport, err := createHTTPTransport(t.serversTransport, false, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pinnedPublicKeys) > 0 {
+		pins, err := parsePinnedPublicKeys(pinnedPublicKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+		tlsConfig.VerifyPeerCertificate = verifyPinnedPublicKeys(pins)
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	roundTripper, err := newSmartRoundTripper(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	t.roundTrippers[key] = roundTripper
+
+	return roundTripper, nil
+}
+
+// update removes the round trippers no longer used by the egress destinations of the new configuration,
+// and closes their idle connections.
+func (t *egressTransports) update(services map[string]*runtime.ServiceInfo) {
+	used := make(map[string]bool)
+	for _, service := range services {
+		if service.Egress == nil {
+			continue
+		}
+
+		for _, destination := range service.Egress.Destinations {
+			used[pinnedPublicKeysKey(destination.PinnedPublicKeys)] = true
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, roundTripper := range t.roundTrippers {
+		if used[key] {
+			continue
+		}
+
+		if closer, ok := roundTripper.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+		delete(t.roundTrippers, key)
+	}
+}
+
+// pinnedPublicKeysKey returns the key of the round tripper of the pinned public keys, whatever their order.
+func pinnedPublicKeysKey(pinnedPublicKeys []string) string {
+	keys := append([]string(nil), pinnedPublicKeys...)
+	sort.Strings(keys)
+
+	return strings.Join(keys, ",")
+}
+
+// requestHostName returns the host of the request, without its port.
+func requestHostName(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		return req.Host
+	}
+
+	return host
+}
+
+// parsePinnedPublicKeys parses the base64 encoded SHA-256 hashes of the pinned public keys.
+func parsePinnedPublicKeys(rawPins []string) (map[string]struct{}, error) {
+	pins := make(map[string]struct{})
+	for _, rawPin := range rawPins {
+		pin := strings.TrimPrefix(rawPin, "sha256/")
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid pinned public key %q: a base64 encoded SHA-256 hash is expected", rawPin)
+		}
+		pins[string(hash)] = struct{}{}
+	}
+
+	return pins, nil
+}
+
+// verifyPinnedPublicKeys verifies that the certificate chain sent by the server contains one of the pinned public keys.
+// It is done in addition to the verification of the chain itself.
+func verifyPinnedPublicKeys(pins map[string]struct{}) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		for _, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				return err
+			}
+
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if _, ok := pins[string(hash[:])]; ok {
+				return nil
+			}
+		}
+
+		return errors.New("none of the public keys of the certificate chain is pinned")
+	}
+}
+
+// statusRecorder records the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !middlewares.IsInformational(status) {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+
+	return hijacker.Hijack()
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
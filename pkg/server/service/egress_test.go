@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressGateway(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Host", req.Host)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	host, rawPort, err := net.SplitHostPort(backend.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(rawPort)
+	require.NoError(t, err)
+
+	hash := sha256.Sum256(backend.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+	otherPin := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	testCases := []struct {
+		desc           string
+		destination    dynamic.EgressDestination
+		host           string
+		expectedStatus int
+	}{
+		{
+			desc:           "pinned public key",
+			destination:    dynamic.EgressDestination{Host: host, Port: port, PinnedPublicKeys: []string{otherPin, pin}},
+			host:           host,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "public key not pinned",
+			destination:    dynamic.EgressDestination{Host: host, Port: port, PinnedPublicKeys: []string{otherPin}},
+			host:           host,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			desc:           "unknown destination",
+			destination:    dynamic.EgressDestination{Host: "api.example.com", Port: port},
+			host:           host,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "with retries and circuit breaker",
+			destination:    dynamic.EgressDestination{Host: host, Port: port, Retry: &dynamic.Retry{Attempts: 2}, CircuitBreaker: &dynamic.CircuitBreaker{Expression: "NetworkErrorRatio() > 0.5"}},
+			host:           host,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(nil, http.DefaultTransport, nil, nil)
+			manager.egressTransports = newEgressTransports(&static.ServersTransport{InsecureSkipVerify: true})
+
+			handler, err := manager.getEgressServiceHandler(context.Background(), "egress", &dynamic.Egress{
+				Destinations: []dynamic.EgressDestination{test.destination},
+				AuditLog:     true,
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://"+test.host+"/foo", nil)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.host, recorder.Header().Get("X-Host"))
+			}
+		})
+	}
+}
+
+func TestEgressGatewayMatch(t *testing.T) {
+	gateway := &egressGateway{
+		destinations: []*egressDestination{
+			{host: "*.example.com"},
+			{host: "api.example.com"},
+		},
+	}
+
+	assert.Equal(t, "api.example.com", gateway.match("api.example.com").host)
+	assert.Equal(t, "*.example.com", gateway.match("foo.example.com").host)
+	assert.Nil(t, gateway.match("example.com"))
+	assert.Nil(t, gateway.match("example.org"))
+}
+
+func TestEgressGatewayInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		destination dynamic.EgressDestination
+	}{
+		{
+			desc: "empty host",
+		},
+		{
+			desc:        "host with a port",
+			destination: dynamic.EgressDestination{Host: "api.example.com:443"},
+		},
+		{
+			desc:        "invalid wildcard",
+			destination: dynamic.EgressDestination{Host: "api.*.com"},
+		},
+		{
+			desc:        "invalid scheme",
+			destination: dynamic.EgressDestination{Host: "api.example.com", Scheme: "ftp"},
+		},
+		{
+			desc:        "pinning without TLS",
+			destination: dynamic.EgressDestination{Host: "api.example.com", Scheme: "http", PinnedPublicKeys: []string{"AAAA"}},
+		},
+		{
+			desc:        "invalid pinned public key",
+			destination: dynamic.EgressDestination{Host: "api.example.com", PinnedPublicKeys: []string{"foo"}},
+		},
+		{
+			desc:        "invalid retry",
+			destination: dynamic.EgressDestination{Host: "api.example.com", Retry: &dynamic.Retry{}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(nil, http.DefaultTransport, nil, nil)
+
+			_, err := manager.getEgressServiceHandler(context.Background(), "egress", &dynamic.Egress{
+				Destinations: []dynamic.EgressDestination{test.destination},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEgressTransports(t *testing.T) {
+	pinA := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+	pinB := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	transports := newEgressTransports(nil)
+
+	roundTripper, err := transports.get([]string{pinB, pinA})
+	require.NoError(t, err)
+
+	// The round trippers are shared by the destinations with the same pinned public keys, and across the configurations.
+	other, err := transports.get([]string{pinA, pinB})
+	require.NoError(t, err)
+	assert.Same(t, roundTripper, other)
+
+	defaultRoundTripper, err := transports.get(nil)
+	require.NoError(t, err)
+	assert.NotSame(t, roundTripper, defaultRoundTripper)
+
+	transports.update(map[string]*runtime.ServiceInfo{
+		"egress@file": {Service: &dynamic.Service{Egress: &dynamic.Egress{
+			Destinations: []dynamic.EgressDestination{{Host: "api.example.com"}},
+		}}},
+	})
+
+	other, err = transports.get(nil)
+	require.NoError(t, err)
+	assert.Same(t, defaultRoundTripper, other)
+
+	// The round trippers no longer used are removed.
+	other, err = transports.get([]string{pinA, pinB})
+	require.NoError(t, err)
+	assert.NotSame(t, roundTripper, other)
+}
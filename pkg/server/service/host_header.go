@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/vulcand/oxy/forward"
+)
+
+// Host header policies of the services.
+const (
+	// hostHeaderPreserve sends the Host header of the client.
+	hostHeaderPreserve = "preserve"
+	// hostHeaderBackend sends the host of the server URL.
+	hostHeaderBackend = "backend"
+	// hostHeaderCustom sends the value rendered from the template.
+	hostHeaderCustom = "custom"
+)
+
+// X-Forwarded-Host header policies of the services.
+const (
+	// forwardedHostKeep sends the X-Forwarded-Host header set on the entry point.
+	forwardedHostKeep = "keep"
+	// forwardedHostClient sends the Host header of the client.
+	forwardedHostClient = "client"
+	// forwardedHostRemove does not send the X-Forwarded-Host header.
+	forwardedHostRemove = "remove"
+)
+
+// hostHeader sets the Host header, and the X-Forwarded-Host header, of the requests forwarded to the servers.
+// A nil hostHeader sends the Host header of the client.
+type hostHeader struct {
+	policy        string
+	custom        *template.Template
+	forwardedHost string
+}
+
+// hostHeaderData is the data given to the template of the custom Host header.
+type hostHeaderData struct {
+	// Host is the Host header of the client.
+	Host string
+	// Hostname is the Host header of the client, without its port.
+	Hostname string
+	// ServerHost is the host of the server URL.
+	ServerHost string
+}
+
+// newHostHeader creates the Host header policy of a service,
+// from the passHostHeader option when the configuration does not set the policy.
+func newHostHeader(passHostHeader *bool, config *dynamic.HostHeader) (*hostHeader, error) {
+	h := &hostHeader{policy: hostHeaderPreserve, forwardedHost: forwardedHostKeep}
+	if passHostHeader != nil && !*passHostHeader {
+		h.policy = hostHeaderBackend
+	}
+
+	if config == nil {
+		return h, nil
+	}
+
+	if config.Policy != "" {
+		h.policy = config.Policy
+	}
+
+	switch h.policy {
+	case hostHeaderPreserve, hostHeaderBackend:
+	case hostHeaderCustom:
+		tmpl, err := template.New("hostHeader").Parse(config.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom Host header %q: %w", config.Value, err)
+		}
+
+		// The fields are only checked on execution.
+		if err := tmpl.Execute(&strings.Builder{}, hostHeaderData{}); err != nil {
+			return nil, fmt.Errorf("invalid custom Host header %q: %w", config.Value, err)
+		}
+
+		h.custom = tmpl
+	default:
+		return nil, fmt.Errorf("unknown Host header policy %q", config.Policy)
+	}
+
+	switch config.ForwardedHost {
+	case "":
+	case forwardedHostKeep, forwardedHostClient, forwardedHostRemove:
+		h.forwardedHost = config.ForwardedHost
+	default:
+		return nil, fmt.Errorf("unknown X-Forwarded-Host header policy %q", config.ForwardedHost)
+	}
+
+	return h, nil
+}
+
+// apply sets the headers of the request forwarded to the server of its URL,
+// given the Host header of the client.
+func (h *hostHeader) apply(outReq *http.Request, clientHost string) {
+	if h == nil {
+		outReq.Host = clientHost
+		return
+	}
+
+	switch h.policy {
+	case hostHeaderBackend:
+		outReq.Host = outReq.URL.Host
+	case hostHeaderCustom:
+		host, err := h.render(clientHost, outReq.URL.Host)
+		if err != nil {
+			log.FromContext(outReq.Context()).Debugf("Unable to render the custom Host header, sending the Host header of the client: %v", err)
+			host = clientHost
+		}
+		outReq.Host = host
+	default:
+		outReq.Host = clientHost
+	}
+
+	switch h.forwardedHost {
+	case forwardedHostClient:
+		outReq.Header.Set(forward.XForwardedHost, clientHost)
+	case forwardedHostRemove:
+		outReq.Header.Del(forward.XForwardedHost)
+	}
+}
+
+func (h *hostHeader) render(clientHost, serverHost string) (string, error) {
+	hostname := clientHost
+	if host, _, err := net.SplitHostPort(clientHost); err == nil {
+		hostname = host
+	}
+
+	var host strings.Builder
+	err := h.custom.Execute(&host, hostHeaderData{Host: clientHost, Hostname: hostname, ServerHost: serverHost})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(host.String()), nil
+}
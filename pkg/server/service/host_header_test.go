@@ -0,0 +1,166 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostHeader(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		passHostHeader        *bool
+		config                *dynamic.HostHeader
+		forwardedHost         string
+		expectedHost          string
+		expectedForwardedHost string
+	}{
+		{
+			desc:           "pass host header",
+			passHostHeader: Bool(true),
+			expectedHost:   "example.com:8080",
+		},
+		{
+			desc:           "do not pass host header",
+			passHostHeader: Bool(false),
+			expectedHost:   "backend.local:81",
+		},
+		{
+			desc:           "preserve policy taking precedence over passHostHeader",
+			passHostHeader: Bool(false),
+			config:         &dynamic.HostHeader{Policy: "preserve"},
+			expectedHost:   "example.com:8080",
+		},
+		{
+			desc:           "backend policy taking precedence over passHostHeader",
+			passHostHeader: Bool(true),
+			config:         &dynamic.HostHeader{Policy: "backend"},
+			expectedHost:   "backend.local:81",
+		},
+		{
+			desc:           "policy from passHostHeader",
+			passHostHeader: Bool(false),
+			config:         &dynamic.HostHeader{ForwardedHost: "client"},
+			forwardedHost:  "cdn.example.com",
+			expectedHost:   "backend.local:81",
+
+			expectedForwardedHost: "example.com:8080",
+		},
+		{
+			desc:           "custom policy",
+			passHostHeader: Bool(true),
+			config:         &dynamic.HostHeader{Policy: "custom", Value: "origin.{{ .Hostname }}"},
+			expectedHost:   "origin.example.com",
+		},
+		{
+			desc:           "custom policy with the server host",
+			passHostHeader: Bool(true),
+			config:         &dynamic.HostHeader{Policy: "custom", Value: "{{ .ServerHost }}"},
+			expectedHost:   "backend.local:81",
+		},
+		{
+			desc:           "keep forwarded host",
+			passHostHeader: Bool(true),
+			config:         &dynamic.HostHeader{ForwardedHost: "keep"},
+			forwardedHost:  "cdn.example.com",
+			expectedHost:   "example.com:8080",
+
+			expectedForwardedHost: "cdn.example.com",
+		},
+		{
+			desc:           "remove forwarded host",
+			passHostHeader: Bool(true),
+			config:         &dynamic.HostHeader{Policy: "backend", ForwardedHost: "remove"},
+			forwardedHost:  "cdn.example.com",
+			expectedHost:   "backend.local:81",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			host, err := newHostHeader(test.passHostHeader, test.config)
+			require.NoError(t, err)
+
+			outReq := httptest.NewRequest(http.MethodGet, "http://backend.local:81/foo", nil)
+			if test.forwardedHost != "" {
+				outReq.Header.Set("X-Forwarded-Host", test.forwardedHost)
+			}
+
+			host.apply(outReq, "example.com:8080")
+
+			assert.Equal(t, test.expectedHost, outReq.Host)
+			assert.Equal(t, test.expectedForwardedHost, outReq.Header.Get("X-Forwarded-Host"))
+		})
+	}
+}
+
+func TestNewHostHeader_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config *dynamic.HostHeader
+	}{
+		{
+			desc:   "unknown policy",
+			config: &dynamic.HostHeader{Policy: "client"},
+		},
+		{
+			desc:   "unknown forwarded host policy",
+			config: &dynamic.HostHeader{ForwardedHost: "backend"},
+		},
+		{
+			desc:   "invalid template",
+			config: &dynamic.HostHeader{Policy: "custom", Value: "{{ .Hostname"},
+		},
+		{
+			desc:   "unknown template field",
+			config: &dynamic.HostHeader{Policy: "custom", Value: "{{ .Port }}"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newHostHeader(Bool(true), test.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBuildProxy_hostHeader(t *testing.T) {
+	var host, forwardedHost string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		host = req.Host
+		forwardedHost = req.Header.Get("X-Forwarded-Host")
+	}))
+	defer server.Close()
+
+	h, err := newHostHeader(Bool(true), &dynamic.HostHeader{
+		Policy:        "custom",
+		Value:         "origin.{{ .Hostname }}",
+		ForwardedHost: "client",
+	})
+	require.NoError(t, err)
+
+	proxy, err := buildProxy(h, nil, http.DefaultTransport, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.Host = "example.com"
+	req.Header.Set("X-Forwarded-Host", "cdn.example.com")
+
+	rw := httptest.NewRecorder()
+	proxy.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "origin.example.com", host)
+	assert.Equal(t, "example.com", forwardedHost)
+}
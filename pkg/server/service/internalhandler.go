@@ -20,13 +20,15 @@ type InternalHandlers struct {
 	api        http.Handler
 	dashboard  http.Handler
 	rest       http.Handler
+	git        http.Handler
 	prometheus http.Handler
 	ping       http.Handler
+	health     http.Handler
 	serviceManager
 }
 
 // NewInternalHandlers creates a new InternalHandlers.
-func NewInternalHandlers(api func(configuration *runtime.Configuration) http.Handler, configuration *runtime.Configuration, rest, metricsHandler, pingHandler, dashboard http.Handler, next serviceManager) *InternalHandlers {
+func NewInternalHandlers(api func(configuration *runtime.Configuration) http.Handler, configuration *runtime.Configuration, rest, git, metricsHandler, pingHandler, healthHandler, dashboard http.Handler, next serviceManager) *InternalHandlers {
 	var apiHandler http.Handler
 	if api != nil {
 		apiHandler = api(configuration)
@@ -36,8 +38,10 @@ func NewInternalHandlers(api func(configuration *runtime.Configuration) http.Han
 		api:            apiHandler,
 		dashboard:      dashboard,
 		rest:           rest,
+		git:            git,
 		prometheus:     metricsHandler,
 		ping:           pingHandler,
+		health:         healthHandler,
 		serviceManager: next,
 	}
 }
@@ -81,12 +85,24 @@ func (m *InternalHandlers) get(serviceName string) (http.Handler, error) {
 		}
 		return m.rest, nil
 
+	case "git@internal":
+		if m.git == nil {
+			return nil, errors.New("git webhook is not enabled")
+		}
+		return m.git, nil
+
 	case "ping@internal":
 		if m.ping == nil {
 			return nil, errors.New("ping is not enabled")
 		}
 		return m.ping, nil
 
+	case "health@internal":
+		if m.health == nil {
+			return nil, errors.New("ping is not enabled")
+		}
+		return m.health, nil
+
 	case "prometheus@internal":
 		if m.prometheus == nil {
 			return nil, errors.New("prometheus is not enabled")
@@ -0,0 +1,102 @@
+package wrr
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ServiceStatus describes the current weight of a child of a weighted service,
+// and the traffic it received since Traefik started.
+// Overridden tells whether the weight was set at runtime, masking the configured weight.
+type ServiceStatus struct {
+	Name             string  `json:"name"`
+	Weight           int     `json:"weight"`
+	ConfiguredWeight int     `json:"configuredWeight"`
+	Overridden       bool    `json:"overridden"`
+	Requests         uint64  `json:"requests"`
+	Errors           uint64  `json:"errors"`
+	AverageDuration  float64 `json:"averageDuration"`
+}
+
+// stats holds the traffic counters of a child of a weighted service.
+type stats struct {
+	requests uint64
+	errors   uint64
+	// duration is the total time, in nanoseconds, spent serving the requests.
+	duration uint64
+}
+
+func (s *stats) status(name string, weight int) ServiceStatus {
+	status := ServiceStatus{
+		Name:     name,
+		Weight:   weight,
+		Requests: atomic.LoadUint64(&s.requests),
+		Errors:   atomic.LoadUint64(&s.errors),
+	}
+
+	if status.Requests > 0 {
+		status.AverageDuration = time.Duration(atomic.LoadUint64(&s.duration) / status.Requests).Seconds()
+	}
+
+	return status
+}
+
+type statsHandler struct {
+	next  http.Handler
+	stats *stats
+}
+
+func (h *statsHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	recorder := &statusRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+	start := time.Now()
+
+	h.next.ServeHTTP(recorder, req)
+
+	atomic.AddUint64(&h.stats.requests, 1)
+	atomic.AddUint64(&h.stats.duration, uint64(time.Since(start)))
+	if recorder.statusCode >= http.StatusInternalServerError {
+		atomic.AddUint64(&h.stats.errors, 1)
+	}
+}
+
+// statusRecorder captures the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.ResponseWriter.WriteHeader(status)
+	r.statusCode = status
+}
+
+// Hijack hijacks the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *statusRecorder) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return nil
+}
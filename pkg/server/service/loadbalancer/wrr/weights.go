@@ -0,0 +1,244 @@
+package wrr
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// Weights keeps track of the balancers built for the weighted services,
+// and of the weights of their children overridden at runtime.
+// The overrides survive configuration reloads,
+// and are applied to the balancers registered afterwards, masking the configured weights until they are cleared.
+// It also collects the traffic statistics of the children,
+// for the progressive delivery tools analysing the canaries.
+type Weights struct {
+	mu        sync.RWMutex
+	overrides map[string]map[string]int
+	// services holds the balancers serving the requests.
+	services map[string]*weightedService
+	// next holds the balancers built for the configuration being applied, until Commit.
+	next  map[string]*weightedService
+	stats map[string]map[string]*stats
+}
+
+// weightedService holds the balancers built for a weighted service,
+// and the weights of its children in the configuration.
+type weightedService struct {
+	balancers  []*Balancer
+	configured map[string]int
+}
+
+// NewWeights creates a new Weights.
+func NewWeights() *Weights {
+	return &Weights{
+		overrides: make(map[string]map[string]int),
+		services:  make(map[string]*weightedService),
+		stats:     make(map[string]map[string]*stats),
+	}
+}
+
+// Instrument wraps the handler of the child service name of the weighted service serviceName,
+// to collect its traffic statistics.
+func (w *Weights) Instrument(serviceName, name string, next http.Handler) http.Handler {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stats[serviceName] == nil {
+		w.stats[serviceName] = make(map[string]*stats)
+	}
+
+	s, ok := w.stats[serviceName][name]
+	if !ok {
+		s = &stats{}
+		w.stats[serviceName][name] = s
+	}
+
+	return &statsHandler{next: next, stats: s}
+}
+
+// Status returns the current weights and the traffic statistics of the children of the weighted service serviceName,
+// sorted by name.
+func (w *Weights) Status(serviceName string) ([]ServiceStatus, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	service, ok := w.services[serviceName]
+	if !ok || len(service.balancers) == 0 {
+		return nil, fmt.Errorf("the weighted service %q is not in use", serviceName)
+	}
+
+	var statuses []ServiceStatus
+	for name, weight := range service.balancers[0].Weights() {
+		s, ok := w.stats[serviceName][name]
+		if !ok {
+			s = &stats{}
+		}
+
+		status := s.status(name, weight)
+		status.ConfiguredWeight = service.configured[name]
+		_, status.Overridden = w.overrides[serviceName][name]
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses, nil
+}
+
+// Register tracks the balancer of the given weighted service,
+// and applies the weights previously overridden for this service.
+// After Reset, the balancer belongs to the configuration being applied until Commit.
+func (w *Weights) Register(serviceName string, balancer *Balancer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	services := w.services
+	if w.next != nil {
+		services = w.next
+	}
+
+	service, ok := services[serviceName]
+	if !ok {
+		// The balancer is fresh from the configuration, before any override.
+		service = &weightedService{configured: balancer.Weights()}
+		services[serviceName] = service
+	}
+
+	// The overrides of the children removed from the configuration are ignored.
+	overrides := make(map[string]int)
+	for name, weight := range w.overrides[serviceName] {
+		configured, ok := service.configured[name]
+		if !ok {
+			continue
+		}
+
+		if configured != weight {
+			log.WithoutContext().Warnf("The weight %d of the service %s in the weighted service %s, set through the API, masks its configured weight %d", weight, name, serviceName, configured)
+		}
+		overrides[name] = weight
+	}
+	balancer.SetWeights(overrides)
+
+	service.balancers = append(service.balancers, balancer)
+}
+
+// Reset starts tracking the balancers built for a new configuration, but keeps the overridden weights.
+// It has to be called before building the handlers of a new configuration,
+// the balancers of the previous configuration being still updated until Commit.
+func (w *Weights) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.next = make(map[string]*weightedService)
+}
+
+// Commit replaces the tracked balancers with the ones registered since Reset.
+// It has to be called once the handlers of the new configuration are built.
+func (w *Weights) Commit() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.next == nil {
+		return
+	}
+
+	w.services = w.next
+	w.next = nil
+}
+
+// SetWeight overrides the weight of the child service name of the weighted service serviceName,
+// and applies it to all the balancers currently built for this service.
+func (w *Weights) SetWeight(serviceName, name string, weight int) error {
+	return w.SetWeights(serviceName, map[string]int{name: weight})
+}
+
+// SetWeights overrides at once the weights of children of the weighted service serviceName,
+// and applies them to all the balancers currently built for this service.
+// No weight is changed if one of them is invalid.
+func (w *Weights) SetWeights(serviceName string, weights map[string]int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for name, weight := range weights {
+		if weight < 0 {
+			return fmt.Errorf("invalid weight %d for the service %q: must not be negative", weight, name)
+		}
+	}
+
+	service, ok := w.next[serviceName]
+	if !ok {
+		service, ok = w.services[serviceName]
+	}
+	if !ok {
+		return fmt.Errorf("the weighted service %q is not in use", serviceName)
+	}
+
+	for name := range weights {
+		if _, ok := service.configured[name]; !ok {
+			return fmt.Errorf("the service %q is not part of the weighted service %q", name, serviceName)
+		}
+	}
+
+	for _, balancer := range w.balancers(serviceName) {
+		// The balancers of the previous configuration may miss the children just added.
+		balancer.SetWeights(balancer.filter(weights))
+	}
+
+	if w.overrides[serviceName] == nil {
+		w.overrides[serviceName] = make(map[string]int)
+	}
+	for name, weight := range weights {
+		w.overrides[serviceName][name] = weight
+	}
+
+	return nil
+}
+
+// ClearWeights drops the weights overridden for the weighted service serviceName,
+// and restores the configured weights in all the balancers currently built for this service.
+func (w *Weights) ClearWeights(serviceName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, current := w.services[serviceName]
+	_, next := w.next[serviceName]
+	if !current && !next {
+		return fmt.Errorf("the weighted service %q is not in use", serviceName)
+	}
+
+	delete(w.overrides, serviceName)
+
+	for _, service := range []*weightedService{w.services[serviceName], w.next[serviceName]} {
+		if service == nil {
+			continue
+		}
+
+		for _, balancer := range service.balancers {
+			balancer.SetWeights(service.configured)
+		}
+	}
+
+	return nil
+}
+
+// balancers returns the balancers currently built for the weighted service serviceName,
+// including the ones built for the configuration being applied.
+// The caller must hold the mutex.
+func (w *Weights) balancers(serviceName string) []*Balancer {
+	var balancers []*Balancer
+	if service, ok := w.services[serviceName]; ok {
+		balancers = append(balancers, service.balancers...)
+	}
+	if service, ok := w.next[serviceName]; ok {
+		balancers = append(balancers, service.balancers...)
+	}
+
+	return balancers
+}
@@ -78,6 +78,9 @@ type Balancer struct {
 	mutex       sync.RWMutex
 	handlers    []*namedHandler
 	curDeadline float64
+	// idle holds the handlers with a non-positive weight,
+	// which are not scheduled until their weight is raised with SetWeight.
+	idle []*namedHandler
 }
 
 func (b *Balancer) nextServer() (*namedHandler, error) {
@@ -110,11 +113,9 @@ func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 
 		if err == nil && cookie != nil {
-			for _, handler := range b.handlers {
-				if handler.name == cookie.Value {
-					handler.ServeHTTP(w, req)
-					return
-				}
+			if handler := b.stickyHandler(cookie.Value); handler != nil {
+				handler.ServeHTTP(w, req)
+				return
 			}
 		}
 	}
@@ -133,20 +134,33 @@ func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	server.ServeHTTP(w, req)
 }
 
+func (b *Balancer) stickyHandler(name string) *namedHandler {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.handlers {
+		if handler.name == name {
+			return handler
+		}
+	}
+	return nil
+}
+
 // AddService adds a handler.
 // It is not thread safe with ServeHTTP.
-// A handler with a non-positive weight is ignored.
+// A handler with a non-positive weight is not scheduled, until its weight is raised with SetWeight.
 func (b *Balancer) AddService(name string, handler http.Handler, weight *int) {
 	w := 1
 	if weight != nil {
 		w = *weight
 	}
+
+	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
 	if w <= 0 { // non-positive weight is meaningless
+		b.idle = append(b.idle, h)
 		return
 	}
 
-	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
-
 	// use RWLock to protect b.curDeadline
 	b.mutex.RLock()
 	h.deadline = b.curDeadline + 1/h.weight
@@ -154,3 +168,115 @@ func (b *Balancer) AddService(name string, handler http.Handler, weight *int) {
 
 	heap.Push(b, h)
 }
+
+// Weights returns the current weight of each handler.
+func (b *Balancer) Weights() map[string]int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	weights := make(map[string]int, len(b.handlers)+len(b.idle))
+	for _, h := range b.handlers {
+		weights[h.name] = int(h.weight)
+	}
+	for _, h := range b.idle {
+		weights[h.name] = 0
+	}
+	return weights
+}
+
+// SetWeight changes the weight of the named handler.
+// A non-positive weight stops scheduling the handler, until its weight is raised again.
+// It returns false if the balancer has no handler with the given name.
+func (b *Balancer) SetWeight(name string, weight int) bool {
+	return b.SetWeights(map[string]int{name: weight})
+}
+
+// SetWeights changes at once the weights of the named handlers,
+// so that the requests are never balanced with only some of the new weights.
+// It returns false, without changing any weight, if the balancer misses one of the handlers.
+func (b *Balancer) SetWeights(weights map[string]int) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for name := range weights {
+		if !b.has(name) {
+			return false
+		}
+	}
+
+	for name, weight := range weights {
+		b.setWeight(name, weight)
+	}
+
+	return true
+}
+
+// filter returns the weights of the handlers the balancer has.
+func (b *Balancer) filter(weights map[string]int) map[string]int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	filtered := make(map[string]int, len(weights))
+	for name, weight := range weights {
+		if b.has(name) {
+			filtered[name] = weight
+		}
+	}
+	return filtered
+}
+
+// has reports whether the balancer has a handler with the given name.
+// The caller must hold the mutex.
+func (b *Balancer) has(name string) bool {
+	for _, h := range b.handlers {
+		if h.name == name {
+			return true
+		}
+	}
+
+	for _, h := range b.idle {
+		if h.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setWeight changes the weight of the named handler.
+// The caller must hold the mutex.
+func (b *Balancer) setWeight(name string, weight int) {
+	for i, h := range b.handlers {
+		if h.name != name {
+			continue
+		}
+
+		if weight <= 0 {
+			heap.Remove(b, i)
+			h.weight = 0
+			b.idle = append(b.idle, h)
+			return
+		}
+
+		h.weight = float64(weight)
+		h.deadline = b.curDeadline + 1/h.weight
+		heap.Fix(b, i)
+		return
+	}
+
+	for i, h := range b.idle {
+		if h.name != name {
+			continue
+		}
+
+		if weight <= 0 {
+			return
+		}
+
+		b.idle = append(b.idle[:i], b.idle[i+1:]...)
+		h.weight = float64(weight)
+		h.deadline = b.curDeadline + 1/h.weight
+		heap.Push(b, h)
+		return
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Int(v int) *int { return &v }
@@ -140,3 +141,171 @@ func TestBalancerBias(t *testing.T) {
 
 	assert.Equal(t, wantSequence, recorder.sequence)
 }
+
+func TestBalancerSetWeight(t *testing.T) {
+	balancer := New(nil)
+
+	balancer.AddService("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.AddService("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(0))
+
+	assert.True(t, balancer.SetWeight("second", 3))
+	assert.False(t, balancer.SetWeight("unknown", 3))
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 1, recorder.save["first"])
+	assert.Equal(t, 3, recorder.save["second"])
+
+	assert.True(t, balancer.SetWeight("first", 0))
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 0, recorder.save["first"])
+	assert.Equal(t, 4, recorder.save["second"])
+}
+
+func TestWeights(t *testing.T) {
+	weights := NewWeights()
+
+	err := weights.SetWeight("foo@file", "first", 2)
+	assert.Error(t, err)
+
+	balancer := New(nil)
+	balancer.AddService("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+	balancer.AddService("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+	weights.Register("foo@file", balancer)
+
+	assert.Error(t, weights.SetWeight("foo@file", "unknown", 2))
+	assert.NoError(t, weights.SetWeight("foo@file", "first", 0))
+
+	// The override has to be applied to the balancers built for a new configuration.
+	weights.Reset()
+
+	next := New(nil)
+	next.AddService("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+	next.AddService("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+	weights.Register("foo@file", next)
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 4; i++ {
+		next.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 0, recorder.save["first"])
+	assert.Equal(t, 4, recorder.save["second"])
+}
+
+func TestWeightsStatus(t *testing.T) {
+	weights := NewWeights()
+
+	_, err := weights.Status("foo@file")
+	assert.Error(t, err)
+
+	balancer := New(nil)
+	balancer.AddService("first", weights.Instrument("foo@file", "first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})), Int(1))
+	balancer.AddService("second", weights.Instrument("foo@file", "second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})), Int(1))
+	weights.Register("foo@file", balancer)
+
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.NoError(t, weights.SetWeight("foo@file", "second", 0))
+
+	statuses, err := weights.Status("foo@file")
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+
+	assert.Equal(t, "first", statuses[0].Name)
+	assert.Equal(t, 1, statuses[0].Weight)
+	assert.Equal(t, uint64(2), statuses[0].Requests)
+	assert.Equal(t, uint64(0), statuses[0].Errors)
+
+	assert.Equal(t, "second", statuses[1].Name)
+	assert.Equal(t, 0, statuses[1].Weight)
+	assert.Equal(t, uint64(2), statuses[1].Requests)
+	assert.Equal(t, uint64(2), statuses[1].Errors)
+}
+
+func TestWeightsSetWeights(t *testing.T) {
+	weights := NewWeights()
+
+	newBalancer := func() *Balancer {
+		balancer := New(nil)
+		balancer.AddService("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+		balancer.AddService("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+		return balancer
+	}
+
+	balancer := newBalancer()
+	weights.Register("foo@file", balancer)
+
+	// No weight is changed when one of them is invalid.
+	assert.Error(t, weights.SetWeights("foo@file", map[string]int{"first": 2, "unknown": 3}))
+	assert.Error(t, weights.SetWeights("foo@file", map[string]int{"first": 2, "second": -1}))
+	assert.Equal(t, map[string]int{"first": 1, "second": 1}, balancer.Weights())
+
+	// While a new configuration is being applied, the updates reach both the current and the new balancers.
+	weights.Reset()
+	next := newBalancer()
+	weights.Register("foo@file", next)
+
+	require.NoError(t, weights.SetWeights("foo@file", map[string]int{"first": 0, "second": 4}))
+	assert.Equal(t, map[string]int{"first": 0, "second": 4}, balancer.Weights())
+	assert.Equal(t, map[string]int{"first": 0, "second": 4}, next.Weights())
+
+	weights.Commit()
+
+	statuses, err := weights.Status("foo@file")
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceStatus{
+		{Name: "first", Weight: 0, ConfiguredWeight: 1, Overridden: true},
+		{Name: "second", Weight: 4, ConfiguredWeight: 1, Overridden: true},
+	}, statuses)
+
+	require.NoError(t, weights.ClearWeights("foo@file"))
+	assert.Equal(t, map[string]int{"first": 1, "second": 1}, next.Weights())
+
+	statuses, err = weights.Status("foo@file")
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceStatus{
+		{Name: "first", Weight: 1, ConfiguredWeight: 1},
+		{Name: "second", Weight: 1, ConfiguredWeight: 1},
+	}, statuses)
+}
+
+func TestStatusRecorder_Hijack(t *testing.T) {
+	recorder := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+
+	_, _, err := recorder.Hijack()
+	assert.Error(t, err)
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/containous/traefik/v2/pkg/affinity"
 	"github.com/containous/traefik/v2/pkg/api"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
 )
 
 // ManagerFactory a factory of service manager.
@@ -15,12 +19,22 @@ type ManagerFactory struct {
 	metricsRegistry metrics.Registry
 
 	defaultRoundTripper http.RoundTripper
+	roundTrippers       map[string]http.RoundTripper
+	egressTransports    *egressTransports
 
 	api              func(configuration *runtime.Configuration) http.Handler
 	restHandler      http.Handler
+	gitHandler       http.Handler
 	dashboardHandler http.Handler
 	metricsHandler   http.Handler
 	pingHandler      http.Handler
+	healthHandler    http.Handler
+
+	weights *wrr.Weights
+
+	affinityTable affinity.Table
+
+	drains *serverDrains
 
 	routinesPool *safe.Pool
 }
@@ -29,12 +43,35 @@ type ManagerFactory struct {
 func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *safe.Pool, metricsRegistry metrics.Registry) *ManagerFactory {
 	factory := &ManagerFactory{
 		metricsRegistry:     metricsRegistry,
-		defaultRoundTripper: setupDefaultRoundTripper(staticConfiguration.ServersTransport),
+		defaultRoundTripper: setupDefaultRoundTripper(staticConfiguration.ServersTransport, metricsRegistry.IsSvcTCPInfoEnabled()),
+		egressTransports:    newEgressTransports(staticConfiguration.ServersTransport),
 		routinesPool:        routinesPool,
+		roundTrippers:       make(map[string]http.RoundTripper),
+		drains:              newServerDrains(),
+	}
+
+	for name, transportConfiguration := range staticConfiguration.ServersTransports {
+		roundTripper, err := createRoundtripper(transportConfiguration, metricsRegistry.IsSvcTCPInfoEnabled())
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to create the servers transport %s: %v", name, err)
+			continue
+		}
+		factory.roundTrippers[name] = roundTripper
+	}
+
+	var err error
+	factory.affinityTable, err = affinity.NewTable(staticConfiguration.AffinityTable)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the affinity table, falling back to a table local to the instance: %v", err)
+		factory.affinityTable = affinity.NewMemoryTable()
 	}
 
 	if staticConfiguration.API != nil {
-		factory.api = api.NewBuilder(staticConfiguration)
+		if staticConfiguration.API.Weights != nil {
+			factory.weights = wrr.NewWeights()
+		}
+
+		factory.api = api.NewBuilder(staticConfiguration, factory.weights)
 
 		if staticConfiguration.API.Dashboard {
 			factory.dashboardHandler = http.FileServer(staticConfiguration.API.DashboardAssets)
@@ -45,6 +82,10 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 		factory.restHandler = staticConfiguration.Providers.Rest.CreateRouter()
 	}
 
+	if staticConfiguration.Providers != nil && staticConfiguration.Providers.Git != nil && staticConfiguration.Providers.Git.WebhookSecret != "" {
+		factory.gitHandler = staticConfiguration.Providers.Git
+	}
+
 	if staticConfiguration.Metrics != nil && staticConfiguration.Metrics.Prometheus != nil {
 		factory.metricsHandler = metrics.PrometheusHandler()
 	}
@@ -54,6 +95,7 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 	// and would break things elsewhere.
 	if staticConfiguration.Ping != nil {
 		factory.pingHandler = staticConfiguration.Ping
+		factory.healthHandler = staticConfiguration.Ping.HealthHandler()
 	}
 
 	return factory
@@ -62,5 +104,31 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 // Build creates a service manager.
 func (f *ManagerFactory) Build(configuration *runtime.Configuration) *InternalHandlers {
 	svcManager := NewManager(configuration.Services, f.defaultRoundTripper, f.metricsRegistry, f.routinesPool)
-	return NewInternalHandlers(f.api, configuration, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, svcManager)
+	svcManager.egressTransports = f.egressTransports
+	f.egressTransports.update(configuration.Services)
+	svcManager.roundTrippers = f.roundTrippers
+	svcManager.affinityTable = f.affinityTable
+
+	f.drains.update(configuration.Services, time.Now())
+	svcManager.drains = f.drains
+
+	if f.weights != nil {
+		f.weights.Reset()
+		svcManager.weights = f.weights
+	}
+
+	return NewInternalHandlers(f.api, configuration, f.restHandler, f.gitHandler, f.metricsHandler, f.pingHandler, f.healthHandler, f.dashboardHandler, svcManager)
+}
+
+// Commit marks the handlers built by the last service manager as the ones serving the requests.
+// It has to be called once all the handlers of the configuration are built.
+func (f *ManagerFactory) Commit() {
+	if f.weights != nil {
+		f.weights.Commit()
+	}
+}
+
+// AffinityTable returns the affinity table shared by the service managers.
+func (f *ManagerFactory) AffinityTable() affinity.Table {
+	return f.affinityTable
 }
@@ -21,7 +21,7 @@ const StatusClientClosedRequest = 499
 // StatusClientClosedRequestText non-standard HTTP status for client disconnection.
 const StatusClientClosedRequestText = "Client Closed Request"
 
-func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwarding, defaultRoundTripper http.RoundTripper, bufferPool httputil.BufferPool) (http.Handler, error) {
+func buildProxy(host *hostHeader, responseForwarding *dynamic.ResponseForwarding, defaultRoundTripper http.RoundTripper, bufferPool httputil.BufferPool) (http.Handler, error) {
 	var flushInterval ptypes.Duration
 	if responseForwarding != nil {
 		err := flushInterval.Set(responseForwarding.FlushInterval)
@@ -56,10 +56,7 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 				outReq.Header.Set("User-Agent", "")
 			}
 
-			// Do not pass client Host header unless optsetter PassHostHeader is set.
-			if passHostHeader != nil && !*passHostHeader {
-				outReq.Host = outReq.URL.Host
-			}
+			host.apply(outReq, outReq.Host)
 
 			// Even if the websocket RFC says that headers should be case-insensitive,
 			// some servers need Sec-WebSocket-Key, Sec-WebSocket-Extensions, Sec-WebSocket-Accept,
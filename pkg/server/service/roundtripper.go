@@ -1,15 +1,21 @@
 package service
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/qos"
+	"github.com/containous/traefik/v2/pkg/tcpinfo"
 	traefiktls "github.com/containous/traefik/v2/pkg/tls"
 	"golang.org/x/net/http2"
 )
@@ -28,7 +34,25 @@ func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, erro
 // An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost
 // in Traefik at this point in time. Setting this value to the default of 100 could lead to confusing
 // behavior and backwards compatibility issues.
-func createRoundtripper(transportConfiguration *static.ServersTransport) (http.RoundTripper, error) {
+// When tcpInfo is true, the connections to the servers are wrapped to give access to their TCP statistics.
+// The requests classified by the QoS middlewares are sent on connections dedicated to their DSCP value.
+func createRoundtripper(transportConfiguration *static.ServersTransport, tcpInfo bool) (http.RoundTripper, error) {
+	transport, err := createHTTPTransport(transportConfiguration, tcpInfo, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTripper, err := newSmartRoundTripper(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return newQoSRoundTripper(roundTripper, transportConfiguration, tcpInfo), nil
+}
+
+// createHTTPTransport creates the http.Transport of the round trippers, configured with the Transport configuration settings.
+// When dscp is not 0, the connections to the servers are marked with the DSCP value.
+func createHTTPTransport(transportConfiguration *static.ServersTransport, tcpInfo bool, dscp int) (*http.Transport, error) {
 	if transportConfiguration == nil {
 		return nil, errors.New("no transport configuration given")
 	}
@@ -43,9 +67,47 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 		dialer.Timeout = time.Duration(transportConfiguration.ForwardingTimeouts.DialTimeout)
 	}
 
+	if transportConfiguration.LocalAddress != "" {
+		ip := net.ParseIP(transportConfiguration.LocalAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid local address %q", transportConfiguration.LocalAddress)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	if transportConfiguration.Interface != "" {
+		control, err := bindToDevice(transportConfiguration.Interface)
+		if err != nil {
+			return nil, err
+		}
+		dialer.Control = control
+	}
+
+	if dscp > 0 {
+		dialer.Control = chainControls(dialer.Control, qos.Control(dscp))
+	}
+
+	proxy := http.ProxyFromEnvironment
+	dial := dialer.DialContext
+	if transportConfiguration.Proxy != "" {
+		proxyDialContext, err := newProxyDialContext(transportConfiguration.Proxy, dialer)
+		if err != nil {
+			return nil, err
+		}
+
+		// The connections are tunneled through the proxy, the environment proxy settings no longer apply.
+		proxy = nil
+		dial = proxyDialContext
+	}
+
+	dialContext := dial
+	if tcpInfo {
+		dialContext = tcpinfo.WrapDialContext(dialContext)
+	}
+
 	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
+		Proxy:                 proxy,
+		DialContext:           dialContext,
 		MaxIdleConnsPerHost:   transportConfiguration.MaxIdleConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
@@ -55,7 +117,7 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 	transport.RegisterProtocol("h2c", &h2cTransportWrapper{
 		Transport: &http2.Transport{
 			DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
-				return net.Dial(netw, addr)
+				return dial(context.Background(), netw, addr)
 			},
 			AllowHTTP: true,
 		},
@@ -66,19 +128,46 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 		transport.IdleConnTimeout = time.Duration(transportConfiguration.ForwardingTimeouts.IdleConnTimeout)
 	}
 
-	if transportConfiguration.InsecureSkipVerify || len(transportConfiguration.RootCAs) > 0 {
+	if transportConfiguration.InsecureSkipVerify || len(transportConfiguration.RootCAs) > 0 || len(transportConfiguration.Certificates) > 0 {
 		transport.TLSClientConfig = &tls.Config{
 			InsecureSkipVerify: transportConfiguration.InsecureSkipVerify,
 			RootCAs:            createRootCACertPool(transportConfiguration.RootCAs),
 		}
+
+		certificates, err := loadClientCertificates(transportConfiguration.Certificates)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.Certificates = certificates
 	}
 
-	smartTransport, err := newSmartRoundTripper(transport)
-	if err != nil {
-		return nil, err
+	return transport, nil
+}
+
+// loadClientCertificates loads the certificates presented to the servers requiring mutual TLS.
+func loadClientCertificates(certificates traefiktls.Certificates) ([]tls.Certificate, error) {
+	var clientCertificates []tls.Certificate
+
+	for _, certificate := range certificates {
+		certContent, err := certificate.CertFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client certificate: %w", err)
+		}
+
+		keyContent, err := certificate.KeyFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client key: %w", err)
+		}
+
+		clientCertificate, err := tls.X509KeyPair(certContent, keyContent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+
+		clientCertificates = append(clientCertificates, clientCertificate)
 	}
 
-	return smartTransport, nil
+	return clientCertificates, nil
 }
 
 func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
@@ -100,8 +189,8 @@ func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
 	return roots
 }
 
-func setupDefaultRoundTripper(conf *static.ServersTransport) http.RoundTripper {
-	transport, err := createRoundtripper(conf)
+func setupDefaultRoundTripper(conf *static.ServersTransport, tcpInfo bool) http.RoundTripper {
+	transport, err := createRoundtripper(conf, tcpInfo)
 	if err != nil {
 		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
 		return http.DefaultTransport
@@ -109,3 +198,92 @@ func setupDefaultRoundTripper(conf *static.ServersTransport) http.RoundTripper {
 
 	return transport
 }
+
+// chainControls returns the dialer control function calling the given ones in turn.
+func chainControls(first, second func(network, address string, c syscall.RawConn) error) func(network, address string, c syscall.RawConn) error {
+	if first == nil {
+		return second
+	}
+
+	return func(network, address string, c syscall.RawConn) error {
+		if err := first(network, address, c); err != nil {
+			return err
+		}
+		return second(network, address, c)
+	}
+}
+
+// qosRoundTripper sends the requests classified with a DSCP value on connections dedicated to the value,
+// marked once when they are dialed.
+// The connections, and the HTTP/2 ones in particular, are thus never shared by the requests of different values.
+type qosRoundTripper struct {
+	http.RoundTripper
+
+	transportConfiguration *static.ServersTransport
+	tcpInfo                bool
+
+	mu     sync.Mutex
+	marked map[int]http.RoundTripper
+}
+
+func newQoSRoundTripper(roundTripper http.RoundTripper, transportConfiguration *static.ServersTransport, tcpInfo bool) *qosRoundTripper {
+	return &qosRoundTripper{
+		RoundTripper:           roundTripper,
+		transportConfiguration: transportConfiguration,
+		tcpInfo:                tcpInfo,
+		marked:                 make(map[int]http.RoundTripper),
+	}
+}
+
+func (q *qosRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	dscp, ok := qos.FromContext(req.Context())
+	if !ok || dscp == 0 {
+		return q.RoundTripper.RoundTrip(req)
+	}
+
+	return q.get(dscp).RoundTrip(req)
+}
+
+// get returns the round tripper of the DSCP value, created on first use.
+// The unmarked round tripper is returned when it cannot be created.
+func (q *qosRoundTripper) get(dscp int) http.RoundTripper {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if roundTripper, ok := q.marked[dscp]; ok {
+		return roundTripper
+	}
+
+	transport, err := createHTTPTransport(q.transportConfiguration, q.tcpInfo, dscp)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the transport marked with the DSCP value %d: %v", dscp, err)
+		return q.RoundTripper
+	}
+
+	roundTripper, err := newSmartRoundTripper(transport)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the transport marked with the DSCP value %d: %v", dscp, err)
+		return q.RoundTripper
+	}
+
+	q.marked[dscp] = roundTripper
+
+	return roundTripper
+}
+
+// CloseIdleConnections closes the idle connections of the unmarked and marked round trippers.
+func (q *qosRoundTripper) CloseIdleConnections() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	roundTrippers := []http.RoundTripper{q.RoundTripper}
+	for _, roundTripper := range q.marked {
+		roundTrippers = append(roundTrippers, roundTripper)
+	}
+
+	for _, roundTripper := range roundTrippers {
+		if closer, ok := roundTripper.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"syscall"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/middlewares/qos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQoSRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	roundTripper, err := createRoundtripper(&static.ServersTransport{MaxIdleConnsPerHost: 10}, false)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc        string
+		dscp        int
+		classified  bool
+		expectedTOS int
+	}{
+		{
+			desc:        "unclassified request",
+			expectedTOS: 0,
+		},
+		{
+			desc:        "request classified as EF",
+			dscp:        46,
+			classified:  true,
+			expectedTOS: 46 << 2,
+		},
+		{
+			desc:        "request classified as AF41",
+			dscp:        34,
+			classified:  true,
+			expectedTOS: 34 << 2,
+		},
+		{
+			desc:        "unclassified request after the classified ones",
+			expectedTOS: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		var conn net.Conn
+		ctx := httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) { conn = info.Conn },
+		})
+		if test.classified {
+			ctx = qos.WithDSCP(ctx, test.dscp)
+		}
+
+		resp, err := roundTripper.RoundTrip(req.WithContext(ctx))
+		require.NoError(t, err, test.desc)
+		require.NoError(t, resp.Body.Close(), test.desc)
+
+		require.NotNil(t, conn, test.desc)
+		assert.Equal(t, test.expectedTOS, getTOS(t, conn), test.desc)
+	}
+}
+
+func getTOS(t *testing.T, conn net.Conn) int {
+	t.Helper()
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	require.True(t, ok)
+
+	rawConn, err := tcpConn.SyscallConn()
+	require.NoError(t, err)
+
+	var tos int
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		tos, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS)
+	})
+	require.NoError(t, err)
+	require.NoError(t, sockErr)
+
+	return tos
+}
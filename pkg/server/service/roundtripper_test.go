@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHTTPTransport_LocalAddress(t *testing.T) {
+	var remoteHost string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		remoteHost, _, _ = net.SplitHostPort(req.RemoteAddr)
+	}))
+	t.Cleanup(server.Close)
+
+	transport, err := createHTTPTransport(&static.ServersTransport{LocalAddress: "127.0.0.1"}, false, 0)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, "127.0.0.1", remoteHost)
+}
+
+func TestCreateHTTPTransport_invalidLocalAddress(t *testing.T) {
+	_, err := createHTTPTransport(&static.ServersTransport{LocalAddress: "backend.example.com"}, false, 0)
+	assert.Error(t, err)
+}
+
+func TestCreateHTTPTransport_ConnectProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(server.Close)
+
+	serverAddr := server.Listener.Addr().String()
+
+	var (
+		mu       sync.Mutex
+		tunneled []string
+	)
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodConnect {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpzZWNyZXQ=" {
+			rw.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		mu.Lock()
+		tunneled = append(tunneled, req.Host)
+		mu.Unlock()
+
+		upstream, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = upstream.Close() }()
+
+		conn, brw, err := rw.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+		go func() { _, _ = io.Copy(upstream, brw) }()
+		_, _ = io.Copy(conn, upstream)
+	}))
+	t.Cleanup(proxyServer.Close)
+
+	testCases := []struct {
+		desc      string
+		proxyURL  string
+		expStatus int
+		expError  bool
+	}{
+		{
+			desc:      "authenticated",
+			proxyURL:  "http://user:secret@" + proxyServer.Listener.Addr().String(),
+			expStatus: http.StatusTeapot,
+		},
+		{
+			desc:     "not authenticated",
+			proxyURL: "http://" + proxyServer.Listener.Addr().String(),
+			expError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			transport, err := createHTTPTransport(&static.ServersTransport{Proxy: test.proxyURL}, false, 0)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := transport.RoundTrip(req)
+			if test.expError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expStatus, resp.StatusCode)
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, []string{serverAddr}, tunneled)
+		})
+	}
+}
+
+func TestCreateHTTPTransport_invalidProxy(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		proxyURL string
+	}{
+		{
+			desc:     "unsupported scheme",
+			proxyURL: "ftp://proxy.example.com",
+		},
+		{
+			desc:     "missing host",
+			proxyURL: "socks5://",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := createHTTPTransport(&static.ServersTransport{Proxy: test.proxyURL}, false, 0)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConnectDialer_handshake(t *testing.T) {
+	// The proxy accepts the connections, but never answers the CONNECT requests.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(ioutil.Discard, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	t.Run("timeout", func(t *testing.T) {
+		dialer := &connectDialer{dialer: &net.Dialer{}, address: listener.Addr().String(), timeout: 50 * time.Millisecond}
+
+		_, err := dialer.DialContext(context.Background(), "tcp", "backend.example.com:80")
+		assert.Error(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		dialer := &connectDialer{dialer: &net.Dialer{}, address: listener.Addr().String(), timeout: time.Minute}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := dialer.DialContext(ctx, "tcp", "backend.example.com:80")
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
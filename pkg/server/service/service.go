@@ -11,15 +11,18 @@ import (
 	"time"
 
 	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/affinity"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/healthcheck"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/middlewares/coalescing"
 	"github.com/containous/traefik/v2/pkg/middlewares/emptybackendhandler"
 	metricsMiddle "github.com/containous/traefik/v2/pkg/middlewares/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/pipelining"
+	"github.com/containous/traefik/v2/pkg/middlewares/qos"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/server/cookie"
 	"github.com/containous/traefik/v2/pkg/server/provider"
@@ -44,6 +47,8 @@ func NewManager(configs map[string]*runtime.ServiceInfo, defaultRoundTripper htt
 		defaultRoundTripper: defaultRoundTripper,
 		balancers:           make(map[string]healthcheck.Balancers),
 		configs:             configs,
+		affinityTable:       affinity.NewMemoryTable(),
+		egressTransports:    newEgressTransports(nil),
 	}
 }
 
@@ -59,6 +64,16 @@ type Manager struct {
 	// which is why there is not just one Balancer per service name.
 	balancers map[string]healthcheck.Balancers
 	configs   map[string]*runtime.ServiceInfo
+	// weights, if not nil, tracks the weighted balancers whose weights can be adjusted at runtime.
+	weights *wrr.Weights
+	// egressTransports holds the round trippers of the egress destinations, shared across the configurations.
+	egressTransports *egressTransports
+	// roundTrippers are the round trippers of the named servers transports, selected by the load-balancers.
+	roundTrippers map[string]http.RoundTripper
+	// affinityTable maps the clients to the servers of the sticky sessions without cookies.
+	affinityTable affinity.Table
+	// drains, if not nil, tracks the servers removed from the sticky load-balancers which are still drained.
+	drains *serverDrains
 }
 
 // BuildHTTP Creates a http.Handler for a service configuration.
@@ -110,6 +125,13 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 			conf.AddError(err, true)
 			return nil, err
 		}
+	case conf.Egress != nil:
+		var err error
+		lb, err = m.getEgressServiceHandler(ctx, serviceName, conf.Egress)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
 	default:
 		sErr := fmt.Errorf("the service %q does not have any type defined", serviceName)
 		conf.AddError(sErr, true)
@@ -157,8 +179,17 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 			return nil, err
 		}
 
+		if m.weights != nil {
+			serviceHandler = m.weights.Instrument(serviceName, service.Name, serviceHandler)
+		}
+
 		balancer.AddService(service.Name, serviceHandler, service.Weight)
 	}
+
+	if m.weights != nil {
+		m.weights.Register(serviceName, balancer)
+	}
+
 	return balancer, nil
 }
 
@@ -168,17 +199,27 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		service.PassHostHeader = &defaultPassHostHeader
 	}
 
-	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, m.defaultRoundTripper, m.bufferPool)
+	fwd, err := m.buildForwarder(service, m.defaultRoundTripper)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(service.ServersTransports) > 0 {
+		fwd, err = m.buildTransportSelector(serviceName, service, fwd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	alHandler := func(next http.Handler) (http.Handler, error) {
 		return accesslog.NewFieldHandler(next, accesslog.ServiceName, serviceName, accesslog.AddServiceFields), nil
 	}
 	chain := alice.New()
 	if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
-		chain = chain.Append(metricsMiddle.WrapServiceHandler(ctx, m.metricsRegistry, serviceName))
+		chain = chain.Append(metricsMiddle.WrapServiceHandler(ctx, m.metricsRegistry, serviceName, service.PathNormalization))
+	}
+	if m.metricsRegistry != nil && m.metricsRegistry.IsSvcTCPInfoEnabled() {
+		chain = chain.Append(metricsMiddle.WrapServiceTCPInfoHandler(ctx, m.metricsRegistry, serviceName))
 	}
 
 	handler, err := chain.Append(alHandler).Then(pipelining.New(ctx, fwd, "pipelining"))
@@ -195,7 +236,27 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
 	// Empty (backend with no servers)
-	return emptybackendhandler.New(balancer), nil
+	lbHandler := emptybackendhandler.New(balancer)
+
+	if service.Coalescing != nil {
+		lbHandler = coalescing.New(ctx, lbHandler, *service.Coalescing, serviceName)
+	}
+
+	if service.QoS != nil {
+		return qos.New(ctx, lbHandler, *service.QoS, serviceName)
+	}
+
+	return lbHandler, nil
+}
+
+// buildForwarder creates the handler forwarding the requests to the servers with the round tripper.
+func (m *Manager) buildForwarder(service *dynamic.ServersLoadBalancer, roundTripper http.RoundTripper) (http.Handler, error) {
+	host, err := newHostHeader(service.PassHostHeader, service.HostHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildProxy(host, service.ResponseForwarding, roundTripper, m.bufferPool)
 }
 
 // LaunchHealthCheck Launches the health checks.
@@ -287,6 +348,27 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 
 	var options []roundrobin.LBOption
 
+	var sticky *affinitySticky
+	if service.Sticky != nil && service.Sticky.Affinity != nil {
+		if service.Sticky.Cookie != nil {
+			return nil, errors.New("the sticky sessions cannot be based on both a cookie and the affinity table")
+		}
+
+		sticky = &affinitySticky{
+			serviceName: serviceName,
+			header:      service.Sticky.Affinity.Header,
+			ttl:         time.Duration(service.Sticky.Affinity.TTL),
+			table:       m.affinityTable,
+		}
+		if sticky.ttl <= 0 {
+			sticky.ttl = time.Hour
+		}
+
+		fwd = &affinityRecorder{next: fwd, sticky: sticky}
+
+		logger.Debugf("Sticky sessions based on the affinity table, with the TTL %s", sticky.ttl)
+	}
+
 	var cookieName string
 	if service.Sticky != nil && service.Sticky.Cookie != nil {
 		cookieName = cookie.GetName(service.Sticky.Cookie.Name, serviceName)
@@ -307,11 +389,25 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 		return nil, err
 	}
 
-	lbsu := healthcheck.NewLBStatusUpdater(lb, m.configs[serviceName])
+	var balancer healthcheck.BalancerHandler = lb
+	if m.drains != nil {
+		if deadlines := m.drains.get(serviceName); len(deadlines) > 0 {
+			balancer, err = newDrainingBalancer(ctx, lb, deadlines)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	lbsu := healthcheck.NewLBStatusUpdater(balancer, m.configs[serviceName])
 	if err := m.upsertServers(ctx, lbsu, service.Servers); err != nil {
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %w", serviceName, err)
 	}
 
+	if sticky != nil {
+		return &affinityBalancer{BalancerHandler: lbsu, sticky: sticky, recorder: fwd}, nil
+	}
+
 	return lbsu, nil
 }
 
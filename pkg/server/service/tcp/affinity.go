@@ -0,0 +1,81 @@
+package tcp
+
+import (
+	"net"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tcp"
+)
+
+// affinityServer records itself as the server of the client in the affinity table, before handling the connection.
+// Recording it on each connection extends the lifetime of the entry.
+type affinityServer struct {
+	tcp.Handler
+	address  string
+	balancer *affinityBalancer
+}
+
+func (s *affinityServer) ServeTCP(conn tcp.WriteCloser) {
+	if key := s.balancer.key(conn); key != "" {
+		if err := s.balancer.table.Set(key, s.address, s.balancer.ttl); err != nil {
+			log.WithoutContext().Debugf("Unable to record the server of the client in the affinity table: %v", err)
+		}
+	}
+
+	s.Handler.ServeTCP(conn)
+}
+
+// affinityBalancer forwards the connections to the server recorded for their client in the affinity table,
+// the clients being identified by their IP address.
+// The connections of the new clients are load-balanced.
+type affinityBalancer struct {
+	serviceName string
+	ttl         time.Duration
+	table       affinity.Table
+	servers     map[string]*affinityServer
+	next        tcp.Handler
+}
+
+func newAffinityBalancer(serviceName string, ttl time.Duration, table affinity.Table) *affinityBalancer {
+	return &affinityBalancer{
+		serviceName: serviceName,
+		ttl:         ttl,
+		table:       table,
+		servers:     make(map[string]*affinityServer),
+	}
+}
+
+// server wraps the handler of a server, so it records itself in the affinity table.
+func (b *affinityBalancer) server(address string, handler tcp.Handler) tcp.Handler {
+	s := &affinityServer{Handler: handler, address: address, balancer: b}
+	b.servers[address] = s
+	return s
+}
+
+func (b *affinityBalancer) key(conn tcp.WriteCloser) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return ""
+	}
+
+	// The key is prefixed, so it does not collide with the one of an HTTP service with the same name.
+	return affinity.Key("tcp/"+b.serviceName, host)
+}
+
+func (b *affinityBalancer) ServeTCP(conn tcp.WriteCloser) {
+	if key := b.key(conn); key != "" {
+		address, err := b.table.Get(key)
+		if err != nil {
+			log.WithoutContext().Debugf("Unable to get the server of the client from the affinity table: %v", err)
+		}
+
+		if server, ok := b.servers[address]; ok {
+			server.ServeTCP(conn)
+			return
+		}
+	}
+
+	b.next.ServeTCP(conn)
+}
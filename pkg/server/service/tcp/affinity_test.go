@@ -0,0 +1,64 @@
+package tcp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/containous/traefik/v2/pkg/tcp"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+func (f *fakeConn) RemoteAddr() net.Addr {
+	return f.remoteAddr
+}
+
+func (f *fakeConn) CloseWrite() error {
+	return nil
+}
+
+func TestAffinityBalancer(t *testing.T) {
+	table := affinity.NewMemoryTable()
+
+	calls := make(map[string]int)
+	newServer := func(address string) tcp.Handler {
+		return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+			calls[address]++
+		})
+	}
+
+	// Another instance shares the same table and servers.
+	var balancers []*affinityBalancer
+	for i := 0; i < 2; i++ {
+		balancer := newAffinityBalancer("foo@file", time.Hour, table)
+
+		loadBalancer := tcp.NewWRRLoadBalancer()
+		for _, address := range []string{"10.0.0.1:80", "10.0.0.2:80"} {
+			loadBalancer.AddServer(balancer.server(address, newServer(address)))
+		}
+		balancer.next = loadBalancer
+
+		balancers = append(balancers, balancer)
+	}
+
+	clientA := &fakeConn{remoteAddr: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 1234}}
+	clientB := &fakeConn{remoteAddr: &net.TCPAddr{IP: net.ParseIP("192.168.1.2"), Port: 1234}}
+
+	balancers[0].ServeTCP(clientA)
+	balancers[0].ServeTCP(clientB)
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 1}, calls)
+
+	// The connections of the clients, whatever the instance and the port of the client,
+	// are forwarded to the server of their first connection.
+	for i := 0; i < 3; i++ {
+		balancers[1].ServeTCP(clientA)
+		balancers[0].ServeTCP(&fakeConn{remoteAddr: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 4321}})
+	}
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 7, "10.0.0.2:80": 1}, calls)
+}
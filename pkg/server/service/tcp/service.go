@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/affinity"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/server/provider"
@@ -15,13 +16,20 @@ import (
 
 // Manager is the TCPHandlers factory.
 type Manager struct {
-	configs map[string]*runtime.TCPServiceInfo
+	configs       map[string]*runtime.TCPServiceInfo
+	affinityTable affinity.Table
 }
 
 // NewManager creates a new manager.
-func NewManager(conf *runtime.Configuration) *Manager {
+// The affinity table of the sticky services is local to the manager when affinityTable is nil.
+func NewManager(conf *runtime.Configuration, affinityTable affinity.Table) *Manager {
+	if affinityTable == nil {
+		affinityTable = affinity.NewMemoryTable()
+	}
+
 	return &Manager{
-		configs: conf.TCPServices,
+		configs:       conf.TCPServices,
+		affinityTable: affinityTable,
 	}
 }
 
@@ -53,6 +61,15 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 		}
 		duration := time.Duration(*conf.LoadBalancer.TerminationDelay) * time.Millisecond
 
+		var sticky *affinityBalancer
+		if conf.LoadBalancer.Sticky != nil && conf.LoadBalancer.Sticky.Affinity != nil {
+			ttl := time.Duration(conf.LoadBalancer.Sticky.Affinity.TTL)
+			if ttl <= 0 {
+				ttl = time.Hour
+			}
+			sticky = newAffinityBalancer(serviceQualifiedName, ttl, m.affinityTable)
+		}
+
 		for name, server := range conf.LoadBalancer.Servers {
 			if _, _, err := net.SplitHostPort(server.Address); err != nil {
 				logger.Errorf("In service %q: %v", serviceQualifiedName, err)
@@ -65,9 +82,18 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			if sticky != nil {
+				loadBalancer.AddServer(sticky.server(server.Address, handler))
+			} else {
+				loadBalancer.AddServer(handler)
+			}
 			logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
 		}
+
+		if sticky != nil {
+			sticky.next = loadBalancer
+			return sticky, nil
+		}
 		return loadBalancer, nil
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
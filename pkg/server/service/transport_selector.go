@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/rules"
+)
+
+type serverURLKey struct{}
+
+// buildTransportSelector creates the handler forwarding each request with the named servers transport
+// of the first selector whose rule matches it, and with the default forwarder when none matches.
+// The rules are the ones of the routers, e.g. ClientIP(`10.0.0.0/8`) || Headers(`X-Internal`, `true`).
+func (m *Manager) buildTransportSelector(serviceName string, service *dynamic.ServersLoadBalancer, defaultFwd http.Handler) (http.Handler, error) {
+	router, err := rules.NewRouter()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, selector := range service.ServersTransports {
+		roundTripper, ok := m.roundTrippers[selector.Name]
+		if !ok {
+			return nil, fmt.Errorf("the servers transport %q does not exist", selector.Name)
+		}
+
+		fwd, err := m.buildForwarder(service, roundTripper)
+		if err != nil {
+			return nil, err
+		}
+
+		// The priorities keep the selectors in their declaration order.
+		if err := router.AddRoute(selector.Rule, len(service.ServersTransports)-i, withServerURL(fwd)); err != nil {
+			return nil, fmt.Errorf("invalid rule of the servers transport %q: %w", selector.Name, err)
+		}
+	}
+
+	router.SortRoutes()
+	router.NotFoundHandler = withServerURL(defaultFwd)
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		router.ServeHTTP(rw, withClientURL(req))
+	}), nil
+}
+
+// withClientURL returns the request with the path and query of the client, matched by the rules.
+// Behind the load-balancer, the URL of the request is the one of the server,
+// and the path and query of the client are in the request URI, like in the Director of the proxy.
+// The URL of the server is kept in the context, for withServerURL.
+func withClientURL(req *http.Request) *http.Request {
+	if req.RequestURI == "" {
+		return req
+	}
+
+	parsedURL, err := url.ParseRequestURI(req.RequestURI)
+	if err != nil {
+		return req
+	}
+
+	u := *req.URL
+	u.Path = parsedURL.Path
+	u.RawPath = parsedURL.RawPath
+	u.RawQuery = parsedURL.RawQuery
+
+	clientReq := req.WithContext(context.WithValue(req.Context(), serverURLKey{}, req.URL))
+	clientReq.URL = &u
+
+	return clientReq
+}
+
+// withServerURL forwards the requests with the URL of the server, set back from the context.
+func withServerURL(fwd http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if serverURL, ok := req.Context().Value(serverURLKey{}).(*url.URL); ok {
+			req = req.WithContext(req.Context())
+			req.URL = serverURL
+		}
+
+		fwd.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoadBalancerServiceHandler_ServersTransports(t *testing.T) {
+	// The server requires a client certificate, only presented by the hardened transport.
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	certPEM, keyPEM, err := generate.KeyPair("client.example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	hardened, err := createRoundtripper(&static.ServersTransport{
+		InsecureSkipVerify: true,
+		Certificates: traefiktls.Certificates{
+			{CertFile: traefiktls.FileOrContent(certPEM), KeyFile: traefiktls.FileOrContent(keyPEM)},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	internal, err := createRoundtripper(&static.ServersTransport{InsecureSkipVerify: true}, false)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		url            string
+		remoteAddr     string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			desc:           "internal client",
+			url:            "http://callme",
+			remoteAddr:     "10.0.0.1:1234",
+			headers:        map[string]string{"X-Client": "external"},
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			desc:           "external client",
+			url:            "http://callme",
+			remoteAddr:     "192.0.2.1:1234",
+			headers:        map[string]string{"X-Client": "external"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "path of the client",
+			url:            "http://callme/external/foo",
+			remoteAddr:     "192.0.2.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "query of the client",
+			url:            "http://callme/foo?client=external",
+			remoteAddr:     "192.0.2.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "default transport",
+			url:            "http://callme/foo",
+			remoteAddr:     "192.0.2.1:1234",
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(nil, internal, nil, nil)
+			manager.roundTrippers = map[string]http.RoundTripper{
+				"hardened": hardened,
+				"internal": internal,
+			}
+
+			handler, err := manager.getLoadBalancerServiceHandler(context.Background(), "test", &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{{URL: server.URL}},
+				ServersTransports: []dynamic.ServersTransportSelector{
+					{Name: "internal", Rule: "ClientIP(`10.0.0.0/8`)"},
+					{Name: "hardened", Rule: "Headers(`X-Client`, `external`)"},
+					{Name: "hardened", Rule: "PathPrefix(`/external`) || Query(`client=external`)"},
+				},
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			req.RemoteAddr = test.remoteAddr
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+		})
+	}
+}
+
+func TestGetLoadBalancerServiceHandler_InvalidServersTransports(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		selector dynamic.ServersTransportSelector
+	}{
+		{
+			desc:     "unknown servers transport",
+			selector: dynamic.ServersTransportSelector{Name: "unknown", Rule: "ClientIP(`10.0.0.0/8`)"},
+		},
+		{
+			desc:     "invalid rule",
+			selector: dynamic.ServersTransportSelector{Name: "internal", Rule: "ClientIP(`10.0.0.0/99`)"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(nil, http.DefaultTransport, nil, nil)
+			manager.roundTrippers = map[string]http.RoundTripper{"internal": http.DefaultTransport}
+
+			_, err := manager.getLoadBalancerServiceHandler(context.Background(), "test", &dynamic.ServersLoadBalancer{
+				Servers:           []dynamic.Server{{URL: "http://10.0.0.1"}},
+				ServersTransports: []dynamic.ServersTransportSelector{test.selector},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
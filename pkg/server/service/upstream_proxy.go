@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// connectTimeout is the maximum duration of the CONNECT handshakes with the HTTP proxies.
+const connectTimeout = 10 * time.Second
+
+type dialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newProxyDialContext returns the dial function tunneling the connections to the servers through the upstream proxy,
+// a SOCKS5 proxy (socks5:// URL) or an HTTP proxy supporting the CONNECT method (http:// URL).
+// The proxy credentials are given in the userinfo of the URL.
+func newProxyDialContext(proxyURL string, dialer *net.Dialer) (dialContextFunc, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", proxyURL)
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		socksDialer, err := proxy.FromURL(u, dialer)
+		if err != nil {
+			return nil, err
+		}
+
+		contextDialer, ok := socksDialer.(proxy.ContextDialer)
+		if !ok {
+			return nil, errors.New("the SOCKS5 dialer does not support contexts")
+		}
+
+		return contextDialer.DialContext, nil
+
+	case "http":
+		address := u.Host
+		if u.Port() == "" {
+			address = net.JoinHostPort(u.Hostname(), "80")
+		}
+
+		connectDialer := &connectDialer{dialer: dialer, address: address, timeout: connectTimeout}
+		if u.User != nil {
+			password, _ := u.User.Password()
+			connectDialer.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+password))
+		}
+
+		return connectDialer.DialContext, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+// connectDialer opens the connections through an HTTP proxy with the CONNECT method.
+type connectDialer struct {
+	dialer        *net.Dialer
+	address       string
+	authorization string
+	timeout       time.Duration
+}
+
+func (d *connectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, d.address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The handshake is bounded by the timeout, and by the deadline of the context if it comes first.
+	deadline := time.Now().Add(d.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	// The connection is closed when the context is canceled during the handshake.
+	done := make(chan struct{})
+	canceled := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+			canceled <- true
+		case <-done:
+			canceled <- false
+		}
+	}()
+
+	tunnel, err := d.handshake(conn, addr)
+
+	close(done)
+	if <-canceled {
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return tunnel, nil
+}
+
+// handshake sends the CONNECT request of the address on the connection to the proxy, and reads its response.
+func (d *connectDialer) handshake(conn net.Conn, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.authorization != "" {
+		req.Header.Set("Proxy-Authorization", d.authorization)
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("unable to send the CONNECT request to the proxy: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CONNECT response of the proxy: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the proxy refused to connect to %s: %s", addr, resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were read along with the CONNECT response.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// NetConn returns the underlying connection.
+func (c *bufferedConn) NetConn() net.Conn {
+	return c.Conn
+}
@@ -7,7 +7,6 @@ import (
 	"io"
 	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/log"
@@ -92,7 +91,7 @@ func (r *Router) AddRoute(sniHost string, target Handler) {
 	if r.routingTable == nil {
 		r.routingTable = map[string]Handler{}
 	}
-	r.routingTable[strings.ToLower(sniHost)] = target
+	r.routingTable[types.CanonicalDomain(sniHost)] = target
 }
 
 // AddRouteTLS defines a handler for a given sniHost and sets the matching tlsConfig.
@@ -0,0 +1,127 @@
+// Package tcpinfo reads the statistics maintained by the kernel on the TCP connections.
+package tcpinfo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrNotSupported is returned when the TCP statistics are not available on the platform.
+var ErrNotSupported = errors.New("the TCP statistics are not supported on this platform")
+
+// Info holds the statistics of a TCP connection.
+type Info struct {
+	// RTT is the smoothed round trip time.
+	RTT time.Duration
+	// TotalRetransmits is the number of segments retransmitted since the connection was established.
+	TotalRetransmits uint32
+	// DeliveryRate is the most recent goodput, in bytes per second, or 0 when the kernel does not report it.
+	DeliveryRate uint64
+}
+
+// conns holds the Conns currently open, by their addresses,
+// to find them from the connections wrapping them, e.g. the TLS connections of the HTTP transport.
+var conns sync.Map
+
+// connKey identifies a TCP connection by its local and remote addresses.
+type connKey struct {
+	local  string
+	remote string
+}
+
+func keyOf(conn net.Conn) (connKey, bool) {
+	local, remote := conn.LocalAddr(), conn.RemoteAddr()
+	if local == nil || remote == nil {
+		return connKey{}, false
+	}
+
+	return connKey{local: local.String(), remote: remote.String()}, true
+}
+
+// Conn is a TCP connection keeping track of the retransmissions already reported.
+type Conn struct {
+	net.Conn
+
+	mu               sync.Mutex
+	totalRetransmits uint32
+}
+
+// Stats returns the current statistics of the connection,
+// with the number of segments retransmitted since the previous call.
+func (c *Conn) Stats() (Info, uint32, error) {
+	info, err := getInfo(c.Conn)
+	if err != nil {
+		return Info{}, 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var retransmits uint32
+	if info.TotalRetransmits > c.totalRetransmits {
+		retransmits = info.TotalRetransmits - c.totalRetransmits
+	}
+	c.totalRetransmits = info.TotalRetransmits
+
+	return info, retransmits, nil
+}
+
+// Close closes the connection, and forgets it.
+func (c *Conn) Close() error {
+	if key, ok := keyOf(c.Conn); ok {
+		if v, ok := conns.Load(key); ok && v == c {
+			conns.Delete(key)
+		}
+	}
+
+	return c.Conn.Close()
+}
+
+// WrapDialContext wraps the TCP connections returned by the given dial function into a Conn.
+func WrapDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := conn.(*net.TCPConn); !ok {
+			return conn, nil
+		}
+
+		c := &Conn{Conn: conn}
+		if key, ok := keyOf(conn); ok {
+			conns.Store(key, c)
+		}
+
+		return c, nil
+	}
+}
+
+// FromConn returns the Conn the given connection is, or wraps, e.g. as a TLS connection.
+// The wrapping connections are matched by their addresses,
+// as the connections they wrap are not always exposed (e.g. by tls.Conn before Go 1.18).
+func FromConn(conn net.Conn) (*Conn, bool) {
+	if conn == nil {
+		return nil, false
+	}
+
+	if c, ok := conn.(*Conn); ok {
+		return c, true
+	}
+
+	key, ok := keyOf(conn)
+	if !ok {
+		return nil, false
+	}
+
+	v, ok := conns.Load(key)
+	if !ok {
+		return nil, false
+	}
+
+	return v.(*Conn), true
+}
@@ -0,0 +1,94 @@
+package tcpinfo
+
+import (
+	"net"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// rawInfo is the beginning of the tcp_info structure of the Linux kernel, up to the delivery rate.
+type rawInfo struct {
+	State         uint8
+	CAState       uint8
+	Retransmits   uint8
+	Probes        uint8
+	Backoff       uint8
+	Options       uint8
+	WScale        uint8
+	Flags         uint8
+	RTO           uint32
+	ATO           uint32
+	SndMSS        uint32
+	RcvMSS        uint32
+	Unacked       uint32
+	Sacked        uint32
+	Lost          uint32
+	Retrans       uint32
+	Fackets       uint32
+	LastDataSent  uint32
+	LastAckSent   uint32
+	LastDataRecv  uint32
+	LastAckRecv   uint32
+	PMTU          uint32
+	RcvSSThresh   uint32
+	RTT           uint32
+	RTTVar        uint32
+	SndSSThresh   uint32
+	SndCwnd       uint32
+	AdvMSS        uint32
+	Reordering    uint32
+	RcvRTT        uint32
+	RcvSpace      uint32
+	TotalRetrans  uint32
+	PacingRate    uint64
+	MaxPacingRate uint64
+	BytesAcked    uint64
+	BytesReceived uint64
+	SegsOut       uint32
+	SegsIn        uint32
+	NotsentBytes  uint32
+	MinRTT        uint32
+	DataSegsIn    uint32
+	DataSegsOut   uint32
+	DeliveryRate  uint64
+}
+
+func getInfo(conn net.Conn) (Info, error) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return Info{}, ErrNotSupported
+	}
+
+	rawConn, err := sc.SyscallConn()
+	if err != nil {
+		return Info{}, err
+	}
+
+	var raw rawInfo
+	size := uint32(unsafe.Sizeof(raw))
+
+	var errno syscall.Errno
+	err = rawConn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall6(syscall.SYS_GETSOCKOPT, fd, syscall.IPPROTO_TCP, syscall.TCP_INFO,
+			uintptr(unsafe.Pointer(&raw)), uintptr(unsafe.Pointer(&size)), 0)
+	})
+	if err != nil {
+		return Info{}, err
+	}
+	if errno != 0 {
+		return Info{}, errno
+	}
+
+	info := Info{
+		RTT:              time.Duration(raw.RTT) * time.Microsecond,
+		TotalRetransmits: raw.TotalRetrans,
+	}
+
+	// Older kernels fill only the beginning of the structure.
+	if uintptr(size) >= unsafe.Offsetof(raw.DeliveryRate)+unsafe.Sizeof(raw.DeliveryRate) {
+		info.DeliveryRate = raw.DeliveryRate
+	}
+
+	return info, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package tcpinfo
+
+import "net"
+
+func getInfo(_ net.Conn) (Info, error) {
+	return Info{}, ErrNotSupported
+}
@@ -0,0 +1,89 @@
+package tcpinfo
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConn_Stats(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("hello"))
+	}()
+
+	dialer := &net.Dialer{}
+	conn, err := WrapDialContext(dialer.DialContext)(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	buf := make([]byte, 5)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+
+	tcpConn, ok := FromConn(conn)
+	require.True(t, ok)
+
+	info, retransmits, err := tcpConn.Stats()
+	if runtime.GOOS != "linux" {
+		assert.Equal(t, ErrNotSupported, err)
+		return
+	}
+
+	require.NoError(t, err)
+	assert.Greater(t, int64(info.RTT), int64(0))
+	assert.Equal(t, uint32(0), retransmits)
+}
+
+func TestFromConn(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		buf := make([]byte, 1)
+		_, _ = conn.Read(buf)
+	}()
+
+	dialer := &net.Dialer{}
+	conn, err := WrapDialContext(dialer.DialContext)(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	got, ok := FromConn(conn)
+	require.True(t, ok)
+	assert.Same(t, conn, got)
+
+	// The TLS connection is matched with the TCP connection it wraps, without unwrapping it.
+	got, ok = FromConn(tls.Client(conn, &tls.Config{}))
+	require.True(t, ok)
+	assert.Same(t, conn, got)
+
+	require.NoError(t, conn.Close())
+
+	_, ok = FromConn(tls.Client(conn, &tls.Config{}))
+	assert.False(t, ok)
+
+	client, server := net.Pipe()
+	defer func() { _ = server.Close() }()
+
+	_, ok = FromConn(client)
+	assert.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 func BuildConfiguration(dynamicConfigBuilders ...func(*dynamic.HTTPConfiguration)) *dynamic.HTTPConfiguration {
 	conf := &dynamic.HTTPConfiguration{
 		Models: map[string]*dynamic.Model{},
+		IPSets: map[string]*dynamic.IPSet{},
 	}
 
 	for _, build := range dynamicConfigBuilders {
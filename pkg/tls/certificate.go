@@ -11,6 +11,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/tls/generate"
+	"github.com/containous/traefik/v2/pkg/types"
 )
 
 var (
@@ -150,13 +151,13 @@ func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certifi
 
 	var SANs []string
 	if parsedCert.Subject.CommonName != "" {
-		SANs = append(SANs, strings.ToLower(parsedCert.Subject.CommonName))
+		SANs = append(SANs, types.CanonicalDomain(parsedCert.Subject.CommonName))
 	}
 	if parsedCert.DNSNames != nil {
 		sort.Strings(parsedCert.DNSNames)
 		for _, dnsName := range parsedCert.DNSNames {
 			if dnsName != parsedCert.Subject.CommonName {
-				SANs = append(SANs, strings.ToLower(dnsName))
+				SANs = append(SANs, types.CanonicalDomain(dnsName))
 			}
 		}
 	}
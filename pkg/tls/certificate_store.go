@@ -1,6 +1,9 @@
 package tls
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"net"
@@ -10,9 +13,27 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/patrickmn/go-cache"
 )
 
+// Strategies selecting the certificate among the ones matching the server name.
+const (
+	// SelectionMostSpecific selects the certificate of the most specific domain, e.g. foo.example.com before *.example.com.
+	SelectionMostSpecific = "mostSpecific"
+	// SelectionPreferECDSA selects an ECDSA certificate, when the client supports it.
+	SelectionPreferECDSA = "preferECDSA"
+	// SelectionPreferLargestKey selects the certificate with the largest key supported by the client.
+	SelectionPreferLargestKey = "preferLargestKey"
+	// SelectionPreferNearestExpiry selects the certificate expiring first, among the ones supported by the client.
+	SelectionPreferNearestExpiry = "preferNearestExpiry"
+)
+
+// CertificateSelections returns the strategies selecting the certificate.
+func CertificateSelections() []string {
+	return []string{SelectionMostSpecific, SelectionPreferECDSA, SelectionPreferLargestKey, SelectionPreferNearestExpiry}
+}
+
 // CertificateStore store for dynamic certificates.
 type CertificateStore struct {
 	DynamicCerts       *safe.Safe
@@ -69,7 +90,13 @@ func (c CertificateStore) GetAllDomains() []string {
 
 // GetBestCertificate returns the best match certificate, and caches the response.
 func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *tls.Certificate {
-	domainToCheck := strings.ToLower(strings.TrimSpace(clientHello.ServerName))
+	return c.SelectCertificate(clientHello, SelectionMostSpecific)
+}
+
+// SelectCertificate returns the certificate matching the server name preferred by the selection strategy,
+// among the ones supported by the client, and caches the matching certificates.
+func (c CertificateStore) SelectCertificate(clientHello *tls.ClientHelloInfo, selection string) *tls.Certificate {
+	domainToCheck := types.CanonicalDomain(clientHello.ServerName)
 	if len(domainToCheck) == 0 {
 		// If no ServerName is provided, Check for local IP address matches
 		host, _, err := net.SplitHostPort(clientHello.Conn.LocalAddr().String())
@@ -79,35 +106,138 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 		domainToCheck = strings.TrimSpace(host)
 	}
 
-	if cert, ok := c.CertCache.Get(domainToCheck); ok {
-		return cert.(*tls.Certificate)
+	if selection == "" {
+		selection = SelectionMostSpecific
 	}
 
-	matchedCerts := map[string]*tls.Certificate{}
+	candidates := c.getCandidates(domainToCheck, selection)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	if selection == SelectionMostSpecific {
+		return candidates[0]
+	}
+
+	for _, cert := range candidates {
+		if clientHello.SupportsCertificate(cert) == nil {
+			return cert
+		}
+	}
+
+	// The client may support none of them only because its hello is incomplete.
+	return candidates[0]
+}
+
+// getCandidates returns the certificates matching the domain, in the order of preference of the selection strategy,
+// and caches them.
+func (c CertificateStore) getCandidates(domainToCheck, selection string) []*tls.Certificate {
+	cacheKey := selection + "/" + domainToCheck
+	if certs, ok := c.CertCache.Get(cacheKey); ok {
+		return certs.([]*tls.Certificate)
+	}
+
+	// Several certificates may match the domain with the same certificate domain, e.g. an RSA and an ECDSA one.
+	type match struct {
+		certDomain string
+		domains    string
+		cert       *tls.Certificate
+	}
+
+	var matches []match
 	if c.DynamicCerts != nil && c.DynamicCerts.Get() != nil {
 		for domains, cert := range c.DynamicCerts.Get().(map[string]*tls.Certificate) {
 			for _, certDomain := range strings.Split(domains, ",") {
 				if MatchDomain(domainToCheck, certDomain) {
-					matchedCerts[certDomain] = cert
+					matches = append(matches, match{certDomain: certDomain, domains: domains, cert: cert})
 				}
 			}
 		}
 	}
 
-	if len(matchedCerts) > 0 {
-		// sort map by keys
-		keys := make([]string, 0, len(matchedCerts))
-		for k := range matchedCerts {
-			keys = append(keys, k)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// sort by certificate domain, the most specific first
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].certDomain != matches[j].certDomain {
+			return matches[i].certDomain > matches[j].certDomain
 		}
-		sort.Strings(keys)
+		return matches[i].domains < matches[j].domains
+	})
 
-		// cache best match
-		c.CertCache.SetDefault(domainToCheck, matchedCerts[keys[len(keys)-1]])
-		return matchedCerts[keys[len(keys)-1]]
+	var candidates []candidate
+	seen := make(map[*tls.Certificate]struct{})
+	for _, m := range matches {
+		if _, ok := seen[m.cert]; ok {
+			continue
+		}
+		seen[m.cert] = struct{}{}
+
+		candidates = append(candidates, newCandidate(m.cert))
+	}
+
+	if selection != SelectionMostSpecific {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].preferredTo(candidates[j], selection)
+		})
+	}
+
+	certs := make([]*tls.Certificate, len(candidates))
+	for i, cand := range candidates {
+		certs[i] = cand.cert
+	}
+
+	c.CertCache.SetDefault(cacheKey, certs)
+	return certs
+}
+
+// candidate is a certificate matching the server name, with its parsed leaf, if valid.
+type candidate struct {
+	cert *tls.Certificate
+	leaf *x509.Certificate
+}
+
+func newCandidate(cert *tls.Certificate) candidate {
+	leaf := cert.Leaf
+	if leaf == nil && len(cert.Certificate) > 0 {
+		leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	}
+
+	return candidate{cert: cert, leaf: leaf}
+}
+
+// preferredTo reports whether the candidate is preferred to the other one by the selection strategy.
+func (c candidate) preferredTo(other candidate, selection string) bool {
+	if c.leaf == nil || other.leaf == nil {
+		return c.leaf != nil
 	}
 
-	return nil
+	switch selection {
+	case SelectionPreferECDSA:
+		return c.leaf.PublicKeyAlgorithm == x509.ECDSA && other.leaf.PublicKeyAlgorithm != x509.ECDSA
+	case SelectionPreferLargestKey:
+		return keySize(c.leaf) > keySize(other.leaf)
+	case SelectionPreferNearestExpiry:
+		return c.leaf.NotAfter.Before(other.leaf.NotAfter)
+	default:
+		return false
+	}
+}
+
+// keySize returns the size, in bits, of the public key of the certificate.
+func keySize(cert *x509.Certificate) int {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	default:
+		return 0
+	}
 }
 
 // ResetCache clears the cache in the store.
@@ -1,8 +1,16 @@
 package tls
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -89,6 +97,117 @@ func TestGetBestCertificate(t *testing.T) {
 	}
 }
 
+func TestSelectCertificate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaCert := createTestCert(t, rsaKey, []string{"foo.com"}, time.Now().Add(90*24*time.Hour))
+	ecdsaCert := createTestCert(t, ecdsaKey, []string{"foo.com", "bar.com"}, time.Now().Add(30*24*time.Hour))
+
+	ecdsaHello := &tls.ClientHelloInfo{
+		ServerName:        "foo.com",
+		CipherSuites:      []uint16{tls.TLS_AES_128_GCM_SHA256},
+		SupportedVersions: []uint16{tls.VersionTLS13},
+		SupportedCurves:   []tls.CurveID{tls.CurveP256},
+		SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256, tls.PSSWithSHA256},
+	}
+	rsaHello := &tls.ClientHelloInfo{
+		ServerName:        "foo.com",
+		CipherSuites:      []uint16{tls.TLS_AES_128_GCM_SHA256},
+		SupportedVersions: []uint16{tls.VersionTLS13},
+		SupportedCurves:   []tls.CurveID{tls.CurveP256},
+		SignatureSchemes:  []tls.SignatureScheme{tls.PSSWithSHA256},
+	}
+
+	testCases := []struct {
+		desc         string
+		selection    string
+		clientHello  *tls.ClientHelloInfo
+		expectedCert *tls.Certificate
+	}{
+		{
+			desc:         "most specific",
+			selection:    SelectionMostSpecific,
+			clientHello:  ecdsaHello,
+			expectedCert: rsaCert,
+		},
+		{
+			desc:         "prefer ECDSA",
+			selection:    SelectionPreferECDSA,
+			clientHello:  ecdsaHello,
+			expectedCert: ecdsaCert,
+		},
+		{
+			desc:         "prefer ECDSA, not supported by the client",
+			selection:    SelectionPreferECDSA,
+			clientHello:  rsaHello,
+			expectedCert: rsaCert,
+		},
+		{
+			desc:         "prefer largest key",
+			selection:    SelectionPreferLargestKey,
+			clientHello:  ecdsaHello,
+			expectedCert: rsaCert,
+		},
+		{
+			desc:         "prefer nearest expiry",
+			selection:    SelectionPreferNearestExpiry,
+			clientHello:  ecdsaHello,
+			expectedCert: ecdsaCert,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewCertificateStore()
+			store.DynamicCerts.Set(map[string]*tls.Certificate{
+				"foo.com":         rsaCert,
+				"foo.com,bar.com": ecdsaCert,
+			})
+
+			assert.Same(t, test.expectedCert, store.SelectCertificate(test.clientHello, test.selection))
+		})
+	}
+}
+
+func TestSelectCertificate_internationalizedDomainName(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cert := createTestCert(t, key, []string{"xn--bcher-kva.example"}, time.Now().Add(90*24*time.Hour))
+
+	store := NewCertificateStore()
+	store.DynamicCerts.Set(map[string]*tls.Certificate{"xn--bcher-kva.example": cert})
+
+	for _, serverName := range []string{"xn--bcher-kva.example", "Bücher.example"} {
+		assert.Same(t, cert, store.SelectCertificate(&tls.ClientHelloInfo{ServerName: serverName}, SelectionMostSpecific), serverName)
+	}
+}
+
+func createTestCert(t *testing.T, key crypto.Signer, domains []string, notAfter time.Time) *tls.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
 func loadTestCert(certName string, uppercase bool) (*tls.Certificate, error) {
 	replacement := "wildcard"
 	if uppercase {
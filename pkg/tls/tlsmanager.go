@@ -82,6 +82,12 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 
 // Get gets the TLS configuration to use for a given store / configuration.
 func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
+	return m.GetWithSelection(storeName, configName, SelectionMostSpecific)
+}
+
+// GetWithSelection gets the TLS configuration to use for a given store / configuration,
+// selecting the certificate with the given strategy among the ones matching the server name.
+func (m *Manager) GetWithSelection(storeName, configName, selection string) (*tls.Config, error) {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
 
@@ -117,7 +123,7 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 			}
 		}
 
-		bestCertificate := store.GetBestCertificate(clientHello)
+		bestCertificate := store.SelectCertificate(clientHello, selection)
 		if bestCertificate != nil {
 			return bestCertificate, nil
 		}
@@ -141,6 +147,36 @@ func (m *Manager) getStore(storeName string) *CertificateStore {
 	return m.stores[storeName]
 }
 
+// GetCertificates returns the certificates of all the stores, including their default certificates when configured.
+func (m *Manager) GetCertificates() []*x509.Certificate {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var certificates []*x509.Certificate
+	for storeName, store := range m.stores {
+		if m.storesConfig[storeName].DefaultCertificate != nil && store.DefaultCertificate != nil {
+			if cert, err := x509.ParseCertificate(store.DefaultCertificate.Certificate[0]); err == nil {
+				certificates = append(certificates, cert)
+			}
+		}
+
+		if store.DynamicCerts == nil || store.DynamicCerts.Get() == nil {
+			continue
+		}
+
+		for _, tlsCert := range store.DynamicCerts.Get().(map[string]*tls.Certificate) {
+			cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+			if err != nil {
+				log.WithoutContext().Errorf("Could not parse certificate: %v", err)
+				continue
+			}
+			certificates = append(certificates, cert)
+		}
+	}
+
+	return certificates
+}
+
 // GetStore gets the certificate store of a given name.
 func (m *Manager) GetStore(storeName string) *CertificateStore {
 	m.lock.RLock()
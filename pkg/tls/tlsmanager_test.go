@@ -66,6 +66,35 @@ func TestTLSInStore(t *testing.T) {
 	}
 }
 
+func TestManager_GetCertificates(t *testing.T) {
+	dynamicConfigs := []*CertAndStores{{
+		Certificate: Certificate{
+			CertFile: localhostCert,
+			KeyFile:  localhostKey,
+		},
+	}}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, dynamicConfigs)
+
+	// The generated default certificate is not returned.
+	certificates := tlsManager.GetCertificates()
+	require.Len(t, certificates, 1)
+	assert.Equal(t, []string{"example.com"}, certificates[0].DNSNames)
+
+	tlsManager.UpdateConfigs(context.Background(),
+		map[string]Store{
+			"default": {
+				DefaultCertificate: &Certificate{
+					CertFile: localhostCert,
+					KeyFile:  localhostKey,
+				},
+			},
+		}, nil, dynamicConfigs)
+
+	assert.Len(t, tlsManager.GetCertificates(), 2)
+}
+
 func TestTLSInvalidStore(t *testing.T) {
 	dynamicConfigs := []*CertAndStores{{
 		Certificate: Certificate{
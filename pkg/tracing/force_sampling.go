@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/ip"
+)
+
+// ForceSamplingValue is the value of the debug header forcing the sampling of the trace of a request.
+const ForceSamplingValue = "force"
+
+// forceSampling holds the debug header forcing the sampling of the traces, and the IPs allowed to use it.
+type forceSampling struct {
+	header  string
+	checker *ip.Checker
+}
+
+// SetForceSampling allows the requests from the trusted IPs to force the sampling of their trace,
+// with the header valued with force.
+func (t *Tracing) SetForceSampling(header string, trustedIPs []string) error {
+	checker, err := ip.NewChecker(trustedIPs)
+	if err != nil {
+		return err
+	}
+
+	t.forceSampling = &forceSampling{header: header, checker: checker}
+	return nil
+}
+
+// IsSamplingForced returns whether the request forces the sampling of its trace,
+// the header being honored only from the trusted IPs.
+func (t *Tracing) IsSamplingForced(req *http.Request) bool {
+	if t.forceSampling == nil || !strings.EqualFold(req.Header.Get(t.forceSampling.header), ForceSamplingValue) {
+		return false
+	}
+
+	return t.forceSampling.checker.IsAuthorized(req.RemoteAddr) == nil
+}
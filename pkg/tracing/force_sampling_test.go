@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing_IsSamplingForced(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		header     string
+		value      string
+		expected   bool
+	}{
+		{
+			desc:       "no header",
+			remoteAddr: "10.0.0.1:1234",
+		},
+		{
+			desc:       "forced from a trusted IP",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Traefik-Trace",
+			value:      "force",
+			expected:   true,
+		},
+		{
+			desc:       "case insensitive value",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "x-traefik-trace",
+			value:      "Force",
+			expected:   true,
+		},
+		{
+			desc:       "unknown value",
+			remoteAddr: "10.0.0.1:1234",
+			header:     "X-Traefik-Trace",
+			value:      "true",
+		},
+		{
+			desc:       "forced from an untrusted IP",
+			remoteAddr: "192.168.0.1:1234",
+			header:     "X-Traefik-Trace",
+			value:      "force",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tracing := &Tracing{}
+			require.NoError(t, tracing.SetForceSampling("X-Traefik-Trace", []string{"10.0.0.0/8"}))
+
+			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+
+			assert.Equal(t, test.expected, tracing.IsSamplingForced(req))
+		})
+	}
+}
+
+func TestTracing_IsSamplingForced_disabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+	req.Header.Set("X-Traefik-Trace", "force")
+
+	assert.False(t, (&Tracing{}).IsSamplingForced(req))
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	zipkinot "github.com/openzipkin-contrib/zipkin-go-opentracing"
+	"github.com/uber/jaeger-client-go"
 )
 
 type contextKey int
@@ -48,8 +51,9 @@ type Tracing struct {
 	ServiceName   string `description:"Set the name for this service" export:"true"`
 	SpanNameLimit int    `description:"Set the maximum character limit for Span names (default 0 = no limit)" export:"true"`
 
-	tracer opentracing.Tracer
-	closer io.Closer
+	tracer        opentracing.Tracer
+	closer        io.Closer
+	forceSampling *forceSampling
 }
 
 // NewTracing Creates a Tracing.
@@ -128,6 +132,30 @@ func GetSpan(r *http.Request) opentracing.Span {
 	return opentracing.SpanFromContext(r.Context())
 }
 
+// GetTraceID returns the ID of the trace of the request, if it is sampled and its tracer exposes it.
+func GetTraceID(r *http.Request) string {
+	span := GetSpan(r)
+	if span == nil {
+		return ""
+	}
+
+	switch spanContext := span.Context().(type) {
+	case jaeger.SpanContext:
+		if spanContext.IsSampled() {
+			return spanContext.TraceID().String()
+		}
+	case zipkinot.SpanContext:
+		if spanContext.Sampled != nil && *spanContext.Sampled {
+			return spanContext.TraceID.String()
+		}
+	case interface{ TraceID() uint64 }:
+		// Datadog, whose traces are kept by the agent.
+		return strconv.FormatUint(spanContext.TraceID(), 10)
+	}
+
+	return ""
+}
+
 // InjectRequestHeaders used to inject OpenTracing headers into the request.
 func InjectRequestHeaders(r *http.Request) {
 	if span := GetSpan(r); span != nil {
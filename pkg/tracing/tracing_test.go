@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestGetTraceID(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		sampled  bool
+		noSpan   bool
+		expected bool
+	}{
+		{
+			desc:     "sampled trace",
+			sampled:  true,
+			expected: true,
+		},
+		{
+			desc: "trace not sampled",
+		},
+		{
+			desc:   "no span",
+			noSpan: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tracer, closer := jaeger.NewTracer("traefik", jaeger.NewConstSampler(test.sampled), jaeger.NewNullReporter())
+			defer func() { _ = closer.Close() }()
+
+			req := httptest.NewRequest("GET", "http://foo.bar/", nil)
+
+			var expected string
+			if !test.noSpan {
+				span := tracer.StartSpan("foo")
+				defer span.Finish()
+
+				req = req.WithContext(opentracing.ContextWithSpan(req.Context(), span))
+				if test.expected {
+					expected = span.Context().(jaeger.SpanContext).TraceID().String()
+				}
+			}
+
+			assert.Equal(t, expected, GetTraceID(req))
+		})
+	}
+}
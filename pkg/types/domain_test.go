@@ -180,3 +180,50 @@ func TestMatchDomain(t *testing.T) {
 		})
 	}
 }
+
+func TestCanonicalDomain(t *testing.T) {
+	testCases := []struct {
+		domain   string
+		expected string
+	}{
+		{domain: " Foo.Example.com ", expected: "foo.example.com"},
+		{domain: "Bücher.example", expected: "xn--bcher-kva.example"},
+		{domain: "XN--BCHER-KVA.example", expected: "xn--bcher-kva.example"},
+		{domain: "*.bücher.example", expected: "*.xn--bcher-kva.example"},
+		{domain: "ｅxample.com", expected: "example.com"},
+		{domain: "foo_bar.bücher.example.", expected: "foo_bar.xn--bcher-kva.example."},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, CanonicalDomain(test.domain))
+		})
+	}
+}
+
+func TestIsMixedScriptDomain(t *testing.T) {
+	testCases := []struct {
+		domain   string
+		expected bool
+	}{
+		{domain: "paypal.com", expected: false},
+		{domain: "bücher.example", expected: false},
+		{domain: "пример.рф", expected: false},
+		{domain: "ドメイン名例.jp", expected: false},
+		{domain: "pаypal.com", expected: true},
+		{domain: "xn--pypal-4ve.com", expected: true},
+		{domain: "αpple.com", expected: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, IsMixedScriptDomain(test.domain))
+		})
+	}
+}
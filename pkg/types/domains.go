@@ -2,6 +2,10 @@ package types
 
 import (
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
 )
 
 // +k8s:deepcopy-gen=true
@@ -50,7 +54,114 @@ func MatchDomain(domain, certDomain string) bool {
 	return false
 }
 
-// CanonicalDomain returns a lower case domain with trim space.
+// idnaProfile converts the internationalized domain names the way the browsers look them up,
+// allowing the wildcards and the underscores of the domains of the certificates and the services.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false))
+
+// CanonicalDomain returns a lower case domain with trim space,
+// the internationalized domain names being converted to their ASCII (punycode) form.
 func CanonicalDomain(domain string) string {
-	return strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if isASCII(domain) {
+		return domain
+	}
+
+	ascii, err := idnaProfile.ToASCII(domain)
+	if err != nil {
+		return domain
+	}
+
+	return ascii
+}
+
+// UnicodeDomain returns the Unicode form of the domain, whose punycode labels are decoded.
+func UnicodeDomain(domain string) string {
+	unicodeDomain, err := idnaProfile.ToUnicode(domain)
+	if err != nil {
+		return domain
+	}
+
+	return unicodeDomain
+}
+
+// compatibleScripts are the sets of scripts which are commonly mixed in a label,
+// following the highly restrictive level of the Unicode Technical Standard #39.
+var compatibleScripts = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// IsMixedScriptDomain returns whether a label of the domain, in its ASCII or Unicode form,
+// mixes letters of scripts which are not commonly used together,
+// e.g. a Cyrillic "а" in a Latin label, making the domain a lookalike of another one.
+func IsMixedScriptDomain(domain string) bool {
+	for _, label := range strings.Split(UnicodeDomain(CanonicalDomain(domain)), ".") {
+		if isASCII(label) {
+			continue
+		}
+
+		if !areCompatibleScripts(labelScripts(label)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// labelScripts returns the scripts of the letters of the label.
+func labelScripts(label string) map[string]struct{} {
+	scripts := make(map[string]struct{})
+	for _, r := range label {
+		if r < utf8.RuneSelf {
+			if unicode.IsLetter(r) {
+				scripts["Latin"] = struct{}{}
+			}
+			continue
+		}
+
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		for name, table := range unicode.Scripts {
+			if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+				scripts[name] = struct{}{}
+				break
+			}
+		}
+	}
+
+	return scripts
+}
+
+func areCompatibleScripts(scripts map[string]struct{}) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+
+	for _, compatible := range compatibleScripts {
+		matched := 0
+		for _, script := range compatible {
+			if _, ok := scripts[script]; ok {
+				matched++
+			}
+		}
+
+		if matched == len(scripts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
 }
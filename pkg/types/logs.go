@@ -1,6 +1,10 @@
 package types
 
-import "github.com/traefik/paerser/types"
+import (
+	"time"
+
+	"github.com/traefik/paerser/types"
+)
 
 const (
 	// AccessLogKeep is the keep string value.
@@ -11,6 +15,15 @@ const (
 	AccessLogRedact = "redact"
 )
 
+const (
+	// HeaderMultiValueFirst captures the first value of the headers with several values.
+	HeaderMultiValueFirst = "first"
+	// HeaderMultiValueLast captures the last value of the headers with several values.
+	HeaderMultiValueLast = "last"
+	// HeaderMultiValueJoin captures all the values of the headers with several values, joined with commas.
+	HeaderMultiValueJoin = "join"
+)
+
 const (
 	// CommonFormat is the common logging format (CLF).
 	CommonFormat string = "common"
@@ -21,9 +34,10 @@ const (
 
 // TraefikLog holds the configuration settings for the traefik logger.
 type TraefikLog struct {
-	Level    string `description:"Log level set to traefik logs." json:"level,omitempty" toml:"level,omitempty" yaml:"level,omitempty" export:"true"`
-	FilePath string `description:"Traefik log file path. Stdout is used when omitted or empty." json:"filePath,omitempty" toml:"filePath,omitempty" yaml:"filePath,omitempty"`
-	Format   string `description:"Traefik log format: json | common" json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty"`
+	Level    string    `description:"Log level set to traefik logs." json:"level,omitempty" toml:"level,omitempty" yaml:"level,omitempty" export:"true"`
+	FilePath string    `description:"Traefik log file path. Stdout is used when omitted or empty." json:"filePath,omitempty" toml:"filePath,omitempty" yaml:"filePath,omitempty"`
+	Format   string    `description:"Traefik log format: json | common" json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty"`
+	EventLog *EventLog `description:"Writes the Traefik logs to the Windows Event Log." json:"eventLog,omitempty" toml:"eventLog,omitempty" yaml:"eventLog,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -32,6 +46,16 @@ func (l *TraefikLog) SetDefaults() {
 	l.Level = "ERROR"
 }
 
+// EventLog holds the Windows Event Log configuration.
+type EventLog struct {
+	Source string `description:"Event source name of the Traefik logs." json:"source,omitempty" toml:"source,omitempty" yaml:"source,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (e *EventLog) SetDefaults() {
+	e.Source = "traefik"
+}
+
 // AccessLog holds the configuration settings for the access logger (middlewares/accesslog).
 type AccessLog struct {
 	FilePath      string            `description:"Access log file path. Stdout is used when omitted or empty." json:"filePath,omitempty" toml:"filePath,omitempty" yaml:"filePath,omitempty" export:"true"`
@@ -39,6 +63,9 @@ type AccessLog struct {
 	Filters       *AccessLogFilters `description:"Access log filters, used to keep only specific access logs." json:"filters,omitempty" toml:"filters,omitempty" yaml:"filters,omitempty" export:"true"`
 	Fields        *AccessLogFields  `description:"AccessLogFields." json:"fields,omitempty" toml:"fields,omitempty" yaml:"fields,omitempty" export:"true"`
 	BufferingSize int64             `description:"Number of access log lines to process in a buffered way." json:"bufferingSize,omitempty" toml:"bufferingSize,omitempty" yaml:"bufferingSize,omitempty" export:"true"`
+	ClickHouse    *ClickHouse       `description:"ClickHouse sink settings." json:"clickHouse,omitempty" toml:"clickHouse,omitempty" yaml:"clickHouse,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Bodies        *AccessLogBodies  `description:"Captures the request and response bodies in the access logs." json:"bodies,omitempty" toml:"bodies,omitempty" yaml:"bodies,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	GeoIP         *AccessLogGeoIP   `description:"Enriches the access logs with the country, the ASN and the proxy flags of the client IPs, from MaxMind databases." json:"geoIP,omitempty" toml:"geoIP,omitempty" yaml:"geoIP,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -50,6 +77,72 @@ func (l *AccessLog) SetDefaults() {
 	l.Fields.SetDefaults()
 }
 
+// AccessLogBodies holds the configuration of the capture of the bodies in the access logs.
+type AccessLogBodies struct {
+	MaxSize   int64          `description:"Maximum number of bytes of a body captured." json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty" export:"true"`
+	Redaction *BodyRedaction `description:"Redaction of the sensitive data of the bodies, before they are logged." json:"redaction,omitempty" toml:"redaction,omitempty" yaml:"redaction,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (b *AccessLogBodies) SetDefaults() {
+	b.MaxSize = 4096
+	b.Redaction = &BodyRedaction{}
+	b.Redaction.SetDefaults()
+}
+
+// BodyRedaction holds the redaction rules of the bodies.
+type BodyRedaction struct {
+	JSONPaths             []string `description:"Paths of the values redacted in the JSON bodies (e.g. user.password or cards.*.number)." json:"jsonPaths,omitempty" toml:"jsonPaths,omitempty" yaml:"jsonPaths,omitempty" export:"true"`
+	Patterns              []string `description:"Regular expressions matching the data redacted in the bodies." json:"patterns,omitempty" toml:"patterns,omitempty" yaml:"patterns,omitempty" export:"true"`
+	CardNumbers           bool     `description:"Redacts the payment card numbers (PAN)." json:"cardNumbers,omitempty" toml:"cardNumbers,omitempty" yaml:"cardNumbers,omitempty" export:"true"`
+	SocialSecurityNumbers bool     `description:"Redacts the US social security numbers (SSN)." json:"socialSecurityNumbers,omitempty" toml:"socialSecurityNumbers,omitempty" yaml:"socialSecurityNumbers,omitempty" export:"true"`
+	Replacement           string   `description:"Replacement of the redacted data." json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *BodyRedaction) SetDefaults() {
+	r.CardNumbers = true
+	r.SocialSecurityNumbers = true
+	r.Replacement = "REDACTED"
+}
+
+// AccessLogGeoIP holds the MaxMind databases enriching the access logs.
+type AccessLogGeoIP struct {
+	CountryDatabase     string         `description:"Path of the country database (GeoIP2-Country or GeoLite2-Country, or a City one)." json:"countryDatabase,omitempty" toml:"countryDatabase,omitempty" yaml:"countryDatabase,omitempty" export:"true"`
+	ASNDatabase         string         `description:"Path of the ASN database (GeoLite2-ASN or GeoIP2-ISP)." json:"asnDatabase,omitempty" toml:"asnDatabase,omitempty" yaml:"asnDatabase,omitempty" export:"true"`
+	AnonymousIPDatabase string         `description:"Path of the anonymous IP database (GeoIP2-Anonymous-IP), flagging the VPNs, the hosting providers, the public proxies and the Tor exit nodes." json:"anonymousIPDatabase,omitempty" toml:"anonymousIPDatabase,omitempty" yaml:"anonymousIPDatabase,omitempty" export:"true"`
+	ReloadInterval      types.Duration `description:"Interval between the checks of the database files, reloaded when they change." json:"reloadInterval,omitempty" toml:"reloadInterval,omitempty" yaml:"reloadInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (g *AccessLogGeoIP) SetDefaults() {
+	g.ReloadInterval = types.Duration(time.Hour)
+}
+
+// ClickHouse holds the configuration of the ClickHouse sink for the access logs.
+type ClickHouse struct {
+	Address       string         `description:"ClickHouse native interface address, or comma-separated addresses." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Database      string         `description:"ClickHouse database." json:"database,omitempty" toml:"database,omitempty" yaml:"database,omitempty" export:"true"`
+	Table         string         `description:"ClickHouse table." json:"table,omitempty" toml:"table,omitempty" yaml:"table,omitempty" export:"true"`
+	Username      string         `description:"ClickHouse username." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password      string         `description:"ClickHouse password." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	CreateTable   bool           `description:"Create the table if it does not exist." json:"createTable,omitempty" toml:"createTable,omitempty" yaml:"createTable,omitempty" export:"true"`
+	BatchSize     int            `description:"Maximum number of access logs inserted at once." json:"batchSize,omitempty" toml:"batchSize,omitempty" yaml:"batchSize,omitempty" export:"true"`
+	FlushInterval types.Duration `description:"Maximum time an access log waits before being inserted." json:"flushInterval,omitempty" toml:"flushInterval,omitempty" yaml:"flushInterval,omitempty" export:"true"`
+	QueueSize     int            `description:"Maximum number of access logs waiting to be inserted, the next ones are dropped." json:"queueSize,omitempty" toml:"queueSize,omitempty" yaml:"queueSize,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *ClickHouse) SetDefaults() {
+	c.Address = "localhost:9000"
+	c.Database = "default"
+	c.Table = "traefik_access_logs"
+	c.CreateTable = true
+	c.BatchSize = 1000
+	c.FlushInterval = types.Duration(5 * time.Second)
+	c.QueueSize = 10000
+}
+
 // AccessLogFilters holds filters configuration.
 type AccessLogFilters struct {
 	StatusCodes   []string       `description:"Keep access logs with status codes in the specified range." json:"statusCodes,omitempty" toml:"statusCodes,omitempty" yaml:"statusCodes,omitempty" export:"true"`
@@ -61,6 +154,21 @@ type AccessLogFilters struct {
 type FieldHeaders struct {
 	DefaultMode string            `description:"Default mode for fields: keep | drop | redact" json:"defaultMode,omitempty" toml:"defaultMode,omitempty" yaml:"defaultMode,omitempty" export:"true"`
 	Names       map[string]string `description:"Override mode for headers" json:"names,omitempty" toml:"names,omitempty" yaml:"names,omitempty" export:"true"`
+	Request     *HeaderCapture    `description:"Request headers to capture, replacing the modes for the request headers." json:"request,omitempty" toml:"request,omitempty" yaml:"request,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Response    *HeaderCapture    `description:"Response headers to capture, replacing the modes for the origin and downstream response headers." json:"response,omitempty" toml:"response,omitempty" yaml:"response,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// HeaderCapture holds the allowlist of the headers captured in the access logs.
+type HeaderCapture struct {
+	Names      []string `description:"Names of the headers to capture, the other ones being dropped." json:"names,omitempty" toml:"names,omitempty" yaml:"names,omitempty" export:"true"`
+	MaxLength  int      `description:"Maximum length of a captured value, the longer ones being truncated (0 means no limit)." json:"maxLength,omitempty" toml:"maxLength,omitempty" yaml:"maxLength,omitempty" export:"true"`
+	MultiValue string   `description:"Handling of the headers with several values: first | last | join" json:"multiValue,omitempty" toml:"multiValue,omitempty" yaml:"multiValue,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *HeaderCapture) SetDefaults() {
+	c.MaxLength = 256
+	c.MultiValue = HeaderMultiValueFirst
 }
 
 // AccessLogFields holds configuration for access log fields.
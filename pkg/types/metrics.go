@@ -12,31 +12,128 @@ type Metrics struct {
 	Datadog    *Datadog    `description:"Datadog metrics exporter type." json:"datadog,omitempty" toml:"datadog,omitempty" yaml:"datadog,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	StatsD     *Statsd     `description:"StatsD metrics exporter type." json:"statsD,omitempty" toml:"statsD,omitempty" yaml:"statsD,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 	InfluxDB   *InfluxDB   `description:"InfluxDB metrics exporter type." json:"influxDB,omitempty" toml:"influxDB,omitempty" yaml:"influxDB,omitempty" label:"allowEmpty" file:"allowEmpty"`
+	Graphite   *Graphite   `description:"Graphite metrics exporter type." json:"graphite,omitempty" toml:"graphite,omitempty" yaml:"graphite,omitempty" export:"true" label:"allowEmpty" file:"allowEmpty"`
 }
 
 // Prometheus can contain specific configuration used by the Prometheus Metrics exporter.
 type Prometheus struct {
-	Buckets              []float64 `description:"Buckets for latency metrics." json:"buckets,omitempty" toml:"buckets,omitempty" yaml:"buckets,omitempty" export:"true"`
-	AddEntryPointsLabels bool      `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
-	AddServicesLabels    bool      `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
-	EntryPoint           string    `description:"EntryPoint" export:"true" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
-	ManualRouting        bool      `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty"`
+	Buckets                   []float64                          `description:"Buckets for latency metrics." json:"buckets,omitempty" toml:"buckets,omitempty" yaml:"buckets,omitempty" export:"true"`
+	SizeBuckets               []float64                          `description:"Buckets, in bytes, for the request and response size metrics." json:"sizeBuckets,omitempty" toml:"sizeBuckets,omitempty" yaml:"sizeBuckets,omitempty" export:"true"`
+	Histograms                map[string]*PrometheusHistogram    `description:"Buckets of the histogram metrics, by metric name, overriding buckets and sizeBuckets." json:"histograms,omitempty" toml:"histograms,omitempty" yaml:"histograms,omitempty" export:"true"`
+	NativeHistograms          *PrometheusNativeHistograms        `description:"Also records the request durations in native histograms, for Prometheus 2.40+." json:"nativeHistograms,omitempty" toml:"nativeHistograms,omitempty" yaml:"nativeHistograms,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	AddEntryPointsLabels      bool                               `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
+	AddServicesLabels         bool                               `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
+	AddRoutersLabels          bool                               `description:"Enable metrics on routers." json:"addRoutersLabels,omitempty" toml:"addRoutersLabels,omitempty" yaml:"addRoutersLabels,omitempty" export:"true"`
+	AddMiddlewaresLabels      bool                               `description:"Enable metrics on middlewares." json:"addMiddlewaresLabels,omitempty" toml:"addMiddlewaresLabels,omitempty" yaml:"addMiddlewaresLabels,omitempty" export:"true"`
+	AddRateLimitMetrics       bool                               `description:"Enable the metrics of the rate limit middlewares." json:"addRateLimitMetrics,omitempty" toml:"addRateLimitMetrics,omitempty" yaml:"addRateLimitMetrics,omitempty" export:"true"`
+	AddRateLimitSourcesLabels bool                               `description:"Add the source label to the metrics of the rate limit middlewares, and report the tokens left in the bucket of each source." json:"addRateLimitSourcesLabels,omitempty" toml:"addRateLimitSourcesLabels,omitempty" yaml:"addRateLimitSourcesLabels,omitempty" export:"true"`
+	AddServicesTCPInfo        bool                               `description:"Enable the TCP statistics of the connections to the servers on services (Linux only)." json:"addServicesTCPInfo,omitempty" toml:"addServicesTCPInfo,omitempty" yaml:"addServicesTCPInfo,omitempty" export:"true"`
+	AddInfoMetrics            bool                               `description:"Enable the info metrics describing the routers, services, middlewares and providers of the configuration." json:"addInfoMetrics,omitempty" toml:"addInfoMetrics,omitempty" yaml:"addInfoMetrics,omitempty" export:"true"`
+	AddRuntimeMetrics         bool                               `description:"Enable the Go runtime and process metrics." json:"addRuntimeMetrics,omitempty" toml:"addRuntimeMetrics,omitempty" yaml:"addRuntimeMetrics,omitempty" export:"true"`
+	RuntimeNamespace          string                             `description:"Namespace prefixing the names of the Go runtime and process metrics." json:"runtimeNamespace,omitempty" toml:"runtimeNamespace,omitempty" yaml:"runtimeNamespace,omitempty" export:"true"`
+	RequestLabels             map[string]*PrometheusRequestLabel `description:"Extra labels of the request metrics, by label name, valued from a request header or the client certificate." json:"requestLabels,omitempty" toml:"requestLabels,omitempty" yaml:"requestLabels,omitempty" export:"true"`
+	EntryPoint                string                             `description:"EntryPoint" export:"true" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
+	ManualRouting             bool                               `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty"`
+	Address                   string                             `description:"Address of a dedicated entry point serving the metrics, instead of entryPoint." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" export:"true"`
+	TLS                       *MetricsTLS                        `description:"Serves the metrics over TLS." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	BasicAuth                 *MetricsBasicAuth                  `description:"Protects the metrics with a basic authentication." json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty" export:"true"`
+	IPWhiteList               []string                           `description:"Allowed IPs or CIDR ranges to scrape the metrics." json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
+	MaxLabelCardinality       int                                `description:"Maximum number of label combinations of each metric, the new combinations beyond it being recorded with the other label values, 0 for no limit." json:"maxLabelCardinality,omitempty" toml:"maxLabelCardinality,omitempty" yaml:"maxLabelCardinality,omitempty" export:"true"`
+	StatusCodeClasses         bool                               `description:"Aggregate the code label of the request metrics into classes (2xx, 3xx, 4xx, 5xx)." json:"statusCodeClasses,omitempty" toml:"statusCodeClasses,omitempty" yaml:"statusCodeClasses,omitempty" export:"true"`
+	DropMethodLabel           bool                               `description:"Drop the method label of the request metrics." json:"dropMethodLabel,omitempty" toml:"dropMethodLabel,omitempty" yaml:"dropMethodLabel,omitempty" export:"true"`
+	Push                      *PrometheusPush                    `description:"Pushes the metrics to a Pushgateway." json:"push,omitempty" toml:"push,omitempty" yaml:"push,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
 func (p *Prometheus) SetDefaults() {
 	p.Buckets = []float64{0.1, 0.3, 1.2, 5}
+	p.SizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
 	p.AddEntryPointsLabels = true
 	p.AddServicesLabels = true
+	p.AddRuntimeMetrics = true
 	p.EntryPoint = "traefik"
 }
 
+// PrometheusHistogram holds the buckets of a histogram metric.
+type PrometheusHistogram struct {
+	Buckets  []float64                              `description:"Buckets of the metric." json:"buckets,omitempty" toml:"buckets,omitempty" yaml:"buckets,omitempty" export:"true"`
+	Services map[string]*PrometheusServiceHistogram `description:"Buckets of the metric for specific services, by service name." json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+}
+
+// PrometheusServiceHistogram holds the buckets of a histogram metric for a service.
+type PrometheusServiceHistogram struct {
+	Buckets []float64 `description:"Buckets of the metric for the service." json:"buckets,omitempty" toml:"buckets,omitempty" yaml:"buckets,omitempty" export:"true"`
+}
+
+// PrometheusRequestLabel holds the source of an extra label of the request metrics,
+// e.g. a header identifying the tenant of a multi-tenant gateway.
+type PrometheusRequestLabel struct {
+	Header       string `description:"Request header valuing the label." json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty" export:"true"`
+	ClientCertCN bool   `description:"Values the label with the common name of the client certificate." json:"clientCertCN,omitempty" toml:"clientCertCN,omitempty" yaml:"clientCertCN,omitempty" export:"true"`
+	MaxValues    int    `description:"Maximum number of values of the label, the new values beyond it being recorded as other, 0 for no limit." json:"maxValues,omitempty" toml:"maxValues,omitempty" yaml:"maxValues,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (p *PrometheusRequestLabel) SetDefaults() {
+	p.MaxValues = 100
+}
+
+// PrometheusNativeHistograms holds the configuration of the native histograms of the request durations,
+// whose buckets grow exponentially and are only exposed when they hold observations.
+type PrometheusNativeHistograms struct {
+	BucketFactor    float64 `description:"Maximum growth factor between the boundaries of two consecutive buckets, greater than 1." json:"bucketFactor,omitempty" toml:"bucketFactor,omitempty" yaml:"bucketFactor,omitempty" export:"true"`
+	MaxBucketNumber int     `description:"Maximum number of buckets of each histogram, their resolution being reduced beyond it, 0 for no limit." json:"maxBucketNumber,omitempty" toml:"maxBucketNumber,omitempty" yaml:"maxBucketNumber,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (p *PrometheusNativeHistograms) SetDefaults() {
+	p.BucketFactor = 1.1
+	p.MaxBucketNumber = 160
+}
+
+// PrometheusPush holds the configuration of the pushes of the Prometheus metrics to a Pushgateway.
+type PrometheusPush struct {
+	URL          string            `description:"URL of the Pushgateway." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	Job          string            `description:"Job label of the pushed metrics." json:"job,omitempty" toml:"job,omitempty" yaml:"job,omitempty" export:"true"`
+	Grouping     map[string]string `description:"Labels of the grouping key, besides the job, identifying the metrics of the instance." json:"grouping,omitempty" toml:"grouping,omitempty" yaml:"grouping,omitempty" export:"true"`
+	PushInterval types.Duration    `description:"Pushgateway push interval." json:"pushInterval,omitempty" toml:"pushInterval,omitempty" yaml:"pushInterval,omitempty" export:"true"`
+	Username     string            `description:"Username of the basic authentication on the Pushgateway." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password     string            `description:"Password of the basic authentication on the Pushgateway." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (p *PrometheusPush) SetDefaults() {
+	p.Job = "traefik"
+	p.PushInterval = types.Duration(10 * time.Second)
+}
+
+// MetricsTLS holds the TLS configuration of the metrics endpoint.
+type MetricsTLS struct {
+	CertFile string   `description:"TLS certificate." json:"certFile,omitempty" toml:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  string   `description:"TLS key." json:"keyFile,omitempty" toml:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	CAFiles  []string `description:"Certificate authorities of the clients, enables the mutual TLS authentication." json:"caFiles,omitempty" toml:"caFiles,omitempty" yaml:"caFiles,omitempty"`
+}
+
+// MetricsBasicAuth holds the basic authentication configuration of the metrics endpoint.
+type MetricsBasicAuth struct {
+	Users     []string `description:"Authorized users, in the htpasswd format." json:"users,omitempty" toml:"users,omitempty" yaml:"users,omitempty"`
+	UsersFile string   `description:"Path to a file containing the authorized users." json:"usersFile,omitempty" toml:"usersFile,omitempty" yaml:"usersFile,omitempty"`
+}
+
 // Datadog contains address and metrics pushing interval configuration.
 type Datadog struct {
-	Address              string         `description:"Datadog's address." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
-	PushInterval         types.Duration `description:"Datadog push interval." json:"pushInterval,omitempty" toml:"pushInterval,omitempty" yaml:"pushInterval,omitempty" export:"true"`
-	AddEntryPointsLabels bool           `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
-	AddServicesLabels    bool           `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
+	Address                   string         `description:"Datadog's address, or unix:// followed by the path of the agent socket." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	PushInterval              types.Duration `description:"Datadog push interval." json:"pushInterval,omitempty" toml:"pushInterval,omitempty" yaml:"pushInterval,omitempty" export:"true"`
+	AddEntryPointsLabels      bool           `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
+	AddServicesLabels         bool           `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
+	AddRoutersLabels          bool           `description:"Enable metrics on routers." json:"addRoutersLabels,omitempty" toml:"addRoutersLabels,omitempty" yaml:"addRoutersLabels,omitempty" export:"true"`
+	AddRateLimitMetrics       bool           `description:"Enable the metrics of the rate limit middlewares." json:"addRateLimitMetrics,omitempty" toml:"addRateLimitMetrics,omitempty" yaml:"addRateLimitMetrics,omitempty" export:"true"`
+	AddRateLimitSourcesLabels bool           `description:"Add the source label to the metrics of the rate limit middlewares, and report the tokens left in the bucket of each source." json:"addRateLimitSourcesLabels,omitempty" toml:"addRateLimitSourcesLabels,omitempty" yaml:"addRateLimitSourcesLabels,omitempty" export:"true"`
+	MaxPacketSize             int            `description:"Maximum size of the packets sent to the agent, 0 to use the default of the transport." json:"maxPacketSize,omitempty" toml:"maxPacketSize,omitempty" yaml:"maxPacketSize,omitempty" export:"true"`
+	OriginDetection           bool           `description:"Send the container ID with the metrics, for the agent origin detection." json:"originDetection,omitempty" toml:"originDetection,omitempty" yaml:"originDetection,omitempty" export:"true"`
+	MaxLabelCardinality       int            `description:"Maximum number of label combinations of each metric, the new combinations beyond it being recorded with the other label values, 0 for no limit." json:"maxLabelCardinality,omitempty" toml:"maxLabelCardinality,omitempty" yaml:"maxLabelCardinality,omitempty" export:"true"`
+	StatusCodeClasses         bool           `description:"Aggregate the code label of the request metrics into classes (2xx, 3xx, 4xx, 5xx)." json:"statusCodeClasses,omitempty" toml:"statusCodeClasses,omitempty" yaml:"statusCodeClasses,omitempty" export:"true"`
+	DropMethodLabel           bool           `description:"Drop the method label of the request metrics." json:"dropMethodLabel,omitempty" toml:"dropMethodLabel,omitempty" yaml:"dropMethodLabel,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -53,7 +150,9 @@ type Statsd struct {
 	PushInterval         types.Duration `description:"StatsD push interval." json:"pushInterval,omitempty" toml:"pushInterval,omitempty" yaml:"pushInterval,omitempty" export:"true"`
 	AddEntryPointsLabels bool           `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
 	AddServicesLabels    bool           `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
+	AddRoutersLabels     bool           `description:"Enable metrics on routers." json:"addRoutersLabels,omitempty" toml:"addRoutersLabels,omitempty" yaml:"addRoutersLabels,omitempty" export:"true"`
 	Prefix               string         `description:"Prefix to use for metrics collection." json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty" export:"true"`
+	Format               string         `description:"Format of the labels: plain (no labels), datadog (DogStatsD tags), or influx (InfluxDB tags)." json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -63,6 +162,7 @@ func (s *Statsd) SetDefaults() {
 	s.AddEntryPointsLabels = true
 	s.AddServicesLabels = true
 	s.Prefix = "traefik"
+	s.Format = "plain"
 }
 
 // InfluxDB contains address, login and metrics pushing interval configuration.
@@ -74,8 +174,19 @@ type InfluxDB struct {
 	RetentionPolicy      string         `description:"InfluxDB retention policy used when protocol is http." json:"retentionPolicy,omitempty" toml:"retentionPolicy,omitempty" yaml:"retentionPolicy,omitempty" export:"true"`
 	Username             string         `description:"InfluxDB username (only with http)." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" export:"true"`
 	Password             string         `description:"InfluxDB password (only with http)." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" export:"true"`
+	Token                string         `description:"InfluxDB token, used with the v2 write API (only with http)." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	Organization         string         `description:"InfluxDB organization, used with the v2 write API (only with http)." json:"organization,omitempty" toml:"organization,omitempty" yaml:"organization,omitempty" export:"true"`
+	Bucket               string         `description:"InfluxDB bucket, enables the v2 write API (only with http)." json:"bucket,omitempty" toml:"bucket,omitempty" yaml:"bucket,omitempty" export:"true"`
+	Gzip                 bool           `description:"Compress the batches written with the v2 write API." json:"gzip,omitempty" toml:"gzip,omitempty" yaml:"gzip,omitempty" export:"true"`
+	BatchSize            int            `description:"Maximum number of points written at once with the v2 write API, 0 for no limit." json:"batchSize,omitempty" toml:"batchSize,omitempty" yaml:"batchSize,omitempty" export:"true"`
+	MaxRetries           int            `description:"Maximum number of retries of the writes failing with a 429 or 5xx status, with the v2 write API." json:"maxRetries,omitempty" toml:"maxRetries,omitempty" yaml:"maxRetries,omitempty" export:"true"`
+	TLS                  *ClientTLS     `description:"TLS configuration of the client, for the addresses using https (only with http)." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
 	AddEntryPointsLabels bool           `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
 	AddServicesLabels    bool           `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
+	AddRoutersLabels     bool           `description:"Enable metrics on routers." json:"addRoutersLabels,omitempty" toml:"addRoutersLabels,omitempty" yaml:"addRoutersLabels,omitempty" export:"true"`
+	MaxLabelCardinality  int            `description:"Maximum number of label combinations of each metric, the new combinations beyond it being recorded with the other label values, 0 for no limit." json:"maxLabelCardinality,omitempty" toml:"maxLabelCardinality,omitempty" yaml:"maxLabelCardinality,omitempty" export:"true"`
+	StatusCodeClasses    bool           `description:"Aggregate the code label of the request metrics into classes (2xx, 3xx, 4xx, 5xx)." json:"statusCodeClasses,omitempty" toml:"statusCodeClasses,omitempty" yaml:"statusCodeClasses,omitempty" export:"true"`
+	DropMethodLabel      bool           `description:"Drop the method label of the request metrics." json:"dropMethodLabel,omitempty" toml:"dropMethodLabel,omitempty" yaml:"dropMethodLabel,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -83,10 +194,32 @@ func (i *InfluxDB) SetDefaults() {
 	i.Address = "localhost:8089"
 	i.Protocol = "udp"
 	i.PushInterval = types.Duration(10 * time.Second)
+	i.MaxRetries = 3
 	i.AddEntryPointsLabels = true
 	i.AddServicesLabels = true
 }
 
+// Graphite contains the address, protocol and metrics pushing interval configuration of a Graphite (Carbon) server.
+type Graphite struct {
+	Address              string         `description:"Graphite address." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Protocol             string         `description:"Graphite protocol: plaintext or pickle." json:"protocol,omitempty" toml:"protocol,omitempty" yaml:"protocol,omitempty" export:"true"`
+	PushInterval         types.Duration `description:"Graphite push interval." json:"pushInterval,omitempty" toml:"pushInterval,omitempty" yaml:"pushInterval,omitempty" export:"true"`
+	AddEntryPointsLabels bool           `description:"Enable metrics on entry points." json:"addEntryPointsLabels,omitempty" toml:"addEntryPointsLabels,omitempty" yaml:"addEntryPointsLabels,omitempty" export:"true"`
+	AddServicesLabels    bool           `description:"Enable metrics on services." json:"addServicesLabels,omitempty" toml:"addServicesLabels,omitempty" yaml:"addServicesLabels,omitempty" export:"true"`
+	AddRoutersLabels     bool           `description:"Enable metrics on routers." json:"addRoutersLabels,omitempty" toml:"addRoutersLabels,omitempty" yaml:"addRoutersLabels,omitempty" export:"true"`
+	Prefix               string         `description:"Prefix to use for metrics collection." json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (g *Graphite) SetDefaults() {
+	g.Address = "localhost:2003"
+	g.Protocol = "plaintext"
+	g.PushInterval = types.Duration(10 * time.Second)
+	g.AddEntryPointsLabels = true
+	g.AddServicesLabels = true
+	g.Prefix = "traefik"
+}
+
 // Statistics provides options for monitoring request and response stats.
 type Statistics struct {
 	RecentErrors int `description:"Number of recent errors logged." json:"recentErrors,omitempty" toml:"recentErrors,omitempty" yaml:"recentErrors,omitempty" export:"true"`
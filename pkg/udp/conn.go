@@ -20,6 +20,14 @@ var timeoutTicker = connTimeout / 10
 
 var errClosedListener = errors.New("udp: listener closed")
 
+// DatagramObserver is notified of the size of the datagrams transferred by a Listener.
+type DatagramObserver interface {
+	// Received is called for each datagram received by the Listener.
+	Received(size int)
+	// Sent is called for each datagram sent by the Listener.
+	Sent(size int)
+}
+
 // Listener augments a session-oriented Listener over a UDP PacketConn.
 type Listener struct {
 	pConn *net.UDPConn
@@ -31,10 +39,17 @@ type Listener struct {
 	accepting bool
 
 	acceptCh chan *Conn // no need for a Once, already indirectly guarded by accepting.
+
+	observer DatagramObserver
 }
 
 // Listen creates a new listener.
 func Listen(network string, laddr *net.UDPAddr) (*Listener, error) {
+	return ListenWithObserver(network, laddr, nil)
+}
+
+// ListenWithObserver creates a new listener, notifying the observer, if any, of the transferred datagrams.
+func ListenWithObserver(network string, laddr *net.UDPAddr, observer DatagramObserver) (*Listener, error) {
 	conn, err := net.ListenUDP(network, laddr)
 	if err != nil {
 		return nil, err
@@ -45,6 +60,7 @@ func Listen(network string, laddr *net.UDPAddr) (*Listener, error) {
 		acceptCh:  make(chan *Conn),
 		conns:     make(map[string]*Conn),
 		accepting: true,
+		observer:  observer,
 	}
 
 	go l.readLoop()
@@ -137,6 +153,9 @@ func (l *Listener) readLoop() {
 		if err != nil {
 			return
 		}
+		if l.observer != nil {
+			l.observer.Received(n)
+		}
 		conn, err := l.getConn(raddr)
 		if err != nil {
 			continue
@@ -270,7 +289,12 @@ func (c *Conn) Write(p []byte) (n int, err error) {
 	c.muActivity.Lock()
 	c.lastActivity = time.Now()
 	c.muActivity.Unlock()
-	return l.pConn.WriteTo(p, c.rAddr)
+
+	n, err = l.pConn.WriteTo(p, c.rAddr)
+	if err == nil && l.observer != nil {
+		l.observer.Sent(n)
+	}
+	return n, err
 }
 
 func (c *Conn) close() {
@@ -3,6 +3,7 @@ package udp
 import (
 	"io"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
@@ -71,6 +72,69 @@ func TestConsecutiveWrites(t *testing.T) {
 	require.Equal(t, "1TEST", string(b[:n]))
 }
 
+// recordingObserver is a DatagramObserver recording the sizes of the transferred datagrams.
+type recordingObserver struct {
+	mu       sync.Mutex
+	received []int
+	sent     []int
+}
+
+func (o *recordingObserver) Received(size int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.received = append(o.received, size)
+}
+
+func (o *recordingObserver) Sent(size int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sent = append(o.sent, size)
+}
+
+func TestListenWithObserver(t *testing.T) {
+	addr, err := net.ResolveUDPAddr("udp", ":0")
+	require.NoError(t, err)
+
+	observer := &recordingObserver{}
+
+	ln, err := ListenWithObserver("udp", addr, observer)
+	require.NoError(t, err)
+	defer func() {
+		err := ln.Close()
+		require.NoError(t, err)
+	}()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err == errClosedListener {
+			return
+		}
+		require.NoError(t, err)
+
+		b := make([]byte, 2048)
+		n, err := conn.Read(b)
+		require.NoError(t, err)
+		_, err = conn.Write(append(b[:n], b[:n]...))
+		require.NoError(t, err)
+	}()
+
+	udpConn, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = udpConn.Write([]byte("TEST"))
+	require.NoError(t, err)
+
+	b := make([]byte, 2048)
+	n, err := udpConn.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, "TESTTEST", string(b[:n]))
+
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	assert.Equal(t, []int{4}, observer.received)
+	assert.Equal(t, []int{8}, observer.sent)
+}
+
 func TestListenNotBlocking(t *testing.T) {
 	addr, err := net.ResolveUDPAddr("udp", ":0")
 
@@ -13,6 +13,17 @@ export function getOverview ({ commit }) {
     })
 }
 
+export function getInstance ({ commit }) {
+  return coreService.getOverview()
+    .then(body => {
+      commit('getInstanceSuccess', body.instance)
+      return body.instance
+    })
+    .catch(error => {
+      return Promise.reject(error)
+    })
+}
+
 export function getVersion ({ commit }) {
   return coreService.getVersion()
     .then(body => {
@@ -11,3 +11,10 @@ export function allOverview (state) {
 export function version (state) {
   return state.version
 }
+
+// ----------------------------
+// Instance
+// ----------------------------
+export function instance (state) {
+  return state.instance
+}
@@ -23,3 +23,10 @@ export function getOverviewClear (state) {
 export function getVersionSuccess (state, body) {
   state.version = body
 }
+
+// ----------------------------
+// Get Instance
+// ----------------------------
+export function getInstanceSuccess (state, body) {
+  state.instance = body || {}
+}
@@ -1,4 +1,5 @@
 export default {
   allOverview: {},
-  version: ''
+  version: '',
+  instance: {}
 }